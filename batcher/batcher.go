@@ -0,0 +1,75 @@
+package batcher
+
+import (
+	"context"
+	"time"
+)
+
+// FlushFunc receives a full or timed-out batch. The slice is owned by the callee.
+type FlushFunc[T any] func(ctx context.Context, items []T) error
+
+// Batcher accumulates items from a channel and hands them to a FlushFunc
+// once size items are buffered or interval has elapsed since the last flush.
+type Batcher[T any] struct {
+	size     int
+	interval time.Duration
+	flush    FlushFunc[T]
+}
+
+// New creates a Batcher. size <= 0 disables size-triggered flushes and
+// interval <= 0 disables time-triggered flushes.
+func New[T any](size int, interval time.Duration, flush FlushFunc[T]) *Batcher[T] {
+	return &Batcher[T]{
+		size:     size,
+		interval: interval,
+		flush:    flush,
+	}
+}
+
+// Run consumes in until it is closed, flushing the remaining items before returning.
+// It stops early with the error of the first failed flush, or with ctx.Err()
+// when ctx is done, in which case buffered items are dropped.
+func (b *Batcher[T]) Run(ctx context.Context, in <-chan T) error {
+	var ticker *time.Ticker
+	var tick <-chan time.Time
+	if b.interval > 0 {
+		ticker = time.NewTicker(b.interval)
+		defer ticker.Stop()
+		tick = ticker.C
+	}
+
+	var buf []T
+	flush := func() error {
+		if len(buf) == 0 {
+			return nil
+		}
+		items := buf
+		buf = nil
+		// the next time flush is due interval after this one, whatever triggered it.
+		if ticker != nil {
+			ticker.Reset(b.interval)
+		}
+		return b.flush(ctx, items)
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case item, ok := <-in:
+			if !ok {
+				return flush()
+			}
+			buf = append(buf, item)
+			if b.size > 0 && len(buf) >= b.size {
+				if err := flush(); err != nil {
+					return err
+				}
+			}
+		case <-tick:
+			if err := flush(); err != nil {
+				return err
+			}
+		}
+	}
+}
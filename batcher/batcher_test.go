@@ -0,0 +1,77 @@
+package batcher
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBatcher(t *testing.T) {
+	var batches [][]int
+	b := New(3, time.Hour, func(_ context.Context, items []int) error {
+		batches = append(batches, items)
+		return nil
+	})
+
+	in := make(chan int)
+	go func() {
+		for i := 1; i <= 7; i++ {
+			in <- i
+		}
+		close(in)
+	}()
+
+	assert.NoError(t, b.Run(context.Background(), in))
+	assert.Equal(t, [][]int{{1, 2, 3}, {4, 5, 6}, {7}}, batches)
+}
+
+func TestBatcher_Interval(t *testing.T) {
+	flushed := make(chan []int, 1)
+	b := New(100, 10*time.Millisecond, func(_ context.Context, items []int) error {
+		flushed <- items
+		return nil
+	})
+
+	in := make(chan int, 1)
+	in <- 1
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go b.Run(ctx, in)
+
+	select {
+	case items := <-flushed:
+		assert.Equal(t, []int{1}, items)
+	case <-time.After(time.Second):
+		t.Fatal("batch was not flushed by interval")
+	}
+}
+
+func TestBatcher_IntervalAfterSizeFlush(t *testing.T) {
+	const interval = 100 * time.Millisecond
+	flushed := make(chan time.Time, 2)
+	b := New(2, interval, func(_ context.Context, items []int) error {
+		flushed <- time.Now()
+		return nil
+	})
+
+	in := make(chan int, 3)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go b.Run(ctx, in)
+
+	// a full batch just before the tick postpones the flush of the partial one.
+	time.Sleep(interval - 20*time.Millisecond)
+	in <- 1
+	in <- 2
+	in <- 3
+
+	full := <-flushed
+	select {
+	case partial := <-flushed:
+		assert.GreaterOrEqual(t, partial.Sub(full), interval-10*time.Millisecond)
+	case <-time.After(time.Second):
+		t.Fatal("batch was not flushed by interval")
+	}
+}
@@ -0,0 +1,332 @@
+package breaker
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+// ErrOpen is returned without calling the protected function while the circuit is open,
+// or while the half-open probe quota is used up.
+var ErrOpen = errors.New("breaker: circuit open")
+
+// errPanic is recorded for a protected function that panicked, as a failure
+// whatever the failure classifier says.
+var errPanic = errors.New("breaker: panic")
+
+type State int
+
+const (
+	StateClosed State = iota
+	StateOpen
+	StateHalfOpen
+)
+
+func (s State) String() string {
+	switch s {
+	case StateClosed:
+		return "closed"
+	case StateOpen:
+		return "open"
+	case StateHalfOpen:
+		return "half-open"
+	default:
+		return "unknown"
+	}
+}
+
+// Breaker is a circuit breaker over a count-based sliding window of the latest calls.
+// It opens when the failure rate or the slow call rate of the window reaches its
+// threshold, rejects calls for the open timeout, then lets a few probe calls through
+// (half-open) and closes again if they all succeed.
+type Breaker struct {
+	name string
+	opts options
+
+	mu         sync.Mutex
+	state      State
+	generation uint64
+	openedAt   time.Time
+
+	// closed state window
+	window   []outcome
+	next     int
+	count    int
+	failures int
+	slows    int
+
+	// half-open state counters
+	probes    int
+	successes int
+}
+
+type outcome struct {
+	failure bool
+	slow    bool
+}
+
+type Option func(*options)
+
+type options struct {
+	windowSize            int
+	minCalls              int
+	failureRateThreshold  float64
+	slowCallDuration      time.Duration
+	slowCallRateThreshold float64
+	openTimeout           time.Duration
+	halfOpenMaxCalls      int
+	isFailure             func(err error) bool
+	onStateChange         func(name string, from, to State)
+	now                   func() time.Time
+}
+
+// WithWindow sets the number of latest calls the rates are computed over. Defaults to 100.
+func WithWindow(size int) Option {
+	return func(o *options) {
+		o.windowSize = size
+	}
+}
+
+// WithMinCalls sets the number of calls the window must hold before the breaker may open. Defaults to 10.
+func WithMinCalls(n int) Option {
+	return func(o *options) {
+		o.minCalls = n
+	}
+}
+
+// WithFailureRateThreshold sets the failure rate in (0, 1] opening the circuit. Defaults to 0.5.
+func WithFailureRateThreshold(rate float64) Option {
+	return func(o *options) {
+		o.failureRateThreshold = rate
+	}
+}
+
+// WithSlowCallThreshold counts calls lasting at least d as slow and opens the circuit
+// once the slow call rate reaches rate. Disabled by default.
+func WithSlowCallThreshold(d time.Duration, rate float64) Option {
+	return func(o *options) {
+		o.slowCallDuration = d
+		o.slowCallRateThreshold = rate
+	}
+}
+
+// WithOpenTimeout sets how long the circuit stays open before going half-open. Defaults to 30s.
+func WithOpenTimeout(d time.Duration) Option {
+	return func(o *options) {
+		o.openTimeout = d
+	}
+}
+
+// WithHalfOpenMaxCalls sets the number of probe calls allowed while half-open. Defaults to 1.
+func WithHalfOpenMaxCalls(n int) Option {
+	return func(o *options) {
+		o.halfOpenMaxCalls = n
+	}
+}
+
+// WithIsFailure decides which errors count as failures. By default every error except
+// context.Canceled does.
+func WithIsFailure(fn func(err error) bool) Option {
+	return func(o *options) {
+		o.isFailure = fn
+	}
+}
+
+// WithOnStateChange registers a callback invoked on every state transition. It runs with
+// the breaker lock held and must not call back into the breaker.
+func WithOnStateChange(fn func(name string, from, to State)) Option {
+	return func(o *options) {
+		o.onStateChange = fn
+	}
+}
+
+// WithClock overrides time.Now, mainly for tests.
+func WithClock(now func() time.Time) Option {
+	return func(o *options) {
+		o.now = now
+	}
+}
+
+func defaultIsFailure(err error) bool {
+	return err != nil && !errors.Is(err, context.Canceled)
+}
+
+// New creates a closed breaker. name identifies it in state change callbacks.
+func New(name string, opts ...Option) *Breaker {
+	o := options{
+		windowSize:           100,
+		minCalls:             10,
+		failureRateThreshold: 0.5,
+		openTimeout:          30 * time.Second,
+		halfOpenMaxCalls:     1,
+		isFailure:            defaultIsFailure,
+		now:                  time.Now,
+	}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	o.windowSize = max(o.windowSize, 1)
+	o.minCalls = min(max(o.minCalls, 1), o.windowSize)
+	o.halfOpenMaxCalls = max(o.halfOpenMaxCalls, 1)
+
+	return &Breaker{
+		name:   name,
+		opts:   o,
+		window: make([]outcome, o.windowSize),
+	}
+}
+
+func (b *Breaker) Name() string {
+	return b.name
+}
+
+func (b *Breaker) State() State {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.refresh()
+	return b.state
+}
+
+// Allow reports whether a call may proceed. On success the caller must invoke done
+// with the call's error once it finishes.
+func (b *Breaker) Allow() (done func(err error), err error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.refresh()
+	switch b.state {
+	case StateOpen:
+		return nil, ErrOpen
+	case StateHalfOpen:
+		if b.probes >= b.opts.halfOpenMaxCalls {
+			return nil, ErrOpen
+		}
+		b.probes++
+	}
+
+	generation, start := b.generation, b.opts.now()
+	return func(err error) {
+		b.record(generation, start, err)
+	}, nil
+}
+
+// Execute calls fn if the breaker allows it and records the outcome. A panic of fn
+// is recorded as a failure and propagated.
+func (b *Breaker) Execute(ctx context.Context, fn func(ctx context.Context) error) error {
+	_, err := Do(ctx, b, func(ctx context.Context) (struct{}, error) {
+		return struct{}{}, fn(ctx)
+	})
+	return err
+}
+
+// Do is the generic counterpart of Breaker.Execute.
+func Do[T any](ctx context.Context, b *Breaker, fn func(ctx context.Context) (T, error)) (T, error) {
+	done, err := b.Allow()
+	if err != nil {
+		var zero T
+		return zero, err
+	}
+
+	// Releases the half-open probe slot even if fn panics.
+	defer func() {
+		if r := recover(); r != nil {
+			done(errPanic)
+			panic(r)
+		}
+	}()
+	v, err := fn(ctx)
+	done(err)
+	return v, err
+}
+
+func (b *Breaker) record(generation uint64, start time.Time, err error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	// the outcome belongs to a state the breaker already left.
+	if generation != b.generation {
+		return
+	}
+
+	o := outcome{
+		failure: err == errPanic || (err != nil && b.opts.isFailure(err)),
+		slow:    b.opts.slowCallDuration > 0 && b.opts.now().Sub(start) >= b.opts.slowCallDuration,
+	}
+
+	switch b.state {
+	case StateClosed:
+		b.push(o)
+		if b.count >= b.opts.minCalls && b.tripped() {
+			b.transition(StateOpen)
+		}
+	case StateHalfOpen:
+		if o.failure || o.slow {
+			b.transition(StateOpen)
+			return
+		}
+		b.successes++
+		if b.successes >= b.opts.halfOpenMaxCalls {
+			b.transition(StateClosed)
+		}
+	}
+}
+
+func (b *Breaker) push(o outcome) {
+	if b.count == len(b.window) {
+		old := b.window[b.next]
+		if old.failure {
+			b.failures--
+		}
+		if old.slow {
+			b.slows--
+		}
+	} else {
+		b.count++
+	}
+
+	b.window[b.next] = o
+	b.next = (b.next + 1) % len(b.window)
+	if o.failure {
+		b.failures++
+	}
+	if o.slow {
+		b.slows++
+	}
+}
+
+func (b *Breaker) tripped() bool {
+	total := float64(b.count)
+	if float64(b.failures)/total >= b.opts.failureRateThreshold {
+		return true
+	}
+	return b.opts.slowCallRateThreshold > 0 && float64(b.slows)/total >= b.opts.slowCallRateThreshold
+}
+
+// refresh moves an open breaker to half-open once the open timeout elapsed.
+func (b *Breaker) refresh() {
+	if b.state == StateOpen && !b.opts.now().Before(b.openedAt.Add(b.opts.openTimeout)) {
+		b.transition(StateHalfOpen)
+	}
+}
+
+func (b *Breaker) transition(to State) {
+	from := b.state
+	b.state = to
+	b.generation++
+
+	switch to {
+	case StateOpen:
+		b.openedAt = b.opts.now()
+	case StateHalfOpen:
+		b.probes, b.successes = 0, 0
+	case StateClosed:
+		clear(b.window)
+		b.next, b.count, b.failures, b.slows = 0, 0, 0, 0
+	}
+
+	if b.opts.onStateChange != nil {
+		b.opts.onStateChange(b.name, from, to)
+	}
+}
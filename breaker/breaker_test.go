@@ -0,0 +1,92 @@
+package breaker
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBreaker(t *testing.T) {
+	now := time.Unix(0, 0)
+	var transitions []string
+	b := New("es",
+		WithWindow(4),
+		WithMinCalls(4),
+		WithFailureRateThreshold(0.5),
+		WithOpenTimeout(time.Minute),
+		WithClock(func() time.Time { return now }),
+		WithOnStateChange(func(_ string, from, to State) {
+			transitions = append(transitions, from.String()+"->"+to.String())
+		}),
+	)
+
+	ctx := context.Background()
+	errBoom := errors.New("boom")
+	ok := func(context.Context) error { return nil }
+	fail := func(context.Context) error { return errBoom }
+
+	assert.NoError(t, b.Execute(ctx, ok))
+	assert.NoError(t, b.Execute(ctx, ok))
+	assert.ErrorIs(t, b.Execute(ctx, fail), errBoom)
+	assert.Equal(t, StateClosed, b.State())
+	assert.ErrorIs(t, b.Execute(ctx, fail), errBoom)
+	assert.Equal(t, StateOpen, b.State())
+	assert.ErrorIs(t, b.Execute(ctx, ok), ErrOpen)
+
+	now = now.Add(time.Minute)
+	assert.Equal(t, StateHalfOpen, b.State())
+	assert.ErrorIs(t, b.Execute(ctx, fail), errBoom)
+	assert.Equal(t, StateOpen, b.State())
+
+	now = now.Add(time.Minute)
+	v, err := Do(ctx, b, func(context.Context) (int, error) { return 1, nil })
+	assert.NoError(t, err)
+	assert.Equal(t, 1, v)
+	assert.Equal(t, StateClosed, b.State())
+
+	assert.Equal(t, []string{
+		"closed->open", "open->half-open", "half-open->open", "open->half-open", "half-open->closed",
+	}, transitions)
+}
+
+func TestBreaker_SlowCalls(t *testing.T) {
+	now := time.Unix(0, 0)
+	b := New("graph",
+		WithWindow(2),
+		WithMinCalls(2),
+		WithSlowCallThreshold(time.Second, 1),
+		WithClock(func() time.Time { return now }),
+	)
+
+	slow := func(context.Context) error { now = now.Add(2 * time.Second); return nil }
+	assert.NoError(t, b.Execute(context.Background(), slow))
+	assert.NoError(t, b.Execute(context.Background(), slow))
+	assert.Equal(t, StateOpen, b.State())
+}
+
+func TestBreaker_Panic(t *testing.T) {
+	now := time.Unix(0, 0)
+	b := New("es",
+		WithWindow(1),
+		WithMinCalls(1),
+		WithOpenTimeout(time.Minute),
+		WithIsFailure(func(error) bool { return false }),
+		WithClock(func() time.Time { return now }),
+	)
+	ctx := context.Background()
+	boom := func(context.Context) error { panic("boom") }
+
+	assert.PanicsWithValue(t, "boom", func() { _ = b.Execute(ctx, boom) })
+	assert.Equal(t, StateOpen, b.State())
+
+	// The probe slot taken by the panicking call is released.
+	now = now.Add(time.Minute)
+	assert.PanicsWithValue(t, "boom", func() { _ = b.Execute(ctx, boom) })
+	assert.Equal(t, StateOpen, b.State())
+	now = now.Add(time.Minute)
+	assert.NoError(t, b.Execute(ctx, func(context.Context) error { return nil }))
+	assert.Equal(t, StateClosed, b.State())
+}
@@ -0,0 +1,211 @@
+package conf
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/me2seeks/forge/sonic"
+)
+
+// Validator is implemented by configs checking themselves once loaded.
+type Validator interface {
+	Validate() error
+}
+
+type Option func(*options)
+
+type options struct {
+	files     []string
+	envPrefix string
+	lookupEnv func(key string) (string, bool)
+}
+
+// WithFiles reads the given YAML (.yaml, .yml) or JSON (.json) files in order, later
+// files overriding earlier ones. Missing files are an error.
+func WithFiles(paths ...string) Option {
+	return func(o *options) {
+		o.files = append(o.files, paths...)
+	}
+}
+
+// WithEnvPrefix prepends prefix to every env var name, e.g. to load several
+// instances of the same config.
+func WithEnvPrefix(prefix string) Option {
+	return func(o *options) {
+		o.envPrefix = prefix
+	}
+}
+
+// WithLookupEnv replaces os.LookupEnv, mainly for tests.
+func WithLookupEnv(lookup func(key string) (string, bool)) Option {
+	return func(o *options) {
+		o.lookupEnv = lookup
+	}
+}
+
+// Load fills the struct pointed to by cfg from, in increasing precedence:
+//   - `default:"..."` field tags,
+//   - the files given by WithFiles, decoded with their `yaml` or `json` tags,
+//   - the env vars named by `env:"..."` field tags.
+//
+// Fields tagged `required:"true"` must end up non-zero, and cfg is validated last
+// if it implements Validator. Nested structs are walked recursively.
+func Load(cfg any, opts ...Option) error {
+	o := options{lookupEnv: os.LookupEnv}
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	v := reflect.ValueOf(cfg)
+	if v.Kind() != reflect.Pointer || v.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("conf: cfg must be a pointer to a struct, got %T", cfg)
+	}
+
+	if err := walk(v.Elem(), "", func(f reflect.Value, sf reflect.StructField, path string) error {
+		if def, ok := sf.Tag.Lookup("default"); ok && f.IsZero() {
+			if err := setString(f, def); err != nil {
+				return fmt.Errorf("conf: default of %s: %w", path, err)
+			}
+		}
+		return nil
+	}); err != nil {
+		return err
+	}
+
+	for _, file := range o.files {
+		if err := loadFile(file, cfg); err != nil {
+			return err
+		}
+	}
+
+	var missing []string
+	if err := walk(v.Elem(), "", func(f reflect.Value, sf reflect.StructField, path string) error {
+		if name, ok := sf.Tag.Lookup("env"); ok && name != "" {
+			if s, ok := o.lookupEnv(o.envPrefix + name); ok {
+				if err := setString(f, s); err != nil {
+					return fmt.Errorf("conf: env %s%s: %w", o.envPrefix, name, err)
+				}
+			}
+		}
+		if sf.Tag.Get("required") == "true" && f.IsZero() {
+			missing = append(missing, path)
+		}
+		return nil
+	}); err != nil {
+		return err
+	}
+	if len(missing) > 0 {
+		return fmt.Errorf("conf: missing required fields %s", strings.Join(missing, ", "))
+	}
+
+	if validator, ok := cfg.(Validator); ok {
+		return validator.Validate()
+	}
+	return nil
+}
+
+func loadFile(path string, cfg any) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("conf: %w", err)
+	}
+
+	switch ext := strings.ToLower(filepath.Ext(path)); ext {
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(data, cfg)
+	case ".json":
+		err = sonic.Unmarshal(data, cfg)
+	default:
+		return fmt.Errorf("conf: unsupported file type %q of %s", ext, path)
+	}
+	if err != nil {
+		return fmt.Errorf("conf: decode %s: %w", path, err)
+	}
+	return nil
+}
+
+// walk calls fn for every settable leaf field of the struct v, recursing into nested structs.
+func walk(v reflect.Value, prefix string, fn func(f reflect.Value, sf reflect.StructField, path string) error) error {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		sf, f := t.Field(i), v.Field(i)
+		if !sf.IsExported() {
+			continue
+		}
+
+		path := prefix + sf.Name
+		if f.Kind() == reflect.Struct && f.Type() != reflect.TypeOf(time.Time{}) {
+			if err := walk(f, path+".", fn); err != nil {
+				return err
+			}
+			continue
+		}
+		if err := fn(f, sf, path); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+var durationType = reflect.TypeOf(time.Duration(0))
+
+// setString parses s into f according to its type. Slices are comma separated.
+func setString(f reflect.Value, s string) error {
+	if f.Type() == durationType {
+		d, err := time.ParseDuration(s)
+		if err != nil {
+			return err
+		}
+		f.SetInt(int64(d))
+		return nil
+	}
+
+	switch f.Kind() {
+	case reflect.String:
+		f.SetString(s)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(s)
+		if err != nil {
+			return err
+		}
+		f.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(s, 10, f.Type().Bits())
+		if err != nil {
+			return err
+		}
+		f.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := strconv.ParseUint(s, 10, f.Type().Bits())
+		if err != nil {
+			return err
+		}
+		f.SetUint(n)
+	case reflect.Float32, reflect.Float64:
+		n, err := strconv.ParseFloat(s, f.Type().Bits())
+		if err != nil {
+			return err
+		}
+		f.SetFloat(n)
+	case reflect.Slice:
+		parts := strings.Split(s, ",")
+		slice := reflect.MakeSlice(f.Type(), len(parts), len(parts))
+		for i, part := range parts {
+			if err := setString(slice.Index(i), strings.TrimSpace(part)); err != nil {
+				return err
+			}
+		}
+		f.Set(slice)
+	default:
+		return errors.New("unsupported field type " + f.Type().String())
+	}
+	return nil
+}
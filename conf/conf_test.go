@@ -0,0 +1,85 @@
+package conf
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type testConfig struct {
+	Name    string        `yaml:"name" json:"name" env:"NAME" required:"true"`
+	Port    int           `yaml:"port" json:"port" env:"PORT" default:"8080"`
+	Timeout time.Duration `yaml:"timeout" json:"timeout" env:"TIMEOUT" default:"1s"`
+	Hosts   []string      `yaml:"hosts" json:"hosts" env:"HOSTS"`
+	DB      struct {
+		DSN string `yaml:"dsn" json:"dsn" env:"DB_DSN"`
+	} `yaml:"db" json:"db"`
+}
+
+func (c *testConfig) Validate() error {
+	if c.Port <= 0 {
+		return errors.New("port must be positive")
+	}
+	return nil
+}
+
+func env(m map[string]string) Option {
+	return WithLookupEnv(func(key string) (string, bool) {
+		v, ok := m[key]
+		return v, ok
+	})
+}
+
+func TestLoad(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "app.yaml")
+	assert.NoError(t, os.WriteFile(file, []byte("name: file\nport: 9000\ndb:\n  dsn: file-dsn\n"), 0o600))
+
+	var cfg testConfig
+	err := Load(&cfg, WithFiles(file), WithEnvPrefix("APP_"), env(map[string]string{
+		"APP_NAME":  "env",
+		"APP_HOSTS": "a, b",
+	}))
+	assert.NoError(t, err)
+	assert.Equal(t, "env", cfg.Name)
+	assert.Equal(t, 9000, cfg.Port)
+	assert.Equal(t, time.Second, cfg.Timeout)
+	assert.Equal(t, []string{"a", "b"}, cfg.Hosts)
+	assert.Equal(t, "file-dsn", cfg.DB.DSN)
+
+	err = Load(&testConfig{}, env(nil))
+	assert.ErrorContains(t, err, "missing required fields Name")
+
+	err = Load(&testConfig{}, env(map[string]string{"NAME": "x", "PORT": "-1"}))
+	assert.ErrorContains(t, err, "port must be positive")
+
+	err = Load(&testConfig{}, env(map[string]string{"NAME": "x", "PORT": "abc"}))
+	assert.ErrorContains(t, err, "env PORT")
+}
+
+func TestWatch(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "app.json")
+	assert.NoError(t, os.WriteFile(file, []byte(`{"name":"v1"}`), 0o600))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	changes := make(chan string, 1)
+	go Watch(ctx, 5*time.Millisecond, func(cfg *testConfig) { changes <- cfg.Name }, WithFiles(file), env(nil))
+
+	time.Sleep(20 * time.Millisecond)
+	assert.NoError(t, os.WriteFile(file, []byte(`{"name":"v2"}`), 0o600))
+	assert.NoError(t, os.Chtimes(file, time.Now(), time.Now().Add(time.Second)))
+
+	select {
+	case name := <-changes:
+		assert.Equal(t, "v2", name)
+	case <-time.After(time.Second):
+		t.Fatal("config not reloaded")
+	}
+}
@@ -0,0 +1,67 @@
+package conf
+
+import (
+	"context"
+	"os"
+	"time"
+
+	"github.com/me2seeks/forge/logs"
+)
+
+// Watch reloads a config of type T whenever one of the files given by WithFiles
+// changes, polling their modification times every interval until ctx is done.
+// onChange receives each successfully reloaded config, failed reloads are logged
+// and the previous config stays in effect. Watch blocks, run it in a goroutine.
+func Watch[T any](ctx context.Context, interval time.Duration, onChange func(cfg *T), opts ...Option) {
+	o := options{}
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	modTimes := func() map[string]time.Time {
+		m := make(map[string]time.Time, len(o.files))
+		for _, file := range o.files {
+			if info, err := os.Stat(file); err == nil {
+				m[file] = info.ModTime()
+			}
+		}
+		return m
+	}
+
+	last := modTimes()
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		current := modTimes()
+		if equalModTimes(last, current) {
+			continue
+		}
+		last = current
+
+		cfg := new(T)
+		if err := Load(cfg, opts...); err != nil {
+			logs.CtxWarnf(ctx, "[conf] reload failed, keeping previous config: %v", err)
+			continue
+		}
+		onChange(cfg)
+	}
+}
+
+func equalModTimes(a, b map[string]time.Time) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for k, v := range a {
+		if !b[k].Equal(v) {
+			return false
+		}
+	}
+	return true
+}
@@ -1,25 +1,101 @@
 package ctxcache
 
 import (
+	"container/list"
 	"context"
 	"sync"
+	"sync/atomic"
+	"time"
 )
 
 type ctxCacheKey struct{}
 
-func Init(ctx context.Context) context.Context {
-	return context.WithValue(ctx, ctxCacheKey{}, new(sync.Map))
+type namespaceKey struct{}
+
+// scopedKey is the key actually stored for key inside namespace ns.
+type scopedKey struct {
+	ns  string
+	key any
 }
 
-func Get[T any](ctx context.Context, key any) (value T, ok bool) {
-	var zero T
+// entry wraps a stored value with its optional expiry.
+type entry struct {
+	key      any
+	value    any
+	expireAt time.Time
+}
 
-	cacheMap, valid := ctx.Value(ctxCacheKey{}).(*sync.Map)
-	if !valid {
-		return zero, false
+func (e *entry) expired(now time.Time) bool {
+	return !e.expireAt.IsZero() && !now.Before(e.expireAt)
+}
+
+// cache is the per-context store. Entries are kept in recency order so the least
+// recently used one can be evicted when maxEntries is reached.
+type cache struct {
+	mu    sync.RWMutex
+	items map[any]*list.Element
+	order *list.List
+
+	maxEntries int
+	hooks      Hooks
+
+	hits      atomic.Uint64
+	misses    atomic.Uint64
+	evictions atomic.Uint64
+}
+
+// Stats is a snapshot of the counters of a context's cache.
+type Stats struct {
+	Len       int
+	Hits      uint64
+	Misses    uint64
+	Evictions uint64
+}
+
+type Option func(*cache)
+
+// WithMaxEntries bounds the cache to n entries, evicting the least recently used
+// one beyond that. n <= 0 means unbounded, the default.
+func WithMaxEntries(n int) Option {
+	return func(c *cache) {
+		c.maxEntries = n
+	}
+}
+
+// Hooks are optional callbacks for instrumenting a cache. They run after the
+// operation, without the cache lock held. key is the key as passed by the caller.
+type Hooks struct {
+	OnStore func(ctx context.Context, key, value any)
+	OnHit   func(ctx context.Context, key any)
+	OnMiss  func(ctx context.Context, key any)
+}
+
+// WithHooks installs hooks on the cache, e.g. to log why a value isn't found.
+func WithHooks(hooks Hooks) Option {
+	return func(c *cache) {
+		c.hooks = hooks
+	}
+}
+
+func newCache(opts []Option) *cache {
+	c := &cache{
+		items: make(map[any]*list.Element),
+		order: list.New(),
 	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+func Init(ctx context.Context, opts ...Option) context.Context {
+	return context.WithValue(ctx, ctxCacheKey{}, newCache(opts))
+}
 
-	loadedValue, exists := cacheMap.Load(key)
+func Get[T any](ctx context.Context, key any) (value T, ok bool) {
+	var zero T
+
+	loadedValue, exists := load(ctx, key)
 	if !exists {
 		return zero, false
 	}
@@ -32,16 +108,209 @@ func Get[T any](ctx context.Context, key any) (value T, ok bool) {
 }
 
 func Store(ctx context.Context, key any, obj any) {
-	if cacheMap, ok := ctx.Value(ctxCacheKey{}).(*sync.Map); ok {
-		cacheMap.Store(key, obj)
+	StoreWithTTL(ctx, key, obj, 0)
+}
+
+// StoreWithTTL stores obj under key until ttl elapses, after which Get and HasKey
+// no longer see it. A ttl <= 0 means the entry never expires.
+func StoreWithTTL(ctx context.Context, key any, obj any, ttl time.Duration) {
+	c, ok := fromContext(ctx)
+	if !ok {
+		return
+	}
+
+	e := &entry{key: scope(ctx, key), value: obj}
+	if ttl > 0 {
+		e.expireAt = time.Now().Add(ttl)
+	}
+	c.store(e)
+
+	if c.hooks.OnStore != nil {
+		c.hooks.OnStore(ctx, key, obj)
 	}
 }
 
 func HasKey(ctx context.Context, key any) bool {
-	if cacheMap, ok := ctx.Value(ctxCacheKey{}).(*sync.Map); ok {
-		_, ok := cacheMap.Load(key)
-		return ok
+	_, ok := load(ctx, key)
+	return ok
+}
+
+// Len returns the number of entries in the cache of ctx, including expired ones
+// not yet collected.
+func Len(ctx context.Context) int {
+	c, ok := fromContext(ctx)
+	if !ok {
+		return 0
+	}
+
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.order.Len()
+}
+
+// GetStats returns the counters of the cache of ctx, for debugging.
+func GetStats(ctx context.Context) Stats {
+	c, ok := fromContext(ctx)
+	if !ok {
+		return Stats{}
+	}
+
+	return Stats{
+		Len:       Len(ctx),
+		Hits:      c.hits.Load(),
+		Misses:    c.misses.Load(),
+		Evictions: c.evictions.Load(),
+	}
+}
+
+func fromContext(ctx context.Context) (*cache, bool) {
+	c, ok := ctx.Value(ctxCacheKey{}).(*cache)
+	return c, ok
+}
+
+// load returns the live value of key, dropping it if it has expired.
+func load(ctx context.Context, key any) (any, bool) {
+	c, ok := fromContext(ctx)
+	if !ok {
+		return nil, false
+	}
+
+	value, ok := c.load(scope(ctx, key))
+	if ok && c.hooks.OnHit != nil {
+		c.hooks.OnHit(ctx, key)
+	} else if !ok && c.hooks.OnMiss != nil {
+		c.hooks.OnMiss(ctx, key)
+	}
+	return value, ok
+}
+
+// load returns the live value of key. Only a bounded cache tracks recency, the
+// others serve reads under the read lock.
+func (c *cache) load(key any) (any, bool) {
+	var e *entry
+	if c.maxEntries > 0 {
+		c.mu.Lock()
+		if el, ok := c.items[key]; ok {
+			e = el.Value.(*entry)
+			c.order.MoveToFront(el)
+		}
+		c.mu.Unlock()
+	} else {
+		c.mu.RLock()
+		if el, ok := c.items[key]; ok {
+			e = el.Value.(*entry)
+		}
+		c.mu.RUnlock()
+	}
+
+	if e == nil {
+		c.misses.Add(1)
+		return nil, false
+	}
+	if e.expired(time.Now()) {
+		c.drop(e)
+		c.misses.Add(1)
+		return nil, false
+	}
+
+	c.hits.Add(1)
+	return e.value, true
+}
+
+// drop removes the expired entry e, unless it was replaced meanwhile.
+func (c *cache) drop(e *entry) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if el, ok := c.items[e.key]; ok && el.Value == e {
+		c.remove(el)
+	}
+}
+
+func (c *cache) store(e *entry) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if el, ok := c.items[e.key]; ok {
+		el.Value = e
+		c.order.MoveToFront(el)
+		return
+	}
+
+	c.items[e.key] = c.order.PushFront(e)
+	if c.maxEntries > 0 && c.order.Len() > c.maxEntries {
+		c.remove(c.order.Back())
+		c.evictions.Add(1)
+	}
+}
+
+func (c *cache) remove(el *list.Element) {
+	e := c.order.Remove(el).(*entry)
+	delete(c.items, e.key)
+}
+
+// clone copies the live entries of c, keeping their recency order, into a new cache
+// with the same options.
+func (c *cache) clone() *cache {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	copied := &cache{
+		items:      make(map[any]*list.Element, len(c.items)),
+		order:      list.New(),
+		maxEntries: c.maxEntries,
+		hooks:      c.hooks,
+	}
+
+	now := time.Now()
+	for el := c.order.Front(); el != nil; el = el.Next() {
+		if e := el.Value.(*entry); !e.expired(now) {
+			copied.items[e.key] = copied.order.PushBack(e)
+		}
+	}
+	return copied
+}
+
+// WithNamespace returns a view of the cache of ctx in which keys live in namespace ns,
+// so they can't collide with the same keys stored by other namespaces or without one.
+// Namespaces nest: a namespace derived from a namespaced ctx is scoped under it.
+func WithNamespace(ctx context.Context, ns string) context.Context {
+	if parent, ok := ctx.Value(namespaceKey{}).(string); ok {
+		ns = parent + "/" + ns
+	}
+	return context.WithValue(ctx, namespaceKey{}, ns)
+}
+
+func scope(ctx context.Context, key any) any {
+	if ns, ok := ctx.Value(namespaceKey{}).(string); ok {
+		return scopedKey{ns: ns, key: key}
 	}
+	return key
+}
+
+// Detach returns a context for background work started from ctx: it keeps the values
+// of ctx but not its deadline or cancellation, and carries a copy of its cache, so
+// later stores on either side are not seen by the other.
+func Detach(ctx context.Context) context.Context {
+	detached := context.WithoutCancel(ctx)
 
-	return false
+	c, ok := fromContext(ctx)
+	if !ok {
+		return detached
+	}
+	return context.WithValue(detached, ctxCacheKey{}, c.clone())
+}
+
+// Forward returns dst carrying a copy of the cache of src and its namespace, or
+// dst itself if src has no cache.
+func Forward(dst, src context.Context) context.Context {
+	c, ok := fromContext(src)
+	if !ok {
+		return dst
+	}
+	dst = context.WithValue(dst, ctxCacheKey{}, c.clone())
+	if ns, ok := src.Value(namespaceKey{}).(string); ok {
+		dst = context.WithValue(dst, namespaceKey{}, ns)
+	}
+	return dst
 }
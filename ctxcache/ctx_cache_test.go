@@ -2,8 +2,10 @@ package ctxcache
 
 import (
 	"context"
+	"fmt"
 	"reflect"
 	"testing"
+	"time"
 
 	. "github.com/onsi/gomega"
 )
@@ -54,3 +56,103 @@ func TestCtxCache(t *testing.T) {
 	g.Expect(ok).Should(BeTrue())
 	g.Expect(reflect.DeepEqual(te, newT)).Should(BeTrue())
 }
+
+func TestCtxCacheTTL(t *testing.T) {
+	g := NewGomegaWithT(t)
+	ctx := Init(context.Background())
+
+	StoreWithTTL(ctx, "url", "https://example.com/presigned", 20*time.Millisecond)
+	StoreWithTTL(ctx, "forever", 1, 0)
+
+	url, ok := Get[string](ctx, "url")
+	g.Expect(ok).Should(BeTrue())
+	g.Expect(url).Should(Equal("https://example.com/presigned"))
+
+	g.Eventually(func() bool { return HasKey(ctx, "url") }).Should(BeFalse())
+	g.Expect(HasKey(ctx, "forever")).Should(BeTrue())
+}
+
+func TestTypedKeyAndNamespace(t *testing.T) {
+	g := NewGomegaWithT(t)
+	ctx := Init(context.Background())
+
+	userID := NewKey[int64]("user_id")
+	other := NewKey[int64]("user_id")
+	userID.Store(ctx, 42)
+
+	id, ok := userID.Get(ctx)
+	g.Expect(ok).Should(BeTrue())
+	g.Expect(id).Should(Equal(int64(42)))
+	g.Expect(other.Has(ctx)).Should(BeFalse())
+	g.Expect(other.GetOr(ctx, -1)).Should(Equal(int64(-1)))
+
+	es := WithNamespace(ctx, "es")
+	graph := WithNamespace(ctx, "graph")
+	Store(es, "client", "es")
+	Store(graph, "client", "graph")
+
+	v, _ := Get[string](es, "client")
+	g.Expect(v).Should(Equal("es"))
+	v, _ = Get[string](graph, "client")
+	g.Expect(v).Should(Equal("graph"))
+	g.Expect(HasKey(ctx, "client")).Should(BeFalse())
+	g.Expect(HasKey(WithNamespace(es, "sub"), "client")).Should(BeFalse())
+}
+
+func TestDetach(t *testing.T) {
+	g := NewGomegaWithT(t)
+	ctx, cancel := context.WithCancel(Init(context.Background()))
+	Store(ctx, "token", "abc")
+
+	detached := Detach(ctx)
+	cancel()
+	g.Expect(detached.Err()).Should(Succeed())
+
+	token, ok := Get[string](detached, "token")
+	g.Expect(ok).Should(BeTrue())
+	g.Expect(token).Should(Equal("abc"))
+
+	Store(detached, "background", 1)
+	Store(ctx, "request", 1)
+	g.Expect(HasKey(ctx, "background")).Should(BeFalse())
+	g.Expect(HasKey(detached, "request")).Should(BeFalse())
+}
+
+func TestMaxEntries(t *testing.T) {
+	g := NewGomegaWithT(t)
+	ctx := Init(context.Background(), WithMaxEntries(2))
+
+	Store(ctx, "a", 1)
+	Store(ctx, "b", 2)
+	_, _ = Get[int](ctx, "a")
+	Store(ctx, "c", 3) // evicts b, the least recently used
+
+	g.Expect(HasKey(ctx, "a")).Should(BeTrue())
+	g.Expect(HasKey(ctx, "b")).Should(BeFalse())
+	g.Expect(HasKey(ctx, "c")).Should(BeTrue())
+	g.Expect(GetStats(ctx)).Should(Equal(Stats{Len: 2, Hits: 3, Misses: 1, Evictions: 1}))
+	g.Expect(Len(Detach(ctx))).Should(Equal(2))
+}
+
+func TestHooksAndDump(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	var events []string
+	ctx := Init(context.Background(), WithHooks(Hooks{
+		OnStore: func(_ context.Context, key, _ any) { events = append(events, fmt.Sprint("store ", key)) },
+		OnHit:   func(_ context.Context, key any) { events = append(events, fmt.Sprint("hit ", key)) },
+		OnMiss:  func(_ context.Context, key any) { events = append(events, fmt.Sprint("miss ", key)) },
+	}))
+
+	Store(ctx, "a", 1)
+	_, _ = Get[int](ctx, "a")
+	_, _ = Get[int](ctx, "b")
+	Store(WithNamespace(ctx, "es"), "client", "c")
+	g.Expect(events).Should(Equal([]string{"store a", "hit a", "miss b", "store client"}))
+
+	g.Expect(Dump(ctx)).Should(Equal([]EntryInfo{
+		{Namespace: "es", Key: "client", KeyType: "string", ValueType: "string"},
+		{Key: "a", KeyType: "string", ValueType: "int"},
+	}))
+	g.Expect(Dump(ctx)[0].String()).Should(Equal("es:client(string) => string"))
+}
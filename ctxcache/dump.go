@@ -0,0 +1,69 @@
+package ctxcache
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+)
+
+// EntryInfo describes a cached entry for debugging, without exposing its value.
+type EntryInfo struct {
+	Namespace string
+	Key       string
+	KeyType   string
+	ValueType string
+	ExpireAt  time.Time
+}
+
+func (e EntryInfo) String() string {
+	var sb strings.Builder
+	if e.Namespace != "" {
+		sb.WriteString(e.Namespace)
+		sb.WriteString(":")
+	}
+	fmt.Fprintf(&sb, "%s(%s) => %s", e.Key, e.KeyType, e.ValueType)
+	if !e.ExpireAt.IsZero() {
+		fmt.Fprintf(&sb, " expires %s", e.ExpireAt.Format(time.RFC3339Nano))
+	}
+	return sb.String()
+}
+
+// Dump lists the live entries of the cache of ctx from most to least recently used,
+// across all namespaces.
+func Dump(ctx context.Context) []EntryInfo {
+	c, ok := fromContext(ctx)
+	if !ok {
+		return nil
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := time.Now()
+	infos := make([]EntryInfo, 0, c.order.Len())
+	for el := c.order.Front(); el != nil; el = el.Next() {
+		e := el.Value.(*entry)
+		if e.expired(now) {
+			continue
+		}
+
+		key, info := e.key, EntryInfo{ExpireAt: e.expireAt}
+		if sk, ok := key.(scopedKey); ok {
+			key, info.Namespace = sk.key, sk.ns
+		}
+		info.Key = fmt.Sprint(key)
+		info.KeyType = typeName(key)
+		info.ValueType = typeName(e.value)
+		infos = append(infos, info)
+	}
+	return infos
+}
+
+func typeName(v any) string {
+	if v == nil {
+		return "<nil>"
+	}
+	return reflect.TypeOf(v).String()
+}
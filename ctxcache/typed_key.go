@@ -0,0 +1,45 @@
+package ctxcache
+
+import (
+	"context"
+	"time"
+)
+
+// TypedKey is a cache key bound to the type of its value. Keys are compared by
+// identity, so two keys created by NewKey never collide, even with the same name.
+type TypedKey[T any] struct {
+	name string
+}
+
+// NewKey creates a key for values of type T. name is only used for debugging.
+func NewKey[T any](name string) *TypedKey[T] {
+	return &TypedKey[T]{name: name}
+}
+
+func (k *TypedKey[T]) String() string {
+	return k.name
+}
+
+func (k *TypedKey[T]) Get(ctx context.Context) (T, bool) {
+	return Get[T](ctx, k)
+}
+
+// GetOr returns the cached value of k, or def if there is none.
+func (k *TypedKey[T]) GetOr(ctx context.Context, def T) T {
+	if v, ok := k.Get(ctx); ok {
+		return v
+	}
+	return def
+}
+
+func (k *TypedKey[T]) Store(ctx context.Context, value T) {
+	Store(ctx, k, value)
+}
+
+func (k *TypedKey[T]) StoreWithTTL(ctx context.Context, value T, ttl time.Duration) {
+	StoreWithTTL(ctx, k, value, ttl)
+}
+
+func (k *TypedKey[T]) Has(ctx context.Context) bool {
+	return HasKey(ctx, k)
+}
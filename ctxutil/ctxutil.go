@@ -0,0 +1,162 @@
+// Package ctxutil derives contexts for work that must not share the fate of
+// the request it starts from.
+//
+// Background work started from a request must not reuse the request context,
+// or it is canceled when the response is written. Detach keeps every value of
+// the request without its cancellation. Forward starts from a clean context
+// and only carries the chosen values, such as the log ID, the ctxcache and the
+// trace, so a long-lived worker doesn't pin everything the request held.
+package ctxutil
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/me2seeks/forge/ctxcache"
+	"github.com/me2seeks/forge/logs"
+)
+
+// Detach returns a context keeping the values of ctx but not its deadline or
+// cancellation, with a copy of its ctxcache so stores on either side aren't
+// seen by the other.
+func Detach(ctx context.Context) context.Context {
+	return ctxcache.Detach(ctx)
+}
+
+// DetachWithTimeout is Detach bounded by timeout from now.
+func DetachWithTimeout(ctx context.Context, timeout time.Duration) (context.Context, context.CancelFunc) {
+	return context.WithTimeout(Detach(ctx), timeout)
+}
+
+// Forwarder copies some values of src into dst.
+type Forwarder func(dst, src context.Context) context.Context
+
+var (
+	// LogID forwards the log context set by logs.SetContext.
+	LogID Forwarder = logs.ForwardContext
+	// CtxCache forwards a copy of the ctxcache and its namespace.
+	CtxCache Forwarder = ctxcache.Forward
+	// Trace forwards the span context, so spans started from dst join the trace of src.
+	Trace Forwarder = func(dst, src context.Context) context.Context {
+		sc := trace.SpanContextFromContext(src)
+		if !sc.IsValid() {
+			return dst
+		}
+		return trace.ContextWithSpanContext(dst, sc)
+	}
+)
+
+// DefaultForwarders are the forwarders used by Forward when none is given.
+var DefaultForwarders = []Forwarder{LogID, CtxCache, Trace}
+
+// Key forwards the value stored under key, e.g. an auth principal.
+func Key(key any) Forwarder {
+	return func(dst, src context.Context) context.Context {
+		v := src.Value(key)
+		if v == nil {
+			return dst
+		}
+		return context.WithValue(dst, key, v)
+	}
+}
+
+// Forward returns dst carrying the values of src selected by forwarders, or by
+// DefaultForwarders if none is given.
+func Forward(dst, src context.Context, forwarders ...Forwarder) context.Context {
+	if len(forwarders) == 0 {
+		forwarders = DefaultForwarders
+	}
+	for _, f := range forwarders {
+		dst = f(dst, src)
+	}
+	return dst
+}
+
+// Background returns a new background context carrying the values of src
+// selected by forwarders, see Forward.
+func Background(src context.Context, forwarders ...Forwarder) context.Context {
+	return Forward(context.Background(), src, forwarders...)
+}
+
+// merged is done when either of its parents is, and looks values up in a then b.
+type merged struct {
+	context.Context // derived from a, canceled when b is done
+	a, b            context.Context
+}
+
+// Merge returns a context done as soon as a or b is, with the earliest of
+// their deadlines, whose values are looked up in a then b. The cause of the
+// first parent done is the cause of the merged context. Cancel releases the
+// resources held to watch b.
+func Merge(a, b context.Context) (context.Context, context.CancelFunc) {
+	ctx, cancel := context.WithCancelCause(a)
+	stop := context.AfterFunc(b, func() {
+		cancel(context.Cause(b))
+	})
+	return &merged{Context: ctx, a: a, b: b}, func() {
+		stop()
+		cancel(context.Canceled)
+	}
+}
+
+func (m *merged) Deadline() (time.Time, bool) {
+	da, okA := m.a.Deadline()
+	db, okB := m.b.Deadline()
+	switch {
+	case okA && okB:
+		if db.Before(da) {
+			return db, true
+		}
+		return da, true
+	case okB:
+		return db, true
+	default:
+		return da, okA
+	}
+}
+
+func (m *merged) Err() error {
+	err := m.Context.Err()
+	if err == nil {
+		return nil
+	}
+	// Canceled because b is done: report b's error, which may be a deadline.
+	if m.a.Err() == nil {
+		if errB := m.b.Err(); errB != nil {
+			return errB
+		}
+	}
+	return err
+}
+
+func (m *merged) Value(key any) any {
+	if v := m.Context.Value(key); v != nil {
+		return v
+	}
+	return m.b.Value(key)
+}
+
+// TimeoutError is the cause of contexts from WithTimeoutCause. It matches
+// context.DeadlineExceeded with errors.Is.
+type TimeoutError struct {
+	Op      string
+	Timeout time.Duration
+}
+
+func (e *TimeoutError) Error() string {
+	return fmt.Sprintf("%s timed out after %v", e.Op, e.Timeout)
+}
+
+func (e *TimeoutError) Is(target error) bool {
+	return target == context.DeadlineExceeded
+}
+
+// WithTimeoutCause is context.WithTimeout whose cause, from context.Cause,
+// names the operation op that timed out instead of the bare
+// context.DeadlineExceeded.
+func WithTimeoutCause(ctx context.Context, timeout time.Duration, op string) (context.Context, context.CancelFunc) {
+	return context.WithTimeoutCause(ctx, timeout, &TimeoutError{Op: op, Timeout: timeout})
+}
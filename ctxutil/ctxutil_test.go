@@ -0,0 +1,82 @@
+package ctxutil
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/me2seeks/forge/ctxcache"
+	"github.com/me2seeks/forge/logs"
+)
+
+type (
+	principalKey struct{}
+	bodyKey      struct{}
+	k            struct{}
+)
+
+func TestForward(t *testing.T) {
+	req := logs.SetContext(context.Background(), "log-1")
+	req = ctxcache.Init(req)
+	ctxcache.Store(req, "user", "u1")
+	req = context.WithValue(req, principalKey{}, "alice")
+	req = context.WithValue(req, bodyKey{}, "large")
+	req, cancel := context.WithCancel(req)
+	cancel()
+
+	bg := Background(req)
+	assert.NoError(t, bg.Err())
+	assert.Equal(t, "log-1", logs.LogID(bg))
+	user, _ := ctxcache.Get[string](bg, "user")
+	assert.Equal(t, "u1", user)
+	assert.Nil(t, bg.Value(principalKey{}))
+	assert.Nil(t, bg.Value(bodyKey{}))
+
+	bg = Background(req, Key(principalKey{}))
+	assert.Equal(t, "alice", bg.Value(principalKey{}))
+	assert.Empty(t, logs.LogID(bg))
+
+	detached := Detach(req)
+	assert.NoError(t, detached.Err())
+	assert.Equal(t, "large", detached.Value(bodyKey{}))
+}
+
+func TestMerge(t *testing.T) {
+	a := context.WithValue(context.Background(), principalKey{}, "alice")
+	b, cancelB := context.WithTimeout(context.WithValue(context.Background(), k{}, "v"), 10*time.Millisecond)
+	defer cancelB()
+
+	ctx, cancel := Merge(a, b)
+	defer cancel()
+	assert.Equal(t, "alice", ctx.Value(principalKey{}))
+	assert.Equal(t, "v", ctx.Value(k{}))
+	deadline, ok := ctx.Deadline()
+	assert.True(t, ok)
+	assert.WithinDuration(t, time.Now().Add(10*time.Millisecond), deadline, 10*time.Millisecond)
+
+	<-ctx.Done()
+	assert.ErrorIs(t, ctx.Err(), context.DeadlineExceeded)
+
+	a2, cancelA := context.WithCancelCause(context.Background())
+	ctx, cancel = Merge(a2, context.Background())
+	defer cancel()
+	cancelA(errors.New("shutdown"))
+	<-ctx.Done()
+	assert.ErrorIs(t, ctx.Err(), context.Canceled)
+	assert.EqualError(t, context.Cause(ctx), "shutdown")
+}
+
+func TestWithTimeoutCause(t *testing.T) {
+	ctx, cancel := WithTimeoutCause(context.Background(), time.Millisecond, "graph import")
+	defer cancel()
+	<-ctx.Done()
+
+	cause := context.Cause(ctx)
+	assert.EqualError(t, cause, "graph import timed out after 1ms")
+	assert.ErrorIs(t, cause, context.DeadlineExceeded)
+	var te *TimeoutError
+	assert.True(t, errors.As(cause, &te))
+}
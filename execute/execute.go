@@ -4,19 +4,26 @@ import (
 	"context"
 	"fmt"
 	"runtime/debug"
+	"time"
+
+	"github.com/me2seeks/forge/retry"
 )
 
-func RunWithContextDone(ctx context.Context, fn func() error) error {
+// RunWithContextDone runs fn in a new goroutine and returns its error, or ctx.Err()
+// if ctx is done first. fn receives a context cancelled as soon as RunWithContextDone
+// returns, so it must observe ctx to avoid outliving the call.
+func RunWithContextDone(ctx context.Context, fn func(ctx context.Context) error) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
 	errChan := make(chan error, 1)
 	go func() {
 		defer func() {
 			if err := recover(); err != nil {
 				errChan <- fmt.Errorf("exec func panic, %v \n %s", err, debug.Stack())
 			}
-			close(errChan)
 		}()
-		err := fn()
-		errChan <- err
+		errChan <- fn(ctx)
 	}()
 
 	select {
@@ -26,3 +33,19 @@ func RunWithContextDone(ctx context.Context, fn func() error) error {
 		return err
 	}
 }
+
+// RunWithTimeout is RunWithContextDone with a deadline of timeout from now.
+func RunWithTimeout(ctx context.Context, timeout time.Duration, fn func(ctx context.Context) error) error {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	return RunWithContextDone(ctx, fn)
+}
+
+// RunWithRetry calls fn under policy, see retry.Run, each call running through
+// RunWithContextDone: a call outliving its ctx, e.g. past policy.AttemptTimeout,
+// is abandoned, and a panic of fn is returned as an error.
+func RunWithRetry(ctx context.Context, policy retry.Policy, fn func(ctx context.Context) error) error {
+	return retry.Run(ctx, policy, func(ctx context.Context) error {
+		return RunWithContextDone(ctx, fn)
+	})
+}
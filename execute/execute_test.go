@@ -0,0 +1,92 @@
+package execute
+
+import (
+	"context"
+	"errors"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/me2seeks/forge/retry"
+)
+
+func TestRunWithTimeout(t *testing.T) {
+	before := runtime.NumGoroutine()
+
+	err := RunWithTimeout(context.Background(), 10*time.Millisecond, func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	})
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+
+	err = RunWithContextDone(context.Background(), func(context.Context) error { panic("boom") })
+	assert.ErrorContains(t, err, "boom")
+
+	// the worker goroutines must have exited once their context was cancelled.
+	deadline := time.Now().Add(time.Second)
+	for runtime.NumGoroutine() > before && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	assert.LessOrEqual(t, runtime.NumGoroutine(), before)
+}
+
+func TestRunWithRetry(t *testing.T) {
+	errTemporary := errors.New("temporary")
+	errFatal := errors.New("fatal")
+
+	calls := 0
+	err := RunWithRetry(context.Background(), retry.Policy{MaxAttempts: 3}, func(context.Context) error {
+		calls++
+		if calls < 3 {
+			return errTemporary
+		}
+		return nil
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, 3, calls)
+
+	calls = 0
+	err = RunWithRetry(context.Background(), retry.Policy{
+		MaxAttempts: 5,
+		Backoff:     retry.Exponential(time.Millisecond, 4*time.Millisecond),
+		Retryable:   func(err error) bool { return !errors.Is(err, errFatal) },
+	}, func(context.Context) error {
+		calls++
+		if calls == 2 {
+			return errFatal
+		}
+		return errTemporary
+	})
+	assert.ErrorIs(t, err, errFatal)
+	assert.Equal(t, 2, calls)
+
+	calls = 0
+	err = RunWithRetry(context.Background(), retry.Policy{MaxAttempts: 2}, func(context.Context) error {
+		calls++
+		panic("boom")
+	})
+	assert.ErrorContains(t, err, "boom")
+	assert.Equal(t, 2, calls)
+}
+
+func TestRunWithRetry_NoLeak(t *testing.T) {
+	before := runtime.NumGoroutine()
+
+	// The calls ignore their ctx, so each is abandoned once it times out.
+	release := make(chan struct{})
+	err := RunWithRetry(context.Background(), retry.Policy{MaxAttempts: 3, AttemptTimeout: 5 * time.Millisecond}, func(context.Context) error {
+		<-release
+		return nil
+	})
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	close(release)
+
+	// the abandoned calls must exit once they return.
+	deadline := time.Now().Add(time.Second)
+	for runtime.NumGoroutine() > before && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	assert.LessOrEqual(t, runtime.NumGoroutine(), before)
+}
@@ -0,0 +1,58 @@
+package flow
+
+import (
+	"sync"
+	"time"
+)
+
+// Debounce returns a function that delays calling fn until wait has elapsed since
+// its last invocation, fn then receives the argument of that last invocation.
+// stop cancels a pending call.
+func Debounce[T any](wait time.Duration, fn func(T)) (call func(T), stop func()) {
+	var (
+		mu    sync.Mutex
+		timer *time.Timer
+	)
+
+	call = func(arg T) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		if timer != nil {
+			timer.Stop()
+		}
+		timer = time.AfterFunc(wait, func() { fn(arg) })
+	}
+	stop = func() {
+		mu.Lock()
+		defer mu.Unlock()
+
+		if timer != nil {
+			timer.Stop()
+		}
+	}
+	return call, stop
+}
+
+// Throttle returns a function that calls fn at most once per interval, invocations
+// within the interval of the last accepted one are dropped. It reports whether fn was called.
+func Throttle[T any](interval time.Duration, fn func(T)) func(T) bool {
+	var (
+		mu   sync.Mutex
+		last time.Time
+	)
+
+	return func(arg T) bool {
+		mu.Lock()
+		now := time.Now()
+		if !last.IsZero() && now.Sub(last) < interval {
+			mu.Unlock()
+			return false
+		}
+		last = now
+		mu.Unlock()
+
+		fn(arg)
+		return true
+	}
+}
@@ -0,0 +1,61 @@
+package flow
+
+import (
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSingleflight(t *testing.T) {
+	var (
+		sf      Singleflight[string, int]
+		calls   atomic.Int32
+		release = make(chan struct{})
+		wg      sync.WaitGroup
+	)
+
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			v, err, _ := sf.Do("key", func() (int, error) {
+				calls.Add(1)
+				<-release
+				return 42, nil
+			})
+			assert.NoError(t, err)
+			assert.Equal(t, 42, v)
+		}()
+	}
+	time.Sleep(10 * time.Millisecond)
+	close(release)
+	wg.Wait()
+	assert.Equal(t, int32(1), calls.Load())
+
+	_, err, _ := sf.Do("panic", func() (int, error) { panic("boom") })
+	assert.ErrorContains(t, err, "boom")
+}
+
+func TestDebounceAndThrottle(t *testing.T) {
+	got := make(chan int, 10)
+	debounced, stop := Debounce(10*time.Millisecond, func(i int) { got <- i })
+	defer stop()
+	for i := 0; i < 5; i++ {
+		debounced(i)
+	}
+	select {
+	case v := <-got:
+		assert.Equal(t, 4, v)
+	case <-time.After(time.Second):
+		t.Fatal("debounced function not called")
+	}
+
+	calls := 0
+	throttled := Throttle(time.Hour, func(int) { calls++ })
+	assert.True(t, throttled(1))
+	assert.False(t, throttled(2))
+	assert.Equal(t, 1, calls)
+}
@@ -0,0 +1,71 @@
+package flow
+
+import (
+	"runtime/debug"
+	"sync"
+
+	"github.com/me2seeks/forge/safego"
+)
+
+// Singleflight deduplicates concurrent calls sharing the same key: while a call for
+// a key is in flight, later callers wait for it and receive its result.
+// The zero value is ready to use.
+type Singleflight[K comparable, V any] struct {
+	mu    sync.Mutex
+	calls map[K]*call[V]
+}
+
+type call[V any] struct {
+	wg    sync.WaitGroup
+	value V
+	err   error
+	dups  int
+}
+
+// Do calls fn for key unless a call for key is already in flight, in which case it
+// waits for that call. shared reports whether the result was given to several callers.
+// A panic in fn is returned as an error to every caller.
+func (s *Singleflight[K, V]) Do(key K, fn func() (V, error)) (value V, err error, shared bool) {
+	s.mu.Lock()
+	if s.calls == nil {
+		s.calls = make(map[K]*call[V])
+	}
+	if c, ok := s.calls[key]; ok {
+		c.dups++
+		s.mu.Unlock()
+		c.wg.Wait()
+		return c.value, c.err, true
+	}
+
+	c := &call[V]{}
+	c.wg.Add(1)
+	s.calls[key] = c
+	s.mu.Unlock()
+
+	func() {
+		defer func() {
+			if r := recover(); r != nil {
+				c.err = safego.NewPanicErr(r, debug.Stack())
+			}
+		}()
+		c.value, c.err = fn()
+	}()
+
+	s.mu.Lock()
+	if s.calls[key] == c {
+		delete(s.calls, key)
+	}
+	shared = c.dups > 0
+	s.mu.Unlock()
+	c.wg.Done()
+
+	return c.value, c.err, shared
+}
+
+// Forget drops the in-flight call for key, so the next Do starts a new call
+// instead of waiting for it.
+func (s *Singleflight[K, V]) Forget(key K) {
+	s.mu.Lock()
+	delete(s.calls, key)
+	s.mu.Unlock()
+}
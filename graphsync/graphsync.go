@@ -0,0 +1,330 @@
+// Package graphsync keeps es indexes in step with graph nodes. It polls each
+// mapped label for nodes changed since its checkpoint and bulk-writes their
+// projection to the mapped index.
+//
+// Nodes must carry their last change time in epoch milliseconds, "updated_at"
+// by default, and be soft-deleted by setting "deleted" to true: a node removed
+// from the graph can't be seen by polling, so its document would remain.
+package graphsync
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/me2seeks/forge/infra/contract/cache"
+	"github.com/me2seeks/forge/infra/contract/es"
+	"github.com/me2seeks/forge/infra/contract/graph"
+	"github.com/me2seeks/forge/logs"
+	"github.com/me2seeks/forge/sonic"
+)
+
+// Mapping syncs the nodes of a label to an index.
+type Mapping struct {
+	Label string
+	Index string
+	// IDProperty is the node property used as document ID. Defaults to the node ID.
+	IDProperty string
+	// Properties are the node properties copied to the document. Empty copies all.
+	Properties []string
+}
+
+// Checkpoint is the position of a mapping in the change stream: the change
+// time and node ID of the last node synced.
+type Checkpoint struct {
+	UpdatedAt int64  `json:"updated_at"`
+	NodeID    string `json:"node_id"`
+}
+
+// Checkpointer persists checkpoints by mapping label.
+type Checkpointer interface {
+	// Load returns the zero Checkpoint if none was saved.
+	Load(ctx context.Context, label string) (Checkpoint, error)
+	Save(ctx context.Context, label string, cp Checkpoint) error
+}
+
+type cacheCheckpointer struct {
+	cache  cache.Cache
+	prefix string
+}
+
+// NewCacheCheckpointer returns a Checkpointer keeping checkpoints in c under
+// "forge:graphsync:", without expiry.
+func NewCacheCheckpointer(c cache.Cache) Checkpointer {
+	return &cacheCheckpointer{cache: c, prefix: "forge:graphsync:"}
+}
+
+func (c *cacheCheckpointer) Load(ctx context.Context, label string) (Checkpoint, error) {
+	var cp Checkpoint
+	raw, err := c.cache.Get(ctx, c.prefix+label)
+	if errors.Is(err, cache.ErrNotFound) {
+		return cp, nil
+	}
+	if err != nil {
+		return cp, err
+	}
+	err = sonic.Unmarshal(raw, &cp)
+	return cp, err
+}
+
+func (c *cacheCheckpointer) Save(ctx context.Context, label string, cp Checkpoint) error {
+	raw, err := sonic.Marshal(cp)
+	if err != nil {
+		return err
+	}
+	return c.cache.Set(ctx, c.prefix+label, raw, 0)
+}
+
+type Syncer struct {
+	graph       graph.Client
+	es          es.Client
+	mappings    []Mapping
+	checkpoints Checkpointer
+
+	updatedAtProperty string
+	deletedProperty   string
+	batchSize         int
+	interval          time.Duration
+}
+
+type Option func(*Syncer)
+
+// WithUpdatedAtProperty sets the node property holding the change time. Defaults to "updated_at".
+func WithUpdatedAtProperty(name string) Option {
+	return func(s *Syncer) {
+		s.updatedAtProperty = name
+	}
+}
+
+// WithDeletedProperty sets the node property marking tombstones. Defaults to "deleted".
+func WithDeletedProperty(name string) Option {
+	return func(s *Syncer) {
+		s.deletedProperty = name
+	}
+}
+
+// WithBatchSize sets the number of nodes read per query. Defaults to 500.
+func WithBatchSize(n int) Option {
+	return func(s *Syncer) {
+		s.batchSize = n
+	}
+}
+
+// WithInterval sets the polling interval of Run. Defaults to 5s.
+func WithInterval(d time.Duration) Option {
+	return func(s *Syncer) {
+		s.interval = d
+	}
+}
+
+func New(g graph.Client, e es.Client, checkpoints Checkpointer, mappings []Mapping, opts ...Option) *Syncer {
+	s := &Syncer{
+		graph:             g,
+		es:                e,
+		mappings:          mappings,
+		checkpoints:       checkpoints,
+		updatedAtProperty: "updated_at",
+		deletedProperty:   "deleted",
+		batchSize:         500,
+		interval:          5 * time.Second,
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// Run syncs all mappings every interval until ctx is done.
+func (s *Syncer) Run(ctx context.Context) error {
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	for {
+		for _, m := range s.mappings {
+			if _, err := s.SyncOnce(ctx, m.Label); err != nil && ctx.Err() == nil {
+				logs.CtxErrorf(ctx, "[graphsync] sync label %s failed: %v", m.Label, err)
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// SyncOnce writes the nodes of label changed since its checkpoint to its index,
+// saving the checkpoint after each batch, and returns the number of nodes synced.
+func (s *Syncer) SyncOnce(ctx context.Context, label string) (int, error) {
+	m, err := s.mapping(label)
+	if err != nil {
+		return 0, err
+	}
+	cp, err := s.checkpoints.Load(ctx, label)
+	if err != nil {
+		return 0, fmt.Errorf("load checkpoint failed: %w", err)
+	}
+
+	total := 0
+	for {
+		nodes, next, err := s.changes(ctx, m, cp)
+		if err != nil {
+			return total, err
+		}
+		if len(nodes) == 0 {
+			return total, nil
+		}
+		if err := s.write(ctx, m, m.Index, nodes, true); err != nil {
+			return total, err
+		}
+		if err := s.checkpoints.Save(ctx, label, next); err != nil {
+			return total, fmt.Errorf("save checkpoint failed: %w", err)
+		}
+		cp = next
+		total += len(nodes)
+		if len(nodes) < s.batchSize {
+			return total, nil
+		}
+	}
+}
+
+// Rebuild indexes all live nodes of label into index, which may differ from
+// the mapped one so it can be built aside and swapped in with an alias. The
+// checkpoint of label is then set to the last node read, so SyncOnce resumes
+// from there.
+func (s *Syncer) Rebuild(ctx context.Context, label, index string) (int, error) {
+	m, err := s.mapping(label)
+	if err != nil {
+		return 0, err
+	}
+
+	var cp Checkpoint
+	total := 0
+	for {
+		nodes, next, err := s.changes(ctx, m, cp)
+		if err != nil {
+			return total, err
+		}
+		if len(nodes) == 0 {
+			break
+		}
+		if err := s.write(ctx, m, index, nodes, false); err != nil {
+			return total, err
+		}
+		cp = next
+		total += len(nodes)
+		if len(nodes) < s.batchSize {
+			break
+		}
+	}
+
+	if err := s.checkpoints.Save(ctx, label, cp); err != nil {
+		return total, fmt.Errorf("save checkpoint failed: %w", err)
+	}
+	return total, nil
+}
+
+func (s *Syncer) mapping(label string) (*Mapping, error) {
+	for i := range s.mappings {
+		if s.mappings[i].Label == label {
+			return &s.mappings[i], nil
+		}
+	}
+	return nil, fmt.Errorf("graphsync: no mapping for label %q", label)
+}
+
+// changes reads the next batch of nodes after cp, ordered by change time then
+// node ID so that nodes sharing a change time are neither skipped nor repeated.
+func (s *Syncer) changes(ctx context.Context, m *Mapping, cp Checkpoint) ([]*graph.Node, Checkpoint, error) {
+	query := fmt.Sprintf(
+		"MATCH (n:`%s`) WHERE n.`%[2]s` > $since OR (n.`%[2]s` = $since AND elementId(n) > $after) "+
+			"RETURN n ORDER BY n.`%[2]s`, elementId(n) LIMIT $limit",
+		escape(m.Label), escape(s.updatedAtProperty))
+	res, err := s.graph.RawQuery(ctx, query, map[string]any{
+		"since": cp.UpdatedAt,
+		"after": cp.NodeID,
+		"limit": s.batchSize,
+	})
+	if err != nil {
+		return nil, cp, fmt.Errorf("query changes failed: %w", err)
+	}
+
+	nodes := make([]*graph.Node, 0, len(res.Records))
+	for _, rec := range res.Records {
+		node, ok := rec["n"].(*graph.Node)
+		if !ok {
+			continue
+		}
+		nodes = append(nodes, node)
+		updatedAt, _ := node.Properties[s.updatedAtProperty].(int64)
+		cp = Checkpoint{UpdatedAt: updatedAt, NodeID: node.ID}
+	}
+	return nodes, cp, nil
+}
+
+// write bulk-writes the projection of nodes to index, deleting the documents
+// of tombstones, or skipping them if tombstones is false.
+func (s *Syncer) write(ctx context.Context, m *Mapping, index string, nodes []*graph.Node, tombstones bool) error {
+	bi, err := s.es.NewBulkIndexer(index)
+	if err != nil {
+		return fmt.Errorf("create bulk indexer failed: %w", err)
+	}
+
+	var errs []error
+	for _, node := range nodes {
+		id := node.ID
+		if m.IDProperty != "" {
+			id = fmt.Sprint(node.Properties[m.IDProperty])
+		}
+
+		item := es.BulkIndexerItem{Index: index, DocumentID: id}
+		if deleted, _ := node.Properties[s.deletedProperty].(bool); deleted {
+			if !tombstones {
+				continue
+			}
+			item.Action = "delete"
+		} else {
+			body, err := sonic.Marshal(s.project(m, node))
+			if err != nil {
+				errs = append(errs, fmt.Errorf("encode node %s failed: %w", node.ID, err))
+				continue
+			}
+			item.Action = "index"
+			item.Body = bytes.NewReader(body)
+		}
+		if err := bi.Add(ctx, item); err != nil {
+			errs = append(errs, fmt.Errorf("add node %s failed: %w", node.ID, err))
+		}
+	}
+	if err := bi.Close(ctx); err != nil {
+		errs = append(errs, fmt.Errorf("flush bulk indexer failed: %w", err))
+	}
+	return errors.Join(errs...)
+}
+
+func (s *Syncer) project(m *Mapping, node *graph.Node) map[string]any {
+	if len(m.Properties) == 0 {
+		doc := make(map[string]any, len(node.Properties))
+		for k, v := range node.Properties {
+			if k != s.deletedProperty {
+				doc[k] = v
+			}
+		}
+		return doc
+	}
+	doc := make(map[string]any, len(m.Properties))
+	for _, k := range m.Properties {
+		if v, ok := node.Properties[k]; ok {
+			doc[k] = v
+		}
+	}
+	return doc
+}
+
+func escape(identifier string) string {
+	return strings.ReplaceAll(identifier, "`", "``")
+}
@@ -0,0 +1,116 @@
+package graphsync
+
+import (
+	"context"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/me2seeks/forge/infra/contract/es"
+	"github.com/me2seeks/forge/infra/contract/graph"
+	"github.com/me2seeks/forge/infra/impl/cache/memory"
+)
+
+// fakeGraph serves the nodes after the checkpoint given in the query params.
+type fakeGraph struct {
+	graph.Client
+	nodes []*graph.Node
+}
+
+func (f *fakeGraph) RawQuery(_ context.Context, _ string, params map[string]any) (*graph.QueryResult, error) {
+	since, after, limit := params["since"].(int64), params["after"].(string), params["limit"].(int)
+	res := &graph.QueryResult{}
+	for _, n := range f.nodes {
+		ts := n.Properties["updated_at"].(int64)
+		if (ts > since || (ts == since && n.ID > after)) && len(res.Records) < limit {
+			res.Records = append(res.Records, graph.Record{"n": n})
+		}
+	}
+	return res, nil
+}
+
+type fakeES struct {
+	es.Client
+	docs map[string]string
+}
+
+func (f *fakeES) NewBulkIndexer(string) (es.BulkIndexer, error) {
+	return f, nil
+}
+
+func (f *fakeES) Add(_ context.Context, item es.BulkIndexerItem) error {
+	key := item.Index + "/" + item.DocumentID
+	if item.Action == "delete" {
+		delete(f.docs, key)
+		return nil
+	}
+	body, err := io.ReadAll(item.Body)
+	f.docs[key] = string(body)
+	return err
+}
+
+func (f *fakeES) Close(context.Context) error {
+	return nil
+}
+
+func node(id string, updatedAt int64, props graph.Properties) *graph.Node {
+	props["updated_at"] = updatedAt
+	return &graph.Node{ID: id, Labels: []string{"Doc"}, Properties: props}
+}
+
+func TestSyncOnce(t *testing.T) {
+	ctx := context.Background()
+	g := &fakeGraph{nodes: []*graph.Node{
+		node("1", 10, graph.Properties{"key": "a", "title": "A", "secret": "x"}),
+		node("2", 10, graph.Properties{"key": "b", "title": "B"}),
+		node("3", 20, graph.Properties{"key": "c", "title": "C"}),
+	}}
+	e := &fakeES{docs: map[string]string{}}
+	checkpoints := NewCacheCheckpointer(memory.New())
+	s := New(g, e, checkpoints, []Mapping{{Label: "Doc", Index: "docs", IDProperty: "key", Properties: []string{"title"}}},
+		WithBatchSize(2))
+
+	n, err := s.SyncOnce(ctx, "Doc")
+	require.NoError(t, err)
+	assert.Equal(t, 3, n)
+	assert.Equal(t, map[string]string{
+		"docs/a": `{"title":"A"}`,
+		"docs/b": `{"title":"B"}`,
+		"docs/c": `{"title":"C"}`,
+	}, e.docs)
+	cp, err := checkpoints.Load(ctx, "Doc")
+	require.NoError(t, err)
+	assert.Equal(t, Checkpoint{UpdatedAt: 20, NodeID: "3"}, cp)
+
+	// Only the tombstone written after the checkpoint is synced.
+	g.nodes = append(g.nodes, node("1", 30, graph.Properties{"key": "a", "deleted": true}))
+	n, err = s.SyncOnce(ctx, "Doc")
+	require.NoError(t, err)
+	assert.Equal(t, 1, n)
+	assert.NotContains(t, e.docs, "docs/a")
+
+	_, err = s.SyncOnce(ctx, "Person")
+	assert.Error(t, err)
+}
+
+func TestRebuild(t *testing.T) {
+	ctx := context.Background()
+	g := &fakeGraph{nodes: []*graph.Node{
+		node("1", 10, graph.Properties{"title": "A", "deleted": true}),
+		node("2", 20, graph.Properties{"title": "B"}),
+	}}
+	e := &fakeES{docs: map[string]string{}}
+	checkpoints := NewCacheCheckpointer(memory.New())
+	s := New(g, e, checkpoints, []Mapping{{Label: "Doc", Index: "docs"}})
+
+	n, err := s.Rebuild(ctx, "Doc", "docs_v2")
+	require.NoError(t, err)
+	assert.Equal(t, 2, n)
+	require.Len(t, e.docs, 1)
+	assert.JSONEq(t, `{"title":"B","updated_at":20}`, e.docs["docs_v2/2"])
+	cp, err := checkpoints.Load(ctx, "Doc")
+	require.NoError(t, err)
+	assert.Equal(t, Checkpoint{UpdatedAt: 20, NodeID: "2"}, cp)
+}
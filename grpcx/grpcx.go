@@ -0,0 +1,317 @@
+// Package grpcx provides gRPC interceptors wiring services into the forge
+// primitives: log IDs and trace context travel in metadata, panics are recovered
+// with goutil, errorx errors become gRPC statuses, and every call is logged,
+// traced and measured.
+package grpcx
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"time"
+
+	gootel "go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	otelcodes "go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/metric/noop"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"github.com/me2seeks/forge/errorx"
+	"github.com/me2seeks/forge/goutil"
+	"github.com/me2seeks/forge/logs"
+	"github.com/me2seeks/forge/otel"
+)
+
+// LogIDKey is the metadata key carrying the log ID.
+const LogIDKey = "x-log-id"
+
+// ErrorDomain is the ErrorInfo domain of statuses converted from errorx errors.
+const ErrorDomain = "forge.errorx"
+
+const instrumentationName = "github.com/me2seeks/forge/grpcx"
+
+// CodeMapper maps an errorx error to a gRPC code.
+type CodeMapper func(err errorx.StatusError) codes.Code
+
+// DefaultCodeMapper maps errors that affect stability to Internal and the others,
+// usually caused by the request, to FailedPrecondition.
+func DefaultCodeMapper(err errorx.StatusError) codes.Code {
+	if err.IsAffectStability() {
+		return codes.Internal
+	}
+	return codes.FailedPrecondition
+}
+
+type Option func(*options)
+
+type options struct {
+	codeMapper    CodeMapper
+	slowThreshold time.Duration
+}
+
+// WithCodeMapper sets how errorx errors are mapped to gRPC codes. Defaults to DefaultCodeMapper.
+func WithCodeMapper(mapper CodeMapper) Option {
+	return func(o *options) {
+		o.codeMapper = mapper
+	}
+}
+
+// WithSlowThreshold sets the duration above which successful calls are logged as warnings. Defaults to 1s.
+func WithSlowThreshold(d time.Duration) Option {
+	return func(o *options) {
+		o.slowThreshold = d
+	}
+}
+
+type observer struct {
+	options
+	tracer   trace.Tracer
+	duration metric.Float64Histogram
+}
+
+func newObserver(opts []Option) *observer {
+	o := &observer{
+		options: options{
+			codeMapper:    DefaultCodeMapper,
+			slowThreshold: time.Second,
+		},
+		tracer: otel.Tracer(instrumentationName),
+	}
+	for _, opt := range opts {
+		opt(&o.options)
+	}
+
+	var err error
+	o.duration, err = otel.Meter(instrumentationName).Float64Histogram("rpc.duration",
+		metric.WithDescription("Duration of gRPC calls."), metric.WithUnit("s"))
+	if err != nil {
+		logs.Warnf("[grpcx] create rpc.duration histogram failed: %v", err)
+		o.duration = noop.Float64Histogram{}
+	}
+	return o
+}
+
+// ServerOptions returns the server options installing the unary and stream server interceptors.
+func ServerOptions(opts ...Option) []grpc.ServerOption {
+	return []grpc.ServerOption{
+		grpc.ChainUnaryInterceptor(UnaryServerInterceptor(opts...)),
+		grpc.ChainStreamInterceptor(StreamServerInterceptor(opts...)),
+	}
+}
+
+// DialOptions returns the dial options installing the unary and stream client interceptors.
+func DialOptions(opts ...Option) []grpc.DialOption {
+	return []grpc.DialOption{
+		grpc.WithChainUnaryInterceptor(UnaryClientInterceptor(opts...)),
+		grpc.WithChainStreamInterceptor(StreamClientInterceptor(opts...)),
+	}
+}
+
+func UnaryServerInterceptor(opts ...Option) grpc.UnaryServerInterceptor {
+	o := newObserver(opts)
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (resp any, err error) {
+		err = o.serve(ctx, info.FullMethod, func(ctx context.Context) error {
+			resp, err = handler(ctx, req)
+			return err
+		})
+		return resp, err
+	}
+}
+
+func StreamServerInterceptor(opts ...Option) grpc.StreamServerInterceptor {
+	o := newObserver(opts)
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		return o.serve(ss.Context(), info.FullMethod, func(ctx context.Context) error {
+			return handler(srv, &serverStream{ServerStream: ss, ctx: ctx})
+		})
+	}
+}
+
+type serverStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *serverStream) Context() context.Context {
+	return s.ctx
+}
+
+// serve runs call with the log ID and trace context of the incoming metadata and
+// converts its error, or panic, to a status.
+func (o *observer) serve(ctx context.Context, method string, call func(ctx context.Context) error) error {
+	md, _ := metadata.FromIncomingContext(ctx)
+	if ids := md.Get(LogIDKey); len(ids) > 0 && ids[0] != "" {
+		ctx = logs.SetContext(ctx, ids[0])
+	}
+	ctx = gootel.GetTextMapPropagator().Extract(ctx, metadataCarrier(md))
+	ctx, span := o.tracer.Start(ctx, method, trace.WithSpanKind(trace.SpanKindServer))
+	defer span.End()
+
+	start := time.Now()
+	err := o.recoverCall(ctx, call)
+	err = o.toStatus(err)
+
+	o.finish(ctx, span, "server", method, start, err)
+	return err
+}
+
+func (o *observer) recoverCall(ctx context.Context, call func(ctx context.Context) error) (err error) {
+	panicked := true
+	defer func() {
+		if panicked {
+			err = status.Error(codes.Internal, "internal error")
+		}
+	}()
+	defer goutil.Recovery(ctx)
+
+	err = call(ctx)
+	panicked = false
+	return err
+}
+
+// toStatus converts err to a status error. errorx errors keep their code and
+// extra fields in an ErrorInfo detail, readable with Code.
+func (o *observer) toStatus(err error) error {
+	if err == nil {
+		return nil
+	}
+	if _, ok := status.FromError(err); ok {
+		return err
+	}
+	switch {
+	case errors.Is(err, context.Canceled):
+		return status.Error(codes.Canceled, err.Error())
+	case errors.Is(err, context.DeadlineExceeded):
+		return status.Error(codes.DeadlineExceeded, err.Error())
+	}
+
+	var se errorx.StatusError
+	if !errors.As(err, &se) {
+		return status.Error(codes.Unknown, errorx.ErrorWithoutStack(err))
+	}
+
+	st := status.New(o.codeMapper(se), se.Msg())
+	info := &errdetails.ErrorInfo{
+		Reason:   strconv.FormatInt(int64(se.Code()), 10),
+		Domain:   ErrorDomain,
+		Metadata: se.Extra(),
+	}
+	if withInfo, detailErr := st.WithDetails(info); detailErr == nil {
+		st = withInfo
+	}
+	return st.Err()
+}
+
+// Code returns the errorx code carried by a status error returned by a server
+// using these interceptors.
+func Code(err error) (int32, bool) {
+	st, ok := status.FromError(err)
+	if !ok {
+		return 0, false
+	}
+	for _, d := range st.Details() {
+		if info, ok := d.(*errdetails.ErrorInfo); ok && info.Domain == ErrorDomain {
+			code, err := strconv.ParseInt(info.Reason, 10, 32)
+			return int32(code), err == nil
+		}
+	}
+	return 0, false
+}
+
+func UnaryClientInterceptor(opts ...Option) grpc.UnaryClientInterceptor {
+	o := newObserver(opts)
+	return func(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, callOpts ...grpc.CallOption) error {
+		ctx, span := o.outgoing(ctx, method)
+		defer span.End()
+
+		start := time.Now()
+		err := invoker(ctx, method, req, reply, cc, callOpts...)
+		o.finish(ctx, span, "client", method, start, err)
+		return err
+	}
+}
+
+// StreamClientInterceptor propagates the log ID and trace context to streams. The
+// logged duration only covers opening the stream.
+func StreamClientInterceptor(opts ...Option) grpc.StreamClientInterceptor {
+	o := newObserver(opts)
+	return func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, callOpts ...grpc.CallOption) (grpc.ClientStream, error) {
+		ctx, span := o.outgoing(ctx, method)
+		defer span.End()
+
+		start := time.Now()
+		cs, err := streamer(ctx, desc, cc, method, callOpts...)
+		o.finish(ctx, span, "client", method, start, err)
+		return cs, err
+	}
+}
+
+// outgoing starts a client span and writes the log ID and trace context of ctx
+// into the outgoing metadata.
+func (o *observer) outgoing(ctx context.Context, method string) (context.Context, trace.Span) {
+	ctx, span := o.tracer.Start(ctx, method, trace.WithSpanKind(trace.SpanKindClient))
+
+	md, _ := metadata.FromOutgoingContext(ctx)
+	md = md.Copy()
+	if logID := logs.LogID(ctx); logID != "" && len(md.Get(LogIDKey)) == 0 {
+		md.Set(LogIDKey, logID)
+	}
+	gootel.GetTextMapPropagator().Inject(ctx, metadataCarrier(md))
+	return metadata.NewOutgoingContext(ctx, md), span
+}
+
+func (o *observer) finish(ctx context.Context, span trace.Span, kind, method string, start time.Time, err error) {
+	elapsed := time.Since(start)
+	code := status.Code(err)
+
+	span.SetAttributes(attribute.String("rpc.grpc.status_code", code.String()))
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(otelcodes.Error, code.String())
+	}
+	o.duration.Record(ctx, elapsed.Seconds(), metric.WithAttributes(
+		attribute.String("rpc.kind", kind),
+		attribute.String("rpc.method", method),
+		attribute.String("rpc.grpc.status_code", code.String()),
+	))
+
+	switch {
+	case err != nil:
+		logs.CtxWarnf(ctx, "[grpcx] %s %s -> %s (%v): %v", kind, method, code, elapsed, errorx.ErrorWithoutStack(err))
+	case o.slowThreshold > 0 && elapsed > o.slowThreshold:
+		logs.CtxWarnf(ctx, "[grpcx] %s %s -> %s slow (%v)", kind, method, code, elapsed)
+	default:
+		logs.CtxDebugf(ctx, "[grpcx] %s %s -> %s (%v)", kind, method, code, elapsed)
+	}
+}
+
+// metadataCarrier adapts metadata.MD to propagation.TextMapCarrier.
+type metadataCarrier metadata.MD
+
+var _ propagation.TextMapCarrier = metadataCarrier(nil)
+
+func (c metadataCarrier) Get(key string) string {
+	if v := metadata.MD(c).Get(key); len(v) > 0 {
+		return v[0]
+	}
+	return ""
+}
+
+func (c metadataCarrier) Set(key, value string) {
+	metadata.MD(c).Set(key, value)
+}
+
+func (c metadataCarrier) Keys() []string {
+	keys := make([]string, 0, len(c))
+	for k := range c {
+		keys = append(keys, k)
+	}
+	return keys
+}
@@ -0,0 +1,64 @@
+package grpcx
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"github.com/me2seeks/forge/errorx"
+	"github.com/me2seeks/forge/errorx/code"
+	"github.com/me2seeks/forge/logs"
+)
+
+func TestUnaryServerInterceptor(t *testing.T) {
+	code.Register(40001, "bad input", code.WithAffectStability(false))
+	interceptor := UnaryServerInterceptor()
+	info := &grpc.UnaryServerInfo{FullMethod: "/test.Service/Method"}
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs(LogIDKey, "abc"))
+
+	resp, err := interceptor(ctx, "req", info, func(ctx context.Context, req any) (any, error) {
+		assert.Equal(t, "abc", logs.LogID(ctx))
+		return "resp", nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "resp", resp)
+
+	_, err = interceptor(ctx, "req", info, func(context.Context, any) (any, error) {
+		return nil, errorx.New(40001, errorx.Extra("field", "name"))
+	})
+	st, _ := status.FromError(err)
+	assert.Equal(t, codes.FailedPrecondition, st.Code())
+	assert.Equal(t, "bad input", st.Message())
+	c, ok := Code(err)
+	assert.True(t, ok)
+	assert.Equal(t, int32(40001), c)
+
+	_, err = interceptor(ctx, "req", info, func(context.Context, any) (any, error) {
+		panic("boom")
+	})
+	assert.Equal(t, codes.Internal, status.Code(err))
+
+	_, err = interceptor(ctx, "req", info, func(context.Context, any) (any, error) {
+		return nil, context.DeadlineExceeded
+	})
+	assert.Equal(t, codes.DeadlineExceeded, status.Code(err))
+}
+
+func TestUnaryClientInterceptor(t *testing.T) {
+	interceptor := UnaryClientInterceptor()
+	ctx := logs.SetContext(context.Background(), "abc")
+
+	err := interceptor(ctx, "/test.Service/Method", nil, nil, nil,
+		func(ctx context.Context, _ string, _, _ any, _ *grpc.ClientConn, _ ...grpc.CallOption) error {
+			md, _ := metadata.FromOutgoingContext(ctx)
+			assert.Equal(t, []string{"abc"}, md.Get(LogIDKey))
+			return nil
+		})
+	assert.NoError(t, err)
+}
@@ -0,0 +1,241 @@
+// Package health aggregates the probes of a service's dependencies into
+// liveness and readiness HTTP handlers.
+//
+// Infra clients register a CheckFunc when they are created, e.g.
+//
+//	health.Register("rdb", db.Ping)
+//	health.Register("es", func(ctx context.Context) error { ... }, health.WithTimeout(time.Second))
+package health
+
+import (
+	"context"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/me2seeks/forge/flow"
+	"github.com/me2seeks/forge/sonic"
+	"github.com/me2seeks/forge/taskgroup"
+)
+
+// CheckFunc probes a dependency and returns nil if it is healthy.
+type CheckFunc func(ctx context.Context) error
+
+type Kind int
+
+const (
+	// Readiness checks gate traffic: a failure takes the instance out of rotation.
+	Readiness Kind = 1 << iota
+	// Liveness checks gate restarts: a failure gets the instance killed, so they
+	// should only cover what a restart can fix.
+	Liveness
+)
+
+type Status string
+
+const (
+	StatusUp   Status = "up"
+	StatusDown Status = "down"
+)
+
+// Result is the outcome of a single check.
+type Result struct {
+	Status    Status        `json:"status"`
+	Error     string        `json:"error,omitempty"`
+	Duration  time.Duration `json:"duration"`
+	CheckedAt time.Time     `json:"checked_at"`
+}
+
+// Report aggregates the results of the checks of a kind. It is up only if all of them are.
+type Report struct {
+	Status Status             `json:"status"`
+	Checks map[string]*Result `json:"checks,omitempty"`
+}
+
+type check struct {
+	name     string
+	fn       CheckFunc
+	kind     Kind
+	timeout  time.Duration
+	cacheTTL time.Duration
+
+	mu   sync.Mutex
+	last *Result
+}
+
+type Option func(*check)
+
+// WithKind sets the kinds of probe the check takes part in. Defaults to Readiness.
+func WithKind(kind Kind) Option {
+	return func(c *check) {
+		c.kind = kind
+	}
+}
+
+// WithTimeout bounds each run of the check. Defaults to 2s.
+func WithTimeout(d time.Duration) Option {
+	return func(c *check) {
+		c.timeout = d
+	}
+}
+
+// WithCacheTTL sets how long a result is reused before the check runs again, so
+// frequent probes don't hammer the dependency. Defaults to 5s, zero disables it.
+func WithCacheTTL(d time.Duration) Option {
+	return func(c *check) {
+		c.cacheTTL = d
+	}
+}
+
+// Registry holds named checks.
+type Registry struct {
+	mu     sync.RWMutex
+	checks map[string]*check
+	sf     flow.Singleflight[string, *Result]
+	now    func() time.Time
+}
+
+func NewRegistry() *Registry {
+	return &Registry{checks: make(map[string]*check), now: time.Now}
+}
+
+// Register adds the check name, replacing any previous one.
+func (r *Registry) Register(name string, fn CheckFunc, opts ...Option) {
+	c := &check{
+		name:     name,
+		fn:       fn,
+		kind:     Readiness,
+		timeout:  2 * time.Second,
+		cacheTTL: 5 * time.Second,
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.checks[name] = c
+}
+
+func (r *Registry) Unregister(name string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.checks, name)
+}
+
+// Check runs the checks of kind concurrently, reusing cached results, and
+// aggregates them into a report.
+func (r *Registry) Check(ctx context.Context, kind Kind) *Report {
+	r.mu.RLock()
+	checks := make([]*check, 0, len(r.checks))
+	for _, c := range r.checks {
+		if c.kind&kind != 0 {
+			checks = append(checks, c)
+		}
+	}
+	r.mu.RUnlock()
+	sort.Slice(checks, func(i, j int) bool { return checks[i].name < checks[j].name })
+
+	tg := taskgroup.NewCollecting[*Result](ctx, len(checks)+1)
+	for _, c := range checks {
+		tg.Go(func() (*Result, error) {
+			return r.run(ctx, c), nil
+		})
+	}
+	results, _ := tg.Wait()
+
+	report := &Report{Status: StatusUp, Checks: make(map[string]*Result, len(checks))}
+	for i, c := range checks {
+		report.Checks[c.name] = results[i]
+		if results[i].Status != StatusUp {
+			report.Status = StatusDown
+		}
+	}
+	return report
+}
+
+// run returns the cached result of c if fresh, or runs it once for all
+// concurrent callers.
+func (r *Registry) run(ctx context.Context, c *check) *Result {
+	c.mu.Lock()
+	last := c.last
+	c.mu.Unlock()
+	if last != nil && r.now().Sub(last.CheckedAt) < c.cacheTTL {
+		return last
+	}
+
+	res, err, _ := r.sf.Do(c.name, func() (*Result, error) {
+		ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), c.timeout)
+		defer cancel()
+
+		start := r.now()
+		res := &Result{Status: StatusUp, CheckedAt: start}
+		err := c.fn(ctx)
+		if err == nil {
+			err = ctx.Err()
+		}
+		if err != nil {
+			res.Status = StatusDown
+			res.Error = err.Error()
+		}
+		res.Duration = r.now().Sub(start)
+
+		c.mu.Lock()
+		c.last = res
+		c.mu.Unlock()
+		return res, nil
+	})
+	if err != nil {
+		// c.fn panicked, the error carries the recovered value.
+		return &Result{Status: StatusDown, Error: err.Error(), CheckedAt: r.now()}
+	}
+	return res
+}
+
+// Handler serves the report of kind as JSON, with status 200 if it is up and
+// 503 otherwise. The query parameter "verbose" adds the per-check results.
+func (r *Registry) Handler(kind Kind) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		report := r.Check(req.Context(), kind)
+		if !req.URL.Query().Has("verbose") {
+			report.Checks = nil
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Cache-Control", "no-store")
+		if report.Status != StatusUp {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		} else {
+			w.WriteHeader(http.StatusOK)
+		}
+		_ = sonic.MarshalWrite(w, report)
+	})
+}
+
+func (r *Registry) LivenessHandler() http.Handler {
+	return r.Handler(Liveness)
+}
+
+func (r *Registry) ReadinessHandler() http.Handler {
+	return r.Handler(Readiness)
+}
+
+// Default is the registry used by the package-level functions.
+var Default = NewRegistry()
+
+func Register(name string, fn CheckFunc, opts ...Option) {
+	Default.Register(name, fn, opts...)
+}
+
+func Unregister(name string) {
+	Default.Unregister(name)
+}
+
+func LivenessHandler() http.Handler {
+	return Default.LivenessHandler()
+}
+
+func ReadinessHandler() http.Handler {
+	return Default.ReadinessHandler()
+}
@@ -0,0 +1,71 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRegistry(t *testing.T) {
+	r := NewRegistry()
+
+	var dbCalls atomic.Int32
+	r.Register("db", func(context.Context) error {
+		dbCalls.Add(1)
+		return nil
+	}, WithKind(Readiness|Liveness))
+	r.Register("es", func(ctx context.Context) error {
+		<-ctx.Done()
+		return errors.New("es unreachable")
+	}, WithTimeout(10*time.Millisecond))
+
+	report := r.Check(context.Background(), Readiness)
+	assert.Equal(t, StatusDown, report.Status)
+	assert.Equal(t, StatusUp, report.Checks["db"].Status)
+	assert.Equal(t, "es unreachable", report.Checks["es"].Error)
+
+	// The liveness probe only runs db, and reuses its cached result.
+	report = r.Check(context.Background(), Liveness)
+	assert.Equal(t, StatusUp, report.Status)
+	assert.Len(t, report.Checks, 1)
+	assert.Equal(t, int32(1), dbCalls.Load())
+
+	r.Unregister("es")
+	assert.Equal(t, StatusUp, r.Check(context.Background(), Readiness).Status)
+}
+
+func TestRegistry_Panic(t *testing.T) {
+	r := NewRegistry()
+	r.Register("db", func(context.Context) error { panic("boom") })
+
+	report := r.Check(context.Background(), Readiness)
+	assert.Equal(t, StatusDown, report.Status)
+	assert.Equal(t, StatusDown, report.Checks["db"].Status)
+	assert.Contains(t, report.Checks["db"].Error, "boom")
+
+	rec := httptest.NewRecorder()
+	r.ReadinessHandler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+	assert.Equal(t, http.StatusServiceUnavailable, rec.Code)
+	assert.JSONEq(t, `{"status":"down"}`, rec.Body.String())
+}
+
+func TestHandler(t *testing.T) {
+	r := NewRegistry()
+	r.Register("cache", func(context.Context) error { return errors.New("down") }, WithCacheTTL(0))
+
+	rec := httptest.NewRecorder()
+	r.ReadinessHandler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/readyz?verbose", nil))
+	assert.Equal(t, http.StatusServiceUnavailable, rec.Code)
+	assert.Contains(t, rec.Body.String(), `"cache":{"status":"down","error":"down"`)
+
+	rec = httptest.NewRecorder()
+	r.LivenessHandler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/livez", nil))
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.JSONEq(t, `{"status":"up"}`, rec.Body.String())
+}
@@ -0,0 +1,396 @@
+// Package httpclient provides an HTTP client with retries, per-attempt timeouts,
+// an optional circuit breaker, log ID and trace propagation, and logging hooks.
+package httpclient
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/me2seeks/forge/breaker"
+	"github.com/me2seeks/forge/logs"
+	"github.com/me2seeks/forge/otel"
+	"github.com/me2seeks/forge/retry"
+	"github.com/me2seeks/forge/sonic"
+)
+
+// DefaultLogIDHeader carries the log ID of the caller's ctx.
+const DefaultLogIDHeader = "X-Log-Id"
+
+// Hooks observe each attempt of a request. OnResponse receives either resp or err.
+type Hooks struct {
+	OnRequest  func(ctx context.Context, req *http.Request)
+	OnResponse func(ctx context.Context, req *http.Request, resp *http.Response, err error, elapsed time.Duration)
+}
+
+// TransportConfig tunes the connection pool. Zero fields keep the defaults of
+// http.DefaultTransport.
+type TransportConfig struct {
+	MaxIdleConns          int
+	MaxIdleConnsPerHost   int
+	MaxConnsPerHost       int
+	IdleConnTimeout       time.Duration
+	TLSHandshakeTimeout   time.Duration
+	ResponseHeaderTimeout time.Duration
+}
+
+type Client struct {
+	client      *http.Client
+	timeout     time.Duration
+	retry       retry.Policy
+	breaker     *breaker.Breaker
+	logIDHeader string
+	hooks       []Hooks
+}
+
+type Option func(*Client)
+
+// WithHTTPClient sets the underlying client. It overrides WithTransport.
+func WithHTTPClient(client *http.Client) Option {
+	return func(c *Client) {
+		c.client = client
+	}
+}
+
+// WithTransport builds the underlying client on a transport tuned by cfg.
+func WithTransport(cfg TransportConfig) Option {
+	return func(c *Client) {
+		c.client = &http.Client{Transport: newTransport(cfg)}
+	}
+}
+
+// WithTimeout bounds each attempt, including reading the response body.
+func WithTimeout(d time.Duration) Option {
+	return func(c *Client) {
+		c.timeout = d
+	}
+}
+
+// WithRetry sets the retry policy. Requests are retried on transport errors
+// accepted by policy.Retryable and on 429, 500, 502, 503 and 504 responses, only
+// if their method is idempotent or they carry an Idempotency-Key header. The
+// delay before a retry is at least the Retry-After of the response.
+// AttemptTimeout is ignored in favor of WithTimeout. Defaults to a single attempt.
+func WithRetry(policy retry.Policy) Option {
+	return func(c *Client) {
+		c.retry = policy
+	}
+}
+
+// WithBreaker guards every attempt with b. Transport errors and 5xx responses
+// count as failures, and requests rejected by an open breaker aren't retried.
+func WithBreaker(b *breaker.Breaker) Option {
+	return func(c *Client) {
+		c.breaker = b
+	}
+}
+
+// WithLogIDHeader sets the header carrying the log ID. An empty name disables it.
+func WithLogIDHeader(name string) Option {
+	return func(c *Client) {
+		c.logIDHeader = name
+	}
+}
+
+// WithHooks adds hooks run around every attempt.
+func WithHooks(hooks Hooks) Option {
+	return func(c *Client) {
+		c.hooks = append(c.hooks, hooks)
+	}
+}
+
+// WithLogging logs every attempt through the logs package, failures as warnings.
+func WithLogging() Option {
+	return WithHooks(Hooks{
+		OnResponse: func(ctx context.Context, req *http.Request, resp *http.Response, err error, elapsed time.Duration) {
+			switch {
+			case err != nil:
+				logs.CtxWarnf(ctx, "[httpclient] %s %s failed after %v: %v", req.Method, req.URL, elapsed, err)
+			case resp.StatusCode >= http.StatusInternalServerError:
+				logs.CtxWarnf(ctx, "[httpclient] %s %s -> %d (%v)", req.Method, req.URL, resp.StatusCode, elapsed)
+			default:
+				logs.CtxDebugf(ctx, "[httpclient] %s %s -> %d (%v)", req.Method, req.URL, resp.StatusCode, elapsed)
+			}
+		},
+	})
+}
+
+func New(opts ...Option) *Client {
+	c := &Client{logIDHeader: DefaultLogIDHeader}
+	for _, opt := range opts {
+		opt(c)
+	}
+	if c.client == nil {
+		c.client = &http.Client{Transport: newTransport(TransportConfig{MaxIdleConnsPerHost: 32})}
+	}
+	return c
+}
+
+func newTransport(cfg TransportConfig) *http.Transport {
+	t := http.DefaultTransport.(*http.Transport).Clone()
+	if cfg.MaxIdleConns > 0 {
+		t.MaxIdleConns = cfg.MaxIdleConns
+	}
+	if cfg.MaxIdleConnsPerHost > 0 {
+		t.MaxIdleConnsPerHost = cfg.MaxIdleConnsPerHost
+	}
+	if cfg.MaxConnsPerHost > 0 {
+		t.MaxConnsPerHost = cfg.MaxConnsPerHost
+	}
+	if cfg.IdleConnTimeout > 0 {
+		t.IdleConnTimeout = cfg.IdleConnTimeout
+	}
+	if cfg.TLSHandshakeTimeout > 0 {
+		t.TLSHandshakeTimeout = cfg.TLSHandshakeTimeout
+	}
+	if cfg.ResponseHeaderTimeout > 0 {
+		t.ResponseHeaderTimeout = cfg.ResponseHeaderTimeout
+	}
+	return t
+}
+
+// Do sends req, retrying it per the retry policy. Like http.Client.Do, a non-2xx
+// response isn't an error; the response of the last attempt is returned.
+func (c *Client) Do(req *http.Request) (*http.Response, error) {
+	ctx := req.Context()
+	if c.logIDHeader != "" && req.Header.Get(c.logIDHeader) == "" {
+		if logID := logs.LogID(ctx); logID != "" {
+			req.Header.Set(c.logIDHeader, logID)
+		}
+	}
+	otel.InjectHTTP(ctx, req.Header)
+
+	if c.retry.MaxAttempts > 1 && req.Body != nil && req.GetBody == nil {
+		buf, err := io.ReadAll(req.Body)
+		_ = req.Body.Close()
+		if err != nil {
+			return nil, fmt.Errorf("read request body failed: %w", err)
+		}
+		req.GetBody = func() (io.ReadCloser, error) {
+			return io.NopCloser(bytes.NewReader(buf)), nil
+		}
+		req.Body, _ = req.GetBody()
+	}
+
+	// WithTimeout bounds the attempts instead of AttemptTimeout, as the
+	// caller reads the body after Do returns.
+	policy := c.retry
+	policy.AttemptTimeout = 0
+	retryable := policy.Retryable
+	if retryable == nil {
+		retryable = retry.IsRetryable
+	}
+	policy.Retryable = func(err error) bool {
+		var re *responseError
+		if errors.As(err, &re) {
+			return true
+		}
+		return idempotent(req) && !errors.Is(err, breaker.ErrOpen) && retryable(err)
+	}
+	onRetry := policy.OnRetry
+	policy.OnRetry = func(ctx context.Context, attempt retry.Attempt) {
+		var re *responseError
+		if errors.As(attempt.Err, &re) {
+			_, _ = io.Copy(io.Discard, re.resp.Body)
+			_ = re.resp.Body.Close()
+		}
+		if onRetry != nil {
+			onRetry(ctx, attempt)
+		}
+	}
+
+	calls := 0
+	resp, err := retry.Do(ctx, policy, func(ctx context.Context) (*http.Response, error) {
+		calls++
+		if calls > 1 && req.GetBody != nil {
+			body, err := req.GetBody()
+			if err != nil {
+				return nil, retry.Permanent(fmt.Errorf("rewind request body failed: %w", err))
+			}
+			req.Body = body
+		}
+		resp, err := c.attempt(ctx, req)
+		if err == nil && idempotent(req) && retryableStatus(resp.StatusCode) {
+			return resp, &responseError{resp: resp}
+		}
+		return resp, err
+	})
+	var re *responseError
+	switch {
+	case errors.As(err, &re):
+		// The retries are exhausted: like any other, the response is returned.
+		return re.resp, nil
+	case err != nil && resp != nil:
+		// ctx ended the retries.
+		_ = resp.Body.Close()
+		return nil, err
+	}
+	return resp, err
+}
+
+// responseError is returned by an attempt whose response is worth retrying.
+type responseError struct {
+	resp *http.Response
+}
+
+func (e *responseError) Error() string {
+	return fmt.Sprintf("http status %d", e.resp.StatusCode)
+}
+
+// RetryAfter is the delay requested by the Retry-After header of the response,
+// in seconds.
+func (e *responseError) RetryAfter() time.Duration {
+	secs, err := strconv.Atoi(e.resp.Header.Get("Retry-After"))
+	if err != nil || secs < 0 {
+		return 0
+	}
+	return time.Duration(secs) * time.Second
+}
+
+func (c *Client) attempt(ctx context.Context, req *http.Request) (*http.Response, error) {
+	cancel := context.CancelFunc(func() {})
+	if c.timeout > 0 {
+		ctx, cancel = context.WithTimeout(ctx, c.timeout)
+	}
+	r := req.Clone(ctx)
+
+	var done func(err error)
+	if c.breaker != nil {
+		var err error
+		if done, err = c.breaker.Allow(); err != nil {
+			cancel()
+			return nil, err
+		}
+	}
+
+	for _, h := range c.hooks {
+		if h.OnRequest != nil {
+			h.OnRequest(ctx, r)
+		}
+	}
+	start := time.Now()
+	resp, err := c.client.Do(r)
+	elapsed := time.Since(start)
+	for _, h := range c.hooks {
+		if h.OnResponse != nil {
+			h.OnResponse(ctx, r, resp, err, elapsed)
+		}
+	}
+
+	if done != nil {
+		if err == nil && resp.StatusCode >= http.StatusInternalServerError {
+			done(&StatusError{StatusCode: resp.StatusCode})
+		} else {
+			done(err)
+		}
+	}
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+	// The attempt ctx must outlive Do until the caller has read the body.
+	resp.Body = &cancelBody{ReadCloser: resp.Body, cancel: cancel}
+	return resp, nil
+}
+
+func retryableStatus(code int) bool {
+	switch code {
+	case http.StatusTooManyRequests, http.StatusInternalServerError, http.StatusBadGateway,
+		http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
+
+func idempotent(req *http.Request) bool {
+	switch req.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodTrace, http.MethodPut, http.MethodDelete:
+		return true
+	}
+	return req.Header.Get("Idempotency-Key") != ""
+}
+
+type cancelBody struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (b *cancelBody) Close() error {
+	defer b.cancel()
+	return b.ReadCloser.Close()
+}
+
+// StatusError is returned by DoJSON for non-2xx responses.
+type StatusError struct {
+	StatusCode int
+	// Body is the beginning of the response body.
+	Body string
+}
+
+func (e *StatusError) Error() string {
+	if e.Body == "" {
+		return fmt.Sprintf("http status %d", e.StatusCode)
+	}
+	return fmt.Sprintf("http status %d: %s", e.StatusCode, e.Body)
+}
+
+// Get sends a GET request to url.
+func (c *Client) Get(ctx context.Context, url string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	return c.Do(req)
+}
+
+// DoJSON sends in, if not nil, as a JSON body and decodes a 2xx response into
+// out, if not nil and the response has a body. Other responses return a *StatusError.
+func (c *Client) DoJSON(ctx context.Context, method, url string, in, out any) error {
+	var body io.Reader
+	if in != nil {
+		buf, err := sonic.Marshal(in)
+		if err != nil {
+			return fmt.Errorf("marshal request failed: %w", err)
+		}
+		body = bytes.NewReader(buf)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, url, body)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+	if in != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := c.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		snippet, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return &StatusError{StatusCode: resp.StatusCode, Body: string(snippet)}
+	}
+	if out == nil {
+		return nil
+	}
+	buf, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("read response failed: %w", err)
+	}
+	if len(buf) == 0 {
+		return nil
+	}
+	if err := sonic.Unmarshal(buf, out); err != nil {
+		return fmt.Errorf("unmarshal response failed: %w", err)
+	}
+	return nil
+}
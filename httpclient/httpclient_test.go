@@ -0,0 +1,75 @@
+package httpclient
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/me2seeks/forge/breaker"
+	"github.com/me2seeks/forge/logs"
+	"github.com/me2seeks/forge/retry"
+)
+
+func TestRetry(t *testing.T) {
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		assert.Equal(t, `{"n":1}`, string(body))
+		assert.Equal(t, "abc", r.Header.Get(DefaultLogIDHeader))
+		if calls.Add(1) < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		_, _ = w.Write([]byte(`{"ok":true}`))
+	}))
+	defer srv.Close()
+
+	var attempts int
+	c := New(
+		WithRetry(retry.Policy{MaxAttempts: 3}),
+		WithHooks(Hooks{OnRequest: func(context.Context, *http.Request) { attempts++ }}),
+	)
+	ctx := logs.SetContext(context.Background(), "abc")
+
+	var out struct{ OK bool }
+	require.NoError(t, c.DoJSON(ctx, http.MethodPut, srv.URL, map[string]int{"n": 1}, &out))
+	assert.True(t, out.OK)
+	assert.Equal(t, 3, attempts)
+
+	// POST isn't idempotent, so it isn't retried.
+	calls.Store(0)
+	err := c.DoJSON(ctx, http.MethodPost, srv.URL, map[string]int{"n": 1}, nil)
+	var statusErr *StatusError
+	require.ErrorAs(t, err, &statusErr)
+	assert.Equal(t, http.StatusServiceUnavailable, statusErr.StatusCode)
+	assert.Equal(t, int32(1), calls.Load())
+}
+
+func TestTimeoutAndBreaker(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-time.After(time.Second):
+		}
+	}))
+	defer srv.Close()
+
+	b := breaker.New("test", breaker.WithWindow(2), breaker.WithMinCalls(2), breaker.WithOpenTimeout(time.Minute))
+	c := New(WithTimeout(20*time.Millisecond), WithBreaker(b))
+
+	for range 2 {
+		_, err := c.Get(context.Background(), srv.URL)
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+	}
+	assert.Equal(t, breaker.StateOpen, b.State())
+
+	_, err := c.Get(context.Background(), srv.URL)
+	assert.ErrorIs(t, err, breaker.ErrOpen)
+}
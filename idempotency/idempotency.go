@@ -0,0 +1,147 @@
+// Package idempotency makes retried writes safe: the first call under a key
+// runs and its result is recorded, later calls under the same key get the
+// recorded result back without running again.
+//
+//	doc, err := idempotency.Do(ctx, store, req.RequestID, func(ctx context.Context) (*Doc, error) {
+//		return es.Index(ctx, req.Doc)
+//	}, idempotency.WithFingerprint(req.Body))
+package idempotency
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/me2seeks/forge/logs"
+	"github.com/me2seeks/forge/sonic"
+)
+
+var (
+	// ErrInProgress is returned when another call holds the key and hasn't finished yet.
+	ErrInProgress = errors.New("idempotency: request in progress")
+	// ErrMismatch is returned when a key is reused with a different fingerprint.
+	ErrMismatch = errors.New("idempotency: key reused with a different request")
+)
+
+// Record is what a Store keeps under a key.
+type Record struct {
+	// Fingerprint is the digest of the request that reserved the key.
+	Fingerprint string `json:"fingerprint"`
+	Done        bool   `json:"done"`
+	// Response is the encoded result, set once Done.
+	Response []byte `json:"response,omitempty"`
+}
+
+// Store persists records with a ttl.
+type Store interface {
+	// Reserve stores rec under key for ttl if the key is absent and returns nil,
+	// or returns the record already stored.
+	Reserve(ctx context.Context, key string, rec *Record, ttl time.Duration) (*Record, error)
+	// Complete replaces the record under key, resetting its ttl.
+	Complete(ctx context.Context, key string, rec *Record, ttl time.Duration) error
+	// Release deletes key, letting the next call run.
+	Release(ctx context.Context, key string) error
+}
+
+type options struct {
+	ttl         time.Duration
+	lockTTL     time.Duration
+	fingerprint string
+}
+
+type Option func(*options)
+
+// WithTTL sets how long a result is replayed. Defaults to 24h.
+func WithTTL(d time.Duration) Option {
+	return func(o *options) {
+		o.ttl = d
+	}
+}
+
+// WithLockTTL sets how long a key stays in progress, so a key whose caller
+// crashed can be run again. It must exceed the duration of fn. Defaults to 1m.
+func WithLockTTL(d time.Duration) Option {
+	return func(o *options) {
+		o.lockTTL = d
+	}
+}
+
+// WithFingerprint records a digest of the request, typically its body, so
+// reusing the key for another request fails with ErrMismatch instead of
+// replaying an unrelated result.
+func WithFingerprint(request []byte) Option {
+	return func(o *options) {
+		o.fingerprint = Fingerprint(request)
+	}
+}
+
+// Fingerprint returns the hex SHA-256 digest of request.
+func Fingerprint(request []byte) string {
+	sum := sha256.Sum256(request)
+	return hex.EncodeToString(sum[:])
+}
+
+// Do runs fn once per key and records its result, which later calls with the
+// same key return instead of running fn. If fn fails or panics, nothing is
+// recorded and the next call runs it again. The result is recorded as JSON, so T must
+// round-trip through it.
+func Do[T any](ctx context.Context, store Store, key string, fn func(ctx context.Context) (T, error), opts ...Option) (T, error) {
+	o := &options{
+		ttl:     24 * time.Hour,
+		lockTTL: time.Minute,
+	}
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	var zero T
+	existing, err := store.Reserve(ctx, key, &Record{Fingerprint: o.fingerprint}, o.lockTTL)
+	if err != nil {
+		return zero, fmt.Errorf("reserve idempotency key failed: %w", err)
+	}
+	if existing != nil {
+		if existing.Fingerprint != o.fingerprint {
+			return zero, ErrMismatch
+		}
+		if !existing.Done {
+			return zero, ErrInProgress
+		}
+		var res T
+		if err := sonic.Unmarshal(existing.Response, &res); err != nil {
+			return zero, fmt.Errorf("decode recorded result failed: %w", err)
+		}
+		return res, nil
+	}
+
+	// a panicking fn, e.g. a handler under Middleware, must not leave the key in
+	// progress until lockTTL expires.
+	defer func() {
+		if r := recover(); r != nil {
+			if rerr := store.Release(context.WithoutCancel(ctx), key); rerr != nil {
+				logs.CtxErrorf(ctx, "[idempotency] release key %s after panic failed: %v", key, rerr)
+			}
+			panic(r)
+		}
+	}()
+
+	res, err := fn(ctx)
+	if err != nil {
+		if rerr := store.Release(context.WithoutCancel(ctx), key); rerr != nil {
+			return zero, errors.Join(err, fmt.Errorf("release idempotency key failed: %w", rerr))
+		}
+		return zero, err
+	}
+
+	resp, err := sonic.Marshal(res)
+	if err != nil {
+		return zero, fmt.Errorf("encode result failed: %w", err)
+	}
+	rec := &Record{Fingerprint: o.fingerprint, Done: true, Response: resp}
+	if err := store.Complete(context.WithoutCancel(ctx), key, rec, o.ttl); err != nil {
+		return zero, fmt.Errorf("record result failed: %w", err)
+	}
+	return res, nil
+}
@@ -0,0 +1,107 @@
+package idempotency
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/me2seeks/forge/infra/impl/cache/memory"
+)
+
+type doc struct {
+	ID      string `json:"id"`
+	Version int    `json:"version"`
+}
+
+func TestDo(t *testing.T) {
+	ctx := context.Background()
+	store := NewCacheStore(memory.New())
+
+	var calls atomic.Int32
+	index := func(context.Context) (*doc, error) {
+		return &doc{ID: "a", Version: int(calls.Add(1))}, nil
+	}
+
+	first, err := Do(ctx, store, "k1", index, WithFingerprint([]byte("a")))
+	require.NoError(t, err)
+	replay, err := Do(ctx, store, "k1", index, WithFingerprint([]byte("a")))
+	require.NoError(t, err)
+	assert.Equal(t, first, replay)
+	assert.Equal(t, int32(1), calls.Load())
+
+	_, err = Do(ctx, store, "k1", index, WithFingerprint([]byte("b")))
+	assert.ErrorIs(t, err, ErrMismatch)
+
+	// A failed call isn't recorded, the retry runs again.
+	_, err = Do(ctx, store, "k2", func(context.Context) (*doc, error) { return nil, errors.New("es down") })
+	assert.EqualError(t, err, "es down")
+	_, err = Do(ctx, store, "k2", index)
+	require.NoError(t, err)
+	assert.Equal(t, int32(2), calls.Load())
+}
+
+func TestDoInProgress(t *testing.T) {
+	ctx := context.Background()
+	store := NewCacheStore(memory.New())
+
+	_, err := Do(ctx, store, "k", func(ctx context.Context) (int, error) {
+		_, err := Do(ctx, store, "k", func(context.Context) (int, error) { return 2, nil })
+		assert.ErrorIs(t, err, ErrInProgress)
+		return 1, nil
+	})
+	require.NoError(t, err)
+}
+
+func TestDoPanic(t *testing.T) {
+	ctx := context.Background()
+	store := NewCacheStore(memory.New())
+
+	assert.PanicsWithValue(t, "boom", func() {
+		_, _ = Do(ctx, store, "k", func(context.Context) (int, error) { panic("boom") })
+	})
+
+	// The key was released, the retry runs.
+	res, err := Do(ctx, store, "k", func(context.Context) (int, error) { return 1, nil })
+	require.NoError(t, err)
+	assert.Equal(t, 1, res)
+}
+
+func TestMiddleware(t *testing.T) {
+	var calls atomic.Int32
+	h := Middleware(NewCacheStore(memory.New()))(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		body, _ := io.ReadAll(req.Body)
+		if calls.Add(1) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.Header().Set("Location", "/docs/1")
+		w.WriteHeader(http.StatusCreated)
+		_, _ = w.Write(body)
+	}))
+
+	serve := func(key, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/docs", strings.NewReader(body))
+		req.Header.Set(Header, key)
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+		return rec
+	}
+
+	assert.Equal(t, http.StatusServiceUnavailable, serve("k", "doc").Code)
+	for range 2 {
+		rec := serve("k", "doc")
+		assert.Equal(t, http.StatusCreated, rec.Code)
+		assert.Equal(t, "/docs/1", rec.Header().Get("Location"))
+		assert.Equal(t, "doc", rec.Body.String())
+	}
+	assert.Equal(t, http.StatusUnprocessableEntity, serve("k", "other").Code)
+	assert.Equal(t, int32(2), calls.Load())
+}
@@ -0,0 +1,107 @@
+package idempotency
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+
+	"github.com/me2seeks/forge/logs"
+)
+
+// Header carries the idempotency key of a request, as set by httpclient.
+const Header = "Idempotency-Key"
+
+type response struct {
+	Status int         `json:"status"`
+	Header http.Header `json:"header"`
+	Body   []byte      `json:"body"`
+}
+
+// errNotRecorded makes Do release the key of a response that must not be replayed.
+var errNotRecorded = errors.New("idempotency: response not recorded")
+
+// Middleware replays the recorded response of requests carrying a key in the
+// Idempotency-Key header. Requests without the header pass through. The key is
+// bound to the method, path and body of its first request; reusing it for
+// another request gets 422, and retrying it while the first request runs gets
+// 409. 5xx responses aren't recorded, so the request can be retried.
+func Middleware(store Store, opts ...Option) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			key := req.Header.Get(Header)
+			if key == "" {
+				next.ServeHTTP(w, req)
+				return
+			}
+
+			body, err := io.ReadAll(req.Body)
+			if err != nil {
+				http.Error(w, "read request body failed", http.StatusBadRequest)
+				return
+			}
+			req.Body = io.NopCloser(bytes.NewReader(body))
+			request := append([]byte(req.Method+" "+req.URL.Path+"\n"), body...)
+
+			ctx := req.Context()
+			var served *response
+			resp, err := Do(ctx, store, key, func(ctx context.Context) (*response, error) {
+				rec := &recorder{header: make(http.Header), status: http.StatusOK}
+				next.ServeHTTP(rec, req.WithContext(ctx))
+				resp := &response{Status: rec.status, Header: rec.header, Body: rec.body.Bytes()}
+				served = resp
+				if resp.Status >= http.StatusInternalServerError {
+					return nil, errNotRecorded
+				}
+				return resp, nil
+			}, append(opts, WithFingerprint(request))...)
+			switch {
+			case errors.Is(err, ErrInProgress):
+				http.Error(w, err.Error(), http.StatusConflict)
+				return
+			case errors.Is(err, ErrMismatch):
+				http.Error(w, err.Error(), http.StatusUnprocessableEntity)
+				return
+			case err != nil && served == nil:
+				logs.CtxErrorf(ctx, "[idempotency] serve request with key %s failed: %v", key, err)
+				http.Error(w, "internal server error", http.StatusInternalServerError)
+				return
+			case err != nil:
+				if !errors.Is(err, errNotRecorded) {
+					logs.CtxWarnf(ctx, "[idempotency] record response of key %s failed: %v", key, err)
+				}
+				resp = served
+			}
+
+			for k, v := range resp.Header {
+				w.Header()[k] = v
+			}
+			w.WriteHeader(resp.Status)
+			_, _ = w.Write(resp.Body)
+		})
+	}
+}
+
+// recorder buffers the response of the wrapped handler.
+type recorder struct {
+	header http.Header
+	status int
+	wrote  bool
+	body   bytes.Buffer
+}
+
+func (r *recorder) Header() http.Header {
+	return r.header
+}
+
+func (r *recorder) WriteHeader(status int) {
+	if !r.wrote {
+		r.status, r.wrote = status, true
+	}
+}
+
+func (r *recorder) Write(p []byte) (int, error) {
+	r.wrote = true
+	return r.body.Write(p)
+}
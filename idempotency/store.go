@@ -0,0 +1,128 @@
+package idempotency
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/me2seeks/forge/infra/contract/cache"
+	"github.com/me2seeks/forge/infra/contract/rdb"
+	"github.com/me2seeks/forge/sonic"
+)
+
+type cacheStore struct {
+	cache  cache.Cache
+	prefix string
+}
+
+// NewCacheStore returns a Store keeping records in c under "forge:idempotency:".
+func NewCacheStore(c cache.Cache) Store {
+	return &cacheStore{cache: c, prefix: "forge:idempotency:"}
+}
+
+func (s *cacheStore) Reserve(ctx context.Context, key string, rec *Record, ttl time.Duration) (*Record, error) {
+	value, err := sonic.Marshal(rec)
+	if err != nil {
+		return nil, err
+	}
+	for {
+		ok, err := s.cache.SetNX(ctx, s.prefix+key, value, ttl)
+		if err != nil || ok {
+			return nil, err
+		}
+
+		raw, err := s.cache.Get(ctx, s.prefix+key)
+		if errors.Is(err, cache.ErrNotFound) {
+			// Released or expired in between, try again.
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		existing := &Record{}
+		if err := sonic.Unmarshal(raw, existing); err != nil {
+			return nil, err
+		}
+		return existing, nil
+	}
+}
+
+func (s *cacheStore) Complete(ctx context.Context, key string, rec *Record, ttl time.Duration) error {
+	value, err := sonic.Marshal(rec)
+	if err != nil {
+		return err
+	}
+	return s.cache.Set(ctx, s.prefix+key, value, ttl)
+}
+
+func (s *cacheStore) Release(ctx context.Context, key string) error {
+	return s.cache.Delete(ctx, s.prefix+key)
+}
+
+// Schema creates the table of the rdb Store on PostgreSQL.
+const Schema = `
+CREATE TABLE IF NOT EXISTS idempotency_keys (
+	id          VARCHAR(255) PRIMARY KEY,
+	fingerprint VARCHAR(64)  NOT NULL,
+	done        BOOLEAN      NOT NULL,
+	response    BYTEA,
+	expires_at  TIMESTAMP    NOT NULL
+);
+`
+
+type rdbStore struct {
+	db  rdb.DB
+	now func() time.Time
+}
+
+// NewRDBStore returns a Store keeping records in the idempotency_keys table, see
+// Schema. Expired rows are replaced lazily and may be purged with
+//
+//	DELETE FROM idempotency_keys WHERE expires_at < now()
+func NewRDBStore(db rdb.DB) Store {
+	return &rdbStore{db: db, now: time.Now}
+}
+
+type recordRow struct {
+	Fingerprint string
+	Done        bool
+	Response    []byte
+}
+
+func (s *rdbStore) Reserve(ctx context.Context, key string, rec *Record, ttl time.Duration) (*Record, error) {
+	for {
+		now := s.now()
+		if _, err := s.db.Exec(ctx, "DELETE FROM idempotency_keys WHERE id = ? AND expires_at <= ?", key, now); err != nil {
+			return nil, err
+		}
+		n, err := s.db.Exec(ctx,
+			"INSERT INTO idempotency_keys (id, fingerprint, done, response, expires_at) VALUES (?, ?, ?, ?, ?) ON CONFLICT (id) DO NOTHING",
+			key, rec.Fingerprint, rec.Done, rec.Response, now.Add(ttl))
+		if err != nil || n == 1 {
+			return nil, err
+		}
+
+		row, err := rdb.Get[recordRow](ctx, s.db,
+			"SELECT fingerprint, done, response FROM idempotency_keys WHERE id = ? AND expires_at > ?", key, now)
+		if errors.Is(err, rdb.ErrNotFound) {
+			// Released or expired in between, try again.
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		return &Record{Fingerprint: row.Fingerprint, Done: row.Done, Response: row.Response}, nil
+	}
+}
+
+func (s *rdbStore) Complete(ctx context.Context, key string, rec *Record, ttl time.Duration) error {
+	_, err := s.db.Exec(ctx,
+		"UPDATE idempotency_keys SET fingerprint = ?, done = ?, response = ?, expires_at = ? WHERE id = ?",
+		rec.Fingerprint, rec.Done, rec.Response, s.now().Add(ttl), key)
+	return err
+}
+
+func (s *rdbStore) Release(ctx context.Context, key string) error {
+	_, err := s.db.Exec(ctx, "DELETE FROM idempotency_keys WHERE id = ?", key)
+	return err
+}
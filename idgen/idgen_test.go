@@ -0,0 +1,45 @@
+package idgen
+
+import (
+	"context"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSnowflake(t *testing.T) {
+	ctx := context.Background()
+	now := DefaultEpoch.Add(time.Hour)
+	s, err := NewSnowflake(WithNodeID(7), WithClock(func() time.Time { return now }))
+	assert.NoError(t, err)
+
+	ids, err := s.GenMultiIDs(ctx, 3)
+	assert.NoError(t, err)
+	assert.True(t, sort.SliceIsSorted(ids, func(i, j int) bool { return ids[i] < ids[j] }))
+	assert.Equal(t, ids[0]+1, ids[1])
+	assert.Equal(t, now, s.Time(ids[0]))
+	assert.Equal(t, int64(7), ids[0]>>sequenceBits&maxNodeID)
+
+	now = now.Add(-time.Second)
+	_, err = s.GenID(ctx)
+	assert.ErrorIs(t, err, ErrClockMovedBackwards)
+
+	_, err = NewSnowflake(WithNodeID(maxNodeID + 1))
+	assert.Error(t, err)
+}
+
+func TestULIDAndKSUID(t *testing.T) {
+	at := time.UnixMilli(1700000000123)
+	id := NewULIDAt(at)
+	assert.Len(t, id, 26)
+	ts, err := ULIDTime(id)
+	assert.NoError(t, err)
+	assert.True(t, at.Equal(ts))
+	assert.Less(t, id, NewULIDAt(at.Add(time.Millisecond)))
+
+	assert.Len(t, NewKSUID(), 27)
+	assert.Less(t, NewKSUIDAt(at), NewKSUIDAt(at.Add(time.Hour)))
+	assert.NotEqual(t, NewKSUIDAt(at), NewKSUIDAt(at))
+}
@@ -0,0 +1,203 @@
+package idgen
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/me2seeks/forge/infra/contract/idgen"
+)
+
+const (
+	nodeBits     = 10
+	sequenceBits = 12
+
+	maxNodeID   = 1<<nodeBits - 1
+	maxSequence = 1<<sequenceBits - 1
+)
+
+// DefaultEpoch is the default origin of Snowflake timestamps, 2024-01-01 UTC.
+var DefaultEpoch = time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+// ErrClockMovedBackwards is returned when the clock went back further than the
+// tolerated drift, issuing IDs then could produce duplicates.
+var ErrClockMovedBackwards = errors.New("idgen: clock moved backwards")
+
+// Snowflake generates 63-bit IDs made of a millisecond timestamp, a node ID and a
+// per-millisecond sequence, unique across nodes as long as node IDs are.
+type Snowflake struct {
+	mu       sync.Mutex
+	epoch    time.Time
+	nodeID   int64
+	lastMs   int64
+	sequence int64
+
+	maxDrift time.Duration
+	now      func() time.Time
+}
+
+var _ idgen.IDGenerator = (*Snowflake)(nil)
+
+type Option func(*snowflakeOptions)
+
+type snowflakeOptions struct {
+	epoch      time.Time
+	nodeSource func() (int64, error)
+	maxDrift   time.Duration
+	now        func() time.Time
+}
+
+// WithEpoch sets the origin of timestamps. It must not change once IDs are issued.
+func WithEpoch(epoch time.Time) Option {
+	return func(o *snowflakeOptions) {
+		o.epoch = epoch
+	}
+}
+
+// WithNodeID uses a fixed node ID in [0, 1023].
+func WithNodeID(id int64) Option {
+	return func(o *snowflakeOptions) {
+		o.nodeSource = func() (int64, error) { return id, nil }
+	}
+}
+
+// WithNodeIDSource computes the node ID when the generator is created, e.g. from a
+// lease or the pod ordinal. The default is HostnameNodeID.
+func WithNodeIDSource(source func() (int64, error)) Option {
+	return func(o *snowflakeOptions) {
+		o.nodeSource = source
+	}
+}
+
+// WithMaxClockDrift makes the generator wait out clock regressions up to d instead of
+// failing with ErrClockMovedBackwards. Defaults to 10ms.
+func WithMaxClockDrift(d time.Duration) Option {
+	return func(o *snowflakeOptions) {
+		o.maxDrift = d
+	}
+}
+
+// WithClock overrides time.Now, mainly for tests.
+func WithClock(now func() time.Time) Option {
+	return func(o *snowflakeOptions) {
+		o.now = now
+	}
+}
+
+// HostnameNodeID derives a node ID from a hash of the hostname. Collisions are
+// possible in large fleets, prefer an explicitly assigned ID there.
+func HostnameNodeID() (int64, error) {
+	hostname, err := os.Hostname()
+	if err != nil {
+		return 0, err
+	}
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(hostname))
+	return int64(h.Sum32() % (maxNodeID + 1)), nil
+}
+
+func NewSnowflake(opts ...Option) (*Snowflake, error) {
+	o := snowflakeOptions{
+		epoch:      DefaultEpoch,
+		nodeSource: HostnameNodeID,
+		maxDrift:   10 * time.Millisecond,
+		now:        time.Now,
+	}
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	nodeID, err := o.nodeSource()
+	if err != nil {
+		return nil, fmt.Errorf("get snowflake node id failed: %w", err)
+	}
+	if nodeID < 0 || nodeID > maxNodeID {
+		return nil, fmt.Errorf("snowflake node id %d out of range [0, %d]", nodeID, maxNodeID)
+	}
+
+	return &Snowflake{
+		epoch:    o.epoch,
+		nodeID:   nodeID,
+		maxDrift: o.maxDrift,
+		now:      o.now,
+	}, nil
+}
+
+func (s *Snowflake) GenID(ctx context.Context) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.next(ctx)
+}
+
+func (s *Snowflake) GenMultiIDs(ctx context.Context, counts int) ([]int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	ids := make([]int64, 0, counts)
+	for i := 0; i < counts; i++ {
+		id, err := s.next(ctx)
+		if err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, nil
+}
+
+func (s *Snowflake) next(ctx context.Context) (int64, error) {
+	ms := s.millis()
+	if ms < s.lastMs {
+		drift := time.Duration(s.lastMs-ms) * time.Millisecond
+		if drift > s.maxDrift {
+			return 0, fmt.Errorf("%w by %s", ErrClockMovedBackwards, drift)
+		}
+		if err := s.sleep(ctx, drift); err != nil {
+			return 0, err
+		}
+		if ms = s.millis(); ms < s.lastMs {
+			return 0, ErrClockMovedBackwards
+		}
+	}
+
+	if ms == s.lastMs {
+		s.sequence = (s.sequence + 1) & maxSequence
+		if s.sequence == 0 {
+			// sequence exhausted for this millisecond, wait for the next one.
+			for ms <= s.lastMs {
+				if err := s.sleep(ctx, time.Millisecond/10); err != nil {
+					return 0, err
+				}
+				ms = s.millis()
+			}
+		}
+	} else {
+		s.sequence = 0
+	}
+
+	s.lastMs = ms
+	return ms<<(nodeBits+sequenceBits) | s.nodeID<<sequenceBits | s.sequence, nil
+}
+
+func (s *Snowflake) millis() int64 {
+	return s.now().Sub(s.epoch).Milliseconds()
+}
+
+func (s *Snowflake) sleep(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// Time returns the time an ID of this generator was issued at.
+func (s *Snowflake) Time(id int64) time.Time {
+	return s.epoch.Add(time.Duration(id>>(nodeBits+sequenceBits)) * time.Millisecond)
+}
@@ -0,0 +1,85 @@
+package idgen
+
+import (
+	"crypto/rand"
+	"encoding/binary"
+	"errors"
+	"math/big"
+	"strings"
+	"time"
+)
+
+const crockford = "0123456789ABCDEFGHJKMNPQRSTVWXYZ"
+
+// NewULID returns a ULID for the current time: 26 characters of Crockford base32,
+// lexicographically sortable by time at millisecond precision.
+func NewULID() string {
+	return NewULIDAt(time.Now())
+}
+
+// NewULIDAt returns a ULID with the timestamp t.
+func NewULIDAt(t time.Time) string {
+	var id [16]byte
+	ms := uint64(t.UnixMilli())
+	id[0], id[1], id[2] = byte(ms>>40), byte(ms>>32), byte(ms>>24)
+	id[3], id[4], id[5] = byte(ms>>16), byte(ms>>8), byte(ms)
+	_, _ = rand.Read(id[6:])
+
+	// 128 bits encode as 26 characters of 5 bits, the first one holding only 3 bits.
+	hi, lo := binary.BigEndian.Uint64(id[:8]), binary.BigEndian.Uint64(id[8:])
+	var out [26]byte
+	for i := 25; i >= 0; i-- {
+		out[i] = crockford[lo&0x1f]
+		lo = lo>>5 | hi<<59
+		hi >>= 5
+	}
+	return string(out[:])
+}
+
+// ULIDTime returns the timestamp of a ULID.
+func ULIDTime(id string) (time.Time, error) {
+	if len(id) != 26 {
+		return time.Time{}, errors.New("idgen: invalid ulid length")
+	}
+
+	// the timestamp is the first 48 bits, held by the first 10 characters.
+	id = strings.ToUpper(id)
+	var ms uint64
+	for i := 0; i < 10; i++ {
+		v := strings.IndexByte(crockford, id[i])
+		if v < 0 {
+			return time.Time{}, errors.New("idgen: invalid ulid character")
+		}
+		ms = ms<<5 | uint64(v)
+	}
+	return time.UnixMilli(int64(ms)), nil
+}
+
+// ksuidEpoch is the origin of KSUID timestamps, 2014-05-13 16:53:20 UTC.
+const ksuidEpoch = 1400000000
+
+const base62 = "0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz"
+
+// NewKSUID returns a KSUID for the current time: 27 characters of base62 encoding a
+// 32-bit second timestamp and 128 random bits, sortable by time at second precision.
+func NewKSUID() string {
+	return NewKSUIDAt(time.Now())
+}
+
+// NewKSUIDAt returns a KSUID with the timestamp t.
+func NewKSUIDAt(t time.Time) string {
+	var id [20]byte
+	binary.BigEndian.PutUint32(id[:4], uint32(t.Unix()-ksuidEpoch))
+	_, _ = rand.Read(id[4:])
+
+	n := new(big.Int).SetBytes(id[:])
+	base := big.NewInt(62)
+	mod := new(big.Int)
+
+	var out [27]byte
+	for i := 26; i >= 0; i-- {
+		n.DivMod(n, base, mod)
+		out[i] = base62[mod.Int64()]
+	}
+	return string(out[:])
+}
@@ -0,0 +1,33 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// ErrNotFound is returned by Get when the key doesn't exist or has expired.
+var ErrNotFound = errors.New("cache: key not found")
+
+// Cache is a key-value cache with per-key expiry. A ttl <= 0 means the key never expires.
+//
+//go:generate  mockgen -destination ../../../internal/mock/infra/contract/cache/cache_mock.go -package mock -source cache.go Cache
+type Cache interface {
+	Get(ctx context.Context, key string) ([]byte, error)
+	// MGet returns the values of the keys that exist, missing keys are absent from the map.
+	MGet(ctx context.Context, keys ...string) (map[string][]byte, error)
+	Set(ctx context.Context, key string, value []byte, ttl time.Duration) error
+	// SetNX sets key only if it doesn't exist and reports whether it did.
+	SetNX(ctx context.Context, key string, value []byte, ttl time.Duration) (bool, error)
+	Delete(ctx context.Context, keys ...string) error
+	Exists(ctx context.Context, key string) (bool, error)
+	// Expire resets the ttl of key and reports whether the key exists.
+	Expire(ctx context.Context, key string, ttl time.Duration) (bool, error)
+	// TTL returns the remaining time to live of key, or ErrNotFound. It returns 0
+	// for a key without expiry.
+	TTL(ctx context.Context, key string) (time.Duration, error)
+	// IncrBy adds delta to the integer stored at key, creating it at 0 without expiry
+	// if needed, and returns the new value.
+	IncrBy(ctx context.Context, key string, delta int64) (int64, error)
+	Close() error
+}
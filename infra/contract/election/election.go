@@ -0,0 +1,50 @@
+package election
+
+import (
+	"context"
+	"errors"
+)
+
+// ErrNotLeader is returned by Resign when this instance doesn't hold leadership.
+var ErrNotLeader = errors.New("election: not leader")
+
+// Election elects a single leader among the instances campaigning for the same
+// name. Leadership may be lost without Resign, e.g. when the backing session or
+// lock expires; observers are told in both cases.
+//
+//go:generate  mockgen -destination ../../../internal/mock/infra/contract/election/election_mock.go -package mock -source election.go Election
+type Election interface {
+	// Campaign blocks until this instance becomes leader or ctx is done.
+	Campaign(ctx context.Context) error
+	Resign(ctx context.Context) error
+	IsLeader() bool
+	// Observe registers fn to be called with true when this instance becomes
+	// leader and false when it stops being leader. It returns a function removing
+	// the observer.
+	Observe(fn func(leader bool)) (cancel func())
+	// Close resigns if needed and releases the backing session.
+	Close() error
+}
+
+// RunAsLeader campaigns on e and runs fn once elected, with a ctx canceled as
+// soon as leadership is lost. It resigns when fn returns and returns its error,
+// or the error of Campaign.
+func RunAsLeader(ctx context.Context, e Election, fn func(ctx context.Context) error) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	stop := e.Observe(func(leader bool) {
+		if !leader {
+			cancel()
+		}
+	})
+	defer stop()
+
+	if err := e.Campaign(ctx); err != nil {
+		return err
+	}
+	defer func() {
+		_ = e.Resign(context.WithoutCancel(ctx))
+	}()
+	return fn(ctx)
+}
@@ -0,0 +1,13 @@
+package embedding
+
+import "context"
+
+// Embedder turns texts into vectors with a given model.
+type Embedder interface {
+	// Embed returns one vector per text, in the same order.
+	Embed(ctx context.Context, texts []string) ([][]float32, error)
+	// Model is the name of the embedding model.
+	Model() string
+	// Dimensions is the length of the vectors, or 0 until known.
+	Dimensions() int
+}
@@ -0,0 +1,110 @@
+package featureflag
+
+import (
+	"context"
+	"hash/fnv"
+	"slices"
+)
+
+// Client evaluates feature flags. Evaluation never fails: unknown or disabled
+// flags, and keys outside the rollout, get the caller's default.
+//
+//go:generate  mockgen -destination ../../../internal/mock/infra/contract/featureflag/featureflag_mock.go -package mock -source featureflag.go Client
+type Client interface {
+	// Value returns the value of the flag named name for key, the unit of the
+	// rollout such as a user or tenant ID, and whether it was served by the flag.
+	Value(ctx context.Context, name, key string) (string, bool)
+	// Subscribe registers fn to be called with the names of the flags changed by
+	// each update. It returns a function removing the subscription.
+	Subscribe(fn func(names []string)) (cancel func())
+	Close() error
+}
+
+// Provider loads flag definitions from a remote source, e.g. a config service.
+type Provider interface {
+	Load(ctx context.Context) ([]*Flag, error)
+}
+
+// Flag is the definition of a feature flag.
+type Flag struct {
+	Name string `yaml:"name" json:"name"`
+	// Enabled must be true for the flag to serve Value to anyone.
+	Enabled bool `yaml:"enabled" json:"enabled"`
+	// Value is served to the keys in the rollout, e.g. "true" or a variant name.
+	// Empty means "true".
+	Value string `yaml:"value" json:"value"`
+	// Percentage of keys, in [0, 100], served Value. Nil means all of them. Keys
+	// are bucketed by hashing them with the flag name, so a key stays in the
+	// rollout as the percentage grows.
+	Percentage *float64 `yaml:"percentage" json:"percentage"`
+	// Allow lists keys served Value regardless of Percentage.
+	Allow []string `yaml:"allow" json:"allow"`
+}
+
+// Evaluate returns the value served to key and whether key is in the rollout.
+func (f *Flag) Evaluate(key string) (string, bool) {
+	if !f.Enabled {
+		return "", false
+	}
+	value := f.Value
+	if value == "" {
+		value = "true"
+	}
+	if f.Percentage == nil || slices.Contains(f.Allow, key) {
+		return value, true
+	}
+	if bucket(f.Name, key) < *f.Percentage*100 {
+		return value, true
+	}
+	return "", false
+}
+
+// Equal reports whether f and o define the same flag.
+func (f *Flag) Equal(o *Flag) bool {
+	if f == nil || o == nil {
+		return f == o
+	}
+	samePercentage := (f.Percentage == nil) == (o.Percentage == nil) &&
+		(f.Percentage == nil || *f.Percentage == *o.Percentage)
+	return f.Name == o.Name && f.Enabled == o.Enabled && f.Value == o.Value &&
+		samePercentage && slices.Equal(f.Allow, o.Allow)
+}
+
+// bucket maps key to one of 10000 buckets, stable per flag.
+func bucket(name, key string) float64 {
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(name))
+	_, _ = h.Write([]byte{0})
+	_, _ = h.Write([]byte(key))
+	return float64(h.Sum32() % 10000)
+}
+
+// BoolFlag is a flag evaluated to a bool with a typed default.
+type BoolFlag struct {
+	Name    string
+	Default bool
+}
+
+// Get returns whether the flag is on for key: its value is "true", or Default
+// if the flag doesn't serve key.
+func (f BoolFlag) Get(ctx context.Context, c Client, key string) bool {
+	v, ok := c.Value(ctx, f.Name, key)
+	if !ok {
+		return f.Default
+	}
+	return v == "true"
+}
+
+// StringFlag is a flag evaluated to a string, e.g. a variant, with a typed default.
+type StringFlag struct {
+	Name    string
+	Default string
+}
+
+func (f StringFlag) Get(ctx context.Context, c Client, key string) string {
+	v, ok := c.Value(ctx, f.Name, key)
+	if !ok {
+		return f.Default
+	}
+	return v
+}
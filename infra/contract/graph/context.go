@@ -0,0 +1,109 @@
+package graph
+
+import (
+	"context"
+	"slices"
+	"sync"
+)
+
+type databaseKey struct{}
+
+// WithDatabase returns a copy of ctx running the operations of clients on
+// database instead of their default one, e.g. the database of a tenant, on
+// backends hosting several databases. A transaction stays on the database it
+// began on.
+func WithDatabase(ctx context.Context, database string) context.Context {
+	return context.WithValue(ctx, databaseKey{}, database)
+}
+
+// DatabaseFromContext returns the database set by WithDatabase, or "".
+func DatabaseFromContext(ctx context.Context) string {
+	database, _ := ctx.Value(databaseKey{}).(string)
+	return database
+}
+
+// AccessMode tells a clustered database where to route an operation.
+type AccessMode string
+
+const (
+	// AccessModeRead routes to any member, e.g. a read replica.
+	AccessModeRead AccessMode = "read"
+	// AccessModeWrite routes to the leader.
+	AccessModeWrite AccessMode = "write"
+)
+
+type accessModeKey struct{}
+
+// WithAccessMode returns a copy of ctx routing the operations of clients with
+// mode rather than by what they do, e.g. to run a writing RawQuery on the
+// leader, or a read-only transaction on a replica.
+func WithAccessMode(ctx context.Context, mode AccessMode) context.Context {
+	return context.WithValue(ctx, accessModeKey{}, mode)
+}
+
+// AccessModeFromContext returns the mode set by WithAccessMode, or def.
+func AccessModeFromContext(ctx context.Context, def AccessMode) AccessMode {
+	if mode, ok := ctx.Value(accessModeKey{}).(AccessMode); ok {
+		return mode
+	}
+	return def
+}
+
+// Bookmarks chain operations for causal consistency in a clustered database:
+// an operation run with bookmarks waits until the member serving it has
+// caught up with them, and then updates them to include its own writes. A
+// request reading its previous writes from a replica passes back the
+// bookmarks returned with them, e.g.
+//
+//	b := graph.NewBookmarks(req.Bookmarks...)
+//	ctx = graph.WithBookmarks(ctx, b)
+//	... // operations on ctx
+//	resp.Bookmarks = b.Values()
+//
+// Bookmarks are safe for concurrent use.
+type Bookmarks struct {
+	mu     sync.Mutex
+	values []string
+}
+
+// NewBookmarks returns bookmarks starting from values, e.g. passed back by a
+// previous request.
+func NewBookmarks(values ...string) *Bookmarks {
+	return &Bookmarks{values: slices.Clone(values)}
+}
+
+// Values returns the current bookmarks.
+func (b *Bookmarks) Values() []string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return slices.Clone(b.values)
+}
+
+// Update replaces the bookmarks an operation started from, previous, by the
+// bookmarks it ended with, next. It is called by clients.
+func (b *Bookmarks) Update(previous, next []string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.values = slices.DeleteFunc(b.values, func(v string) bool {
+		return slices.Contains(previous, v)
+	})
+	for _, v := range next {
+		if !slices.Contains(b.values, v) {
+			b.values = append(b.values, v)
+		}
+	}
+}
+
+type bookmarksKey struct{}
+
+// WithBookmarks returns a copy of ctx running the operations of clients after
+// b and recording them in b.
+func WithBookmarks(ctx context.Context, b *Bookmarks) context.Context {
+	return context.WithValue(ctx, bookmarksKey{}, b)
+}
+
+// BookmarksFromContext returns the bookmarks set by WithBookmarks, or nil.
+func BookmarksFromContext(ctx context.Context) *Bookmarks {
+	b, _ := ctx.Value(bookmarksKey{}).(*Bookmarks)
+	return b
+}
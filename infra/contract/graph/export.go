@@ -0,0 +1,395 @@
+package graph
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// ExportFormat is the file format written by ExportGraph.
+type ExportFormat string
+
+const (
+	// FormatGraphML is a GraphML document, e.g. for Gephi, the labels of the
+	// nodes, the label of the edges and the properties being data of their
+	// own keys.
+	FormatGraphML ExportFormat = "graphml"
+	// FormatJSONLines is a JSON object per node and edge, with their fields
+	// and the type "node" or "edge". Unlike CSV, it keeps the types of the
+	// property values.
+	FormatJSONLines ExportFormat = "jsonl"
+	// FormatNodesCSV is the nodes only, with the columns id, labels, joined by
+	// semicolons, and a column per property.
+	FormatNodesCSV ExportFormat = "nodes.csv"
+	// FormatEdgesCSV is the edges only, with the columns id, source, target,
+	// label and a column per property.
+	FormatEdgesCSV ExportFormat = "edges.csv"
+)
+
+// Export writes the nodes and edges of the records of query, each once, to w
+// in format, for the backends implementing ExportGraph over the QueryStream
+// of ops. JSON Lines are streamed; GraphML and CSV declare the properties up
+// front, so the entities are held in memory until the stream ends. In CSV,
+// strings are written as is and other property values as JSON, a missing
+// property being an empty cell.
+func Export(ctx context.Context, ops Operations, w io.Writer, format ExportFormat, query *Query) error {
+	var out exporter
+	switch format {
+	case FormatGraphML:
+		out = &graphMLExporter{w: w}
+	case FormatJSONLines:
+		out = &jsonLinesExporter{enc: json.NewEncoder(w)}
+	case FormatNodesCSV:
+		out = &csvExporter{w: csv.NewWriter(w), nodes: true}
+	case FormatEdgesCSV:
+		out = &csvExporter{w: csv.NewWriter(w)}
+	default:
+		return fmt.Errorf("export graph: unsupported format %q", format)
+	}
+
+	it, err := ops.QueryStream(ctx, query)
+	if err != nil {
+		return err
+	}
+	defer it.Close(ctx)
+	nodes, edges := make(map[string]bool), make(map[string]bool)
+	for it.Next(ctx) {
+		record := it.Record()
+		for _, key := range slices.Sorted(maps.Keys(record)) {
+			err := walkEntities(record[key], func(node *Node) error {
+				if nodes[node.ID] {
+					return nil
+				}
+				nodes[node.ID] = true
+				return out.node(node)
+			}, func(edge *Edge) error {
+				if edges[edge.ID] {
+					return nil
+				}
+				edges[edge.ID] = true
+				return out.edge(edge)
+			})
+			if err != nil {
+				return fmt.Errorf("export graph: %w", err)
+			}
+		}
+	}
+	if err := it.Err(); err != nil {
+		return err
+	}
+	if err := out.close(); err != nil {
+		return fmt.Errorf("export graph: %w", err)
+	}
+	return nil
+}
+
+// walkEntities calls node and edge on the nodes and edges of v, a value of a
+// record, in order.
+func walkEntities(v any, node func(*Node) error, edge func(*Edge) error) error {
+	switch v := v.(type) {
+	case *Node:
+		if v != nil {
+			return node(v)
+		}
+	case *Edge:
+		if v != nil {
+			return edge(v)
+		}
+	case *Path:
+		if v != nil {
+			if err := walkEntities(v.Nodes, node, edge); err != nil {
+				return err
+			}
+			return walkEntities(v.Edges, node, edge)
+		}
+	case []*Node:
+		for _, n := range v {
+			if err := walkEntities(n, node, edge); err != nil {
+				return err
+			}
+		}
+	case []*Edge:
+		for _, e := range v {
+			if err := walkEntities(e, node, edge); err != nil {
+				return err
+			}
+		}
+	case []any:
+		for _, item := range v {
+			if err := walkEntities(item, node, edge); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// exporter writes the nodes and edges of an export in a format.
+type exporter interface {
+	node(node *Node) error
+	edge(edge *Edge) error
+	// close writes what is held and flushes the output.
+	close() error
+}
+
+type jsonLinesExporter struct {
+	enc *json.Encoder
+}
+
+func (e *jsonLinesExporter) node(node *Node) error {
+	return e.enc.Encode(struct {
+		Type string `json:"type"`
+		*Node
+	}{Type: "node", Node: node})
+}
+
+func (e *jsonLinesExporter) edge(edge *Edge) error {
+	return e.enc.Encode(struct {
+		Type string `json:"type"`
+		*Edge
+	}{Type: "edge", Edge: edge})
+}
+
+func (e *jsonLinesExporter) close() error {
+	return nil
+}
+
+// csvExporter writes the nodes, or the edges, the others being skipped.
+type csvExporter struct {
+	w     *csv.Writer
+	nodes bool
+	// rows are the fixed columns of the entities held until close, and props
+	// their properties.
+	rows  [][]string
+	props []Properties
+}
+
+func (e *csvExporter) node(node *Node) error {
+	if e.nodes {
+		e.rows = append(e.rows, []string{node.ID, strings.Join(node.Labels, ";")})
+		e.props = append(e.props, node.Properties)
+	}
+	return nil
+}
+
+func (e *csvExporter) edge(edge *Edge) error {
+	if !e.nodes {
+		e.rows = append(e.rows, []string{edge.ID, edge.SourceNodeID, edge.TargetNodeID, edge.Label})
+		e.props = append(e.props, edge.Properties)
+	}
+	return nil
+}
+
+func (e *csvExporter) close() error {
+	header := []string{"id", "source", "target", "label"}
+	if e.nodes {
+		header = []string{"id", "labels"}
+	}
+	keys := propertyKeys(e.props)
+	if err := e.w.Write(append(header, keys...)); err != nil {
+		return err
+	}
+	for i, row := range e.rows {
+		for _, key := range keys {
+			cell, err := textValue(e.props[i][key])
+			if err != nil {
+				return fmt.Errorf("property %s: %w", key, err)
+			}
+			row = append(row, cell)
+		}
+		if err := e.w.Write(row); err != nil {
+			return err
+		}
+	}
+	e.w.Flush()
+	return e.w.Error()
+}
+
+// graphMLExporter holds the nodes and edges until close, as the keys of their
+// properties come first.
+type graphMLExporter struct {
+	w     io.Writer
+	nodes []*Node
+	edges []*Edge
+}
+
+func (e *graphMLExporter) node(node *Node) error {
+	e.nodes = append(e.nodes, node)
+	return nil
+}
+
+func (e *graphMLExporter) edge(edge *Edge) error {
+	e.edges = append(e.edges, edge)
+	return nil
+}
+
+type graphMLDocument struct {
+	XMLName xml.Name     `xml:"graphml"`
+	XMLNS   string       `xml:"xmlns,attr"`
+	Keys    []graphMLKey `xml:"key"`
+	Graph   graphMLGraph `xml:"graph"`
+}
+
+type graphMLKey struct {
+	ID   string `xml:"id,attr"`
+	For  string `xml:"for,attr"`
+	Name string `xml:"attr.name,attr"`
+	Type string `xml:"attr.type,attr"`
+}
+
+type graphMLGraph struct {
+	ID          string           `xml:"id,attr"`
+	EdgeDefault string           `xml:"edgedefault,attr"`
+	Nodes       []graphMLElement `xml:"node"`
+	Edges       []graphMLElement `xml:"edge"`
+}
+
+// graphMLElement is a node, or an edge with a source and a target.
+type graphMLElement struct {
+	ID     string        `xml:"id,attr"`
+	Source string        `xml:"source,attr,omitempty"`
+	Target string        `xml:"target,attr,omitempty"`
+	Data   []graphMLData `xml:"data"`
+}
+
+type graphMLData struct {
+	Key   string `xml:"key,attr"`
+	Value string `xml:",chardata"`
+}
+
+func (e *graphMLExporter) close() error {
+	doc := graphMLDocument{
+		XMLNS: "http://graphml.graphdrawing.org/xmlns",
+		Keys: []graphMLKey{
+			{ID: "labels", For: "node", Name: "labels", Type: "string"},
+			{ID: "label", For: "edge", Name: "label", Type: "string"},
+		},
+		Graph: graphMLGraph{ID: "G", EdgeDefault: "directed"},
+	}
+
+	// keys declares the keys of the properties of an element kind, prefixed
+	// by its initial, and returns their IDs by property.
+	keys := func(kind string, props []Properties) map[string]string {
+		ids := make(map[string]string)
+		for i, name := range propertyKeys(props) {
+			ids[name] = fmt.Sprintf("%c%d", kind[0], i)
+			doc.Keys = append(doc.Keys, graphMLKey{ID: ids[name], For: kind, Name: name, Type: graphMLType(props, name)})
+		}
+		return ids
+	}
+	data := func(ids map[string]string, props Properties) ([]graphMLData, error) {
+		var out []graphMLData
+		for _, name := range slices.Sorted(maps.Keys(props)) {
+			if props[name] == nil {
+				continue
+			}
+			value, err := textValue(props[name])
+			if err != nil {
+				return nil, fmt.Errorf("property %s: %w", name, err)
+			}
+			out = append(out, graphMLData{Key: ids[name], Value: value})
+		}
+		return out, nil
+	}
+
+	nodeProps := make([]Properties, len(e.nodes))
+	for i, node := range e.nodes {
+		nodeProps[i] = node.Properties
+	}
+	nodeKeys := keys("node", nodeProps)
+	for _, node := range e.nodes {
+		d, err := data(nodeKeys, node.Properties)
+		if err != nil {
+			return err
+		}
+		d = append([]graphMLData{{Key: "labels", Value: strings.Join(node.Labels, ";")}}, d...)
+		doc.Graph.Nodes = append(doc.Graph.Nodes, graphMLElement{ID: node.ID, Data: d})
+	}
+	edgeProps := make([]Properties, len(e.edges))
+	for i, edge := range e.edges {
+		edgeProps[i] = edge.Properties
+	}
+	edgeKeys := keys("edge", edgeProps)
+	for _, edge := range e.edges {
+		d, err := data(edgeKeys, edge.Properties)
+		if err != nil {
+			return err
+		}
+		d = append([]graphMLData{{Key: "label", Value: edge.Label}}, d...)
+		doc.Graph.Edges = append(doc.Graph.Edges, graphMLElement{ID: edge.ID, Source: edge.SourceNodeID, Target: edge.TargetNodeID, Data: d})
+	}
+
+	if _, err := io.WriteString(e.w, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(e.w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(doc); err != nil {
+		return err
+	}
+	_, err := io.WriteString(e.w, "\n")
+	return err
+}
+
+// graphMLType returns the GraphML type of the values of the property name,
+// string if they have different types or aren't scalars.
+func graphMLType(props []Properties, name string) string {
+	typ := ""
+	for _, p := range props {
+		var t string
+		switch p[name].(type) {
+		case nil:
+			continue
+		case bool:
+			t = "boolean"
+		case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64:
+			t = "long"
+		case float32, float64:
+			t = "double"
+		default:
+			return "string"
+		}
+		if typ != "" && typ != t {
+			return "string"
+		}
+		typ = t
+	}
+	if typ == "" {
+		return "string"
+	}
+	return typ
+}
+
+// propertyKeys returns the names of the properties of props, sorted.
+func propertyKeys(props []Properties) []string {
+	names := make(map[string]bool)
+	for _, p := range props {
+		for name := range p {
+			names[name] = true
+		}
+	}
+	return slices.Sorted(maps.Keys(names))
+}
+
+// textValue returns a string as is, nil as an empty string and other values
+// as JSON.
+func textValue(v any) (string, error) {
+	switch v := v.(type) {
+	case nil:
+		return "", nil
+	case string:
+		return v, nil
+	case float64:
+		return strconv.FormatFloat(v, 'g', -1, 64), nil
+	default:
+		b, err := json.Marshal(v)
+		return string(b), err
+	}
+}
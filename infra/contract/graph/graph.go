@@ -2,46 +2,132 @@ package graph
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"io"
 )
 
+// ErrTxDone is returned when using a transaction that was committed or rolled back.
+var ErrTxDone = errors.New("graph: transaction has already been committed or rolled back")
+
 // Client defines the interface for a graph database.
 type Client interface {
 	GraphAlgorithms
+	Operations
+
+	// BeginTx starts a transaction. Operations on the returned Tx are atomic and
+	// only visible to others once committed.
+	BeginTx(ctx context.Context) (Tx, error)
+
+	// --- Bulk Operations ---
+	NewBulkWriter() BulkWriter
+
+	// --- Import/Export ---
+	// ExportGraph writes the nodes and edges returned by query, e.g. as
+	// paths, to w in format. See Export.
+	ExportGraph(ctx context.Context, w io.Writer, format ExportFormat, query *Query) error
+	// ImportGraph creates the nodes read from nodes, then the edges read from
+	// edges between them, e.g. as written by ExportGraph, in batches. See
+	// Import.
+	ImportGraph(ctx context.Context, nodes, edges io.Reader, opts ...ImportOpt) (*ImportResult, error)
+
+	// --- Schema Operations ---
+	CreateNodeIndex(ctx context.Context, label string, properties []string) error
+	CreateEdgeIndex(ctx context.Context, label string, properties []string) error
+	CreateConstraint(ctx context.Context, label, property string, constraintType ConstraintType) error
+	DropNodeIndex(ctx context.Context, label string, properties []string) error
+	DropEdgeIndex(ctx context.Context, label string, properties []string) error
+	DropConstraint(ctx context.Context, label, property string, constraintType ConstraintType) error
+	// CreateFullTextIndex creates the full-text index name over the properties
+	// of the nodes of any of labels, if it doesn't exist.
+	CreateFullTextIndex(ctx context.Context, name string, labels, properties []string) error
+	DropFullTextIndex(ctx context.Context, name string) error
+
+	// --- Schema Introspection ---
+	ListLabels(ctx context.Context) ([]string, error)
+	ListRelationshipTypes(ctx context.Context) ([]string, error)
+	ListIndexes(ctx context.Context) ([]*IndexInfo, error)
+	ListConstraints(ctx context.Context) ([]*ConstraintInfo, error)
+}
 
+// Operations are the data operations available both on a Client, each running
+// in its own transaction, and on a Tx.
+type Operations interface {
 	// --- Node Operations ---
 	CreateNode(ctx context.Context, node *Node) (*Node, error)
 	GetNode(ctx context.Context, nodeID string) (*Node, error)
 	UpdateNode(ctx context.Context, nodeID string, properties Properties) error
 	DeleteNode(ctx context.Context, nodeID string) error
+	// CreateNodes creates nodes in a single transaction and returns them in the
+	// same order.
+	CreateNodes(ctx context.Context, nodes []*Node) ([]*Node, error)
+	// MergeNode atomically creates node, or updates the node with its labels and
+	// the same values of matchKeys, and returns it. matchKeys must be keys of
+	// node.Properties. An existing node keeps the properties node doesn't set.
+	MergeNode(ctx context.Context, node *Node, matchKeys []string) (*Node, error)
 
 	// --- Edge Operations ---
 	CreateEdge(ctx context.Context, edge *Edge) (*Edge, error)
 	GetEdge(ctx context.Context, edgeID string) (*Edge, error)
 	UpdateEdge(ctx context.Context, edgeID string, properties Properties) error
 	DeleteEdge(ctx context.Context, edgeID string) error
+	// CreateEdges creates edges between nodes selected by ID in a single
+	// transaction and returns them in the same order. It fails, creating none,
+	// if any endpoint is missing.
+	CreateEdges(ctx context.Context, edges []*Edge) ([]*Edge, error)
+	// MergeEdge atomically creates the edge, or matches the one with its label
+	// and properties between the same endpoints, and returns it. Each endpoint
+	// is selected by its selector if set, or by its node ID otherwise, and must
+	// select a single node. onCreate is set on a created edge and onMatch on a
+	// matched one; both may be nil. It returns nil if an endpoint is missing.
+	MergeEdge(ctx context.Context, edge *Edge, onCreate, onMatch Properties) (*Edge, error)
 
-	// --- Bulk Operations ---
-	NewBulkWriter() BulkWriter
+	// GetNeighbors returns the nodes reached from the node by walks of up to
+	// the depth of opts (1 by default) along the edges of their direction and
+	// types, never following an edge twice, and the edges of these walks. It
+	// returns an empty neighborhood if the node doesn't exist.
+	GetNeighbors(ctx context.Context, nodeID string, opts ...NeighborOpt) (*Neighborhood, error)
+
+	// Traverse visits the nodes reached from the node within the depth of
+	// spec, along the edges of its direction and types, in the order of its
+	// strategy, and returns the path to each node visited, the first being the
+	// node alone. It returns no path if the node doesn't exist. See
+	// TraversalSpec.
+	Traverse(ctx context.Context, startNodeID string, spec TraversalSpec) ([]*Path, error)
 
 	// --- Query Operations ---
 	Query(ctx context.Context, query *Query) (*QueryResult, error)
 	RawQuery(ctx context.Context, query string, params map[string]any) (*QueryResult, error)
+	// BatchQuery runs statement once per item of rows, bound to the variable
+	// row, in a single round trip and transaction, and returns the records of
+	// all the runs. Like RawQuery, statement is in the query language of the
+	// backend, e.g. to upsert edges between nodes keyed by business IDs in
+	// Cypher:
+	//
+	//	MATCH (a:Account {key: row.from}), (b:Account {key: row.to})
+	//	MERGE (a)-[t:TRANSFERRED]->(b) SET t.amount = row.amount
+	//
+	// params are shared by all the runs and can't be named rows.
+	BatchQuery(ctx context.Context, statement string, rows []map[string]any, params map[string]any) (*QueryResult, error)
+	// QueryStream executes a query and streams its records, which are not all
+	// held in memory like by Query. The iterator must be closed, and holds a
+	// connection until then.
+	QueryStream(ctx context.Context, query *Query) (RecordIterator, error)
 	// FindNodes is a convenience method to find and return nodes directly.
 	// It is a wrapper around the generic Query method.
 	FindNodes(ctx context.Context, query *Query) ([]*Node, error)
 	// FindEdges is a convenience method to find and return edges directly.
 	// It is a wrapper around the generic Query method.
 	FindEdges(ctx context.Context, query *Query) ([]*Edge, error)
+	// FindPaths is a convenience method to find and return the paths named by
+	// the PathAlias of patterns directly. It is a wrapper around the generic
+	// Query method.
+	FindPaths(ctx context.Context, query *Query) ([]*Path, error)
 	// Count executes a query and returns the number of results.
 	Count(ctx context.Context, query *Query) (int64, error)
-
-	// --- Schema Operations ---
-	CreateNodeIndex(ctx context.Context, label string, properties []string) error
-	CreateEdgeIndex(ctx context.Context, label string, properties []string) error
-	CreateConstraint(ctx context.Context, label, property string, constraintType ConstraintType) error
-	DropNodeIndex(ctx context.Context, label string, properties []string) error
-	DropEdgeIndex(ctx context.Context, label string, properties []string) error
-	DropConstraint(ctx context.Context, label, property string, constraintType ConstraintType) error
+	// FullTextSearch returns the nodes matching query in the full-text index
+	// indexName, best first, up to limit if positive.
+	FullTextSearch(ctx context.Context, indexName, query string, limit int) ([]*ScoredNode, error)
 
 	// --- Bulk Update/Delete Operations (based on Query) ---
 	// UpdateNodesByQuery updates properties of all nodes matching the query.
@@ -54,6 +140,37 @@ type Client interface {
 	DeleteEdgesByQuery(ctx context.Context, query *Query) (int, error)
 }
 
+// Tx is a transaction. It must end with Commit or Rollback, after which its
+// operations fail with ErrTxDone. It must not be used concurrently.
+type Tx interface {
+	Operations
+	Commit(ctx context.Context) error
+	Rollback(ctx context.Context) error
+}
+
+// RunInTx runs fn in a transaction of c, committing it if fn returns nil and
+// rolling it back otherwise, or if fn panics.
+func RunInTx(ctx context.Context, c Client, fn func(ctx context.Context, tx Tx) error) (err error) {
+	tx, err := c.BeginTx(ctx)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if r := recover(); r != nil {
+			_ = tx.Rollback(context.WithoutCancel(ctx))
+			panic(r)
+		}
+	}()
+
+	if err := fn(ctx, tx); err != nil {
+		if rerr := tx.Rollback(context.WithoutCancel(ctx)); rerr != nil {
+			return errors.Join(err, fmt.Errorf("rollback failed: %w", rerr))
+		}
+		return err
+	}
+	return tx.Commit(ctx)
+}
+
 // GraphAlgorithms defines a set of common graph algorithms.
 type GraphAlgorithms interface {
 	// ShortestPath calculates the shortest path between two nodes.
@@ -78,6 +195,7 @@ type GraphAlgorithms interface {
 type BulkWriter interface {
 	AddNode(ctx context.Context, node *Node) error
 	AddEdge(ctx context.Context, edge *Edge) error
-	// Close finalizes the bulk operation and reports any errors.
+	// Close finalizes the bulk operation and reports any errors. Once it
+	// succeeds, the added nodes and edges hold the IDs they were created with.
 	Close(ctx context.Context) error
 }
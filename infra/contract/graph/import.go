@@ -0,0 +1,324 @@
+package graph
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// ImportOpt is a function that configures ImportGraph.
+type ImportOpt func(*importOptions)
+
+type importOptions struct {
+	format    ExportFormat
+	batchSize int
+}
+
+// WithImportFormat reads the files in format: FormatJSONLines, the default,
+// or CSV with FormatNodesCSV or FormatEdgesCSV alike, the nodes and the edges
+// being in their own formats.
+func WithImportFormat(format ExportFormat) ImportOpt {
+	return func(o *importOptions) {
+		o.format = format
+	}
+}
+
+// WithBatchSize creates the nodes, and then the edges, by size per bulk
+// writer, 1000 by default.
+func WithBatchSize(size int) ImportOpt {
+	return func(o *importOptions) {
+		o.batchSize = size
+	}
+}
+
+// ImportResult is the outcome of an ImportGraph, partial if it fails.
+type ImportResult struct {
+	// NodeIDs maps the IDs of the nodes in the file to the IDs they were
+	// created with.
+	NodeIDs map[string]string `json:"node_ids"`
+	// Nodes and Edges are the numbers of nodes and edges created.
+	Nodes int `json:"nodes"`
+	Edges int `json:"edges"`
+}
+
+// Import reads the nodes, then the edges, and creates them with the bulk
+// writers of c, each batch in its own writer, and so in its own transaction
+// where the backend makes Close atomic, for the backends implementing
+// ImportGraph. The edges are created between the nodes their source and
+// target IDs map to, so the map of the IDs is held in memory; any other
+// entity is streamed. edges may be nil.
+//
+// In JSON Lines, a line of the other type, as set by ExportGraph, is skipped,
+// so that an export can be read by both readers. In CSV, columns are matched
+// case-insensitively, as written by Gephi, and a cell holding JSON other than
+// a string is decoded, e.g. a number, a boolean or a list.
+func Import(ctx context.Context, c Client, nodes, edges io.Reader, opts ...ImportOpt) (*ImportResult, error) {
+	o := &importOptions{format: FormatJSONLines, batchSize: 1000}
+	for _, opt := range opts {
+		opt(o)
+	}
+	o.batchSize = max(o.batchSize, 1)
+	csvFormat := o.format == FormatNodesCSV || o.format == FormatEdgesCSV
+	if !csvFormat && o.format != FormatJSONLines {
+		return nil, fmt.Errorf("import graph: unsupported format %q", o.format)
+	}
+
+	res := &ImportResult{NodeIDs: make(map[string]string)}
+	// batch are the nodes to create, fileIDs their IDs in the file, and
+	// pending the set of these IDs.
+	var batch []*Node
+	var fileIDs []string
+	pending := make(map[string]bool)
+	flushNodes := func() error {
+		if len(batch) == 0 {
+			return nil
+		}
+		w := c.NewBulkWriter()
+		for _, node := range batch {
+			if err := w.AddNode(ctx, node); err != nil {
+				return err
+			}
+		}
+		if err := w.Close(ctx); err != nil {
+			return err
+		}
+		for i, node := range batch {
+			if fileIDs[i] != "" {
+				res.NodeIDs[fileIDs[i]] = node.ID
+			}
+		}
+		res.Nodes += len(batch)
+		batch, fileIDs = nil, nil
+		clear(pending)
+		return nil
+	}
+	addNode := func(node *Node) error {
+		if node.ID != "" {
+			if _, ok := res.NodeIDs[node.ID]; ok || pending[node.ID] {
+				return fmt.Errorf("duplicate node %q", node.ID)
+			}
+			pending[node.ID] = true
+		}
+		fileIDs = append(fileIDs, node.ID)
+		node.ID = ""
+		batch = append(batch, node)
+		if len(batch) == o.batchSize {
+			return flushNodes()
+		}
+		return nil
+	}
+	readNodes := readNodesJSONLines
+	if csvFormat {
+		readNodes = readNodesCSV
+	}
+	err := readNodes(nodes, addNode)
+	if err == nil {
+		err = flushNodes()
+	}
+	if err != nil {
+		return res, fmt.Errorf("import graph: nodes: %w", err)
+	}
+	if edges == nil {
+		return res, nil
+	}
+
+	var edgeBatch []*Edge
+	flushEdges := func() error {
+		if len(edgeBatch) == 0 {
+			return nil
+		}
+		w := c.NewBulkWriter()
+		for _, edge := range edgeBatch {
+			if err := w.AddEdge(ctx, edge); err != nil {
+				return err
+			}
+		}
+		if err := w.Close(ctx); err != nil {
+			return err
+		}
+		res.Edges += len(edgeBatch)
+		edgeBatch = nil
+		return nil
+	}
+	addEdge := func(edge *Edge) error {
+		source, ok := res.NodeIDs[edge.SourceNodeID]
+		if !ok {
+			return fmt.Errorf("edge %q: unknown source node %q", edge.ID, edge.SourceNodeID)
+		}
+		target, ok := res.NodeIDs[edge.TargetNodeID]
+		if !ok {
+			return fmt.Errorf("edge %q: unknown target node %q", edge.ID, edge.TargetNodeID)
+		}
+		edgeBatch = append(edgeBatch, &Edge{Label: edge.Label, SourceNodeID: source, TargetNodeID: target, Properties: edge.Properties})
+		if len(edgeBatch) == o.batchSize {
+			return flushEdges()
+		}
+		return nil
+	}
+	readEdges := readEdgesJSONLines
+	if csvFormat {
+		readEdges = readEdgesCSV
+	}
+	err = readEdges(edges, addEdge)
+	if err == nil {
+		err = flushEdges()
+	}
+	if err != nil {
+		return res, fmt.Errorf("import graph: edges: %w", err)
+	}
+	return res, nil
+}
+
+// jsonLine is a node or an edge in JSON Lines.
+type jsonLine struct {
+	Type         string     `json:"type"`
+	ID           string     `json:"id"`
+	Labels       []string   `json:"labels"`
+	Label        string     `json:"label"`
+	SourceNodeID string     `json:"source_node_id"`
+	TargetNodeID string     `json:"target_node_id"`
+	Properties   Properties `json:"properties"`
+}
+
+// readJSONLines calls add on each line of r of type typ, or without type.
+func readJSONLines(r io.Reader, typ string, add func(line *jsonLine) error) error {
+	dec := json.NewDecoder(r)
+	dec.UseNumber()
+	for {
+		var line jsonLine
+		if err := dec.Decode(&line); errors.Is(err, io.EOF) {
+			return nil
+		} else if err != nil {
+			return err
+		}
+		if line.Type != "" && line.Type != typ {
+			continue
+		}
+		for k, v := range line.Properties {
+			line.Properties[k] = normalizeNumbers(v)
+		}
+		if err := add(&line); err != nil {
+			return err
+		}
+	}
+}
+
+// readNodesJSONLines calls add on each node of r.
+func readNodesJSONLines(r io.Reader, add func(*Node) error) error {
+	return readJSONLines(r, "node", func(line *jsonLine) error {
+		return add(&Node{ID: line.ID, Labels: line.Labels, Properties: line.Properties})
+	})
+}
+
+// readEdgesJSONLines calls add on each edge of r.
+func readEdgesJSONLines(r io.Reader, add func(*Edge) error) error {
+	return readJSONLines(r, "edge", func(line *jsonLine) error {
+		return add(&Edge{ID: line.ID, Label: line.Label, SourceNodeID: line.SourceNodeID, TargetNodeID: line.TargetNodeID, Properties: line.Properties})
+	})
+}
+
+// readNodesCSV calls add on the node of each row of r.
+func readNodesCSV(r io.Reader, add func(*Node) error) error {
+	return readCSV(r, []string{"id", "labels"}, func(fixed []string, props Properties) error {
+		node := &Node{ID: fixed[0], Properties: props}
+		if fixed[1] != "" {
+			node.Labels = strings.Split(fixed[1], ";")
+		}
+		return add(node)
+	})
+}
+
+// readEdgesCSV calls add on the edge of each row of r.
+func readEdgesCSV(r io.Reader, add func(*Edge) error) error {
+	return readCSV(r, []string{"id", "source", "target", "label"}, func(fixed []string, props Properties) error {
+		return add(&Edge{ID: fixed[0], SourceNodeID: fixed[1], TargetNodeID: fixed[2], Label: fixed[3], Properties: props})
+	})
+}
+
+// readCSV calls row with the cells of the columns named columns, empty if
+// missing, and the properties of the other columns of each row of r.
+func readCSV(r io.Reader, columns []string, row func(fixed []string, props Properties) error) error {
+	cr := csv.NewReader(r)
+	header, err := cr.Read()
+	if errors.Is(err, io.EOF) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+	// fields maps each column to its index in columns, or -1 for a property.
+	fields := make([]int, len(header))
+	for i, name := range header {
+		fields[i] = -1
+		for j, column := range columns {
+			if strings.EqualFold(name, column) {
+				fields[i] = j
+			}
+		}
+	}
+	for {
+		record, err := cr.Read()
+		if errors.Is(err, io.EOF) {
+			return nil
+		} else if err != nil {
+			return err
+		}
+		fixed := make([]string, len(columns))
+		props := Properties{}
+		for i, cell := range record {
+			switch {
+			case fields[i] >= 0:
+				fixed[fields[i]] = cell
+			case cell != "":
+				props[header[i]] = parseTextValue(cell)
+			}
+		}
+		if err := row(fixed, props); err != nil {
+			return err
+		}
+	}
+}
+
+// parseTextValue returns the value of a cell written by textValue: the JSON
+// it holds, unless it is a string or null, or the cell itself.
+func parseTextValue(cell string) any {
+	if !json.Valid([]byte(cell)) {
+		return cell
+	}
+	dec := json.NewDecoder(strings.NewReader(cell))
+	dec.UseNumber()
+	var v any
+	if err := dec.Decode(&v); err != nil {
+		return cell
+	}
+	switch v.(type) {
+	case nil, string:
+		return cell
+	}
+	return normalizeNumbers(v)
+}
+
+// normalizeNumbers converts the JSON numbers in v to int64, if they are
+// integers, or float64.
+func normalizeNumbers(v any) any {
+	switch v := v.(type) {
+	case json.Number:
+		if i, err := v.Int64(); err == nil {
+			return i
+		}
+		f, _ := v.Float64()
+		return f
+	case []any:
+		for i, item := range v {
+			v[i] = normalizeNumbers(item)
+		}
+	case map[string]any:
+		for k, item := range v {
+			v[k] = normalizeNumbers(item)
+		}
+	}
+	return v
+}
@@ -21,6 +21,12 @@ type Node struct {
 	Properties Properties `json:"properties"`
 }
 
+// ScoredNode is a node with its relevance to a search.
+type ScoredNode struct {
+	Node  *Node   `json:"node"`
+	Score float64 `json:"score"`
+}
+
 // Path represents a sequence of nodes and edges, typically as the result of a pathfinding algorithm.
 type Path struct {
 	Nodes []*Node `json:"nodes"`
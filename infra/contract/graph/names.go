@@ -0,0 +1,129 @@
+package graph
+
+import (
+	"errors"
+	"fmt"
+	"unicode"
+	"unicode/utf8"
+)
+
+// ErrInvalidName is returned for a label, relationship type or property key
+// that can't be written in a query.
+var ErrInvalidName = errors.New("graph: invalid name")
+
+// ValidateName returns an error wrapping ErrInvalidName if name, a label, a
+// relationship type or a property key, is empty, isn't valid UTF-8 or holds
+// control characters. The backends writing names in their queries quote them,
+// so that any other character, backticks included, is read as part of the
+// name.
+func ValidateName(name string) error {
+	switch {
+	case name == "":
+		return fmt.Errorf("%w: empty name", ErrInvalidName)
+	case !utf8.ValidString(name):
+		return fmt.Errorf("%w: %q isn't valid UTF-8", ErrInvalidName, name)
+	}
+	for _, r := range name {
+		if unicode.IsControl(r) {
+			return fmt.Errorf("%w: %q holds control characters", ErrInvalidName, name)
+		}
+	}
+	return nil
+}
+
+// ValidateNames validates each of names, see ValidateName.
+func ValidateNames(names ...string) error {
+	for _, name := range names {
+		if err := ValidateName(name); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ValidateNames validates the keys of p, see ValidateName.
+func (p Properties) ValidateNames() error {
+	for key := range p {
+		if err := ValidateName(key); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ValidateNames validates the label of e and the labels and property keys of
+// its selectors, see ValidateName.
+func (e *Edge) ValidateNames() error {
+	if err := ValidateName(e.Label); err != nil {
+		return err
+	}
+	for _, selector := range []*NodeSelector{e.SourceNodeSelector, e.TargetNodeSelector} {
+		if selector == nil {
+			continue
+		}
+		if err := ValidateNames(selector.Labels...); err != nil {
+			return err
+		}
+		if err := selector.Properties.ValidateNames(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ValidateNames validates the labels, relationship types and property keys
+// of q, in its patterns, conditions, aggregations and orders, see
+// ValidateName. Aliases and expressions are written as they are.
+func (q *Query) ValidateNames() error {
+	var names []string
+	var pattern func(p *Pattern)
+	pattern = func(p *Pattern) {
+		names = append(names, p.Labels...)
+		for key := range p.Properties {
+			names = append(names, key)
+		}
+		if e := p.Edge; e != nil {
+			names = append(names, e.Labels...)
+			for key := range e.Properties {
+				names = append(names, key)
+			}
+			if e.Node != nil {
+				pattern(e.Node)
+			}
+		}
+	}
+	for i := range q.Match {
+		pattern(&q.Match[i])
+	}
+	where := func(w *Where) {
+		if w == nil {
+			return
+		}
+		for _, conds := range [][]Condition{w.Filter, w.Must, w.MustNot, w.Should} {
+			for _, cond := range conds {
+				if cond.Property != "" {
+					names = append(names, cond.Property)
+				}
+			}
+		}
+	}
+	items := func(items []Return) {
+		for _, item := range items {
+			if item.Aggregate != nil && item.Aggregate.Property != "" {
+				names = append(names, item.Aggregate.Property)
+			}
+		}
+	}
+	where(q.Where)
+	for _, stage := range q.With {
+		items(stage.Items)
+		where(stage.Where)
+	}
+	items(q.Return)
+	for _, o := range q.OrderBy {
+		if o.Property != "" {
+			names = append(names, o.Property)
+		}
+	}
+	return ValidateNames(names...)
+}
@@ -0,0 +1,63 @@
+package graph
+
+// Neighborhood is the nodes around a node and the edges leading to them, as
+// returned by GetNeighbors.
+type Neighborhood struct {
+	// Nodes are the neighbors, each once, without the node itself.
+	Nodes []*Node `json:"nodes"`
+	// Edges are the edges of the walks from the node to its neighbors, each
+	// once.
+	Edges []*Edge `json:"edges"`
+}
+
+// NeighborOpt is a function that configures GetNeighbors.
+type NeighborOpt func(*NeighborOptions)
+
+// NeighborOptions filter the walks of GetNeighbors, see NewNeighborOptions.
+type NeighborOptions struct {
+	// Direction of the edges followed from the node, DirectionBoth by default.
+	Direction EdgeDirection
+	// RelationshipTypes are the types of the edges followed, any by default.
+	RelationshipTypes []string
+	// Depth is the maximum number of edges between the node and its
+	// neighbors, at least 1.
+	Depth int
+}
+
+// WithDirection follows the edges in direction only, e.g. DirectionOutgoing
+// for the nodes the node points to.
+func WithDirection(direction EdgeDirection) NeighborOpt {
+	return func(o *NeighborOptions) {
+		o.Direction = direction
+	}
+}
+
+// WithRelationshipTypes follows the edges of any of types only.
+func WithRelationshipTypes(types ...string) NeighborOpt {
+	return func(o *NeighborOptions) {
+		o.RelationshipTypes = types
+	}
+}
+
+// WithDepth returns the nodes up to depth edges away, 1 by default. Backends
+// enumerate the walks to them, whose number grows quickly with depth in dense
+// graphs.
+func WithDepth(depth int) NeighborOpt {
+	return func(o *NeighborOptions) {
+		o.Depth = depth
+	}
+}
+
+// NewNeighborOptions returns the options set by opts, for the backends
+// implementing GetNeighbors.
+func NewNeighborOptions(opts ...NeighborOpt) *NeighborOptions {
+	o := &NeighborOptions{Direction: DirectionBoth, Depth: 1}
+	for _, opt := range opts {
+		opt(o)
+	}
+	if o.Direction == "" {
+		o.Direction = DirectionBoth
+	}
+	o.Depth = max(o.Depth, 1)
+	return o
+}
@@ -10,7 +10,8 @@ type Neo4jExtensions interface {
 	// 'config' holds algorithm-specific parameters.
 	CommunityDetection(ctx context.Context, algorithm string, config map[string]any) (map[string]string, error)
 
-	// Similarity calculates similarity scores between nodes.
+	// Similarity calculates similarity scores between nodes, keyed by the
+	// SimilarityKey of each pair of nodes.
 	// 'algorithm' can be "jaccard", "cosine", "pearson", etc.
 	// 'config' holds algorithm-specific parameters.
 	Similarity(ctx context.Context, algorithm string, config map[string]any) (map[string]float64, error)
@@ -37,3 +38,31 @@ type LinkPredictionResult struct {
 	TargetNodeID string  `json:"target_node_id"`
 	Score        float64 `json:"score"`
 }
+
+// SimilarityKey returns the key of the similarity of two nodes in the result of
+// Neo4jExtensions.Similarity.
+func SimilarityKey(nodeID1, nodeID2 string) string {
+	return nodeID1 + "|" + nodeID2
+}
+
+// Unwrapper is implemented by clients decorating another client, so that the
+// capabilities of the decorated client can be discovered.
+type Unwrapper interface {
+	Unwrap() Client
+}
+
+// AsNeo4jExtensions returns the Neo4j extensions of c, looking through the
+// decorators wrapping it, and whether it has them.
+func AsNeo4jExtensions(c Client) (Neo4jExtensions, bool) {
+	for c != nil {
+		if ext, ok := c.(Neo4jExtensions); ok {
+			return ext, true
+		}
+		u, ok := c.(Unwrapper)
+		if !ok {
+			break
+		}
+		c = u.Unwrap()
+	}
+	return nil, false
+}
@@ -1,15 +1,45 @@
 package graph
 
+import (
+	"context"
+	"strings"
+)
+
 // --- Query Structure ---
 
 // Query represents a full graph query.
 type Query struct {
-	Match   []Pattern `json:"match"`
-	Where   *Where    `json:"where,omitempty"`
-	Return  []Return  `json:"return"`
-	OrderBy []Order   `json:"order_by,omitempty"`
-	Skip    *int      `json:"skip,omitempty"`
-	Limit   *int      `json:"limit,omitempty"`
+	Match []Pattern `json:"match"`
+	Where *Where    `json:"where,omitempty"`
+	// With are the stages the matched records go through in order before
+	// Return, which then refers to the items of the last stage.
+	With    []Stage  `json:"with,omitempty"`
+	Return  []Return `json:"return"`
+	OrderBy []Order  `json:"order_by,omitempty"`
+	Skip    *int     `json:"skip,omitempty"`
+	Limit   *int     `json:"limit,omitempty"`
+	// After holds one value per OrderBy item and selects the records sorting
+	// after them, for keyset pagination: unlike Skip, it costs the same on any
+	// page. The last OrderBy item must be unique, e.g. an ID, and none of them
+	// may be null.
+	After []any `json:"after,omitempty"`
+}
+
+// Stage projects the records of a query to Items, which may aggregate, and
+// keeps the projected records satisfying Where. Only the aliases of Items are
+// visible to the next stages, e.g. to keep the people with more than 10
+// friends:
+//
+//	With: []graph.Stage{{
+//		Items: []graph.Return{
+//			{Expression: "p"},
+//			{Alias: "friends", Aggregate: &graph.Aggregation{Func: graph.AggregateCount, Alias: "f"}},
+//		},
+//		Where: &graph.Where{Filter: []graph.Condition{{Alias: "friends", Operator: graph.OpGreaterThan, Value: 10}}},
+//	}},
+type Stage struct {
+	Items []Return `json:"items"`
+	Where *Where   `json:"where,omitempty"`
 }
 
 // Pattern defines a graph pattern to match, e.g., (n:Label)-[r:REL]->(m:Label).
@@ -70,9 +100,10 @@ type ExpressionCondition struct {
 
 // Condition is a single filter condition.
 type Condition struct {
-	// The alias of the node/edge to apply the filter on.
+	// The alias of the node/edge to apply the filter on, or of a value
+	// projected by a Stage.
 	Alias string
-	// The property name.
+	// The property name, or empty to compare the value of Alias itself.
 	Property string
 	// The comparison operator.
 	Operator Operator
@@ -104,11 +135,49 @@ type Return struct {
 	// Alias is the name to use for the returned item in the result set.
 	// e.g., if Expression is "count(n)" and Alias is "node_count", the result will contain a "node_count" field.
 	Alias string `json:"alias,omitempty"`
+	// Aggregate, if set, returns an aggregation instead of Expression. The
+	// items returned without Aggregate are its grouping keys: one record is
+	// returned per distinct combination of their values, or a single record if
+	// there are none.
+	Aggregate *Aggregation `json:"aggregate,omitempty"`
+}
+
+// AggregateFunc is the function of an Aggregation.
+type AggregateFunc string
+
+const (
+	AggregateCount   AggregateFunc = "count"
+	AggregateSum     AggregateFunc = "sum"
+	AggregateAvg     AggregateFunc = "avg"
+	AggregateMin     AggregateFunc = "min"
+	AggregateMax     AggregateFunc = "max"
+	AggregateCollect AggregateFunc = "collect"
+)
+
+// Aggregation computes Func over the records of a group, e.g. the average
+// age of the people of each city:
+//
+//	Return: []graph.Return{
+//		{Expression: "c.name", Alias: "city"},
+//		{Alias: "avg_age", Aggregate: &graph.Aggregation{Func: graph.AggregateAvg, Alias: "p", Property: "age"}},
+//	}
+type Aggregation struct {
+	Func AggregateFunc `json:"func"`
+	// Alias is the node or edge aggregated. With AggregateCount, an empty
+	// Alias counts the records.
+	Alias string `json:"alias,omitempty"`
+	// Property is the property aggregated, or the entity itself if empty.
+	Property string `json:"property,omitempty"`
+	// Distinct aggregates each distinct value once.
+	Distinct bool `json:"distinct,omitempty"`
 }
 
 // Order specifies a field to sort the results by.
 type Order struct {
-	Alias    string
+	// Alias is the node or edge to sort by, or the Alias of an aggregated
+	// item of Return or of a Stage.
+	Alias string
+	// Property is the property to sort by, or the ID of the entity if empty.
 	Property string
 	Asc      bool
 }
@@ -121,9 +190,34 @@ type QueryResult struct {
 // Record is a single result row, a map of alias to the returned entity.
 type Record map[string]ResultEntity
 
-// ResultEntity can be a Node, an Edge, or a single property value.
+// ResultEntity can be a Node, an Edge, a Path, or a single property value.
 type ResultEntity any
 
+// RecordIterator iterates over the records of a streamed query:
+//
+//	it, err := client.QueryStream(ctx, query)
+//	if err != nil {
+//		return err
+//	}
+//	defer it.Close(ctx)
+//	for it.Next(ctx) {
+//		rec := it.Record()
+//		...
+//	}
+//	return it.Err()
+type RecordIterator interface {
+	// Next advances to the next record, returning false when there are no more
+	// or on error.
+	Next(ctx context.Context) bool
+	// Record returns the current record.
+	Record() Record
+	// Err returns the error that stopped the iteration, if any.
+	Err() error
+	// Close releases the resources of the iterator. Records not consumed yet
+	// are discarded.
+	Close(ctx context.Context) error
+}
+
 // --- Schema ---
 
 // ConstraintType defines the type of constraint to apply.
@@ -134,4 +228,58 @@ const (
 	ConstraintUnique ConstraintType = "UNIQUE"
 	// ConstraintExists ensures that a property exists for all nodes/edges with a given label.
 	ConstraintExists ConstraintType = "EXISTS"
+	// ConstraintNodeKey ensures that a property exists and is unique for all
+	// nodes with a given label.
+	ConstraintNodeKey ConstraintType = "NODE_KEY"
+	// ConstraintPropertyType ensures that the values of a property have a
+	// type, set with PropertyTypeConstraint.
+	ConstraintPropertyType ConstraintType = "PROPERTY_TYPE"
 )
+
+// PropertyTypeConstraint returns the ConstraintPropertyType constraint
+// requiring values of typ, a Cypher type, e.g. "STRING", "INTEGER" or
+// "LIST<STRING NOT NULL>", in upper case. Properties without value aren't
+// constrained.
+func PropertyTypeConstraint(typ string) ConstraintType {
+	return ConstraintPropertyType + ConstraintType(":"+strings.ToUpper(typ))
+}
+
+// PropertyType returns the type required by a constraint of
+// PropertyTypeConstraint, and whether t is one.
+func (t ConstraintType) PropertyType() (string, bool) {
+	return strings.CutPrefix(string(t), string(ConstraintPropertyType)+":")
+}
+
+// EntityType tells whether a schema item applies to nodes or relationships.
+type EntityType string
+
+const (
+	EntityNode         EntityType = "NODE"
+	EntityRelationship EntityType = "RELATIONSHIP"
+)
+
+// IndexInfo describes an index of the database.
+type IndexInfo struct {
+	Name string `json:"name"`
+	// Type is the kind of index, e.g. "RANGE", "FULLTEXT", "LOOKUP" on Neo4j.
+	Type       string     `json:"type"`
+	EntityType EntityType `json:"entity_type"`
+	// LabelsOrTypes are the node labels or relationship types indexed, empty
+	// for an index of all labels or types.
+	LabelsOrTypes []string `json:"labels_or_types"`
+	Properties    []string `json:"properties"`
+	// State is whether the index is usable, e.g. "ONLINE" or "POPULATING".
+	State string `json:"state"`
+}
+
+// ConstraintInfo describes a constraint of the database.
+type ConstraintInfo struct {
+	Name string `json:"name"`
+	// Type is ConstraintUnique, ConstraintExists, ConstraintNodeKey, a
+	// PropertyTypeConstraint, or the database's own name for the constraints
+	// without equivalent.
+	Type          ConstraintType `json:"type"`
+	EntityType    EntityType     `json:"entity_type"`
+	LabelsOrTypes []string       `json:"labels_or_types"`
+	Properties    []string       `json:"properties"`
+}
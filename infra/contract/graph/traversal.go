@@ -0,0 +1,193 @@
+package graph
+
+import (
+	"errors"
+	"slices"
+)
+
+// TraversalStrategy is the order in which Traverse visits the nodes.
+type TraversalStrategy string
+
+const (
+	// TraversalBFS visits the nodes breadth first, by increasing depth.
+	TraversalBFS TraversalStrategy = "bfs"
+	// TraversalDFS visits the nodes depth first, each before the nodes it
+	// leads to.
+	TraversalDFS TraversalStrategy = "dfs"
+)
+
+// TraversalUniqueness tells how Traverse handles the nodes it reaches again,
+// e.g. through cycles.
+type TraversalUniqueness string
+
+const (
+	// UniqueNodeGlobal visits each node once, by the first path reaching it,
+	// so cycles are never followed.
+	UniqueNodeGlobal TraversalUniqueness = "node_global"
+	// UniqueNodePath visits a node once per path reaching it, a path never
+	// going back to one of its nodes. The number of paths grows quickly with
+	// the depth in dense graphs.
+	UniqueNodePath TraversalUniqueness = "node_path"
+)
+
+var (
+	// SkipNode is returned by a TraversalSpec.Visit to visit the node without
+	// expanding it.
+	SkipNode = errors.New("graph: skip node")
+	// StopTraversal is returned by a TraversalSpec.Visit to end the traversal
+	// after the node, without error.
+	StopTraversal = errors.New("graph: stop traversal")
+)
+
+// TraversalSpec configures a Traverse.
+type TraversalSpec struct {
+	// Strategy is TraversalBFS by default.
+	Strategy TraversalStrategy
+	// MaxDepth is the maximum number of edges between the start node and the
+	// nodes visited, at least 1.
+	MaxDepth int
+	// Direction of the edges followed, DirectionBoth by default.
+	Direction EdgeDirection
+	// RelationshipTypes are the types of the edges followed, any by default.
+	RelationshipTypes []string
+	// Uniqueness is UniqueNodeGlobal by default.
+	Uniqueness TraversalUniqueness
+	// NodeFilter, if set, is called on each node reached, the start node
+	// included. The nodes it rejects are neither visited nor expanded.
+	NodeFilter func(node *Node) bool
+	// Visit, if set, is called on the path to each node visited, in order. It
+	// may return SkipNode or StopTraversal; Traverse stops with any other
+	// error it returns.
+	Visit func(path *Path) error
+}
+
+// TraverseSubgraph runs the traversal of spec from the node startID over a
+// subgraph holding the nodes and edges within spec.MaxDepth of it, and
+// returns the paths to the nodes visited, in order, the first being the start
+// node alone. It returns no path if the start node isn't in nodes. Backends
+// implement Traverse by loading the subgraph, e.g. with a variable-length
+// pattern, and running the traversal in memory with it, so that spec's
+// functions need no round trip.
+func TraverseSubgraph(startID string, nodes []*Node, edges []*Edge, spec TraversalSpec) ([]*Path, error) {
+	byID := make(map[string]*Node, len(nodes))
+	for _, node := range nodes {
+		byID[node.ID] = node
+	}
+	start := byID[startID]
+	if start == nil {
+		return nil, nil
+	}
+
+	// hops are the edges leaving each node in the direction of spec, in the
+	// order of edges, with the nodes they lead to.
+	type hop struct {
+		edge *Edge
+		node *Node
+	}
+	hops := make(map[string][]hop)
+	for _, edge := range edges {
+		if len(spec.RelationshipTypes) > 0 && !slices.Contains(spec.RelationshipTypes, edge.Label) {
+			continue
+		}
+		source, target := byID[edge.SourceNodeID], byID[edge.TargetNodeID]
+		if source == nil || target == nil {
+			continue
+		}
+		if spec.Direction != DirectionIncoming {
+			hops[source.ID] = append(hops[source.ID], hop{edge: edge, node: target})
+		}
+		// A self-loop is followed once in both directions.
+		if spec.Direction == DirectionIncoming || (spec.Direction != DirectionOutgoing && source != target) {
+			hops[target.ID] = append(hops[target.ID], hop{edge: edge, node: source})
+		}
+	}
+
+	maxDepth := max(spec.MaxDepth, 1)
+	global := spec.Uniqueness != UniqueNodePath
+	seen := make(map[string]bool)
+	var visited []*Path
+	// visit visits the last node of path, and reports whether to expand it.
+	visit := func(path *Path) (bool, error) {
+		node := path.Nodes[len(path.Nodes)-1]
+		if spec.NodeFilter != nil && !spec.NodeFilter(node) {
+			return false, nil
+		}
+		visited = append(visited, path)
+		if spec.Visit != nil {
+			if err := spec.Visit(path); errors.Is(err, SkipNode) {
+				return false, nil
+			} else if err != nil {
+				return false, err
+			}
+		}
+		return len(path.Edges) < maxDepth, nil
+	}
+	// next returns the paths extending path by a hop.
+	next := func(path *Path) []*Path {
+		var paths []*Path
+		for _, h := range hops[path.Nodes[len(path.Nodes)-1].ID] {
+			if global && seen[h.node.ID] || !global && slices.Contains(path.Nodes, h.node) {
+				continue
+			}
+			if global && spec.Strategy != TraversalDFS {
+				// Breadth first, a node is reached first by a shortest path.
+				seen[h.node.ID] = true
+			}
+			paths = append(paths, &Path{
+				Nodes: append(slices.Clip(path.Nodes), h.node),
+				Edges: append(slices.Clip(path.Edges), h.edge),
+			})
+		}
+		return paths
+	}
+
+	var err error
+	if spec.Strategy == TraversalDFS {
+		var walk func(path *Path) error
+		walk = func(path *Path) error {
+			if global {
+				seen[path.Nodes[len(path.Nodes)-1].ID] = true
+			}
+			expand, err := visit(path)
+			if err != nil || !expand {
+				return err
+			}
+			for _, p := range next(path) {
+				// A node may have been reached by an earlier sibling.
+				if global && seen[p.Nodes[len(p.Nodes)-1].ID] {
+					continue
+				}
+				if err := walk(p); err != nil {
+					return err
+				}
+			}
+			return nil
+		}
+		err = walk(&Path{Nodes: []*Node{start}})
+	} else {
+		seen[start.ID] = true
+		queue := []*Path{{Nodes: []*Node{start}}}
+		for len(queue) > 0 && err == nil {
+			path := queue[0]
+			queue = queue[1:]
+			var expand bool
+			if expand, err = visit(path); expand {
+				queue = append(queue, next(path)...)
+			}
+		}
+	}
+	if err != nil && !errors.Is(err, StopTraversal) {
+		return nil, err
+	}
+	return visited, nil
+}
+
+// NeighborOptions returns the options of GetNeighbors loading the subgraph
+// that TraverseSubgraph needs to run spec.
+func (spec TraversalSpec) NeighborOptions() *NeighborOptions {
+	return NewNeighborOptions(
+		WithDirection(spec.Direction),
+		WithRelationshipTypes(spec.RelationshipTypes...),
+		WithDepth(spec.MaxDepth),
+	)
+}
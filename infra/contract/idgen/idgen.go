@@ -0,0 +1,9 @@
+package idgen
+
+import "context"
+
+// IDGenerator issues unique, roughly time-ordered int64 IDs.
+type IDGenerator interface {
+	GenID(ctx context.Context) (int64, error)
+	GenMultiIDs(ctx context.Context, counts int) ([]int64, error)
+}
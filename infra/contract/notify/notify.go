@@ -0,0 +1,68 @@
+package notify
+
+import (
+	"context"
+	"time"
+)
+
+type Channel string
+
+const (
+	ChannelEmail   Channel = "email"
+	ChannelSMS     Channel = "sms"
+	ChannelWebhook Channel = "webhook"
+)
+
+// Message is a notification. If Template is set, Subject and Body are rendered
+// from it with Data, overriding their values.
+type Message struct {
+	Channel Channel
+	// To holds email addresses, phone numbers or URLs depending on Channel.
+	// Webhook providers may have a fixed URL and ignore it.
+	To       []string
+	Subject  string
+	Body     string
+	HTML     bool
+	Template string
+	Data     map[string]any
+	// Metadata is passed through to providers that support it, e.g. as tags.
+	Metadata map[string]string
+}
+
+// Sender renders and delivers messages through the provider of their channel.
+//
+//go:generate  mockgen -destination ../../../internal/mock/infra/contract/notify/notify_mock.go -package mock -source notify.go Sender
+type Sender interface {
+	// Send delivers msg and returns its receipt. A failed delivery returns both
+	// the receipt and the error.
+	Send(ctx context.Context, msg *Message) (*Receipt, error)
+}
+
+// Provider is a delivery backend such as SMTP, SES or a webhook.
+type Provider interface {
+	Name() string
+	Channel() Channel
+	// Deliver sends msg and returns the ID the backend assigned to it, if any.
+	Deliver(ctx context.Context, msg *Message) (providerMessageID string, err error)
+}
+
+type Status string
+
+const (
+	StatusSent   Status = "sent"
+	StatusFailed Status = "failed"
+)
+
+// Receipt reports the outcome of a Send.
+type Receipt struct {
+	Provider          string
+	ProviderMessageID string
+	Status            Status
+	Attempts          int
+	// Error is the message of the last delivery error if Status is StatusFailed.
+	Error  string
+	SentAt time.Time
+}
+
+// StatusReporter observes the receipt of every Send, e.g. to store delivery status.
+type StatusReporter func(ctx context.Context, msg *Message, receipt *Receipt)
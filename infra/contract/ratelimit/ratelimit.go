@@ -0,0 +1,42 @@
+package ratelimit
+
+import (
+	"context"
+	"time"
+)
+
+// Limit allows Events events per Period, with bursts of up to Burst events.
+// A Burst <= 0 defaults to Events.
+type Limit struct {
+	Events int
+	Period time.Duration
+	Burst  int
+}
+
+// PerSecond is a Limit of n events per second.
+func PerSecond(n int) Limit {
+	return Limit{Events: n, Period: time.Second}
+}
+
+// PerMinute is a Limit of n events per minute.
+func PerMinute(n int) Limit {
+	return Limit{Events: n, Period: time.Minute}
+}
+
+// Result is the outcome of a rate limit check.
+type Result struct {
+	Allowed bool
+	// Remaining is the number of events still allowed right now.
+	Remaining int
+	// RetryAfter is how long to wait before the denied events could be allowed.
+	RetryAfter time.Duration
+}
+
+// Limiter rate limits events per key, e.g. per user or per tenant.
+type Limiter interface {
+	Allow(ctx context.Context, key string) (bool, error)
+	// AllowN reports whether n events may happen now, consuming them if so.
+	AllowN(ctx context.Context, key string, n int) (Result, error)
+	// Wait blocks until an event is allowed for key or ctx is done.
+	Wait(ctx context.Context, key string) error
+}
@@ -0,0 +1,87 @@
+package rdb
+
+import (
+	"context"
+	"fmt"
+)
+
+const (
+	DefaultPageSize = 20
+	MaxPageSize     = 1000
+)
+
+// Select runs query and returns its rows scanned into a []T.
+func Select[T any](ctx context.Context, db DB, query string, args ...any) ([]T, error) {
+	var rows []T
+	if err := db.Query(ctx, &rows, query, args...); err != nil {
+		return nil, err
+	}
+	return rows, nil
+}
+
+// Get runs query and returns its first row scanned into a T, or ErrNotFound.
+func Get[T any](ctx context.Context, db DB, query string, args ...any) (T, error) {
+	var row T
+	err := db.QueryRow(ctx, &row, query, args...)
+	return row, err
+}
+
+// Page selects a 1-based page of results.
+type Page struct {
+	Number int
+	Size   int
+}
+
+// normalize clamps p to a valid page, defaulting to the first page of DefaultPageSize.
+func (p Page) normalize() Page {
+	if p.Number < 1 {
+		p.Number = 1
+	}
+	if p.Size <= 0 {
+		p.Size = DefaultPageSize
+	}
+	p.Size = min(p.Size, MaxPageSize)
+	return p
+}
+
+func (p Page) Offset() int {
+	p = p.normalize()
+	return (p.Number - 1) * p.Size
+}
+
+type PageResult[T any] struct {
+	Items  []T
+	Total  int64
+	Number int
+	Size   int
+}
+
+func (r *PageResult[T]) HasNext() bool {
+	return int64(r.Number*r.Size) < r.Total
+}
+
+// Paginate runs query for a single page along with a COUNT(*) of all its rows.
+// query must not carry its own LIMIT or OFFSET; an ORDER BY is needed for the
+// pages to be stable.
+func Paginate[T any](ctx context.Context, db DB, page Page, query string, args ...any) (*PageResult[T], error) {
+	page = page.normalize()
+
+	var total int64
+	countQuery := fmt.Sprintf("SELECT COUNT(*) FROM (%s) AS paginate_q", query)
+	if err := db.QueryRow(ctx, &total, countQuery, args...); err != nil {
+		return nil, fmt.Errorf("count rows failed: %w", err)
+	}
+
+	result := &PageResult[T]{Total: total, Number: page.Number, Size: page.Size}
+	if int64(page.Offset()) >= total {
+		return result, nil
+	}
+
+	pageArgs := append(args[:len(args):len(args)], page.Size, page.Offset())
+	items, err := Select[T](ctx, db, query+" LIMIT ? OFFSET ?", pageArgs...)
+	if err != nil {
+		return nil, err
+	}
+	result.Items = items
+	return result, nil
+}
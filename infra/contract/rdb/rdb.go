@@ -0,0 +1,50 @@
+package rdb
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+)
+
+// ErrNotFound is returned by QueryRow and Get when the query yields no rows.
+var ErrNotFound = errors.New("rdb: record not found")
+
+// DB runs SQL against a relational database. Queries use ? placeholders, which
+// implementations rewrite to the native syntax of the underlying driver.
+//
+//go:generate  mockgen -destination ../../../internal/mock/infra/contract/rdb/rdb_mock.go -package mock -source rdb.go DB
+type DB interface {
+	// Exec runs a statement that returns no rows and reports the number of rows affected.
+	Exec(ctx context.Context, query string, args ...any) (int64, error)
+	// Query scans all rows into dest, a pointer to a slice of structs, maps or scalars.
+	Query(ctx context.Context, dest any, query string, args ...any) error
+	// QueryRow scans the first row into dest, a pointer to a struct, map or scalar.
+	// It returns ErrNotFound if the query yields no rows.
+	QueryRow(ctx context.Context, dest any, query string, args ...any) error
+	// Transaction runs fn in a transaction that is committed if fn returns nil and
+	// rolled back otherwise, including when fn panics. Calling Transaction on the tx
+	// passed to fn opens a nested transaction backed by a savepoint.
+	Transaction(ctx context.Context, fn func(ctx context.Context, tx DB) error, opts ...TxOptFn) error
+	Ping(ctx context.Context) error
+	// Close releases the underlying connection pool. It is a no-op on a tx.
+	Close() error
+}
+
+type TxOption struct {
+	Isolation sql.IsolationLevel
+	ReadOnly  bool
+}
+
+type TxOptFn func(option *TxOption)
+
+func WithIsolation(level sql.IsolationLevel) TxOptFn {
+	return func(o *TxOption) {
+		o.Isolation = level
+	}
+}
+
+func WithReadOnly() TxOptFn {
+	return func(o *TxOption) {
+		o.ReadOnly = true
+	}
+}
@@ -0,0 +1,337 @@
+package memory
+
+import (
+	"bytes"
+	"container/list"
+	"context"
+	"fmt"
+	"math/rand/v2"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/me2seeks/forge/infra/contract/cache"
+)
+
+// memoryCache is a process-local cache.Cache. Entries are kept in LRU order and
+// bounded by maxEntries; expired entries are dropped lazily on access and swept by a
+// timing wheel so that keys nobody reads again don't linger.
+type memoryCache struct {
+	mu    sync.Mutex
+	items map[string]*list.Element
+	order *list.List
+	wheel *timingWheel
+
+	maxEntries int
+	jitter     float64
+	now        func() time.Time
+
+	stop     chan struct{}
+	stopOnce sync.Once
+}
+
+type entry struct {
+	key      string
+	value    []byte
+	expireAt time.Time
+}
+
+func (e *entry) expired(now time.Time) bool {
+	return !e.expireAt.IsZero() && !now.Before(e.expireAt)
+}
+
+type Option func(*memoryCache)
+
+// WithMaxEntries bounds the cache to n entries, evicting the least recently used
+// one beyond that. n <= 0 means unbounded, the default.
+func WithMaxEntries(n int) Option {
+	return func(c *memoryCache) {
+		c.maxEntries = n
+	}
+}
+
+// WithJitter randomly extends each ttl by up to fraction of itself, so keys set
+// together don't all expire at once and stampede the backing store.
+func WithJitter(fraction float64) Option {
+	return func(c *memoryCache) {
+		c.jitter = fraction
+	}
+}
+
+// WithWheel sets the tick and number of slots of the expiry wheel. Defaults to 1s and 60 slots.
+// A tick <= 0 disables background sweeping, leaving only lazy expiry.
+func WithWheel(tick time.Duration, slots int) Option {
+	return func(c *memoryCache) {
+		c.wheel = newTimingWheel(tick, slots)
+	}
+}
+
+// WithClock overrides time.Now, mainly for tests.
+func WithClock(now func() time.Time) Option {
+	return func(c *memoryCache) {
+		c.now = now
+	}
+}
+
+func New(opts ...Option) cache.Cache {
+	c := &memoryCache{
+		items: make(map[string]*list.Element),
+		order: list.New(),
+		wheel: newTimingWheel(time.Second, 60),
+		now:   time.Now,
+		stop:  make(chan struct{}),
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	if c.wheel.tick > 0 {
+		go c.sweep()
+	}
+	return c
+}
+
+func (c *memoryCache) Get(_ context.Context, key string) ([]byte, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	e, ok := c.lookup(key)
+	if !ok {
+		return nil, cache.ErrNotFound
+	}
+	return bytes.Clone(e.value), nil
+}
+
+func (c *memoryCache) MGet(_ context.Context, keys ...string) (map[string][]byte, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	values := make(map[string][]byte, len(keys))
+	for _, key := range keys {
+		if e, ok := c.lookup(key); ok {
+			values[key] = bytes.Clone(e.value)
+		}
+	}
+	return values, nil
+}
+
+func (c *memoryCache) Set(_ context.Context, key string, value []byte, ttl time.Duration) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.set(key, value, ttl)
+	return nil
+}
+
+func (c *memoryCache) SetNX(_ context.Context, key string, value []byte, ttl time.Duration) (bool, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if _, ok := c.lookup(key); ok {
+		return false, nil
+	}
+	c.set(key, value, ttl)
+	return true, nil
+}
+
+func (c *memoryCache) Delete(_ context.Context, keys ...string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for _, key := range keys {
+		if el, ok := c.items[key]; ok {
+			c.remove(el)
+		}
+	}
+	return nil
+}
+
+func (c *memoryCache) Exists(_ context.Context, key string) (bool, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	_, ok := c.lookup(key)
+	return ok, nil
+}
+
+func (c *memoryCache) Expire(_ context.Context, key string, ttl time.Duration) (bool, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	e, ok := c.lookup(key)
+	if !ok {
+		return false, nil
+	}
+	e.expireAt = c.expireAt(ttl)
+	c.wheel.schedule(key, e.expireAt)
+	return true, nil
+}
+
+func (c *memoryCache) TTL(_ context.Context, key string) (time.Duration, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	e, ok := c.lookup(key)
+	if !ok {
+		return 0, cache.ErrNotFound
+	}
+	if e.expireAt.IsZero() {
+		return 0, nil
+	}
+	return e.expireAt.Sub(c.now()), nil
+}
+
+func (c *memoryCache) IncrBy(_ context.Context, key string, delta int64) (int64, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var n int64
+	e, ok := c.lookup(key)
+	if ok {
+		var err error
+		if n, err = strconv.ParseInt(string(e.value), 10, 64); err != nil {
+			return 0, fmt.Errorf("value of key %s is not an integer: %w", key, err)
+		}
+	}
+
+	n += delta
+	value := []byte(strconv.FormatInt(n, 10))
+	if ok {
+		e.value = value
+	} else {
+		c.set(key, value, 0)
+	}
+	return n, nil
+}
+
+func (c *memoryCache) Close() error {
+	c.stopOnce.Do(func() { close(c.stop) })
+	return nil
+}
+
+// lookup returns the live entry of key and marks it recently used.
+func (c *memoryCache) lookup(key string) (*entry, bool) {
+	el, ok := c.items[key]
+	if !ok {
+		return nil, false
+	}
+
+	e := el.Value.(*entry)
+	if e.expired(c.now()) {
+		c.remove(el)
+		return nil, false
+	}
+	c.order.MoveToFront(el)
+	return e, true
+}
+
+// set stores a copy of value, the caller keeps ownership of its buffer.
+func (c *memoryCache) set(key string, value []byte, ttl time.Duration) {
+	e := &entry{key: key, value: bytes.Clone(value), expireAt: c.expireAt(ttl)}
+	c.wheel.schedule(key, e.expireAt)
+
+	if el, ok := c.items[key]; ok {
+		el.Value = e
+		c.order.MoveToFront(el)
+		return
+	}
+
+	c.items[key] = c.order.PushFront(e)
+	if c.maxEntries > 0 && c.order.Len() > c.maxEntries {
+		c.remove(c.order.Back())
+	}
+}
+
+func (c *memoryCache) expireAt(ttl time.Duration) time.Time {
+	if ttl <= 0 {
+		return time.Time{}
+	}
+	if c.jitter > 0 {
+		ttl += time.Duration(rand.Float64() * c.jitter * float64(ttl))
+	}
+	return c.now().Add(ttl)
+}
+
+func (c *memoryCache) remove(el *list.Element) {
+	e := c.order.Remove(el).(*entry)
+	delete(c.items, e.key)
+}
+
+func (c *memoryCache) sweep() {
+	ticker := time.NewTicker(c.wheel.tick)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-c.stop:
+			return
+		case <-ticker.C:
+			c.expireTick()
+		}
+	}
+}
+
+// expireTick drops the expired keys of the wheel slots elapsed since the last tick.
+func (c *memoryCache) expireTick() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := c.now()
+	for _, key := range c.wheel.advance(now) {
+		el, ok := c.items[key]
+		if !ok {
+			continue
+		}
+		if e := el.Value.(*entry); e.expired(now) {
+			c.remove(el)
+		} else if !e.expireAt.IsZero() {
+			// not due yet: a later round of the wheel, or a ttl extended since.
+			c.wheel.schedule(key, e.expireAt)
+		}
+	}
+}
+
+// timingWheel buckets keys by expiry time into slots of tick width, slot i holding
+// the keys due by the end of the ticks congruent to i. It is not safe for concurrent
+// use, the cache lock guards it.
+type timingWheel struct {
+	tick  time.Duration
+	slots []map[string]struct{}
+	last  int64 // last tick swept
+}
+
+func newTimingWheel(tick time.Duration, slots int) *timingWheel {
+	w := &timingWheel{tick: tick, slots: make([]map[string]struct{}, max(slots, 1))}
+	for i := range w.slots {
+		w.slots[i] = make(map[string]struct{})
+	}
+	return w
+}
+
+func (w *timingWheel) schedule(key string, expireAt time.Time) {
+	if w.tick <= 0 || expireAt.IsZero() {
+		return
+	}
+
+	due := expireAt.UnixNano()/int64(w.tick) + 1
+	w.slots[due%int64(len(w.slots))][key] = struct{}{}
+}
+
+// advance sweeps the slots of the ticks elapsed up to now and returns their keys.
+func (w *timingWheel) advance(now time.Time) []string {
+	current := now.UnixNano() / int64(w.tick)
+	if w.last == 0 || current-w.last > int64(len(w.slots)) {
+		w.last = current - int64(len(w.slots))
+	}
+
+	var keys []string
+	for t := w.last + 1; t <= current; t++ {
+		slot := w.slots[t%int64(len(w.slots))]
+		for key := range slot {
+			keys = append(keys, key)
+		}
+		clear(slot)
+	}
+	w.last = current
+	return keys
+}
@@ -0,0 +1,97 @@
+package memory
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/me2seeks/forge/infra/contract/cache"
+)
+
+func TestMemoryCache(t *testing.T) {
+	ctx := context.Background()
+	now := time.Unix(1000, 0)
+	c := New(WithMaxEntries(2), WithWheel(0, 0), WithClock(func() time.Time { return now }))
+	defer c.Close()
+
+	assert.NoError(t, c.Set(ctx, "a", []byte("1"), time.Minute))
+	assert.NoError(t, c.Set(ctx, "b", []byte("2"), 0))
+
+	v, err := c.Get(ctx, "a")
+	assert.NoError(t, err)
+	assert.Equal(t, []byte("1"), v)
+
+	ok, err := c.SetNX(ctx, "a", []byte("x"), 0)
+	assert.NoError(t, err)
+	assert.False(t, ok)
+
+	// "b" is the least recently used entry.
+	assert.NoError(t, c.Set(ctx, "c", []byte("3"), 0))
+	_, err = c.Get(ctx, "b")
+	assert.ErrorIs(t, err, cache.ErrNotFound)
+
+	ttl, err := c.TTL(ctx, "a")
+	assert.NoError(t, err)
+	assert.Equal(t, time.Minute, ttl)
+
+	now = now.Add(time.Minute)
+	exists, err := c.Exists(ctx, "a")
+	assert.NoError(t, err)
+	assert.False(t, exists)
+
+	n, err := c.IncrBy(ctx, "counter", 2)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2), n)
+	n, err = c.IncrBy(ctx, "counter", -1)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), n)
+
+	values, err := c.MGet(ctx, "c", "counter", "missing")
+	assert.NoError(t, err)
+	assert.Equal(t, map[string][]byte{"c": []byte("3"), "counter": []byte("1")}, values)
+
+	assert.NoError(t, c.Delete(ctx, "c"))
+	ok, err = c.Expire(ctx, "c", time.Second)
+	assert.NoError(t, err)
+	assert.False(t, ok)
+}
+
+func TestMemoryCache_Copies(t *testing.T) {
+	ctx := context.Background()
+	c := New(WithWheel(0, 0))
+	defer c.Close()
+
+	// neither the buffer passed to Set nor the ones returned alias the stored value.
+	buf := []byte("value")
+	assert.NoError(t, c.Set(ctx, "k", buf, 0))
+	buf[0] = 'X'
+
+	v, err := c.Get(ctx, "k")
+	assert.NoError(t, err)
+	v[0] = 'Y'
+	values, err := c.MGet(ctx, "k")
+	assert.NoError(t, err)
+	values["k"][0] = 'Z'
+
+	v, err = c.Get(ctx, "k")
+	assert.NoError(t, err)
+	assert.Equal(t, []byte("value"), v)
+}
+
+func TestMemoryCache_Wheel(t *testing.T) {
+	ctx := context.Background()
+	c := New(WithWheel(time.Millisecond, 8), WithJitter(0.5)).(*memoryCache)
+	defer c.Close()
+
+	assert.NoError(t, c.Set(ctx, "a", []byte("1"), 5*time.Millisecond))
+	assert.NoError(t, c.Set(ctx, "b", []byte("2"), 0))
+
+	// swept in the background, without anyone reading the key.
+	assert.Eventually(t, func() bool {
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		return len(c.items) == 1
+	}, time.Second, time.Millisecond)
+}
@@ -0,0 +1,134 @@
+package etcd
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	clientv3 "go.etcd.io/etcd/client/v3"
+	"go.etcd.io/etcd/client/v3/concurrency"
+
+	"github.com/me2seeks/forge/idgen"
+	"github.com/me2seeks/forge/infra/contract/election"
+	"github.com/me2seeks/forge/infra/impl/election/internal"
+	"github.com/me2seeks/forge/safego"
+)
+
+type etcdElection struct {
+	*internal.Leadership
+
+	client *clientv3.Client
+	prefix string
+	value  string
+	ttl    int
+
+	mu       sync.Mutex
+	session  *concurrency.Session
+	election *concurrency.Election
+}
+
+// Option is a function that configures the etcd election
+type Option func(*etcdElection)
+
+// WithTTL sets the TTL in seconds of the session backing leadership, i.e. how
+// long a crashed leader keeps it. Defaults to 10.
+func WithTTL(seconds int) Option {
+	return func(e *etcdElection) {
+		e.ttl = seconds
+	}
+}
+
+// WithValue sets the value advertised by the leader, e.g. its address. Defaults to a ULID.
+func WithValue(v string) Option {
+	return func(e *etcdElection) {
+		e.value = v
+	}
+}
+
+// New creates an election among the instances using the same name on client.
+func New(client *clientv3.Client, name string, opts ...Option) election.Election {
+	e := &etcdElection{
+		Leadership: internal.NewLeadership(),
+		client:     client,
+		prefix:     "/forge/election/" + name,
+		value:      idgen.NewULID(),
+		ttl:        10,
+	}
+	for _, opt := range opts {
+		opt(e)
+	}
+	return e
+}
+
+// current returns the election on a live session, creating a new session if the
+// previous one expired.
+func (e *etcdElection) current() (*concurrency.Election, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if e.session != nil {
+		select {
+		case <-e.session.Done():
+		default:
+			return e.election, nil
+		}
+	}
+
+	session, err := concurrency.NewSession(e.client, concurrency.WithTTL(e.ttl))
+	if err != nil {
+		return nil, fmt.Errorf("create etcd session failed: %w", err)
+	}
+	e.session = session
+	e.election = concurrency.NewElection(session, e.prefix)
+	return e.election, nil
+}
+
+func (e *etcdElection) Campaign(ctx context.Context) error {
+	el, err := e.current()
+	if err != nil {
+		return err
+	}
+	if err = el.Campaign(ctx, e.value); err != nil {
+		return err
+	}
+
+	e.mu.Lock()
+	done := e.session.Done()
+	e.mu.Unlock()
+
+	e.Set(true)
+	safego.Go(ctx, func() {
+		<-done
+		e.Set(false)
+	})
+	return nil
+}
+
+func (e *etcdElection) Resign(ctx context.Context) error {
+	if !e.IsLeader() {
+		return election.ErrNotLeader
+	}
+
+	e.mu.Lock()
+	el := e.election
+	e.mu.Unlock()
+
+	if err := el.Resign(ctx); err != nil {
+		return err
+	}
+	e.Set(false)
+	return nil
+}
+
+func (e *etcdElection) Close() error {
+	if e.IsLeader() {
+		_ = e.Resign(context.Background())
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.session == nil {
+		return nil
+	}
+	return e.session.Close()
+}
@@ -0,0 +1,56 @@
+package internal
+
+import (
+	"sync"
+)
+
+// Leadership tracks whether an instance is leader and notifies observers of changes.
+type Leadership struct {
+	mu        sync.Mutex
+	leader    bool
+	observers map[int]func(leader bool)
+	nextID    int
+}
+
+func NewLeadership() *Leadership {
+	return &Leadership{observers: make(map[int]func(leader bool))}
+}
+
+func (l *Leadership) IsLeader() bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.leader
+}
+
+func (l *Leadership) Observe(fn func(leader bool)) (cancel func()) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	id := l.nextID
+	l.nextID++
+	l.observers[id] = fn
+	return func() {
+		l.mu.Lock()
+		defer l.mu.Unlock()
+		delete(l.observers, id)
+	}
+}
+
+// Set records the leadership state and notifies the observers if it changed.
+func (l *Leadership) Set(leader bool) {
+	l.mu.Lock()
+	if l.leader == leader {
+		l.mu.Unlock()
+		return
+	}
+	l.leader = leader
+	observers := make([]func(bool), 0, len(l.observers))
+	for _, fn := range l.observers {
+		observers = append(observers, fn)
+	}
+	l.mu.Unlock()
+
+	for _, fn := range observers {
+		fn(leader)
+	}
+}
@@ -0,0 +1,187 @@
+package redis
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+
+	"github.com/me2seeks/forge/idgen"
+	"github.com/me2seeks/forge/infra/contract/election"
+	"github.com/me2seeks/forge/infra/impl/election/internal"
+	"github.com/me2seeks/forge/logs"
+	"github.com/me2seeks/forge/safego"
+)
+
+// renewScript extends the lock only if this instance still holds it.
+var renewScript = redis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("PEXPIRE", KEYS[1], ARGV[2])
+end
+return 0`)
+
+// releaseScript deletes the lock only if this instance still holds it.
+var releaseScript = redis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("DEL", KEYS[1])
+end
+return 0`)
+
+// redisElection holds leadership through a lock key renewed in the background.
+// Unlike etcd, a single Redis node gives no fencing guarantee: a leader paused
+// for longer than the TTL may briefly overlap with its successor.
+type redisElection struct {
+	*internal.Leadership
+
+	client redis.UniversalClient
+	key    string
+	value  string
+	ttl    time.Duration
+	retry  time.Duration
+
+	mu     sync.Mutex
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// Option is a function that configures the redis election
+type Option func(*redisElection)
+
+// WithTTL sets the TTL of the lock, i.e. how long a crashed leader keeps it. It
+// is renewed every third of it. Defaults to 10s.
+func WithTTL(ttl time.Duration) Option {
+	return func(e *redisElection) {
+		e.ttl = ttl
+	}
+}
+
+// WithRetryInterval sets how often Campaign retries to take the lock. Defaults to 1s.
+func WithRetryInterval(d time.Duration) Option {
+	return func(e *redisElection) {
+		e.retry = d
+	}
+}
+
+// WithValue sets the value stored in the lock. It must be unique per instance. Defaults to a ULID.
+func WithValue(v string) Option {
+	return func(e *redisElection) {
+		e.value = v
+	}
+}
+
+// New creates an election among the instances using the same name on client.
+func New(client redis.UniversalClient, name string, opts ...Option) election.Election {
+	e := &redisElection{
+		Leadership: internal.NewLeadership(),
+		client:     client,
+		key:        "forge:election:" + name,
+		value:      idgen.NewULID(),
+		ttl:        10 * time.Second,
+		retry:      time.Second,
+	}
+	for _, opt := range opts {
+		opt(e)
+	}
+	return e
+}
+
+func (e *redisElection) Campaign(ctx context.Context) error {
+	ticker := time.NewTicker(e.retry)
+	defer ticker.Stop()
+
+	for {
+		ok, err := e.client.SetNX(ctx, e.key, e.value, e.ttl).Result()
+		if err != nil && ctx.Err() == nil {
+			logs.CtxWarnf(ctx, "[election] take lock %s failed: %v", e.key, err)
+		}
+		if ok {
+			break
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+
+	renewCtx, cancel := context.WithCancel(context.WithoutCancel(ctx))
+	done := make(chan struct{})
+	e.mu.Lock()
+	e.cancel, e.done = cancel, done
+	e.mu.Unlock()
+
+	e.Set(true)
+	safego.Go(renewCtx, func() {
+		defer close(done)
+		e.renew(renewCtx)
+	})
+	return nil
+}
+
+// renew extends the lock until ctx is canceled or the lock is lost.
+func (e *redisElection) renew(ctx context.Context) {
+	ticker := time.NewTicker(e.ttl / 3)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		n, err := renewScript.Run(ctx, e.client, []string{e.key}, e.value, e.ttl.Milliseconds()).Int()
+		if ctx.Err() != nil {
+			return
+		}
+		if err == nil && n == 1 {
+			continue
+		}
+		if err != nil {
+			logs.CtxWarnf(ctx, "[election] renew lock %s failed: %v", e.key, err)
+			// Keep trying while the lock may still be ours.
+			if ttl, ttlErr := e.client.PTTL(ctx, e.key).Result(); ttlErr != nil || ttl > e.ttl/3 {
+				continue
+			}
+		}
+		logs.CtxWarnf(ctx, "[election] lost lock %s", e.key)
+		e.Set(false)
+		return
+	}
+}
+
+func (e *redisElection) stopRenewal() {
+	e.mu.Lock()
+	cancel, done := e.cancel, e.done
+	e.cancel, e.done = nil, nil
+	e.mu.Unlock()
+
+	if cancel != nil {
+		cancel()
+		<-done
+	}
+}
+
+func (e *redisElection) Resign(ctx context.Context) error {
+	if !e.IsLeader() {
+		return election.ErrNotLeader
+	}
+	e.stopRenewal()
+	e.Set(false)
+
+	if err := releaseScript.Run(ctx, e.client, []string{e.key}, e.value).Err(); err != nil && !errors.Is(err, redis.Nil) {
+		return err
+	}
+	return nil
+}
+
+func (e *redisElection) Close() error {
+	if e.IsLeader() {
+		return e.Resign(context.Background())
+	}
+	e.stopRenewal()
+	return nil
+}
@@ -0,0 +1,62 @@
+package redis
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/me2seeks/forge/infra/contract/election"
+)
+
+func newElection(t *testing.T, mr *miniredis.Miniredis) election.Election {
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { _ = client.Close() })
+	e := New(client, "jobs", WithTTL(60*time.Millisecond), WithRetryInterval(5*time.Millisecond))
+	t.Cleanup(func() { _ = e.Close() })
+	return e
+}
+
+func TestCampaign(t *testing.T) {
+	ctx := context.Background()
+	mr := miniredis.RunT(t)
+	a, b := newElection(t, mr), newElection(t, mr)
+
+	require.NoError(t, a.Campaign(ctx))
+	assert.True(t, a.IsLeader())
+
+	timeout, cancel := context.WithTimeout(ctx, 30*time.Millisecond)
+	defer cancel()
+	assert.ErrorIs(t, b.Campaign(timeout), context.DeadlineExceeded)
+	assert.False(t, b.IsLeader())
+
+	require.NoError(t, a.Resign(ctx))
+	assert.False(t, a.IsLeader())
+	assert.ErrorIs(t, a.Resign(ctx), election.ErrNotLeader)
+
+	require.NoError(t, b.Campaign(ctx))
+	assert.True(t, b.IsLeader())
+}
+
+func TestLostLeadership(t *testing.T) {
+	ctx := context.Background()
+	mr := miniredis.RunT(t)
+	e := newElection(t, mr)
+
+	var ran atomic.Bool
+	err := election.RunAsLeader(ctx, e, func(ctx context.Context) error {
+		ran.Store(true)
+		// Another process takes over the lock, e.g. after it expired during a pause.
+		require.NoError(t, mr.Set("forge:election:jobs", "other"))
+		<-ctx.Done()
+		return ctx.Err()
+	})
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.True(t, ran.Load())
+	assert.False(t, e.IsLeader())
+}
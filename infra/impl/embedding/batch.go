@@ -0,0 +1,88 @@
+package embedding
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/me2seeks/forge/infra/contract/embedding"
+)
+
+type batchingEmbedder struct {
+	embedding.Embedder
+	batchSize int
+
+	mu       sync.Mutex
+	interval time.Duration
+	next     time.Time
+}
+
+// BatchOption configures WithBatching.
+type BatchOption func(*batchingEmbedder)
+
+// WithRequestsPerSecond spaces the requests sent to the provider to at most rps per second.
+func WithRequestsPerSecond(rps float64) BatchOption {
+	return func(b *batchingEmbedder) {
+		if rps > 0 {
+			b.interval = time.Duration(float64(time.Second) / rps)
+		}
+	}
+}
+
+// WithBatching splits the texts given to Embed into requests of at most batchSize
+// texts, the limit of most providers, sent sequentially.
+func WithBatching(e embedding.Embedder, batchSize int, opts ...BatchOption) embedding.Embedder {
+	b := &batchingEmbedder{Embedder: e, batchSize: batchSize}
+	for _, opt := range opts {
+		opt(b)
+	}
+	return b
+}
+
+func (b *batchingEmbedder) Embed(ctx context.Context, texts []string) ([][]float32, error) {
+	size := b.batchSize
+	if size <= 0 {
+		size = len(texts)
+	}
+
+	vectors := make([][]float32, 0, len(texts))
+	for start := 0; start < len(texts); start += size {
+		if err := b.wait(ctx); err != nil {
+			return nil, err
+		}
+
+		batch, err := b.Embedder.Embed(ctx, texts[start:min(start+size, len(texts))])
+		if err != nil {
+			return nil, err
+		}
+		vectors = append(vectors, batch...)
+	}
+	return vectors, nil
+}
+
+// wait blocks until the next request may be sent.
+func (b *batchingEmbedder) wait(ctx context.Context) error {
+	if b.interval <= 0 {
+		return nil
+	}
+
+	b.mu.Lock()
+	now := time.Now()
+	at := now
+	if b.next.After(now) {
+		at = b.next
+	}
+	b.next = at.Add(b.interval)
+	b.mu.Unlock()
+
+	if delay := at.Sub(now); delay > 0 {
+		timer := time.NewTimer(delay)
+		defer timer.Stop()
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+	return nil
+}
@@ -0,0 +1,45 @@
+package embedding
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type lengthEmbedder struct {
+	calls [][]string
+}
+
+func (e *lengthEmbedder) Embed(_ context.Context, texts []string) ([][]float32, error) {
+	e.calls = append(e.calls, texts)
+	vectors := make([][]float32, len(texts))
+	for i, text := range texts {
+		vectors[i] = []float32{float32(len(text))}
+	}
+	return vectors, nil
+}
+
+func (e *lengthEmbedder) Model() string   { return "length" }
+func (e *lengthEmbedder) Dimensions() int { return 1 }
+
+func TestWithBatching(t *testing.T) {
+	inner := &lengthEmbedder{}
+	e := WithBatching(inner, 2, WithRequestsPerSecond(1000))
+
+	vectors, err := e.Embed(context.Background(), []string{"a", "bb", "ccc"})
+	assert.NoError(t, err)
+	assert.Equal(t, [][]float32{{1}, {2}, {3}}, vectors)
+	assert.Equal(t, [][]string{{"a", "bb"}, {"ccc"}}, inner.calls)
+	assert.Equal(t, "length", e.Model())
+}
+
+func TestNewWithConfig_RequestsPerSecond(t *testing.T) {
+	e, err := NewWithConfig(Config{Type: "ollama", RequestsPerSecond: 2})
+	assert.NoError(t, err)
+	b, ok := e.(*batchingEmbedder)
+	assert.True(t, ok)
+	assert.Equal(t, 500*time.Millisecond, b.interval)
+	assert.Zero(t, b.batchSize)
+}
@@ -0,0 +1,56 @@
+package embedding
+
+import (
+	"fmt"
+
+	"github.com/me2seeks/forge/conf"
+	"github.com/me2seeks/forge/infra/contract/embedding"
+	"github.com/me2seeks/forge/infra/impl/embedding/ollama"
+	"github.com/me2seeks/forge/infra/impl/embedding/openai"
+)
+
+type Embedder = embedding.Embedder
+
+// Config selects and configures the embedder built by NewWithConfig.
+type Config struct {
+	Type       string `env:"EMBEDDING_TYPE" yaml:"type" json:"type" required:"true"`
+	BaseURL    string `env:"EMBEDDING_BASE_URL" yaml:"base_url" json:"base_url"`
+	APIKey     string `env:"EMBEDDING_API_KEY" yaml:"api_key" json:"api_key"`
+	Model      string `env:"EMBEDDING_MODEL" yaml:"model" json:"model"`
+	Dimensions int    `env:"EMBEDDING_DIMENSIONS" yaml:"dimensions" json:"dimensions"`
+	// BatchSize splits Embed calls into requests of at most BatchSize texts if positive.
+	BatchSize int `env:"EMBEDDING_BATCH_SIZE" yaml:"batch_size" json:"batch_size"`
+	// RequestsPerSecond spaces the requests sent to the provider if positive.
+	RequestsPerSecond float64 `env:"EMBEDDING_RPS" yaml:"requests_per_second" json:"requests_per_second"`
+}
+
+// New creates the embedder configured by the EMBEDDING_* env vars.
+func New() (Embedder, error) {
+	var cfg Config
+	if err := conf.Load(&cfg); err != nil {
+		return nil, err
+	}
+	return NewWithConfig(cfg)
+}
+
+func NewWithConfig(cfg Config) (Embedder, error) {
+	var e Embedder
+
+	switch cfg.Type {
+	case "openai":
+		var opts []openai.Option
+		if cfg.Dimensions > 0 {
+			opts = append(opts, openai.WithDimensions(cfg.Dimensions))
+		}
+		e = openai.New(cfg.BaseURL, cfg.APIKey, cfg.Model, opts...)
+	case "ollama":
+		e = ollama.New(cfg.BaseURL, cfg.Model)
+	default:
+		return nil, fmt.Errorf("unknown embedding type: %s", cfg.Type)
+	}
+
+	if cfg.BatchSize > 0 || cfg.RequestsPerSecond > 0 {
+		e = WithBatching(e, cfg.BatchSize, WithRequestsPerSecond(cfg.RequestsPerSecond))
+	}
+	return e, nil
+}
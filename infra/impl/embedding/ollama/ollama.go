@@ -0,0 +1,100 @@
+package ollama
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync/atomic"
+
+	"github.com/me2seeks/forge/infra/contract/embedding"
+	"github.com/me2seeks/forge/sonic"
+)
+
+// ollamaEmbedder calls the /api/embed endpoint of a local Ollama server.
+type ollamaEmbedder struct {
+	client     *http.Client
+	baseURL    string
+	model      string
+	dimensions atomic.Int64
+}
+
+type Option func(*ollamaEmbedder)
+
+// WithHTTPClient replaces http.DefaultClient.
+func WithHTTPClient(client *http.Client) Option {
+	return func(e *ollamaEmbedder) {
+		e.client = client
+	}
+}
+
+// New creates an embedder for model. baseURL is the server root, e.g. http://localhost:11434.
+func New(baseURL, model string, opts ...Option) embedding.Embedder {
+	e := &ollamaEmbedder{
+		client:  http.DefaultClient,
+		baseURL: strings.TrimSuffix(baseURL, "/"),
+		model:   model,
+	}
+	for _, opt := range opts {
+		opt(e)
+	}
+	return e
+}
+
+type embedRequest struct {
+	Model string   `json:"model"`
+	Input []string `json:"input"`
+}
+
+type embedResponse struct {
+	Embeddings [][]float32 `json:"embeddings"`
+}
+
+func (e *ollamaEmbedder) Embed(ctx context.Context, texts []string) ([][]float32, error) {
+	if len(texts) == 0 {
+		return nil, nil
+	}
+
+	body, err := sonic.Marshal(embedRequest{Model: e.model, Input: texts})
+	if err != nil {
+		return nil, err
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, e.baseURL+"/api/embed", bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+
+	httpResp, err := e.client.Do(httpReq)
+	if err != nil {
+		return nil, fmt.Errorf("embedding request failed: %w", err)
+	}
+	defer httpResp.Body.Close()
+
+	if httpResp.StatusCode != http.StatusOK {
+		msg, _ := io.ReadAll(io.LimitReader(httpResp.Body, 4096))
+		return nil, fmt.Errorf("embedding request failed, status %d: %s", httpResp.StatusCode, msg)
+	}
+
+	var resp embedResponse
+	if err = sonic.UnmarshalRead(httpResp.Body, &resp); err != nil {
+		return nil, fmt.Errorf("decode embedding response failed: %w", err)
+	}
+	if len(resp.Embeddings) != len(texts) {
+		return nil, fmt.Errorf("embedding response has %d vectors for %d texts", len(resp.Embeddings), len(texts))
+	}
+
+	e.dimensions.CompareAndSwap(0, int64(len(resp.Embeddings[0])))
+	return resp.Embeddings, nil
+}
+
+func (e *ollamaEmbedder) Model() string {
+	return e.model
+}
+
+func (e *ollamaEmbedder) Dimensions() int {
+	return int(e.dimensions.Load())
+}
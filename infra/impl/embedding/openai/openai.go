@@ -0,0 +1,130 @@
+package openai
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync/atomic"
+
+	"github.com/me2seeks/forge/infra/contract/embedding"
+	"github.com/me2seeks/forge/sonic"
+)
+
+// openaiEmbedder calls the /embeddings endpoint of an OpenAI compatible API.
+type openaiEmbedder struct {
+	client     *http.Client
+	baseURL    string
+	apiKey     string
+	model      string
+	dimensions atomic.Int64
+	// requestDimensions asks the API to shorten vectors, for models supporting it.
+	requestDimensions bool
+}
+
+type Option func(*openaiEmbedder)
+
+// WithHTTPClient replaces http.DefaultClient.
+func WithHTTPClient(client *http.Client) Option {
+	return func(e *openaiEmbedder) {
+		e.client = client
+	}
+}
+
+// WithDimensions requests vectors of n dimensions from models supporting it.
+func WithDimensions(n int) Option {
+	return func(e *openaiEmbedder) {
+		e.dimensions.Store(int64(n))
+		e.requestDimensions = true
+	}
+}
+
+// New creates an embedder for model. baseURL is the API root, e.g. https://api.openai.com/v1.
+func New(baseURL, apiKey, model string, opts ...Option) embedding.Embedder {
+	e := &openaiEmbedder{
+		client:  http.DefaultClient,
+		baseURL: strings.TrimSuffix(baseURL, "/"),
+		apiKey:  apiKey,
+		model:   model,
+	}
+	for _, opt := range opts {
+		opt(e)
+	}
+	return e
+}
+
+type embeddingRequest struct {
+	Model      string   `json:"model"`
+	Input      []string `json:"input"`
+	Dimensions int      `json:"dimensions,omitempty"`
+}
+
+type embeddingResponse struct {
+	Data []struct {
+		Index     int       `json:"index"`
+		Embedding []float32 `json:"embedding"`
+	} `json:"data"`
+}
+
+func (e *openaiEmbedder) Embed(ctx context.Context, texts []string) ([][]float32, error) {
+	if len(texts) == 0 {
+		return nil, nil
+	}
+
+	req := embeddingRequest{Model: e.model, Input: texts}
+	if e.requestDimensions {
+		req.Dimensions = e.Dimensions()
+	}
+	body, err := sonic.Marshal(req)
+	if err != nil {
+		return nil, err
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, e.baseURL+"/embeddings", bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+	if e.apiKey != "" {
+		httpReq.Header.Set("Authorization", "Bearer "+e.apiKey)
+	}
+
+	httpResp, err := e.client.Do(httpReq)
+	if err != nil {
+		return nil, fmt.Errorf("embedding request failed: %w", err)
+	}
+	defer httpResp.Body.Close()
+
+	if httpResp.StatusCode != http.StatusOK {
+		msg, _ := io.ReadAll(io.LimitReader(httpResp.Body, 4096))
+		return nil, fmt.Errorf("embedding request failed, status %d: %s", httpResp.StatusCode, msg)
+	}
+
+	var resp embeddingResponse
+	if err = sonic.UnmarshalRead(httpResp.Body, &resp); err != nil {
+		return nil, fmt.Errorf("decode embedding response failed: %w", err)
+	}
+	if len(resp.Data) != len(texts) {
+		return nil, fmt.Errorf("embedding response has %d vectors for %d texts", len(resp.Data), len(texts))
+	}
+
+	vectors := make([][]float32, len(texts))
+	for _, d := range resp.Data {
+		if d.Index < 0 || d.Index >= len(vectors) {
+			return nil, fmt.Errorf("embedding response has invalid index %d", d.Index)
+		}
+		vectors[d.Index] = d.Embedding
+	}
+	e.dimensions.CompareAndSwap(0, int64(len(vectors[0])))
+	return vectors, nil
+}
+
+func (e *openaiEmbedder) Model() string {
+	return e.model
+}
+
+func (e *openaiEmbedder) Dimensions() int {
+	return int(e.dimensions.Load())
+}
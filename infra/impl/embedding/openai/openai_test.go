@@ -0,0 +1,34 @@
+package openai
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/me2seeks/forge/sonic"
+)
+
+func TestEmbed(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/v1/embeddings", r.URL.Path)
+		assert.Equal(t, "Bearer key", r.Header.Get("Authorization"))
+
+		var req embeddingRequest
+		assert.NoError(t, sonic.UnmarshalRead(r.Body, &req))
+		assert.Equal(t, embeddingRequest{Model: "m", Input: []string{"a", "b"}, Dimensions: 2}, req)
+
+		// out of order on purpose, vectors must be placed by index.
+		_, _ = w.Write([]byte(`{"data":[{"index":1,"embedding":[3,4]},{"index":0,"embedding":[1,2]}]}`))
+	}))
+	defer srv.Close()
+
+	e := New(srv.URL+"/v1/", "key", "m", WithDimensions(2))
+	vectors, err := e.Embed(context.Background(), []string{"a", "b"})
+	assert.NoError(t, err)
+	assert.Equal(t, [][]float32{{1, 2}, {3, 4}}, vectors)
+	assert.Equal(t, 2, e.Dimensions())
+	assert.Equal(t, "m", e.Model())
+}
@@ -2,10 +2,10 @@ package es
 
 import (
 	"fmt"
-	"os"
 
 	elasticsearch7 "github.com/elastic/go-elasticsearch/v7"
 	elasticsearchv8 "github.com/elastic/go-elasticsearch/v8"
+	"github.com/me2seeks/forge/conf"
 	"github.com/me2seeks/forge/infra/contract/es"
 )
 
@@ -20,22 +20,38 @@ type (
 	Request         = es.Request
 )
 
+// Config selects and configures the client built by NewWithConfig.
+type Config struct {
+	Version   string   `env:"ES_VERSION" yaml:"version" json:"version" required:"true"`
+	Addresses []string `env:"ES_ADDR" yaml:"addresses" json:"addresses"`
+	Username  string   `env:"ES_USERNAME" yaml:"username" json:"username"`
+	Password  string   `env:"ES_PASSWORD" yaml:"password" json:"password"`
+}
+
+// New creates the client configured by the ES_* env vars.
 func New() (Client, error) {
-	v := os.Getenv("ES_VERSION")
-	switch v {
+	var cfg Config
+	if err := conf.Load(&cfg); err != nil {
+		return nil, err
+	}
+	return NewWithConfig(cfg)
+}
+
+func NewWithConfig(cfg Config) (Client, error) {
+	switch cfg.Version {
 	case "v8":
 		return NewES8(elasticsearchv8.Config{
-			Addresses: []string{os.Getenv("ES_ADDR")},
-			Username:  os.Getenv("ES_USERNAME"),
-			Password:  os.Getenv("ES_PASSWORD"),
+			Addresses: cfg.Addresses,
+			Username:  cfg.Username,
+			Password:  cfg.Password,
 		})
 	case "v7":
 		return NewES7(elasticsearch7.Config{
-			Addresses: []string{os.Getenv("ES_ADDR")},
-			Username:  os.Getenv("ES_USERNAME"),
-			Password:  os.Getenv("ES_PASSWORD"),
+			Addresses: cfg.Addresses,
+			Username:  cfg.Username,
+			Password:  cfg.Password,
 		})
 	default:
-		return nil, fmt.Errorf("unsupported es version %s", v)
+		return nil, fmt.Errorf("unsupported es version %s", cfg.Version)
 	}
 }
@@ -0,0 +1,200 @@
+package featureflag
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/me2seeks/forge/conf"
+	"github.com/me2seeks/forge/infra/contract/featureflag"
+	"github.com/me2seeks/forge/logs"
+	"github.com/me2seeks/forge/safego"
+)
+
+type Client = featureflag.Client
+
+type client struct {
+	flags atomic.Pointer[map[string]*featureflag.Flag]
+
+	mu     sync.Mutex
+	subs   map[int]func(names []string)
+	nextID int
+
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+type Option func(*options)
+
+type options struct {
+	interval time.Duration
+}
+
+// WithInterval sets how often the source is checked for changes. Defaults to 10s.
+func WithInterval(d time.Duration) Option {
+	return func(o *options) {
+		o.interval = d
+	}
+}
+
+func newOptions(opts []Option) *options {
+	o := &options{interval: 10 * time.Second}
+	for _, opt := range opts {
+		opt(o)
+	}
+	return o
+}
+
+// fileConfig is the layout of a flag file:
+//
+//	flags:
+//	  - name: es_v8
+//	    enabled: true
+//	    percentage: 10
+type fileConfig struct {
+	Flags []*featureflag.Flag `yaml:"flags" json:"flags"`
+}
+
+// NewFile serves the flags of the YAML or JSON file at path, reloading it when it
+// changes. A reload that fails keeps the previous flags.
+func NewFile(ctx context.Context, path string, opts ...Option) (Client, error) {
+	o := newOptions(opts)
+
+	var cfg fileConfig
+	if err := conf.Load(&cfg, conf.WithFiles(path)); err != nil {
+		return nil, fmt.Errorf("load feature flags failed: %w", err)
+	}
+
+	c := newClient(cfg.Flags)
+	watchCtx := c.ctx(ctx)
+	safego.Go(ctx, func() {
+		defer close(c.done)
+		conf.Watch(watchCtx, o.interval, func(cfg *fileConfig) {
+			c.update(cfg.Flags)
+		}, conf.WithFiles(path))
+	})
+	return c, nil
+}
+
+// NewRemote serves the flags of provider, polling it every interval. The first
+// load must succeed, later failures are logged and keep the previous flags.
+func NewRemote(ctx context.Context, provider featureflag.Provider, opts ...Option) (Client, error) {
+	o := newOptions(opts)
+
+	flags, err := provider.Load(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("load feature flags failed: %w", err)
+	}
+
+	c := newClient(flags)
+	pollCtx := c.ctx(ctx)
+	safego.Go(ctx, func() {
+		defer close(c.done)
+		ticker := time.NewTicker(o.interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-pollCtx.Done():
+				return
+			case <-ticker.C:
+			}
+
+			flags, err := provider.Load(pollCtx)
+			if err != nil {
+				logs.CtxWarnf(pollCtx, "[featureflag] reload failed, keeping previous flags: %v", err)
+				continue
+			}
+			c.update(flags)
+		}
+	})
+	return c, nil
+}
+
+func newClient(flags []*featureflag.Flag) *client {
+	c := &client{
+		subs: make(map[int]func(names []string)),
+		done: make(chan struct{}),
+	}
+	c.flags.Store(index(flags))
+	return c
+}
+
+// ctx returns the context of the background reloads, canceled by Close.
+func (c *client) ctx(parent context.Context) context.Context {
+	ctx, cancel := context.WithCancel(context.WithoutCancel(parent))
+	c.cancel = cancel
+	return ctx
+}
+
+func index(flags []*featureflag.Flag) *map[string]*featureflag.Flag {
+	m := make(map[string]*featureflag.Flag, len(flags))
+	for _, f := range flags {
+		m[f.Name] = f
+	}
+	return &m
+}
+
+func (c *client) Value(_ context.Context, name, key string) (string, bool) {
+	f, ok := (*c.flags.Load())[name]
+	if !ok {
+		return "", false
+	}
+	return f.Evaluate(key)
+}
+
+func (c *client) Subscribe(fn func(names []string)) (cancel func()) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	id := c.nextID
+	c.nextID++
+	c.subs[id] = fn
+	return func() {
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		delete(c.subs, id)
+	}
+}
+
+func (c *client) Close() error {
+	c.cancel()
+	<-c.done
+	return nil
+}
+
+// update replaces the flags and notifies the subscribers of the changed ones.
+func (c *client) update(flags []*featureflag.Flag) {
+	next := index(flags)
+	prev := *c.flags.Swap(next)
+
+	var changed []string
+	for name, f := range *next {
+		if !f.Equal(prev[name]) {
+			changed = append(changed, name)
+		}
+	}
+	for name := range prev {
+		if _, ok := (*next)[name]; !ok {
+			changed = append(changed, name)
+		}
+	}
+	if len(changed) == 0 {
+		return
+	}
+	slices.Sort(changed)
+
+	c.mu.Lock()
+	subs := make([]func([]string), 0, len(c.subs))
+	for _, fn := range c.subs {
+		subs = append(subs, fn)
+	}
+	c.mu.Unlock()
+
+	for _, fn := range subs {
+		fn(changed)
+	}
+}
@@ -0,0 +1,111 @@
+package featureflag
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/me2seeks/forge/infra/contract/featureflag"
+)
+
+var esV8 = featureflag.BoolFlag{Name: "es_v8", Default: false}
+
+func TestFile(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "flags.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(`
+flags:
+  - name: es_v8
+    enabled: true
+    percentage: 0
+    allow: [tenant-1]
+  - name: ranker
+    enabled: true
+    value: v2
+`), 0o644))
+
+	c, err := NewFile(ctx, path, WithInterval(10*time.Millisecond))
+	require.NoError(t, err)
+	defer c.Close()
+
+	assert.True(t, esV8.Get(ctx, c, "tenant-1"))
+	assert.False(t, esV8.Get(ctx, c, "tenant-2"))
+	assert.Equal(t, "v2", featureflag.StringFlag{Name: "ranker", Default: "v1"}.Get(ctx, c, "any"))
+	assert.Equal(t, "v1", featureflag.StringFlag{Name: "missing", Default: "v1"}.Get(ctx, c, "any"))
+
+	var mu sync.Mutex
+	var changed []string
+	c.Subscribe(func(names []string) {
+		mu.Lock()
+		defer mu.Unlock()
+		changed = append(changed, names...)
+	})
+
+	// Make sure the new modification time differs from the first one.
+	time.Sleep(20 * time.Millisecond)
+	require.NoError(t, os.WriteFile(path, []byte(`
+flags:
+  - name: es_v8
+    enabled: true
+  - name: ranker
+    enabled: true
+    value: v2
+`), 0o644))
+	require.NoError(t, os.Chtimes(path, time.Now(), time.Now().Add(time.Second)))
+
+	assert.Eventually(t, func() bool { return esV8.Get(ctx, c, "tenant-2") }, time.Second, 10*time.Millisecond)
+	mu.Lock()
+	assert.Equal(t, []string{"es_v8"}, changed)
+	mu.Unlock()
+}
+
+func TestPercentage(t *testing.T) {
+	pct := 30.0
+	f := &featureflag.Flag{Name: "rollout", Enabled: true, Percentage: &pct}
+
+	in := 0
+	for i := range 10000 {
+		if _, ok := f.Evaluate(string(rune(i))); ok {
+			in++
+		}
+	}
+	assert.InDelta(t, 3000, in, 300)
+
+	// Growing the rollout keeps the keys already in it.
+	wider := 60.0
+	g := &featureflag.Flag{Name: "rollout", Enabled: true, Percentage: &wider}
+	for i := range 1000 {
+		key := string(rune(i))
+		if _, ok := f.Evaluate(key); ok {
+			_, ok = g.Evaluate(key)
+			assert.True(t, ok)
+		}
+	}
+
+	f.Enabled = false
+	_, ok := f.Evaluate("any")
+	assert.False(t, ok)
+}
+
+type staticProvider struct {
+	flags []*featureflag.Flag
+}
+
+func (p *staticProvider) Load(context.Context) ([]*featureflag.Flag, error) {
+	return p.flags, nil
+}
+
+func TestRemote(t *testing.T) {
+	ctx := context.Background()
+	c, err := NewRemote(ctx, &staticProvider{flags: []*featureflag.Flag{{Name: "es_v8", Enabled: true}}},
+		WithInterval(time.Hour))
+	require.NoError(t, err)
+	assert.True(t, esV8.Get(ctx, c, "any"))
+	assert.NoError(t, c.Close())
+}
@@ -0,0 +1,295 @@
+package arangodb
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strings"
+
+	"github.com/me2seeks/forge/infra/contract/graph"
+	"github.com/me2seeks/forge/infra/impl/graph/internal"
+)
+
+// defaultMaxNodes bounds the graphs loaded by the algorithms computed by the
+// client.
+const defaultMaxNodes = 10000
+
+// ShortestPath finds the shortest path between two nodes with AQL
+// K_SHORTEST_PATHS, or the path of lowest total weight if config sets
+// "relationshipWeightProperty", or all the shortest paths, up to
+// config["limit"] (100 by default), if config["all"] is true. config may also
+// set "relationshipTypes", "direction" (OUTGOING, INCOMING or BOTH, the
+// default) and "maxDepth", which filter the paths as they are enumerated.
+func (c *arangoClient) ShortestPath(ctx context.Context, sourceNodeID, targetNodeID string, config map[string]any) ([]*graph.Path, error) {
+	aql, bindVars := c.buildShortestPathAQL(sourceNodeID, targetNodeID, config)
+	results, err := c.query(ctx, aql, bindVars)
+	if err != nil {
+		return nil, err
+	}
+
+	var paths []*graph.Path
+	var shortest float64
+	for i, result := range results {
+		obj, ok := result.(map[string]any)
+		if !ok || !isPath(obj) {
+			continue
+		}
+		// Paths are enumerated by increasing weight, the shortest ones first.
+		weight := toFloat(obj["weight"])
+		if i == 0 {
+			shortest = weight
+		} else if weight > shortest {
+			break
+		}
+		paths = append(paths, toGraphPath(obj))
+	}
+	return paths, nil
+}
+
+func (c *arangoClient) buildShortestPathAQL(sourceNodeID, targetNodeID string, config map[string]any) (string, map[string]any) {
+	direction := "ANY"
+	switch strings.ToUpper(internal.ConfigString(config, "direction")) {
+	case "OUTGOING":
+		direction = "OUTBOUND"
+	case "INCOMING":
+		direction = "INBOUND"
+	}
+	b := c.newBuilder()
+	var sb strings.Builder
+	sb.WriteString("FOR p IN " + direction + " K_SHORTEST_PATHS " + b.bind(sourceNodeID) + " TO " + b.bind(targetNodeID) + " GRAPH " + b.graphName())
+	if weight := internal.ConfigString(config, "relationshipWeightProperty"); weight != "" {
+		sb.WriteString(" OPTIONS {weightAttribute: " + b.bind(weight) + ", defaultWeight: 1}")
+	}
+	if types := internal.ConfigStrings(config, "relationshipTypes"); len(types) > 0 {
+		sb.WriteString(" FILTER p.edges[*]." + labelAttr + " ALL IN " + b.bind(types))
+	}
+	if maxDepth, ok := internal.ConfigInt(config, "maxDepth"); ok {
+		sb.WriteString(" FILTER LENGTH(p.edges) <= " + b.bind(maxDepth))
+	}
+	limit := 1
+	if all, _ := config["all"].(bool); all {
+		limit = internal.ConfigIntOr(config, "limit", 100)
+	}
+	sb.WriteString(" LIMIT " + b.bind(limit) + " RETURN p")
+	return sb.String(), b.bindVars
+}
+
+// subgraph is the graph loaded by the algorithms computed by the client.
+type subgraph struct {
+	nodes []string
+	index map[string]int
+	// out and in are the neighbors of each node by index, following and
+	// against the direction of the edges.
+	out, in [][]int
+}
+
+// loadSubgraph loads the nodes and edges selected by config: "nodeLabels"
+// keeps the nodes having any of them and the edges between them, and
+// "relationshipTypes" the edges having any of them. It fails if there are
+// more than config["maxNodes"] nodes, 10000 by default.
+func (c *arangoClient) loadSubgraph(ctx context.Context, config map[string]any) (*subgraph, error) {
+	labels, types := internal.ConfigStrings(config, "nodeLabels"), internal.ConfigStrings(config, "relationshipTypes")
+	maxNodes := internal.ConfigIntOr(config, "maxNodes", defaultMaxNodes)
+
+	nodeFilter := "true"
+	if len(labels) > 0 {
+		nodeFilter = "LENGTH(INTERSECTION(n._labels, @labels)) > 0"
+	}
+	aql := "FOR n IN @@vertices FILTER " + nodeFilter + " LIMIT @limit RETURN n._id"
+	bindVars := map[string]any{"@vertices": c.vertices, "limit": maxNodes + 1}
+	if len(labels) > 0 {
+		bindVars["labels"] = labels
+	}
+	ids, err := c.query(ctx, aql, bindVars)
+	if err != nil {
+		return nil, err
+	}
+	if len(ids) > maxNodes {
+		return nil, fmt.Errorf("arangodb: more than %d nodes to load, see config maxNodes", maxNodes)
+	}
+
+	g := &subgraph{index: make(map[string]int, len(ids))}
+	for _, id := range toStrings(ids) {
+		g.index[id] = len(g.nodes)
+		g.nodes = append(g.nodes, id)
+	}
+	g.out, g.in = make([][]int, len(g.nodes)), make([][]int, len(g.nodes))
+
+	edgeFilter := "true"
+	bindVars = map[string]any{"@edges": c.edges}
+	if len(types) > 0 {
+		edgeFilter = "e._label IN @types"
+		bindVars["types"] = types
+	}
+	edges, err := c.query(ctx, "FOR e IN @@edges FILTER "+edgeFilter+" RETURN [e._from, e._to]", bindVars)
+	if err != nil {
+		return nil, err
+	}
+	for _, edge := range edges {
+		ends := toStrings(edge)
+		if len(ends) != 2 {
+			continue
+		}
+		from, okFrom := g.index[ends[0]]
+		to, okTo := g.index[ends[1]]
+		if okFrom && okTo {
+			g.out[from] = append(g.out[from], to)
+			g.in[to] = append(g.in[to], from)
+		}
+	}
+	return g, nil
+}
+
+// PageRank computes the PageRank of the nodes by power iteration on the
+// client, like GDS: scores start at 1 - "dampingFactor" (0.85 by default) and
+// are iterated up to "maxIterations" (20 by default) times, or until no score
+// changes by more than "tolerance" (1e-7 by default). config may also narrow
+// the graph, see loadSubgraph.
+func (c *arangoClient) PageRank(ctx context.Context, config map[string]any) (map[string]float64, error) {
+	g, err := c.loadSubgraph(ctx, config)
+	if err != nil {
+		return nil, err
+	}
+	damping := internal.ConfigFloatOr(config, "dampingFactor", 0.85)
+	maxIterations := internal.ConfigIntOr(config, "maxIterations", 20)
+	tolerance := internal.ConfigFloatOr(config, "tolerance", 1e-7)
+
+	scores := make([]float64, len(g.nodes))
+	for i := range scores {
+		scores[i] = 1 - damping
+	}
+	for iter := 0; iter < maxIterations; iter++ {
+		next := make([]float64, len(scores))
+		for i := range next {
+			next[i] = 1 - damping
+		}
+		for i, targets := range g.out {
+			for _, j := range targets {
+				next[j] += damping * scores[i] / float64(len(targets))
+			}
+		}
+		delta := 0.0
+		for i := range next {
+			delta = math.Max(delta, math.Abs(next[i]-scores[i]))
+		}
+		scores = next
+		if delta < tolerance {
+			break
+		}
+	}
+
+	out := make(map[string]float64, len(g.nodes))
+	for i, id := range g.nodes {
+		out[id] = scores[i]
+	}
+	return out, nil
+}
+
+// ConnectedComponents computes the weakly connected components of the nodes
+// on the client. Component IDs are the smallest node ID of each component.
+// config may narrow the graph, see loadSubgraph.
+func (c *arangoClient) ConnectedComponents(ctx context.Context, config map[string]any) (map[string]string, error) {
+	g, err := c.loadSubgraph(ctx, config)
+	if err != nil {
+		return nil, err
+	}
+	parent := make(map[string]string, len(g.nodes))
+	for _, id := range g.nodes {
+		parent[id] = id
+	}
+	for i, targets := range g.out {
+		for _, j := range targets {
+			internal.Union(parent, g.nodes[i], g.nodes[j], strings.Compare)
+		}
+	}
+	components := make(map[string]string, len(parent))
+	for id := range parent {
+		components[id] = internal.Find(parent, id)
+	}
+	return components, nil
+}
+
+// BetweennessCentrality computes the betweenness centrality of the nodes with
+// Brandes' algorithm on the client. Edges are followed in their direction
+// unless config["orientation"] is UNDIRECTED, or against it if REVERSE; as
+// with GDS, scores aren't normalized. config may also narrow the graph, see
+// loadSubgraph.
+func (c *arangoClient) BetweennessCentrality(ctx context.Context, config map[string]any) (map[string]float64, error) {
+	g, err := c.loadSubgraph(ctx, config)
+	if err != nil {
+		return nil, err
+	}
+	neighbors := g.out
+	undirected := false
+	switch strings.ToUpper(internal.ConfigString(config, "orientation")) {
+	case "REVERSE":
+		neighbors = g.in
+	case "UNDIRECTED":
+		undirected = true
+		neighbors = make([][]int, len(g.nodes))
+		for i := range neighbors {
+			neighbors[i] = append(append([]int(nil), g.out[i]...), g.in[i]...)
+		}
+	}
+
+	n := len(g.nodes)
+	scores := make([]float64, n)
+	for s := 0; s < n; s++ {
+		// Count the shortest paths from s by breadth-first search.
+		var stack []int
+		preds := make([][]int, n)
+		sigma, dist := make([]float64, n), make([]int, n)
+		for i := range dist {
+			dist[i] = -1
+		}
+		sigma[s], dist[s] = 1, 0
+		queue := []int{s}
+		for len(queue) > 0 {
+			v := queue[0]
+			queue = queue[1:]
+			stack = append(stack, v)
+			for _, w := range neighbors[v] {
+				if dist[w] < 0 {
+					dist[w] = dist[v] + 1
+					queue = append(queue, w)
+				}
+				if dist[w] == dist[v]+1 {
+					sigma[w] += sigma[v]
+					preds[w] = append(preds[w], v)
+				}
+			}
+		}
+		// Accumulate the dependencies of s in reverse order of distance.
+		delta := make([]float64, n)
+		for i := len(stack) - 1; i >= 0; i-- {
+			w := stack[i]
+			for _, v := range preds[w] {
+				delta[v] += sigma[v] / sigma[w] * (1 + delta[w])
+			}
+			if w != s {
+				scores[w] += delta[w]
+			}
+		}
+	}
+
+	out := make(map[string]float64, n)
+	for i, id := range g.nodes {
+		if undirected {
+			// Each path is counted from both of its ends.
+			scores[i] /= 2
+		}
+		out[id] = scores[i]
+	}
+	return out, nil
+}
+
+func toFloat(v any) float64 {
+	switch f := v.(type) {
+	case float64:
+		return f
+	case int64:
+		return float64(f)
+	default:
+		return 0
+	}
+}
@@ -0,0 +1,617 @@
+package arangodb
+
+import (
+	"fmt"
+	"maps"
+	"math"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/me2seeks/forge/infra/contract/graph"
+)
+
+// maxTraversalDepth bounds the variable-length edges without MaxHops, as AQL
+// traversals need a maximum depth.
+const maxTraversalDepth = 10
+
+// aqlBuilder translates the query DSL to AQL, one operation per line.
+type aqlBuilder struct {
+	client   *arangoClient
+	lines    []string
+	bindVars map[string]any
+	// params counts the bind parameters of values.
+	params int
+	// vars maps the aliases visible to the query to the AQL variables, or
+	// expressions, holding them. They differ when an alias is projected again
+	// by a stage, as AQL variables can't be redeclared.
+	vars map[string]string
+	// declared are the AQL variables declared so far.
+	declared map[string]bool
+	// aliases are the node and edge aliases of the patterns in order,
+	// returned by a query without Return items.
+	aliases []string
+	// aggregated are the visible aliases holding an aggregated value.
+	aggregated map[string]bool
+	// projected maps the expressions of the items of the last projection to
+	// their variables, which stay sortable on after an aggregation.
+	projected map[string]string
+}
+
+func (c *arangoClient) newBuilder() *aqlBuilder {
+	return &aqlBuilder{
+		client:     c,
+		bindVars:   make(map[string]any),
+		vars:       make(map[string]string),
+		declared:   make(map[string]bool),
+		aggregated: make(map[string]bool),
+	}
+}
+
+func (b *aqlBuilder) add(format string, args ...any) {
+	b.lines = append(b.lines, fmt.Sprintf(format, args...))
+}
+
+func (b *aqlBuilder) String() string {
+	return strings.Join(b.lines, "\n")
+}
+
+// bind binds value to a new bind parameter and returns its reference.
+func (b *aqlBuilder) bind(value any) string {
+	for {
+		name := "v" + strconv.Itoa(b.params)
+		b.params++
+		if _, ok := b.bindVars[name]; !ok {
+			b.bindVars[name] = value
+			return "@" + name
+		}
+	}
+}
+
+// bindParams adds the bind parameters of a raw expression.
+func (b *aqlBuilder) bindParams(params map[string]any) error {
+	for name, value := range params {
+		if _, ok := b.bindVars[name]; ok {
+			return fmt.Errorf("arangodb: bind parameter %s is already used by the query", name)
+		}
+		b.bindVars[name] = value
+	}
+	return nil
+}
+
+func (b *aqlBuilder) vertexCollection() string {
+	b.bindVars["@vertices"] = b.client.vertices
+	return "@@vertices"
+}
+
+func (b *aqlBuilder) edgeCollection() string {
+	b.bindVars["@edges"] = b.client.edges
+	return "@@edges"
+}
+
+func (b *aqlBuilder) graphName() string {
+	b.bindVars["graph"] = b.client.graph
+	return "@graph"
+}
+
+// fresh declares a variable named after name, suffixed if it is taken.
+func (b *aqlBuilder) fresh(name string) string {
+	name = nonWord.ReplaceAllString(name, "_")
+	v := name
+	for i := 1; b.declared[v]; i++ {
+		v = name + "_" + strconv.Itoa(i)
+	}
+	b.declared[v] = true
+	return v
+}
+
+var nonWord = regexp.MustCompile(`\W`)
+
+// declare declares the variable of a pattern alias.
+func (b *aqlBuilder) declare(alias string) string {
+	v := b.fresh(alias)
+	b.vars[alias] = v
+	b.aliases = append(b.aliases, alias)
+	return v
+}
+
+// ref returns the variable holding alias.
+func (b *aqlBuilder) ref(alias string) string {
+	if v, ok := b.vars[alias]; ok {
+		return v
+	}
+	return alias
+}
+
+// attr returns the attribute property of expr.
+func attr(expr, property string) string {
+	return expr + "." + quoteName(property)
+}
+
+// quoteName returns name between backticks, the backslashes and backticks it
+// holds escaped, so that no property key can change the query. Labels are
+// bound as values instead. The clients reject the names graph.ValidateName
+// rejects before building it.
+func quoteName(name string) string {
+	name = strings.ReplaceAll(name, `\`, `\\`)
+	return "`" + strings.ReplaceAll(name, "`", "\\`") + "`"
+}
+
+var identifier = regexp.MustCompile(`[A-Za-z_][A-Za-z0-9_]*`)
+
+// expr rewrites the aliases referenced by an expression to their variables.
+// Identifiers following a dot or an @ are attributes and bind parameters.
+func (b *aqlBuilder) expr(expression string) string {
+	var sb strings.Builder
+	last := 0
+	for _, loc := range identifier.FindAllStringIndex(expression, -1) {
+		if loc[0] > 0 && strings.ContainsRune(".@", rune(expression[loc[0]-1])) {
+			continue
+		}
+		if v, ok := b.vars[expression[loc[0]:loc[1]]]; ok {
+			sb.WriteString(expression[last:loc[0]])
+			sb.WriteString(v)
+			last = loc[1]
+		}
+	}
+	sb.WriteString(expression[last:])
+	return sb.String()
+}
+
+// body translates the patterns, conditions and stages of query.
+func (b *aqlBuilder) body(query *graph.Query) error {
+	if err := query.ValidateNames(); err != nil {
+		return err
+	}
+	for i := range query.Match {
+		b.match(&query.Match[i])
+	}
+	if err := b.filter(query.Where); err != nil {
+		return err
+	}
+	for _, stage := range query.With {
+		if err := b.project(stage.Items); err != nil {
+			return err
+		}
+		if err := b.filter(stage.Where); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// match translates a pattern to nested loops: over the vertex collection for
+// its first node, unless its alias is already bound, then a traversal of the
+// graph per edge. Aliases default to n, r and m as in Cypher.
+func (b *aqlBuilder) match(p *graph.Pattern) {
+	alias := p.Alias
+	if alias == "" {
+		alias = "n"
+	}
+	cur, ok := b.vars[alias]
+	if !ok {
+		cur = b.declare(alias)
+		b.add("FOR %s IN %s", cur, b.vertexCollection())
+	}
+	b.filterNode(cur, p.Labels, p.Properties)
+
+	vertices := []string{"[" + cur + "]"}
+	var edges []string
+	for e := p.Edge; e != nil; {
+		next := e.Node
+		if next == nil {
+			next = &graph.Pattern{}
+		}
+		var vs, es string
+		cur, vs, es = b.traverse(cur, e, next)
+		vertices = append(vertices, vs)
+		edges = append(edges, es)
+		e = next.Edge
+	}
+
+	if p.PathAlias != "" {
+		v := b.fresh(p.PathAlias)
+		b.vars[p.PathAlias] = v
+		b.add("LET %s = {vertices: FLATTEN([%s]), edges: FLATTEN([%s])}", v, strings.Join(vertices, ", "), strings.Join(edges, ", "))
+	}
+}
+
+// traverse traverses e from the vertex variable from to the node next, and
+// returns the variable of next and the expressions of the vertices and edges
+// traversed, as lists.
+func (b *aqlBuilder) traverse(from string, e *graph.EdgePattern, next *graph.Pattern) (string, string, string) {
+	direction := "OUTBOUND"
+	switch e.Direction {
+	case graph.DirectionIncoming:
+		direction = "INBOUND"
+	case graph.DirectionBoth:
+		direction = "ANY"
+	}
+	edgeAlias, nextAlias := e.Alias, next.Alias
+	if edgeAlias == "" {
+		edgeAlias = "r"
+	}
+	if nextAlias == "" {
+		nextAlias = "m"
+	}
+
+	// A node already bound is reached by a traversal to a new variable.
+	target, bound := b.vars[nextAlias]
+	var v string
+	if bound {
+		v = b.fresh(nextAlias)
+	} else {
+		v = b.declare(nextAlias)
+	}
+
+	var vertices, edges string
+	if e.MinHops == nil && e.MaxHops == nil {
+		ev := b.declare(edgeAlias)
+		b.add("FOR %s, %s IN 1..1 %s %s GRAPH %s", v, ev, direction, from, b.graphName())
+		if len(e.Labels) > 0 {
+			b.add("FILTER %s.%s IN %s", ev, labelAttr, b.bind(e.Labels))
+		}
+		for _, key := range slices.Sorted(maps.Keys(e.Properties)) {
+			b.add("FILTER %s == %s", attr(ev, key), b.bind(e.Properties[key]))
+		}
+		vertices, edges = "["+v+"]", "["+ev+"]"
+	} else {
+		minHops, maxHops := 1, maxTraversalDepth
+		if e.MinHops != nil {
+			minHops = *e.MinHops
+		}
+		if e.MaxHops != nil {
+			maxHops = *e.MaxHops
+		}
+		ev, pv := b.fresh(edgeAlias), b.fresh("p")
+		// Like in Cypher, the alias of a variable-length edge holds the list
+		// of edges traversed.
+		b.vars[edgeAlias] = pv + ".edges"
+		b.aliases = append(b.aliases, edgeAlias)
+		b.add("FOR %s, %s, %s IN %d..%d %s %s GRAPH %s", v, ev, pv, minHops, maxHops, direction, from, b.graphName())
+		if len(e.Labels) > 0 {
+			b.add("FILTER %s.edges[*].%s ALL IN %s", pv, labelAttr, b.bind(e.Labels))
+		}
+		for _, key := range slices.Sorted(maps.Keys(e.Properties)) {
+			b.add("FILTER %s.edges[*].%s ALL == %s", pv, quoteName(key), b.bind(e.Properties[key]))
+		}
+		vertices, edges = "SLICE("+pv+".vertices, 1)", pv+".edges"
+	}
+
+	if bound {
+		b.add("FILTER %s._id == %s._id", v, target)
+	}
+	b.filterNode(v, next.Labels, next.Properties)
+	return v, vertices, edges
+}
+
+// filterNode keeps the values of the vertex variable v having all labels and
+// the values of properties.
+func (b *aqlBuilder) filterNode(v string, labels []string, properties graph.Properties) {
+	// One condition per label lets the array index on labels be used.
+	for _, label := range labels {
+		b.add("FILTER %s IN %s.%s[*]", b.bind(label), v, labelsAttr)
+	}
+	for _, key := range slices.Sorted(maps.Keys(properties)) {
+		b.add("FILTER %s == %s", attr(v, key), b.bind(properties[key]))
+	}
+}
+
+// filter translates where to a FILTER operation.
+func (b *aqlBuilder) filter(where *graph.Where) error {
+	cond, err := b.where(where)
+	if err != nil || cond == "" {
+		return err
+	}
+	b.add("FILTER %s", cond)
+	return nil
+}
+
+// where translates where to an AQL condition, empty if it has none.
+func (b *aqlBuilder) where(where *graph.Where) (string, error) {
+	if where == nil {
+		return "", nil
+	}
+	conditions := func(conds []graph.Condition, op string) string {
+		parts := make([]string, len(conds))
+		for i, cond := range conds {
+			parts[i] = b.condition(cond)
+		}
+		return strings.Join(parts, op)
+	}
+
+	var clauses []string
+	if len(where.Filter) > 0 {
+		clauses = append(clauses, conditions(where.Filter, " AND "))
+	}
+	if len(where.Must) > 0 {
+		clauses = append(clauses, "("+conditions(where.Must, " AND ")+")")
+	}
+	if len(where.Should) > 0 {
+		clauses = append(clauses, "("+conditions(where.Should, " OR ")+")")
+	}
+	if len(where.MustNot) > 0 {
+		clauses = append(clauses, "NOT ("+conditions(where.MustNot, " AND ")+")")
+	}
+	for _, expr := range where.MustExpr {
+		if err := b.bindParams(expr.Params); err != nil {
+			return "", err
+		}
+		clauses = append(clauses, "("+b.expr(expr.Expression)+")")
+	}
+	return strings.Join(clauses, " AND "), nil
+}
+
+// condition translates cond to an AQL comparison. As null sorts before any
+// value in AQL, range comparisons exclude null like in Cypher.
+func (b *aqlBuilder) condition(cond graph.Condition) string {
+	left := b.ref(cond.Alias)
+	if cond.Property != "" {
+		left = attr(left, cond.Property)
+	}
+	value := b.bind(cond.Value)
+
+	switch cond.Operator {
+	case graph.OpNotEqual:
+		return left + " != " + value
+	case graph.OpGreaterThan, graph.OpGreaterThanOrEqual, graph.OpLessThan, graph.OpLessThanOrEqual:
+		return "(" + left + " != null AND " + left + " " + string(cond.Operator) + " " + value + ")"
+	case graph.OpIn:
+		return left + " IN " + value
+	case graph.OpContains:
+		return "CONTAINS(" + left + ", " + value + ")"
+	default:
+		return left + " == " + value
+	}
+}
+
+// itemName returns the name of a projected item.
+func itemName(item graph.Return) string {
+	if item.Alias != "" {
+		return item.Alias
+	}
+	return item.Expression
+}
+
+// project binds the items of a stage to variables, which become the only
+// aliases visible. If any item aggregates, the others are the keys of a
+// COLLECT grouping the records.
+func (b *aqlBuilder) project(items []graph.Return) error {
+	grouped := slices.ContainsFunc(items, func(item graph.Return) bool { return item.Aggregate != nil })
+	vars := make(map[string]string, len(items))
+	projected := make(map[string]string, len(items))
+	aggregated := make(map[string]bool)
+
+	var keys, aggregates, lets []string
+	for _, item := range items {
+		name := itemName(item)
+		if item.Aggregate != nil {
+			expr, err := b.aggregate(item.Aggregate)
+			if err != nil {
+				return err
+			}
+			v := b.fresh(name)
+			aggregates = append(aggregates, v+" = "+expr)
+			vars[name], aggregated[name] = v, true
+			continue
+		}
+
+		expr := b.expr(item.Expression)
+		switch {
+		case grouped:
+			v := b.fresh(name)
+			keys = append(keys, v+" = "+expr)
+			vars[name] = v
+		case expr == b.vars[name]:
+			// An alias projected as itself keeps its variable.
+			vars[name] = expr
+		default:
+			v := b.fresh(name)
+			lets = append(lets, "LET "+v+" = "+expr)
+			vars[name] = v
+		}
+		projected[item.Expression] = vars[name]
+	}
+
+	if grouped {
+		collect := "COLLECT"
+		if len(keys) > 0 {
+			collect += " " + strings.Join(keys, ", ")
+		}
+		b.add("%s AGGREGATE %s", collect, strings.Join(aggregates, ", "))
+	} else {
+		b.lines = append(b.lines, lets...)
+	}
+	b.vars, b.projected, b.aggregated = vars, projected, aggregated
+	return nil
+}
+
+// aggregate returns the AQL aggregate function call computing a in a COLLECT.
+func (b *aqlBuilder) aggregate(a *graph.Aggregation) (string, error) {
+	arg := b.ref(a.Alias)
+	if a.Property != "" {
+		arg = attr(arg, a.Property)
+	}
+
+	switch a.Func {
+	case graph.AggregateCount:
+		switch {
+		case a.Alias == "":
+			return "COUNT(1)", nil
+		case a.Distinct:
+			return "COUNT_DISTINCT(" + arg + ")", nil
+		default:
+			// Like in Cypher, nulls aren't counted.
+			return "SUM(" + arg + " == null ? 0 : 1)", nil
+		}
+	case graph.AggregateCollect:
+		if a.Distinct {
+			return "UNIQUE(" + arg + ")", nil
+		}
+		return "PUSH(" + arg + ")", nil
+	case graph.AggregateMin, graph.AggregateMax:
+		return strings.ToUpper(string(a.Func)) + "(" + arg + ")", nil
+	case graph.AggregateSum, graph.AggregateAvg:
+		if a.Distinct {
+			return "", unsupported("distinct " + string(a.Func))
+		}
+		if a.Func == graph.AggregateAvg {
+			return "AVERAGE(" + arg + ")", nil
+		}
+		return "SUM(" + arg + ")", nil
+	default:
+		return "", fmt.Errorf("arangodb: unknown aggregate function %s", a.Func)
+	}
+}
+
+// orderExpression returns the expression sorted on by o.
+func (b *aqlBuilder) orderExpression(o graph.Order) string {
+	if o.Property == "" {
+		if b.aggregated[o.Alias] {
+			return b.ref(o.Alias)
+		}
+		return b.ref(o.Alias) + "._id"
+	}
+	if v, ok := b.projected[o.Alias+"."+o.Property]; ok {
+		return v
+	}
+	return attr(b.ref(o.Alias), o.Property)
+}
+
+// returns translates the keyset condition, sorting, paging and Return items
+// of query, returning an object per record.
+func (b *aqlBuilder) returns(query *graph.Query) error {
+	items := query.Return
+	if len(items) == 0 {
+		for _, alias := range slices.Compact(slices.Clone(b.aliases)) {
+			items = append(items, graph.Return{Expression: alias})
+		}
+	}
+	grouped := slices.ContainsFunc(items, func(item graph.Return) bool { return item.Aggregate != nil })
+	if grouped {
+		if err := b.project(items); err != nil {
+			return err
+		}
+	} else {
+		b.projected = make(map[string]string, len(items))
+		for _, item := range items {
+			b.projected[item.Expression] = b.expr(item.Expression)
+		}
+	}
+
+	b.after(query)
+	if len(query.OrderBy) > 0 {
+		keys := make([]string, len(query.OrderBy))
+		for i, o := range query.OrderBy {
+			dir := " DESC"
+			if o.Asc {
+				dir = " ASC"
+			}
+			keys[i] = b.orderExpression(o) + dir
+		}
+		b.add("SORT %s", strings.Join(keys, ", "))
+	}
+	if query.Skip != nil || query.Limit != nil {
+		skip, limit := 0, math.MaxInt32
+		if query.Skip != nil {
+			skip = *query.Skip
+		}
+		if query.Limit != nil {
+			limit = *query.Limit
+		}
+		b.add("LIMIT %s, %s", b.bind(skip), b.bind(limit))
+	}
+
+	fields := make([]string, len(items))
+	for i, item := range items {
+		name := itemName(item)
+		value := b.projected[item.Expression]
+		if grouped {
+			value = b.vars[name]
+		}
+		fields[i] = strconv.Quote(name) + ": " + value
+	}
+	b.add("RETURN {%s}", strings.Join(fields, ", "))
+	return nil
+}
+
+// after adds the keyset condition selecting the records sorting after the
+// values of query.After, see the neo4j client.
+func (b *aqlBuilder) after(query *graph.Query) {
+	n := min(len(query.OrderBy), len(query.After))
+	if n == 0 {
+		return
+	}
+	var equals, branches []string
+	for i := 0; i < n; i++ {
+		expr, value := b.orderExpression(query.OrderBy[i]), b.bind(query.After[i])
+		op := " < "
+		if query.OrderBy[i].Asc {
+			op = " > "
+		}
+		branch := append(slices.Clone(equals), expr+op+value)
+		branches = append(branches, "("+strings.Join(branch, " AND ")+")")
+		equals = append(equals, expr+" == "+value)
+	}
+	b.add("FILTER %s", strings.Join(branches, " OR "))
+}
+
+// buildQueryAQL translates query to AQL returning an object per record, keyed
+// by the names of the Return items, or by the pattern aliases if there are
+// none.
+func (c *arangoClient) buildQueryAQL(query *graph.Query) (string, map[string]any, error) {
+	b := c.newBuilder()
+	if err := b.body(query); err != nil {
+		return "", nil, err
+	}
+	if err := b.returns(query); err != nil {
+		return "", nil, err
+	}
+	return b.String(), b.bindVars, nil
+}
+
+// buildCountAQL translates query to AQL counting its records.
+func (c *arangoClient) buildCountAQL(query *graph.Query) (string, map[string]any, error) {
+	b := c.newBuilder()
+	if err := b.body(query); err != nil {
+		return "", nil, err
+	}
+	count := b.fresh("count")
+	b.add("COLLECT WITH COUNT INTO %s", count)
+	b.add("RETURN %s", count)
+	return b.String(), b.bindVars, nil
+}
+
+// buildTargetAQL translates the patterns and conditions of query to a
+// subquery of the distinct documents to update or delete: those of the first
+// node alias if edges is false, or of the edge alias of the first pattern
+// otherwise. It returns the builder to complete and the subquery.
+func (c *arangoClient) buildTargetAQL(query *graph.Query, edges bool) (*aqlBuilder, string, error) {
+	if len(query.Match) == 0 {
+		return nil, "", fmt.Errorf("arangodb: query matches no pattern")
+	}
+	b := c.newBuilder()
+	if err := b.body(query); err != nil {
+		return nil, "", err
+	}
+
+	first := query.Match[0]
+	target := first.Alias
+	if target == "" {
+		target = "n"
+	}
+	if edges {
+		if first.Edge == nil {
+			return nil, "", fmt.Errorf("arangodb: the first pattern of the query has no edge")
+		}
+		target = first.Edge.Alias
+		if target == "" {
+			target = "r"
+		}
+	}
+	// A variable-length edge alias holds a list of edges, flattened.
+	sub := "UNIQUE(FLATTEN((" + strings.Join(b.lines, " ") + " RETURN " + b.ref(target) + "))[*]._key)"
+	b.lines = nil
+	return b, sub, nil
+}
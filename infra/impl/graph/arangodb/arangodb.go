@@ -0,0 +1,376 @@
+// Package arangodb implements the graph contract on ArangoDB over its HTTP
+// API. Nodes are the documents of a vertex collection and edges those of an
+// edge collection, both part of a named graph which queries traverse. The
+// labels of a node are stored in its _labels attribute and the type of an edge
+// in its _label attribute; the other attributes starting with an underscore
+// are ArangoDB's own, so properties must not start with one. Node and edge IDs
+// are document handles, e.g. "nodes/123".
+//
+// Queries are translated to AQL, which is also the language of RawQuery and
+// BatchQuery. Return expressions are AQL expressions too, e.g. "n.name" or
+// "LENGTH(p.edges)".
+package arangodb
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+
+	"github.com/me2seeks/forge/httpclient"
+	"github.com/me2seeks/forge/infra/contract/graph"
+	"github.com/me2seeks/forge/sonic"
+)
+
+const (
+	// labelsAttr holds the labels of a node.
+	labelsAttr = "_labels"
+	// labelAttr holds the type of an edge.
+	labelAttr = "_label"
+)
+
+// Error numbers of the ArangoDB HTTP API.
+const (
+	errDocumentNotFound = 1202
+	errIndexNotFound    = 1212
+	errGraphNotFound    = 1924
+	errGraphDuplicate   = 1925
+)
+
+// Error is an error response of the ArangoDB HTTP API.
+type Error struct {
+	// Code is the HTTP status code.
+	Code int `json:"code"`
+	// Num is ArangoDB's error number, e.g. 1202 for a missing document.
+	Num     int    `json:"errorNum"`
+	Message string `json:"errorMessage"`
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("arangodb: %s (status %d, error %d)", e.Message, e.Code, e.Num)
+}
+
+// isErrorNum reports whether err is an API error with number num.
+func isErrorNum(err error, num int) bool {
+	var apiErr *Error
+	return errors.As(err, &apiErr) && apiErr.Num == num
+}
+
+type arangoClient struct {
+	http     *httpclient.Client
+	endpoint string
+	auth     func(req *http.Request)
+	// database is the default database, unless a call selects another one
+	// with graph.WithDatabase.
+	database string
+	graph    string
+	vertices string
+	edges    string
+	// trx is the stream transaction the client runs in, nil outside of one.
+	trx *streamTx
+}
+
+// Option is a function that configures the arangodb client
+type Option func(*options)
+
+// options contains the configuration for the arangodb client
+type options struct {
+	database    string
+	graph       string
+	vertices    string
+	edges       string
+	auth        func(req *http.Request)
+	httpOptions []httpclient.Option
+}
+
+// WithDatabase sets the database the client uses unless a call selects
+// another one with graph.WithDatabase. Defaults to _system.
+func WithDatabase(database string) Option {
+	return func(o *options) {
+		o.database = database
+	}
+}
+
+// WithGraph sets the name of the graph, "forge" by default.
+func WithGraph(name string) Option {
+	return func(o *options) {
+		o.graph = name
+	}
+}
+
+// WithCollections sets the vertex and edge collections of the graph, "nodes"
+// and "edges" by default.
+func WithCollections(vertices, edges string) Option {
+	return func(o *options) {
+		o.vertices = vertices
+		o.edges = edges
+	}
+}
+
+// WithBasicAuth sets basic authentication for the client
+func WithBasicAuth(username, password string) Option {
+	return func(o *options) {
+		o.auth = func(req *http.Request) {
+			req.SetBasicAuth(username, password)
+		}
+	}
+}
+
+// WithBearerAuth authenticates the client with a JWT token
+func WithBearerAuth(token string) Option {
+	return func(o *options) {
+		o.auth = func(req *http.Request) {
+			req.Header.Set("Authorization", "bearer "+token)
+		}
+	}
+}
+
+// WithHTTPOptions configures the HTTP client, e.g. its timeout and retries.
+func WithHTTPOptions(opts ...httpclient.Option) Option {
+	return func(o *options) {
+		o.httpOptions = append(o.httpOptions, opts...)
+	}
+}
+
+// New creates an arangodb client of the server at endpoint, e.g.
+// http://localhost:8529, and creates the graph and its collections in the
+// default database if they don't exist.
+func New(ctx context.Context, endpoint string, opts ...Option) (graph.Client, error) {
+	o := &options{
+		database: "_system",
+		graph:    "forge",
+		vertices: "nodes",
+		edges:    "edges",
+	}
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	c := &arangoClient{
+		http:     httpclient.New(o.httpOptions...),
+		endpoint: strings.TrimSuffix(endpoint, "/"),
+		auth:     o.auth,
+		database: o.database,
+		graph:    o.graph,
+		vertices: o.vertices,
+		edges:    o.edges,
+	}
+	if err := c.ensureGraph(ctx); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// ensureGraph creates the graph, with its collections, and the indexes on
+// labels if they don't exist.
+func (c *arangoClient) ensureGraph(ctx context.Context) error {
+	err := c.do(ctx, http.MethodGet, "/_api/gharial/"+url.PathEscape(c.graph), nil, nil)
+	if isErrorNum(err, errGraphNotFound) {
+		body := map[string]any{
+			"name": c.graph,
+			"edgeDefinitions": []map[string]any{{
+				"collection": c.edges,
+				"from":       []string{c.vertices},
+				"to":         []string{c.vertices},
+			}},
+		}
+		err = c.do(ctx, http.MethodPost, "/_api/gharial", body, nil)
+		if isErrorNum(err, errGraphDuplicate) {
+			err = nil
+		}
+	}
+	if err != nil {
+		return err
+	}
+
+	// Creating an index that exists returns it.
+	if err := c.createIndex(ctx, c.vertices, "idx_labels", []string{labelsAttr + "[*]"}, false); err != nil {
+		return err
+	}
+	return c.createIndex(ctx, c.edges, "idx_label", []string{labelAttr}, false)
+}
+
+// do sends a request to the HTTP API of the database, path being relative to
+// /_db/{database}, and decodes the response into out if not nil. In a
+// transaction, it fails with graph.ErrTxDone once the transaction has ended.
+func (c *arangoClient) do(ctx context.Context, method, path string, in, out any) error {
+	if c.trx != nil {
+		c.trx.mu.Lock()
+		defer c.trx.mu.Unlock()
+		if c.trx.closed {
+			return graph.ErrTxDone
+		}
+	}
+	return c.send(ctx, method, path, in, out)
+}
+
+func (c *arangoClient) send(ctx context.Context, method, path string, in, out any) error {
+	var body io.Reader
+	if in != nil {
+		buf, err := sonic.Marshal(in)
+		if err != nil {
+			return fmt.Errorf("marshal request failed: %w", err)
+		}
+		body = bytes.NewReader(buf)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, c.endpoint+"/_db/"+url.PathEscape(c.databaseFor(ctx))+path, body)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+	if in != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if c.auth != nil {
+		c.auth(req)
+	}
+	if c.trx != nil {
+		req.Header.Set("x-arango-trx-id", c.trx.id)
+	}
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	buf, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("read response failed: %w", err)
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		apiErr := &Error{Code: resp.StatusCode}
+		if err := sonic.Unmarshal(buf, apiErr); err != nil || apiErr.Message == "" {
+			apiErr.Message = http.StatusText(resp.StatusCode)
+		}
+		return apiErr
+	}
+	if out == nil || len(buf) == 0 {
+		return nil
+	}
+	if err := sonic.Unmarshal(buf, out); err != nil {
+		return fmt.Errorf("unmarshal response failed: %w", err)
+	}
+	return nil
+}
+
+// databaseFor returns the database selected by ctx, or the default one. A
+// transaction stays on the database it began on.
+func (c *arangoClient) databaseFor(ctx context.Context) string {
+	if c.trx == nil {
+		if db := graph.DatabaseFromContext(ctx); db != "" {
+			return db
+		}
+	}
+	return c.database
+}
+
+// streamTx is an ArangoDB stream transaction, which the requests carrying its
+// ID run in.
+type streamTx struct {
+	id     string
+	mu     sync.Mutex
+	closed bool
+}
+
+// arangoTx reuses the client operations on top of a stream transaction.
+type arangoTx struct {
+	*arangoClient
+}
+
+// BeginTx begins a stream transaction writing to the collections of the graph,
+// or only reading them if ctx sets the read access mode.
+func (c *arangoClient) BeginTx(ctx context.Context) (graph.Tx, error) {
+	tx, err := c.begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &arangoTx{arangoClient: tx}, nil
+}
+
+// begin begins a stream transaction and returns a copy of the client running
+// in it.
+func (c *arangoClient) begin(ctx context.Context) (*arangoClient, error) {
+	access := "write"
+	if graph.AccessModeFromContext(ctx, graph.AccessModeWrite) == graph.AccessModeRead {
+		access = "read"
+	}
+	body := map[string]any{
+		"collections": map[string]any{access: []string{c.vertices, c.edges}},
+	}
+	var res struct {
+		Result struct {
+			ID string `json:"id"`
+		} `json:"result"`
+	}
+	if err := c.do(ctx, http.MethodPost, "/_api/transaction/begin", body, &res); err != nil {
+		return nil, err
+	}
+
+	tx := *c
+	tx.database = c.databaseFor(ctx)
+	tx.trx = &streamTx{id: res.Result.ID}
+	return &tx, nil
+}
+
+func (t *arangoTx) Commit(ctx context.Context) error {
+	return t.finish(ctx, http.MethodPut)
+}
+
+func (t *arangoTx) Rollback(ctx context.Context) error {
+	return t.finish(ctx, http.MethodDelete)
+}
+
+// finish commits the transaction with PUT or aborts it with DELETE.
+func (t *arangoTx) finish(ctx context.Context, method string) error {
+	t.trx.mu.Lock()
+	defer t.trx.mu.Unlock()
+	if t.trx.closed {
+		return graph.ErrTxDone
+	}
+	t.trx.closed = true
+	return t.send(ctx, method, "/_api/transaction/"+url.PathEscape(t.trx.id), nil, nil)
+}
+
+// atomically runs fn in the transaction of the client, or in a new one if it
+// isn't in any, for operations needing several queries.
+func (c *arangoClient) atomically(ctx context.Context, fn func(c *arangoClient) error) error {
+	if c.trx != nil {
+		return fn(c)
+	}
+	tx, err := c.begin(ctx)
+	if err != nil {
+		return err
+	}
+	t := &arangoTx{arangoClient: tx}
+	if err := fn(tx); err != nil {
+		if rerr := t.Rollback(context.WithoutCancel(ctx)); rerr != nil {
+			return errors.Join(err, fmt.Errorf("rollback failed: %w", rerr))
+		}
+		return err
+	}
+	return t.Commit(ctx)
+}
+
+// unsupported returns the error of an operation ArangoDB has no equivalent for.
+func unsupported(op string) error {
+	return fmt.Errorf("arangodb: %s: %w", op, errors.ErrUnsupported)
+}
+
+func (c *arangoClient) CreateFullTextIndex(ctx context.Context, name string, labels, properties []string) error {
+	return unsupported("full-text index")
+}
+
+func (c *arangoClient) DropFullTextIndex(ctx context.Context, name string) error {
+	return unsupported("full-text index")
+}
+
+func (c *arangoClient) FullTextSearch(ctx context.Context, indexName, query string, limit int) ([]*graph.ScoredNode, error) {
+	return nil, unsupported("full-text search")
+}
@@ -0,0 +1,378 @@
+package arangodb
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/me2seeks/forge/infra/contract/graph"
+)
+
+func newTestClient() *arangoClient {
+	return &arangoClient{vertices: "nodes", edges: "edges", graph: "forge"}
+}
+
+func TestBuildQueryAQL(t *testing.T) {
+	limit := 10
+	query := &graph.Query{
+		Match: []graph.Pattern{{
+			Alias:  "p",
+			Labels: []string{"Person"},
+			Edge: &graph.EdgePattern{
+				Alias:     "r",
+				Labels:    []string{"LIVES_IN"},
+				Direction: graph.DirectionOutgoing,
+				Node:      &graph.Pattern{Alias: "c", Properties: graph.Properties{"name": "Paris"}},
+			},
+		}},
+		Where: &graph.Where{
+			Filter: []graph.Condition{{Alias: "p", Property: "age", Operator: graph.OpGreaterThan, Value: 30}},
+			Should: []graph.Condition{
+				{Alias: "p", Property: "name", Operator: graph.OpContains, Value: "an"},
+				{Alias: "p", Property: "role", Operator: graph.OpIn, Value: []string{"admin"}},
+			},
+		},
+		Return:  []graph.Return{{Expression: "p"}, {Expression: "r.since", Alias: "since"}},
+		OrderBy: []graph.Order{{Alias: "p", Property: "name", Asc: true}},
+		Limit:   &limit,
+	}
+
+	aql, bindVars, err := newTestClient().buildQueryAQL(query)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	want := "FOR p IN @@vertices\n" +
+		"FILTER @v0 IN p._labels[*]\n" +
+		"FOR c, r IN 1..1 OUTBOUND p GRAPH @graph\n" +
+		"FILTER r._label IN @v1\n" +
+		"FILTER c.`name` == @v2\n" +
+		"FILTER (p.`age` != null AND p.`age` > @v3) AND (CONTAINS(p.`name`, @v4) OR p.`role` IN @v5)\n" +
+		"SORT p.`name` ASC\n" +
+		"LIMIT @v6, @v7\n" +
+		"RETURN {\"p\": p, \"since\": r.since}"
+	if aql != want {
+		t.Errorf("AQL mismatch.\nGot:  %s\nWant: %s", aql, want)
+	}
+	wantVars := map[string]any{
+		"@vertices": "nodes", "graph": "forge",
+		"v0": "Person", "v1": []string{"LIVES_IN"}, "v2": "Paris", "v3": 30,
+		"v4": "an", "v5": []string{"admin"}, "v6": 0, "v7": 10,
+	}
+	if !reflect.DeepEqual(bindVars, wantVars) {
+		t.Errorf("Bind vars mismatch.\nGot:  %v\nWant: %v", bindVars, wantVars)
+	}
+}
+
+// TestBuildQueryAQL_DefaultReturn tests that a query without Return items
+// returns the aliases of its patterns, defaulting to n, r and m.
+func TestBuildQueryAQL_DefaultReturn(t *testing.T) {
+	query := &graph.Query{Match: []graph.Pattern{{Edge: &graph.EdgePattern{Direction: graph.DirectionBoth}}}}
+	aql, _, err := newTestClient().buildQueryAQL(query)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	want := "FOR n IN @@vertices\n" +
+		"FOR m, r IN 1..1 ANY n GRAPH @graph\n" +
+		"RETURN {\"n\": n, \"m\": m, \"r\": r}"
+	if aql != want {
+		t.Errorf("AQL mismatch.\nGot:  %s\nWant: %s", aql, want)
+	}
+}
+
+// TestBuildQueryAQL_Path tests variable-length edges and path aliases.
+func TestBuildQueryAQL_Path(t *testing.T) {
+	minHops, maxHops := 2, 3
+	query := &graph.Query{
+		Match: []graph.Pattern{{
+			PathAlias: "path",
+			Alias:     "a",
+			Edge: &graph.EdgePattern{
+				Alias:     "rs",
+				Labels:    []string{"ROAD"},
+				Direction: graph.DirectionIncoming,
+				MinHops:   &minHops,
+				MaxHops:   &maxHops,
+				Node:      &graph.Pattern{Alias: "b"},
+			},
+		}},
+		Return: []graph.Return{{Expression: "path"}, {Expression: "LENGTH(rs)", Alias: "hops"}},
+	}
+	aql, _, err := newTestClient().buildQueryAQL(query)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	want := "FOR a IN @@vertices\n" +
+		"FOR b, rs, p IN 2..3 INBOUND a GRAPH @graph\n" +
+		"FILTER p.edges[*]._label ALL IN @v0\n" +
+		"LET path = {vertices: FLATTEN([[a], SLICE(p.vertices, 1)]), edges: FLATTEN([p.edges])}\n" +
+		"RETURN {\"path\": path, \"hops\": LENGTH(p.edges)}"
+	if aql != want {
+		t.Errorf("AQL mismatch.\nGot:  %s\nWant: %s", aql, want)
+	}
+}
+
+func TestBuildQueryAQL_Aggregate(t *testing.T) {
+	query := &graph.Query{
+		Match: []graph.Pattern{{
+			Alias: "p",
+			Edge:  &graph.EdgePattern{Labels: []string{"LIVES_IN"}, Node: &graph.Pattern{Alias: "c"}},
+		}},
+		Return: []graph.Return{
+			{Expression: "c.name", Alias: "city"},
+			{Alias: "people", Aggregate: &graph.Aggregation{Func: graph.AggregateCount}},
+			{Alias: "avg_age", Aggregate: &graph.Aggregation{Func: graph.AggregateAvg, Alias: "p", Property: "age"}},
+			{Alias: "names", Aggregate: &graph.Aggregation{Func: graph.AggregateCollect, Alias: "p", Property: "name", Distinct: true}},
+		},
+		OrderBy: []graph.Order{{Alias: "people"}, {Alias: "c", Property: "name", Asc: true}},
+	}
+	aql, _, err := newTestClient().buildQueryAQL(query)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	want := "FOR p IN @@vertices\n" +
+		"FOR c, r IN 1..1 OUTBOUND p GRAPH @graph\n" +
+		"FILTER r._label IN @v0\n" +
+		"COLLECT city = c.name AGGREGATE people = COUNT(1), avg_age = AVERAGE(p.`age`), names = UNIQUE(p.`name`)\n" +
+		"SORT people DESC, city ASC\n" +
+		"RETURN {\"city\": city, \"people\": people, \"avg_age\": avg_age, \"names\": names}"
+	if aql != want {
+		t.Errorf("AQL mismatch.\nGot:  %s\nWant: %s", aql, want)
+	}
+
+	query.Return[1].Aggregate = &graph.Aggregation{Func: graph.AggregateSum, Alias: "p", Property: "age", Distinct: true}
+	if _, _, err := newTestClient().buildQueryAQL(query); err == nil {
+		t.Error("Expected an error for a distinct sum")
+	}
+}
+
+// TestBuildQueryAQL_With tests that an alias projected again by a stage is
+// renamed, as AQL variables can't be redeclared.
+func TestBuildQueryAQL_With(t *testing.T) {
+	query := &graph.Query{
+		Match: []graph.Pattern{{Alias: "p", Edge: &graph.EdgePattern{Labels: []string{"KNOWS"}, Node: &graph.Pattern{Alias: "f"}}}},
+		With: []graph.Stage{{
+			Items: []graph.Return{
+				{Expression: "p"},
+				{Alias: "friends", Aggregate: &graph.Aggregation{Func: graph.AggregateCount, Alias: "f"}},
+			},
+			Where: &graph.Where{Filter: []graph.Condition{{Alias: "friends", Operator: graph.OpGreaterThan, Value: 10}}},
+		}},
+		Return:  []graph.Return{{Expression: "p.name", Alias: "name"}, {Expression: "friends"}},
+		OrderBy: []graph.Order{{Alias: "friends"}, {Alias: "p", Asc: true}},
+		After:   []any{12, "nodes/1"},
+	}
+	aql, bindVars, err := newTestClient().buildQueryAQL(query)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	want := "FOR p IN @@vertices\n" +
+		"FOR f, r IN 1..1 OUTBOUND p GRAPH @graph\n" +
+		"FILTER r._label IN @v0\n" +
+		"COLLECT p_1 = p AGGREGATE friends = SUM(f == null ? 0 : 1)\n" +
+		"FILTER (friends != null AND friends > @v1)\n" +
+		"FILTER (friends < @v2) OR (friends == @v2 AND p_1._id > @v3)\n" +
+		"SORT friends DESC, p_1._id ASC\n" +
+		"RETURN {\"name\": p_1.name, \"friends\": friends}"
+	if aql != want {
+		t.Errorf("AQL mismatch.\nGot:  %s\nWant: %s", aql, want)
+	}
+	if bindVars["v2"] != 12 || bindVars["v3"] != "nodes/1" {
+		t.Errorf("Unexpected bind vars: %v", bindVars)
+	}
+}
+
+func TestBuildCountAQL(t *testing.T) {
+	aql, _, err := newTestClient().buildCountAQL(&graph.Query{Match: []graph.Pattern{{Alias: "count", Labels: []string{"Person"}}}})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	want := "FOR count IN @@vertices\n" +
+		"FILTER @v0 IN count._labels[*]\n" +
+		"COLLECT WITH COUNT INTO count_1\n" +
+		"RETURN count_1"
+	if aql != want {
+		t.Errorf("AQL mismatch.\nGot:  %s\nWant: %s", aql, want)
+	}
+}
+
+// TestBuildTargetAQL tests the subqueries selecting the documents updated or
+// deleted by query.
+func TestBuildTargetAQL(t *testing.T) {
+	query := &graph.Query{Match: []graph.Pattern{{Labels: []string{"Person"}, Edge: &graph.EdgePattern{Alias: "k"}}}}
+
+	_, sub, err := newTestClient().buildTargetAQL(query, false)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	want := "UNIQUE(FLATTEN((FOR n IN @@vertices FILTER @v0 IN n._labels[*] FOR m, k IN 1..1 OUTBOUND n GRAPH @graph RETURN n))[*]._key)"
+	if sub != want {
+		t.Errorf("Subquery mismatch.\nGot:  %s\nWant: %s", sub, want)
+	}
+
+	_, sub, _ = newTestClient().buildTargetAQL(query, true)
+	if want := "UNIQUE(FLATTEN((FOR n IN @@vertices FILTER @v0 IN n._labels[*] FOR m, k IN 1..1 OUTBOUND n GRAPH @graph RETURN k))[*]._key)"; sub != want {
+		t.Errorf("Subquery mismatch.\nGot:  %s\nWant: %s", sub, want)
+	}
+
+	if _, _, err := newTestClient().buildTargetAQL(&graph.Query{Match: []graph.Pattern{{Alias: "n"}}}, true); err == nil {
+		t.Error("Expected an error for a pattern without edge")
+	}
+}
+
+func TestBuildMergeEdgeAQL(t *testing.T) {
+	edge := &graph.Edge{
+		Label:              "KNOWS",
+		SourceNodeID:       "nodes/1",
+		TargetNodeSelector: &graph.NodeSelector{Labels: []string{"Person"}, Properties: graph.Properties{"email": "b@x.io"}},
+		Properties:         graph.Properties{"since": 2020},
+	}
+	aql, bindVars, err := newTestClient().buildMergeEdgeAQL(edge, nil, graph.Properties{"seen": true})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	want := "LET pairs = (FOR a IN @@vertices FILTER a._id == @v0 FOR b IN @@vertices FILTER @v1 IN b._labels[*] FILTER b.`email` == @v2 RETURN {a: a._id, b: b._id})\n" +
+		"FILTER ASSERT(LENGTH(pairs) <= 1, CONCAT('merge edge: endpoints match ', LENGTH(pairs), ' pairs of nodes'))\n" +
+		"FOR pair IN pairs\n" +
+		"UPSERT {_from: pair.a, _to: pair.b, _label: @v3, `since`: @v4}\n" +
+		"INSERT MERGE(@v5, @v6, {_from: pair.a, _to: pair.b, _label: @v3})\n" +
+		"UPDATE @v7 IN @@edges OPTIONS {keepNull: false}\n" +
+		"RETURN NEW"
+	if aql != want {
+		t.Errorf("AQL mismatch.\nGot:  %s\nWant: %s", aql, want)
+	}
+	if !reflect.DeepEqual(bindVars["v6"], graph.Properties{}) || !reflect.DeepEqual(bindVars["v7"], graph.Properties{"seen": true}) {
+		t.Errorf("Unexpected bind vars: %v", bindVars)
+	}
+
+	if _, _, err := newTestClient().buildMergeEdgeAQL(&graph.Edge{Label: "KNOWS", SourceNodeID: "nodes/1"}, nil, nil); err == nil {
+		t.Error("Expected an error for an endpoint without ID or selector")
+	}
+}
+
+func TestBuildShortestPathAQL(t *testing.T) {
+	cases := []struct {
+		config map[string]any
+		want   string
+	}{
+		{nil, "FOR p IN ANY K_SHORTEST_PATHS @v0 TO @v1 GRAPH @graph LIMIT @v2 RETURN p"},
+		{
+			map[string]any{"relationshipTypes": []any{"ROAD"}, "direction": "outgoing", "maxDepth": 5},
+			"FOR p IN OUTBOUND K_SHORTEST_PATHS @v0 TO @v1 GRAPH @graph FILTER p.edges[*]._label ALL IN @v2 FILTER LENGTH(p.edges) <= @v3 LIMIT @v4 RETURN p",
+		},
+		{
+			map[string]any{"relationshipWeightProperty": "km", "all": true},
+			"FOR p IN ANY K_SHORTEST_PATHS @v0 TO @v1 GRAPH @graph OPTIONS {weightAttribute: @v2, defaultWeight: 1} LIMIT @v3 RETURN p",
+		},
+	}
+	for _, c := range cases {
+		aql, bindVars := newTestClient().buildShortestPathAQL("nodes/1", "nodes/2", c.config)
+		if aql != c.want {
+			t.Errorf("AQL mismatch.\nGot:  %s\nWant: %s", aql, c.want)
+		}
+		if bindVars["v0"] != "nodes/1" || bindVars["v1"] != "nodes/2" {
+			t.Errorf("Unexpected bind vars: %v", bindVars)
+		}
+	}
+}
+
+func TestBuildNeighborsAQL(t *testing.T) {
+	opts := graph.NewNeighborOptions(graph.WithDirection(graph.DirectionIncoming), graph.WithRelationshipTypes("ROAD"), graph.WithDepth(2))
+	aql, bindVars := newTestClient().buildNeighborsAQL("nodes/1", opts)
+	want := "FOR v, e, p IN 1..2 INBOUND @v0 GRAPH @graph\n" +
+		"FILTER p.edges[*]._label ALL IN @v1\n" +
+		"FILTER v._id != @v0\n" +
+		"RETURN {node: v, edges: p.edges}"
+	if aql != want {
+		t.Errorf("AQL mismatch.\nGot:  %s\nWant: %s", aql, want)
+	}
+	wantVars := map[string]any{"graph": "forge", "v0": "nodes/1", "v1": []string{"ROAD"}}
+	if !reflect.DeepEqual(bindVars, wantVars) {
+		t.Errorf("Unexpected bind vars: %v", bindVars)
+	}
+}
+
+func TestBuildSubgraphAQL(t *testing.T) {
+	spec := graph.TraversalSpec{Direction: graph.DirectionOutgoing, RelationshipTypes: []string{"ROAD"}, MaxDepth: 3}
+	aql, bindVars := newTestClient().buildSubgraphAQL("nodes/1", spec.NeighborOptions())
+	want := "FOR start IN [DOCUMENT(@v0)] FILTER start != null\n" +
+		"LET nodes = APPEND([start], (FOR v, e, p IN 1..3 OUTBOUND start GRAPH @graph OPTIONS {uniqueVertices: \"path\"} FILTER p.edges[*]._label ALL IN @v1 FILTER v._id != start._id RETURN DISTINCT v))\n" +
+		"LET ids = nodes[*]._id\n" +
+		"LET edges = (FOR v IN nodes FOR w, e IN 1..1 OUTBOUND v GRAPH @graph FILTER w._id IN ids FILTER e._label IN @v1 RETURN e)\n" +
+		"RETURN {nodes, edges}"
+	if aql != want {
+		t.Errorf("AQL mismatch.\nGot:  %s\nWant: %s", aql, want)
+	}
+	wantVars := map[string]any{"graph": "forge", "v0": "nodes/1", "v1": []string{"ROAD"}}
+	if !reflect.DeepEqual(bindVars, wantVars) {
+		t.Errorf("Unexpected bind vars: %v", bindVars)
+	}
+}
+
+func TestToRecord(t *testing.T) {
+	node := map[string]any{"_id": "nodes/1", "_key": "1", "_rev": "x", "_labels": []any{"Person"}, "name": "Alice"}
+	edge := map[string]any{"_id": "edges/2", "_from": "nodes/1", "_to": "nodes/3", "_label": "KNOWS", "since": int64(2020)}
+	path := map[string]any{"vertices": []any{node}, "edges": []any{edge}}
+
+	got := toRecord(map[string]any{"n": node, "r": []any{edge}, "p": path, "count": int64(3)})
+	want := graph.Record{
+		"n":     &graph.Node{ID: "nodes/1", Labels: []string{"Person"}, Properties: graph.Properties{"name": "Alice"}},
+		"r":     []*graph.Edge{{ID: "edges/2", Label: "KNOWS", SourceNodeID: "nodes/1", TargetNodeID: "nodes/3", Properties: graph.Properties{"since": int64(2020)}}},
+		"count": int64(3),
+	}
+	want["p"] = &graph.Path{Nodes: []*graph.Node{want["n"].(*graph.Node)}, Edges: want["r"].([]*graph.Edge)}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Unexpected record.\nGot:  %#v\nWant: %#v", got, want)
+	}
+
+	if got := toRecord(node); !reflect.DeepEqual(got, graph.Record{"value": want["n"]}) {
+		t.Errorf("Unexpected record of a document: %#v", got)
+	}
+	if got := toRecord("Alice"); !reflect.DeepEqual(got, graph.Record{"value": "Alice"}) {
+		t.Errorf("Unexpected record of a value: %#v", got)
+	}
+}
+
+func TestIndexLabels(t *testing.T) {
+	if got := indexLabels(indexName("idx", "Person", []string{"name", "age"}), "idx"); !reflect.DeepEqual(got, []string{"Person"}) {
+		t.Errorf("Unexpected labels: %v", got)
+	}
+	if got := indexLabels("idx_labels", "idx"); got != nil {
+		t.Errorf("Unexpected labels: %v", got)
+	}
+}
+
+func TestBuildQueryAQL_QuotedNames(t *testing.T) {
+	query := &graph.Query{
+		Match: []graph.Pattern{{Alias: "n", Properties: graph.Properties{"na`me": "Ada"}}},
+		Where: &graph.Where{
+			Filter: []graph.Condition{{Alias: "n", Property: `a\`, Operator: graph.OpEqual, Value: 1}},
+		},
+		Return: []graph.Return{{Expression: "n"}},
+	}
+	aql, _, err := newTestClient().buildQueryAQL(query)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	want := "FOR n IN @@vertices\n" +
+		"FILTER n.`na\\`me` == @v0\n" +
+		"FILTER n.`a\\\\` == @v1\n" +
+		"RETURN {\"n\": n}"
+	if aql != want {
+		t.Errorf("AQL mismatch.\nGot:  %s\nWant: %s", aql, want)
+	}
+}
+
+func TestInvalidNames(t *testing.T) {
+	query := &graph.Query{Match: []graph.Pattern{{Alias: "n", Properties: graph.Properties{"na\x00me": "Ada"}}}}
+	if _, _, err := newTestClient().buildQueryAQL(query); !errors.Is(err, graph.ErrInvalidName) {
+		t.Errorf("Expected an invalid name error, got %v", err)
+	}
+	edge := &graph.Edge{Label: "KNOWS", SourceNodeID: "nodes/1", TargetNodeID: "nodes/2", Properties: graph.Properties{"": 1}}
+	if _, _, err := newTestClient().buildMergeEdgeAQL(edge, nil, nil); !errors.Is(err, graph.ErrInvalidName) {
+		t.Errorf("Expected an invalid name error, got %v", err)
+	}
+	node := &graph.Node{Labels: []string{"Person"}, Properties: graph.Properties{"name\n": "Ada"}}
+	if _, _, err := newTestClient().buildFindNodeAQL(node, []string{"name\n"}); !errors.Is(err, graph.ErrInvalidName) {
+		t.Errorf("Expected an invalid name error, got %v", err)
+	}
+}
@@ -0,0 +1,104 @@
+package arangodb
+
+import (
+	"context"
+	"errors"
+	"os"
+	"testing"
+
+	"github.com/me2seeks/forge/infra/contract/graph"
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	testEndpoint = os.Getenv("ARANGODB_ENDPOINT")
+	testUsername = os.Getenv("ARANGODB_USERNAME")
+	testPassword = os.Getenv("ARANGODB_PASSWORD")
+)
+
+func setup(t *testing.T) (graph.Client, func()) {
+	if testEndpoint == "" {
+		t.Skip("ARANGODB_ENDPOINT is not set")
+	}
+	ctx := context.Background()
+	client, err := New(ctx, testEndpoint, WithBasicAuth(testUsername, testPassword), WithGraph("forge_test"),
+		WithCollections("forge_test_nodes", "forge_test_edges"))
+	require.NoError(t, err)
+
+	teardown := func() {
+		c := client.(*arangoClient)
+		require.NoError(t, c.exec(ctx, "FOR e IN @@edges REMOVE e IN @@edges", map[string]any{"@edges": c.edges}))
+		require.NoError(t, c.exec(ctx, "FOR n IN @@vertices REMOVE n IN @@vertices", map[string]any{"@vertices": c.vertices}))
+	}
+	return client, teardown
+}
+
+func TestArangoDB(t *testing.T) {
+	client, teardown := setup(t)
+	defer teardown()
+
+	ctx := context.Background()
+
+	a, err := client.CreateNode(ctx, &graph.Node{Labels: []string{"City"}, Properties: graph.Properties{"name": "A"}})
+	require.NoError(t, err)
+	b, err := client.CreateNode(ctx, &graph.Node{Labels: []string{"City"}, Properties: graph.Properties{"name": "B"}})
+	require.NoError(t, err)
+	c, err := client.CreateNode(ctx, &graph.Node{Labels: []string{"City"}, Properties: graph.Properties{"name": "C"}})
+	require.NoError(t, err)
+	for _, e := range []*graph.Edge{
+		{Label: "ROAD", SourceNodeID: a.ID, TargetNodeID: b.ID, Properties: graph.Properties{"km": 10}},
+		{Label: "ROAD", SourceNodeID: b.ID, TargetNodeID: c.ID, Properties: graph.Properties{"km": 10}},
+		{Label: "ROAD", SourceNodeID: a.ID, TargetNodeID: c.ID, Properties: graph.Properties{"km": 50}},
+	} {
+		_, err := client.CreateEdge(ctx, e)
+		require.NoError(t, err)
+	}
+
+	nodes, err := client.FindNodes(ctx, &graph.Query{Match: []graph.Pattern{{Labels: []string{"City"}, Properties: graph.Properties{"name": "B"}}}})
+	require.NoError(t, err)
+	require.Len(t, nodes, 1)
+	require.Equal(t, b.ID, nodes[0].ID)
+
+	count, err := client.Count(ctx, &graph.Query{Match: []graph.Pattern{{Edge: &graph.EdgePattern{Labels: []string{"ROAD"}}}}})
+	require.NoError(t, err)
+	require.EqualValues(t, 3, count)
+
+	paths, err := client.ShortestPath(ctx, a.ID, c.ID, nil)
+	require.NoError(t, err)
+	require.Len(t, paths, 1)
+	require.Len(t, paths[0].Edges, 1)
+
+	paths, err = client.ShortestPath(ctx, a.ID, c.ID, map[string]any{"relationshipWeightProperty": "km", "direction": "OUTGOING"})
+	require.NoError(t, err)
+	require.Len(t, paths, 1)
+	require.Len(t, paths[0].Edges, 2)
+
+	components, err := client.ConnectedComponents(ctx, nil)
+	require.NoError(t, err)
+	require.Len(t, components, 3)
+	require.Equal(t, components[a.ID], components[c.ID])
+
+	tx, err := client.BeginTx(ctx)
+	require.NoError(t, err)
+	_, err = tx.CreateNode(ctx, &graph.Node{Labels: []string{"City"}, Properties: graph.Properties{"name": "D"}})
+	require.NoError(t, err)
+	require.NoError(t, tx.Rollback(ctx))
+	_, err = tx.CreateNode(ctx, &graph.Node{Labels: []string{"City"}})
+	require.ErrorIs(t, err, graph.ErrTxDone)
+
+	deleted, err := client.DeleteNodesByQuery(ctx, &graph.Query{Match: []graph.Pattern{{Properties: graph.Properties{"name": "C"}}}})
+	require.NoError(t, err)
+	require.Equal(t, 1, deleted)
+	edges, err := client.FindEdges(ctx, &graph.Query{Match: []graph.Pattern{{Edge: &graph.EdgePattern{}}}})
+	require.NoError(t, err)
+	require.Len(t, edges, 1)
+
+	require.NoError(t, client.CreateNodeIndex(ctx, "City", []string{"name"}))
+	indexes, err := client.ListIndexes(ctx)
+	require.NoError(t, err)
+	require.NotEmpty(t, indexes)
+	require.NoError(t, client.DropNodeIndex(ctx, "City", []string{"name"}))
+
+	err = client.CreateFullTextIndex(ctx, "cities", []string{"City"}, []string{"name"})
+	require.True(t, errors.Is(err, errors.ErrUnsupported))
+}
@@ -0,0 +1,286 @@
+package arangodb
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/me2seeks/forge/infra/contract/graph"
+)
+
+// streamBatchSize is the number of results fetched per round trip by
+// QueryStream.
+const streamBatchSize = 1000
+
+// cursor is a batch of the results of an AQL query.
+type cursor struct {
+	ID      string `json:"id"`
+	Result  []any  `json:"result"`
+	HasMore bool   `json:"hasMore"`
+}
+
+// cursor runs aql and returns the first batch of its results.
+func (c *arangoClient) cursor(ctx context.Context, aql string, bindVars map[string]any, batchSize int) (*cursor, error) {
+	body := map[string]any{"query": aql}
+	if len(bindVars) > 0 {
+		body["bindVars"] = bindVars
+	}
+	if batchSize > 0 {
+		body["batchSize"] = batchSize
+	}
+	var res cursor
+	if err := c.do(ctx, http.MethodPost, "/_api/cursor", body, &res); err != nil {
+		return nil, err
+	}
+	return &res, nil
+}
+
+// next fetches the next batch of the cursor id.
+func (c *arangoClient) next(ctx context.Context, id string) (*cursor, error) {
+	var res cursor
+	if err := c.do(ctx, http.MethodPut, "/_api/cursor/"+url.PathEscape(id), nil, &res); err != nil {
+		return nil, err
+	}
+	return &res, nil
+}
+
+// query runs aql and returns all its results.
+func (c *arangoClient) query(ctx context.Context, aql string, bindVars map[string]any) ([]any, error) {
+	cur, err := c.cursor(ctx, aql, bindVars, 0)
+	if err != nil {
+		return nil, err
+	}
+	results := cur.Result
+	for cur.HasMore {
+		if cur, err = c.next(ctx, cur.ID); err != nil {
+			return nil, err
+		}
+		results = append(results, cur.Result...)
+	}
+	return results, nil
+}
+
+// exec runs aql for its side effects.
+func (c *arangoClient) exec(ctx context.Context, aql string, bindVars map[string]any) error {
+	_, err := c.query(ctx, aql, bindVars)
+	return err
+}
+
+// queryRecords runs aql and converts its results to records, see toRecord.
+func (c *arangoClient) queryRecords(ctx context.Context, aql string, bindVars map[string]any) (*graph.QueryResult, error) {
+	results, err := c.query(ctx, aql, bindVars)
+	if err != nil {
+		return nil, err
+	}
+	records := make([]graph.Record, 0, len(results))
+	for _, result := range results {
+		records = append(records, toRecord(result))
+	}
+	return &graph.QueryResult{Records: records}, nil
+}
+
+// recordIterator converts the results of a cursor as its batches are fetched.
+type recordIterator struct {
+	client *arangoClient
+	cursor *cursor
+	pos    int
+	record graph.Record
+	err    error
+	closed bool
+}
+
+func (it *recordIterator) Next(ctx context.Context) bool {
+	if it.closed || it.err != nil {
+		return false
+	}
+	for it.pos >= len(it.cursor.Result) {
+		if !it.cursor.HasMore {
+			it.record = nil
+			return false
+		}
+		it.cursor, it.err = it.client.next(ctx, it.cursor.ID)
+		if it.err != nil {
+			it.record = nil
+			return false
+		}
+		it.pos = 0
+	}
+	it.record = toRecord(it.cursor.Result[it.pos])
+	it.pos++
+	return true
+}
+
+func (it *recordIterator) Record() graph.Record {
+	return it.record
+}
+
+func (it *recordIterator) Err() error {
+	return it.err
+}
+
+// Close deletes the cursor on the server if it has results left.
+func (it *recordIterator) Close(ctx context.Context) error {
+	if it.closed {
+		return nil
+	}
+	it.closed = true
+	if it.err != nil || !it.cursor.HasMore {
+		return nil
+	}
+	return it.client.do(ctx, http.MethodDelete, "/_api/cursor/"+url.PathEscape(it.cursor.ID), nil, nil)
+}
+
+// toRecord converts a result to a record. Objects other than documents and
+// paths, like the results of translated queries, are records of their
+// attributes; other results are records of a single "value".
+func toRecord(result any) graph.Record {
+	if obj, ok := result.(map[string]any); ok && !isDocument(obj) && !isPath(obj) {
+		rec := make(graph.Record, len(obj))
+		for key, value := range obj {
+			rec[key] = toGraphEntity(value)
+		}
+		return rec
+	}
+	return graph.Record{"value": toGraphEntity(result)}
+}
+
+// toGraphEntity converts the documents and paths in v to nodes, edges and
+// paths.
+func toGraphEntity(v any) graph.ResultEntity {
+	switch v := v.(type) {
+	case map[string]any:
+		switch {
+		case isEdge(v):
+			return toGraphEdge(v)
+		case isDocument(v):
+			return toGraphNode(v)
+		case isPath(v):
+			return toGraphPath(v)
+		}
+		return v
+	case []any:
+		return toGraphEntities(v)
+	default:
+		return v
+	}
+}
+
+// toGraphEntities converts a list to []*graph.Node or []*graph.Edge if it only
+// holds nodes or edges, or converts its items otherwise.
+func toGraphEntities(list []any) graph.ResultEntity {
+	if len(list) > 0 {
+		nodes, edges := true, true
+		for _, item := range list {
+			obj, ok := item.(map[string]any)
+			edge := ok && isEdge(obj)
+			nodes = nodes && ok && isDocument(obj) && !edge
+			edges = edges && edge
+		}
+		switch {
+		case nodes:
+			out := make([]*graph.Node, len(list))
+			for i, item := range list {
+				out[i] = toGraphNode(item.(map[string]any))
+			}
+			return out
+		case edges:
+			out := make([]*graph.Edge, len(list))
+			for i, item := range list {
+				out[i] = toGraphEdge(item.(map[string]any))
+			}
+			return out
+		}
+	}
+	out := make([]any, len(list))
+	for i, item := range list {
+		out[i] = toGraphEntity(item)
+	}
+	return out
+}
+
+func isDocument(obj map[string]any) bool {
+	_, ok := obj["_id"].(string)
+	return ok
+}
+
+func isEdge(obj map[string]any) bool {
+	_, from := obj["_from"].(string)
+	_, to := obj["_to"].(string)
+	return from && to && isDocument(obj)
+}
+
+// isPath reports whether obj is a path of a traversal or shortest path
+// search, an object of vertices and edges with an optional weight.
+func isPath(obj map[string]any) bool {
+	_, vertices := obj["vertices"].([]any)
+	_, edges := obj["edges"].([]any)
+	if !vertices || !edges {
+		return false
+	}
+	for key := range obj {
+		switch key {
+		case "vertices", "edges", "weight", "weights":
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+func toGraphNode(doc map[string]any) *graph.Node {
+	return &graph.Node{
+		ID:         doc["_id"].(string),
+		Labels:     toStrings(doc[labelsAttr]),
+		Properties: toProperties(doc),
+	}
+}
+
+func toGraphEdge(doc map[string]any) *graph.Edge {
+	label, _ := doc[labelAttr].(string)
+	return &graph.Edge{
+		ID:           doc["_id"].(string),
+		Label:        label,
+		SourceNodeID: doc["_from"].(string),
+		TargetNodeID: doc["_to"].(string),
+		Properties:   toProperties(doc),
+	}
+}
+
+func toGraphPath(obj map[string]any) *graph.Path {
+	path := &graph.Path{}
+	for _, v := range obj["vertices"].([]any) {
+		if doc, ok := v.(map[string]any); ok && isDocument(doc) {
+			path.Nodes = append(path.Nodes, toGraphNode(doc))
+		}
+	}
+	for _, e := range obj["edges"].([]any) {
+		if doc, ok := e.(map[string]any); ok && isEdge(doc) {
+			path.Edges = append(path.Edges, toGraphEdge(doc))
+		}
+	}
+	return path
+}
+
+// toProperties returns the attributes of doc not starting with an underscore.
+func toProperties(doc map[string]any) graph.Properties {
+	props := make(graph.Properties, len(doc))
+	for key, value := range doc {
+		if !strings.HasPrefix(key, "_") {
+			props[key] = value
+		}
+	}
+	return props
+}
+
+// toStrings returns the strings of a list.
+func toStrings(v any) []string {
+	list, _ := v.([]any)
+	values := make([]string, 0, len(list))
+	for _, item := range list {
+		if s, ok := item.(string); ok {
+			values = append(values, s)
+		}
+	}
+	return values
+}
@@ -0,0 +1,635 @@
+package arangodb
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/me2seeks/forge/infra/contract/graph"
+)
+
+func (c *arangoClient) CreateNode(ctx context.Context, node *graph.Node) (*graph.Node, error) {
+	nodes, err := c.CreateNodes(ctx, []*graph.Node{node})
+	if err != nil {
+		return nil, err
+	}
+	return nodes[0], nil
+}
+
+// CreateNodes inserts the nodes in a single query, which ArangoDB runs
+// atomically.
+func (c *arangoClient) CreateNodes(ctx context.Context, nodes []*graph.Node) ([]*graph.Node, error) {
+	if len(nodes) == 0 {
+		return nil, nil
+	}
+	rows := make([]map[string]any, len(nodes))
+	for i, node := range nodes {
+		rows[i] = map[string]any{"labels": labelsOrEmpty(node.Labels), "props": propsOrEmpty(node.Properties)}
+	}
+	aql := "FOR row IN @rows INSERT MERGE(row.props, {_labels: row.labels}) INTO @@vertices RETURN NEW"
+	results, err := c.query(ctx, aql, map[string]any{"rows": rows, "@vertices": c.vertices})
+	if err != nil {
+		return nil, err
+	}
+	created := make([]*graph.Node, len(results))
+	for i, result := range results {
+		created[i] = toGraphNode(result.(map[string]any))
+	}
+	return created, nil
+}
+
+func labelsOrEmpty(labels []string) []string {
+	if labels == nil {
+		return []string{}
+	}
+	return labels
+}
+
+func propsOrEmpty(props graph.Properties) graph.Properties {
+	if props == nil {
+		return graph.Properties{}
+	}
+	return props
+}
+
+func (c *arangoClient) GetNode(ctx context.Context, nodeID string) (*graph.Node, error) {
+	doc, err := c.document(ctx, c.vertices, nodeID)
+	if err != nil || doc == nil {
+		return nil, err
+	}
+	return toGraphNode(doc), nil
+}
+
+// document returns the document id of collection, nil if there is none.
+func (c *arangoClient) document(ctx context.Context, collection, id string) (map[string]any, error) {
+	results, err := c.query(ctx, "FOR d IN @@collection FILTER d._id == @id RETURN d",
+		map[string]any{"@collection": collection, "id": id})
+	if err != nil || len(results) == 0 {
+		return nil, err
+	}
+	return results[0].(map[string]any), nil
+}
+
+// UpdateNode sets properties on the node; setting a property to nil removes it.
+func (c *arangoClient) UpdateNode(ctx context.Context, nodeID string, properties graph.Properties) error {
+	return c.update(ctx, c.vertices, nodeID, properties)
+}
+
+func (c *arangoClient) update(ctx context.Context, collection, id string, properties graph.Properties) error {
+	aql := "FOR d IN @@collection FILTER d._id == @id UPDATE d WITH @props IN @@collection OPTIONS {keepNull: false}"
+	return c.exec(ctx, aql, map[string]any{"@collection": collection, "id": id, "props": propsOrEmpty(properties)})
+}
+
+// DeleteNode deletes the node and its edges.
+func (c *arangoClient) DeleteNode(ctx context.Context, nodeID string) error {
+	aql := "LET removed = (FOR e IN @@edges FILTER e._from == @id OR e._to == @id REMOVE e IN @@edges) " +
+		"FOR n IN @@vertices FILTER n._id == @id REMOVE n IN @@vertices"
+	return c.exec(ctx, aql, map[string]any{"@vertices": c.vertices, "@edges": c.edges, "id": nodeID})
+}
+
+// MergeNode looks up the node and updates or inserts it in a transaction.
+func (c *arangoClient) MergeNode(ctx context.Context, node *graph.Node, matchKeys []string) (*graph.Node, error) {
+	aql, bindVars, err := c.buildFindNodeAQL(node, matchKeys)
+	if err != nil {
+		return nil, err
+	}
+	var merged *graph.Node
+	err = c.atomically(ctx, func(tx *arangoClient) error {
+		results, err := tx.query(ctx, aql, bindVars)
+		if err != nil {
+			return err
+		}
+		if len(results) == 0 {
+			merged, err = tx.CreateNode(ctx, node)
+			return err
+		}
+		existing := toGraphNode(results[0].(map[string]any))
+		if err := tx.UpdateNode(ctx, existing.ID, node.Properties); err != nil {
+			return err
+		}
+		merged, err = tx.GetNode(ctx, existing.ID)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return merged, nil
+}
+
+// buildFindNodeAQL finds a node having the labels of node and its values of
+// matchKeys.
+func (c *arangoClient) buildFindNodeAQL(node *graph.Node, matchKeys []string) (string, map[string]any, error) {
+	if len(node.Labels) == 0 {
+		return "", nil, fmt.Errorf("merge node: no label")
+	}
+	if len(matchKeys) == 0 {
+		return "", nil, fmt.Errorf("merge node: no match key")
+	}
+	if err := graph.ValidateNames(append(slices.Clone(node.Labels), matchKeys...)...); err != nil {
+		return "", nil, err
+	}
+	props := make(graph.Properties, len(matchKeys))
+	for _, key := range matchKeys {
+		value, ok := node.Properties[key]
+		if !ok {
+			return "", nil, fmt.Errorf("merge node: match key %s is not a property of the node", key)
+		}
+		props[key] = value
+	}
+
+	b := c.newBuilder()
+	b.add("FOR n IN %s", b.vertexCollection())
+	b.filterNode("n", node.Labels, props)
+	b.add("LIMIT 1 RETURN n")
+	return b.String(), b.bindVars, nil
+}
+
+func (c *arangoClient) CreateEdge(ctx context.Context, edge *graph.Edge) (*graph.Edge, error) {
+	b := c.newBuilder()
+	if err := b.endpoints(edge); err != nil {
+		return nil, err
+	}
+	b.add("INSERT MERGE(%s, {_from: a._id, _to: b._id, _label: %s}) INTO %s",
+		b.bind(propsOrEmpty(edge.Properties)), b.bind(edge.Label), b.edgeCollection())
+	b.add("RETURN NEW")
+
+	results, err := c.query(ctx, b.String(), b.bindVars)
+	if err != nil || len(results) == 0 {
+		return nil, err
+	}
+	return toGraphEdge(results[0].(map[string]any)), nil
+}
+
+// endpoints loops over the pairs of source and target nodes of edge, bound to
+// a and b. Each endpoint is selected by its selector if set, or by its node ID
+// otherwise.
+func (b *aqlBuilder) endpoints(edge *graph.Edge) error {
+	for _, endpoint := range []struct {
+		alias    string
+		nodeID   string
+		selector *graph.NodeSelector
+	}{
+		{"a", edge.SourceNodeID, edge.SourceNodeSelector},
+		{"b", edge.TargetNodeID, edge.TargetNodeSelector},
+	} {
+		b.declared[endpoint.alias] = true
+		b.add("FOR %s IN %s", endpoint.alias, b.vertexCollection())
+		switch {
+		case endpoint.selector != nil:
+			if err := graph.ValidateNames(endpoint.selector.Labels...); err != nil {
+				return err
+			}
+			if err := endpoint.selector.Properties.ValidateNames(); err != nil {
+				return err
+			}
+			b.filterNode(endpoint.alias, endpoint.selector.Labels, endpoint.selector.Properties)
+		case endpoint.nodeID != "":
+			b.add("FILTER %s._id == %s", endpoint.alias, b.bind(endpoint.nodeID))
+		default:
+			return fmt.Errorf("no node ID or selector for endpoint %s", endpoint.alias)
+		}
+	}
+	return nil
+}
+
+// CreateEdges inserts the edges in a single query, failing if an endpoint is
+// missing.
+func (c *arangoClient) CreateEdges(ctx context.Context, edges []*graph.Edge) ([]*graph.Edge, error) {
+	if len(edges) == 0 {
+		return nil, nil
+	}
+	rows := make([]map[string]any, len(edges))
+	for i, edge := range edges {
+		rows[i] = map[string]any{
+			"i":     i,
+			"from":  edge.SourceNodeID,
+			"to":    edge.TargetNodeID,
+			"label": edge.Label,
+			"props": propsOrEmpty(edge.Properties),
+		}
+	}
+	aql := "FOR row IN @rows " +
+		"LET a = DOCUMENT(@@vertices, row.from) LET b = DOCUMENT(@@vertices, row.to) " +
+		"FILTER ASSERT(a != null AND b != null, CONCAT('create edges: endpoint of edge ', row.i, ' not found')) " +
+		"INSERT MERGE(row.props, {_from: a._id, _to: b._id, _label: row.label}) INTO @@edges RETURN NEW"
+	results, err := c.query(ctx, aql, map[string]any{"rows": rows, "@vertices": c.vertices, "@edges": c.edges})
+	if err != nil {
+		return nil, err
+	}
+	created := make([]*graph.Edge, len(results))
+	for i, result := range results {
+		created[i] = toGraphEdge(result.(map[string]any))
+	}
+	return created, nil
+}
+
+// MergeEdge upserts the edge in a single query, after checking its endpoints
+// select a single pair of nodes.
+func (c *arangoClient) MergeEdge(ctx context.Context, edge *graph.Edge, onCreate, onMatch graph.Properties) (*graph.Edge, error) {
+	aql, bindVars, err := c.buildMergeEdgeAQL(edge, onCreate, onMatch)
+	if err != nil {
+		return nil, err
+	}
+	results, err := c.query(ctx, aql, bindVars)
+	if err != nil || len(results) == 0 {
+		return nil, err
+	}
+	return toGraphEdge(results[0].(map[string]any)), nil
+}
+
+func (c *arangoClient) buildMergeEdgeAQL(edge *graph.Edge, onCreate, onMatch graph.Properties) (string, map[string]any, error) {
+	if edge.Label == "" {
+		return "", nil, fmt.Errorf("merge edge: no label")
+	}
+	if err := edge.ValidateNames(); err != nil {
+		return "", nil, err
+	}
+	if err := edge.Properties.ValidateNames(); err != nil {
+		return "", nil, err
+	}
+	b := c.newBuilder()
+	if err := b.endpoints(edge); err != nil {
+		return "", nil, err
+	}
+	pairs := "LET pairs = (" + strings.Join(b.lines, " ") + " RETURN {a: a._id, b: b._id})"
+	b.lines = []string{pairs}
+	b.add("FILTER ASSERT(LENGTH(pairs) <= 1, CONCAT('merge edge: endpoints match ', LENGTH(pairs), ' pairs of nodes'))")
+	b.add("FOR pair IN pairs")
+
+	label := b.bind(edge.Label)
+	search := []string{"_from: pair.a", "_to: pair.b", "_label: " + label}
+	for _, key := range slices.Sorted(maps.Keys(edge.Properties)) {
+		search = append(search, quoteName(key)+": "+b.bind(edge.Properties[key]))
+	}
+	b.add("UPSERT {%s}", strings.Join(search, ", "))
+	b.add("INSERT MERGE(%s, %s, {_from: pair.a, _to: pair.b, _label: %s})",
+		b.bind(propsOrEmpty(edge.Properties)), b.bind(propsOrEmpty(onCreate)), label)
+	b.add("UPDATE %s IN %s OPTIONS {keepNull: false}", b.bind(propsOrEmpty(onMatch)), b.edgeCollection())
+	b.add("RETURN NEW")
+	return b.String(), b.bindVars, nil
+}
+
+func (c *arangoClient) GetEdge(ctx context.Context, edgeID string) (*graph.Edge, error) {
+	doc, err := c.document(ctx, c.edges, edgeID)
+	if err != nil || doc == nil {
+		return nil, err
+	}
+	return toGraphEdge(doc), nil
+}
+
+// UpdateEdge sets properties on the edge; setting a property to nil removes it.
+func (c *arangoClient) UpdateEdge(ctx context.Context, edgeID string, properties graph.Properties) error {
+	return c.update(ctx, c.edges, edgeID, properties)
+}
+
+func (c *arangoClient) DeleteEdge(ctx context.Context, edgeID string) error {
+	aql := "FOR e IN @@edges FILTER e._id == @id REMOVE e IN @@edges"
+	return c.exec(ctx, aql, map[string]any{"@edges": c.edges, "id": edgeID})
+}
+
+// GetNeighbors traverses the graph from the node, never following an edge
+// twice on a path, and collects the distinct ends and edges of the paths.
+func (c *arangoClient) GetNeighbors(ctx context.Context, nodeID string, opts ...graph.NeighborOpt) (*graph.Neighborhood, error) {
+	aql, bindVars := c.buildNeighborsAQL(nodeID, graph.NewNeighborOptions(opts...))
+	res, err := c.queryRecords(ctx, aql, bindVars)
+	if err != nil {
+		return nil, err
+	}
+	neighborhood := &graph.Neighborhood{}
+	nodes, edges := make(map[string]bool), make(map[string]bool)
+	for _, record := range res.Records {
+		if node, ok := record["node"].(*graph.Node); ok && !nodes[node.ID] {
+			nodes[node.ID] = true
+			neighborhood.Nodes = append(neighborhood.Nodes, node)
+		}
+		walk, _ := record["edges"].([]*graph.Edge)
+		for _, edge := range walk {
+			if !edges[edge.ID] {
+				edges[edge.ID] = true
+				neighborhood.Edges = append(neighborhood.Edges, edge)
+			}
+		}
+	}
+	return neighborhood, nil
+}
+
+// buildNeighborsAQL returns the end node and the edges of each path of
+// GetNeighbors.
+func (c *arangoClient) buildNeighborsAQL(nodeID string, o *graph.NeighborOptions) (string, map[string]any) {
+	direction := "ANY"
+	switch o.Direction {
+	case graph.DirectionOutgoing:
+		direction = "OUTBOUND"
+	case graph.DirectionIncoming:
+		direction = "INBOUND"
+	}
+	b := c.newBuilder()
+	start := b.bind(nodeID)
+	b.add("FOR v, e, p IN 1..%d %s %s GRAPH %s", o.Depth, direction, start, b.graphName())
+	if len(o.RelationshipTypes) > 0 {
+		b.add("FILTER p.edges[*].%s ALL IN %s", labelAttr, b.bind(o.RelationshipTypes))
+	}
+	b.add("FILTER v._id != %s", start)
+	b.add("RETURN {node: v, edges: p.edges}")
+	return b.String(), b.bindVars
+}
+
+// Traverse loads the subgraph within the depth of spec from the node in a
+// single query, and runs the traversal in memory over it.
+func (c *arangoClient) Traverse(ctx context.Context, startNodeID string, spec graph.TraversalSpec) ([]*graph.Path, error) {
+	aql, bindVars := c.buildSubgraphAQL(startNodeID, spec.NeighborOptions())
+	res, err := c.queryRecords(ctx, aql, bindVars)
+	if err != nil || len(res.Records) == 0 {
+		return nil, err
+	}
+	nodes, _ := res.Records[0]["nodes"].([]*graph.Node)
+	edges, _ := res.Records[0]["edges"].([]*graph.Edge)
+	return graph.TraverseSubgraph(startNodeID, nodes, edges, spec)
+}
+
+// buildSubgraphAQL returns the nodes within the depth of o from the node,
+// itself first, and the edges of the types of o between them, in a single
+// result, none if the node doesn't exist.
+func (c *arangoClient) buildSubgraphAQL(nodeID string, o *graph.NeighborOptions) (string, map[string]any) {
+	direction := "ANY"
+	switch o.Direction {
+	case graph.DirectionOutgoing:
+		direction = "OUTBOUND"
+	case graph.DirectionIncoming:
+		direction = "INBOUND"
+	}
+	b := c.newBuilder()
+	start := b.bind(nodeID)
+	graphName := b.graphName()
+	var pathFilter, edgeFilter string
+	if len(o.RelationshipTypes) > 0 {
+		types := b.bind(o.RelationshipTypes)
+		pathFilter = fmt.Sprintf(" FILTER p.edges[*].%s ALL IN %s", labelAttr, types)
+		edgeFilter = fmt.Sprintf(" FILTER e.%s IN %s", labelAttr, types)
+	}
+	b.add("FOR start IN [DOCUMENT(%s)] FILTER start != null", start)
+	b.add("LET nodes = APPEND([start], (FOR v, e, p IN 1..%d %s start GRAPH %s OPTIONS {uniqueVertices: \"path\"}%s FILTER v._id != start._id RETURN DISTINCT v))",
+		o.Depth, direction, graphName, pathFilter)
+	b.add("LET ids = nodes[*]._id")
+	b.add("LET edges = (FOR v IN nodes FOR w, e IN 1..1 OUTBOUND v GRAPH %s FILTER w._id IN ids%s RETURN e)", graphName, edgeFilter)
+	b.add("RETURN {nodes, edges}")
+	return b.String(), b.bindVars
+}
+
+func (c *arangoClient) Query(ctx context.Context, query *graph.Query) (*graph.QueryResult, error) {
+	aql, bindVars, err := c.buildQueryAQL(query)
+	if err != nil {
+		return nil, err
+	}
+	return c.queryRecords(ctx, aql, bindVars)
+}
+
+// RawQuery runs an AQL query. Each result that is an object other than a
+// document or a path is a record of its attributes, e.g. RETURN {n, m}, and
+// any other result a record of a single "value".
+func (c *arangoClient) RawQuery(ctx context.Context, query string, params map[string]any) (*graph.QueryResult, error) {
+	return c.queryRecords(ctx, query, params)
+}
+
+// BatchQuery runs an AQL statement in the body of FOR row IN @rows, e.g.
+//
+//	UPSERT {key: row.key} INSERT row UPDATE row IN accounts
+func (c *arangoClient) BatchQuery(ctx context.Context, statement string, rows []map[string]any, params map[string]any) (*graph.QueryResult, error) {
+	if _, ok := params["rows"]; ok {
+		return nil, fmt.Errorf("batch query: parameter rows is reserved")
+	}
+	bindVars := make(map[string]any, len(params)+1)
+	maps.Copy(bindVars, params)
+	bindVars["rows"] = rows
+	return c.queryRecords(ctx, "FOR row IN @rows "+statement, bindVars)
+}
+
+// QueryStream runs the query on a cursor whose batches are fetched as the
+// records are consumed.
+func (c *arangoClient) QueryStream(ctx context.Context, query *graph.Query) (graph.RecordIterator, error) {
+	aql, bindVars, err := c.buildQueryAQL(query)
+	if err != nil {
+		return nil, err
+	}
+	cur, err := c.cursor(ctx, aql, bindVars, streamBatchSize)
+	if err != nil {
+		return nil, err
+	}
+	return &recordIterator{client: c, cursor: cur}, nil
+}
+
+// ExportGraph writes the entities of the records of QueryStream.
+func (c *arangoClient) ExportGraph(ctx context.Context, w io.Writer, format graph.ExportFormat, query *graph.Query) error {
+	return graph.Export(ctx, c, w, format, query)
+}
+
+func (c *arangoClient) FindNodes(ctx context.Context, query *graph.Query) ([]*graph.Node, error) {
+	result, err := c.Query(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	var nodes []*graph.Node
+	seen := make(map[string]struct{})
+	for _, record := range result.Records {
+		for _, entity := range record {
+			if node, ok := entity.(*graph.Node); ok {
+				if _, exists := seen[node.ID]; !exists {
+					nodes = append(nodes, node)
+					seen[node.ID] = struct{}{}
+				}
+			}
+		}
+	}
+	return nodes, nil
+}
+
+func (c *arangoClient) FindEdges(ctx context.Context, query *graph.Query) ([]*graph.Edge, error) {
+	result, err := c.Query(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	var edges []*graph.Edge
+	seen := make(map[string]struct{})
+	add := func(edge *graph.Edge) {
+		if _, exists := seen[edge.ID]; !exists {
+			edges = append(edges, edge)
+			seen[edge.ID] = struct{}{}
+		}
+	}
+	for _, record := range result.Records {
+		for _, entity := range record {
+			switch v := entity.(type) {
+			case *graph.Edge:
+				add(v)
+			case []*graph.Edge:
+				for _, edge := range v {
+					add(edge)
+				}
+			}
+		}
+	}
+	return edges, nil
+}
+
+func (c *arangoClient) FindPaths(ctx context.Context, query *graph.Query) ([]*graph.Path, error) {
+	result, err := c.Query(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	var paths []*graph.Path
+	for _, record := range result.Records {
+		for _, entity := range record {
+			switch v := entity.(type) {
+			case *graph.Path:
+				paths = append(paths, v)
+			case []any:
+				for _, item := range v {
+					if path, ok := item.(*graph.Path); ok {
+						paths = append(paths, path)
+					}
+				}
+			}
+		}
+	}
+	return paths, nil
+}
+
+func (c *arangoClient) Count(ctx context.Context, query *graph.Query) (int64, error) {
+	aql, bindVars, err := c.buildCountAQL(query)
+	if err != nil {
+		return 0, err
+	}
+	results, err := c.query(ctx, aql, bindVars)
+	if err != nil || len(results) == 0 {
+		return 0, err
+	}
+	count, _ := results[0].(int64)
+	return count, nil
+}
+
+// UpdateNodesByQuery sets properties on the nodes of the first alias of the
+// query, in a single query.
+func (c *arangoClient) UpdateNodesByQuery(ctx context.Context, query *graph.Query, properties graph.Properties) (int, error) {
+	return c.updateByQuery(ctx, query, false, properties)
+}
+
+// UpdateEdgesByQuery sets properties on the edges of the edge alias of the
+// first pattern of the query, in a single query.
+func (c *arangoClient) UpdateEdgesByQuery(ctx context.Context, query *graph.Query, properties graph.Properties) (int, error) {
+	return c.updateByQuery(ctx, query, true, properties)
+}
+
+func (c *arangoClient) updateByQuery(ctx context.Context, query *graph.Query, edges bool, properties graph.Properties) (int, error) {
+	b, targets, err := c.buildTargetAQL(query, edges)
+	if err != nil {
+		return 0, err
+	}
+	collection := b.vertexCollection()
+	if edges {
+		collection = b.edgeCollection()
+	}
+	keys, key, count := b.fresh("keys"), b.fresh("key"), b.fresh("count")
+	b.add("LET %s = %s", keys, targets)
+	b.add("FOR %s IN %s UPDATE %s WITH %s IN %s OPTIONS {keepNull: false}", key, keys, key, b.bind(propsOrEmpty(properties)), collection)
+	b.add("COLLECT WITH COUNT INTO %s RETURN %s", count, count)
+	return c.count(ctx, b)
+}
+
+// DeleteNodesByQuery deletes the nodes of the first alias of the query and
+// their edges, in a single query.
+func (c *arangoClient) DeleteNodesByQuery(ctx context.Context, query *graph.Query) (int, error) {
+	b, targets, err := c.buildTargetAQL(query, false)
+	if err != nil {
+		return 0, err
+	}
+	keys, ids, e, key, count := b.fresh("keys"), b.fresh("ids"), b.fresh("e"), b.fresh("key"), b.fresh("count")
+	vertices, edges := b.vertexCollection(), b.edgeCollection()
+	b.add("LET %s = %s", keys, targets)
+	b.add("LET %s = (FOR %s IN %s RETURN CONCAT(%s, '/', %s))", ids, key, keys, b.bind(c.vertices), key)
+	b.add("LET %s = (FOR %s IN %s FILTER %s._from IN %s OR %s._to IN %s REMOVE %s IN %s)",
+		b.fresh("removed"), e, edges, e, ids, e, ids, e, edges)
+	b.add("FOR %s IN %s REMOVE %s IN %s", key, keys, key, vertices)
+	b.add("COLLECT WITH COUNT INTO %s RETURN %s", count, count)
+	return c.count(ctx, b)
+}
+
+// DeleteEdgesByQuery deletes the edges of the edge alias of the first pattern
+// of the query, in a single query.
+func (c *arangoClient) DeleteEdgesByQuery(ctx context.Context, query *graph.Query) (int, error) {
+	b, targets, err := c.buildTargetAQL(query, true)
+	if err != nil {
+		return 0, err
+	}
+	keys, key, count := b.fresh("keys"), b.fresh("key"), b.fresh("count")
+	b.add("LET %s = %s", keys, targets)
+	b.add("FOR %s IN %s REMOVE %s IN %s", key, keys, key, b.edgeCollection())
+	b.add("COLLECT WITH COUNT INTO %s RETURN %s", count, count)
+	return c.count(ctx, b)
+}
+
+// count runs the query of b returning a count.
+func (c *arangoClient) count(ctx context.Context, b *aqlBuilder) (int, error) {
+	results, err := c.query(ctx, b.String(), b.bindVars)
+	if err != nil || len(results) == 0 {
+		return 0, err
+	}
+	count, _ := results[0].(int64)
+	return int(count), nil
+}
+
+type bulkWriter struct {
+	nodes  []*graph.Node
+	edges  []*graph.Edge
+	client *arangoClient
+}
+
+func (c *arangoClient) NewBulkWriter() graph.BulkWriter {
+	return &bulkWriter{
+		client: c,
+	}
+}
+
+// ImportGraph imports in batches of the bulk writer, see Import.
+func (c *arangoClient) ImportGraph(ctx context.Context, nodes, edges io.Reader, opts ...graph.ImportOpt) (*graph.ImportResult, error) {
+	return graph.Import(ctx, c, nodes, edges, opts...)
+}
+
+func (b *bulkWriter) AddNode(ctx context.Context, node *graph.Node) error {
+	b.nodes = append(b.nodes, node)
+	return nil
+}
+
+func (b *bulkWriter) AddEdge(ctx context.Context, edge *graph.Edge) error {
+	b.edges = append(b.edges, edge)
+	return nil
+}
+
+// Close creates the added nodes, then edges, in a single transaction.
+func (b *bulkWriter) Close(ctx context.Context) error {
+	if len(b.nodes) == 0 && len(b.edges) == 0 {
+		return nil
+	}
+	var nodes []*graph.Node
+	var edges []*graph.Edge
+	err := b.client.atomically(ctx, func(tx *arangoClient) error {
+		var err error
+		if nodes, err = tx.CreateNodes(ctx, b.nodes); err != nil {
+			return err
+		}
+		edges, err = tx.CreateEdges(ctx, b.edges)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	for i, node := range nodes {
+		b.nodes[i].ID = node.ID
+	}
+	for i, edge := range edges {
+		b.edges[i].ID = edge.ID
+	}
+	return nil
+}
@@ -0,0 +1,204 @@
+package arangodb
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/me2seeks/forge/infra/contract/graph"
+)
+
+// ArangoDB indexes cover the documents of a collection whatever their labels,
+// so the indexes and constraints of a label apply to all the nodes, or edges,
+// having the properties indexed. They are named after the label and
+// properties, e.g. "idx_Person_name", to be listed and dropped by them.
+
+// CreateNodeIndex creates a persistent index on the properties of the vertex
+// collection.
+func (c *arangoClient) CreateNodeIndex(ctx context.Context, label string, properties []string) error {
+	return c.createIndex(ctx, c.vertices, indexName("idx", label, properties), properties, false)
+}
+
+// CreateEdgeIndex creates a persistent index on the properties of the edge
+// collection.
+func (c *arangoClient) CreateEdgeIndex(ctx context.Context, label string, properties []string) error {
+	return c.createIndex(ctx, c.edges, indexName("idx", label, properties), properties, false)
+}
+
+func (c *arangoClient) DropNodeIndex(ctx context.Context, label string, properties []string) error {
+	return c.dropIndex(ctx, c.vertices, indexName("idx", label, properties))
+}
+
+func (c *arangoClient) DropEdgeIndex(ctx context.Context, label string, properties []string) error {
+	return c.dropIndex(ctx, c.edges, indexName("idx", label, properties))
+}
+
+// CreateConstraint creates a unique sparse index on property, so nodes
+// without it aren't constrained. ArangoDB has no existence constraint.
+func (c *arangoClient) CreateConstraint(ctx context.Context, label, property string, constraintType graph.ConstraintType) error {
+	if constraintType != graph.ConstraintUnique {
+		return unsupported("constraint type " + string(constraintType))
+	}
+	return c.createIndex(ctx, c.vertices, indexName("uniq", label, []string{property}), []string{property}, true)
+}
+
+func (c *arangoClient) DropConstraint(ctx context.Context, label, property string, constraintType graph.ConstraintType) error {
+	if constraintType != graph.ConstraintUnique {
+		return unsupported("constraint type " + string(constraintType))
+	}
+	return c.dropIndex(ctx, c.vertices, indexName("uniq", label, []string{property}))
+}
+
+func indexName(prefix, label string, properties []string) string {
+	return prefix + "_" + label + "_" + strings.Join(properties, "_")
+}
+
+// createIndex creates a persistent index on fields of collection, unique and
+// sparse if unique is true, unless it exists.
+func (c *arangoClient) createIndex(ctx context.Context, collection, name string, fields []string, unique bool) error {
+	body := map[string]any{
+		"type":   "persistent",
+		"name":   name,
+		"fields": fields,
+		"unique": unique,
+		"sparse": unique,
+	}
+	return c.do(ctx, http.MethodPost, "/_api/index?collection="+url.QueryEscape(collection), body, nil)
+}
+
+// dropIndex drops the index name of collection if it exists.
+func (c *arangoClient) dropIndex(ctx context.Context, collection, name string) error {
+	indexes, err := c.collectionIndexes(ctx, collection)
+	if err != nil {
+		return err
+	}
+	for _, idx := range indexes {
+		if idx.Name != name {
+			continue
+		}
+		// The ID of an index is prefixed with its collection, e.g. "nodes/42".
+		err := c.do(ctx, http.MethodDelete, "/_api/index/"+idx.ID, nil, nil)
+		if isErrorNum(err, errIndexNotFound) {
+			return nil
+		}
+		return err
+	}
+	return nil
+}
+
+// index is an index of the index API.
+type index struct {
+	ID     string   `json:"id"`
+	Name   string   `json:"name"`
+	Type   string   `json:"type"`
+	Fields []string `json:"fields"`
+	Unique bool     `json:"unique"`
+}
+
+func (c *arangoClient) collectionIndexes(ctx context.Context, collection string) ([]index, error) {
+	var res struct {
+		Indexes []index `json:"indexes"`
+	}
+	err := c.do(ctx, http.MethodGet, "/_api/index?collection="+url.QueryEscape(collection), nil, &res)
+	return res.Indexes, err
+}
+
+// indexes returns the indexes of the vertex and edge collections, by entity
+// type.
+func (c *arangoClient) indexes(ctx context.Context) (map[graph.EntityType][]index, error) {
+	indexes := make(map[graph.EntityType][]index, 2)
+	for entityType, collection := range map[graph.EntityType]string{graph.EntityNode: c.vertices, graph.EntityRelationship: c.edges} {
+		list, err := c.collectionIndexes(ctx, collection)
+		if err != nil {
+			return nil, err
+		}
+		indexes[entityType] = list
+	}
+	return indexes, nil
+}
+
+// ListIndexes returns the indexes of the vertex and edge collections, except
+// the primary and edge indexes managed by ArangoDB and the unique indexes
+// listed as constraints. The label of the indexes created by CreateNodeIndex
+// and CreateEdgeIndex is parsed from their name.
+func (c *arangoClient) ListIndexes(ctx context.Context) ([]*graph.IndexInfo, error) {
+	indexes, err := c.indexes(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var infos []*graph.IndexInfo
+	for _, entityType := range []graph.EntityType{graph.EntityNode, graph.EntityRelationship} {
+		for _, idx := range indexes[entityType] {
+			if idx.Unique || idx.Type == "primary" || idx.Type == "edge" {
+				continue
+			}
+			infos = append(infos, &graph.IndexInfo{
+				Name:          idx.Name,
+				Type:          strings.ToUpper(idx.Type),
+				EntityType:    entityType,
+				LabelsOrTypes: indexLabels(idx.Name, "idx"),
+				Properties:    idx.Fields,
+				State:         "ONLINE",
+			})
+		}
+	}
+	return infos, nil
+}
+
+// ListConstraints returns the unique indexes of the vertex collection, other
+// than its primary index.
+func (c *arangoClient) ListConstraints(ctx context.Context) ([]*graph.ConstraintInfo, error) {
+	indexes, err := c.indexes(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var infos []*graph.ConstraintInfo
+	for _, idx := range indexes[graph.EntityNode] {
+		if !idx.Unique || idx.Type == "primary" {
+			continue
+		}
+		infos = append(infos, &graph.ConstraintInfo{
+			Name:          idx.Name,
+			Type:          graph.ConstraintUnique,
+			EntityType:    graph.EntityNode,
+			LabelsOrTypes: indexLabels(idx.Name, "uniq"),
+			Properties:    idx.Fields,
+		})
+	}
+	return infos, nil
+}
+
+// indexLabels returns the label in the name of an index created with prefix,
+// see indexName, or nil for other indexes.
+func indexLabels(name, prefix string) []string {
+	rest, ok := strings.CutPrefix(name, prefix+"_")
+	if !ok {
+		return nil
+	}
+	label, _, ok := strings.Cut(rest, "_")
+	if !ok || label == "" {
+		return nil
+	}
+	return []string{label}
+}
+
+// ListLabels returns the labels of the stored nodes.
+func (c *arangoClient) ListLabels(ctx context.Context) ([]string, error) {
+	aql := "FOR n IN @@vertices FOR label IN n._labels COLLECT value = label SORT value RETURN value"
+	return c.listStrings(ctx, aql, map[string]any{"@vertices": c.vertices})
+}
+
+// ListRelationshipTypes returns the types of the stored edges.
+func (c *arangoClient) ListRelationshipTypes(ctx context.Context) ([]string, error) {
+	aql := "FOR e IN @@edges COLLECT value = e._label SORT value RETURN value"
+	return c.listStrings(ctx, aql, map[string]any{"@edges": c.edges})
+}
+
+func (c *arangoClient) listStrings(ctx context.Context, aql string, bindVars map[string]any) ([]string, error) {
+	results, err := c.query(ctx, aql, bindVars)
+	if err != nil {
+		return nil, err
+	}
+	return toStrings(results), nil
+}
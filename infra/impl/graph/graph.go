@@ -0,0 +1,113 @@
+package graph
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/me2seeks/forge/conf"
+	"github.com/me2seeks/forge/infra/contract/graph"
+	"github.com/me2seeks/forge/infra/impl/graph/arangodb"
+	"github.com/me2seeks/forge/infra/impl/graph/memgraph"
+	"github.com/me2seeks/forge/infra/impl/graph/memory"
+	"github.com/me2seeks/forge/infra/impl/graph/nebula"
+	"github.com/me2seeks/forge/infra/impl/graph/neo4j"
+	"github.com/me2seeks/forge/infra/impl/graph/neptune"
+)
+
+type Client = graph.Client
+
+// Config selects and configures the backend built by NewWithConfig.
+type Config struct {
+	Type     string `env:"GRAPH_TYPE" yaml:"type" json:"type" required:"true"`
+	Database string `env:"GRAPH_DATABASE" yaml:"database" json:"database"`
+	Neo4j    struct {
+		URI      string `env:"NEO4J_URI" yaml:"uri" json:"uri"`
+		Username string `env:"NEO4J_USERNAME" yaml:"username" json:"username"`
+		Password string `env:"NEO4J_PASSWORD" yaml:"password" json:"password"`
+	} `yaml:"neo4j" json:"neo4j"`
+	Memgraph struct {
+		URI      string `env:"MEMGRAPH_URI" yaml:"uri" json:"uri"`
+		Username string `env:"MEMGRAPH_USERNAME" yaml:"username" json:"username"`
+		Password string `env:"MEMGRAPH_PASSWORD" yaml:"password" json:"password"`
+	} `yaml:"memgraph" json:"memgraph"`
+	ArangoDB struct {
+		Endpoint string `env:"ARANGODB_ENDPOINT" yaml:"endpoint" json:"endpoint"`
+		Username string `env:"ARANGODB_USERNAME" yaml:"username" json:"username"`
+		Password string `env:"ARANGODB_PASSWORD" yaml:"password" json:"password"`
+		Graph    string `env:"ARANGODB_GRAPH" yaml:"graph" json:"graph"`
+	} `yaml:"arangodb" json:"arangodb"`
+	Neptune struct {
+		Endpoint       string `env:"NEPTUNE_ENDPOINT" yaml:"endpoint" json:"endpoint"`
+		ReaderEndpoint string `env:"NEPTUNE_READER_ENDPOINT" yaml:"reader_endpoint" json:"reader_endpoint"`
+		// DisableIAMAuth sends unsigned requests, to clusters without IAM
+		// database authentication. Requests are otherwise signed with the AWS
+		// credentials and region of the environment.
+		DisableIAMAuth bool `env:"NEPTUNE_DISABLE_IAM_AUTH" yaml:"disable_iam_auth" json:"disable_iam_auth"`
+	} `yaml:"neptune" json:"neptune"`
+	// Nebula runs in the space Database.
+	Nebula struct {
+		// Endpoint is the endpoint of the nebula-http-gateway, and Address the
+		// address of the graph service as reached from it, e.g. graphd:9669.
+		Endpoint string `env:"NEBULA_ENDPOINT" yaml:"endpoint" json:"endpoint"`
+		Address  string `env:"NEBULA_ADDRESS" yaml:"address" json:"address"`
+		Username string `env:"NEBULA_USERNAME" yaml:"username" json:"username"`
+		Password string `env:"NEBULA_PASSWORD" yaml:"password" json:"password"`
+	} `yaml:"nebula" json:"nebula"`
+}
+
+// New creates the backend configured by the GRAPH_TYPE and related env vars.
+func New(ctx context.Context) (Client, error) {
+	var cfg Config
+	if err := conf.Load(&cfg); err != nil {
+		return nil, err
+	}
+	return NewWithConfig(ctx, cfg)
+}
+
+func NewWithConfig(ctx context.Context, cfg Config) (Client, error) {
+	switch cfg.Type {
+	case "neo4j":
+		opts := []neo4j.Option{neo4j.WithDatabase(cfg.Database)}
+		if cfg.Neo4j.Username != "" {
+			opts = append(opts, neo4j.WithBasicAuth(cfg.Neo4j.Username, cfg.Neo4j.Password, ""))
+		}
+		return neo4j.New(ctx, cfg.Neo4j.URI, opts...)
+	case "memgraph":
+		opts := []memgraph.Option{memgraph.WithDatabase(cfg.Database)}
+		if cfg.Memgraph.Username != "" {
+			opts = append(opts, memgraph.WithBasicAuth(cfg.Memgraph.Username, cfg.Memgraph.Password))
+		}
+		return memgraph.New(ctx, cfg.Memgraph.URI, opts...)
+	case "arangodb":
+		var opts []arangodb.Option
+		if cfg.Database != "" {
+			opts = append(opts, arangodb.WithDatabase(cfg.Database))
+		}
+		if cfg.ArangoDB.Graph != "" {
+			opts = append(opts, arangodb.WithGraph(cfg.ArangoDB.Graph))
+		}
+		if cfg.ArangoDB.Username != "" {
+			opts = append(opts, arangodb.WithBasicAuth(cfg.ArangoDB.Username, cfg.ArangoDB.Password))
+		}
+		return arangodb.New(ctx, cfg.ArangoDB.Endpoint, opts...)
+	case "neptune":
+		var opts []neptune.Option
+		if cfg.Neptune.ReaderEndpoint != "" {
+			opts = append(opts, neptune.WithReaderEndpoint(cfg.Neptune.ReaderEndpoint))
+		}
+		if cfg.Neptune.DisableIAMAuth {
+			opts = append(opts, neptune.WithoutIAMAuth())
+		}
+		return neptune.New(ctx, cfg.Neptune.Endpoint, opts...)
+	case "nebula":
+		opts := []nebula.Option{nebula.WithSpace(cfg.Database)}
+		if cfg.Nebula.Username != "" {
+			opts = append(opts, nebula.WithBasicAuth(cfg.Nebula.Username, cfg.Nebula.Password))
+		}
+		return nebula.New(ctx, cfg.Nebula.Endpoint, cfg.Nebula.Address, opts...)
+	case "memory":
+		return memory.New(), nil
+	}
+
+	return nil, fmt.Errorf("unknown graph type: %s", cfg.Type)
+}
@@ -0,0 +1,67 @@
+// Package internal holds the helpers shared by the graph backends: reading
+// the algorithm configs and the union-find of connected components.
+package internal
+
+// ConfigString returns the string under key, or "".
+func ConfigString(config map[string]any, key string) string {
+	s, _ := config[key].(string)
+	return s
+}
+
+// ConfigStrings returns the strings under key, given as a string, []string or
+// []any.
+func ConfigStrings(config map[string]any, key string) []string {
+	switch v := config[key].(type) {
+	case string:
+		return []string{v}
+	case []string:
+		return v
+	case []any:
+		strs := make([]string, 0, len(v))
+		for _, item := range v {
+			if s, ok := item.(string); ok {
+				strs = append(strs, s)
+			}
+		}
+		return strs
+	default:
+		return nil
+	}
+}
+
+// ConfigInt returns the number under key as an int, given as an int, int64 or
+// float64, the latter being how JSON decodes numbers.
+func ConfigInt(config map[string]any, key string) (int, bool) {
+	switch v := config[key].(type) {
+	case int:
+		return v, true
+	case int64:
+		return int(v), true
+	case float64:
+		return int(v), true
+	default:
+		return 0, false
+	}
+}
+
+// ConfigIntOr returns the number under key as an int, or def.
+func ConfigIntOr(config map[string]any, key string, def int) int {
+	if v, ok := ConfigInt(config, key); ok {
+		return v
+	}
+	return def
+}
+
+// ConfigFloatOr returns the number under key as a float64, or def.
+func ConfigFloatOr(config map[string]any, key string, def float64) float64 {
+	switch v := config[key].(type) {
+	case float64:
+		return v
+	case int:
+		return float64(v)
+	case int64:
+		return float64(v)
+	default:
+		return def
+	}
+}
@@ -0,0 +1,56 @@
+package internal
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// TestUnionFind tests that components are rooted at their smallest ID.
+func TestUnionFind(t *testing.T) {
+	parent := map[string]string{"a": "a", "b": "b", "c": "c", "d": "d", "e": "e"}
+	Union(parent, "d", "b", strings.Compare)
+	Union(parent, "c", "d", strings.Compare)
+	Union(parent, "e", "e", strings.Compare)
+
+	components := make(map[string]string)
+	for id := range parent {
+		components[id] = Find(parent, id)
+	}
+	expected := map[string]string{"a": "a", "b": "b", "c": "b", "d": "b", "e": "e"}
+	if !reflect.DeepEqual(components, expected) {
+		t.Errorf("Components mismatch.\nGot:  %v\nWant: %v", components, expected)
+	}
+}
+
+// TestConfig tests the conversions of the config values.
+func TestConfig(t *testing.T) {
+	config := map[string]any{
+		"name":   "pagerank",
+		"one":    "KNOWS",
+		"types":  []any{"KNOWS", 1, "LIKES"},
+		"limit":  float64(5),
+		"factor": 2,
+	}
+	if got := ConfigString(config, "name"); got != "pagerank" {
+		t.Errorf("Unexpected string: %q", got)
+	}
+	if got := ConfigStrings(config, "one"); !reflect.DeepEqual(got, []string{"KNOWS"}) {
+		t.Errorf("Unexpected strings: %v", got)
+	}
+	if got := ConfigStrings(config, "types"); !reflect.DeepEqual(got, []string{"KNOWS", "LIKES"}) {
+		t.Errorf("Unexpected strings: %v", got)
+	}
+	if got := ConfigIntOr(config, "limit", 10); got != 5 {
+		t.Errorf("Unexpected int: %d", got)
+	}
+	if got := ConfigIntOr(config, "missing", 10); got != 10 {
+		t.Errorf("Unexpected default int: %d", got)
+	}
+	if got := ConfigFloatOr(config, "factor", 0.85); got != 2 {
+		t.Errorf("Unexpected float: %v", got)
+	}
+	if _, ok := ConfigInt(config, "name"); ok {
+		t.Error("Expected no int for a string")
+	}
+}
@@ -0,0 +1,24 @@
+package internal
+
+// Find returns the root of the set of id in parent, where each ID starts as
+// its own parent, compressing the path to it.
+func Find(parent map[string]string, id string) string {
+	for parent[id] != id {
+		parent[id] = parent[parent[id]]
+		id = parent[id]
+	}
+	return id
+}
+
+// Union joins the sets of a and b under the smaller root by compare, so roots
+// are the smallest IDs of their sets.
+func Union(parent map[string]string, a, b string, compare func(a, b string) int) {
+	ra, rb := Find(parent, a), Find(parent, b)
+	if ra == rb {
+		return
+	}
+	if compare(rb, ra) < 0 {
+		ra, rb = rb, ra
+	}
+	parent[rb] = ra
+}
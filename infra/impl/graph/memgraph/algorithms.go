@@ -0,0 +1,206 @@
+package memgraph
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/me2seeks/forge/infra/contract/graph"
+	"github.com/me2seeks/forge/infra/impl/graph/internal"
+	neo4jimpl "github.com/me2seeks/forge/infra/impl/graph/neo4j"
+)
+
+// ShortestPath finds the shortest path between two nodes with Memgraph's
+// breadth-first expansion, or the path of lowest total weight if config sets
+// "relationshipWeightProperty", or all the shortest paths if config["all"] is
+// true. config may also set "relationshipTypes", "direction" (OUTGOING,
+// INCOMING or BOTH, the default) and "maxDepth".
+func (c *memgraphClient) ShortestPath(ctx context.Context, sourceNodeID, targetNodeID string, config map[string]any) ([]*graph.Path, error) {
+	names := internal.ConfigStrings(config, "relationshipTypes")
+	if weight := internal.ConfigString(config, "relationshipWeightProperty"); weight != "" {
+		names = append(names, weight)
+	}
+	if err := graph.ValidateNames(names...); err != nil {
+		return nil, err
+	}
+	cypher, params := buildShortestPathCypher(sourceNodeID, targetNodeID, config)
+	res, err := c.RawQuery(ctx, cypher, params)
+	if err != nil {
+		return nil, err
+	}
+	var paths []*graph.Path
+	for _, record := range res.Records {
+		if path, ok := record["p"].(*graph.Path); ok {
+			paths = append(paths, path)
+		}
+	}
+	return paths, nil
+}
+
+func buildShortestPathCypher(sourceNodeID, targetNodeID string, config map[string]any) (string, map[string]any) {
+	weight := internal.ConfigString(config, "relationshipWeightProperty")
+	cost := "1"
+	if weight != "" {
+		cost = "r." + neo4jimpl.QuoteName(weight)
+	}
+	maxDepth, hasMaxDepth := internal.ConfigInt(config, "maxDepth")
+
+	var expansion string
+	if all, _ := config["all"].(bool); all || weight != "" {
+		expansion = "*WSHORTEST"
+		if all {
+			expansion = "*ALLSHORTEST"
+		}
+		if hasMaxDepth {
+			expansion += fmt.Sprintf(" %d", maxDepth)
+		}
+		expansion += " (r, n | " + cost + ") total_weight"
+	} else {
+		expansion = "*BFS"
+		if hasMaxDepth {
+			expansion += fmt.Sprintf(" ..%d", maxDepth)
+		}
+	}
+
+	rel := "[" + expansion + "]"
+	if types := internal.ConfigStrings(config, "relationshipTypes"); len(types) > 0 {
+		quoted := make([]string, len(types))
+		for i, typ := range types {
+			quoted[i] = neo4jimpl.QuoteName(typ)
+		}
+		rel = "[:" + strings.Join(quoted, "|") + " " + expansion + "]"
+	}
+	pattern := "-" + rel + "-"
+	switch strings.ToUpper(internal.ConfigString(config, "direction")) {
+	case "OUTGOING":
+		pattern = "-" + rel + "->"
+	case "INCOMING":
+		pattern = "<-" + rel + "-"
+	}
+
+	cypher := "MATCH (s), (t) WHERE elementId(s) = $source AND elementId(t) = $target " +
+		"MATCH p = (s)" + pattern + "(t) RETURN p"
+	return cypher, map[string]any{"source": sourceNodeID, "target": targetNodeID}
+}
+
+// PageRank runs MAGE pagerank.get and returns the score of each node by ID.
+// config may set "maxIterations", "dampingFactor" and "tolerance", and narrow
+// the graph with "nodeLabels" and "relationshipTypes", in which case only the
+// nodes linked by the relationships kept are scored.
+func (c *memgraphClient) PageRank(ctx context.Context, config map[string]any) (map[string]float64, error) {
+	params := map[string]any{
+		"max_iterations": internal.ConfigIntOr(config, "maxIterations", 100),
+		"damping_factor": internal.ConfigFloatOr(config, "dampingFactor", 0.85),
+		"stop_epsilon":   internal.ConfigFloatOr(config, "tolerance", 1e-5),
+	}
+	values, err := c.nodeValues(ctx, config, "pagerank.get", "$max_iterations, $damping_factor, $stop_epsilon", "rank", params)
+	if err != nil {
+		return nil, err
+	}
+	return toFloats(values), nil
+}
+
+// ConnectedComponents runs MAGE weakly_connected_components.get and returns
+// the component ID of each node by ID. config may narrow the graph, see
+// PageRank.
+func (c *memgraphClient) ConnectedComponents(ctx context.Context, config map[string]any) (map[string]string, error) {
+	values, err := c.nodeValues(ctx, config, "weakly_connected_components.get", "", "component_id", nil)
+	if err != nil {
+		return nil, err
+	}
+	components := make(map[string]string, len(values))
+	for id, v := range values {
+		components[id] = fmt.Sprint(v)
+	}
+	return components, nil
+}
+
+// BetweennessCentrality runs MAGE betweenness_centrality.get and returns the
+// score of each node by ID. Relationships are followed in their direction
+// unless config["orientation"] is UNDIRECTED, scores are normalized if
+// config["normalized"] is true, and the computation runs on
+// config["concurrency"] threads, 4 by default. config may also narrow the
+// graph, see PageRank.
+func (c *memgraphClient) BetweennessCentrality(ctx context.Context, config map[string]any) (map[string]float64, error) {
+	normalized, _ := config["normalized"].(bool)
+	params := map[string]any{
+		"directed":   !strings.EqualFold(internal.ConfigString(config, "orientation"), "UNDIRECTED"),
+		"normalized": normalized,
+		"threads":    internal.ConfigIntOr(config, "concurrency", 4),
+	}
+	values, err := c.nodeValues(ctx, config, "betweenness_centrality.get", "$directed, $normalized, $threads", "betweenness_centrality", params)
+	if err != nil {
+		return nil, err
+	}
+	return toFloats(values), nil
+}
+
+// nodeValues calls a MAGE procedure with args and returns the value it yields
+// as field for each node, by node ID.
+func (c *memgraphClient) nodeValues(ctx context.Context, config map[string]any, procedure, args, field string, params map[string]any) (map[string]any, error) {
+	cypher := buildProcedureCypher(config, procedure, args, field)
+	if params == nil {
+		params = make(map[string]any)
+	}
+	params["labels"] = internal.ConfigStrings(config, "nodeLabels")
+	params["types"] = internal.ConfigStrings(config, "relationshipTypes")
+
+	res, err := c.RawQuery(ctx, cypher, params)
+	if err != nil {
+		return nil, err
+	}
+	values := make(map[string]any, len(res.Records))
+	for _, record := range res.Records {
+		if node, ok := record["node"].(*graph.Node); ok {
+			values[node.ID] = record["value"]
+		}
+	}
+	return values, nil
+}
+
+// buildProcedureCypher calls procedure on the whole graph, or on the subgraph
+// selected by config:
+//
+//   - "nodeLabels" keeps the relationships between nodes having any of them,
+//   - "relationshipTypes" keeps the relationships having any of them.
+//
+// As the subgraph is projected from relationships, its nodes without any are
+// left out.
+func buildProcedureCypher(config map[string]any, procedure, args, field string) string {
+	var sb strings.Builder
+	callArgs := args
+	labels, types := internal.ConfigStrings(config, "nodeLabels"), internal.ConfigStrings(config, "relationshipTypes")
+	if len(labels) > 0 || len(types) > 0 {
+		var conditions []string
+		if len(labels) > 0 {
+			conditions = append(conditions,
+				"any(l IN labels(a) WHERE l IN $labels)",
+				"any(l IN labels(b) WHERE l IN $labels)")
+		}
+		if len(types) > 0 {
+			conditions = append(conditions, "type(r) IN $types")
+		}
+		sb.WriteString("MATCH p = (a)-[r]->(b) WHERE " + strings.Join(conditions, " AND ") + " ")
+		sb.WriteString("WITH project(p) AS subgraph ")
+		callArgs = "subgraph"
+		if args != "" {
+			callArgs += ", " + args
+		}
+	}
+	sb.WriteString("CALL " + procedure + "(" + callArgs + ") YIELD node, " + field + " ")
+	sb.WriteString("RETURN node, " + field + " AS value")
+	return sb.String()
+}
+
+func toFloats(values map[string]any) map[string]float64 {
+	out := make(map[string]float64, len(values))
+	for id, v := range values {
+		switch f := v.(type) {
+		case float64:
+			out[id] = f
+		case int64:
+			out[id] = float64(f)
+		}
+	}
+	return out
+}
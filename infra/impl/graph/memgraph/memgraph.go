@@ -0,0 +1,129 @@
+// Package memgraph implements the graph contract on Memgraph over the Bolt
+// protocol. Data operations and queries are shared with the neo4j client, as
+// Memgraph speaks the same Cypher, while schema operations use Memgraph's
+// syntax and graph algorithms run on the MAGE library.
+package memgraph
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/me2seeks/forge/infra/contract/graph"
+	neo4jimpl "github.com/me2seeks/forge/infra/impl/graph/neo4j"
+	"github.com/neo4j/neo4j-go-driver/v5/neo4j"
+	"github.com/neo4j/neo4j-go-driver/v5/neo4j/config"
+)
+
+type memgraphClient struct {
+	graph.Client
+	driver neo4j.DriverWithContext
+	// database is the default database, the server's default if empty.
+	database string
+}
+
+// Option is a function that configures the memgraph client
+type Option func(*options)
+
+// options contains the configuration for the memgraph client
+type options struct {
+	auth        neo4j.AuthToken
+	configurers []func(*config.Config)
+	database    string
+}
+
+// WithBasicAuth sets basic authentication for the client
+func WithBasicAuth(username, password string) Option {
+	return func(o *options) {
+		o.auth = neo4j.BasicAuth(username, password, "")
+	}
+}
+
+// WithAuth sets the authentication token for the client
+func WithAuth(auth neo4j.AuthToken) Option {
+	return func(o *options) {
+		o.auth = auth
+	}
+}
+
+// WithConfigurers adds configurers for the Bolt driver
+func WithConfigurers(configurers ...func(*config.Config)) Option {
+	return func(o *options) {
+		o.configurers = configurers
+	}
+}
+
+// WithDatabase sets the database the client uses unless a call selects
+// another one with graph.WithDatabase, on Memgraph Enterprise with
+// multi-tenancy. Defaults to the server's default database.
+func WithDatabase(database string) Option {
+	return func(o *options) {
+		o.database = database
+	}
+}
+
+// New creates a memgraph client connected to uri, e.g. bolt://localhost:7687.
+func New(ctx context.Context, uri string, opts ...Option) (graph.Client, error) {
+	o := &options{
+		auth: neo4j.NoAuth(),
+	}
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	driver, err := neo4j.NewDriverWithContext(uri, o.auth, o.configurers...)
+	if err != nil {
+		return nil, err
+	}
+	if err := driver.VerifyConnectivity(ctx); err != nil {
+		return nil, err
+	}
+
+	return &memgraphClient{
+		Client:   neo4jimpl.NewWithDriver(driver, neo4jimpl.WithDatabase(o.database)),
+		driver:   driver,
+		database: o.database,
+	}, nil
+}
+
+// run runs cypher in an auto-commit transaction, which Memgraph requires for
+// schema changes, and calls fn on each record.
+func (c *memgraphClient) run(ctx context.Context, cypher string, params map[string]any, fn func(record *neo4j.Record) error) error {
+	database := c.database
+	if db := graph.DatabaseFromContext(ctx); db != "" {
+		database = db
+	}
+	session := c.driver.NewSession(ctx, neo4j.SessionConfig{DatabaseName: database})
+	defer session.Close(ctx)
+
+	res, err := session.Run(ctx, cypher, params)
+	if err != nil {
+		return err
+	}
+	for res.Next(ctx) {
+		if fn == nil {
+			continue
+		}
+		if err := fn(res.Record()); err != nil {
+			return err
+		}
+	}
+	return res.Err()
+}
+
+// unsupported returns the error of an operation Memgraph has no equivalent for.
+func unsupported(op string) error {
+	return fmt.Errorf("memgraph: %s: %w", op, errors.ErrUnsupported)
+}
+
+func (c *memgraphClient) CreateFullTextIndex(ctx context.Context, name string, labels, properties []string) error {
+	return unsupported("full-text index")
+}
+
+func (c *memgraphClient) DropFullTextIndex(ctx context.Context, name string) error {
+	return unsupported("full-text index")
+}
+
+func (c *memgraphClient) FullTextSearch(ctx context.Context, indexName, query string, limit int) ([]*graph.ScoredNode, error) {
+	return nil, unsupported("full-text search")
+}
@@ -0,0 +1,187 @@
+package memgraph
+
+import (
+	"context"
+	"errors"
+	"os"
+	"reflect"
+	"testing"
+
+	"github.com/me2seeks/forge/infra/contract/graph"
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	testURI      = os.Getenv("MEMGRAPH_URI")
+	testUsername = os.Getenv("MEMGRAPH_USERNAME")
+	testPassword = os.Getenv("MEMGRAPH_PASSWORD")
+)
+
+func setup(t *testing.T) (graph.Client, func()) {
+	if testURI == "" {
+		t.Skip("MEMGRAPH_URI is not set")
+	}
+	ctx := context.Background()
+	client, err := New(ctx, testURI, WithBasicAuth(testUsername, testPassword))
+	require.NoError(t, err)
+
+	teardown := func() {
+		err := client.(*memgraphClient).run(ctx, "MATCH (n) DETACH DELETE n", nil, nil)
+		require.NoError(t, err)
+	}
+	return client, teardown
+}
+
+// TestBuildIndexCypher tests that an index is created per property.
+func TestBuildIndexCypher(t *testing.T) {
+	got := buildIndexCypher("CREATE INDEX ON", "Person", []string{"name", "email"})
+	want := []string{"CREATE INDEX ON :`Person`(`name`)", "CREATE INDEX ON :`Person`(`email`)"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Unexpected statements.\nGot:  %v\nWant: %v", got, want)
+	}
+
+	got = buildIndexCypher("DROP EDGE INDEX ON", "KNOWS", nil)
+	if want := []string{"DROP EDGE INDEX ON :`KNOWS`"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Unexpected statements.\nGot:  %v\nWant: %v", got, want)
+	}
+}
+
+func TestBuildConstraintCypher(t *testing.T) {
+	cypher, err := buildConstraintCypher("CREATE", "Person", "email", graph.ConstraintUnique)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if want := "CREATE CONSTRAINT ON (n:`Person`) ASSERT n.`email` IS UNIQUE"; cypher != want {
+		t.Errorf("Cypher mismatch.\nGot:  %s\nWant: %s", cypher, want)
+	}
+
+	cypher, _ = buildConstraintCypher("DROP", "Person", "name", graph.ConstraintExists)
+	if want := "DROP CONSTRAINT ON (n:`Person`) ASSERT EXISTS (n.`name`)"; cypher != want {
+		t.Errorf("Cypher mismatch.\nGot:  %s\nWant: %s", cypher, want)
+	}
+
+	if _, err := buildConstraintCypher("CREATE", "Person", "id", "NODE_KEY"); err == nil {
+		t.Error("Expected an error for an unsupported constraint type")
+	}
+}
+
+// TestBuildShortestPathCypher tests the expansion chosen from the config.
+func TestBuildShortestPathCypher(t *testing.T) {
+	cases := []struct {
+		config map[string]any
+		want   string
+	}{
+		{nil, "MATCH p = (s)-[*BFS]-(t) RETURN p"},
+		{
+			map[string]any{"relationshipTypes": []any{"ROAD"}, "direction": "outgoing", "maxDepth": 5},
+			"MATCH p = (s)-[:`ROAD` *BFS ..5]->(t) RETURN p",
+		},
+		{
+			map[string]any{"relationshipWeightProperty": "km"},
+			"MATCH p = (s)-[*WSHORTEST (r, n | r.`km`) total_weight]-(t) RETURN p",
+		},
+		{
+			map[string]any{"all": true, "maxDepth": 3},
+			"MATCH p = (s)-[*ALLSHORTEST 3 (r, n | 1) total_weight]-(t) RETURN p",
+		},
+	}
+	for _, c := range cases {
+		cypher, params := buildShortestPathCypher("1", "2", c.config)
+		want := "MATCH (s), (t) WHERE elementId(s) = $source AND elementId(t) = $target " + c.want
+		if cypher != want {
+			t.Errorf("Cypher mismatch.\nGot:  %s\nWant: %s", cypher, want)
+		}
+		if !reflect.DeepEqual(params, map[string]any{"source": "1", "target": "2"}) {
+			t.Errorf("Unexpected params: %v", params)
+		}
+	}
+}
+
+// TestBuildProcedureCypher tests that a procedure runs on the subgraph selected by the config.
+func TestBuildProcedureCypher(t *testing.T) {
+	cypher := buildProcedureCypher(nil, "weakly_connected_components.get", "", "component_id")
+	want := "CALL weakly_connected_components.get() YIELD node, component_id RETURN node, component_id AS value"
+	if cypher != want {
+		t.Errorf("Cypher mismatch.\nGot:  %s\nWant: %s", cypher, want)
+	}
+
+	config := map[string]any{"relationshipTypes": []string{"LINKS"}}
+	cypher = buildProcedureCypher(config, "pagerank.get", "$max_iterations", "rank")
+	want = "MATCH p = (a)-[r]->(b) WHERE type(r) IN $types WITH project(p) AS subgraph " +
+		"CALL pagerank.get(subgraph, $max_iterations) YIELD node, rank RETURN node, rank AS value"
+	if cypher != want {
+		t.Errorf("Cypher mismatch.\nGot:  %s\nWant: %s", cypher, want)
+	}
+}
+
+func TestToIndexInfo(t *testing.T) {
+	got := toIndexInfo(map[string]any{"index type": "label+property", "label": "Person", "property": "name", "count": int64(3)})
+	want := &graph.IndexInfo{
+		Name:          "Person(name)",
+		Type:          "LABEL+PROPERTY",
+		EntityType:    graph.EntityNode,
+		LabelsOrTypes: []string{"Person"},
+		Properties:    []string{"name"},
+		State:         "ONLINE",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Unexpected index.\nGot:  %+v\nWant: %+v", got, want)
+	}
+
+	if got := toIndexInfo(map[string]any{"index type": "edge-type", "label": "KNOWS"}); got.EntityType != graph.EntityRelationship {
+		t.Errorf("Unexpected entity type: %s", got.EntityType)
+	}
+}
+
+func TestToConstraintInfo(t *testing.T) {
+	got := toConstraintInfo(map[string]any{"constraint type": "unique", "label": "Person", "properties": []any{"email"}})
+	if got.Type != graph.ConstraintUnique || got.Name != "Person(email)" || !reflect.DeepEqual(got.Properties, []string{"email"}) {
+		t.Errorf("Unexpected constraint: %+v", got)
+	}
+}
+
+func TestMemgraph(t *testing.T) {
+	client, teardown := setup(t)
+	defer teardown()
+
+	ctx := context.Background()
+
+	a, err := client.CreateNode(ctx, &graph.Node{Labels: []string{"City"}, Properties: graph.Properties{"name": "A"}})
+	require.NoError(t, err)
+	b, err := client.CreateNode(ctx, &graph.Node{Labels: []string{"City"}, Properties: graph.Properties{"name": "B"}})
+	require.NoError(t, err)
+	c, err := client.CreateNode(ctx, &graph.Node{Labels: []string{"City"}, Properties: graph.Properties{"name": "C"}})
+	require.NoError(t, err)
+	for _, e := range []*graph.Edge{
+		{Label: "ROAD", SourceNodeID: a.ID, TargetNodeID: b.ID, Properties: graph.Properties{"km": 10}},
+		{Label: "ROAD", SourceNodeID: b.ID, TargetNodeID: c.ID, Properties: graph.Properties{"km": 10}},
+		{Label: "ROAD", SourceNodeID: a.ID, TargetNodeID: c.ID, Properties: graph.Properties{"km": 50}},
+	} {
+		_, err := client.CreateEdge(ctx, e)
+		require.NoError(t, err)
+	}
+
+	paths, err := client.ShortestPath(ctx, a.ID, c.ID, nil)
+	require.NoError(t, err)
+	require.Len(t, paths, 1)
+	require.Len(t, paths[0].Edges, 1)
+
+	paths, err = client.ShortestPath(ctx, a.ID, c.ID, map[string]any{"relationshipWeightProperty": "km", "direction": "OUTGOING"})
+	require.NoError(t, err)
+	require.Len(t, paths, 1)
+	require.Len(t, paths[0].Edges, 2)
+
+	components, err := client.ConnectedComponents(ctx, nil)
+	require.NoError(t, err)
+	require.Len(t, components, 3)
+	require.Equal(t, components[a.ID], components[c.ID])
+
+	require.NoError(t, client.CreateNodeIndex(ctx, "City", []string{"name"}))
+	indexes, err := client.ListIndexes(ctx)
+	require.NoError(t, err)
+	require.NotEmpty(t, indexes)
+	require.NoError(t, client.DropNodeIndex(ctx, "City", []string{"name"}))
+
+	err = client.CreateFullTextIndex(ctx, "cities", []string{"City"}, []string{"name"})
+	require.True(t, errors.Is(err, errors.ErrUnsupported))
+}
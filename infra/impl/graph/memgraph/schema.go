@@ -0,0 +1,198 @@
+package memgraph
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/me2seeks/forge/infra/contract/graph"
+	neo4jimpl "github.com/me2seeks/forge/infra/impl/graph/neo4j"
+	"github.com/neo4j/neo4j-go-driver/v5/neo4j"
+)
+
+// CreateNodeIndex creates a label-property index per property, or a label
+// index if there are none.
+func (c *memgraphClient) CreateNodeIndex(ctx context.Context, label string, properties []string) error {
+	return c.runIndex(ctx, "CREATE INDEX ON", label, properties)
+}
+
+// CreateEdgeIndex creates an edge-type-property index per property, or an
+// edge-type index if there are none.
+func (c *memgraphClient) CreateEdgeIndex(ctx context.Context, label string, properties []string) error {
+	return c.runIndex(ctx, "CREATE EDGE INDEX ON", label, properties)
+}
+
+func (c *memgraphClient) DropNodeIndex(ctx context.Context, label string, properties []string) error {
+	return c.runIndex(ctx, "DROP INDEX ON", label, properties)
+}
+
+func (c *memgraphClient) DropEdgeIndex(ctx context.Context, label string, properties []string) error {
+	return c.runIndex(ctx, "DROP EDGE INDEX ON", label, properties)
+}
+
+func buildIndexCypher(prefix, label string, properties []string) []string {
+	if len(properties) == 0 {
+		return []string{prefix + " :" + neo4jimpl.QuoteName(label)}
+	}
+	statements := make([]string, len(properties))
+	for i, property := range properties {
+		statements[i] = prefix + " :" + neo4jimpl.QuoteName(label) + "(" + neo4jimpl.QuoteName(property) + ")"
+	}
+	return statements
+}
+
+// runIndex runs the statements of buildIndexCypher on valid names.
+func (c *memgraphClient) runIndex(ctx context.Context, prefix, label string, properties []string) error {
+	if err := graph.ValidateNames(append([]string{label}, properties...)...); err != nil {
+		return err
+	}
+	for _, cypher := range buildIndexCypher(prefix, label, properties) {
+		if err := c.run(ctx, cypher, nil, nil); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (c *memgraphClient) CreateConstraint(ctx context.Context, label, property string, constraintType graph.ConstraintType) error {
+	cypher, err := buildConstraintCypher("CREATE", label, property, constraintType)
+	if err != nil {
+		return err
+	}
+	return c.run(ctx, cypher, nil, nil)
+}
+
+func (c *memgraphClient) DropConstraint(ctx context.Context, label, property string, constraintType graph.ConstraintType) error {
+	cypher, err := buildConstraintCypher("DROP", label, property, constraintType)
+	if err != nil {
+		return err
+	}
+	return c.run(ctx, cypher, nil, nil)
+}
+
+func buildConstraintCypher(verb, label, property string, constraintType graph.ConstraintType) (string, error) {
+	if err := graph.ValidateNames(label, property); err != nil {
+		return "", err
+	}
+	cypher := verb + " CONSTRAINT ON (n:" + neo4jimpl.QuoteName(label) + ") ASSERT "
+	switch constraintType {
+	case graph.ConstraintUnique:
+		return cypher + "n." + neo4jimpl.QuoteName(property) + " IS UNIQUE", nil
+	case graph.ConstraintExists:
+		return cypher + "EXISTS (n." + neo4jimpl.QuoteName(property) + ")", nil
+	default:
+		return "", fmt.Errorf("memgraph: unsupported constraint type %s", constraintType)
+	}
+}
+
+// ListLabels returns the labels of the stored nodes. Memgraph has no catalog
+// of labels, so all nodes are scanned.
+func (c *memgraphClient) ListLabels(ctx context.Context) ([]string, error) {
+	return c.listStrings(ctx, "MATCH (n) UNWIND labels(n) AS value RETURN DISTINCT value ORDER BY value")
+}
+
+// ListRelationshipTypes returns the types of the stored relationships, see
+// ListLabels.
+func (c *memgraphClient) ListRelationshipTypes(ctx context.Context) ([]string, error) {
+	return c.listStrings(ctx, "MATCH ()-[r]->() RETURN DISTINCT type(r) AS value ORDER BY value")
+}
+
+func (c *memgraphClient) listStrings(ctx context.Context, cypher string) ([]string, error) {
+	var values []string
+	err := c.run(ctx, cypher, nil, func(record *neo4j.Record) error {
+		value, _ := record.Get("value")
+		values = append(values, value.(string))
+		return nil
+	})
+	return values, err
+}
+
+// ListIndexes returns the indexes of SHOW INDEX INFO, one per label or edge
+// type and property. Memgraph doesn't name indexes, so they are named after
+// what they index, e.g. "Person(name)", and are always online.
+func (c *memgraphClient) ListIndexes(ctx context.Context) ([]*graph.IndexInfo, error) {
+	var indexes []*graph.IndexInfo
+	err := c.run(ctx, "SHOW INDEX INFO", nil, func(record *neo4j.Record) error {
+		indexes = append(indexes, toIndexInfo(record.AsMap()))
+		return nil
+	})
+	return indexes, err
+}
+
+// toIndexInfo converts a row of SHOW INDEX INFO, e.g. {"index type":
+// "label+property", "label": "Person", "property": "name"}.
+func toIndexInfo(row map[string]any) *graph.IndexInfo {
+	typ, _ := row["index type"].(string)
+	label, _ := row["label"].(string)
+	properties := toStrings(row["property"])
+
+	entityType := graph.EntityNode
+	if strings.HasPrefix(typ, "edge-type") {
+		entityType = graph.EntityRelationship
+	}
+	name := label
+	if len(properties) > 0 {
+		name += "(" + strings.Join(properties, ", ") + ")"
+	}
+	return &graph.IndexInfo{
+		Name:          name,
+		Type:          strings.ToUpper(typ),
+		EntityType:    entityType,
+		LabelsOrTypes: []string{label},
+		Properties:    properties,
+		State:         "ONLINE",
+	}
+}
+
+// ListConstraints returns the constraints of SHOW CONSTRAINT INFO, named
+// after what they constrain like indexes.
+func (c *memgraphClient) ListConstraints(ctx context.Context) ([]*graph.ConstraintInfo, error) {
+	var constraints []*graph.ConstraintInfo
+	err := c.run(ctx, "SHOW CONSTRAINT INFO", nil, func(record *neo4j.Record) error {
+		constraints = append(constraints, toConstraintInfo(record.AsMap()))
+		return nil
+	})
+	return constraints, err
+}
+
+// toConstraintInfo converts a row of SHOW CONSTRAINT INFO, e.g. {"constraint
+// type": "unique", "label": "Person", "properties": ["email"]}.
+func toConstraintInfo(row map[string]any) *graph.ConstraintInfo {
+	typ, _ := row["constraint type"].(string)
+	label, _ := row["label"].(string)
+	properties := toStrings(row["properties"])
+
+	constraintType := graph.ConstraintType(strings.ToUpper(typ))
+	switch typ {
+	case "unique":
+		constraintType = graph.ConstraintUnique
+	case "exists":
+		constraintType = graph.ConstraintExists
+	}
+	return &graph.ConstraintInfo{
+		Name:          label + "(" + strings.Join(properties, ", ") + ")",
+		Type:          constraintType,
+		EntityType:    graph.EntityNode,
+		LabelsOrTypes: []string{label},
+		Properties:    properties,
+	}
+}
+
+// toStrings returns v as a list of strings, whether it is a single string or
+// a list of them.
+func toStrings(v any) []string {
+	switch v := v.(type) {
+	case string:
+		return []string{v}
+	case []any:
+		values := make([]string, 0, len(v))
+		for _, item := range v {
+			if s, ok := item.(string); ok {
+				values = append(values, s)
+			}
+		}
+		return values
+	default:
+		return nil
+	}
+}
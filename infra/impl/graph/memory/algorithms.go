@@ -0,0 +1,332 @@
+package memory
+
+import (
+	"container/heap"
+	"context"
+	"math"
+	"slices"
+	"strings"
+
+	"github.com/me2seeks/forge/infra/contract/graph"
+	"github.com/me2seeks/forge/infra/impl/graph/internal"
+)
+
+// ShortestPath finds the shortest path between two nodes with Dijkstra's
+// algorithm, edges weighing their config["relationshipWeightProperty"], or 1
+// if unset or not a number, or all the shortest paths, up to config["limit"]
+// (100 by default), if config["all"] is true. config may also set
+// "relationshipTypes", "direction" (OUTGOING, INCOMING or BOTH, the default)
+// and "maxDepth", which drops the longer paths.
+func (c *memoryClient) ShortestPath(ctx context.Context, sourceNodeID, targetNodeID string, config map[string]any) ([]*graph.Path, error) {
+	var paths []*graph.Path
+	err := c.exec.read(ctx, func(s *store) error {
+		paths = s.shortestPaths(sourceNodeID, targetNodeID, config)
+		return nil
+	})
+	return paths, err
+}
+
+func (s *store) shortestPaths(sourceNodeID, targetNodeID string, config map[string]any) []*graph.Path {
+	source, target := s.nodes[sourceNodeID], s.nodes[targetNodeID]
+	if source == nil || target == nil {
+		return nil
+	}
+	direction := graph.DirectionBoth
+	switch strings.ToUpper(internal.ConfigString(config, "direction")) {
+	case "OUTGOING":
+		direction = graph.DirectionOutgoing
+	case "INCOMING":
+		direction = graph.DirectionIncoming
+	}
+	types, weight := internal.ConfigStrings(config, "relationshipTypes"), internal.ConfigString(config, "relationshipWeightProperty")
+
+	// preds are the steps reaching each node on its shortest paths, back to the
+	// node they come from.
+	dist := map[string]float64{source.ID: 0}
+	preds := make(map[string][]step)
+	done := make(map[string]bool)
+	queue := &distanceQueue{{id: source.ID}}
+	for queue.Len() > 0 {
+		item := heap.Pop(queue).(queued)
+		if done[item.id] {
+			continue
+		}
+		done[item.id] = true
+		if item.id == target.ID {
+			break
+		}
+		for _, st := range s.steps(s.nodes[item.id], direction) {
+			if len(types) > 0 && !slices.Contains(types, st.edge.Label) {
+				continue
+			}
+			d := item.dist + edgeWeight(st.edge, weight)
+			back := step{edge: st.edge, node: s.nodes[item.id]}
+			switch old, ok := dist[st.node.ID]; {
+			case !ok || d < old:
+				dist[st.node.ID], preds[st.node.ID] = d, []step{back}
+				heap.Push(queue, queued{id: st.node.ID, dist: d})
+			case d == old:
+				preds[st.node.ID] = append(preds[st.node.ID], back)
+			}
+		}
+	}
+	if _, ok := dist[target.ID]; !ok {
+		return nil
+	}
+
+	limit := 1
+	if all, _ := config["all"].(bool); all {
+		limit = internal.ConfigIntOr(config, "limit", 100)
+	}
+	maxDepth, bounded := internal.ConfigInt(config, "maxDepth")
+	var paths []*graph.Path
+	// Walk the predecessors back from the target, collecting reversed paths.
+	var walkBack func(nodes []*graph.Node, edges []*graph.Edge)
+	walkBack = func(nodes []*graph.Node, edges []*graph.Edge) {
+		if len(paths) >= limit || (bounded && len(edges) > maxDepth) {
+			return
+		}
+		last := nodes[len(nodes)-1]
+		if last.ID == source.ID {
+			path := &graph.Path{Nodes: slices.Clone(nodes), Edges: slices.Clone(edges)}
+			slices.Reverse(path.Nodes)
+			slices.Reverse(path.Edges)
+			paths = append(paths, clonePath(path))
+			return
+		}
+		for _, back := range preds[last.ID] {
+			// Zero weights may make predecessors loop.
+			if !slices.Contains(nodes, back.node) {
+				walkBack(append(nodes, back.node), append(edges, back.edge))
+			}
+		}
+	}
+	walkBack([]*graph.Node{target}, nil)
+	return paths
+}
+
+func edgeWeight(edge *graph.Edge, property string) float64 {
+	if property == "" {
+		return 1
+	}
+	switch v := normalize(edge.Properties[property]).(type) {
+	case int64:
+		return float64(v)
+	case float64:
+		return v
+	default:
+		return 1
+	}
+}
+
+type queued struct {
+	id   string
+	dist float64
+}
+
+// distanceQueue is a min-heap of nodes by distance.
+type distanceQueue []queued
+
+func (q distanceQueue) Len() int           { return len(q) }
+func (q distanceQueue) Less(i, j int) bool { return q[i].dist < q[j].dist }
+func (q distanceQueue) Swap(i, j int)      { q[i], q[j] = q[j], q[i] }
+func (q *distanceQueue) Push(x any)        { *q = append(*q, x.(queued)) }
+
+func (q *distanceQueue) Pop() any {
+	old := *q
+	item := old[len(old)-1]
+	*q = old[:len(old)-1]
+	return item
+}
+
+// subgraph is the graph the algorithms run on.
+type subgraph struct {
+	nodes []string
+	index map[string]int
+	// out and in are the neighbors of each node by index, following and
+	// against the direction of the edges.
+	out, in [][]int
+}
+
+// subgraph returns the nodes and edges selected by config: "nodeLabels" keeps
+// the nodes having any of them and the edges between them, and
+// "relationshipTypes" the edges having any of them.
+func (s *store) subgraph(config map[string]any) *subgraph {
+	labels, types := internal.ConfigStrings(config, "nodeLabels"), internal.ConfigStrings(config, "relationshipTypes")
+	g := &subgraph{index: make(map[string]int)}
+	for _, id := range s.nodeIDs() {
+		node := s.nodes[id]
+		if len(labels) > 0 && !slices.ContainsFunc(labels, func(label string) bool { return slices.Contains(node.Labels, label) }) {
+			continue
+		}
+		g.index[id] = len(g.nodes)
+		g.nodes = append(g.nodes, id)
+	}
+	g.out, g.in = make([][]int, len(g.nodes)), make([][]int, len(g.nodes))
+	for _, id := range s.edgeIDs() {
+		edge := s.edges[id]
+		if len(types) > 0 && !slices.Contains(types, edge.Label) {
+			continue
+		}
+		from, okFrom := g.index[edge.SourceNodeID]
+		to, okTo := g.index[edge.TargetNodeID]
+		if okFrom && okTo {
+			g.out[from] = append(g.out[from], to)
+			g.in[to] = append(g.in[to], from)
+		}
+	}
+	return g
+}
+
+// load returns the subgraph selected by config, see store.subgraph.
+func (c *memoryClient) load(ctx context.Context, config map[string]any) (*subgraph, error) {
+	var g *subgraph
+	err := c.exec.read(ctx, func(s *store) error {
+		g = s.subgraph(config)
+		return nil
+	})
+	return g, err
+}
+
+// PageRank computes the PageRank of the nodes by power iteration, like GDS:
+// scores start at 1 - "dampingFactor" (0.85 by default) and are iterated up
+// to "maxIterations" (20 by default) times, or until no score changes by more
+// than "tolerance" (1e-7 by default). config may also narrow the graph, see
+// store.subgraph.
+func (c *memoryClient) PageRank(ctx context.Context, config map[string]any) (map[string]float64, error) {
+	g, err := c.load(ctx, config)
+	if err != nil {
+		return nil, err
+	}
+	damping := internal.ConfigFloatOr(config, "dampingFactor", 0.85)
+	maxIterations := internal.ConfigIntOr(config, "maxIterations", 20)
+	tolerance := internal.ConfigFloatOr(config, "tolerance", 1e-7)
+
+	scores := make([]float64, len(g.nodes))
+	for i := range scores {
+		scores[i] = 1 - damping
+	}
+	for iter := 0; iter < maxIterations; iter++ {
+		next := make([]float64, len(scores))
+		for i := range next {
+			next[i] = 1 - damping
+		}
+		for i, targets := range g.out {
+			for _, j := range targets {
+				next[j] += damping * scores[i] / float64(len(targets))
+			}
+		}
+		delta := 0.0
+		for i := range next {
+			delta = math.Max(delta, math.Abs(next[i]-scores[i]))
+		}
+		scores = next
+		if delta < tolerance {
+			break
+		}
+	}
+
+	out := make(map[string]float64, len(g.nodes))
+	for i, id := range g.nodes {
+		out[id] = scores[i]
+	}
+	return out, nil
+}
+
+// ConnectedComponents computes the weakly connected components of the nodes.
+// Component IDs are the smallest node ID of each component. config may narrow
+// the graph, see store.subgraph.
+func (c *memoryClient) ConnectedComponents(ctx context.Context, config map[string]any) (map[string]string, error) {
+	g, err := c.load(ctx, config)
+	if err != nil {
+		return nil, err
+	}
+	parent := make(map[string]string, len(g.nodes))
+	for _, id := range g.nodes {
+		parent[id] = id
+	}
+	for i, targets := range g.out {
+		for _, j := range targets {
+			internal.Union(parent, g.nodes[i], g.nodes[j], compareIDs)
+		}
+	}
+	components := make(map[string]string, len(parent))
+	for id := range parent {
+		components[id] = internal.Find(parent, id)
+	}
+	return components, nil
+}
+
+// BetweennessCentrality computes the betweenness centrality of the nodes with
+// Brandes' algorithm. Edges are followed in their direction unless
+// config["orientation"] is UNDIRECTED, or against it if REVERSE; as with GDS,
+// scores aren't normalized. config may also narrow the graph, see
+// store.subgraph.
+func (c *memoryClient) BetweennessCentrality(ctx context.Context, config map[string]any) (map[string]float64, error) {
+	g, err := c.load(ctx, config)
+	if err != nil {
+		return nil, err
+	}
+	neighbors := g.out
+	undirected := false
+	switch strings.ToUpper(internal.ConfigString(config, "orientation")) {
+	case "REVERSE":
+		neighbors = g.in
+	case "UNDIRECTED":
+		undirected = true
+		neighbors = make([][]int, len(g.nodes))
+		for i := range neighbors {
+			neighbors[i] = append(append([]int(nil), g.out[i]...), g.in[i]...)
+		}
+	}
+
+	n := len(g.nodes)
+	scores := make([]float64, n)
+	for s := 0; s < n; s++ {
+		// Count the shortest paths from s by breadth-first search.
+		var stack []int
+		preds := make([][]int, n)
+		sigma, dist := make([]float64, n), make([]int, n)
+		for i := range dist {
+			dist[i] = -1
+		}
+		sigma[s], dist[s] = 1, 0
+		queue := []int{s}
+		for len(queue) > 0 {
+			v := queue[0]
+			queue = queue[1:]
+			stack = append(stack, v)
+			for _, w := range neighbors[v] {
+				if dist[w] < 0 {
+					dist[w] = dist[v] + 1
+					queue = append(queue, w)
+				}
+				if dist[w] == dist[v]+1 {
+					sigma[w] += sigma[v]
+					preds[w] = append(preds[w], v)
+				}
+			}
+		}
+		// Accumulate the dependencies of s in reverse order of distance.
+		delta := make([]float64, n)
+		for i := len(stack) - 1; i >= 0; i-- {
+			w := stack[i]
+			for _, v := range preds[w] {
+				delta[v] += sigma[v] / sigma[w] * (1 + delta[w])
+			}
+			if w != s {
+				scores[w] += delta[w]
+			}
+		}
+	}
+
+	out := make(map[string]float64, n)
+	for i, id := range g.nodes {
+		if undirected {
+			// Each path is counted from both of its ends.
+			scores[i] /= 2
+		}
+		out[id] = scores[i]
+	}
+	return out, nil
+}
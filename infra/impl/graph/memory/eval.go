@@ -0,0 +1,1050 @@
+package memory
+
+import (
+	"cmp"
+	"fmt"
+	"maps"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/me2seeks/forge/infra/contract/graph"
+)
+
+// row binds the aliases of a query to their values: *graph.Node, *graph.Edge,
+// []*graph.Edge for variable-length edges, *graph.Path, or the values of the
+// items projected by a stage.
+type row map[string]any
+
+func (r row) with(alias string, value any) row {
+	next := maps.Clone(r)
+	next[alias] = value
+	return next
+}
+
+// Default aliases, as in the Cypher built by the neo4j client.
+const (
+	defaultNodeAlias = "n"
+	defaultEdgeAlias = "r"
+	defaultEndAlias  = "m"
+)
+
+func orDefault(alias, def string) string {
+	if alias == "" {
+		return def
+	}
+	return alias
+}
+
+// matchAliases returns the aliases of the nodes and edges matched by query,
+// which are returned if it returns nothing else.
+func matchAliases(query *graph.Query) []string {
+	var aliases []string
+	add := func(alias string) {
+		if !slices.Contains(aliases, alias) {
+			aliases = append(aliases, alias)
+		}
+	}
+	for _, p := range query.Match {
+		add(orDefault(p.Alias, defaultNodeAlias))
+		if p.Edge != nil {
+			add(orDefault(p.Edge.Alias, defaultEdgeAlias))
+			if p.Edge.Node != nil {
+				add(orDefault(p.Edge.Node.Alias, defaultEndAlias))
+			}
+		}
+	}
+	return aliases
+}
+
+// match returns the rows binding the patterns, the aliases they share binding
+// the same entities.
+func (s *store) match(patterns []graph.Pattern) []row {
+	rows := []row{{}}
+	for i := range patterns {
+		var next []row
+		for _, r := range rows {
+			next = append(next, s.matchPattern(&patterns[i], r)...)
+		}
+		rows = next
+	}
+	return rows
+}
+
+func (s *store) matchPattern(p *graph.Pattern, r row) []row {
+	alias := orDefault(p.Alias, defaultNodeAlias)
+	var rows []row
+	for _, start := range s.candidates(r, alias, p.Labels, p.Properties) {
+		bound := r.with(alias, start)
+		if p.Edge == nil {
+			if p.PathAlias != "" {
+				bound[p.PathAlias] = &graph.Path{Nodes: []*graph.Node{start}}
+			}
+			rows = append(rows, bound)
+			continue
+		}
+
+		for _, w := range s.walks(start, p.Edge) {
+			next := bound
+			end := w.nodes[len(w.nodes)-1]
+			if node := p.Edge.Node; node != nil {
+				endAlias := orDefault(node.Alias, defaultEndAlias)
+				if !hasLabels(end, node.Labels) || !hasProperties(end.Properties, node.Properties) || !bindable(next, endAlias, end) {
+					continue
+				}
+				next = next.with(endAlias, end)
+			}
+			var edges any = w.edges
+			if !isVariableLength(p.Edge) {
+				edges = w.edges[0]
+			}
+			edgeAlias := orDefault(p.Edge.Alias, defaultEdgeAlias)
+			if !bindable(next, edgeAlias, edges) {
+				continue
+			}
+			next = next.with(edgeAlias, edges)
+			if p.PathAlias != "" {
+				next[p.PathAlias] = &graph.Path{Nodes: w.nodes, Edges: w.edges}
+			}
+			rows = append(rows, next)
+		}
+	}
+	return rows
+}
+
+// candidates returns the nodes alias can be bound to in r: the one it is bound
+// to if any, or all of them.
+func (s *store) candidates(r row, alias string, labels []string, props graph.Properties) []*graph.Node {
+	var nodes []*graph.Node
+	if bound, ok := r[alias]; ok {
+		if node, ok := bound.(*graph.Node); ok {
+			nodes = append(nodes, node)
+		}
+	} else {
+		for _, id := range s.nodeIDs() {
+			nodes = append(nodes, s.nodes[id])
+		}
+	}
+	return slices.DeleteFunc(nodes, func(node *graph.Node) bool {
+		return !hasLabels(node, labels) || !hasProperties(node.Properties, props)
+	})
+}
+
+// bindable reports whether alias is unbound in r or bound to value.
+func bindable(r row, alias string, value any) bool {
+	bound, ok := r[alias]
+	if !ok {
+		return true
+	}
+	return valueKey(bound) == valueKey(value)
+}
+
+func isVariableLength(e *graph.EdgePattern) bool {
+	return e.MinHops != nil || e.MaxHops != nil
+}
+
+// walk is a sequence of edges from the first of nodes, through the others.
+type walk struct {
+	nodes []*graph.Node
+	edges []*graph.Edge
+}
+
+// walks returns the walks from start along the edges of e, within its number
+// of hops, never following an edge twice.
+func (s *store) walks(start *graph.Node, e *graph.EdgePattern) []walk {
+	minHops, maxHops := 1, 1
+	if isVariableLength(e) {
+		maxHops = -1
+		if e.MinHops != nil {
+			minHops = *e.MinHops
+		}
+		if e.MaxHops != nil {
+			maxHops = *e.MaxHops
+		}
+	}
+
+	var walks []walk
+	var visit func(w walk)
+	visit = func(w walk) {
+		hops := len(w.edges)
+		if hops >= minHops {
+			walks = append(walks, walk{nodes: slices.Clone(w.nodes), edges: slices.Clone(w.edges)})
+		}
+		if maxHops >= 0 && hops >= maxHops {
+			return
+		}
+		for _, step := range s.steps(w.nodes[hops], e.Direction) {
+			if !edgeMatches(step.edge, e.Labels, e.Properties) || slices.Contains(w.edges, step.edge) {
+				continue
+			}
+			visit(walk{nodes: append(w.nodes, step.node), edges: append(w.edges, step.edge)})
+		}
+	}
+	visit(walk{nodes: []*graph.Node{start}})
+	return walks
+}
+
+type step struct {
+	edge *graph.Edge
+	node *graph.Node
+}
+
+// steps returns the edges of node in direction, with the nodes they lead to.
+// Self-loops are followed once in both directions.
+func (s *store) steps(node *graph.Node, direction graph.EdgeDirection) []step {
+	var steps []step
+	if direction != graph.DirectionIncoming {
+		for _, id := range s.out[node.ID] {
+			edge := s.edges[id]
+			steps = append(steps, step{edge: edge, node: s.nodes[edge.TargetNodeID]})
+		}
+	}
+	if direction == graph.DirectionIncoming || direction == graph.DirectionBoth {
+		for _, id := range s.in[node.ID] {
+			edge := s.edges[id]
+			if direction == graph.DirectionBoth && edge.SourceNodeID == edge.TargetNodeID {
+				continue
+			}
+			steps = append(steps, step{edge: edge, node: s.nodes[edge.SourceNodeID]})
+		}
+	}
+	return steps
+}
+
+// edgeMatches reports whether edge has any of labels, if any, and props.
+func edgeMatches(edge *graph.Edge, labels []string, props graph.Properties) bool {
+	return (len(labels) == 0 || slices.Contains(labels, edge.Label)) && hasProperties(edge.Properties, props)
+}
+
+// filter returns the rows satisfying where.
+func filter(rows []row, where *graph.Where) ([]row, error) {
+	if where == nil {
+		return rows, nil
+	}
+	if len(where.MustExpr) > 0 {
+		return nil, unsupported("where expressions")
+	}
+	var kept []row
+	for _, r := range rows {
+		ok, err := satisfies(r, where)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			kept = append(kept, r)
+		}
+	}
+	return kept, nil
+}
+
+// satisfies reports whether r satisfies all of Filter and Must, any of Should
+// if set, and not all of MustNot if set, like the neo4j WHERE clause.
+func satisfies(r row, where *graph.Where) (bool, error) {
+	all := func(conds []graph.Condition) (bool, error) {
+		for _, cond := range conds {
+			ok, err := test(r, cond)
+			if err != nil || !ok {
+				return false, err
+			}
+		}
+		return true, nil
+	}
+
+	for _, conds := range [][]graph.Condition{where.Filter, where.Must} {
+		if ok, err := all(conds); err != nil || !ok {
+			return false, err
+		}
+	}
+	if len(where.Should) > 0 {
+		matched := false
+		for _, cond := range where.Should {
+			ok, err := test(r, cond)
+			if err != nil {
+				return false, err
+			}
+			matched = matched || ok
+		}
+		if !matched {
+			return false, nil
+		}
+	}
+	if len(where.MustNot) > 0 {
+		ok, err := all(where.MustNot)
+		if err != nil || ok {
+			return false, err
+		}
+	}
+	return true, nil
+}
+
+// test evaluates cond on r. As in Cypher, comparisons with null are false.
+func test(r row, cond graph.Condition) (bool, error) {
+	left, err := lookup(r, cond.Alias, cond.Property)
+	if err != nil {
+		return false, err
+	}
+	right := cond.Value
+	switch cond.Operator {
+	case graph.OpNotEqual:
+		return left != nil && right != nil && !equal(left, right), nil
+	case graph.OpGreaterThan, graph.OpGreaterThanOrEqual, graph.OpLessThan, graph.OpLessThanOrEqual:
+		c, ok := compare(left, right)
+		if !ok {
+			return false, nil
+		}
+		switch cond.Operator {
+		case graph.OpGreaterThan:
+			return c > 0, nil
+		case graph.OpGreaterThanOrEqual:
+			return c >= 0, nil
+		case graph.OpLessThan:
+			return c < 0, nil
+		default:
+			return c <= 0, nil
+		}
+	case graph.OpIn:
+		list, _ := normalize(right).([]any)
+		return slices.ContainsFunc(list, func(item any) bool { return equal(left, item) }), nil
+	case graph.OpContains:
+		l, lok := left.(string)
+		r, rok := right.(string)
+		return lok && rok && strings.Contains(l, r), nil
+	default:
+		return equal(left, right), nil
+	}
+}
+
+// lookup returns the value of alias in r, or of its property if set. An item
+// projected without alias by a stage is named after its expression, e.g.
+// "n.name".
+func lookup(r row, alias, property string) (any, error) {
+	value, ok := r[alias]
+	if !ok {
+		if value, ok := r[alias+"."+property]; ok && property != "" {
+			return value, nil
+		}
+		return nil, fmt.Errorf("memory: variable %s not defined", alias)
+	}
+	if property == "" {
+		return value, nil
+	}
+	return propertyOf(value, property), nil
+}
+
+func propertyOf(value any, property string) any {
+	switch v := value.(type) {
+	case *graph.Node:
+		return v.Properties[property]
+	case *graph.Edge:
+		return v.Properties[property]
+	case map[string]any:
+		return v[property]
+	case graph.Properties:
+		return v[property]
+	default:
+		return nil
+	}
+}
+
+// equal reports whether a and b are equal in Cypher: numbers by value,
+// entities by ID, and never null.
+func equal(a, b any) bool {
+	a, b = normalize(a), normalize(b)
+	if a == nil || b == nil {
+		return false
+	}
+	if c, ok := compareNumbers(a, b); ok {
+		return c == 0
+	}
+	switch x := a.(type) {
+	case *graph.Node, *graph.Edge, *graph.Path:
+		return valueKey(a) == valueKey(b)
+	case []any:
+		y, ok := b.([]any)
+		if !ok || len(x) != len(y) {
+			return false
+		}
+		for i := range x {
+			if !equal(x[i], y[i]) {
+				return false
+			}
+		}
+		return true
+	default:
+		return reflect.DeepEqual(a, b)
+	}
+}
+
+// compare compares a and b if both are numbers, strings or booleans.
+func compare(a, b any) (int, bool) {
+	a, b = normalize(a), normalize(b)
+	if c, ok := compareNumbers(a, b); ok {
+		return c, true
+	}
+	switch x := a.(type) {
+	case string:
+		if y, ok := b.(string); ok {
+			return strings.Compare(x, y), true
+		}
+	case bool:
+		if y, ok := b.(bool); ok {
+			return cmp.Compare(boolRank(x), boolRank(y)), true
+		}
+	}
+	return 0, false
+}
+
+func compareNumbers(a, b any) (int, bool) {
+	switch x := a.(type) {
+	case int64:
+		switch y := b.(type) {
+		case int64:
+			return cmp.Compare(x, y), true
+		case float64:
+			return cmp.Compare(float64(x), y), true
+		}
+	case float64:
+		switch y := b.(type) {
+		case int64:
+			return cmp.Compare(x, float64(y)), true
+		case float64:
+			return cmp.Compare(x, y), true
+		}
+	}
+	return 0, false
+}
+
+func boolRank(b bool) int {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+// orderCompare compares a and b for ORDER BY: values of different types by
+// type, strings before booleans before numbers, and nulls last.
+func orderCompare(a, b any) int {
+	if c, ok := compare(a, b); ok {
+		return c
+	}
+	return cmp.Compare(typeRank(a), typeRank(b))
+}
+
+func typeRank(v any) int {
+	switch normalize(v).(type) {
+	case nil:
+		return 4
+	case int64, float64:
+		return 3
+	case bool:
+		return 2
+	case string:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// valueKey returns a key identifying value, for grouping and DISTINCT.
+func valueKey(value any) string {
+	switch v := normalize(value).(type) {
+	case *graph.Node:
+		return "node:" + v.ID
+	case *graph.Edge:
+		return "edge:" + v.ID
+	case *graph.Path:
+		var sb strings.Builder
+		sb.WriteString("path:")
+		for _, edge := range v.Edges {
+			sb.WriteString(edge.ID + ",")
+		}
+		if len(v.Nodes) > 0 {
+			sb.WriteString(v.Nodes[0].ID)
+		}
+		return sb.String()
+	case []any:
+		keys := make([]string, len(v))
+		for i, item := range v {
+			keys[i] = valueKey(item)
+		}
+		return "list:[" + strings.Join(keys, ",") + "]"
+	default:
+		return fmt.Sprintf("%T:%v", v, v)
+	}
+}
+
+// expression is a parsed Return expression: the value of alias, or of its
+// property, if fn is empty, or fn called on arg, which is nil for count(*).
+type expression struct {
+	fn              string
+	distinct        bool
+	arg             *expression
+	alias, property string
+}
+
+var (
+	aggregateFuncs = []string{"count", "sum", "avg", "min", "max", "collect"}
+	scalarFuncs    = []string{"id", "elementid", "labels", "type", "length", "size", "nodes", "relationships", "startnode", "endnode", "properties", "keys"}
+)
+
+func (e *expression) aggregates() bool {
+	return slices.Contains(aggregateFuncs, e.fn)
+}
+
+// parseExpression parses the expressions of the package doc, e.g. "n",
+// "n.name", "length(p)" or "count(DISTINCT n.city)".
+func parseExpression(s string) (*expression, error) {
+	s = strings.TrimSpace(s)
+	if open := strings.IndexByte(s, '('); open > 0 && strings.HasSuffix(s, ")") {
+		e := &expression{fn: strings.ToLower(strings.TrimSpace(s[:open]))}
+		if !slices.Contains(aggregateFuncs, e.fn) && !slices.Contains(scalarFuncs, e.fn) {
+			return nil, unsupported("function " + s[:open])
+		}
+		inner := strings.TrimSpace(s[open+1 : len(s)-1])
+		if rest, ok := cutPrefixFold(inner, "DISTINCT "); ok && e.aggregates() {
+			e.distinct, inner = true, strings.TrimSpace(rest)
+		}
+		if inner == "*" && e.fn == "count" {
+			return e, nil
+		}
+		arg, err := parseExpression(inner)
+		if err != nil {
+			return nil, err
+		}
+		if arg.aggregates() {
+			return nil, unsupported("nested aggregation " + strconv.Quote(s))
+		}
+		e.arg = arg
+		return e, nil
+	}
+
+	alias, property, _ := strings.Cut(s, ".")
+	alias, property = unquote(alias), unquote(property)
+	if !isIdentifier(alias) || (property != "" && !isIdentifier(property)) {
+		return nil, unsupported("expression " + strconv.Quote(s))
+	}
+	return &expression{alias: alias, property: property}, nil
+}
+
+func cutPrefixFold(s, prefix string) (string, bool) {
+	if len(s) >= len(prefix) && strings.EqualFold(s[:len(prefix)], prefix) {
+		return s[len(prefix):], true
+	}
+	return s, false
+}
+
+func unquote(s string) string {
+	if len(s) >= 2 && s[0] == '`' && s[len(s)-1] == '`' {
+		return s[1 : len(s)-1]
+	}
+	return s
+}
+
+func isIdentifier(s string) bool {
+	if s == "" {
+		return false
+	}
+	for _, r := range s {
+		if r != '_' && !('a' <= r && r <= 'z') && !('A' <= r && r <= 'Z') && !('0' <= r && r <= '9') {
+			return false
+		}
+	}
+	return true
+}
+
+// eval evaluates the scalar expression e on r.
+func (s *store) eval(e *expression, r row) (any, error) {
+	if e.fn == "" {
+		return lookup(r, e.alias, e.property)
+	}
+	v, err := s.eval(e.arg, r)
+	if err != nil {
+		return nil, err
+	}
+	switch e.fn {
+	case "id", "elementid":
+		switch x := v.(type) {
+		case *graph.Node:
+			return x.ID, nil
+		case *graph.Edge:
+			return x.ID, nil
+		}
+	case "labels":
+		if node, ok := v.(*graph.Node); ok {
+			return normalize(node.Labels), nil
+		}
+	case "type":
+		if edge, ok := v.(*graph.Edge); ok {
+			return edge.Label, nil
+		}
+	case "length", "size":
+		switch x := v.(type) {
+		case *graph.Path:
+			return int64(len(x.Edges)), nil
+		case string:
+			return int64(len([]rune(x))), nil
+		}
+		if list, ok := normalize(v).([]any); ok {
+			return int64(len(list)), nil
+		}
+	case "nodes":
+		if path, ok := v.(*graph.Path); ok {
+			return path.Nodes, nil
+		}
+	case "relationships":
+		switch x := v.(type) {
+		case *graph.Path:
+			return x.Edges, nil
+		case []*graph.Edge:
+			return x, nil
+		}
+	case "startnode", "endnode":
+		if edge, ok := v.(*graph.Edge); ok {
+			if e.fn == "startnode" {
+				return s.nodes[edge.SourceNodeID], nil
+			}
+			return s.nodes[edge.TargetNodeID], nil
+		}
+	case "properties":
+		switch x := v.(type) {
+		case *graph.Node:
+			return map[string]any(maps.Clone(x.Properties)), nil
+		case *graph.Edge:
+			return map[string]any(maps.Clone(x.Properties)), nil
+		}
+	case "keys":
+		var props graph.Properties
+		switch x := v.(type) {
+		case *graph.Node:
+			props = x.Properties
+		case *graph.Edge:
+			props = x.Properties
+		}
+		if props != nil {
+			return normalize(slices.Sorted(maps.Keys(props))), nil
+		}
+	}
+	return nil, nil
+}
+
+// projection is the parsed items of a Return or a Stage.
+type projection struct {
+	names      []string
+	exprs      []*expression
+	aggregates bool
+}
+
+func parseItems(items []graph.Return) (*projection, error) {
+	p := &projection{}
+	for _, item := range items {
+		var e *expression
+		if a := item.Aggregate; a != nil {
+			e = &expression{fn: string(a.Func), distinct: a.Distinct}
+			if !e.aggregates() {
+				return nil, unsupported("aggregation " + string(a.Func))
+			}
+			if a.Alias != "" {
+				e.arg = &expression{alias: a.Alias, property: a.Property}
+			}
+		} else {
+			var err error
+			if e, err = parseExpression(item.Expression); err != nil {
+				return nil, err
+			}
+		}
+		p.names = append(p.names, itemName(item))
+		p.exprs = append(p.exprs, e)
+		p.aggregates = p.aggregates || e.aggregates()
+	}
+	return p, nil
+}
+
+// itemName returns the name of the projected item, its expression if it has
+// no alias, e.g. "n.name" or "count(n)", as in Cypher.
+func itemName(item graph.Return) string {
+	if item.Alias != "" {
+		return item.Alias
+	}
+	if a := item.Aggregate; a != nil {
+		arg := a.Alias
+		if a.Property != "" {
+			arg += "." + a.Property
+		}
+		if arg == "" {
+			arg = "*"
+		} else if a.Distinct {
+			arg = "DISTINCT " + arg
+		}
+		return string(a.Func) + "(" + arg + ")"
+	}
+	return item.Expression
+}
+
+// project evaluates p on rows, grouping them by the items which don't
+// aggregate if some do. Unless it aggregates, it also returns the row each
+// projected row comes from, whose aliases ORDER BY may refer to.
+func (s *store) project(p *projection, rows []row) ([]row, []row, error) {
+	if !p.aggregates {
+		projected := make([]row, len(rows))
+		for i, r := range rows {
+			projected[i] = make(row, len(p.exprs))
+			for j, e := range p.exprs {
+				v, err := s.eval(e, r)
+				if err != nil {
+					return nil, nil, err
+				}
+				projected[i][p.names[j]] = v
+			}
+		}
+		return projected, rows, nil
+	}
+
+	type group struct {
+		keys   row
+		values [][]any
+		rows   int
+	}
+	var groups []*group
+	byKey := make(map[string]*group)
+	for _, r := range rows {
+		keys := make(row)
+		var parts []string
+		for j, e := range p.exprs {
+			if e.aggregates() {
+				continue
+			}
+			v, err := s.eval(e, r)
+			if err != nil {
+				return nil, nil, err
+			}
+			keys[p.names[j]] = v
+			parts = append(parts, valueKey(v))
+		}
+		key := strings.Join(parts, "\x00")
+		g, ok := byKey[key]
+		if !ok {
+			g = &group{keys: keys, values: make([][]any, len(p.exprs))}
+			byKey[key] = g
+			groups = append(groups, g)
+		}
+		g.rows++
+		for j, e := range p.exprs {
+			if !e.aggregates() || e.arg == nil {
+				continue
+			}
+			v, err := s.eval(e.arg, r)
+			if err != nil {
+				return nil, nil, err
+			}
+			g.values[j] = append(g.values[j], v)
+		}
+	}
+	// Aggregating without grouping keys returns a record even with no rows.
+	if len(groups) == 0 && !slices.ContainsFunc(p.exprs, func(e *expression) bool { return !e.aggregates() }) {
+		groups = append(groups, &group{keys: make(row), values: make([][]any, len(p.exprs))})
+	}
+
+	projected := make([]row, len(groups))
+	for i, g := range groups {
+		projected[i] = g.keys
+		for j, e := range p.exprs {
+			if e.aggregates() {
+				projected[i][p.names[j]] = aggregate(e, g.values[j], g.rows)
+			}
+		}
+	}
+	return projected, nil, nil
+}
+
+// aggregate computes the aggregation e of values, ignoring nulls, over the
+// rows of a group.
+func aggregate(e *expression, values []any, rows int) any {
+	if e.arg == nil {
+		return int64(rows)
+	}
+	var kept []any
+	seen := make(map[string]bool)
+	for _, v := range values {
+		if v == nil {
+			continue
+		}
+		if e.distinct {
+			key := valueKey(v)
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+		}
+		kept = append(kept, normalize(v))
+	}
+
+	switch e.fn {
+	case "count":
+		return int64(len(kept))
+	case "collect":
+		return append([]any{}, kept...)
+	case "sum", "avg":
+		var sum int64
+		var fsum float64
+		floats := false
+		for _, v := range kept {
+			switch x := v.(type) {
+			case int64:
+				sum += x
+			case float64:
+				fsum, floats = fsum+x, true
+			}
+		}
+		if e.fn == "avg" {
+			if len(kept) == 0 {
+				return nil
+			}
+			return (float64(sum) + fsum) / float64(len(kept))
+		}
+		if floats {
+			return float64(sum) + fsum
+		}
+		return sum
+	default:
+		if len(kept) == 0 {
+			return nil
+		}
+		best := kept[0]
+		for _, v := range kept[1:] {
+			c := orderCompare(v, best)
+			if (e.fn == "min" && c < 0) || (e.fn == "max" && c > 0) {
+				best = v
+			}
+		}
+		return best
+	}
+}
+
+// orderValue returns the value o sorts r by: the property of its alias, or
+// the ID of the entity, or the value of a projected item, if o has none.
+func orderValue(r row, o graph.Order) (any, error) {
+	v, err := lookup(r, o.Alias, o.Property)
+	if err != nil || o.Property != "" {
+		return v, err
+	}
+	switch x := v.(type) {
+	case *graph.Node:
+		return x.ID, nil
+	case *graph.Edge:
+		return x.ID, nil
+	default:
+		return v, nil
+	}
+}
+
+// evaluate runs query up to its RETURN: it returns the matches satisfying
+// its WHERE clause as projected by its stages and satisfying its keyset
+// condition.
+func (s *store) evaluate(query *graph.Query) ([]row, error) {
+	rows, err := filter(s.match(query.Match), query.Where)
+	if err != nil {
+		return nil, err
+	}
+	for _, stage := range query.With {
+		p, err := parseItems(stage.Items)
+		if err != nil {
+			return nil, err
+		}
+		if rows, _, err = s.project(p, rows); err != nil {
+			return nil, err
+		}
+		if rows, err = filter(rows, stage.Where); err != nil {
+			return nil, err
+		}
+	}
+	return after(rows, query)
+}
+
+// after returns the rows sorting after query.After, see graph.Query.
+func after(rows []row, query *graph.Query) ([]row, error) {
+	n := min(len(query.OrderBy), len(query.After))
+	if n == 0 {
+		return rows, nil
+	}
+	var kept []row
+	for _, r := range rows {
+		for i := 0; i < n; i++ {
+			v, err := orderValue(r, query.OrderBy[i])
+			if err != nil {
+				return nil, err
+			}
+			c, ok := compare(v, query.After[i])
+			if !ok {
+				break
+			}
+			if !query.OrderBy[i].Asc {
+				c = -c
+			}
+			if c > 0 {
+				kept = append(kept, r)
+			}
+			if c != 0 {
+				break
+			}
+		}
+	}
+	return kept, nil
+}
+
+// query runs query and returns its records.
+func (s *store) query(query *graph.Query) ([]graph.Record, error) {
+	rows, err := s.evaluate(query)
+	if err != nil {
+		return nil, err
+	}
+
+	p, err := parseItems(query.Return)
+	if err != nil {
+		return nil, err
+	}
+	if len(query.Return) == 0 {
+		// Return the aliases of the last stage, or of the patterns if none.
+		names := matchAliases(query)
+		if len(query.With) > 0 {
+			last, _ := parseItems(query.With[len(query.With)-1].Items)
+			names = last.names
+		}
+		for _, name := range names {
+			p.names = append(p.names, name)
+			p.exprs = append(p.exprs, &expression{alias: name})
+		}
+	}
+	projected, sources, err := s.project(p, rows)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(query.OrderBy) > 0 {
+		keys := make([][]any, len(projected))
+		for i, r := range projected {
+			scope := r
+			if sources != nil {
+				scope = maps.Clone(sources[i])
+				maps.Copy(scope, r)
+			}
+			for _, o := range query.OrderBy {
+				v, err := orderValue(scope, o)
+				if err != nil {
+					return nil, err
+				}
+				keys[i] = append(keys[i], v)
+			}
+		}
+		order := make([]int, len(projected))
+		for i := range order {
+			order[i] = i
+		}
+		slices.SortStableFunc(order, func(a, b int) int {
+			for k, o := range query.OrderBy {
+				c := orderCompare(keys[a][k], keys[b][k])
+				if !o.Asc {
+					c = -c
+				}
+				if c != 0 {
+					return c
+				}
+			}
+			return 0
+		})
+		sorted := make([]row, len(order))
+		for i, j := range order {
+			sorted[i] = projected[j]
+		}
+		projected = sorted
+	}
+
+	if query.Skip != nil {
+		projected = projected[min(max(*query.Skip, 0), len(projected)):]
+	}
+	if query.Limit != nil {
+		projected = projected[:min(max(*query.Limit, 0), len(projected))]
+	}
+	records := make([]graph.Record, len(projected))
+	for i, r := range projected {
+		records[i] = toRecord(r)
+	}
+	return records, nil
+}
+
+// toRecord converts r to a record of copies of the stored entities.
+func toRecord(r row) graph.Record {
+	record := make(graph.Record, len(r))
+	for key, value := range r {
+		record[key] = toResult(value)
+	}
+	return record
+}
+
+// toResult converts value like the neo4j client: a list of nodes to
+// []*graph.Node and a list of edges to []*graph.Edge.
+func toResult(value any) graph.ResultEntity {
+	switch v := value.(type) {
+	case *graph.Node:
+		return cloneNode(v)
+	case *graph.Edge:
+		return cloneEdge(v)
+	case *graph.Path:
+		return clonePath(v)
+	case []*graph.Node:
+		return cloneNodes(v)
+	case []*graph.Edge:
+		return cloneEdges(v)
+	case []any:
+		if len(v) > 0 && allOf[*graph.Node](v) {
+			nodes := make([]*graph.Node, len(v))
+			for i, item := range v {
+				nodes[i] = cloneNode(item.(*graph.Node))
+			}
+			return nodes
+		}
+		if len(v) > 0 && allOf[*graph.Edge](v) {
+			edges := make([]*graph.Edge, len(v))
+			for i, item := range v {
+				edges[i] = cloneEdge(item.(*graph.Edge))
+			}
+			return edges
+		}
+		items := make([]any, len(v))
+		for i, item := range v {
+			items[i] = toResult(item)
+		}
+		return items
+	default:
+		return v
+	}
+}
+
+func allOf[T any](list []any) bool {
+	for _, item := range list {
+		if _, ok := item.(T); !ok {
+			return false
+		}
+	}
+	return true
+}
+
+func cloneNodes(nodes []*graph.Node) []*graph.Node {
+	cloned := make([]*graph.Node, len(nodes))
+	for i, node := range nodes {
+		cloned[i] = cloneNode(node)
+	}
+	return cloned
+}
+
+func cloneEdges(edges []*graph.Edge) []*graph.Edge {
+	cloned := make([]*graph.Edge, len(edges))
+	for i, edge := range edges {
+		cloned[i] = cloneEdge(edge)
+	}
+	return cloned
+}
+
+func clonePath(path *graph.Path) *graph.Path {
+	return &graph.Path{Nodes: cloneNodes(path.Nodes), Edges: cloneEdges(path.Edges)}
+}
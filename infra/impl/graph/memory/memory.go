@@ -0,0 +1,183 @@
+// Package memory implements the graph contract in memory, for the tests of the
+// code using a graph.Client to run without a database. Nodes and edges are
+// kept in maps with the adjacency lists of the nodes, and graph.Query is
+// evaluated on them with the semantics of the Cypher the neo4j client builds.
+// Return expressions are a subset of Cypher: an alias, a property of an alias,
+// e.g. "n.name", or a call of id, elementId, labels, type, length, size,
+// nodes, relationships, startNode, endNode or an aggregate function on one,
+// e.g. "count(DISTINCT n)".
+//
+// Each call of graph.WithDatabase selects a database of its own, created on
+// its first write. Writes are atomic and transactions see a snapshot of their
+// database, committed unless it was written meanwhile. There is no query
+// language, so RawQuery, BatchQuery and graph.Where.MustExpr fail with an
+// error wrapping errors.ErrUnsupported.
+package memory
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/me2seeks/forge/infra/contract/graph"
+)
+
+// ErrConflict is returned by the Commit of a transaction whose database was
+// written since it began.
+var ErrConflict = errors.New("memory: transaction conflicts with a concurrent write")
+
+type memoryClient struct {
+	dbs  *databases
+	exec executor
+}
+
+// New creates an empty in-memory graph.
+func New() graph.Client {
+	dbs := &databases{stores: make(map[string]*store)}
+	return &memoryClient{dbs: dbs, exec: dbs}
+}
+
+// executor runs units of work on a store, each atomically or all in the same
+// transaction.
+type executor interface {
+	read(ctx context.Context, work func(s *store) error) error
+	write(ctx context.Context, work func(s *store) error) error
+}
+
+// databases runs each unit of work on the database selected by ctx, reads
+// concurrently and writes one at a time.
+type databases struct {
+	mu     sync.RWMutex
+	stores map[string]*store
+}
+
+func (d *databases) read(ctx context.Context, work func(s *store) error) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	s := d.stores[graph.DatabaseFromContext(ctx)]
+	if s == nil {
+		s = newStore()
+	}
+	return work(s)
+}
+
+func (d *databases) write(ctx context.Context, work func(s *store) error) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	name := graph.DatabaseFromContext(ctx)
+	s := d.stores[name]
+	if s == nil {
+		s = newStore()
+		d.stores[name] = s
+	}
+	return s.apply(work)
+}
+
+// txExecutor runs all units of work on the snapshot of a transaction.
+type txExecutor struct {
+	mu     sync.Mutex
+	dbs    *databases
+	name   string
+	store  *store
+	closed bool
+	// version is the version of the database the snapshot was taken at.
+	version int64
+}
+
+func (e *txExecutor) run(ctx context.Context, work func(s *store) error, write bool) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.closed {
+		return graph.ErrTxDone
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if write {
+		return e.store.apply(work)
+	}
+	return work(e.store)
+}
+
+func (e *txExecutor) read(ctx context.Context, work func(s *store) error) error {
+	return e.run(ctx, work, false)
+}
+
+func (e *txExecutor) write(ctx context.Context, work func(s *store) error) error {
+	return e.run(ctx, work, true)
+}
+
+// memoryTx reuses the client operations on top of a snapshot of a database.
+type memoryTx struct {
+	*memoryClient
+	exec *txExecutor
+}
+
+// BeginTx takes a snapshot of the database selected by ctx, which the
+// operations of the transaction read and write.
+func (c *memoryClient) BeginTx(ctx context.Context) (graph.Tx, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	c.dbs.mu.RLock()
+	defer c.dbs.mu.RUnlock()
+	name := graph.DatabaseFromContext(ctx)
+	exec := &txExecutor{dbs: c.dbs, name: name, store: newStore()}
+	if s := c.dbs.stores[name]; s != nil {
+		exec.store, exec.version = s.clone(), s.version
+	}
+	return &memoryTx{
+		memoryClient: &memoryClient{dbs: c.dbs, exec: exec},
+		exec:         exec,
+	}, nil
+}
+
+// Commit replaces the database with the snapshot of the transaction if it
+// wrote, failing with ErrConflict if the database was written since the
+// transaction began.
+func (t *memoryTx) Commit(ctx context.Context) error {
+	t.exec.mu.Lock()
+	defer t.exec.mu.Unlock()
+	if t.exec.closed {
+		return graph.ErrTxDone
+	}
+	t.exec.closed = true
+	if t.exec.store.version == t.exec.version {
+		return nil
+	}
+
+	t.exec.dbs.mu.Lock()
+	defer t.exec.dbs.mu.Unlock()
+	var version int64
+	if s := t.exec.dbs.stores[t.exec.name]; s != nil {
+		version = s.version
+	}
+	if version != t.exec.version {
+		return ErrConflict
+	}
+	t.exec.dbs.stores[t.exec.name] = t.exec.store
+	return nil
+}
+
+func (t *memoryTx) Rollback(ctx context.Context) error {
+	t.exec.mu.Lock()
+	defer t.exec.mu.Unlock()
+	if t.exec.closed {
+		return graph.ErrTxDone
+	}
+	t.exec.closed, t.exec.store = true, nil
+	return nil
+}
+
+// unsupported returns the error of an operation the in-memory graph has no
+// equivalent for.
+func unsupported(op string) error {
+	return fmt.Errorf("memory: %s: %w", op, errors.ErrUnsupported)
+}
@@ -0,0 +1,670 @@
+package memory
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/me2seeks/forge/infra/contract/graph"
+	"github.com/stretchr/testify/require"
+)
+
+// setupCities creates cities A -> B -> C and A -> C, with the km of the roads.
+func setupCities(t *testing.T) (graph.Client, []*graph.Node) {
+	ctx := context.Background()
+	client := New()
+	nodes, err := client.CreateNodes(ctx, []*graph.Node{
+		{Labels: []string{"City"}, Properties: graph.Properties{"name": "A", "population": 100}},
+		{Labels: []string{"City"}, Properties: graph.Properties{"name": "B", "population": 200}},
+		{Labels: []string{"City", "Capital"}, Properties: graph.Properties{"name": "C", "population": 300}},
+	})
+	require.NoError(t, err)
+	_, err = client.CreateEdges(ctx, []*graph.Edge{
+		{Label: "ROAD", SourceNodeID: nodes[0].ID, TargetNodeID: nodes[1].ID, Properties: graph.Properties{"km": 1}},
+		{Label: "ROAD", SourceNodeID: nodes[1].ID, TargetNodeID: nodes[2].ID, Properties: graph.Properties{"km": 1}},
+		{Label: "ROAD", SourceNodeID: nodes[0].ID, TargetNodeID: nodes[2].ID, Properties: graph.Properties{"km": 5}},
+	})
+	require.NoError(t, err)
+	return client, nodes
+}
+
+func ptr(i int) *int {
+	return &i
+}
+
+func names(t *testing.T, nodes []*graph.Node) []string {
+	t.Helper()
+	var got []string
+	for _, node := range nodes {
+		got = append(got, node.Properties["name"].(string))
+	}
+	return got
+}
+
+func TestNodeOperations(t *testing.T) {
+	ctx := context.Background()
+	client := New()
+
+	node, err := client.CreateNode(ctx, &graph.Node{Labels: []string{"Person"}, Properties: graph.Properties{"name": "Alice", "age": 30}})
+	require.NoError(t, err)
+	require.NotEmpty(t, node.ID)
+	require.Equal(t, int64(30), node.Properties["age"])
+
+	// Returned nodes are copies.
+	node.Properties["name"] = "Changed"
+	got, err := client.GetNode(ctx, node.ID)
+	require.NoError(t, err)
+	require.Equal(t, "Alice", got.Properties["name"])
+
+	require.NoError(t, client.UpdateNode(ctx, node.ID, graph.Properties{"age": 31, "name": nil}))
+	got, err = client.GetNode(ctx, node.ID)
+	require.NoError(t, err)
+	require.Equal(t, graph.Properties{"age": int64(31)}, got.Properties)
+
+	merged, err := client.MergeNode(ctx, &graph.Node{Labels: []string{"Person"}, Properties: graph.Properties{"age": int64(31), "city": "Paris"}}, []string{"age"})
+	require.NoError(t, err)
+	require.Equal(t, node.ID, merged.ID)
+	require.Equal(t, "Paris", merged.Properties["city"])
+
+	require.NoError(t, client.DeleteNode(ctx, node.ID))
+	got, err = client.GetNode(ctx, node.ID)
+	require.NoError(t, err)
+	require.Nil(t, got)
+}
+
+func TestEdgeOperations(t *testing.T) {
+	ctx := context.Background()
+	client, nodes := setupCities(t)
+	a, b := nodes[0], nodes[1]
+
+	edge, err := client.CreateEdge(ctx, &graph.Edge{
+		Label:              "RAIL",
+		SourceNodeSelector: &graph.NodeSelector{Labels: []string{"City"}, Properties: graph.Properties{"name": "A"}},
+		TargetNodeSelector: &graph.NodeSelector{Properties: graph.Properties{"name": "B"}},
+	})
+	require.NoError(t, err)
+	require.Equal(t, a.ID, edge.SourceNodeID)
+	require.Equal(t, b.ID, edge.TargetNodeID)
+
+	missing, err := client.CreateEdge(ctx, &graph.Edge{Label: "RAIL", SourceNodeID: a.ID, TargetNodeID: "404"})
+	require.NoError(t, err)
+	require.Nil(t, missing)
+
+	merged, err := client.MergeEdge(ctx, &graph.Edge{Label: "RAIL", SourceNodeID: a.ID, TargetNodeID: b.ID}, graph.Properties{"new": true}, graph.Properties{"seen": true})
+	require.NoError(t, err)
+	require.Equal(t, edge.ID, merged.ID)
+	require.Equal(t, graph.Properties{"seen": true}, merged.Properties)
+
+	require.NoError(t, client.UpdateEdge(ctx, edge.ID, graph.Properties{"speed": 300}))
+	got, err := client.GetEdge(ctx, edge.ID)
+	require.NoError(t, err)
+	require.Equal(t, int64(300), got.Properties["speed"])
+
+	// A failing batch creates no edge.
+	_, err = client.CreateEdges(ctx, []*graph.Edge{
+		{Label: "FERRY", SourceNodeID: a.ID, TargetNodeID: b.ID},
+		{Label: "FERRY", SourceNodeID: a.ID, TargetNodeID: "404"},
+	})
+	require.Error(t, err)
+	edges, err := client.FindEdges(ctx, &graph.Query{Match: []graph.Pattern{{Edge: &graph.EdgePattern{Labels: []string{"FERRY"}, Node: &graph.Pattern{}}}}})
+	require.NoError(t, err)
+	require.Empty(t, edges)
+
+	// Deleting a node deletes its edges.
+	require.NoError(t, client.DeleteNode(ctx, b.ID))
+	got, err = client.GetEdge(ctx, edge.ID)
+	require.NoError(t, err)
+	require.Nil(t, got)
+}
+
+func TestGetNeighbors(t *testing.T) {
+	ctx := context.Background()
+	client, nodes := setupCities(t)
+	a, b, c := nodes[0], nodes[1], nodes[2]
+	_, err := client.CreateEdge(ctx, &graph.Edge{Label: "RAIL", SourceNodeID: c.ID, TargetNodeID: a.ID})
+	require.NoError(t, err)
+
+	n, err := client.GetNeighbors(ctx, b.ID)
+	require.NoError(t, err)
+	require.Equal(t, []string{"C", "A"}, names(t, n.Nodes))
+	require.Len(t, n.Edges, 2)
+
+	n, err = client.GetNeighbors(ctx, a.ID, graph.WithDirection(graph.DirectionOutgoing), graph.WithRelationshipTypes("ROAD"))
+	require.NoError(t, err)
+	require.Equal(t, []string{"B", "C"}, names(t, n.Nodes))
+	for _, edge := range n.Edges {
+		require.Equal(t, a.ID, edge.SourceNodeID)
+	}
+
+	// Longer walks reach the same neighbors through other edges, A -> C.
+	n, err = client.GetNeighbors(ctx, b.ID, graph.WithDirection(graph.DirectionOutgoing), graph.WithDepth(3))
+	require.NoError(t, err)
+	require.Equal(t, []string{"C", "A"}, names(t, n.Nodes))
+	require.Len(t, n.Edges, 3)
+
+	n, err = client.GetNeighbors(ctx, "404")
+	require.NoError(t, err)
+	require.Empty(t, n.Nodes)
+	require.Empty(t, n.Edges)
+}
+
+func TestTraverse(t *testing.T) {
+	ctx := context.Background()
+	client, nodes := setupCities(t)
+	a, b, c := nodes[0], nodes[1], nodes[2]
+	_, err := client.CreateEdge(ctx, &graph.Edge{Label: "RAIL", SourceNodeID: c.ID, TargetNodeID: a.ID})
+	require.NoError(t, err)
+
+	// ends returns the names of the nodes visited and the lengths of their
+	// paths.
+	ends := func(paths []*graph.Path) ([]string, []int) {
+		var visited []string
+		var lengths []int
+		for _, path := range paths {
+			visited = append(visited, path.Nodes[len(path.Nodes)-1].Properties["name"].(string))
+			lengths = append(lengths, len(path.Edges))
+		}
+		return visited, lengths
+	}
+
+	t.Run("bfs", func(t *testing.T) {
+		paths, err := client.Traverse(ctx, a.ID, graph.TraversalSpec{MaxDepth: 2, Direction: graph.DirectionOutgoing})
+		require.NoError(t, err)
+		visited, lengths := ends(paths)
+		require.Equal(t, []string{"A", "B", "C"}, visited)
+		require.Equal(t, []int{0, 1, 1}, lengths)
+	})
+
+	t.Run("dfs", func(t *testing.T) {
+		paths, err := client.Traverse(ctx, a.ID, graph.TraversalSpec{Strategy: graph.TraversalDFS, MaxDepth: 2, Direction: graph.DirectionOutgoing})
+		require.NoError(t, err)
+		visited, lengths := ends(paths)
+		require.Equal(t, []string{"A", "B", "C"}, visited)
+		require.Equal(t, []int{0, 1, 2}, lengths)
+		require.Equal(t, []string{"A", "B", "C"}, names(t, paths[2].Nodes))
+	})
+
+	t.Run("node path uniqueness", func(t *testing.T) {
+		// The RAIL edge back to A closes a cycle, never followed.
+		paths, err := client.Traverse(ctx, a.ID, graph.TraversalSpec{MaxDepth: 3, Direction: graph.DirectionOutgoing, Uniqueness: graph.UniqueNodePath})
+		require.NoError(t, err)
+		visited, lengths := ends(paths)
+		require.Equal(t, []string{"A", "B", "C", "C"}, visited)
+		require.Equal(t, []int{0, 1, 1, 2}, lengths)
+	})
+
+	t.Run("relationship types", func(t *testing.T) {
+		paths, err := client.Traverse(ctx, b.ID, graph.TraversalSpec{MaxDepth: 3, RelationshipTypes: []string{"RAIL"}})
+		require.NoError(t, err)
+		visited, _ := ends(paths)
+		require.Equal(t, []string{"B"}, visited)
+
+		paths, err = client.Traverse(ctx, a.ID, graph.TraversalSpec{MaxDepth: 3, Direction: graph.DirectionIncoming})
+		require.NoError(t, err)
+		visited, _ = ends(paths)
+		require.Equal(t, []string{"A", "C", "B"}, visited)
+	})
+
+	t.Run("node filter", func(t *testing.T) {
+		paths, err := client.Traverse(ctx, a.ID, graph.TraversalSpec{
+			MaxDepth:   2,
+			Direction:  graph.DirectionOutgoing,
+			NodeFilter: func(node *graph.Node) bool { return node.ID != b.ID },
+		})
+		require.NoError(t, err)
+		visited, _ := ends(paths)
+		require.Equal(t, []string{"A", "C"}, visited)
+	})
+
+	t.Run("visit", func(t *testing.T) {
+		var seen []string
+		paths, err := client.Traverse(ctx, a.ID, graph.TraversalSpec{
+			Strategy:  graph.TraversalDFS,
+			MaxDepth:  2,
+			Direction: graph.DirectionOutgoing,
+			Visit: func(path *graph.Path) error {
+				seen = append(seen, path.Nodes[len(path.Nodes)-1].Properties["name"].(string))
+				if path.Nodes[len(path.Nodes)-1].ID == b.ID {
+					return graph.SkipNode
+				}
+				return nil
+			},
+		})
+		require.NoError(t, err)
+		visited, lengths := ends(paths)
+		require.Equal(t, []string{"A", "B", "C"}, visited)
+		require.Equal(t, []int{0, 1, 1}, lengths)
+		require.Equal(t, visited, seen)
+
+		paths, err = client.Traverse(ctx, a.ID, graph.TraversalSpec{
+			MaxDepth: 2,
+			Visit: func(path *graph.Path) error {
+				if len(path.Edges) > 0 {
+					return graph.StopTraversal
+				}
+				return nil
+			},
+		})
+		require.NoError(t, err)
+		require.Len(t, paths, 2)
+
+		boom := errors.New("boom")
+		_, err = client.Traverse(ctx, a.ID, graph.TraversalSpec{MaxDepth: 2, Visit: func(*graph.Path) error { return boom }})
+		require.ErrorIs(t, err, boom)
+	})
+
+	paths, err := client.Traverse(ctx, "404", graph.TraversalSpec{MaxDepth: 2})
+	require.NoError(t, err)
+	require.Empty(t, paths)
+}
+
+func TestQuery(t *testing.T) {
+	ctx := context.Background()
+	client, nodes := setupCities(t)
+
+	t.Run("where", func(t *testing.T) {
+		found, err := client.FindNodes(ctx, &graph.Query{
+			Match: []graph.Pattern{{Alias: "c", Labels: []string{"City"}}},
+			Where: &graph.Where{
+				Filter:  []graph.Condition{{Alias: "c", Property: "population", Operator: graph.OpGreaterThanOrEqual, Value: 200}},
+				MustNot: []graph.Condition{{Alias: "c", Property: "name", Operator: graph.OpIn, Value: []string{"C"}}},
+			},
+		})
+		require.NoError(t, err)
+		require.Equal(t, []string{"B"}, names(t, found))
+	})
+
+	t.Run("pattern", func(t *testing.T) {
+		result, err := client.Query(ctx, &graph.Query{
+			Match: []graph.Pattern{{
+				Alias:      "a",
+				Properties: graph.Properties{"name": "A"},
+				Edge:       &graph.EdgePattern{Labels: []string{"ROAD"}, Node: &graph.Pattern{Alias: "b", Labels: []string{"Capital"}}},
+			}},
+			Return: []graph.Return{{Expression: "b.name", Alias: "name"}, {Expression: "r.km"}},
+		})
+		require.NoError(t, err)
+		require.Equal(t, []graph.Record{{"name": "C", "r.km": int64(5)}}, result.Records)
+	})
+
+	t.Run("variable length", func(t *testing.T) {
+		paths, err := client.FindPaths(ctx, &graph.Query{
+			Match: []graph.Pattern{{
+				PathAlias:  "p",
+				Properties: graph.Properties{"name": "A"},
+				Edge:       &graph.EdgePattern{MinHops: ptr(1), MaxHops: ptr(2), Node: &graph.Pattern{Properties: graph.Properties{"name": "C"}}},
+			}},
+			Return: []graph.Return{{Expression: "p"}},
+		})
+		require.NoError(t, err)
+		require.Len(t, paths, 2)
+		require.Len(t, paths[0].Edges, 2)
+		require.Len(t, paths[1].Edges, 1)
+	})
+
+	t.Run("incoming", func(t *testing.T) {
+		found, err := client.FindNodes(ctx, &graph.Query{
+			Match:  []graph.Pattern{{Properties: graph.Properties{"name": "C"}, Edge: &graph.EdgePattern{Direction: graph.DirectionIncoming, Node: &graph.Pattern{}}}},
+			Return: []graph.Return{{Expression: "m"}},
+		})
+		require.NoError(t, err)
+		require.Equal(t, []string{"B", "A"}, names(t, found))
+	})
+
+	t.Run("aggregate", func(t *testing.T) {
+		result, err := client.Query(ctx, &graph.Query{
+			Match: []graph.Pattern{{Alias: "a", Edge: &graph.EdgePattern{Node: &graph.Pattern{Alias: "b"}}}},
+			With: []graph.Stage{{
+				Items: []graph.Return{
+					{Expression: "a"},
+					{Alias: "roads", Aggregate: &graph.Aggregation{Func: graph.AggregateCount, Alias: "b"}},
+					{Alias: "km", Aggregate: &graph.Aggregation{Func: graph.AggregateSum, Alias: "r", Property: "km"}},
+				},
+				Where: &graph.Where{Filter: []graph.Condition{{Alias: "roads", Operator: graph.OpGreaterThan, Value: 1}}},
+			}},
+			Return: []graph.Return{{Expression: "a.name", Alias: "name"}, {Expression: "roads"}, {Expression: "km"}},
+		})
+		require.NoError(t, err)
+		require.Equal(t, []graph.Record{{"name": "A", "roads": int64(2), "km": int64(6)}}, result.Records)
+
+		result, err = client.Query(ctx, &graph.Query{
+			Match:  []graph.Pattern{{Alias: "c", Labels: []string{"Town"}}},
+			Return: []graph.Return{{Expression: "count(*)", Alias: "count"}, {Expression: "collect(c.name)", Alias: "names"}},
+		})
+		require.NoError(t, err)
+		require.Equal(t, []graph.Record{{"count": int64(0), "names": []any{}}}, result.Records)
+	})
+
+	t.Run("order", func(t *testing.T) {
+		query := &graph.Query{
+			Match:   []graph.Pattern{{Alias: "c", Labels: []string{"City"}}},
+			Return:  []graph.Return{{Expression: "c"}},
+			OrderBy: []graph.Order{{Alias: "c", Property: "population"}},
+			Limit:   ptr(2),
+		}
+		found, err := client.FindNodes(ctx, query)
+		require.NoError(t, err)
+		require.Equal(t, []string{"C", "B"}, names(t, found))
+
+		query.After = []any{200}
+		found, err = client.FindNodes(ctx, query)
+		require.NoError(t, err)
+		require.Equal(t, []string{"A"}, names(t, found))
+	})
+
+	t.Run("count", func(t *testing.T) {
+		count, err := client.Count(ctx, &graph.Query{Match: []graph.Pattern{{Labels: []string{"City"}}}})
+		require.NoError(t, err)
+		require.EqualValues(t, 3, count)
+	})
+
+	t.Run("bound alias", func(t *testing.T) {
+		found, err := client.FindNodes(ctx, &graph.Query{
+			Match: []graph.Pattern{
+				{Alias: "a", Properties: graph.Properties{"name": "A"}},
+				{Alias: "a", Edge: &graph.EdgePattern{Node: &graph.Pattern{Alias: "b"}}},
+			},
+			Return:  []graph.Return{{Expression: "b"}},
+			OrderBy: []graph.Order{{Alias: "b", Property: "name", Asc: true}},
+		})
+		require.NoError(t, err)
+		require.Equal(t, []string{nodes[1].ID, nodes[2].ID}, []string{found[0].ID, found[1].ID})
+	})
+
+	t.Run("unsupported", func(t *testing.T) {
+		_, err := client.Query(ctx, &graph.Query{
+			Match: []graph.Pattern{{}},
+			Where: &graph.Where{MustExpr: []graph.ExpressionCondition{{Expression: "n.name STARTS WITH 'A'"}}},
+		})
+		require.True(t, errors.Is(err, errors.ErrUnsupported))
+		_, err = client.RawQuery(ctx, "MATCH (n) RETURN n", nil)
+		require.True(t, errors.Is(err, errors.ErrUnsupported))
+		_, err = client.Query(ctx, &graph.Query{Match: []graph.Pattern{{}}, Return: []graph.Return{{Expression: "n.name + '!'"}}})
+		require.True(t, errors.Is(err, errors.ErrUnsupported))
+	})
+
+	t.Run("undefined variable", func(t *testing.T) {
+		_, err := client.Query(ctx, &graph.Query{Match: []graph.Pattern{{}}, Return: []graph.Return{{Expression: "x.name"}}})
+		require.Error(t, err)
+	})
+}
+
+func TestByQuery(t *testing.T) {
+	ctx := context.Background()
+	client, _ := setupCities(t)
+
+	updated, err := client.UpdateNodesByQuery(ctx, &graph.Query{
+		Match: []graph.Pattern{{Alias: "c", Labels: []string{"City"}}},
+		Where: &graph.Where{Filter: []graph.Condition{{Alias: "c", Property: "population", Operator: graph.OpLessThan, Value: 300}}},
+	}, graph.Properties{"small": true})
+	require.NoError(t, err)
+	require.Equal(t, 2, updated)
+
+	updated, err = client.UpdateEdgesByQuery(ctx, &graph.Query{
+		Match: []graph.Pattern{{Edge: &graph.EdgePattern{Alias: "r", Properties: graph.Properties{"km": 1}}}},
+	}, graph.Properties{"short": true})
+	require.NoError(t, err)
+	require.Equal(t, 2, updated)
+
+	deleted, err := client.DeleteEdgesByQuery(ctx, &graph.Query{
+		Match: []graph.Pattern{{Edge: &graph.EdgePattern{Alias: "r", Properties: graph.Properties{"short": true}}}},
+	})
+	require.NoError(t, err)
+	require.Equal(t, 2, deleted)
+
+	deleted, err = client.DeleteNodesByQuery(ctx, &graph.Query{
+		Match: []graph.Pattern{{Properties: graph.Properties{"small": true}}},
+	})
+	require.NoError(t, err)
+	require.Equal(t, 2, deleted)
+
+	count, err := client.Count(ctx, &graph.Query{Match: []graph.Pattern{{Edge: &graph.EdgePattern{}}}})
+	require.NoError(t, err)
+	require.Zero(t, count)
+}
+
+func TestExportGraph(t *testing.T) {
+	ctx := context.Background()
+	client, nodes := setupCities(t)
+	a, b, c := nodes[0], nodes[1], nodes[2]
+	query := &graph.Query{
+		Match: []graph.Pattern{{
+			PathAlias:  "p",
+			Properties: graph.Properties{"name": "A"},
+			Edge:       &graph.EdgePattern{Labels: []string{"ROAD"}, Node: &graph.Pattern{}},
+		}},
+		Return: []graph.Return{{Expression: "p"}},
+	}
+	edges, err := client.FindEdges(ctx, &graph.Query{Match: []graph.Pattern{{Edge: &graph.EdgePattern{Alias: "r", Node: &graph.Pattern{}}}}})
+	require.NoError(t, err)
+	var ab, ac *graph.Edge
+	for _, edge := range edges {
+		switch {
+		case edge.SourceNodeID == a.ID && edge.TargetNodeID == b.ID:
+			ab = edge
+		case edge.SourceNodeID == a.ID && edge.TargetNodeID == c.ID:
+			ac = edge
+		}
+	}
+	require.NotNil(t, ab)
+	require.NotNil(t, ac)
+
+	export := func(format graph.ExportFormat) string {
+		var buf strings.Builder
+		require.NoError(t, client.ExportGraph(ctx, &buf, format, query))
+		return buf.String()
+	}
+
+	lines := strings.Split(strings.TrimSpace(export(graph.FormatJSONLines)), "\n")
+	require.Len(t, lines, 5)
+	require.JSONEq(t, `{"type": "node", "id": "`+a.ID+`", "labels": ["City"], "properties": {"name": "A", "population": 100}}`, lines[0])
+	require.JSONEq(t, `{"type": "edge", "id": "`+ab.ID+`", "label": "ROAD", "source_node_id": "`+a.ID+`", "target_node_id": "`+b.ID+`", "properties": {"km": 1}}`, lines[2])
+
+	require.Equal(t, "id,labels,name,population\n"+
+		a.ID+",City,A,100\n"+
+		b.ID+",City,B,200\n"+
+		c.ID+",City;Capital,C,300\n", export(graph.FormatNodesCSV))
+	require.Equal(t, "id,source,target,label,km\n"+
+		ab.ID+","+a.ID+","+b.ID+",ROAD,1\n"+
+		ac.ID+","+a.ID+","+c.ID+",ROAD,5\n", export(graph.FormatEdgesCSV))
+
+	graphML := export(graph.FormatGraphML)
+	for _, want := range []string{
+		`<key id="n1" for="node" attr.name="population" attr.type="long"></key>`,
+		`<node id="` + c.ID + `">`,
+		`<data key="labels">City;Capital</data>`,
+		`<edge id="` + ac.ID + `" source="` + a.ID + `" target="` + c.ID + `">`,
+		`<data key="e0">5</data>`,
+	} {
+		require.Contains(t, graphML, want)
+	}
+
+	require.Error(t, client.ExportGraph(ctx, io.Discard, "xlsx", query))
+}
+
+func TestImportGraph(t *testing.T) {
+	ctx := context.Background()
+	source, _ := setupCities(t)
+	all := &graph.Query{
+		Match:  []graph.Pattern{{PathAlias: "p", Edge: &graph.EdgePattern{Node: &graph.Pattern{}}}},
+		Return: []graph.Return{{Expression: "p"}},
+	}
+	export := func(format graph.ExportFormat) string {
+		var buf strings.Builder
+		require.NoError(t, source.ExportGraph(ctx, &buf, format, all))
+		return buf.String()
+	}
+	// check checks that client holds the cities of setupCities.
+	check := func(client graph.Client, res *graph.ImportResult) {
+		require.Equal(t, 3, res.Nodes)
+		require.Equal(t, 3, res.Edges)
+		require.Len(t, res.NodeIDs, 3)
+		result, err := client.Query(ctx, &graph.Query{
+			Match: []graph.Pattern{{
+				Alias:      "a",
+				Properties: graph.Properties{"name": "A"},
+				Edge:       &graph.EdgePattern{Labels: []string{"ROAD"}, Node: &graph.Pattern{Alias: "b", Labels: []string{"Capital"}}},
+			}},
+			Return: []graph.Return{{Expression: "b.population", Alias: "population"}, {Expression: "r.km"}},
+		})
+		require.NoError(t, err)
+		require.Equal(t, []graph.Record{{"population": int64(300), "r.km": int64(5)}}, result.Records)
+	}
+
+	t.Run("json lines", func(t *testing.T) {
+		client := New()
+		lines := export(graph.FormatJSONLines)
+		// Both readers read the export, each taking its own lines.
+		res, err := client.ImportGraph(ctx, strings.NewReader(lines), strings.NewReader(lines), graph.WithBatchSize(2))
+		require.NoError(t, err)
+		check(client, res)
+	})
+
+	t.Run("csv", func(t *testing.T) {
+		client := New()
+		res, err := client.ImportGraph(ctx,
+			strings.NewReader(export(graph.FormatNodesCSV)), strings.NewReader(export(graph.FormatEdgesCSV)),
+			graph.WithImportFormat(graph.FormatNodesCSV))
+		require.NoError(t, err)
+		check(client, res)
+	})
+
+	t.Run("unknown node", func(t *testing.T) {
+		client := New()
+		res, err := client.ImportGraph(ctx,
+			strings.NewReader("Id,Labels,name\n1,City,A\n"), strings.NewReader("Id,Source,Target,Label\n1,1,2,ROAD\n"),
+			graph.WithImportFormat(graph.FormatNodesCSV))
+		require.ErrorContains(t, err, `unknown target node "2"`)
+		require.Equal(t, 1, res.Nodes)
+		node, err := client.GetNode(ctx, res.NodeIDs["1"])
+		require.NoError(t, err)
+		require.Equal(t, graph.Properties{"name": "A"}, node.Properties)
+	})
+}
+
+func TestTx(t *testing.T) {
+	ctx := context.Background()
+	client, _ := setupCities(t)
+	count := func() int64 {
+		n, err := client.Count(ctx, &graph.Query{Match: []graph.Pattern{{}}})
+		require.NoError(t, err)
+		return n
+	}
+
+	tx, err := client.BeginTx(ctx)
+	require.NoError(t, err)
+	_, err = tx.CreateNode(ctx, &graph.Node{Labels: []string{"City"}})
+	require.NoError(t, err)
+	require.EqualValues(t, 3, count())
+	require.NoError(t, tx.Commit(ctx))
+	require.EqualValues(t, 4, count())
+	_, err = tx.CreateNode(ctx, &graph.Node{})
+	require.ErrorIs(t, err, graph.ErrTxDone)
+
+	err = graph.RunInTx(ctx, client, func(ctx context.Context, tx graph.Tx) error {
+		if _, err := tx.CreateNode(ctx, &graph.Node{}); err != nil {
+			return err
+		}
+		return errors.New("abort")
+	})
+	require.EqualError(t, err, "abort")
+	require.EqualValues(t, 4, count())
+
+	// A transaction fails to commit after a concurrent write.
+	tx, err = client.BeginTx(ctx)
+	require.NoError(t, err)
+	_, err = tx.CreateNode(ctx, &graph.Node{})
+	require.NoError(t, err)
+	_, err = client.CreateNode(ctx, &graph.Node{})
+	require.NoError(t, err)
+	require.ErrorIs(t, tx.Commit(ctx), ErrConflict)
+	require.EqualValues(t, 5, count())
+}
+
+func TestDatabases(t *testing.T) {
+	ctx := context.Background()
+	client, _ := setupCities(t)
+
+	other := graph.WithDatabase(ctx, "other")
+	_, err := client.CreateNode(other, &graph.Node{Labels: []string{"Person"}})
+	require.NoError(t, err)
+
+	labels, err := client.ListLabels(ctx)
+	require.NoError(t, err)
+	require.Equal(t, []string{"Capital", "City"}, labels)
+	labels, err = client.ListLabels(other)
+	require.NoError(t, err)
+	require.Equal(t, []string{"Person"}, labels)
+}
+
+func TestSchema(t *testing.T) {
+	ctx := context.Background()
+	client, _ := setupCities(t)
+
+	require.NoError(t, client.CreateConstraint(ctx, "City", "name", graph.ConstraintUnique))
+	_, err := client.CreateNode(ctx, &graph.Node{Labels: []string{"City"}, Properties: graph.Properties{"name": "A"}})
+	require.Error(t, err)
+	require.Error(t, client.CreateConstraint(ctx, "City", "country", graph.ConstraintExists))
+	constraints, err := client.ListConstraints(ctx)
+	require.NoError(t, err)
+	require.Len(t, constraints, 1)
+	require.NoError(t, client.DropConstraint(ctx, "City", "name", graph.ConstraintUnique))
+	_, err = client.CreateNode(ctx, &graph.Node{Labels: []string{"City"}, Properties: graph.Properties{"name": "A"}})
+	require.NoError(t, err)
+	// The names are no longer unique, nor the countries set.
+	require.Error(t, client.CreateConstraint(ctx, "City", "name", graph.ConstraintNodeKey))
+	require.Error(t, client.CreateConstraint(ctx, "City", "country", graph.ConstraintNodeKey))
+	require.Error(t, client.CreateConstraint(ctx, "City", "name", graph.PropertyTypeConstraint("STRING")))
+
+	require.NoError(t, client.CreateNodeIndex(ctx, "City", []string{"name"}))
+	require.NoError(t, client.CreateFullTextIndex(ctx, "city_names", []string{"City"}, []string{"name"}))
+	indexes, err := client.ListIndexes(ctx)
+	require.NoError(t, err)
+	require.Len(t, indexes, 2)
+
+	scored, err := client.FullTextSearch(ctx, "city_names", "b c", 1)
+	require.NoError(t, err)
+	require.Len(t, scored, 1)
+	require.Equal(t, "B", scored[0].Node.Properties["name"])
+	_, err = client.FullTextSearch(ctx, "missing", "b", 0)
+	require.Error(t, err)
+}
+
+func TestAlgorithms(t *testing.T) {
+	ctx := context.Background()
+	client, nodes := setupCities(t)
+	a, c := nodes[0], nodes[2]
+
+	paths, err := client.ShortestPath(ctx, a.ID, c.ID, nil)
+	require.NoError(t, err)
+	require.Len(t, paths, 1)
+	require.Len(t, paths[0].Edges, 1)
+
+	paths, err = client.ShortestPath(ctx, a.ID, c.ID, map[string]any{"relationshipWeightProperty": "km"})
+	require.NoError(t, err)
+	require.Len(t, paths, 1)
+	require.Len(t, paths[0].Edges, 2)
+
+	paths, err = client.ShortestPath(ctx, c.ID, a.ID, map[string]any{"direction": "OUTGOING"})
+	require.NoError(t, err)
+	require.Empty(t, paths)
+
+	components, err := client.ConnectedComponents(ctx, nil)
+	require.NoError(t, err)
+	require.Equal(t, map[string]string{a.ID: a.ID, nodes[1].ID: a.ID, c.ID: a.ID}, components)
+
+	ranks, err := client.PageRank(ctx, nil)
+	require.NoError(t, err)
+	require.Greater(t, ranks[c.ID], ranks[a.ID])
+
+	// C is on the shortest paths from A and B to D.
+	d, err := client.CreateNode(ctx, &graph.Node{Labels: []string{"City"}})
+	require.NoError(t, err)
+	_, err = client.CreateEdge(ctx, &graph.Edge{Label: "ROAD", SourceNodeID: c.ID, TargetNodeID: d.ID})
+	require.NoError(t, err)
+	scores, err := client.BetweennessCentrality(ctx, nil)
+	require.NoError(t, err)
+	require.Equal(t, map[string]float64{a.ID: 0, nodes[1].ID: 0, c.ID: 2, d.ID: 0}, scores)
+}
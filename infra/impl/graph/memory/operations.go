@@ -0,0 +1,673 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"github.com/me2seeks/forge/infra/contract/graph"
+)
+
+func (c *memoryClient) CreateNode(ctx context.Context, node *graph.Node) (*graph.Node, error) {
+	nodes, err := c.CreateNodes(ctx, []*graph.Node{node})
+	if err != nil {
+		return nil, err
+	}
+	return nodes[0], nil
+}
+
+func (c *memoryClient) CreateNodes(ctx context.Context, nodes []*graph.Node) ([]*graph.Node, error) {
+	if len(nodes) == 0 {
+		return nil, nil
+	}
+	var created []*graph.Node
+	err := c.exec.write(ctx, func(s *store) error {
+		var err error
+		created, err = s.createNodes(nodes)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return created, nil
+}
+
+func (s *store) createNodes(nodes []*graph.Node) ([]*graph.Node, error) {
+	created := make([]*graph.Node, len(nodes))
+	for i, node := range nodes {
+		stored := &graph.Node{ID: s.newID(), Labels: append([]string{}, node.Labels...), Properties: newProperties(node.Properties)}
+		if err := s.putNode(stored); err != nil {
+			return nil, err
+		}
+		created[i] = cloneNode(stored)
+	}
+	return created, nil
+}
+
+// GetNode returns the node, or nil if there is none with this ID.
+func (c *memoryClient) GetNode(ctx context.Context, nodeID string) (*graph.Node, error) {
+	var node *graph.Node
+	err := c.exec.read(ctx, func(s *store) error {
+		node = cloneNode(s.nodes[nodeID])
+		return nil
+	})
+	return node, err
+}
+
+// UpdateNode sets properties on the node; setting a property to nil removes it.
+func (c *memoryClient) UpdateNode(ctx context.Context, nodeID string, properties graph.Properties) error {
+	return c.exec.write(ctx, func(s *store) error {
+		node, ok := s.nodes[nodeID]
+		if !ok {
+			return nil
+		}
+		return s.putNode(&graph.Node{ID: node.ID, Labels: node.Labels, Properties: setProperties(node.Properties, properties)})
+	})
+}
+
+// DeleteNode deletes the node and its edges.
+func (c *memoryClient) DeleteNode(ctx context.Context, nodeID string) error {
+	return c.exec.write(ctx, func(s *store) error {
+		s.deleteNode(nodeID)
+		return nil
+	})
+}
+
+func (c *memoryClient) MergeNode(ctx context.Context, node *graph.Node, matchKeys []string) (*graph.Node, error) {
+	if len(node.Labels) == 0 {
+		return nil, fmt.Errorf("merge node: no label")
+	}
+	if len(matchKeys) == 0 {
+		return nil, fmt.Errorf("merge node: no match key")
+	}
+	selector := &graph.NodeSelector{Labels: node.Labels, Properties: graph.Properties{}}
+	for _, key := range matchKeys {
+		value, ok := node.Properties[key]
+		if !ok {
+			return nil, fmt.Errorf("merge node: match key %s is not a property of the node", key)
+		}
+		selector.Properties[key] = value
+	}
+
+	var merged *graph.Node
+	err := c.exec.write(ctx, func(s *store) error {
+		matches := s.selectNodes(selector)
+		switch len(matches) {
+		case 0:
+			created, err := s.createNodes([]*graph.Node{node})
+			if err != nil {
+				return err
+			}
+			merged = created[0]
+			return nil
+		case 1:
+			stored := &graph.Node{ID: matches[0].ID, Labels: matches[0].Labels, Properties: setProperties(matches[0].Properties, node.Properties)}
+			if err := s.putNode(stored); err != nil {
+				return err
+			}
+			merged = cloneNode(stored)
+			return nil
+		default:
+			return fmt.Errorf("merge node: %d nodes match", len(matches))
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+	return merged, nil
+}
+
+// CreateEdge creates edge between the nodes selected by its selectors if both
+// are set, or by its node IDs otherwise, like the neo4j client: one edge per
+// pair of selected nodes, of which it returns the first, or nil if there is
+// none.
+func (c *memoryClient) CreateEdge(ctx context.Context, edge *graph.Edge) (*graph.Edge, error) {
+	var created *graph.Edge
+	err := c.exec.write(ctx, func(s *store) error {
+		sources, targets := s.endpoint(edge.SourceNodeID, nil), s.endpoint(edge.TargetNodeID, nil)
+		if edge.SourceNodeSelector != nil && edge.TargetNodeSelector != nil {
+			sources, targets = s.selectNodes(edge.SourceNodeSelector), s.selectNodes(edge.TargetNodeSelector)
+		}
+		for _, source := range sources {
+			for _, target := range targets {
+				e := s.createEdge(edge.Label, source.ID, target.ID, newProperties(edge.Properties))
+				if created == nil {
+					created = e
+				}
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return created, nil
+}
+
+// endpoint returns the nodes selected by selector if set, or the node with ID
+// nodeID.
+func (s *store) endpoint(nodeID string, selector *graph.NodeSelector) []*graph.Node {
+	if selector != nil {
+		return s.selectNodes(selector)
+	}
+	if node, ok := s.nodes[nodeID]; ok {
+		return []*graph.Node{node}
+	}
+	return nil
+}
+
+// createEdge stores a new edge between existing nodes and returns a copy.
+func (s *store) createEdge(label, sourceID, targetID string, props graph.Properties) *graph.Edge {
+	stored := &graph.Edge{ID: s.newID(), Label: label, SourceNodeID: sourceID, TargetNodeID: targetID, Properties: props}
+	s.putEdge(stored)
+	return cloneEdge(stored)
+}
+
+func (c *memoryClient) CreateEdges(ctx context.Context, edges []*graph.Edge) ([]*graph.Edge, error) {
+	if len(edges) == 0 {
+		return nil, nil
+	}
+	var created []*graph.Edge
+	err := c.exec.write(ctx, func(s *store) error {
+		var err error
+		created, err = s.createEdges(edges)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return created, nil
+}
+
+func (s *store) createEdges(edges []*graph.Edge) ([]*graph.Edge, error) {
+	created := make([]*graph.Edge, len(edges))
+	for i, edge := range edges {
+		if edge.SourceNodeSelector != nil || edge.TargetNodeSelector != nil {
+			return nil, fmt.Errorf("create edges: edge %d uses node selectors, batches only support node IDs", i)
+		}
+		_, okSource := s.nodes[edge.SourceNodeID]
+		_, okTarget := s.nodes[edge.TargetNodeID]
+		if !okSource || !okTarget {
+			return nil, fmt.Errorf("create edges: endpoint of edge %d not found", i)
+		}
+		created[i] = s.createEdge(edge.Label, edge.SourceNodeID, edge.TargetNodeID, newProperties(edge.Properties))
+	}
+	return created, nil
+}
+
+func (c *memoryClient) MergeEdge(ctx context.Context, edge *graph.Edge, onCreate, onMatch graph.Properties) (*graph.Edge, error) {
+	if edge.Label == "" {
+		return nil, fmt.Errorf("merge edge: no label")
+	}
+	if edge.SourceNodeSelector == nil && edge.SourceNodeID == "" {
+		return nil, fmt.Errorf("no node ID or selector for endpoint a")
+	}
+	if edge.TargetNodeSelector == nil && edge.TargetNodeID == "" {
+		return nil, fmt.Errorf("no node ID or selector for endpoint b")
+	}
+
+	var merged *graph.Edge
+	err := c.exec.write(ctx, func(s *store) error {
+		sources, targets := s.endpoint(edge.SourceNodeID, edge.SourceNodeSelector), s.endpoint(edge.TargetNodeID, edge.TargetNodeSelector)
+		if pairs := len(sources) * len(targets); pairs == 0 {
+			return nil
+		} else if pairs > 1 {
+			return fmt.Errorf("merge edge: endpoints match %d pairs of nodes", pairs)
+		}
+
+		source, target := sources[0], targets[0]
+		for _, id := range s.out[source.ID] {
+			if e := s.edges[id]; e.TargetNodeID == target.ID && edgeMatches(e, []string{edge.Label}, edge.Properties) {
+				stored := *e
+				stored.Properties = setProperties(e.Properties, onMatch)
+				s.putEdge(&stored)
+				merged = cloneEdge(&stored)
+				return nil
+			}
+		}
+		merged = s.createEdge(edge.Label, source.ID, target.ID, setProperties(newProperties(edge.Properties), onCreate))
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return merged, nil
+}
+
+// GetEdge returns the edge, or nil if there is none with this ID.
+func (c *memoryClient) GetEdge(ctx context.Context, edgeID string) (*graph.Edge, error) {
+	var edge *graph.Edge
+	err := c.exec.read(ctx, func(s *store) error {
+		edge = cloneEdge(s.edges[edgeID])
+		return nil
+	})
+	return edge, err
+}
+
+// UpdateEdge sets properties on the edge; setting a property to nil removes it.
+func (c *memoryClient) UpdateEdge(ctx context.Context, edgeID string, properties graph.Properties) error {
+	return c.exec.write(ctx, func(s *store) error {
+		if edge, ok := s.edges[edgeID]; ok {
+			stored := *edge
+			stored.Properties = setProperties(edge.Properties, properties)
+			s.putEdge(&stored)
+		}
+		return nil
+	})
+}
+
+func (c *memoryClient) DeleteEdge(ctx context.Context, edgeID string) error {
+	return c.exec.write(ctx, func(s *store) error {
+		s.deleteEdge(edgeID)
+		return nil
+	})
+}
+
+// GetNeighbors returns the ends of the walks from the node and their edges in
+// order of discovery, walking like a variable-length pattern of a query.
+func (c *memoryClient) GetNeighbors(ctx context.Context, nodeID string, opts ...graph.NeighborOpt) (*graph.Neighborhood, error) {
+	o := graph.NewNeighborOptions(opts...)
+	neighborhood := &graph.Neighborhood{}
+	err := c.exec.read(ctx, func(s *store) error {
+		start := s.nodes[nodeID]
+		if start == nil {
+			return nil
+		}
+		minHops := 1
+		e := &graph.EdgePattern{Labels: o.RelationshipTypes, Direction: o.Direction, MinHops: &minHops, MaxHops: &o.Depth}
+		nodes, edges := make(map[string]bool), make(map[string]bool)
+		for _, w := range s.walks(start, e) {
+			end := w.nodes[len(w.nodes)-1]
+			if end == start {
+				continue
+			}
+			if !nodes[end.ID] {
+				nodes[end.ID] = true
+				neighborhood.Nodes = append(neighborhood.Nodes, cloneNode(end))
+			}
+			for _, edge := range w.edges {
+				if !edges[edge.ID] {
+					edges[edge.ID] = true
+					neighborhood.Edges = append(neighborhood.Edges, cloneEdge(edge))
+				}
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return neighborhood, nil
+}
+
+// Traverse copies the subgraph within the depth of spec from the node, and
+// runs the traversal over the copy once the store is unlocked, so that the
+// functions of spec may use the client.
+func (c *memoryClient) Traverse(ctx context.Context, startNodeID string, spec graph.TraversalSpec) ([]*graph.Path, error) {
+	o := spec.NeighborOptions()
+	var nodes []*graph.Node
+	var edges []*graph.Edge
+	err := c.exec.read(ctx, func(s *store) error {
+		start := s.nodes[startNodeID]
+		if start == nil {
+			return nil
+		}
+		within := map[string]bool{start.ID: true}
+		nodes = append(nodes, cloneNode(start))
+		frontier := []*graph.Node{start}
+		for depth := 0; depth < o.Depth && len(frontier) > 0; depth++ {
+			var next []*graph.Node
+			for _, node := range frontier {
+				for _, step := range s.steps(node, o.Direction) {
+					if within[step.node.ID] || !edgeMatches(step.edge, o.RelationshipTypes, nil) {
+						continue
+					}
+					within[step.node.ID] = true
+					nodes = append(nodes, cloneNode(step.node))
+					next = append(next, step.node)
+				}
+			}
+			frontier = next
+		}
+		for _, node := range nodes {
+			for _, id := range s.out[node.ID] {
+				edge := s.edges[id]
+				if within[edge.TargetNodeID] && edgeMatches(edge, o.RelationshipTypes, nil) {
+					edges = append(edges, cloneEdge(edge))
+				}
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return graph.TraverseSubgraph(startNodeID, nodes, edges, spec)
+}
+
+func (c *memoryClient) Query(ctx context.Context, query *graph.Query) (*graph.QueryResult, error) {
+	var records []graph.Record
+	err := c.exec.read(ctx, func(s *store) error {
+		var err error
+		records, err = s.query(query)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &graph.QueryResult{Records: records}, nil
+}
+
+// RawQuery isn't supported, as there is no query language.
+func (c *memoryClient) RawQuery(ctx context.Context, query string, params map[string]any) (*graph.QueryResult, error) {
+	return nil, unsupported("raw query")
+}
+
+// BatchQuery isn't supported, as there is no query language.
+func (c *memoryClient) BatchQuery(ctx context.Context, statement string, rows []map[string]any, params map[string]any) (*graph.QueryResult, error) {
+	return nil, unsupported("batch query")
+}
+
+// QueryStream runs the query like Query and iterates over its records.
+func (c *memoryClient) QueryStream(ctx context.Context, query *graph.Query) (graph.RecordIterator, error) {
+	result, err := c.Query(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	return &recordIterator{records: result.Records}, nil
+}
+
+// ExportGraph writes the entities of the records of QueryStream.
+func (c *memoryClient) ExportGraph(ctx context.Context, w io.Writer, format graph.ExportFormat, query *graph.Query) error {
+	return graph.Export(ctx, c, w, format, query)
+}
+
+// recordIterator iterates over records already computed.
+type recordIterator struct {
+	records []graph.Record
+	record  graph.Record
+}
+
+func (it *recordIterator) Next(ctx context.Context) bool {
+	if len(it.records) == 0 {
+		it.record = nil
+		return false
+	}
+	it.record, it.records = it.records[0], it.records[1:]
+	return true
+}
+
+func (it *recordIterator) Record() graph.Record {
+	return it.record
+}
+
+func (it *recordIterator) Err() error {
+	return nil
+}
+
+func (it *recordIterator) Close(ctx context.Context) error {
+	it.records, it.record = nil, nil
+	return nil
+}
+
+func (c *memoryClient) FindNodes(ctx context.Context, query *graph.Query) ([]*graph.Node, error) {
+	result, err := c.Query(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	var nodes []*graph.Node
+	seen := make(map[string]struct{})
+	for _, record := range result.Records {
+		for _, entity := range record {
+			if node, ok := entity.(*graph.Node); ok {
+				if _, exists := seen[node.ID]; !exists {
+					nodes = append(nodes, node)
+					seen[node.ID] = struct{}{}
+				}
+			}
+		}
+	}
+	return nodes, nil
+}
+
+func (c *memoryClient) FindEdges(ctx context.Context, query *graph.Query) ([]*graph.Edge, error) {
+	result, err := c.Query(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	var edges []*graph.Edge
+	seen := make(map[string]struct{})
+	for _, record := range result.Records {
+		for _, entity := range record {
+			if edge, ok := entity.(*graph.Edge); ok {
+				if _, exists := seen[edge.ID]; !exists {
+					edges = append(edges, edge)
+					seen[edge.ID] = struct{}{}
+				}
+			}
+		}
+	}
+	return edges, nil
+}
+
+func (c *memoryClient) FindPaths(ctx context.Context, query *graph.Query) ([]*graph.Path, error) {
+	result, err := c.Query(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	var paths []*graph.Path
+	for _, record := range result.Records {
+		for _, entity := range record {
+			switch v := entity.(type) {
+			case *graph.Path:
+				paths = append(paths, v)
+			case []any:
+				for _, item := range v {
+					if path, ok := item.(*graph.Path); ok {
+						paths = append(paths, path)
+					}
+				}
+			}
+		}
+	}
+	return paths, nil
+}
+
+// Count counts the matches of the first alias of the query, like the neo4j
+// client.
+func (c *memoryClient) Count(ctx context.Context, query *graph.Query) (int64, error) {
+	var count int64
+	err := c.exec.read(ctx, func(s *store) error {
+		rows, err := s.evaluate(query)
+		if err != nil {
+			return err
+		}
+		var alias string
+		if aliases := matchAliases(query); len(aliases) > 0 {
+			alias = aliases[0]
+		}
+		_, count, err = targets(rows, alias)
+		return err
+	})
+	return count, err
+}
+
+// targets returns the distinct entities bound to alias in rows, and the
+// number of rows binding it, like count(alias). An empty alias counts all the
+// rows.
+func targets(rows []row, alias string) ([]any, int64, error) {
+	if alias == "" {
+		return nil, int64(len(rows)), nil
+	}
+	var entities []any
+	var count int64
+	seen := make(map[string]bool)
+	for _, r := range rows {
+		value, ok := r[alias]
+		if !ok {
+			return nil, 0, fmt.Errorf("memory: variable %s not defined", alias)
+		}
+		if value == nil {
+			continue
+		}
+		count++
+		items := []any{value}
+		if edges, ok := value.([]*graph.Edge); ok {
+			items = items[:0]
+			for _, edge := range edges {
+				items = append(items, edge)
+			}
+		}
+		for _, item := range items {
+			if key := valueKey(item); !seen[key] {
+				seen[key] = true
+				entities = append(entities, item)
+			}
+		}
+	}
+	return entities, count, nil
+}
+
+// targetAlias returns the alias changed by the ByQuery operations: the first
+// node alias of the query, or the edge alias of its first pattern if edges is
+// true, empty if there is none, like the neo4j client.
+func targetAlias(query *graph.Query, edges bool) string {
+	if len(query.Match) == 0 {
+		return ""
+	}
+	if !edges {
+		return orDefault(query.Match[0].Alias, defaultNodeAlias)
+	}
+	if query.Match[0].Edge != nil {
+		return query.Match[0].Edge.Alias
+	}
+	return ""
+}
+
+// byQuery runs change on the entities of the target alias of the query, see
+// targetAlias, and returns the count of its matches.
+func (c *memoryClient) byQuery(ctx context.Context, query *graph.Query, edges bool, change func(s *store, entity any) error) (int, error) {
+	var count int64
+	err := c.exec.write(ctx, func(s *store) error {
+		rows, err := s.evaluate(query)
+		if err != nil {
+			return err
+		}
+		entities, n, err := targets(rows, targetAlias(query, edges))
+		if err != nil {
+			return err
+		}
+		for _, entity := range entities {
+			if err := change(s, entity); err != nil {
+				return err
+			}
+		}
+		count = n
+		return nil
+	})
+	return int(count), err
+}
+
+// UpdateNodesByQuery sets properties on the nodes of the first alias of the
+// query.
+func (c *memoryClient) UpdateNodesByQuery(ctx context.Context, query *graph.Query, properties graph.Properties) (int, error) {
+	return c.byQuery(ctx, query, false, func(s *store, entity any) error {
+		node, ok := entity.(*graph.Node)
+		if !ok || len(properties) == 0 {
+			return nil
+		}
+		node = s.nodes[node.ID]
+		return s.putNode(&graph.Node{ID: node.ID, Labels: node.Labels, Properties: setProperties(node.Properties, properties)})
+	})
+}
+
+// UpdateEdgesByQuery sets properties on the edges of the edge alias of the
+// first pattern of the query.
+func (c *memoryClient) UpdateEdgesByQuery(ctx context.Context, query *graph.Query, properties graph.Properties) (int, error) {
+	return c.byQuery(ctx, query, true, func(s *store, entity any) error {
+		edge, ok := entity.(*graph.Edge)
+		if !ok || len(properties) == 0 {
+			return nil
+		}
+		stored := *s.edges[edge.ID]
+		stored.Properties = setProperties(stored.Properties, properties)
+		s.putEdge(&stored)
+		return nil
+	})
+}
+
+// DeleteNodesByQuery deletes the nodes of the first alias of the query and
+// their edges.
+func (c *memoryClient) DeleteNodesByQuery(ctx context.Context, query *graph.Query) (int, error) {
+	return c.byQuery(ctx, query, false, func(s *store, entity any) error {
+		if node, ok := entity.(*graph.Node); ok {
+			s.deleteNode(node.ID)
+		}
+		return nil
+	})
+}
+
+// DeleteEdgesByQuery deletes the edges of the edge alias of the first pattern
+// of the query.
+func (c *memoryClient) DeleteEdgesByQuery(ctx context.Context, query *graph.Query) (int, error) {
+	return c.byQuery(ctx, query, true, func(s *store, entity any) error {
+		if edge, ok := entity.(*graph.Edge); ok {
+			s.deleteEdge(edge.ID)
+		}
+		return nil
+	})
+}
+
+type bulkWriter struct {
+	nodes  []*graph.Node
+	edges  []*graph.Edge
+	client *memoryClient
+}
+
+func (c *memoryClient) NewBulkWriter() graph.BulkWriter {
+	return &bulkWriter{
+		client: c,
+	}
+}
+
+// ImportGraph imports in batches of the bulk writer, see Import.
+func (c *memoryClient) ImportGraph(ctx context.Context, nodes, edges io.Reader, opts ...graph.ImportOpt) (*graph.ImportResult, error) {
+	return graph.Import(ctx, c, nodes, edges, opts...)
+}
+
+func (b *bulkWriter) AddNode(ctx context.Context, node *graph.Node) error {
+	b.nodes = append(b.nodes, node)
+	return nil
+}
+
+func (b *bulkWriter) AddEdge(ctx context.Context, edge *graph.Edge) error {
+	b.edges = append(b.edges, edge)
+	return nil
+}
+
+// Close creates the added nodes, then edges, atomically.
+func (b *bulkWriter) Close(ctx context.Context) error {
+	if len(b.nodes) == 0 && len(b.edges) == 0 {
+		return nil
+	}
+	var nodes []*graph.Node
+	var edges []*graph.Edge
+	err := b.client.exec.write(ctx, func(s *store) error {
+		var err error
+		if nodes, err = s.createNodes(b.nodes); err != nil {
+			return err
+		}
+		edges, err = s.createEdges(b.edges)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	for i, node := range nodes {
+		b.nodes[i].ID = node.ID
+	}
+	for i, edge := range edges {
+		b.edges[i].ID = edge.ID
+	}
+	return nil
+}
@@ -0,0 +1,260 @@
+package memory
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+	"unicode"
+
+	"github.com/me2seeks/forge/infra/contract/graph"
+)
+
+// Indexes are only recorded for ListIndexes, as queries scan all the nodes.
+// Constraints are enforced on each write of a node with their label, and
+// full-text indexes are searched by scanning the nodes of their labels.
+
+const (
+	indexRange    = "RANGE"
+	indexFullText = "FULLTEXT"
+	indexOnline   = "ONLINE"
+)
+
+func (c *memoryClient) CreateNodeIndex(ctx context.Context, label string, properties []string) error {
+	return c.createIndex(ctx, graph.EntityNode, label, properties)
+}
+
+func (c *memoryClient) CreateEdgeIndex(ctx context.Context, label string, properties []string) error {
+	return c.createIndex(ctx, graph.EntityRelationship, label, properties)
+}
+
+func (c *memoryClient) DropNodeIndex(ctx context.Context, label string, properties []string) error {
+	return c.dropIndex(ctx, indexName(graph.EntityNode, label, properties))
+}
+
+func (c *memoryClient) DropEdgeIndex(ctx context.Context, label string, properties []string) error {
+	return c.dropIndex(ctx, indexName(graph.EntityRelationship, label, properties))
+}
+
+// indexName names the range indexes, e.g. index_node_Person_name.
+func indexName(entityType graph.EntityType, label string, properties []string) string {
+	return "index_" + strings.ToLower(string(entityType)) + "_" + label + "_" + strings.Join(properties, "_")
+}
+
+func (c *memoryClient) createIndex(ctx context.Context, entityType graph.EntityType, label string, properties []string) error {
+	if len(properties) == 0 {
+		return fmt.Errorf("index on %s needs properties", label)
+	}
+	return c.addIndex(ctx, &graph.IndexInfo{
+		Name:          indexName(entityType, label, properties),
+		Type:          indexRange,
+		EntityType:    entityType,
+		LabelsOrTypes: []string{label},
+		Properties:    slices.Clone(properties),
+		State:         indexOnline,
+	})
+}
+
+// addIndex adds index unless there is an index of the same name.
+func (c *memoryClient) addIndex(ctx context.Context, index *graph.IndexInfo) error {
+	return c.exec.write(ctx, func(s *store) error {
+		if !slices.ContainsFunc(s.indexes, func(other *graph.IndexInfo) bool { return other.Name == index.Name }) {
+			s.indexes = append(s.indexes, index)
+		}
+		return nil
+	})
+}
+
+func (c *memoryClient) dropIndex(ctx context.Context, name string) error {
+	return c.exec.write(ctx, func(s *store) error {
+		s.indexes = slices.DeleteFunc(slices.Clone(s.indexes), func(index *graph.IndexInfo) bool { return index.Name == name })
+		return nil
+	})
+}
+
+// CreateConstraint creates the constraint on the nodes of label, failing if
+// some of them violate it.
+func (c *memoryClient) CreateConstraint(ctx context.Context, label, property string, constraintType graph.ConstraintType) error {
+	if constraintType != graph.ConstraintUnique && constraintType != graph.ConstraintExists && constraintType != graph.ConstraintNodeKey {
+		return unsupported("constraint type " + string(constraintType))
+	}
+	constraint := &graph.ConstraintInfo{
+		Name:          constraintName(label, property, constraintType),
+		Type:          constraintType,
+		EntityType:    graph.EntityNode,
+		LabelsOrTypes: []string{label},
+		Properties:    []string{property},
+	}
+	return c.exec.write(ctx, func(s *store) error {
+		if slices.ContainsFunc(s.constraints, func(other *graph.ConstraintInfo) bool { return other.Name == constraint.Name }) {
+			return nil
+		}
+		s.constraints = append(s.constraints, constraint)
+		for _, id := range s.nodeIDs() {
+			if err := s.checkConstraints(s.nodes[id]); err != nil {
+				s.constraints = s.constraints[:len(s.constraints)-1]
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func (c *memoryClient) DropConstraint(ctx context.Context, label, property string, constraintType graph.ConstraintType) error {
+	name := constraintName(label, property, constraintType)
+	return c.exec.write(ctx, func(s *store) error {
+		s.constraints = slices.DeleteFunc(slices.Clone(s.constraints), func(constraint *graph.ConstraintInfo) bool { return constraint.Name == name })
+		return nil
+	})
+}
+
+// constraintName names the constraints, e.g. constraint_Person_email_unique.
+func constraintName(label, property string, constraintType graph.ConstraintType) string {
+	return "constraint_" + label + "_" + property + "_" + strings.ToLower(string(constraintType))
+}
+
+func (c *memoryClient) CreateFullTextIndex(ctx context.Context, name string, labels, properties []string) error {
+	if len(labels) == 0 || len(properties) == 0 {
+		return fmt.Errorf("full-text index %s needs labels and properties", name)
+	}
+	return c.addIndex(ctx, &graph.IndexInfo{
+		Name:          name,
+		Type:          indexFullText,
+		EntityType:    graph.EntityNode,
+		LabelsOrTypes: slices.Clone(labels),
+		Properties:    slices.Clone(properties),
+		State:         indexOnline,
+	})
+}
+
+func (c *memoryClient) DropFullTextIndex(ctx context.Context, name string) error {
+	return c.dropIndex(ctx, name)
+}
+
+// FullTextSearch scores the nodes of the index by the number of occurrences of
+// the words of query in their indexed properties, ignoring case. query is a
+// list of words; the Lucene syntax of the neo4j client isn't supported.
+func (c *memoryClient) FullTextSearch(ctx context.Context, indexName, query string, limit int) ([]*graph.ScoredNode, error) {
+	terms := words(query)
+	var nodes []*graph.ScoredNode
+	err := c.exec.read(ctx, func(s *store) error {
+		i := slices.IndexFunc(s.indexes, func(index *graph.IndexInfo) bool {
+			return index.Name == indexName && index.Type == indexFullText
+		})
+		if i < 0 {
+			return fmt.Errorf("memory: no full-text index %s", indexName)
+		}
+		index := s.indexes[i]
+		for _, id := range s.nodeIDs() {
+			node := s.nodes[id]
+			if !slices.ContainsFunc(index.LabelsOrTypes, func(label string) bool { return slices.Contains(node.Labels, label) }) {
+				continue
+			}
+			score := 0
+			for _, property := range index.Properties {
+				for _, word := range words(text(node.Properties[property])) {
+					if slices.Contains(terms, word) {
+						score++
+					}
+				}
+			}
+			if score > 0 {
+				nodes = append(nodes, &graph.ScoredNode{Node: cloneNode(node), Score: float64(score)})
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	slices.SortStableFunc(nodes, func(a, b *graph.ScoredNode) int {
+		return cmp.Compare(b.Score, a.Score)
+	})
+	if limit > 0 && len(nodes) > limit {
+		nodes = nodes[:limit]
+	}
+	return nodes, nil
+}
+
+// words splits s into lower-case words.
+func words(s string) []string {
+	return strings.FieldsFunc(strings.ToLower(s), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+// text returns the text of a string property, or of a list of strings.
+func text(value any) string {
+	switch v := value.(type) {
+	case string:
+		return v
+	case []any:
+		var parts []string
+		for _, item := range v {
+			if s, ok := item.(string); ok {
+				parts = append(parts, s)
+			}
+		}
+		return strings.Join(parts, " ")
+	default:
+		return ""
+	}
+}
+
+func (c *memoryClient) ListLabels(ctx context.Context) ([]string, error) {
+	var labels []string
+	err := c.exec.read(ctx, func(s *store) error {
+		set := make(map[string]struct{})
+		for _, node := range s.nodes {
+			for _, label := range node.Labels {
+				set[label] = struct{}{}
+			}
+		}
+		labels = slices.Sorted(maps.Keys(set))
+		return nil
+	})
+	return labels, err
+}
+
+func (c *memoryClient) ListRelationshipTypes(ctx context.Context) ([]string, error) {
+	var types []string
+	err := c.exec.read(ctx, func(s *store) error {
+		set := make(map[string]struct{})
+		for _, edge := range s.edges {
+			set[edge.Label] = struct{}{}
+		}
+		types = slices.Sorted(maps.Keys(set))
+		return nil
+	})
+	return types, err
+}
+
+func (c *memoryClient) ListIndexes(ctx context.Context) ([]*graph.IndexInfo, error) {
+	var indexes []*graph.IndexInfo
+	err := c.exec.read(ctx, func(s *store) error {
+		for _, index := range s.indexes {
+			info := *index
+			info.LabelsOrTypes, info.Properties = slices.Clone(index.LabelsOrTypes), slices.Clone(index.Properties)
+			indexes = append(indexes, &info)
+		}
+		return nil
+	})
+	slices.SortFunc(indexes, func(a, b *graph.IndexInfo) int { return strings.Compare(a.Name, b.Name) })
+	return indexes, err
+}
+
+func (c *memoryClient) ListConstraints(ctx context.Context) ([]*graph.ConstraintInfo, error) {
+	var constraints []*graph.ConstraintInfo
+	err := c.exec.read(ctx, func(s *store) error {
+		for _, constraint := range s.constraints {
+			info := *constraint
+			info.LabelsOrTypes, info.Properties = slices.Clone(constraint.LabelsOrTypes), slices.Clone(constraint.Properties)
+			constraints = append(constraints, &info)
+		}
+		return nil
+	})
+	slices.SortFunc(constraints, func(a, b *graph.ConstraintInfo) int { return strings.Compare(a.Name, b.Name) })
+	return constraints, err
+}
@@ -0,0 +1,309 @@
+package memory
+
+import (
+	"cmp"
+	"fmt"
+	"maps"
+	"reflect"
+	"slices"
+	"strconv"
+
+	"github.com/me2seeks/forge/infra/contract/graph"
+)
+
+// store holds the entities and schema of a database. Stored entities are
+// never changed in place but replaced, so they can be returned to the readers
+// of a clone without copying the whole store.
+type store struct {
+	nodes map[string]*graph.Node
+	edges map[string]*graph.Edge
+	// out and in are the IDs of the edges leaving and entering each node, in
+	// order of creation.
+	out, in map[string][]string
+	lastID  int64
+	// version counts the writes, for transactions to detect conflicts.
+	version int64
+
+	indexes     []*graph.IndexInfo
+	constraints []*graph.ConstraintInfo
+
+	// undo reverts the changes of the write in progress, latest last.
+	undo []func()
+}
+
+func newStore() *store {
+	return &store{
+		nodes: make(map[string]*graph.Node),
+		edges: make(map[string]*graph.Edge),
+		out:   make(map[string][]string),
+		in:    make(map[string][]string),
+	}
+}
+
+// clone returns a snapshot of s which can be written independently.
+func (s *store) clone() *store {
+	c := &store{
+		nodes:       maps.Clone(s.nodes),
+		edges:       maps.Clone(s.edges),
+		out:         make(map[string][]string, len(s.out)),
+		in:          make(map[string][]string, len(s.in)),
+		lastID:      s.lastID,
+		version:     s.version,
+		indexes:     slices.Clone(s.indexes),
+		constraints: slices.Clone(s.constraints),
+	}
+	for id, edges := range s.out {
+		c.out[id] = slices.Clone(edges)
+	}
+	for id, edges := range s.in {
+		c.in[id] = slices.Clone(edges)
+	}
+	return c
+}
+
+// apply runs work as a write, reverting its changes if it fails.
+func (s *store) apply(work func(s *store) error) error {
+	s.undo = s.undo[:0]
+	err := work(s)
+	if err != nil {
+		for i := len(s.undo) - 1; i >= 0; i-- {
+			s.undo[i]()
+		}
+	} else {
+		s.version++
+	}
+	s.undo = s.undo[:0]
+	return err
+}
+
+func (s *store) newID() string {
+	s.lastID++
+	return strconv.FormatInt(s.lastID, 10)
+}
+
+// nodeIDs returns the IDs of the nodes in order of creation.
+func (s *store) nodeIDs() []string {
+	return sortedIDs(maps.Keys(s.nodes))
+}
+
+// edgeIDs returns the IDs of the edges in order of creation.
+func (s *store) edgeIDs() []string {
+	return sortedIDs(maps.Keys(s.edges))
+}
+
+func sortedIDs(seq func(yield func(string) bool)) []string {
+	ids := slices.Collect(seq)
+	slices.SortFunc(ids, compareIDs)
+	return ids
+}
+
+// compareIDs orders the decimal IDs of the store numerically.
+func compareIDs(a, b string) int {
+	return cmp.Or(cmp.Compare(len(a), len(b)), cmp.Compare(a, b))
+}
+
+// putNode stores node, replacing the node with the same ID, if it satisfies
+// the constraints.
+func (s *store) putNode(node *graph.Node) error {
+	if err := s.checkConstraints(node); err != nil {
+		return err
+	}
+	old := s.nodes[node.ID]
+	s.nodes[node.ID] = node
+	s.undo = append(s.undo, func() {
+		if old == nil {
+			delete(s.nodes, node.ID)
+		} else {
+			s.nodes[node.ID] = old
+		}
+	})
+	return nil
+}
+
+// deleteNode deletes the node and its edges.
+func (s *store) deleteNode(id string) {
+	old, ok := s.nodes[id]
+	if !ok {
+		return
+	}
+	for _, edgeID := range slices.Concat(s.out[id], s.in[id]) {
+		s.deleteEdge(edgeID)
+	}
+	delete(s.nodes, id)
+	s.undo = append(s.undo, func() {
+		s.nodes[id] = old
+	})
+}
+
+// putEdge stores edge, replacing the edge with the same ID, whose endpoints
+// must be the same.
+func (s *store) putEdge(edge *graph.Edge) {
+	old := s.edges[edge.ID]
+	s.edges[edge.ID] = edge
+	if old != nil {
+		s.undo = append(s.undo, func() {
+			s.edges[edge.ID] = old
+		})
+		return
+	}
+	out, in := s.out[edge.SourceNodeID], s.in[edge.TargetNodeID]
+	s.out[edge.SourceNodeID] = append(out, edge.ID)
+	s.in[edge.TargetNodeID] = append(s.in[edge.TargetNodeID], edge.ID)
+	s.undo = append(s.undo, func() {
+		delete(s.edges, edge.ID)
+		s.out[edge.SourceNodeID], s.in[edge.TargetNodeID] = out, in
+	})
+}
+
+func (s *store) deleteEdge(id string) {
+	old, ok := s.edges[id]
+	if !ok {
+		return
+	}
+	out, in := s.out[old.SourceNodeID], s.in[old.TargetNodeID]
+	delete(s.edges, id)
+	// The adjacency lists are copied rather than changed in place, for undo to
+	// restore them.
+	s.out[old.SourceNodeID] = without(out, id)
+	s.in[old.TargetNodeID] = without(s.in[old.TargetNodeID], id)
+	s.undo = append(s.undo, func() {
+		s.edges[id] = old
+		s.out[old.SourceNodeID], s.in[old.TargetNodeID] = out, in
+	})
+}
+
+func without(ids []string, id string) []string {
+	kept := make([]string, 0, len(ids))
+	for _, other := range ids {
+		if other != id {
+			kept = append(kept, other)
+		}
+	}
+	return kept
+}
+
+// checkConstraints returns an error if node violates a constraint of its
+// labels. Unique constraints are checked by scanning the nodes.
+func (s *store) checkConstraints(node *graph.Node) error {
+	for _, constraint := range s.constraints {
+		label, property := constraint.LabelsOrTypes[0], constraint.Properties[0]
+		if !slices.Contains(node.Labels, label) {
+			continue
+		}
+		value, ok := node.Properties[property]
+		// A node key is both required and unique.
+		required := constraint.Type == graph.ConstraintExists || constraint.Type == graph.ConstraintNodeKey
+		unique := constraint.Type == graph.ConstraintUnique || constraint.Type == graph.ConstraintNodeKey
+		if !ok && required {
+			return fmt.Errorf("memory: node %s has no property %s, required by constraint %s", node.ID, property, constraint.Name)
+		}
+		if ok && unique {
+			for _, other := range s.nodes {
+				if other.ID != node.ID && slices.Contains(other.Labels, label) && equal(other.Properties[property], value) {
+					return fmt.Errorf("memory: node %s has the same %s as node %s, violating constraint %s", node.ID, property, other.ID, constraint.Name)
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// hasLabels reports whether node has all of labels.
+func hasLabels(node *graph.Node, labels []string) bool {
+	for _, label := range labels {
+		if !slices.Contains(node.Labels, label) {
+			return false
+		}
+	}
+	return true
+}
+
+// hasProperties reports whether props has all the properties of want.
+func hasProperties(props, want graph.Properties) bool {
+	for key, value := range want {
+		if !equal(props[key], value) {
+			return false
+		}
+	}
+	return true
+}
+
+// selectNodes returns the nodes having the labels and properties of selector.
+func (s *store) selectNodes(selector *graph.NodeSelector) []*graph.Node {
+	var nodes []*graph.Node
+	for _, id := range s.nodeIDs() {
+		node := s.nodes[id]
+		if hasLabels(node, selector.Labels) && hasProperties(node.Properties, selector.Properties) {
+			nodes = append(nodes, node)
+		}
+	}
+	return nodes
+}
+
+// setProperties returns props with the properties of set, removing those set
+// to nil like SET n += $props.
+func setProperties(props, set graph.Properties) graph.Properties {
+	merged := maps.Clone(props)
+	if merged == nil {
+		merged = graph.Properties{}
+	}
+	for key, value := range set {
+		if value == nil {
+			delete(merged, key)
+		} else {
+			merged[key] = normalize(value)
+		}
+	}
+	return merged
+}
+
+// newProperties returns a copy of props to store, like SET n = $props.
+func newProperties(props graph.Properties) graph.Properties {
+	return setProperties(nil, props)
+}
+
+// normalize converts a property value to the types the neo4j driver returns:
+// int64 for integers, float64 for floats and []any for lists.
+func normalize(value any) any {
+	v := reflect.ValueOf(value)
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return v.Int()
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return int64(v.Uint())
+	case reflect.Float32, reflect.Float64:
+		return v.Float()
+	case reflect.Slice, reflect.Array:
+		if v.Type().Elem().Kind() == reflect.Uint8 {
+			return value
+		}
+		list := make([]any, v.Len())
+		for i := range list {
+			list[i] = normalize(v.Index(i).Interface())
+		}
+		return list
+	default:
+		return value
+	}
+}
+
+// cloneNode returns a copy of node for callers to change.
+func cloneNode(node *graph.Node) *graph.Node {
+	if node == nil {
+		return nil
+	}
+	return &graph.Node{ID: node.ID, Labels: slices.Clone(node.Labels), Properties: maps.Clone(node.Properties)}
+}
+
+func cloneEdge(edge *graph.Edge) *graph.Edge {
+	if edge == nil {
+		return nil
+	}
+	return &graph.Edge{
+		ID:           edge.ID,
+		Label:        edge.Label,
+		SourceNodeID: edge.SourceNodeID,
+		TargetNodeID: edge.TargetNodeID,
+		Properties:   maps.Clone(edge.Properties),
+	}
+}
@@ -0,0 +1,86 @@
+package nebula
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/me2seeks/forge/infra/contract/graph"
+	"github.com/me2seeks/forge/infra/impl/graph/internal"
+)
+
+// defaultMaxDepth bounds the search of ShortestPath unless config sets
+// "maxDepth".
+const defaultMaxDepth = 10
+
+// ShortestPath finds the shortest paths between two nodes with FIND SHORTEST
+// PATH, up to config["maxDepth"] (10 by default) steps, returning one, or all
+// of them up to config["limit"] (100 by default) if config["all"] is true.
+// config may also set "relationshipTypes" and "direction" (OUTGOING, INCOMING
+// or BOTH, the default). Weighted paths aren't supported.
+func (c *nebulaClient) ShortestPath(ctx context.Context, sourceNodeID, targetNodeID string, config map[string]any) ([]*graph.Path, error) {
+	if internal.ConfigString(config, "relationshipWeightProperty") != "" {
+		return nil, unsupported("weighted shortest path")
+	}
+	if err := graph.ValidateNames(internal.ConfigStrings(config, "relationshipTypes")...); err != nil {
+		return nil, err
+	}
+	limit := 1
+	if all, _ := config["all"].(bool); all {
+		limit = internal.ConfigIntOr(config, "limit", 100)
+	}
+
+	res, err := c.exec(ctx, buildShortestPathNGQL(sourceNodeID, targetNodeID, config))
+	if err != nil {
+		return nil, err
+	}
+	// Each row holds the nodes and edges of a path, in a column each.
+	cols := []column{{name: "nodes", kind: kindNodes}, {name: "edges", kind: kindEdges}}
+	var paths []*graph.Path
+	for _, record := range decodeRecords(res, cols) {
+		if len(paths) == limit {
+			break
+		}
+		nodes, _ := record["nodes"].([]*graph.Node)
+		edges, _ := record["edges"].([]*graph.Edge)
+		paths = append(paths, &graph.Path{Nodes: nodes, Edges: edges})
+	}
+	return paths, nil
+}
+
+// buildShortestPathNGQL returns the statement of ShortestPath, returning the
+// nodes and edges of the paths with their properties.
+func buildShortestPathNGQL(sourceNodeID, targetNodeID string, config map[string]any) string {
+	over := "*"
+	if types := internal.ConfigStrings(config, "relationshipTypes"); len(types) > 0 {
+		quoted := make([]string, len(types))
+		for i, typ := range types {
+			quoted[i] = quote(typ)
+		}
+		over = strings.Join(quoted, ", ")
+	}
+	switch strings.ToUpper(internal.ConfigString(config, "direction")) {
+	case "OUTGOING":
+	case "INCOMING":
+		over += " REVERSELY"
+	default:
+		over += " BIDIRECT"
+	}
+	return fmt.Sprintf("FIND SHORTEST PATH WITH PROP FROM %s TO %s OVER %s UPTO %d STEPS YIELD path AS p"+
+		" | YIELD nodes($-.p) AS `nodes`, relationships($-.p) AS `edges`",
+		quoteString(sourceNodeID), quoteString(targetNodeID), over, internal.ConfigIntOr(config, "maxDepth", defaultMaxDepth))
+}
+
+// PageRank isn't supported: Nebula's graph algorithms run on Spark, with
+// NebulaGraph Algorithm.
+func (c *nebulaClient) PageRank(ctx context.Context, config map[string]any) (map[string]float64, error) {
+	return nil, unsupported("page rank")
+}
+
+func (c *nebulaClient) ConnectedComponents(ctx context.Context, config map[string]any) (map[string]string, error) {
+	return nil, unsupported("connected components")
+}
+
+func (c *nebulaClient) BetweennessCentrality(ctx context.Context, config map[string]any) (map[string]float64, error) {
+	return nil, unsupported("betweenness centrality")
+}
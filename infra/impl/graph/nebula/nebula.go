@@ -0,0 +1,248 @@
+// Package nebula implements the graph contract on NebulaGraph through its
+// HTTP gateway, nebula-http-gateway, translating queries to nGQL. Nodes are
+// vertices whose labels are their tags, and edges are identified by their
+// type, endpoints and rank, see EdgeID.
+//
+// Nebula is schema-full: the tags and edge types, with their properties, must
+// exist before the vertices and edges using them are written, see Schema. A
+// property of a node is written to each of its tags declaring it. Vertex IDs
+// are strings, so spaces need a FIXED_STRING vid type, at least 26 bytes long
+// for the nodes created without ID, which get a ULID. Queries scanning the
+// vertices of a tag need an index on it, see CreateNodeIndex.
+//
+// The operations run in the space set by graph.WithDatabase, or the default
+// space of the client. Nebula has no transactions, constraints nor graph
+// algorithms besides shortest paths, and full-text search needs Elasticsearch
+// listeners, so the operations depending on them fail with an error wrapping
+// errors.ErrUnsupported.
+package nebula
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/me2seeks/forge/httpclient"
+	"github.com/me2seeks/forge/infra/contract/graph"
+	"github.com/me2seeks/forge/sonic"
+)
+
+// Paths of the gateway API.
+const (
+	connectPath = "/api-nebula/db/connect"
+	execPath    = "/api-nebula/db/exec"
+)
+
+// Error is an error response of the gateway, e.g. a statement rejected by
+// Nebula.
+type Error struct {
+	// Code is the gateway's error code, non-zero.
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("nebula: %s (code %d)", e.Message, e.Code)
+}
+
+type nebulaClient struct {
+	http     *httpclient.Client
+	endpoint string
+	space    string
+	login    loginRequest
+
+	mu sync.Mutex
+	// cookies hold the session of the gateway, opened by connect.
+	cookies []*http.Cookie
+	// tags caches the properties of the tags of each space, see tagFields.
+	tags map[tagKey][]field
+}
+
+type tagKey struct {
+	space, tag string
+}
+
+// loginRequest opens a session of the gateway on a graph service.
+type loginRequest struct {
+	Username string `json:"username"`
+	Password string `json:"password"`
+	Address  string `json:"address"`
+	Port     int    `json:"port"`
+}
+
+// Option is a function that configures the nebula client
+type Option func(*options)
+
+// options contains the configuration for the nebula client
+type options struct {
+	username    string
+	password    string
+	space       string
+	httpOptions []httpclient.Option
+}
+
+// WithBasicAuth sets the user the sessions are opened as, root with the
+// password nebula by default.
+func WithBasicAuth(username, password string) Option {
+	return func(o *options) {
+		o.username = username
+		o.password = password
+	}
+}
+
+// WithSpace sets the space the operations run in unless their context sets
+// another one with graph.WithDatabase.
+func WithSpace(space string) Option {
+	return func(o *options) {
+		o.space = space
+	}
+}
+
+// WithHTTPOptions configures the HTTP client, e.g. its timeout and retries.
+func WithHTTPOptions(opts ...httpclient.Option) Option {
+	return func(o *options) {
+		o.httpOptions = append(o.httpOptions, opts...)
+	}
+}
+
+// New creates a nebula client of the gateway at endpoint, e.g.
+// http://localhost:8080, opening a session on the graph service at address,
+// e.g. graphd:9669, as reached from the gateway.
+func New(ctx context.Context, endpoint, address string, opts ...Option) (graph.Client, error) {
+	o := &options{username: "root", password: "nebula"}
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	host, port, err := net.SplitHostPort(address)
+	if err != nil {
+		return nil, fmt.Errorf("nebula: invalid graph service address %s: %w", address, err)
+	}
+	portNum, err := strconv.Atoi(port)
+	if err != nil {
+		return nil, fmt.Errorf("nebula: invalid graph service port %s: %w", port, err)
+	}
+
+	c := &nebulaClient{
+		http:     httpclient.New(o.httpOptions...),
+		endpoint: strings.TrimSuffix(endpoint, "/"),
+		space:    o.space,
+		login:    loginRequest{Username: o.username, Password: o.password, Address: host, Port: portNum},
+		tags:     make(map[tagKey][]field),
+	}
+	if err := c.connect(ctx); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// connect opens a session of the gateway, replacing the current one.
+func (c *nebulaClient) connect(ctx context.Context) error {
+	resp, err := c.post(ctx, connectPath, c.login, nil)
+	if err != nil {
+		return err
+	}
+	c.mu.Lock()
+	c.cookies = resp.Cookies()
+	c.mu.Unlock()
+	return nil
+}
+
+// result is the result of a statement: its columns and a map of the values
+// of each row, see decodeRow.
+type result struct {
+	Headers []string         `json:"headers"`
+	Tables  []map[string]any `json:"tables"`
+}
+
+// exec runs stmt, one or more nGQL statements separated by semicolons, in
+// the space of ctx and returns the result of the last one.
+func (c *nebulaClient) exec(ctx context.Context, stmt string) (*result, error) {
+	space := c.spaceOf(ctx)
+	if space == "" {
+		return nil, fmt.Errorf("nebula: no space selected, see WithSpace")
+	}
+
+	res := &result{}
+	req := map[string]any{"gql": "USE " + quote(space) + "; " + stmt}
+	if _, err := c.post(ctx, execPath, req, res); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// spaceOf returns the space of the operations run with ctx.
+func (c *nebulaClient) spaceOf(ctx context.Context) string {
+	if space := graph.DatabaseFromContext(ctx); space != "" {
+		return space
+	}
+	return c.space
+}
+
+// post sends in to the gateway API at path, with the session cookies, and
+// decodes the data of the response into out, if not nil.
+func (c *nebulaClient) post(ctx context.Context, path string, in, out any) (*http.Response, error) {
+	buf, err := sonic.Marshal(in)
+	if err != nil {
+		return nil, fmt.Errorf("marshal request failed: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.endpoint+path, bytes.NewReader(buf))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/json")
+	c.mu.Lock()
+	for _, cookie := range c.cookies {
+		req.AddCookie(cookie)
+	}
+	c.mu.Unlock()
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("read response failed: %w", err)
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return nil, &Error{Code: resp.StatusCode, Message: http.StatusText(resp.StatusCode)}
+	}
+	var envelope struct {
+		Error
+		Data sonic.RawMessage `json:"data"`
+	}
+	if err := sonic.Unmarshal(body, &envelope); err != nil {
+		return nil, fmt.Errorf("unmarshal response failed: %w", err)
+	}
+	if envelope.Code != 0 {
+		return nil, &envelope.Error
+	}
+	if out != nil && len(envelope.Data) > 0 {
+		if err := sonic.Unmarshal(envelope.Data, out); err != nil {
+			return nil, fmt.Errorf("unmarshal response data failed: %w", err)
+		}
+	}
+	return resp, nil
+}
+
+// unsupported returns the error of an operation Nebula has no equivalent for.
+func unsupported(op string) error {
+	return fmt.Errorf("nebula: %s: %w", op, errors.ErrUnsupported)
+}
+
+// BeginTx fails, as Nebula has no transactions: each statement is applied on
+// its own, and the operations writing several entities aren't atomic.
+func (c *nebulaClient) BeginTx(ctx context.Context) (graph.Tx, error) {
+	return nil, unsupported("transactions")
+}
@@ -0,0 +1,321 @@
+package nebula
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/me2seeks/forge/infra/contract/graph"
+)
+
+func TestBuildQueryNGQL(t *testing.T) {
+	limit := 10
+	query := &graph.Query{
+		Match: []graph.Pattern{{
+			Alias:  "p",
+			Labels: []string{"Person"},
+			Edge: &graph.EdgePattern{
+				Alias:     "r",
+				Labels:    []string{"LIVES_IN"},
+				Direction: graph.DirectionOutgoing,
+				Node:      &graph.Pattern{Alias: "c", Labels: []string{"City"}, Properties: graph.Properties{"name": "Paris"}},
+			},
+		}},
+		Where: &graph.Where{
+			Filter: []graph.Condition{{Alias: "p", Property: "age", Operator: graph.OpGreaterThan, Value: 30}},
+			Should: []graph.Condition{
+				{Alias: "p", Property: "name", Operator: graph.OpContains, Value: "an"},
+				{Alias: "r", Property: "role", Operator: graph.OpIn, Value: []string{"admin"}},
+			},
+		},
+		Return:  []graph.Return{{Expression: "p"}, {Expression: "r.since", Alias: "since"}},
+		OrderBy: []graph.Order{{Alias: "p", Property: "name", Asc: true}},
+		Limit:   &limit,
+	}
+
+	got, cols, err := buildQueryNGQL(query)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	want := "MATCH (p:`Person`)-[r:`LIVES_IN`]->(c:`City` {`name`: \"Paris\"})\n" +
+		"WHERE p.`Person`.`age` > 30 AND (p.`Person`.`name` CONTAINS \"an\" OR r.`role` IN [\"admin\"])\n" +
+		"RETURN p AS `p`, r.since AS `since`, p.`Person`.`name` AS `__order1`\n" +
+		"ORDER BY `__order1` ASC\n" +
+		"LIMIT 10"
+	if got != want {
+		t.Errorf("nGQL mismatch.\nGot:  %s\nWant: %s", got, want)
+	}
+	if len(cols) != 2 || cols[0] != (column{name: "p", kind: kindNode}) || cols[1] != (column{name: "since"}) {
+		t.Errorf("Unexpected columns: %+v", cols)
+	}
+}
+
+// TestBuildQueryNGQL_DefaultReturn tests that a query without Return items
+// returns the aliases of its patterns, defaulting to n, r and m, the
+// properties of nodes with several labels being conditions.
+func TestBuildQueryNGQL_DefaultReturn(t *testing.T) {
+	query := &graph.Query{Match: []graph.Pattern{{
+		Labels:     []string{"Person", "Admin"},
+		Properties: graph.Properties{"name": "Ann"},
+		Edge:       &graph.EdgePattern{Direction: graph.DirectionBoth},
+	}}}
+	got, _, err := buildQueryNGQL(query)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	want := "MATCH (n:`Person`:`Admin`)-[r]-(m)\n" +
+		"WHERE properties(n).`name` == \"Ann\"\n" +
+		"RETURN n AS `n`, r AS `r`, m AS `m`"
+	if got != want {
+		t.Errorf("nGQL mismatch.\nGot:  %s\nWant: %s", got, want)
+	}
+}
+
+// TestBuildQueryNGQL_Path tests that paths and variable-length edges are
+// returned with hidden columns counting their entities.
+func TestBuildQueryNGQL_Path(t *testing.T) {
+	maxHops := 3
+	query := &graph.Query{
+		Match: []graph.Pattern{{
+			PathAlias: "p",
+			Alias:     "a",
+			Edge:      &graph.EdgePattern{Alias: "e", Labels: []string{"ROAD", "RAIL"}, MaxHops: &maxHops, Node: &graph.Pattern{Alias: "b"}},
+		}},
+		Where:  &graph.Where{Filter: []graph.Condition{{Alias: "a", Property: "name", Value: "A"}}},
+		Return: []graph.Return{{Expression: "length(p)", Alias: "hops"}, {Expression: "p"}, {Expression: "e"}},
+	}
+	got, cols, err := buildQueryNGQL(query)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	want := "MATCH p = (a)-[e:`ROAD`|`RAIL`*1..3]->(b)\n" +
+		"WHERE properties(a).`name` == \"A\"\n" +
+		"RETURN nodes(p) AS `p`, relationships(p) AS `__edges1`, size(nodes(p)) AS `__size2`, size(relationships(p)) AS `__size3`, " +
+		"e AS `e`, size(e) AS `__size4`, length(p) AS `hops`"
+	if got != want {
+		t.Errorf("nGQL mismatch.\nGot:  %s\nWant: %s", got, want)
+	}
+	wantCols := []column{
+		{name: "p", kind: kindPath, nodes: "__size2", edges: "__size3"},
+		{name: "e", kind: kindEdges, nodes: "__size4", edges: "__size4"},
+		{name: "hops"},
+	}
+	if len(cols) != len(wantCols) {
+		t.Fatalf("Unexpected columns: %+v", cols)
+	}
+	for i := range cols {
+		if cols[i] != wantCols[i] {
+			t.Errorf("Unexpected column %d: %+v", i, cols[i])
+		}
+	}
+}
+
+// TestBuildQueryNGQL_Stages tests that the stages keep the kinds and tags of
+// the aliases they project, and that the keyset condition applies to the sort
+// keys, projected first.
+func TestBuildQueryNGQL_Stages(t *testing.T) {
+	query := &graph.Query{
+		Match: []graph.Pattern{{
+			Alias: "p", Labels: []string{"Person"},
+			Edge: &graph.EdgePattern{Alias: "k", Labels: []string{"KNOWS"}, Node: &graph.Pattern{Alias: "f"}},
+		}},
+		With: []graph.Stage{{
+			Items: []graph.Return{
+				{Expression: "p"},
+				{Alias: "friends", Aggregate: &graph.Aggregation{Func: graph.AggregateCount, Alias: "f", Distinct: true}},
+			},
+			Where: &graph.Where{
+				Filter:   []graph.Condition{{Alias: "friends", Operator: graph.OpGreaterThanOrEqual, Value: 2}},
+				MustExpr: []graph.ExpressionCondition{{Expression: "p.name STARTS WITH \"A\""}},
+			},
+		}},
+		Return:  []graph.Return{{Expression: "p.name", Alias: "name"}, {Expression: "friends"}},
+		OrderBy: []graph.Order{{Alias: "friends"}, {Alias: "p"}},
+		After:   []any{3, "x"},
+	}
+	got, cols, err := buildQueryNGQL(query)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	want := "MATCH (p:`Person`)-[k:`KNOWS`]->(f)\n" +
+		"WITH p AS `p`, count(DISTINCT f) AS `friends`\n" +
+		"WHERE friends >= 2 AND (p.`Person`.`name` STARTS WITH \"A\")\n" +
+		"WITH p.`Person`.`name` AS `name`, friends AS `friends`, id(p) AS `__order1`\n" +
+		"WHERE (`friends` < 3) OR (`friends` == 3 AND `__order1` < \"x\")\n" +
+		"RETURN `name` AS `name`, `friends` AS `friends`, `__order1` AS `__order1`\n" +
+		"ORDER BY `friends` DESC, `__order1` DESC"
+	if got != want {
+		t.Errorf("nGQL mismatch.\nGot:  %s\nWant: %s", got, want)
+	}
+	if len(cols) != 2 || cols[0].name != "name" || cols[1].name != "friends" {
+		t.Errorf("Unexpected columns: %+v", cols)
+	}
+}
+
+func TestBuildCountAndTargetNGQL(t *testing.T) {
+	query := &graph.Query{Match: []graph.Pattern{{
+		Alias: "a", Labels: []string{"City"},
+		Edge: &graph.EdgePattern{Labels: []string{"ROAD"}, Properties: graph.Properties{"km": 1.5}},
+	}}}
+
+	got, err := buildCountNGQL(query)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if want := "MATCH (a:`City`)-[r:`ROAD` {`km`: 1.5}]->(m)\nRETURN count(*) AS `count`"; got != want {
+		t.Errorf("nGQL mismatch.\nGot:  %s\nWant: %s", got, want)
+	}
+
+	got, cols, err := buildTargetNGQL(query, true)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if want := "MATCH (a:`City`)-[r:`ROAD` {`km`: 1.5}]->(m)\nRETURN r AS `r`"; got != want {
+		t.Errorf("nGQL mismatch.\nGot:  %s\nWant: %s", got, want)
+	}
+	if len(cols) != 1 || cols[0] != (column{name: "r", kind: kindEdge}) {
+		t.Errorf("Unexpected columns: %+v", cols)
+	}
+
+	if _, _, err := buildTargetNGQL(&graph.Query{Match: []graph.Pattern{{}}}, true); err == nil {
+		t.Errorf("Expected an error for a pattern without edge")
+	}
+	_, _, err = buildQueryNGQL(&graph.Query{
+		Match: []graph.Pattern{{}},
+		Where: &graph.Where{MustExpr: []graph.ExpressionCondition{{Expression: "n.age > $age", Params: map[string]any{"age": 1}}}},
+	})
+	if !errors.Is(err, errors.ErrUnsupported) {
+		t.Errorf("Expected an unsupported error, got %v", err)
+	}
+}
+
+func TestLiteral(t *testing.T) {
+	cases := []struct {
+		value any
+		want  string
+	}{
+		{nil, "NULL"},
+		{"a \"b\"\n\\", `"a \"b\"\n\\"`},
+		{int32(-3), "-3"},
+		{uint8(3), "3"},
+		{2.0, "2.0"},
+		{1e21, "1e+21"},
+		{true, "true"},
+		{[]any{"a", 1}, `["a", 1]`},
+		{graph.Properties{"b": 1, "a": []string{}}, "{`a`: [], `b`: 1}"},
+		{time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC), `datetime("2024-01-02T03:04:05.000000")`},
+	}
+	for _, c := range cases {
+		got, err := literal(c.value)
+		if err != nil {
+			t.Fatalf("Unexpected error for %v: %v", c.value, err)
+		}
+		if got != c.want {
+			t.Errorf("Literal mismatch.\nGot:  %s\nWant: %s", got, c.want)
+		}
+	}
+	if _, err := literal(struct{}{}); err == nil {
+		t.Errorf("Expected an error for a struct")
+	}
+}
+
+func TestEdgeID(t *testing.T) {
+	id := EdgeID("KNOWS", `a"->"b`, "c", 2)
+	if want := `KNOWS:"a\"->\"b"->"c"@2`; id != want {
+		t.Errorf("Unexpected edge ID.\nGot:  %s\nWant: %s", id, want)
+	}
+	key, err := parseEdgeID(id)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if want := (edgeKey{typ: "KNOWS", src: `a"->"b`, dst: "c", rank: 2}); key != want {
+		t.Errorf("Unexpected edge key.\nGot:  %+v\nWant: %+v", key, want)
+	}
+	if got, want := key.ref(), `"a\"->\"b"->"c"@2`; got != want {
+		t.Errorf("Unexpected edge reference.\nGot:  %s\nWant: %s", got, want)
+	}
+	for _, invalid := range []string{"", "KNOWS", `KNOWS:"a"`, `KNOWS:"a"->"b"`, `KNOWS:"a"->"b"@x`} {
+		if _, err := parseEdgeID(invalid); err == nil {
+			t.Errorf("Expected an error for %q", invalid)
+		}
+	}
+}
+
+func TestBuildShortestPathNGQL(t *testing.T) {
+	got := buildShortestPathNGQL("a", "b", map[string]any{"relationshipTypes": []any{"ROAD", "RAIL"}, "direction": "incoming", "maxDepth": 4})
+	want := "FIND SHORTEST PATH WITH PROP FROM \"a\" TO \"b\" OVER `ROAD`, `RAIL` REVERSELY UPTO 4 STEPS YIELD path AS p" +
+		" | YIELD nodes($-.p) AS `nodes`, relationships($-.p) AS `edges`"
+	if got != want {
+		t.Errorf("nGQL mismatch.\nGot:  %s\nWant: %s", got, want)
+	}
+}
+
+func TestBuildNeighborsNGQL(t *testing.T) {
+	got := buildNeighborsNGQL("a", graph.NewNeighborOptions())
+	want := "MATCH p = (n)-[]-(m) WHERE id(n) == \"a\" AND id(m) != \"a\" UNWIND relationships(p) AS r RETURN DISTINCT m AS `m`, r AS `r`"
+	if got != want {
+		t.Errorf("nGQL mismatch.\nGot:  %s\nWant: %s", got, want)
+	}
+
+	got = buildNeighborsNGQL("a", graph.NewNeighborOptions(graph.WithDirection(graph.DirectionOutgoing), graph.WithRelationshipTypes("ROAD", "RAIL"), graph.WithDepth(3)))
+	want = "MATCH p = (n)-[:`ROAD`|`RAIL`*1..3]->(m) WHERE id(n) == \"a\" AND id(m) != \"a\" UNWIND relationships(p) AS r RETURN DISTINCT m AS `m`, r AS `r`"
+	if got != want {
+		t.Errorf("nGQL mismatch.\nGot:  %s\nWant: %s", got, want)
+	}
+}
+
+func TestBuildSubgraphNGQL(t *testing.T) {
+	got := buildSubgraphNGQL("a", graph.TraversalSpec{}.NeighborOptions())
+	want := "GET SUBGRAPH WITH PROP 1 STEPS FROM \"a\" YIELD VERTICES AS `nodes`, EDGES AS `edges`"
+	if got != want {
+		t.Errorf("nGQL mismatch.\nGot:  %s\nWant: %s", got, want)
+	}
+
+	spec := graph.TraversalSpec{Direction: graph.DirectionIncoming, RelationshipTypes: []string{"ROAD", "RAIL"}, MaxDepth: 3}
+	got = buildSubgraphNGQL("a", spec.NeighborOptions())
+	want = "GET SUBGRAPH WITH PROP 3 STEPS FROM \"a\" IN `ROAD`, `RAIL` YIELD VERTICES AS `nodes`, EDGES AS `edges`"
+	if got != want {
+		t.Errorf("nGQL mismatch.\nGot:  %s\nWant: %s", got, want)
+	}
+}
+
+func TestBuildQueryNGQL_QuotedNames(t *testing.T) {
+	query := &graph.Query{
+		Match: []graph.Pattern{{
+			Alias:  "n",
+			Labels: []string{"Ci`ty"},
+			Edge: &graph.EdgePattern{
+				Labels: []string{"ROAD`", "RAIL"},
+				Node:   &graph.Pattern{Alias: "m"},
+			},
+		}},
+		Return: []graph.Return{{Expression: "n"}},
+	}
+	got, _, err := buildQueryNGQL(query)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	want := "MATCH (n:`Ci``ty`)-[r:`ROAD```|`RAIL`]->(m)\nRETURN n AS `n`"
+	if got != want {
+		t.Errorf("nGQL mismatch.\nGot:  %s\nWant: %s", got, want)
+	}
+	if got, want := quote("a`b"), "`a``b`"; got != want {
+		t.Errorf("Unexpected quoted name.\nGot:  %s\nWant: %s", got, want)
+	}
+}
+
+func TestInvalidNames(t *testing.T) {
+	query := &graph.Query{Match: []graph.Pattern{{Alias: "n", Labels: []string{"City\n"}}}}
+	if _, _, err := buildQueryNGQL(query); !errors.Is(err, graph.ErrInvalidName) {
+		t.Errorf("Expected an invalid name error, got %v", err)
+	}
+	if _, err := buildCountNGQL(query); !errors.Is(err, graph.ErrInvalidName) {
+		t.Errorf("Expected an invalid name error, got %v", err)
+	}
+	if _, err := updateEdge(edgeKey{typ: "ROAD"}, graph.Properties{"": 1}); !errors.Is(err, graph.ErrInvalidName) {
+		t.Errorf("Expected an invalid name error, got %v", err)
+	}
+	if _, err := parseEdgeID("RO\x00AD:\"a\"->\"b\"@0"); !errors.Is(err, graph.ErrInvalidName) {
+		t.Errorf("Expected an invalid name error, got %v", err)
+	}
+}
@@ -0,0 +1,243 @@
+package nebula
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/me2seeks/forge/infra/contract/graph"
+	"github.com/me2seeks/forge/sonic"
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	testEndpoint = os.Getenv("NEBULA_ENDPOINT")
+	testAddress  = os.Getenv("NEBULA_ADDRESS")
+)
+
+// setup connects to the gateway at NEBULA_ENDPOINT, running in the space
+// forge_test, which needs a FIXED_STRING(32) vid type, and creates the schema
+// of the tests.
+func setup(t *testing.T) (graph.Client, func()) {
+	if testEndpoint == "" || testAddress == "" {
+		t.Skip("NEBULA_ENDPOINT or NEBULA_ADDRESS is not set")
+	}
+	ctx := context.Background()
+	client, err := New(ctx, testEndpoint, testAddress, WithSpace("forge_test"))
+	require.NoError(t, err)
+
+	schema, ok := AsSchema(client)
+	require.True(t, ok)
+	require.NoError(t, schema.CreateTag(ctx, "City", []Property{{Name: "name", Type: "string"}, {Name: "size", Type: "int64"}}))
+	require.NoError(t, schema.CreateEdgeType(ctx, "ROAD", []Property{{Name: "km", Type: "int64"}}))
+	require.NoError(t, client.CreateNodeIndex(ctx, "City", nil))
+	require.NoError(t, client.CreateNodeIndex(ctx, "City", []string{"name"}))
+	// Nebula applies schema changes within a heartbeat.
+	time.Sleep(20 * time.Second)
+
+	teardown := func() {
+		_, err := client.DeleteNodesByQuery(ctx, &graph.Query{Match: []graph.Pattern{{Labels: []string{"City"}}}})
+		require.NoError(t, err)
+	}
+	return client, teardown
+}
+
+// newTestServer returns a client of a gateway responding to the statements
+// with respond, and records the statements run.
+func newTestServer(t *testing.T, respond func(gql string) string) (*nebulaClient, *[]string) {
+	var stmts []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		switch r.URL.Path {
+		case connectPath:
+			var login loginRequest
+			if err := sonic.Unmarshal(body, &login); err != nil || login.Address != "graphd" || login.Port != 9669 || login.Username != "root" {
+				t.Errorf("Unexpected login: %s", body)
+			}
+			http.SetCookie(w, &http.Cookie{Name: "nsid", Value: "session"})
+			_, _ = w.Write([]byte(`{"code": 0, "data": {}, "message": "Login successfully"}`))
+		case execPath:
+			if cookie, err := r.Cookie("nsid"); err != nil || cookie.Value != "session" {
+				t.Errorf("Unexpected session: %v", r.Cookies())
+			}
+			var req struct {
+				GQL string `json:"gql"`
+			}
+			if err := sonic.Unmarshal(body, &req); err != nil {
+				t.Errorf("Unexpected request: %s", body)
+			}
+			stmts = append(stmts, req.GQL)
+			_, _ = w.Write([]byte(respond(req.GQL)))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	client, err := New(context.Background(), server.URL, "graphd:9669", WithSpace("forge"))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	return client.(*nebulaClient), &stmts
+}
+
+func TestExec(t *testing.T) {
+	c, stmts := newTestServer(t, func(gql string) string {
+		if gql == "USE `other`; RETURN 1" {
+			return `{"code": -1, "message": "SpaceNotFound: other"}`
+		}
+		return `{"code": 0, "data": {"headers": ["n"], "tables": [{"n": 1}]}, "message": ""}`
+	})
+
+	ctx := context.Background()
+	res, err := c.exec(ctx, "RETURN 1 AS n")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if want := (&result{Headers: []string{"n"}, Tables: []map[string]any{{"n": int64(1)}}}); !reflect.DeepEqual(res, want) {
+		t.Errorf("Unexpected result.\nGot:  %+v\nWant: %+v", res, want)
+	}
+
+	_, err = c.exec(graph.WithDatabase(ctx, "other"), "RETURN 1")
+	var apiErr *Error
+	if !errors.As(err, &apiErr) || apiErr.Code != -1 || apiErr.Message != "SpaceNotFound: other" {
+		t.Errorf("Expected a gateway error, got %v", err)
+	}
+	if want := []string{"USE `forge`; RETURN 1 AS n", "USE `other`; RETURN 1"}; !reflect.DeepEqual(*stmts, want) {
+		t.Errorf("Unexpected statements.\nGot:  %q\nWant: %q", *stmts, want)
+	}
+
+	c.space = ""
+	if _, err := c.exec(ctx, "RETURN 1"); err == nil {
+		t.Errorf("Expected an error without space")
+	}
+}
+
+func TestDecodeRow(t *testing.T) {
+	a := map[string]any{"vid": "a", "tags": []any{"City", "Capital"}, "properties": map[string]any{
+		"City": map[string]any{"name": "A"}, "Capital": map[string]any{"country": "X"},
+	}}
+	b := map[string]any{"vid": "b", "tags": []any{"City"}, "properties": map[string]any{"City": map[string]any{"name": "B"}}}
+	r := map[string]any{"srcID": "a", "dstID": "b", "edgeName": "ROAD", "rank": int64(0), "properties": map[string]any{"km": int64(10)}}
+
+	nodeA := &graph.Node{ID: "a", Labels: []string{"City", "Capital"}, Properties: graph.Properties{"name": "A", "country": "X"}}
+	nodeB := &graph.Node{ID: "b", Labels: []string{"City"}, Properties: graph.Properties{"name": "B"}}
+	edge := &graph.Edge{ID: `ROAD:"a"->"b"@0`, Label: "ROAD", SourceNodeID: "a", TargetNodeID: "b", Properties: graph.Properties{"km": int64(10)}}
+
+	row := map[string]any{
+		"n": `("a" :City{name: "A"} :Capital{country: "X"})`, "missing": nil, "r": `[:ROAD "a"->"b" @0 {km: 10}]`,
+		"p": "nodes", "__size1": int64(2), "__size2": int64(1), "name": "A",
+		verticesKey: []any{a, a, b},
+		edgesKey:    []any{r, r},
+	}
+	cols := []column{
+		{name: "n", kind: kindNode},
+		{name: "missing", kind: kindNode},
+		{name: "r", kind: kindEdge},
+		{name: "p", kind: kindPath, nodes: "__size1", edges: "__size2"},
+		{name: "name"},
+	}
+	want := graph.Record{
+		"n": nodeA, "missing": nil, "r": edge,
+		"p":    &graph.Path{Nodes: []*graph.Node{nodeA, nodeB}, Edges: []*graph.Edge{edge}},
+		"name": "A",
+	}
+	if got := decodeRow(row, cols); !reflect.DeepEqual(got, want) {
+		t.Errorf("Unexpected record.\nGot:  %#v\nWant: %#v", got, want)
+	}
+}
+
+func TestCreateNodes(t *testing.T) {
+	c, stmts := newTestServer(t, func(gql string) string {
+		switch gql {
+		case "USE `forge`; DESCRIBE TAG `City`":
+			return `{"code": 0, "data": {"headers": ["Field", "Type"], "tables": [{"Field": "name", "Type": "string"}, {"Field": "size", "Type": "int64"}]}}`
+		case "USE `forge`; DESCRIBE TAG `Capital`":
+			return `{"code": 0, "data": {"headers": ["Field", "Type"], "tables": [{"Field": "name", "Type": "string"}]}}`
+		}
+		return `{"code": 0, "data": {"headers": [], "tables": []}}`
+	})
+
+	ctx := context.Background()
+	nodes, err := c.CreateNodes(ctx, []*graph.Node{
+		{ID: "a", Labels: []string{"City", "Capital"}, Properties: graph.Properties{"name": "A", "size": 1}},
+		{ID: "b", Labels: []string{"City"}},
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(nodes) != 2 || nodes[0].ID != "a" || nodes[1].ID != "b" {
+		t.Errorf("Unexpected nodes: %+v", nodes)
+	}
+	want := []string{
+		"USE `forge`; DESCRIBE TAG `City`",
+		"USE `forge`; DESCRIBE TAG `Capital`",
+		"USE `forge`; INSERT VERTEX `City`(`name`, `size`), `Capital`(`name`) VALUES \"a\":(\"A\", 1, \"A\"); " +
+			"INSERT VERTEX `City`() VALUES \"b\":()",
+	}
+	if !reflect.DeepEqual(*stmts, want) {
+		t.Errorf("Unexpected statements.\nGot:  %q\nWant: %q", *stmts, want)
+	}
+
+	_, err = c.CreateNode(ctx, &graph.Node{Labels: []string{"City"}, Properties: graph.Properties{"unknown": 1}})
+	if err == nil {
+		t.Errorf("Expected an error for an undeclared property")
+	}
+	_, err = c.CreateNode(ctx, &graph.Node{})
+	if err == nil {
+		t.Errorf("Expected an error for a node without label")
+	}
+}
+
+func TestNebula(t *testing.T) {
+	client, teardown := setup(t)
+	defer teardown()
+
+	ctx := context.Background()
+
+	nodes, err := client.CreateNodes(ctx, []*graph.Node{
+		{Labels: []string{"City"}, Properties: graph.Properties{"name": "A"}},
+		{Labels: []string{"City"}, Properties: graph.Properties{"name": "B"}},
+		{Labels: []string{"City"}, Properties: graph.Properties{"name": "C"}},
+	})
+	require.NoError(t, err)
+	a, b, c := nodes[0], nodes[1], nodes[2]
+	edges, err := client.CreateEdges(ctx, []*graph.Edge{
+		{Label: "ROAD", SourceNodeID: a.ID, TargetNodeID: b.ID, Properties: graph.Properties{"km": 1}},
+		{Label: "ROAD", SourceNodeID: b.ID, TargetNodeID: c.ID, Properties: graph.Properties{"km": 2}},
+	})
+	require.NoError(t, err)
+
+	got, err := client.GetNode(ctx, b.ID)
+	require.NoError(t, err)
+	require.Equal(t, "B", got.Properties["name"])
+
+	edge, err := client.GetEdge(ctx, edges[0].ID)
+	require.NoError(t, err)
+	require.EqualValues(t, 1, edge.Properties["km"])
+
+	count, err := client.Count(ctx, &graph.Query{Match: []graph.Pattern{{Labels: []string{"City"}}}})
+	require.NoError(t, err)
+	require.EqualValues(t, 3, count)
+
+	paths, err := client.ShortestPath(ctx, a.ID, c.ID, map[string]any{"direction": "OUTGOING"})
+	require.NoError(t, err)
+	require.Len(t, paths, 1)
+	require.Len(t, paths[0].Edges, 2)
+
+	merged, err := client.MergeNode(ctx, &graph.Node{Labels: []string{"City"}, Properties: graph.Properties{"name": "A", "size": 1}}, []string{"name"})
+	require.NoError(t, err)
+	require.Equal(t, a.ID, merged.ID)
+	require.EqualValues(t, 1, merged.Properties["size"])
+
+	_, err = client.BeginTx(ctx)
+	require.True(t, errors.Is(err, errors.ErrUnsupported))
+	err = client.CreateConstraint(ctx, "City", "name", graph.ConstraintUnique)
+	require.True(t, errors.Is(err, errors.ErrUnsupported))
+}
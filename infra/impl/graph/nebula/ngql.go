@@ -0,0 +1,754 @@
+package nebula
+
+import (
+	"fmt"
+	"maps"
+	"reflect"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/me2seeks/forge/infra/contract/graph"
+)
+
+// maxTraversalDepth bounds the variable-length edges without MaxHops, as
+// Nebula's MATCH needs a maximum number of hops.
+const maxTraversalDepth = 10
+
+// Default aliases of the patterns, as in Cypher.
+const (
+	defaultNodeAlias = "n"
+	defaultEdgeAlias = "r"
+	defaultEndAlias  = "m"
+)
+
+// quote quotes an identifier, e.g. a tag or a property, the backticks it
+// holds doubled, so that no name can change the statement. The clients reject
+// the names graph.ValidateName rejects before building it.
+func quote(name string) string {
+	return "`" + strings.ReplaceAll(name, "`", "``") + "`"
+}
+
+// quoteString returns s as an nGQL string literal.
+func quoteString(s string) string {
+	var sb strings.Builder
+	sb.WriteByte('"')
+	for _, r := range s {
+		switch r {
+		case '"', '\\':
+			sb.WriteByte('\\')
+			sb.WriteRune(r)
+		case '\n':
+			sb.WriteString(`\n`)
+		case '\r':
+			sb.WriteString(`\r`)
+		case '\t':
+			sb.WriteString(`\t`)
+		default:
+			sb.WriteRune(r)
+		}
+	}
+	sb.WriteByte('"')
+	return sb.String()
+}
+
+// literal returns value as an nGQL literal, as statements can't be
+// parameterized through the gateway.
+func literal(value any) (string, error) {
+	switch v := value.(type) {
+	case nil:
+		return "NULL", nil
+	case string:
+		return quoteString(v), nil
+	case bool:
+		return strconv.FormatBool(v), nil
+	case float32:
+		return floatLiteral(float64(v)), nil
+	case float64:
+		return floatLiteral(v), nil
+	case time.Time:
+		return "datetime(" + quoteString(v.UTC().Format("2006-01-02T15:04:05.000000")) + ")", nil
+	case graph.Properties:
+		return literal(map[string]any(v))
+	case map[string]any:
+		parts := make([]string, 0, len(v))
+		for _, key := range slices.Sorted(maps.Keys(v)) {
+			item, err := literal(v[key])
+			if err != nil {
+				return "", err
+			}
+			parts = append(parts, quote(key)+": "+item)
+		}
+		return "{" + strings.Join(parts, ", ") + "}", nil
+	}
+
+	rv := reflect.ValueOf(value)
+	switch rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(rv.Int(), 10), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return strconv.FormatUint(rv.Uint(), 10), nil
+	case reflect.Slice, reflect.Array:
+		parts := make([]string, rv.Len())
+		for i := range parts {
+			item, err := literal(rv.Index(i).Interface())
+			if err != nil {
+				return "", err
+			}
+			parts[i] = item
+		}
+		return "[" + strings.Join(parts, ", ") + "]", nil
+	default:
+		return "", fmt.Errorf("nebula: unsupported value %T", value)
+	}
+}
+
+// floatLiteral formats f with a decimal point, for it to stay a double.
+func floatLiteral(f float64) string {
+	s := strconv.FormatFloat(f, 'g', -1, 64)
+	if !strings.ContainsAny(s, ".eEN") {
+		s += ".0"
+	}
+	return s
+}
+
+// edgeKey identifies an edge in Nebula.
+type edgeKey struct {
+	typ      string
+	src, dst string
+	rank     int64
+}
+
+// EdgeID returns the ID of the edge of type edgeType from source to target
+// with rank, e.g. KNOWS:"a"->"b"@0, by which the client identifies edges.
+func EdgeID(edgeType, source, target string, rank int64) string {
+	return edgeType + ":" + strconv.Quote(source) + "->" + strconv.Quote(target) + "@" + strconv.FormatInt(rank, 10)
+}
+
+func (k edgeKey) id() string {
+	return EdgeID(k.typ, k.src, k.dst, k.rank)
+}
+
+// ref returns the nGQL reference of the edge, without its type, e.g.
+// "a"->"b"@0.
+func (k edgeKey) ref() string {
+	return quoteString(k.src) + "->" + quoteString(k.dst) + "@" + strconv.FormatInt(k.rank, 10)
+}
+
+// parseEdgeID parses an ID returned by EdgeID.
+func parseEdgeID(id string) (edgeKey, error) {
+	invalid := fmt.Errorf("nebula: invalid edge ID %s", id)
+	i := strings.Index(id, `:"`)
+	if i <= 0 {
+		return edgeKey{}, invalid
+	}
+	key := edgeKey{typ: id[:i]}
+	if err := graph.ValidateName(key.typ); err != nil {
+		return edgeKey{}, err
+	}
+	rest := id[i+1:]
+
+	src, err := strconv.QuotedPrefix(rest)
+	if err != nil {
+		return edgeKey{}, invalid
+	}
+	rest, ok := strings.CutPrefix(rest[len(src):], "->")
+	if !ok {
+		return edgeKey{}, invalid
+	}
+	dst, err := strconv.QuotedPrefix(rest)
+	if err != nil {
+		return edgeKey{}, invalid
+	}
+	rank, ok := strings.CutPrefix(rest[len(dst):], "@")
+	if !ok {
+		return edgeKey{}, invalid
+	}
+	if key.rank, err = strconv.ParseInt(rank, 10, 64); err != nil {
+		return edgeKey{}, invalid
+	}
+	key.src, _ = strconv.Unquote(src)
+	key.dst, _ = strconv.Unquote(dst)
+	return key, nil
+}
+
+// kind is the kind of value of an alias or a column, telling how to decode
+// it.
+type kind int
+
+const (
+	kindValue kind = iota
+	kindNode
+	kindEdge
+	// kindNodes and kindEdges are lists of nodes or edges, e.g. the alias of a
+	// variable-length edge.
+	kindNodes
+	kindEdges
+	kindPath
+)
+
+// column is a column of a query built by the client. The gateway returns
+// vertices and edges as text, along with their decoded values in lists per
+// row, in column order; the columns of lists and paths have hidden columns
+// counting their entities, to split these lists.
+type column struct {
+	name string
+	kind kind
+	// nodes and edges name the hidden columns of the numbers of nodes and
+	// edges of a list or a path.
+	nodes, edges string
+}
+
+// ngqlBuilder translates the query DSL to an nGQL MATCH statement, one clause
+// per line.
+type ngqlBuilder struct {
+	lines []string
+	// kinds are the kinds of the aliases visible to the query.
+	kinds map[string]kind
+	// tags maps the node aliases matched with a single label to it, which
+	// qualifies their properties: Nebula reads a property of a vertex from one
+	// of its tags.
+	tags map[string]string
+	// aliases are the node and edge aliases of the patterns in order,
+	// returned by a query without Return items.
+	aliases []string
+	// hidden counts the hidden columns.
+	hidden int
+	err    error
+}
+
+func newBuilder() *ngqlBuilder {
+	return &ngqlBuilder{
+		kinds: make(map[string]kind),
+		tags:  make(map[string]string),
+	}
+}
+
+func (b *ngqlBuilder) add(format string, args ...any) {
+	b.lines = append(b.lines, fmt.Sprintf(format, args...))
+}
+
+func (b *ngqlBuilder) String() string {
+	return strings.Join(b.lines, "\n")
+}
+
+// literal returns value as a literal, recording the first error.
+func (b *ngqlBuilder) literal(value any) string {
+	s, err := literal(value)
+	if err != nil && b.err == nil {
+		b.err = err
+	}
+	return s
+}
+
+// hiddenColumn returns the name of a new hidden column.
+func (b *ngqlBuilder) hiddenColumn(prefix string) string {
+	b.hidden++
+	return "__" + prefix + strconv.Itoa(b.hidden)
+}
+
+// prop returns the expression of the property of alias.
+func (b *ngqlBuilder) prop(alias, property string) string {
+	switch b.kinds[alias] {
+	case kindNode:
+		if tag, ok := b.tags[alias]; ok {
+			return alias + "." + quote(tag) + "." + quote(property)
+		}
+		return "properties(" + alias + ")." + quote(property)
+	default:
+		return alias + "." + quote(property)
+	}
+}
+
+var propertyRef = regexp.MustCompile(`\b([A-Za-z_][A-Za-z0-9_]*)\.([A-Za-z_][A-Za-z0-9_]*)\b`)
+
+// expr rewrites the properties of the node aliases referenced by an
+// expression, e.g. n.name, as Nebula reads them from a tag.
+func (b *ngqlBuilder) expr(expression string) string {
+	var sb strings.Builder
+	last := 0
+	for _, loc := range propertyRef.FindAllStringSubmatchIndex(expression, -1) {
+		alias := expression[loc[2]:loc[3]]
+		// Skip the properties of properties, and those already qualified by a
+		// tag, e.g. n.Person.name.
+		if b.kinds[alias] != kindNode || (loc[0] > 0 && expression[loc[0]-1] == '.') ||
+			(loc[1] < len(expression) && expression[loc[1]] == '.') {
+			continue
+		}
+		sb.WriteString(expression[last:loc[0]])
+		sb.WriteString(b.prop(alias, expression[loc[4]:loc[5]]))
+		last = loc[1]
+	}
+	sb.WriteString(expression[last:])
+	return sb.String()
+}
+
+// body translates the patterns, conditions and stages of query.
+func (b *ngqlBuilder) body(query *graph.Query) {
+	var conds []string
+	for i := range query.Match {
+		conds = append(conds, b.match(&query.Match[i])...)
+	}
+	if where := b.where(query.Where); where != "" {
+		conds = append(conds, where)
+	}
+	if len(conds) > 0 {
+		b.add("WHERE %s", strings.Join(conds, " AND "))
+	}
+	for _, stage := range query.With {
+		b.add("WITH %s", b.project(stage.Items))
+		if where := b.where(stage.Where); where != "" {
+			b.add("WHERE %s", where)
+		}
+	}
+}
+
+// match translates a pattern to a MATCH clause, and returns the conditions
+// on its aliases already bound, which Nebula can't set in the pattern.
+func (b *ngqlBuilder) match(p *graph.Pattern) []string {
+	var conds []string
+	var sb strings.Builder
+	if p.PathAlias != "" {
+		sb.WriteString(p.PathAlias + " = ")
+		b.kinds[p.PathAlias] = kindPath
+	}
+	sb.WriteString(b.node(p, defaultNodeAlias, &conds))
+	for e := p.Edge; e != nil; {
+		next := e.Node
+		if next == nil {
+			next = &graph.Pattern{}
+		}
+		sb.WriteString(b.edge(e))
+		sb.WriteString(b.node(next, defaultEndAlias, &conds))
+		e = next.Edge
+	}
+	b.add("MATCH %s", sb.String())
+	return conds
+}
+
+// node translates a node of a pattern. The properties of a node with a single
+// label are set in the pattern, for Nebula to look them up in the indexes of
+// its tag; the others are conditions, added to conds.
+func (b *ngqlBuilder) node(p *graph.Pattern, def string, conds *[]string) string {
+	alias := p.Alias
+	if alias == "" {
+		alias = def
+	}
+	if _, bound := b.kinds[alias]; bound {
+		for _, label := range p.Labels {
+			*conds = append(*conds, quoteString(label)+" IN tags("+alias+")")
+		}
+		for _, key := range slices.Sorted(maps.Keys(p.Properties)) {
+			*conds = append(*conds, b.prop(alias, key)+" == "+b.literal(p.Properties[key]))
+		}
+		return "(" + alias + ")"
+	}
+
+	b.kinds[alias] = kindNode
+	b.aliases = append(b.aliases, alias)
+	var sb strings.Builder
+	sb.WriteString("(" + alias)
+	for _, label := range p.Labels {
+		sb.WriteString(":" + quote(label))
+	}
+	if len(p.Labels) == 1 {
+		b.tags[alias] = p.Labels[0]
+		if len(p.Properties) > 0 {
+			sb.WriteString(" " + b.literal(map[string]any(p.Properties)))
+		}
+	} else {
+		for _, key := range slices.Sorted(maps.Keys(p.Properties)) {
+			*conds = append(*conds, b.prop(alias, key)+" == "+b.literal(p.Properties[key]))
+		}
+	}
+	sb.WriteString(")")
+	return sb.String()
+}
+
+// edge translates an edge of a pattern.
+func (b *ngqlBuilder) edge(e *graph.EdgePattern) string {
+	alias := e.Alias
+	if alias == "" {
+		alias = defaultEdgeAlias
+	}
+	var sb strings.Builder
+	sb.WriteString("[" + alias)
+	for i, label := range e.Labels {
+		if i == 0 {
+			sb.WriteString(":")
+		} else {
+			sb.WriteString("|")
+		}
+		sb.WriteString(quote(label))
+	}
+	if e.MinHops == nil && e.MaxHops == nil {
+		b.kinds[alias] = kindEdge
+	} else {
+		minHops, maxHops := 1, maxTraversalDepth
+		if e.MinHops != nil {
+			minHops = *e.MinHops
+		}
+		if e.MaxHops != nil {
+			maxHops = *e.MaxHops
+		}
+		sb.WriteString(fmt.Sprintf("*%d..%d", minHops, maxHops))
+		// Like in Cypher, the alias of a variable-length edge holds the list
+		// of edges traversed.
+		b.kinds[alias] = kindEdges
+	}
+	if !slices.Contains(b.aliases, alias) {
+		b.aliases = append(b.aliases, alias)
+	}
+	if len(e.Properties) > 0 {
+		sb.WriteString(" " + b.literal(map[string]any(e.Properties)))
+	}
+	sb.WriteString("]")
+
+	switch e.Direction {
+	case graph.DirectionIncoming:
+		return "<-" + sb.String() + "-"
+	case graph.DirectionBoth:
+		return "-" + sb.String() + "-"
+	default:
+		return "-" + sb.String() + "->"
+	}
+}
+
+// where translates where to an nGQL condition, empty if it has none.
+func (b *ngqlBuilder) where(where *graph.Where) string {
+	if where == nil {
+		return ""
+	}
+	conditions := func(conds []graph.Condition, op string) string {
+		parts := make([]string, len(conds))
+		for i, cond := range conds {
+			parts[i] = b.condition(cond)
+		}
+		return strings.Join(parts, op)
+	}
+
+	var clauses []string
+	if len(where.Filter) > 0 {
+		clauses = append(clauses, conditions(where.Filter, " AND "))
+	}
+	if len(where.Must) > 0 {
+		clauses = append(clauses, "("+conditions(where.Must, " AND ")+")")
+	}
+	if len(where.Should) > 0 {
+		clauses = append(clauses, "("+conditions(where.Should, " OR ")+")")
+	}
+	if len(where.MustNot) > 0 {
+		clauses = append(clauses, "NOT ("+conditions(where.MustNot, " AND ")+")")
+	}
+	for _, expr := range where.MustExpr {
+		if len(expr.Params) > 0 && b.err == nil {
+			b.err = unsupported("expression parameters")
+		}
+		clauses = append(clauses, "("+b.expr(expr.Expression)+")")
+	}
+	return strings.Join(clauses, " AND ")
+}
+
+// condition translates cond to an nGQL comparison.
+func (b *ngqlBuilder) condition(cond graph.Condition) string {
+	left := cond.Alias
+	if cond.Property != "" {
+		left = b.prop(cond.Alias, cond.Property)
+	}
+	value := b.literal(cond.Value)
+
+	switch cond.Operator {
+	case graph.OpNotEqual:
+		return left + " != " + value
+	case graph.OpGreaterThan, graph.OpGreaterThanOrEqual, graph.OpLessThan, graph.OpLessThanOrEqual:
+		return left + " " + string(cond.Operator) + " " + value
+	case graph.OpIn:
+		return left + " IN " + value
+	case graph.OpContains:
+		return left + " CONTAINS " + value
+	default:
+		return left + " == " + value
+	}
+}
+
+// itemName returns the name of a projected item.
+func itemName(item graph.Return) string {
+	if item.Alias != "" {
+		return item.Alias
+	}
+	return item.Expression
+}
+
+// itemKind returns the kind of the value of item.
+func (b *ngqlBuilder) itemKind(item graph.Return) kind {
+	if a := item.Aggregate; a != nil {
+		if a.Func == graph.AggregateCollect && a.Property == "" {
+			switch b.kinds[a.Alias] {
+			case kindNode:
+				return kindNodes
+			case kindEdge:
+				return kindEdges
+			}
+		}
+		return kindValue
+	}
+	if k, ok := b.kinds[item.Expression]; ok {
+		return k
+	}
+	return kindValue
+}
+
+// itemExpression returns the nGQL expression of item.
+func (b *ngqlBuilder) itemExpression(item graph.Return) string {
+	a := item.Aggregate
+	if a == nil {
+		return b.expr(item.Expression)
+	}
+	arg := a.Alias
+	if a.Property != "" {
+		arg = b.prop(a.Alias, a.Property)
+	}
+	if a.Func == graph.AggregateCount && a.Alias == "" {
+		return "count(*)"
+	}
+	switch a.Func {
+	case graph.AggregateCount, graph.AggregateSum, graph.AggregateAvg, graph.AggregateMin, graph.AggregateMax, graph.AggregateCollect:
+	default:
+		if b.err == nil {
+			b.err = fmt.Errorf("nebula: unknown aggregate function %s", a.Func)
+		}
+	}
+	if a.Distinct {
+		arg = "DISTINCT " + arg
+	}
+	return string(a.Func) + "(" + arg + ")"
+}
+
+// project translates the items of a WITH clause, which become the only
+// aliases visible, keeping their kinds and tags.
+func (b *ngqlBuilder) project(items []graph.Return) string {
+	parts := make([]string, len(items))
+	kinds := make(map[string]kind, len(items))
+	tags := make(map[string]string)
+	for i, item := range items {
+		name := itemName(item)
+		parts[i] = b.itemExpression(item) + " AS " + quote(name)
+		kinds[name] = b.itemKind(item)
+		if tag, ok := b.tags[item.Expression]; ok && item.Aggregate == nil {
+			tags[name] = tag
+		}
+	}
+	b.kinds, b.tags = kinds, tags
+	return strings.Join(parts, ", ")
+}
+
+// ordered is a column of a RETURN clause.
+type ordered struct {
+	expr string
+	column
+}
+
+// returns translates the Return items, sorting, keyset condition and paging
+// of query, and returns the columns to decode.
+func (b *ngqlBuilder) returns(query *graph.Query) []column {
+	items := query.Return
+	if len(items) == 0 {
+		if len(query.With) > 0 {
+			for _, item := range query.With[len(query.With)-1].Items {
+				items = append(items, graph.Return{Expression: itemName(item)})
+			}
+		} else {
+			for _, alias := range b.aliases {
+				items = append(items, graph.Return{Expression: alias})
+			}
+		}
+	}
+
+	// The columns of entities come first, for the decoded entities of the
+	// row to be those of the columns returned by the query: other
+	// expressions may return entities, which come next.
+	var entities, values []ordered
+	exprs := make(map[string]string, len(items))
+	for _, item := range items {
+		col := ordered{expr: b.itemExpression(item), column: column{name: itemName(item), kind: b.itemKind(item)}}
+		if item.Aggregate == nil {
+			exprs[item.Expression] = col.name
+		}
+		if col.kind == kindValue {
+			values = append(values, col)
+		} else {
+			entities = append(entities, col)
+		}
+	}
+	returned := make(map[string]bool, len(items))
+	for _, col := range append(slices.Clone(entities), values...) {
+		returned[col.name] = true
+	}
+
+	// Nebula sorts on the returned columns, so the keys not returned are
+	// returned as hidden columns.
+	var keys []string
+	var hidden []ordered
+	for _, o := range query.OrderBy {
+		expr, name := b.orderExpression(o)
+		if n, ok := exprs[name]; ok {
+			name = n
+		} else if !returned[name] {
+			name = b.hiddenColumn("order")
+			hidden = append(hidden, ordered{expr: expr, column: column{name: name}})
+		}
+		keys = append(keys, name)
+	}
+
+	var parts []string
+	var cols []column
+	for _, col := range entities {
+		parts = append(parts, col.expr+" AS "+quote(col.name))
+		switch col.kind {
+		case kindNodes, kindEdges:
+			col.nodes = b.hiddenColumn("size")
+			col.edges = col.nodes
+			parts = append(parts, "size("+col.expr+") AS "+quote(col.nodes))
+		case kindPath:
+			// A path is returned as its nodes and edges.
+			parts[len(parts)-1] = "nodes(" + col.expr + ") AS " + quote(col.name)
+			edges := b.hiddenColumn("edges")
+			col.nodes, col.edges = b.hiddenColumn("size"), b.hiddenColumn("size")
+			parts = append(parts, "relationships("+col.expr+") AS "+quote(edges),
+				"size(nodes("+col.expr+")) AS "+quote(col.nodes),
+				"size(relationships("+col.expr+")) AS "+quote(col.edges))
+		}
+		cols = append(cols, col.column)
+	}
+	for _, col := range append(values, hidden...) {
+		parts = append(parts, col.expr+" AS "+quote(col.name))
+		if col.kind == kindValue && returned[col.name] {
+			cols = append(cols, col.column)
+		}
+	}
+
+	if cond := b.after(query, keys); cond != "" {
+		// The keyset condition applies to the sort keys, projected first.
+		b.add("WITH %s", strings.Join(parts, ", "))
+		b.add("WHERE %s", cond)
+		for i, part := range parts {
+			name := part[strings.LastIndex(part, " AS ")+len(" AS "):]
+			parts[i] = name + " AS " + name
+		}
+	}
+	b.add("RETURN %s", strings.Join(parts, ", "))
+
+	if len(keys) > 0 {
+		sorts := make([]string, len(keys))
+		for i, key := range keys {
+			dir := " DESC"
+			if query.OrderBy[i].Asc {
+				dir = " ASC"
+			}
+			sorts[i] = quote(key) + dir
+		}
+		b.add("ORDER BY %s", strings.Join(sorts, ", "))
+	}
+	if query.Skip != nil {
+		b.add("SKIP %d", *query.Skip)
+	}
+	if query.Limit != nil {
+		b.add("LIMIT %d", *query.Limit)
+	}
+	return cols
+}
+
+// orderExpression returns the expression sorted on by o, and the name of the
+// item returning it, if any: nodes sort by their ID, and the other aliases by
+// their value.
+func (b *ngqlBuilder) orderExpression(o graph.Order) (string, string) {
+	if o.Property != "" {
+		return b.prop(o.Alias, o.Property), o.Alias + "." + o.Property
+	}
+	if b.kinds[o.Alias] == kindNode {
+		return "id(" + o.Alias + ")", "id(" + o.Alias + ")"
+	}
+	return o.Alias, o.Alias
+}
+
+// after returns the keyset condition selecting the records sorting after the
+// values of query.After, on the sort keys, see the neo4j client.
+func (b *ngqlBuilder) after(query *graph.Query, keys []string) string {
+	n := min(len(query.OrderBy), len(query.After))
+	if n == 0 {
+		return ""
+	}
+	var equals, branches []string
+	for i := 0; i < n; i++ {
+		key, value := quote(keys[i]), b.literal(query.After[i])
+		op := " < "
+		if query.OrderBy[i].Asc {
+			op = " > "
+		}
+		branch := append(slices.Clone(equals), key+op+value)
+		branches = append(branches, "("+strings.Join(branch, " AND ")+")")
+		equals = append(equals, key+" == "+value)
+	}
+	return strings.Join(branches, " OR ")
+}
+
+// buildQueryNGQL translates query to a MATCH statement, and returns the
+// columns of its records, named after the Return items, or the pattern
+// aliases if there are none.
+func buildQueryNGQL(query *graph.Query) (string, []column, error) {
+	if err := query.ValidateNames(); err != nil {
+		return "", nil, err
+	}
+	b := newBuilder()
+	b.body(query)
+	cols := b.returns(query)
+	if b.err != nil {
+		return "", nil, b.err
+	}
+	return b.String(), cols, nil
+}
+
+// buildCountNGQL translates query to a MATCH statement counting its records
+// in the column count.
+func buildCountNGQL(query *graph.Query) (string, error) {
+	if err := query.ValidateNames(); err != nil {
+		return "", err
+	}
+	b := newBuilder()
+	b.body(query)
+	b.add("RETURN count(*) AS `count`")
+	if b.err != nil {
+		return "", b.err
+	}
+	return b.String(), nil
+}
+
+// buildTargetNGQL translates query to a MATCH statement returning the
+// entities to update or delete: those of the first node alias if edges is
+// false, or of the edge alias of the first pattern otherwise.
+func buildTargetNGQL(query *graph.Query, edges bool) (string, []column, error) {
+	if len(query.Match) == 0 {
+		return "", nil, fmt.Errorf("nebula: query matches no pattern")
+	}
+	first := query.Match[0]
+	target := first.Alias
+	if target == "" {
+		target = defaultNodeAlias
+	}
+	if edges {
+		if first.Edge == nil {
+			return "", nil, fmt.Errorf("nebula: the first pattern of the query has no edge")
+		}
+		target = first.Edge.Alias
+		if target == "" {
+			target = defaultEdgeAlias
+		}
+	}
+	return buildQueryNGQL(&graph.Query{
+		Match:  query.Match,
+		Where:  query.Where,
+		Return: []graph.Return{{Expression: target}},
+	})
+}
@@ -0,0 +1,1032 @@
+package nebula
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"maps"
+	"reflect"
+	"slices"
+	"strings"
+
+	"github.com/me2seeks/forge/idgen"
+	"github.com/me2seeks/forge/infra/contract/graph"
+)
+
+func (c *nebulaClient) CreateNode(ctx context.Context, node *graph.Node) (*graph.Node, error) {
+	nodes, err := c.CreateNodes(ctx, []*graph.Node{node})
+	if err != nil {
+		return nil, err
+	}
+	return nodes[0], nil
+}
+
+// CreateNodes inserts the vertices in a single request, which isn't atomic.
+// The nodes without ID get a ULID.
+func (c *nebulaClient) CreateNodes(ctx context.Context, nodes []*graph.Node) ([]*graph.Node, error) {
+	if len(nodes) == 0 {
+		return nil, nil
+	}
+	created := make([]*graph.Node, len(nodes))
+	stmts := make([]string, len(nodes))
+	for i, node := range nodes {
+		if err := validateNode(node); err != nil {
+			return nil, err
+		}
+		created[i] = &graph.Node{
+			ID:         node.ID,
+			Labels:     slices.Clone(node.Labels),
+			Properties: maps.Clone(node.Properties),
+		}
+		if created[i].ID == "" {
+			created[i].ID = idgen.NewULID()
+		}
+		if created[i].Properties == nil {
+			created[i].Properties = graph.Properties{}
+		}
+		stmt, err := c.insertVertex(ctx, created[i], created[i].Labels)
+		if err != nil {
+			return nil, err
+		}
+		stmts[i] = stmt
+	}
+	if _, err := c.exec(ctx, strings.Join(stmts, "; ")); err != nil {
+		return nil, err
+	}
+	return created, nil
+}
+
+// validateNode validates the labels and property keys of node, which are
+// written in the statements, see graph.ValidateName.
+func validateNode(node *graph.Node) error {
+	if err := graph.ValidateNames(node.Labels...); err != nil {
+		return err
+	}
+	return node.Properties.ValidateNames()
+}
+
+// validateEdge validates the names of edge and its property keys, see
+// graph.ValidateName.
+func validateEdge(edge *graph.Edge) error {
+	if err := edge.ValidateNames(); err != nil {
+		return err
+	}
+	return edge.Properties.ValidateNames()
+}
+
+// insertVertex returns the statement inserting the tags of node, with the
+// properties of node they declare.
+func (c *nebulaClient) insertVertex(ctx context.Context, node *graph.Node, tags []string) (string, error) {
+	if len(node.Labels) == 0 {
+		return "", fmt.Errorf("nebula: node %s has no label, to tag its vertex with", node.ID)
+	}
+	split, err := c.splitProperties(ctx, node.Labels, node.Properties)
+	if err != nil {
+		return "", err
+	}
+	var schemas, values []string
+	for _, tag := range tags {
+		keys := split[tag]
+		quoted := make([]string, len(keys))
+		for i, key := range keys {
+			quoted[i] = quote(key)
+			value, err := literal(node.Properties[key])
+			if err != nil {
+				return "", err
+			}
+			values = append(values, value)
+		}
+		schemas = append(schemas, quote(tag)+"("+strings.Join(quoted, ", ")+")")
+	}
+	return fmt.Sprintf("INSERT VERTEX %s VALUES %s:(%s)", strings.Join(schemas, ", "), quoteString(node.ID), strings.Join(values, ", ")), nil
+}
+
+// updateVertex returns the statements setting properties on the tags of node
+// declaring them, none if properties is empty.
+func (c *nebulaClient) updateVertex(ctx context.Context, node *graph.Node, properties graph.Properties) ([]string, error) {
+	split, err := c.splitProperties(ctx, node.Labels, properties)
+	if err != nil {
+		return nil, err
+	}
+	var stmts []string
+	for _, tag := range node.Labels {
+		keys := split[tag]
+		if len(keys) == 0 {
+			continue
+		}
+		sets := make([]string, len(keys))
+		for i, key := range keys {
+			value, err := literal(properties[key])
+			if err != nil {
+				return nil, err
+			}
+			sets[i] = quote(key) + " = " + value
+		}
+		stmts = append(stmts, fmt.Sprintf("UPDATE VERTEX ON %s %s SET %s", quote(tag), quoteString(node.ID), strings.Join(sets, ", ")))
+	}
+	return stmts, nil
+}
+
+// splitProperties returns the keys of properties declared by each of tags,
+// sorted. It fails if a key is declared by none.
+func (c *nebulaClient) splitProperties(ctx context.Context, tags []string, properties graph.Properties) (map[string][]string, error) {
+	split := make(map[string][]string, len(tags))
+	declared := make(map[string]bool, len(properties))
+	for _, tag := range tags {
+		fields, err := c.tagFields(ctx, tag)
+		if err != nil {
+			return nil, err
+		}
+		for _, key := range slices.Sorted(maps.Keys(properties)) {
+			if slices.ContainsFunc(fields, func(f field) bool { return f.name == key }) {
+				split[tag] = append(split[tag], key)
+				declared[key] = true
+			}
+		}
+	}
+	for _, key := range slices.Sorted(maps.Keys(properties)) {
+		if !declared[key] {
+			return nil, fmt.Errorf("nebula: property %s isn't declared by any of the tags %v", key, tags)
+		}
+	}
+	return split, nil
+}
+
+// GetNode fetches the vertex with all its tags.
+func (c *nebulaClient) GetNode(ctx context.Context, nodeID string) (*graph.Node, error) {
+	nodes, err := c.fetchNodes(ctx, []string{nodeID})
+	if err != nil || len(nodes) == 0 {
+		return nil, err
+	}
+	return nodes[0], nil
+}
+
+// fetchNodes fetches the vertices with IDs, skipping the missing ones.
+func (c *nebulaClient) fetchNodes(ctx context.Context, ids []string) ([]*graph.Node, error) {
+	refs := make([]string, len(ids))
+	for i, id := range ids {
+		refs[i] = quoteString(id)
+	}
+	res, err := c.exec(ctx, "FETCH PROP ON * "+strings.Join(refs, ", ")+" YIELD vertex AS `n`")
+	if err != nil {
+		return nil, err
+	}
+	var nodes []*graph.Node
+	for _, record := range decodeRecords(res, []column{{name: "n", kind: kindNode}}) {
+		if node, ok := record["n"].(*graph.Node); ok {
+			nodes = append(nodes, node)
+		}
+	}
+	return nodes, nil
+}
+
+// UpdateNode sets properties on the tags of the node declaring them. As
+// Nebula's properties always exist, setting a property to nil sets it to
+// null. It does nothing if the node is missing.
+func (c *nebulaClient) UpdateNode(ctx context.Context, nodeID string, properties graph.Properties) error {
+	if len(properties) == 0 {
+		return nil
+	}
+	if err := properties.ValidateNames(); err != nil {
+		return err
+	}
+	node, err := c.GetNode(ctx, nodeID)
+	if err != nil || node == nil {
+		return err
+	}
+	stmts, err := c.updateVertex(ctx, node, properties)
+	if err != nil {
+		return err
+	}
+	_, err = c.exec(ctx, strings.Join(stmts, "; "))
+	return err
+}
+
+// DeleteNode deletes the node and its edges.
+func (c *nebulaClient) DeleteNode(ctx context.Context, nodeID string) error {
+	_, err := c.exec(ctx, "DELETE VERTEX "+quoteString(nodeID)+" WITH EDGE")
+	return err
+}
+
+// MergeNode looks the node up by its tags and the values of matchKeys, which
+// needs an index on one of its tags, and then inserts or updates it. Unlike
+// the neo4j client, it isn't atomic.
+func (c *nebulaClient) MergeNode(ctx context.Context, node *graph.Node, matchKeys []string) (*graph.Node, error) {
+	if len(matchKeys) == 0 {
+		return nil, fmt.Errorf("merge node: no match keys")
+	}
+	if err := validateNode(node); err != nil {
+		return nil, err
+	}
+	match := make(graph.Properties, len(matchKeys))
+	for _, key := range matchKeys {
+		value, ok := node.Properties[key]
+		if !ok {
+			return nil, fmt.Errorf("merge node: match key %s is not a property of the node", key)
+		}
+		match[key] = value
+	}
+	existing, err := c.FindNodes(ctx, &graph.Query{
+		Match: []graph.Pattern{{Alias: "n", Labels: node.Labels, Properties: match}},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	switch len(existing) {
+	case 0:
+		return c.CreateNode(ctx, &graph.Node{Labels: node.Labels, Properties: node.Properties})
+	case 1:
+	default:
+		return nil, fmt.Errorf("merge node: %d nodes match", len(existing))
+	}
+
+	// The tags the node lacks are inserted, and the others updated.
+	merged := &graph.Node{ID: existing[0].ID, Labels: node.Labels, Properties: node.Properties}
+	var missing []string
+	for _, tag := range node.Labels {
+		if !slices.Contains(existing[0].Labels, tag) {
+			missing = append(missing, tag)
+		}
+	}
+	var stmts []string
+	if len(missing) > 0 {
+		stmt, err := c.insertVertex(ctx, merged, missing)
+		if err != nil {
+			return nil, err
+		}
+		stmts = append(stmts, stmt)
+	}
+	updates, err := c.updateVertex(ctx, &graph.Node{ID: merged.ID, Labels: existing[0].Labels}, onTags(node.Properties, existing[0].Properties))
+	if err != nil {
+		return nil, err
+	}
+	stmts = append(stmts, updates...)
+	if len(stmts) > 0 {
+		if _, err := c.exec(ctx, strings.Join(stmts, "; ")); err != nil {
+			return nil, err
+		}
+	}
+	return c.GetNode(ctx, merged.ID)
+}
+
+// onTags returns the properties of props which existing has, i.e. declared by
+// the tags of an existing node.
+func onTags(props, existing graph.Properties) graph.Properties {
+	on := make(graph.Properties, len(props))
+	for key, value := range props {
+		if _, ok := existing[key]; ok {
+			on[key] = value
+		}
+	}
+	return on
+}
+
+// CreateEdge inserts an edge for each pair of endpoints, selected by their
+// selectors if both are set, or by their IDs otherwise, and returns the first
+// one, or nil if an endpoint is missing. Edges have the rank 0, so an edge of
+// the same type between the same nodes is replaced, see MergeEdge.
+func (c *nebulaClient) CreateEdge(ctx context.Context, edge *graph.Edge) (*graph.Edge, error) {
+	if err := validateEdge(edge); err != nil {
+		return nil, err
+	}
+	sources, targets, err := c.endpoints(ctx, edge)
+	if err != nil {
+		return nil, err
+	}
+	var created []*graph.Edge
+	var stmts []string
+	for _, source := range sources {
+		for _, target := range targets {
+			e := &graph.Edge{Label: edge.Label, SourceNodeID: source, TargetNodeID: target, Properties: maps.Clone(edge.Properties)}
+			stmt, err := insertEdge(e, 0)
+			if err != nil {
+				return nil, err
+			}
+			created, stmts = append(created, e), append(stmts, stmt)
+		}
+	}
+	if len(created) == 0 {
+		return nil, nil
+	}
+	if _, err := c.exec(ctx, strings.Join(stmts, "; ")); err != nil {
+		return nil, err
+	}
+	return created[0], nil
+}
+
+// endpoints returns the IDs of the existing endpoints of edge, selected by
+// their selectors if both are set.
+func (c *nebulaClient) endpoints(ctx context.Context, edge *graph.Edge) ([]string, []string, error) {
+	if edge.SourceNodeSelector != nil && edge.TargetNodeSelector != nil {
+		sources, err := c.selectNodes(ctx, edge.SourceNodeSelector)
+		if err != nil {
+			return nil, nil, err
+		}
+		targets, err := c.selectNodes(ctx, edge.TargetNodeSelector)
+		return sources, targets, err
+	}
+	existing, err := c.existing(ctx, []string{edge.SourceNodeID, edge.TargetNodeID})
+	if err != nil || !existing[edge.SourceNodeID] || !existing[edge.TargetNodeID] {
+		return nil, nil, err
+	}
+	return []string{edge.SourceNodeID}, []string{edge.TargetNodeID}, nil
+}
+
+// selectNodes returns the IDs of the nodes selected by selector.
+func (c *nebulaClient) selectNodes(ctx context.Context, selector *graph.NodeSelector) ([]string, error) {
+	nodes, err := c.FindNodes(ctx, &graph.Query{
+		Match: []graph.Pattern{{Alias: "n", Labels: selector.Labels, Properties: selector.Properties}},
+	})
+	if err != nil {
+		return nil, err
+	}
+	ids := make([]string, len(nodes))
+	for i, node := range nodes {
+		ids[i] = node.ID
+	}
+	return ids, nil
+}
+
+// existing returns which of the vertices with ids exist.
+func (c *nebulaClient) existing(ctx context.Context, ids []string) (map[string]bool, error) {
+	nodes, err := c.fetchNodes(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+	existing := make(map[string]bool, len(nodes))
+	for _, node := range nodes {
+		existing[node.ID] = true
+	}
+	return existing, nil
+}
+
+// insertEdge returns the statement inserting edge with rank, and sets its ID.
+func insertEdge(edge *graph.Edge, rank int64) (string, error) {
+	if edge.Label == "" {
+		return "", fmt.Errorf("nebula: edge from %s to %s has no label", edge.SourceNodeID, edge.TargetNodeID)
+	}
+	if edge.Properties == nil {
+		edge.Properties = graph.Properties{}
+	}
+	key := edgeKey{typ: edge.Label, src: edge.SourceNodeID, dst: edge.TargetNodeID, rank: rank}
+	edge.ID = key.id()
+
+	keys := slices.Sorted(maps.Keys(edge.Properties))
+	quoted, values := make([]string, len(keys)), make([]string, len(keys))
+	for i, k := range keys {
+		value, err := literal(edge.Properties[k])
+		if err != nil {
+			return "", err
+		}
+		quoted[i], values[i] = quote(k), value
+	}
+	return fmt.Sprintf("INSERT EDGE %s(%s) VALUES %s:(%s)", quote(key.typ), strings.Join(quoted, ", "), key.ref(), strings.Join(values, ", ")), nil
+}
+
+// CreateEdges inserts the edges in a single request, which isn't atomic,
+// after checking their endpoints exist. Like CreateEdge, it replaces the
+// edges of the same type between the same nodes.
+func (c *nebulaClient) CreateEdges(ctx context.Context, edges []*graph.Edge) ([]*graph.Edge, error) {
+	if len(edges) == 0 {
+		return nil, nil
+	}
+	var ids []string
+	for _, edge := range edges {
+		if err := validateEdge(edge); err != nil {
+			return nil, err
+		}
+		ids = append(ids, edge.SourceNodeID, edge.TargetNodeID)
+	}
+	existing, err := c.existing(ctx, slices.Compact(slices.Sorted(slices.Values(ids))))
+	if err != nil {
+		return nil, err
+	}
+
+	created := make([]*graph.Edge, len(edges))
+	stmts := make([]string, len(edges))
+	for i, edge := range edges {
+		if !existing[edge.SourceNodeID] || !existing[edge.TargetNodeID] {
+			return nil, fmt.Errorf("create edges: endpoint of edge %d not found", i)
+		}
+		created[i] = &graph.Edge{Label: edge.Label, SourceNodeID: edge.SourceNodeID, TargetNodeID: edge.TargetNodeID, Properties: maps.Clone(edge.Properties)}
+		if stmts[i], err = insertEdge(created[i], 0); err != nil {
+			return nil, err
+		}
+	}
+	if _, err := c.exec(ctx, strings.Join(stmts, "; ")); err != nil {
+		return nil, err
+	}
+	return created, nil
+}
+
+func (c *nebulaClient) GetEdge(ctx context.Context, edgeID string) (*graph.Edge, error) {
+	key, err := parseEdgeID(edgeID)
+	if err != nil {
+		return nil, err
+	}
+	res, err := c.exec(ctx, "FETCH PROP ON "+quote(key.typ)+" "+key.ref()+" YIELD edge AS `r`")
+	if err != nil {
+		return nil, err
+	}
+	for _, record := range decodeRecords(res, []column{{name: "r", kind: kindEdge}}) {
+		if edge, ok := record["r"].(*graph.Edge); ok {
+			return edge, nil
+		}
+	}
+	return nil, nil
+}
+
+// UpdateEdge sets properties on the edge; setting a property to nil sets it
+// to null.
+func (c *nebulaClient) UpdateEdge(ctx context.Context, edgeID string, properties graph.Properties) error {
+	key, err := parseEdgeID(edgeID)
+	if err != nil {
+		return err
+	}
+	stmt, err := updateEdge(key, properties)
+	if err != nil || stmt == "" {
+		return err
+	}
+	_, err = c.exec(ctx, stmt)
+	return err
+}
+
+// updateEdge returns the statement setting properties on the edge, empty if
+// properties is.
+func updateEdge(key edgeKey, properties graph.Properties) (string, error) {
+	if err := properties.ValidateNames(); err != nil {
+		return "", err
+	}
+	keys := slices.Sorted(maps.Keys(properties))
+	if len(keys) == 0 {
+		return "", nil
+	}
+	sets := make([]string, len(keys))
+	for i, k := range keys {
+		value, err := literal(properties[k])
+		if err != nil {
+			return "", err
+		}
+		sets[i] = quote(k) + " = " + value
+	}
+	return fmt.Sprintf("UPDATE EDGE ON %s %s SET %s", quote(key.typ), key.ref(), strings.Join(sets, ", ")), nil
+}
+
+func (c *nebulaClient) DeleteEdge(ctx context.Context, edgeID string) error {
+	key, err := parseEdgeID(edgeID)
+	if err != nil {
+		return err
+	}
+	_, err = c.exec(ctx, "DELETE EDGE "+quote(key.typ)+" "+key.ref())
+	return err
+}
+
+// MergeEdge matches the edges of the type between the endpoints having the
+// properties of edge, or inserts one with the next rank, so that edges of the
+// same type between the same nodes are kept apart. Unlike the neo4j client, it
+// isn't atomic.
+func (c *nebulaClient) MergeEdge(ctx context.Context, edge *graph.Edge, onCreate, onMatch graph.Properties) (*graph.Edge, error) {
+	if edge.Label == "" {
+		return nil, fmt.Errorf("merge edge: no label")
+	}
+	if err := validateEdge(edge); err != nil {
+		return nil, err
+	}
+	if err := graph.ValidateNames(slices.Concat(slices.Collect(maps.Keys(onCreate)), slices.Collect(maps.Keys(onMatch)))...); err != nil {
+		return nil, err
+	}
+	sources, targets, err := c.endpoints(ctx, edge)
+	if err != nil {
+		return nil, err
+	}
+	switch n := len(sources) * len(targets); {
+	case n == 0:
+		return nil, nil
+	case n > 1:
+		return nil, fmt.Errorf("merge edge: endpoints match %d pairs of nodes", n)
+	}
+
+	stmt := fmt.Sprintf("GO FROM %s OVER %s WHERE dst(edge) == %s YIELD edge AS `r`", quoteString(sources[0]), quote(edge.Label), quoteString(targets[0]))
+	res, err := c.exec(ctx, stmt)
+	if err != nil {
+		return nil, err
+	}
+	rank := int64(0)
+	for _, record := range decodeRecords(res, []column{{name: "r", kind: kindEdge}}) {
+		existing, ok := record["r"].(*graph.Edge)
+		if !ok {
+			continue
+		}
+		key, err := parseEdgeID(existing.ID)
+		if err != nil {
+			return nil, err
+		}
+		if !hasProperties(existing.Properties, edge.Properties) {
+			rank = max(rank, key.rank+1)
+			continue
+		}
+		stmt, err := updateEdge(key, onMatch)
+		if err != nil || stmt == "" {
+			return existing, err
+		}
+		if _, err := c.exec(ctx, stmt); err != nil {
+			return nil, err
+		}
+		return c.GetEdge(ctx, existing.ID)
+	}
+
+	created := &graph.Edge{Label: edge.Label, SourceNodeID: sources[0], TargetNodeID: targets[0], Properties: maps.Clone(edge.Properties)}
+	if created.Properties == nil {
+		created.Properties = graph.Properties{}
+	}
+	maps.Copy(created.Properties, onCreate)
+	if stmt, err = insertEdge(created, rank); err != nil {
+		return nil, err
+	}
+	if _, err := c.exec(ctx, stmt); err != nil {
+		return nil, err
+	}
+	return created, nil
+}
+
+// hasProperties reports whether props has the values of want, numbers being
+// compared by value.
+func hasProperties(props, want graph.Properties) bool {
+	for key, value := range want {
+		got, ok := props[key]
+		if !ok {
+			return false
+		}
+		if a, ok := toFloat(got); ok {
+			if b, ok := toFloat(value); ok && a == b {
+				continue
+			}
+		}
+		if !reflect.DeepEqual(got, value) {
+			return false
+		}
+	}
+	return true
+}
+
+func toFloat(value any) (float64, bool) {
+	rv := reflect.ValueOf(value)
+	switch rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(rv.Int()), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return float64(rv.Uint()), true
+	case reflect.Float32, reflect.Float64:
+		return rv.Float(), true
+	default:
+		return 0, false
+	}
+}
+
+// GetNeighbors matches the walks from the node and returns their distinct end
+// nodes and edges, in the order of the rows.
+func (c *nebulaClient) GetNeighbors(ctx context.Context, nodeID string, opts ...graph.NeighborOpt) (*graph.Neighborhood, error) {
+	o := graph.NewNeighborOptions(opts...)
+	if err := graph.ValidateNames(o.RelationshipTypes...); err != nil {
+		return nil, err
+	}
+	res, err := c.exec(ctx, buildNeighborsNGQL(nodeID, o))
+	if err != nil {
+		return nil, err
+	}
+	neighborhood := &graph.Neighborhood{}
+	nodes, edges := make(map[string]bool), make(map[string]bool)
+	for _, record := range decodeRecords(res, []column{{name: "m", kind: kindNode}, {name: "r", kind: kindEdge}}) {
+		if node, ok := record["m"].(*graph.Node); ok && !nodes[node.ID] {
+			nodes[node.ID] = true
+			neighborhood.Nodes = append(neighborhood.Nodes, node)
+		}
+		if edge, ok := record["r"].(*graph.Edge); ok && !edges[edge.ID] {
+			edges[edge.ID] = true
+			neighborhood.Edges = append(neighborhood.Edges, edge)
+		}
+	}
+	return neighborhood, nil
+}
+
+// buildNeighborsNGQL returns the statement of GetNeighbors, returning a row
+// per end node m and edge r of the walks.
+func buildNeighborsNGQL(nodeID string, o *graph.NeighborOptions) string {
+	rel := "["
+	if len(o.RelationshipTypes) > 0 {
+		quoted := make([]string, len(o.RelationshipTypes))
+		for i, typ := range o.RelationshipTypes {
+			quoted[i] = quote(typ)
+		}
+		rel += ":" + strings.Join(quoted, "|")
+	}
+	if o.Depth > 1 {
+		rel += fmt.Sprintf("*1..%d", o.Depth)
+	}
+	rel += "]"
+
+	pattern := "-" + rel + "-"
+	switch o.Direction {
+	case graph.DirectionOutgoing:
+		pattern = "-" + rel + "->"
+	case graph.DirectionIncoming:
+		pattern = "<-" + rel + "-"
+	}
+	id := quoteString(nodeID)
+	return "MATCH p = (n)" + pattern + "(m) WHERE id(n) == " + id + " AND id(m) != " + id +
+		" UNWIND relationships(p) AS r RETURN DISTINCT m AS `m`, r AS `r`"
+}
+
+// Traverse loads the subgraph within the depth of spec from the node with GET
+// SUBGRAPH, and runs the traversal in memory over it.
+func (c *nebulaClient) Traverse(ctx context.Context, startNodeID string, spec graph.TraversalSpec) ([]*graph.Path, error) {
+	o := spec.NeighborOptions()
+	if err := graph.ValidateNames(o.RelationshipTypes...); err != nil {
+		return nil, err
+	}
+	if o.Direction != graph.DirectionBoth && len(o.RelationshipTypes) == 0 {
+		// GET SUBGRAPH only takes a direction with the edge types.
+		types, err := c.ListRelationshipTypes(ctx)
+		if err != nil {
+			return nil, err
+		}
+		o.RelationshipTypes = types
+	}
+	res, err := c.exec(ctx, buildSubgraphNGQL(startNodeID, o))
+	if err != nil {
+		return nil, err
+	}
+	// Each row holds the nodes reached at a step and their edges.
+	var nodes []*graph.Node
+	var edges []*graph.Edge
+	seenNodes, seenEdges := make(map[string]bool), make(map[string]bool)
+	for _, record := range decodeRecords(res, []column{{name: "nodes", kind: kindNodes}, {name: "edges", kind: kindEdges}}) {
+		stepNodes, _ := record["nodes"].([]*graph.Node)
+		for _, node := range stepNodes {
+			if !seenNodes[node.ID] {
+				seenNodes[node.ID] = true
+				nodes = append(nodes, node)
+			}
+		}
+		stepEdges, _ := record["edges"].([]*graph.Edge)
+		for _, edge := range stepEdges {
+			if !seenEdges[edge.ID] {
+				seenEdges[edge.ID] = true
+				edges = append(edges, edge)
+			}
+		}
+	}
+	return graph.TraverseSubgraph(startNodeID, nodes, edges, spec)
+}
+
+// buildSubgraphNGQL returns the statement loading the subgraph of Traverse,
+// returning a row per step with its nodes and edges.
+func buildSubgraphNGQL(nodeID string, o *graph.NeighborOptions) string {
+	stmt := fmt.Sprintf("GET SUBGRAPH WITH PROP %d STEPS FROM %s", o.Depth, quoteString(nodeID))
+	if len(o.RelationshipTypes) > 0 {
+		quoted := make([]string, len(o.RelationshipTypes))
+		for i, typ := range o.RelationshipTypes {
+			quoted[i] = quote(typ)
+		}
+		direction := "BOTH"
+		switch o.Direction {
+		case graph.DirectionOutgoing:
+			direction = "OUT"
+		case graph.DirectionIncoming:
+			direction = "IN"
+		}
+		stmt += " " + direction + " " + strings.Join(quoted, ", ")
+	}
+	return stmt + " YIELD VERTICES AS `nodes`, EDGES AS `edges`"
+}
+
+func (c *nebulaClient) Query(ctx context.Context, query *graph.Query) (*graph.QueryResult, error) {
+	stmt, cols, err := buildQueryNGQL(query)
+	if err != nil {
+		return nil, err
+	}
+	res, err := c.exec(ctx, stmt)
+	if err != nil {
+		return nil, err
+	}
+	return &graph.QueryResult{Records: decodeRecords(res, cols)}, nil
+}
+
+// RawQuery runs nGQL statements, returning the columns of the last one.
+// Vertices, edges and paths are returned as their nGQL text, and params
+// aren't supported, as the gateway doesn't pass parameters.
+func (c *nebulaClient) RawQuery(ctx context.Context, query string, params map[string]any) (*graph.QueryResult, error) {
+	if len(params) > 0 {
+		return nil, unsupported("query parameters")
+	}
+	res, err := c.exec(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	return &graph.QueryResult{Records: rawRecords(res)}, nil
+}
+
+func (c *nebulaClient) BatchQuery(ctx context.Context, statement string, rows []map[string]any, params map[string]any) (*graph.QueryResult, error) {
+	return nil, unsupported("batch query")
+}
+
+// QueryStream runs the query like Query, as the gateway returns all the
+// records at once, and iterates over them.
+func (c *nebulaClient) QueryStream(ctx context.Context, query *graph.Query) (graph.RecordIterator, error) {
+	result, err := c.Query(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	return &recordIterator{records: result.Records}, nil
+}
+
+// ExportGraph writes the entities of the records of QueryStream.
+func (c *nebulaClient) ExportGraph(ctx context.Context, w io.Writer, format graph.ExportFormat, query *graph.Query) error {
+	return graph.Export(ctx, c, w, format, query)
+}
+
+// recordIterator iterates over records already fetched.
+type recordIterator struct {
+	records []graph.Record
+	record  graph.Record
+}
+
+func (it *recordIterator) Next(ctx context.Context) bool {
+	if len(it.records) == 0 {
+		it.record = nil
+		return false
+	}
+	it.record, it.records = it.records[0], it.records[1:]
+	return true
+}
+
+func (it *recordIterator) Record() graph.Record {
+	return it.record
+}
+
+func (it *recordIterator) Err() error {
+	return nil
+}
+
+func (it *recordIterator) Close(ctx context.Context) error {
+	it.records, it.record = nil, nil
+	return nil
+}
+
+func (c *nebulaClient) FindNodes(ctx context.Context, query *graph.Query) ([]*graph.Node, error) {
+	result, err := c.Query(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	var nodes []*graph.Node
+	seen := make(map[string]struct{})
+	for _, record := range result.Records {
+		for _, entity := range record {
+			if node, ok := entity.(*graph.Node); ok {
+				if _, exists := seen[node.ID]; !exists {
+					nodes = append(nodes, node)
+					seen[node.ID] = struct{}{}
+				}
+			}
+		}
+	}
+	return nodes, nil
+}
+
+func (c *nebulaClient) FindEdges(ctx context.Context, query *graph.Query) ([]*graph.Edge, error) {
+	result, err := c.Query(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	var edges []*graph.Edge
+	seen := make(map[string]struct{})
+	for _, record := range result.Records {
+		for _, entity := range record {
+			if edge, ok := entity.(*graph.Edge); ok {
+				if _, exists := seen[edge.ID]; !exists {
+					edges = append(edges, edge)
+					seen[edge.ID] = struct{}{}
+				}
+			}
+		}
+	}
+	return edges, nil
+}
+
+func (c *nebulaClient) FindPaths(ctx context.Context, query *graph.Query) ([]*graph.Path, error) {
+	result, err := c.Query(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	var paths []*graph.Path
+	for _, record := range result.Records {
+		for _, entity := range record {
+			if path, ok := entity.(*graph.Path); ok {
+				paths = append(paths, path)
+			}
+		}
+	}
+	return paths, nil
+}
+
+func (c *nebulaClient) Count(ctx context.Context, query *graph.Query) (int64, error) {
+	stmt, err := buildCountNGQL(query)
+	if err != nil {
+		return 0, err
+	}
+	res, err := c.exec(ctx, stmt)
+	if err != nil || len(res.Tables) == 0 {
+		return 0, err
+	}
+	return int64(toInt(res.Tables[0]["count"])), nil
+}
+
+// UpdateNodesByQuery sets properties on the nodes of the first alias of the
+// query, on the tags of each declaring them.
+func (c *nebulaClient) UpdateNodesByQuery(ctx context.Context, query *graph.Query, properties graph.Properties) (int, error) {
+	if err := properties.ValidateNames(); err != nil {
+		return 0, err
+	}
+	nodes, err := c.targetNodes(ctx, query)
+	if err != nil || len(properties) == 0 {
+		return len(nodes), err
+	}
+	var stmts []string
+	for _, node := range nodes {
+		updates, err := c.updateVertex(ctx, node, properties)
+		if err != nil {
+			return 0, err
+		}
+		stmts = append(stmts, updates...)
+	}
+	return len(nodes), c.execAll(ctx, stmts)
+}
+
+// UpdateEdgesByQuery sets properties on the edges of the edge alias of the
+// first pattern of the query.
+func (c *nebulaClient) UpdateEdgesByQuery(ctx context.Context, query *graph.Query, properties graph.Properties) (int, error) {
+	if err := properties.ValidateNames(); err != nil {
+		return 0, err
+	}
+	keys, err := c.targetEdges(ctx, query)
+	if err != nil || len(properties) == 0 {
+		return len(keys), err
+	}
+	stmts := make([]string, len(keys))
+	for i, key := range keys {
+		if stmts[i], err = updateEdge(key, properties); err != nil {
+			return 0, err
+		}
+	}
+	return len(keys), c.execAll(ctx, stmts)
+}
+
+// DeleteNodesByQuery deletes the nodes of the first alias of the query and
+// their edges.
+func (c *nebulaClient) DeleteNodesByQuery(ctx context.Context, query *graph.Query) (int, error) {
+	nodes, err := c.targetNodes(ctx, query)
+	if err != nil || len(nodes) == 0 {
+		return 0, err
+	}
+	refs := make([]string, len(nodes))
+	for i, node := range nodes {
+		refs[i] = quoteString(node.ID)
+	}
+	return len(nodes), c.execAll(ctx, []string{"DELETE VERTEX " + strings.Join(refs, ", ") + " WITH EDGE"})
+}
+
+// DeleteEdgesByQuery deletes the edges of the edge alias of the first pattern
+// of the query.
+func (c *nebulaClient) DeleteEdgesByQuery(ctx context.Context, query *graph.Query) (int, error) {
+	keys, err := c.targetEdges(ctx, query)
+	if err != nil || len(keys) == 0 {
+		return 0, err
+	}
+	// A statement deletes the edges of a single type.
+	refs := make(map[string][]string)
+	for _, key := range keys {
+		refs[key.typ] = append(refs[key.typ], key.ref())
+	}
+	var stmts []string
+	for _, typ := range slices.Sorted(maps.Keys(refs)) {
+		stmts = append(stmts, "DELETE EDGE "+quote(typ)+" "+strings.Join(refs[typ], ", "))
+	}
+	return len(keys), c.execAll(ctx, stmts)
+}
+
+// execAll runs stmts in a single request, if any.
+func (c *nebulaClient) execAll(ctx context.Context, stmts []string) error {
+	if len(stmts) == 0 {
+		return nil
+	}
+	_, err := c.exec(ctx, strings.Join(stmts, "; "))
+	return err
+}
+
+// targetNodes returns the distinct nodes to update or delete by query.
+func (c *nebulaClient) targetNodes(ctx context.Context, query *graph.Query) ([]*graph.Node, error) {
+	stmt, cols, err := buildTargetNGQL(query, false)
+	if err != nil {
+		return nil, err
+	}
+	res, err := c.exec(ctx, stmt)
+	if err != nil {
+		return nil, err
+	}
+	var nodes []*graph.Node
+	seen := make(map[string]bool)
+	for _, record := range decodeRecords(res, cols) {
+		if node, ok := record[cols[0].name].(*graph.Node); ok && !seen[node.ID] {
+			nodes = append(nodes, node)
+			seen[node.ID] = true
+		}
+	}
+	return nodes, nil
+}
+
+// targetEdges returns the distinct edges to update or delete by query. The
+// alias of a variable-length edge targets all the edges traversed.
+func (c *nebulaClient) targetEdges(ctx context.Context, query *graph.Query) ([]edgeKey, error) {
+	stmt, cols, err := buildTargetNGQL(query, true)
+	if err != nil {
+		return nil, err
+	}
+	res, err := c.exec(ctx, stmt)
+	if err != nil {
+		return nil, err
+	}
+	var keys []edgeKey
+	seen := make(map[string]bool)
+	add := func(edge *graph.Edge) error {
+		if seen[edge.ID] {
+			return nil
+		}
+		seen[edge.ID] = true
+		key, err := parseEdgeID(edge.ID)
+		keys = append(keys, key)
+		return err
+	}
+	for _, record := range decodeRecords(res, cols) {
+		switch v := record[cols[0].name].(type) {
+		case *graph.Edge:
+			if err := add(v); err != nil {
+				return nil, err
+			}
+		case []*graph.Edge:
+			for _, edge := range v {
+				if err := add(edge); err != nil {
+					return nil, err
+				}
+			}
+		}
+	}
+	return keys, nil
+}
+
+type bulkWriter struct {
+	nodes  []*graph.Node
+	edges  []*graph.Edge
+	client *nebulaClient
+}
+
+func (c *nebulaClient) NewBulkWriter() graph.BulkWriter {
+	return &bulkWriter{
+		client: c,
+	}
+}
+
+// ImportGraph imports in batches of the bulk writer, see Import.
+func (c *nebulaClient) ImportGraph(ctx context.Context, nodes, edges io.Reader, opts ...graph.ImportOpt) (*graph.ImportResult, error) {
+	return graph.Import(ctx, c, nodes, edges, opts...)
+}
+
+func (b *bulkWriter) AddNode(ctx context.Context, node *graph.Node) error {
+	b.nodes = append(b.nodes, node)
+	return nil
+}
+
+func (b *bulkWriter) AddEdge(ctx context.Context, edge *graph.Edge) error {
+	b.edges = append(b.edges, edge)
+	return nil
+}
+
+// Close creates the added nodes, then edges, see CreateNodes and CreateEdges.
+// Without transactions, a failure keeps the entities created before it.
+func (b *bulkWriter) Close(ctx context.Context) error {
+	nodes, err := b.client.CreateNodes(ctx, b.nodes)
+	if err != nil {
+		return err
+	}
+	for i, node := range nodes {
+		b.nodes[i].ID = node.ID
+	}
+	edges, err := b.client.CreateEdges(ctx, b.edges)
+	if err != nil {
+		return err
+	}
+	for i, edge := range edges {
+		b.edges[i].ID = edge.ID
+	}
+	return nil
+}
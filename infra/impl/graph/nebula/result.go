@@ -0,0 +1,193 @@
+package nebula
+
+import (
+	"fmt"
+	"maps"
+
+	"github.com/me2seeks/forge/infra/contract/graph"
+)
+
+// The gateway returns the vertices, edges and paths of a row as text, and
+// decodes those of the columns, and of their lists, into lists under these
+// keys of the row, in column order. A decoded vertex is an object with its
+// vid, tags and properties per tag, and an edge an object with its srcID,
+// dstID, edgeName, rank and properties.
+const (
+	verticesKey = "_verticesParsedList"
+	edgesKey    = "_edgesParsedList"
+)
+
+// decodeRecords decodes the rows of res, whose columns are cols.
+func decodeRecords(res *result, cols []column) []graph.Record {
+	records := make([]graph.Record, len(res.Tables))
+	for i, row := range res.Tables {
+		records[i] = decodeRow(row, cols)
+	}
+	return records
+}
+
+// decodeRow decodes the columns cols of row, consuming its decoded vertices
+// and edges in order. Lists of entities without hidden column counting them
+// take all the entities left.
+func decodeRow(row map[string]any, cols []column) graph.Record {
+	vertices, _ := row[verticesKey].([]any)
+	edges, _ := row[edgesKey].([]any)
+	// size returns the number of entities of the hidden column name, or -1
+	// for all of them.
+	size := func(name string) int {
+		if name == "" {
+			return -1
+		}
+		return toInt(row[name])
+	}
+	take := func(list *[]any, n int) []any {
+		if n < 0 || n > len(*list) {
+			n = len(*list)
+		}
+		items := (*list)[:n]
+		*list = (*list)[n:]
+		return items
+	}
+
+	record := make(graph.Record, len(cols))
+	for _, col := range cols {
+		value := row[col.name]
+		switch col.kind {
+		case kindNode:
+			// A null isn't decoded.
+			record[col.name] = nil
+			if value == nil {
+				continue
+			}
+			if items := take(&vertices, 1); len(items) == 1 {
+				record[col.name] = toGraphNode(items[0])
+			}
+		case kindEdge:
+			// A null isn't decoded.
+			record[col.name] = nil
+			if value == nil {
+				continue
+			}
+			if items := take(&edges, 1); len(items) == 1 {
+				record[col.name] = toGraphEdge(items[0])
+			}
+		case kindNodes:
+			record[col.name] = toGraphNodes(take(&vertices, size(col.nodes)))
+		case kindEdges:
+			record[col.name] = toGraphEdges(take(&edges, size(col.edges)))
+		case kindPath:
+			record[col.name] = &graph.Path{
+				Nodes: toGraphNodes(take(&vertices, size(col.nodes))),
+				Edges: toGraphEdges(take(&edges, size(col.edges))),
+			}
+		default:
+			record[col.name] = value
+		}
+	}
+	return record
+}
+
+// rawRecords returns the rows of res as records of their columns, vertices,
+// edges and paths being their nGQL text.
+func rawRecords(res *result) []graph.Record {
+	records := make([]graph.Record, len(res.Tables))
+	for i, row := range res.Tables {
+		record := make(graph.Record, len(res.Headers))
+		for _, header := range res.Headers {
+			record[header] = row[header]
+		}
+		records[i] = record
+	}
+	return records
+}
+
+func toGraphNode(value any) *graph.Node {
+	obj, _ := value.(map[string]any)
+	node := &graph.Node{
+		ID:         toID(obj["vid"]),
+		Labels:     toStrings(obj["tags"]),
+		Properties: graph.Properties{},
+	}
+	// The properties of the tags are merged, as those of the node.
+	tags, _ := obj["properties"].(map[string]any)
+	for _, tag := range node.Labels {
+		props, _ := tags[tag].(map[string]any)
+		maps.Copy(node.Properties, props)
+	}
+	return node
+}
+
+func toGraphNodes(list []any) []*graph.Node {
+	nodes := make([]*graph.Node, len(list))
+	for i, item := range list {
+		nodes[i] = toGraphNode(item)
+	}
+	return nodes
+}
+
+func toGraphEdge(value any) *graph.Edge {
+	obj, _ := value.(map[string]any)
+	key := edgeKey{
+		typ:  toString(obj["edgeName"]),
+		src:  toID(obj["srcID"]),
+		dst:  toID(obj["dstID"]),
+		rank: int64(toInt(obj["rank"])),
+	}
+	props, _ := obj["properties"].(map[string]any)
+	if props == nil {
+		props = graph.Properties{}
+	}
+	return &graph.Edge{
+		ID:           key.id(),
+		Label:        key.typ,
+		SourceNodeID: key.src,
+		TargetNodeID: key.dst,
+		Properties:   props,
+	}
+}
+
+func toGraphEdges(list []any) []*graph.Edge {
+	edges := make([]*graph.Edge, len(list))
+	for i, item := range list {
+		edges[i] = toGraphEdge(item)
+	}
+	return edges
+}
+
+// toID returns a vertex ID, a string unless the space has INT64 vids.
+func toID(value any) string {
+	if s, ok := value.(string); ok {
+		return s
+	}
+	if value == nil {
+		return ""
+	}
+	return fmt.Sprint(value)
+}
+
+func toString(value any) string {
+	s, _ := value.(string)
+	return s
+}
+
+func toStrings(value any) []string {
+	list, _ := value.([]any)
+	strs := make([]string, 0, len(list))
+	for _, item := range list {
+		if s, ok := item.(string); ok {
+			strs = append(strs, s)
+		}
+	}
+	return strs
+}
+
+func toInt(value any) int {
+	switch v := value.(type) {
+	case int64:
+		return int(v)
+	case float64:
+		return int(v)
+	default:
+		return 0
+	}
+}
@@ -0,0 +1,312 @@
+package nebula
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/me2seeks/forge/infra/contract/graph"
+)
+
+// Schema manages the tags and edge types of a space, which Nebula needs
+// before vertices and edges use them. Nebula applies schema changes
+// asynchronously, within a heartbeat of its services, so writes right after
+// a change may fail.
+type Schema interface {
+	// CreateTag creates the tag name with properties, if it doesn't exist.
+	CreateTag(ctx context.Context, name string, properties []Property) error
+	DropTag(ctx context.Context, name string) error
+	// CreateEdgeType creates the edge type name with properties, if it
+	// doesn't exist.
+	CreateEdgeType(ctx context.Context, name string, properties []Property) error
+	DropEdgeType(ctx context.Context, name string) error
+}
+
+// Property is a property of a tag or an edge type.
+type Property struct {
+	Name string
+	// Type is the nGQL type of the property, e.g. "string", "int64",
+	// "double", "bool" or "datetime".
+	Type string
+}
+
+// AsSchema returns the schema operations of c, looking through the
+// decorators wrapping it, and whether it has them.
+func AsSchema(c graph.Client) (Schema, bool) {
+	for c != nil {
+		if schema, ok := c.(Schema); ok {
+			return schema, true
+		}
+		u, ok := c.(graph.Unwrapper)
+		if !ok {
+			break
+		}
+		c = u.Unwrap()
+	}
+	return nil, false
+}
+
+func (c *nebulaClient) CreateTag(ctx context.Context, name string, properties []Property) error {
+	if err := validateSchema(name, properties); err != nil {
+		return err
+	}
+	defer c.forgetTag(ctx, name)
+	_, err := c.exec(ctx, "CREATE TAG IF NOT EXISTS "+quote(name)+"("+propertyList(properties)+")")
+	return err
+}
+
+func (c *nebulaClient) DropTag(ctx context.Context, name string) error {
+	if err := graph.ValidateName(name); err != nil {
+		return err
+	}
+	defer c.forgetTag(ctx, name)
+	_, err := c.exec(ctx, "DROP TAG IF EXISTS "+quote(name))
+	return err
+}
+
+func (c *nebulaClient) CreateEdgeType(ctx context.Context, name string, properties []Property) error {
+	if err := validateSchema(name, properties); err != nil {
+		return err
+	}
+	_, err := c.exec(ctx, "CREATE EDGE IF NOT EXISTS "+quote(name)+"("+propertyList(properties)+")")
+	return err
+}
+
+func (c *nebulaClient) DropEdgeType(ctx context.Context, name string) error {
+	if err := graph.ValidateName(name); err != nil {
+		return err
+	}
+	_, err := c.exec(ctx, "DROP EDGE IF EXISTS "+quote(name))
+	return err
+}
+
+// validateSchema validates the name of a tag or an edge type and those of its
+// properties, see graph.ValidateName.
+func validateSchema(name string, properties []Property) error {
+	names := []string{name}
+	for _, p := range properties {
+		names = append(names, p.Name)
+	}
+	return graph.ValidateNames(names...)
+}
+
+func propertyList(properties []Property) string {
+	parts := make([]string, len(properties))
+	for i, p := range properties {
+		parts[i] = quote(p.Name) + " " + p.Type
+	}
+	return strings.Join(parts, ", ")
+}
+
+// field is a property of a tag or an edge type, as described by Nebula.
+type field struct {
+	name, typ string
+}
+
+// tagFields returns the properties of the tag, cached per space as they are
+// needed by each write of a vertex.
+func (c *nebulaClient) tagFields(ctx context.Context, tag string) ([]field, error) {
+	key := tagKey{space: c.spaceOf(ctx), tag: tag}
+	c.mu.Lock()
+	fields, ok := c.tags[key]
+	c.mu.Unlock()
+	if ok {
+		return fields, nil
+	}
+
+	fields, err := c.describe(ctx, "TAG", tag)
+	if err != nil {
+		return nil, err
+	}
+	c.mu.Lock()
+	c.tags[key] = fields
+	c.mu.Unlock()
+	return fields, nil
+}
+
+// forgetTag removes the tag from the cache of tagFields.
+func (c *nebulaClient) forgetTag(ctx context.Context, tag string) {
+	c.mu.Lock()
+	delete(c.tags, tagKey{space: c.spaceOf(ctx), tag: tag})
+	c.mu.Unlock()
+}
+
+// describe returns the properties of the tag or edge type name, schema being
+// TAG or EDGE.
+func (c *nebulaClient) describe(ctx context.Context, schema, name string) ([]field, error) {
+	res, err := c.exec(ctx, "DESCRIBE "+schema+" "+quote(name))
+	if err != nil {
+		return nil, err
+	}
+	fields := make([]field, len(res.Tables))
+	for i, row := range res.Tables {
+		fields[i] = field{name: toString(row["Field"]), typ: toString(row["Type"])}
+	}
+	return fields, nil
+}
+
+// indexedStringLength is the length of the prefix of the string properties
+// indexed, which Nebula needs for variable-length strings.
+const indexedStringLength = 64
+
+// indexType is the type of Nebula's indexes, as opposed to full-text ones.
+const indexType = "NATIVE"
+
+// CreateNodeIndex creates an index of the tag label on properties, or of the
+// tag itself if there are none, which lets MATCH scan its vertices. Indexes
+// only cover the vertices written after them: those written before are
+// indexed by rebuilding the index, e.g. with REBUILD TAG INDEX.
+func (c *nebulaClient) CreateNodeIndex(ctx context.Context, label string, properties []string) error {
+	return c.createIndex(ctx, graph.EntityNode, label, properties)
+}
+
+// CreateEdgeIndex creates an index of the edge type label, like
+// CreateNodeIndex.
+func (c *nebulaClient) CreateEdgeIndex(ctx context.Context, label string, properties []string) error {
+	return c.createIndex(ctx, graph.EntityRelationship, label, properties)
+}
+
+func (c *nebulaClient) DropNodeIndex(ctx context.Context, label string, properties []string) error {
+	if err := graph.ValidateNames(append([]string{label}, properties...)...); err != nil {
+		return err
+	}
+	_, err := c.exec(ctx, "DROP TAG INDEX IF EXISTS "+quote(indexName(graph.EntityNode, label, properties)))
+	return err
+}
+
+func (c *nebulaClient) DropEdgeIndex(ctx context.Context, label string, properties []string) error {
+	if err := graph.ValidateNames(append([]string{label}, properties...)...); err != nil {
+		return err
+	}
+	_, err := c.exec(ctx, "DROP EDGE INDEX IF EXISTS "+quote(indexName(graph.EntityRelationship, label, properties)))
+	return err
+}
+
+// indexName names the indexes, e.g. index_node_Person_name.
+func indexName(entityType graph.EntityType, label string, properties []string) string {
+	return "index_" + strings.ToLower(string(entityType)) + "_" + label + "_" + strings.Join(properties, "_")
+}
+
+// schemaKeyword returns the nGQL keyword of the schemas of entityType.
+func schemaKeyword(entityType graph.EntityType) string {
+	if entityType == graph.EntityRelationship {
+		return "EDGE"
+	}
+	return "TAG"
+}
+
+func (c *nebulaClient) createIndex(ctx context.Context, entityType graph.EntityType, label string, properties []string) error {
+	if err := graph.ValidateNames(append([]string{label}, properties...)...); err != nil {
+		return err
+	}
+	schema := schemaKeyword(entityType)
+	fields, err := c.describe(ctx, schema, label)
+	if err != nil {
+		return err
+	}
+	columns := make([]string, len(properties))
+	for i, property := range properties {
+		columns[i] = quote(property)
+		j := slices.IndexFunc(fields, func(f field) bool { return f.name == property })
+		if j < 0 {
+			return fmt.Errorf("nebula: property %s isn't declared by %s", property, label)
+		}
+		if fields[j].typ == "string" {
+			columns[i] += fmt.Sprintf("(%d)", indexedStringLength)
+		}
+	}
+	_, err = c.exec(ctx, fmt.Sprintf("CREATE %s INDEX IF NOT EXISTS %s ON %s(%s)",
+		schema, quote(indexName(entityType, label, properties)), quote(label), strings.Join(columns, ", ")))
+	return err
+}
+
+// Nebula has neither constraints nor full-text indexes of its own: full-text
+// indexes are kept by Elasticsearch listeners.
+
+func (c *nebulaClient) CreateConstraint(ctx context.Context, label, property string, constraintType graph.ConstraintType) error {
+	return unsupported("constraints")
+}
+
+func (c *nebulaClient) DropConstraint(ctx context.Context, label, property string, constraintType graph.ConstraintType) error {
+	return unsupported("constraints")
+}
+
+func (c *nebulaClient) CreateFullTextIndex(ctx context.Context, name string, labels, properties []string) error {
+	return unsupported("full-text index")
+}
+
+func (c *nebulaClient) DropFullTextIndex(ctx context.Context, name string) error {
+	return unsupported("full-text index")
+}
+
+func (c *nebulaClient) FullTextSearch(ctx context.Context, indexName, query string, limit int) ([]*graph.ScoredNode, error) {
+	return nil, unsupported("full-text search")
+}
+
+// ListIndexes returns the tag and edge indexes of the space, with the
+// properties they index.
+func (c *nebulaClient) ListIndexes(ctx context.Context) ([]*graph.IndexInfo, error) {
+	var indexes []*graph.IndexInfo
+	for _, list := range []struct {
+		entityType graph.EntityType
+		// by is the column of the tag or edge type indexed.
+		by string
+	}{{graph.EntityNode, "By Tag"}, {graph.EntityRelationship, "By Edge"}} {
+		schema := schemaKeyword(list.entityType)
+		res, err := c.exec(ctx, "SHOW "+schema+" INDEXES")
+		if err != nil {
+			return nil, err
+		}
+		for _, row := range res.Tables {
+			name := toString(row["Index Name"])
+			fields, err := c.describe(ctx, schema+" INDEX", name)
+			if err != nil {
+				return nil, err
+			}
+			properties := make([]string, len(fields))
+			for i, f := range fields {
+				properties[i] = f.name
+			}
+			indexes = append(indexes, &graph.IndexInfo{
+				Name:          name,
+				Type:          indexType,
+				EntityType:    list.entityType,
+				LabelsOrTypes: []string{toString(row[list.by])},
+				Properties:    properties,
+				State:         "ONLINE",
+			})
+		}
+	}
+	slices.SortFunc(indexes, func(a, b *graph.IndexInfo) int { return strings.Compare(a.Name, b.Name) })
+	return indexes, nil
+}
+
+func (c *nebulaClient) ListConstraints(ctx context.Context) ([]*graph.ConstraintInfo, error) {
+	return nil, nil
+}
+
+// ListLabels returns the tags of the space.
+func (c *nebulaClient) ListLabels(ctx context.Context) ([]string, error) {
+	return c.listNames(ctx, "SHOW TAGS")
+}
+
+// ListRelationshipTypes returns the edge types of the space.
+func (c *nebulaClient) ListRelationshipTypes(ctx context.Context) ([]string, error) {
+	return c.listNames(ctx, "SHOW EDGES")
+}
+
+func (c *nebulaClient) listNames(ctx context.Context, stmt string) ([]string, error) {
+	res, err := c.exec(ctx, stmt)
+	if err != nil {
+		return nil, err
+	}
+	names := make([]string, 0, len(res.Tables))
+	for _, row := range res.Tables {
+		if name := toString(row["Name"]); name != "" {
+			names = append(names, name)
+		}
+	}
+	slices.Sort(names)
+	return names, nil
+}
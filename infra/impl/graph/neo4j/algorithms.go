@@ -0,0 +1,284 @@
+package neo4j
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/me2seeks/forge/infra/contract/graph"
+	"github.com/me2seeks/forge/infra/impl/graph/internal"
+	"github.com/neo4j/neo4j-go-driver/v5/neo4j"
+)
+
+// ShortestPath finds the shortest paths between two nodes with Cypher's
+// shortestPath, or allShortestPaths if config["all"] is true. config may also
+// set "relationshipTypes", "direction" (OUTGOING, INCOMING or BOTH, the
+// default) and "maxDepth".
+//
+// If config sets "relationshipWeightProperty", the path of lowest total weight
+// is found with GDS Dijkstra instead, on a projection that may be narrowed by
+// "nodeLabels", "relationshipTypes" and "orientation". The edges of such a
+// path are virtual, of type PATH_<index> with their cost as property.
+func (c *neo4jClient) ShortestPath(ctx context.Context, sourceNodeID, targetNodeID string, config map[string]any) ([]*graph.Path, error) {
+	if internal.ConfigString(config, "relationshipWeightProperty") != "" {
+		return c.dijkstra(ctx, sourceNodeID, targetNodeID, config)
+	}
+
+	if err := graph.ValidateNames(internal.ConfigStrings(config, "relationshipTypes")...); err != nil {
+		return nil, err
+	}
+	cypher, params := buildShortestPathCypher(sourceNodeID, targetNodeID, config)
+	result, err := c.exec.read(ctx, func(tx runner) (any, error) {
+		return collectPaths(ctx, tx, cypher, params)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result.([]*graph.Path), nil
+}
+
+func buildShortestPathCypher(sourceNodeID, targetNodeID string, config map[string]any) (string, map[string]any) {
+	fn := "shortestPath"
+	if all, _ := config["all"].(bool); all {
+		fn = "allShortestPaths"
+	}
+
+	var rel strings.Builder
+	rel.WriteString("[")
+	for i, typ := range internal.ConfigStrings(config, "relationshipTypes") {
+		if i == 0 {
+			rel.WriteString(":")
+		} else {
+			rel.WriteString("|")
+		}
+		rel.WriteString(quoteName(typ))
+	}
+	rel.WriteString("*")
+	if maxDepth, ok := internal.ConfigInt(config, "maxDepth"); ok {
+		rel.WriteString(fmt.Sprintf("..%d", maxDepth))
+	}
+	rel.WriteString("]")
+
+	pattern := "-" + rel.String() + "-"
+	switch strings.ToUpper(internal.ConfigString(config, "direction")) {
+	case "OUTGOING":
+		pattern = "-" + rel.String() + "->"
+	case "INCOMING":
+		pattern = "<-" + rel.String() + "-"
+	}
+
+	cypher := "MATCH (s), (t) WHERE elementId(s) = $source AND elementId(t) = $target " +
+		"MATCH p = " + fn + "((s)" + pattern + "(t)) RETURN p"
+	return cypher, map[string]any{"source": sourceNodeID, "target": targetNodeID}
+}
+
+func (c *neo4jClient) dijkstra(ctx context.Context, sourceNodeID, targetNodeID string, config map[string]any) ([]*graph.Path, error) {
+	p, _ := splitGDSConfig(config, "NATURAL")
+	var paths []*graph.Path
+	err := c.withProjection(ctx, p, func(name string) error {
+		cypher := "MATCH (s), (t) WHERE elementId(s) = $source AND elementId(t) = $target " +
+			"CALL gds.shortestPath.dijkstra.stream($graph, {sourceNode: s, targetNode: t, relationshipWeightProperty: $weight}) " +
+			"YIELD path RETURN path AS p"
+		params := map[string]any{"graph": name, "source": sourceNodeID, "target": targetNodeID, "weight": p.weight}
+		result, err := c.exec.read(ctx, func(tx runner) (any, error) {
+			return collectPaths(ctx, tx, cypher, params)
+		})
+		if err != nil {
+			return err
+		}
+		paths = result.([]*graph.Path)
+		return nil
+	})
+	return paths, err
+}
+
+// PageRank runs GDS PageRank on a temporary projection of the graph and returns
+// the score of each node by ID. config is passed to gds.pageRank.stream, e.g.
+// "dampingFactor", "maxIterations" or "relationshipWeightProperty", except for
+// "nodeLabels", "relationshipTypes" and "orientation", which narrow the
+// projection. It returns ErrGDSUnavailable if GDS isn't installed.
+func (c *neo4jClient) PageRank(ctx context.Context, config map[string]any) (map[string]float64, error) {
+	p, algo := splitGDSConfig(config, "NATURAL")
+	values, err := c.streamNodeValues(ctx, p, "gds.pageRank.stream", algo, "score")
+	if err != nil {
+		return nil, err
+	}
+	return toFloats(values), nil
+}
+
+// ConnectedComponents runs GDS weakly connected components on a temporary
+// projection of the graph and returns the component ID of each node by ID.
+// config is passed to gds.wcc.stream, except for the projection keys, see
+// PageRank.
+//
+// Without GDS, graphs of up to config["fallbackMaxNodes"] nodes, 10000 by
+// default, are read with Cypher and their components computed by the client.
+// Component IDs are then the smallest node ID of each component.
+func (c *neo4jClient) ConnectedComponents(ctx context.Context, config map[string]any) (map[string]string, error) {
+	p, algo := splitGDSConfig(config, "UNDIRECTED")
+	maxNodes, ok := internal.ConfigInt(algo, "fallbackMaxNodes")
+	if !ok {
+		maxNodes = 10000
+	}
+	delete(algo, "fallbackMaxNodes")
+
+	values, err := c.streamNodeValues(ctx, p, "gds.wcc.stream", algo, "componentId")
+	if errors.Is(err, ErrGDSUnavailable) {
+		return c.connectedComponents(ctx, p, maxNodes)
+	}
+	if err != nil {
+		return nil, err
+	}
+	components := make(map[string]string, len(values))
+	for id, v := range values {
+		components[id] = fmt.Sprint(v)
+	}
+	return components, nil
+}
+
+// connectedComponents reads the nodes and relationships of p and joins them
+// into components with union-find.
+func (c *neo4jClient) connectedComponents(ctx context.Context, p *projection, maxNodes int) (map[string]string, error) {
+	labelFilter := func(alias string) string {
+		if len(p.nodeLabels) == 0 {
+			return "true"
+		}
+		return "any(l IN labels(" + alias + ") WHERE l IN $labels)"
+	}
+	relFilter := "true"
+	if len(p.relationshipTypes) > 0 {
+		relFilter = "type(r) IN $types"
+	}
+	params := map[string]any{"labels": p.nodeLabels, "types": p.relationshipTypes, "limit": maxNodes + 1}
+
+	result, err := c.exec.read(ctx, func(tx runner) (any, error) {
+		res, err := tx.Run(ctx, "MATCH (n) WHERE "+labelFilter("n")+" RETURN elementId(n) AS id LIMIT $limit", params)
+		if err != nil {
+			return nil, err
+		}
+		parent := make(map[string]string)
+		for res.Next(ctx) {
+			id, _ := res.Record().Get("id")
+			parent[id.(string)] = id.(string)
+		}
+		if err := res.Err(); err != nil {
+			return nil, err
+		}
+		if len(parent) > maxNodes {
+			return nil, fmt.Errorf("connected components: more than %d nodes to compute without GDS: %w", maxNodes, ErrGDSUnavailable)
+		}
+
+		cypher := "MATCH (a)-[r]->(b) WHERE " + relFilter + " AND " + labelFilter("a") + " AND " + labelFilter("b") +
+			" RETURN elementId(a) AS a, elementId(b) AS b"
+		res, err = tx.Run(ctx, cypher, params)
+		if err != nil {
+			return nil, err
+		}
+		for res.Next(ctx) {
+			record := res.Record()
+			a, _ := record.Get("a")
+			b, _ := record.Get("b")
+			internal.Union(parent, a.(string), b.(string), strings.Compare)
+		}
+		return parent, res.Err()
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	parent := result.(map[string]string)
+	components := make(map[string]string, len(parent))
+	for id := range parent {
+		components[id] = internal.Find(parent, id)
+	}
+	return components, nil
+}
+
+// BetweennessCentrality runs GDS betweenness centrality on a temporary
+// projection of the graph and returns the score of each node by ID. The
+// projection follows "relationshipTypes" and "orientation" (NATURAL by
+// default, REVERSE or UNDIRECTED), and "samplingSize" trades accuracy for
+// speed on large graphs by only starting from that many nodes. The rest of
+// config is passed to gds.betweenness.stream.
+func (c *neo4jClient) BetweennessCentrality(ctx context.Context, config map[string]any) (map[string]float64, error) {
+	p, algo := splitGDSConfig(config, "NATURAL")
+	values, err := c.streamNodeValues(ctx, p, "gds.betweenness.stream", algo, "score")
+	if err != nil {
+		return nil, err
+	}
+	return toFloats(values), nil
+}
+
+// streamNodeValues runs the stream mode of a GDS procedure on a projection and
+// returns the value it yields as field for each node, by node ID.
+func (c *neo4jClient) streamNodeValues(ctx context.Context, p *projection, procedure string, config map[string]any, field string) (map[string]any, error) {
+	var values map[string]any
+	err := c.withProjection(ctx, p, func(name string) error {
+		cypher := "CALL " + procedure + "($graph, $config) YIELD nodeId, " + field +
+			" RETURN elementId(gds.util.asNode(nodeId)) AS id, " + field + " AS value"
+		result, err := c.exec.read(ctx, func(tx runner) (any, error) {
+			res, err := tx.Run(ctx, cypher, map[string]any{"graph": name, "config": config})
+			if err != nil {
+				return nil, err
+			}
+			values := make(map[string]any)
+			for res.Next(ctx) {
+				record := res.Record()
+				id, _ := record.Get("id")
+				value, _ := record.Get("value")
+				values[id.(string)] = value
+			}
+			return values, res.Err()
+		})
+		if err != nil {
+			return err
+		}
+		values = result.(map[string]any)
+		return nil
+	})
+	return values, err
+}
+
+func toFloats(values map[string]any) map[string]float64 {
+	out := make(map[string]float64, len(values))
+	for id, v := range values {
+		switch f := v.(type) {
+		case float64:
+			out[id] = f
+		case int64:
+			out[id] = float64(f)
+		}
+	}
+	return out
+}
+
+// collectPaths runs cypher and converts the paths it returns as p.
+func collectPaths(ctx context.Context, tx runner, cypher string, params map[string]any) ([]*graph.Path, error) {
+	res, err := tx.Run(ctx, cypher, params)
+	if err != nil {
+		return nil, err
+	}
+	var paths []*graph.Path
+	for res.Next(ctx) {
+		p, _ := res.Record().Get("p")
+		if path, ok := p.(neo4j.Path); ok {
+			paths = append(paths, toGraphPath(path))
+		}
+	}
+	return paths, res.Err()
+}
+
+func toGraphPath(p neo4j.Path) *graph.Path {
+	path := &graph.Path{
+		Nodes: make([]*graph.Node, len(p.Nodes)),
+		Edges: make([]*graph.Edge, len(p.Relationships)),
+	}
+	for i, n := range p.Nodes {
+		path.Nodes[i] = toGraphNode(n)
+	}
+	for i, r := range p.Relationships {
+		path.Edges[i] = toGraphEdge(r)
+	}
+	return path
+}
@@ -0,0 +1,192 @@
+package neo4j
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/me2seeks/forge/infra/contract/graph"
+	"github.com/neo4j/neo4j-go-driver/v5/neo4j"
+)
+
+func (c *neo4jClient) CreateNodes(ctx context.Context, nodes []*graph.Node) ([]*graph.Node, error) {
+	if len(nodes) == 0 {
+		return nil, nil
+	}
+	result, err := c.exec.write(ctx, func(tx runner) (any, error) {
+		return createNodes(ctx, tx, nodes)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result.([]*graph.Node), nil
+}
+
+func (c *neo4jClient) CreateEdges(ctx context.Context, edges []*graph.Edge) ([]*graph.Edge, error) {
+	if len(edges) == 0 {
+		return nil, nil
+	}
+	result, err := c.exec.write(ctx, func(tx runner) (any, error) {
+		return createEdges(ctx, tx, edges)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result.([]*graph.Edge), nil
+}
+
+// batchStatement is an UNWIND over the rows of the entities sharing the labels
+// or type of its Cypher, each row carrying the index of its entity.
+type batchStatement struct {
+	cypher string
+	rows   []map[string]any
+}
+
+// buildCreateNodesCypher groups nodes by label set, as labels can't be
+// parameters, into one statement per group.
+func buildCreateNodesCypher(nodes []*graph.Node) []*batchStatement {
+	var stmts []*batchStatement
+	byLabels := make(map[string]*batchStatement)
+	for i, node := range nodes {
+		key := strings.Join(node.Labels, "\x00")
+		stmt, ok := byLabels[key]
+		if !ok {
+			var labels strings.Builder
+			for _, label := range node.Labels {
+				labels.WriteString(":" + quoteName(label))
+			}
+			stmt = &batchStatement{cypher: "UNWIND $rows AS row CREATE (n" + labels.String() + ") SET n = row.props RETURN row.i AS i, n"}
+			byLabels[key] = stmt
+			stmts = append(stmts, stmt)
+		}
+		stmt.rows = append(stmt.rows, map[string]any{"i": i, "props": propsOrEmpty(node.Properties)})
+	}
+	return stmts
+}
+
+// buildCreateEdgesCypher groups edges by type into one statement per group.
+// Endpoints are matched by element ID.
+func buildCreateEdgesCypher(edges []*graph.Edge) ([]*batchStatement, error) {
+	var stmts []*batchStatement
+	byLabel := make(map[string]*batchStatement)
+	for i, edge := range edges {
+		if edge.SourceNodeSelector != nil || edge.TargetNodeSelector != nil {
+			return nil, fmt.Errorf("create edges: edge %d uses node selectors, batches only support node IDs", i)
+		}
+		if err := graph.ValidateName(edge.Label); err != nil {
+			return nil, fmt.Errorf("create edges: edge %d: %w", i, err)
+		}
+		stmt, ok := byLabel[edge.Label]
+		if !ok {
+			stmt = &batchStatement{cypher: "UNWIND $rows AS row MATCH (a) WHERE elementId(a) = row.source MATCH (b) WHERE elementId(b) = row.target " +
+				"CREATE (a)-[r:" + quoteName(edge.Label) + "]->(b) SET r = row.props RETURN row.i AS i, r"}
+			byLabel[edge.Label] = stmt
+			stmts = append(stmts, stmt)
+		}
+		stmt.rows = append(stmt.rows, map[string]any{
+			"i":      i,
+			"source": edge.SourceNodeID,
+			"target": edge.TargetNodeID,
+			"props":  propsOrEmpty(edge.Properties),
+		})
+	}
+	return stmts, nil
+}
+
+// propsOrEmpty avoids setting an entity to null, which Cypher rejects.
+func propsOrEmpty(props graph.Properties) graph.Properties {
+	if props == nil {
+		return graph.Properties{}
+	}
+	return props
+}
+
+func createNodes(ctx context.Context, tx runner, nodes []*graph.Node) ([]*graph.Node, error) {
+	for i, node := range nodes {
+		if err := graph.ValidateNames(node.Labels...); err != nil {
+			return nil, fmt.Errorf("create nodes: node %d: %w", i, err)
+		}
+	}
+	created := make([]*graph.Node, len(nodes))
+	for _, stmt := range buildCreateNodesCypher(nodes) {
+		err := runBatch(ctx, tx, stmt, "n", func(i int, entity any) {
+			created[i] = toGraphNode(entity.(neo4j.Node))
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	return created, nil
+}
+
+func createEdges(ctx context.Context, tx runner, edges []*graph.Edge) ([]*graph.Edge, error) {
+	stmts, err := buildCreateEdgesCypher(edges)
+	if err != nil {
+		return nil, err
+	}
+	created := make([]*graph.Edge, len(edges))
+	for _, stmt := range stmts {
+		err := runBatch(ctx, tx, stmt, "r", func(i int, entity any) {
+			created[i] = toGraphEdge(entity.(neo4j.Relationship))
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	// An edge with a missing endpoint matches no row, failing the transaction
+	// rather than returning a partial batch.
+	if i := slices.Index(created, nil); i >= 0 {
+		return nil, fmt.Errorf("create edges: endpoint of edge %d not found", i)
+	}
+	return created, nil
+}
+
+// runBatch runs stmt and calls collect with the index and the created entity of each row.
+func runBatch(ctx context.Context, tx runner, stmt *batchStatement, key string, collect func(i int, entity any)) error {
+	res, err := tx.Run(ctx, stmt.cypher, map[string]any{"rows": stmt.rows})
+	if err != nil {
+		return err
+	}
+	for res.Next(ctx) {
+		record := res.Record()
+		i, _ := record.Get("i")
+		entity, _ := record.Get(key)
+		collect(int(i.(int64)), entity)
+	}
+	return res.Err()
+}
+
+func (c *neo4jClient) BatchQuery(ctx context.Context, statement string, rows []map[string]any, params map[string]any) (*graph.QueryResult, error) {
+	cypher, params, err := buildBatchQueryCypher(statement, rows, params)
+	if err != nil {
+		return nil, err
+	}
+	result, err := c.exec.write(ctx, func(tx runner) (any, error) {
+		res, err := tx.Run(ctx, cypher, params)
+		if err != nil {
+			return nil, err
+		}
+		var records []graph.Record
+		for res.Next(ctx) {
+			records = append(records, toGraphRecord(res.Record()))
+		}
+		return &graph.QueryResult{Records: records}, res.Err()
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result.(*graph.QueryResult), nil
+}
+
+// buildBatchQueryCypher runs statement on each row of $rows.
+func buildBatchQueryCypher(statement string, rows []map[string]any, params map[string]any) (string, map[string]any, error) {
+	if _, ok := params["rows"]; ok {
+		return "", nil, fmt.Errorf("batch query: parameter rows is reserved")
+	}
+	merged := make(map[string]any, len(params)+1)
+	maps.Copy(merged, params)
+	merged["rows"] = rows
+	return "UNWIND $rows AS row " + statement, merged, nil
+}
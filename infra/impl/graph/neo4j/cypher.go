@@ -0,0 +1,108 @@
+package neo4j
+
+import "github.com/me2seeks/forge/infra/contract/graph"
+
+// The builders below return the Cypher run by the client, for the backends
+// running it on other openCypher servers, e.g. over HTTP. Entities are
+// matched by elementId.
+
+// QuoteName returns a label, relationship type or property key quoted for
+// Cypher, the names rejected by graph.ValidateName being left to the caller.
+func QuoteName(name string) string {
+	return quoteName(name)
+}
+
+// BuildQueryCypher returns the Cypher of Query and QueryStream.
+func BuildQueryCypher(query *graph.Query) (string, map[string]any) {
+	return buildCypherQuery(query)
+}
+
+// BuildCountCypher returns the Cypher of Count, whose single value is the count.
+func BuildCountCypher(query *graph.Query) (string, map[string]any) {
+	return buildCountCypher(query)
+}
+
+// BuildUpdateByQueryCypher returns the Cypher of UpdateNodesByQuery, or
+// UpdateEdgesByQuery if edges is true, whose single value is the count of the
+// entities updated.
+func BuildUpdateByQueryCypher(query *graph.Query, edges bool, properties graph.Properties) (string, map[string]any) {
+	return buildUpdateByQueryCypher(query, edges, properties)
+}
+
+// BuildDeleteByQueryCypher returns the Cypher of DeleteNodesByQuery, or
+// DeleteEdgesByQuery if edges is true, whose single value is the count of the
+// entities deleted.
+func BuildDeleteByQueryCypher(query *graph.Query, edges bool) (string, map[string]any) {
+	return buildDeleteByQueryCypher(query, edges)
+}
+
+// BuildMergeNodeCypher returns the Cypher of MergeNode, returning the node n.
+func BuildMergeNodeCypher(node *graph.Node, matchKeys []string) (string, map[string]any, error) {
+	return buildMergeNodeCypher(node, matchKeys)
+}
+
+// BuildCreateEdgeCypher returns the Cypher of CreateEdge, returning the edge r.
+func BuildCreateEdgeCypher(edge *graph.Edge) (string, map[string]any) {
+	return buildCreateEdgeCypher(edge)
+}
+
+// BuildMergeEdgeCypher returns the Cypher of MergeEdge, returning the edge r
+// for each pair of endpoints matched.
+func BuildMergeEdgeCypher(edge *graph.Edge, onCreate, onMatch graph.Properties) (string, map[string]any, error) {
+	return buildMergeEdgeCypher(edge, onCreate, onMatch)
+}
+
+// BuildBatchQueryCypher returns the Cypher of BatchQuery.
+func BuildBatchQueryCypher(statement string, rows []map[string]any, params map[string]any) (string, map[string]any, error) {
+	return buildBatchQueryCypher(statement, rows, params)
+}
+
+// BuildShortestPathCypher returns the Cypher of an unweighted ShortestPath,
+// returning the paths p.
+func BuildShortestPathCypher(sourceNodeID, targetNodeID string, config map[string]any) (string, map[string]any) {
+	return buildShortestPathCypher(sourceNodeID, targetNodeID, config)
+}
+
+// BuildNeighborsCypher returns the Cypher of GetNeighbors, returning a single
+// row with the lists nodes and edges.
+func BuildNeighborsCypher(nodeID string, opts *graph.NeighborOptions) (string, map[string]any) {
+	return buildNeighborsCypher(nodeID, opts)
+}
+
+// BuildSubgraphCypher returns the Cypher loading the subgraph of Traverse,
+// returning a single row with the lists nodes, the start node first, and
+// edges.
+func BuildSubgraphCypher(nodeID string, opts *graph.NeighborOptions) (string, map[string]any) {
+	return buildSubgraphCypher(nodeID, opts)
+}
+
+// BatchStatement is an UNWIND over Rows, passed as the $rows parameter. Each
+// row has the index i of its entity in the batch.
+type BatchStatement struct {
+	Cypher string
+	Rows   []map[string]any
+}
+
+// BuildCreateNodesCypher returns the statements of CreateNodes, one per label
+// set, returning i and the node n.
+func BuildCreateNodesCypher(nodes []*graph.Node) []*BatchStatement {
+	return exportBatch(buildCreateNodesCypher(nodes))
+}
+
+// BuildCreateEdgesCypher returns the statements of CreateEdges, one per type,
+// returning i and the edge r.
+func BuildCreateEdgesCypher(edges []*graph.Edge) ([]*BatchStatement, error) {
+	stmts, err := buildCreateEdgesCypher(edges)
+	if err != nil {
+		return nil, err
+	}
+	return exportBatch(stmts), nil
+}
+
+func exportBatch(stmts []*batchStatement) []*BatchStatement {
+	out := make([]*BatchStatement, len(stmts))
+	for i, stmt := range stmts {
+		out[i] = &BatchStatement{Cypher: stmt.cypher, Rows: stmt.rows}
+	}
+	return out
+}
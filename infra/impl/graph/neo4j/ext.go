@@ -0,0 +1,279 @@
+package neo4j
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/me2seeks/forge/infra/contract/graph"
+	"github.com/me2seeks/forge/infra/impl/graph/internal"
+)
+
+var _ graph.Neo4jExtensions = (*neo4jClient)(nil)
+
+// gdsAlgorithm is the stream procedure of a GDS algorithm, the field it yields
+// per node and the default orientation of its projection.
+type gdsAlgorithm struct {
+	procedure   string
+	field       string
+	orientation string
+}
+
+var communityAlgorithms = map[string]gdsAlgorithm{
+	"louvain":          {"gds.louvain.stream", "communityId", "UNDIRECTED"},
+	"leiden":           {"gds.leiden.stream", "communityId", "UNDIRECTED"},
+	"labelPropagation": {"gds.labelPropagation.stream", "communityId", "UNDIRECTED"},
+	"wcc":              {"gds.wcc.stream", "componentId", "UNDIRECTED"},
+}
+
+var centralityAlgorithms = map[string]gdsAlgorithm{
+	"degree":      {"gds.degree.stream", "score", "NATURAL"},
+	"closeness":   {"gds.closeness.stream", "score", "NATURAL"},
+	"harmonic":    {"gds.closeness.harmonic.stream", "score", "NATURAL"},
+	"betweenness": {"gds.betweenness.stream", "score", "NATURAL"},
+	"eigenvector": {"gds.eigenvector.stream", "score", "NATURAL"},
+	"pageRank":    {"gds.pageRank.stream", "score", "NATURAL"},
+	"articleRank": {"gds.articleRank.stream", "score", "NATURAL"},
+}
+
+var embeddingAlgorithms = map[string]gdsAlgorithm{
+	"fastRP":    {"gds.fastRP.stream", "embedding", "UNDIRECTED"},
+	"node2vec":  {"gds.node2vec.stream", "embedding", "NATURAL"},
+	"hashgnn":   {"gds.hashgnn.stream", "embedding", "UNDIRECTED"},
+	"graphSage": {"gds.beta.graphSage.stream", "embedding", "UNDIRECTED"},
+}
+
+func lookupAlgorithm(kind string, algorithms map[string]gdsAlgorithm, name string) (gdsAlgorithm, error) {
+	algo, ok := algorithms[name]
+	if !ok {
+		return gdsAlgorithm{}, fmt.Errorf("unsupported %s algorithm %q", kind, name)
+	}
+	return algo, nil
+}
+
+// CommunityDetection returns the community ID of each node by ID. algorithm is
+// "louvain", "leiden", "labelPropagation" or "wcc", and config is passed to
+// its GDS procedure except for the projection keys, see PageRank.
+func (c *neo4jClient) CommunityDetection(ctx context.Context, algorithm string, config map[string]any) (map[string]string, error) {
+	algo, err := lookupAlgorithm("community detection", communityAlgorithms, algorithm)
+	if err != nil {
+		return nil, err
+	}
+	p, rest := splitGDSConfig(config, algo.orientation)
+	values, err := c.streamNodeValues(ctx, p, algo.procedure, rest, algo.field)
+	if err != nil {
+		return nil, err
+	}
+	communities := make(map[string]string, len(values))
+	for id, v := range values {
+		communities[id] = fmt.Sprint(v)
+	}
+	return communities, nil
+}
+
+// Centrality returns the centrality score of each node by ID. algorithm is
+// "degree", "closeness", "harmonic", "betweenness", "eigenvector", "pageRank"
+// or "articleRank", and config is passed to its GDS procedure except for the
+// projection keys, see PageRank.
+func (c *neo4jClient) Centrality(ctx context.Context, algorithm string, config map[string]any) (map[string]float64, error) {
+	algo, err := lookupAlgorithm("centrality", centralityAlgorithms, algorithm)
+	if err != nil {
+		return nil, err
+	}
+	p, rest := splitGDSConfig(config, algo.orientation)
+	values, err := c.streamNodeValues(ctx, p, algo.procedure, rest, algo.field)
+	if err != nil {
+		return nil, err
+	}
+	return toFloats(values), nil
+}
+
+// NodeEmbedding returns the embedding of each node by ID. algorithm is
+// "fastRP", "node2vec", "hashgnn" or "graphSage", and config is passed to its
+// GDS procedure, e.g. "embeddingDimension", or "modelName" for the trained
+// model of graphSage. Node properties read by the algorithm are projected from
+// config["nodeProperties"].
+func (c *neo4jClient) NodeEmbedding(ctx context.Context, algorithm string, config map[string]any) (map[string][]float64, error) {
+	algo, err := lookupAlgorithm("node embedding", embeddingAlgorithms, algorithm)
+	if err != nil {
+		return nil, err
+	}
+	p, rest := splitGDSConfig(config, algo.orientation)
+	if algorithm != "hashgnn" && algorithm != "graphSage" {
+		// Only these two take the node properties as a config key.
+		delete(rest, "nodeProperties")
+	}
+	values, err := c.streamNodeValues(ctx, p, algo.procedure, rest, algo.field)
+	if err != nil {
+		return nil, err
+	}
+	embeddings := make(map[string][]float64, len(values))
+	for id, v := range values {
+		items, _ := v.([]any)
+		embedding := make([]float64, 0, len(items))
+		for _, item := range items {
+			switch f := item.(type) {
+			case float64:
+				embedding = append(embedding, f)
+			case int64:
+				embedding = append(embedding, float64(f))
+			}
+		}
+		embeddings[id] = embedding
+	}
+	return embeddings, nil
+}
+
+// Similarity returns the similarity of pairs of nodes, keyed by
+// graph.SimilarityKey. algorithm is "jaccard", "overlap" or "cosine", compared
+// on the neighbors of nodes with gds.nodeSimilarity, or, if config sets
+// "nodeProperties", "cosine", "pearson", "euclidean" or "jaccard", compared on
+// those properties with gds.knn. The rest of config is passed to the procedure,
+// e.g. "topK" or "similarityCutoff".
+func (c *neo4jClient) Similarity(ctx context.Context, algorithm string, config map[string]any) (map[string]float64, error) {
+	p, rest := splitGDSConfig(config, "NATURAL")
+	metric := strings.ToUpper(algorithm)
+	procedure := "gds.nodeSimilarity.stream"
+	if len(p.nodeProperties) > 0 {
+		switch metric {
+		case "COSINE", "PEARSON", "EUCLIDEAN", "JACCARD":
+		default:
+			return nil, fmt.Errorf("unsupported property similarity algorithm %q", algorithm)
+		}
+		procedure = "gds.knn.stream"
+		props := make(map[string]any, len(p.nodeProperties))
+		for _, prop := range p.nodeProperties {
+			props[prop] = metric
+		}
+		rest["nodeProperties"] = props
+	} else {
+		switch metric {
+		case "JACCARD", "OVERLAP", "COSINE":
+		default:
+			return nil, fmt.Errorf("unsupported neighborhood similarity algorithm %q", algorithm)
+		}
+		rest["similarityMetric"] = metric
+	}
+
+	var similarities map[string]float64
+	err := c.withProjection(ctx, p, func(name string) error {
+		cypher := "CALL " + procedure + "($graph, $config) YIELD node1, node2, similarity " +
+			"RETURN elementId(gds.util.asNode(node1)) AS a, elementId(gds.util.asNode(node2)) AS b, similarity"
+		result, err := c.exec.read(ctx, func(tx runner) (any, error) {
+			res, err := tx.Run(ctx, cypher, map[string]any{"graph": name, "config": rest})
+			if err != nil {
+				return nil, err
+			}
+			similarities := make(map[string]float64)
+			for res.Next(ctx) {
+				record := res.Record()
+				a, _ := record.Get("a")
+				b, _ := record.Get("b")
+				similarity, _ := record.Get("similarity")
+				similarities[graph.SimilarityKey(a.(string), b.(string))] = similarity.(float64)
+			}
+			return similarities, res.Err()
+		})
+		if err != nil {
+			return err
+		}
+		similarities = result.(map[string]float64)
+		return nil
+	})
+	return similarities, err
+}
+
+// linkPredictionScores are the Cypher expressions of the link prediction
+// scores of s and t, given their common neighbors and the neighbor counts of
+// each node as degree(x).
+var linkPredictionScores = map[string]string{
+	"commonNeighbors":        "toFloat(size(common))",
+	"adamicAdar":             "reduce(acc = 0.0, m IN common | acc + 1.0 / log(degree(m)))",
+	"resourceAllocation":     "reduce(acc = 0.0, m IN common | acc + 1.0 / degree(m))",
+	"preferentialAttachment": "toFloat(degree(s) * degree(t))",
+	"totalNeighbors":         "toFloat(degree(s) + degree(t) - size(common))",
+}
+
+// LinkPrediction scores the likelihood of edges between the nodes of
+// config["sourceNodeIds"] and either the nodes of config["targetNodeIds"], or
+// by default the nodes two hops away they aren't linked to. algorithm is
+// "commonNeighbors", "adamicAdar", "resourceAllocation",
+// "preferentialAttachment" or "totalNeighbors". Neighbors are counted along
+// config["relationshipTypes"], all by default, and the config["topK"] best
+// pairs are returned, 10 by default.
+//
+// The scores are computed with Cypher, as GDS only predicts links with
+// trained pipelines.
+func (c *neo4jClient) LinkPrediction(ctx context.Context, algorithm string, config map[string]any) ([]*graph.LinkPredictionResult, error) {
+	cypher, params, err := buildLinkPredictionCypher(algorithm, config)
+	if err != nil {
+		return nil, err
+	}
+	result, err := c.exec.read(ctx, func(tx runner) (any, error) {
+		res, err := tx.Run(ctx, cypher, params)
+		if err != nil {
+			return nil, err
+		}
+		var links []*graph.LinkPredictionResult
+		for res.Next(ctx) {
+			record := res.Record()
+			source, _ := record.Get("source")
+			target, _ := record.Get("target")
+			score, _ := record.Get("score")
+			links = append(links, &graph.LinkPredictionResult{
+				SourceNodeID: source.(string),
+				TargetNodeID: target.(string),
+				Score:        score.(float64),
+			})
+		}
+		return links, res.Err()
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result.([]*graph.LinkPredictionResult), nil
+}
+
+func buildLinkPredictionCypher(algorithm string, config map[string]any) (string, map[string]any, error) {
+	score, ok := linkPredictionScores[algorithm]
+	if !ok {
+		return "", nil, fmt.Errorf("unsupported link prediction algorithm %q", algorithm)
+	}
+	sources := internal.ConfigStrings(config, "sourceNodeIds")
+	if len(sources) == 0 {
+		return "", nil, fmt.Errorf("link prediction: no sourceNodeIds")
+	}
+	topK, ok := internal.ConfigInt(config, "topK")
+	if !ok {
+		topK = 10
+	}
+
+	rel := "-[]-"
+	if types := internal.ConfigStrings(config, "relationshipTypes"); len(types) > 0 {
+		if err := graph.ValidateNames(types...); err != nil {
+			return "", nil, fmt.Errorf("link prediction: %w", err)
+		}
+		rel = "-[:" + quoteNames(types, "|") + "]-"
+	}
+	degree := func(alias string) string {
+		return "COUNT { MATCH (" + alias + ")" + rel + "(x) RETURN DISTINCT x }"
+	}
+	for _, alias := range []string{"m", "s", "t"} {
+		score = strings.ReplaceAll(score, "degree("+alias+")", degree(alias))
+	}
+
+	params := map[string]any{"sources": sources, "topK": topK}
+	var sb strings.Builder
+	sb.WriteString("MATCH (s) WHERE elementId(s) IN $sources ")
+	if targets := internal.ConfigStrings(config, "targetNodeIds"); len(targets) > 0 {
+		params["targets"] = targets
+		sb.WriteString("MATCH (t) WHERE elementId(t) IN $targets AND t <> s ")
+		sb.WriteString("OPTIONAL MATCH (s)" + rel + "(m)" + rel + "(t) ")
+	} else {
+		sb.WriteString("MATCH (s)" + rel + "(m)" + rel + "(t) WHERE t <> s AND NOT (s)" + rel + "(t) ")
+	}
+	sb.WriteString("WITH s, t, collect(DISTINCT m) AS common ")
+	sb.WriteString("RETURN elementId(s) AS source, elementId(t) AS target, " + score + " AS score ")
+	sb.WriteString("ORDER BY score DESC LIMIT $topK")
+	return sb.String(), params, nil
+}
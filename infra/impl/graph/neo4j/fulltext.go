@@ -0,0 +1,71 @@
+package neo4j
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/me2seeks/forge/infra/contract/graph"
+	"github.com/neo4j/neo4j-go-driver/v5/neo4j"
+)
+
+func (c *neo4jClient) CreateFullTextIndex(ctx context.Context, name string, labels, properties []string) error {
+	cypher, err := buildCreateFullTextIndexCypher(name, labels, properties)
+	if err != nil {
+		return err
+	}
+	return c.runCypher(ctx, cypher, nil)
+}
+
+func buildCreateFullTextIndexCypher(name string, labels, properties []string) (string, error) {
+	if len(labels) == 0 || len(properties) == 0 {
+		return "", fmt.Errorf("full-text index %s needs labels and properties", name)
+	}
+	if err := graph.ValidateNames(slices.Concat([]string{name}, labels, properties)...); err != nil {
+		return "", fmt.Errorf("full-text index: %w", err)
+	}
+	props := make([]string, len(properties))
+	for i, property := range properties {
+		props[i] = "n." + quoteName(property)
+	}
+	return "CREATE FULLTEXT INDEX " + quoteName(name) + " IF NOT EXISTS FOR (n:" + quoteNames(labels, "|") + ") " +
+		"ON EACH [" + strings.Join(props, ", ") + "]", nil
+}
+
+func (c *neo4jClient) DropFullTextIndex(ctx context.Context, name string) error {
+	if err := graph.ValidateName(name); err != nil {
+		return err
+	}
+	return c.runCypher(ctx, "DROP INDEX "+quoteName(name)+" IF EXISTS", nil)
+}
+
+// FullTextSearch queries the index with db.index.fulltext.queryNodes. query
+// uses the Lucene syntax, e.g. `title:graph~ AND body:"neo4j"`.
+func (c *neo4jClient) FullTextSearch(ctx context.Context, indexName, query string, limit int) ([]*graph.ScoredNode, error) {
+	cypher := "CALL db.index.fulltext.queryNodes($index, $query) YIELD node, score RETURN node, score"
+	params := map[string]any{"index": indexName, "query": query}
+	if limit > 0 {
+		cypher += " LIMIT $limit"
+		params["limit"] = limit
+	}
+
+	result, err := c.exec.read(ctx, func(tx runner) (any, error) {
+		res, err := tx.Run(ctx, cypher, params)
+		if err != nil {
+			return nil, err
+		}
+		var nodes []*graph.ScoredNode
+		for res.Next(ctx) {
+			record := res.Record()
+			node, _ := record.Get("node")
+			score, _ := record.Get("score")
+			nodes = append(nodes, &graph.ScoredNode{Node: toGraphNode(node.(neo4j.Node)), Score: score.(float64)})
+		}
+		return nodes, res.Err()
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result.([]*graph.ScoredNode), nil
+}
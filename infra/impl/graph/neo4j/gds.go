@@ -0,0 +1,123 @@
+package neo4j
+
+import (
+	"context"
+	"errors"
+	"maps"
+
+	"github.com/me2seeks/forge/idgen"
+	"github.com/me2seeks/forge/infra/impl/graph/internal"
+	"github.com/neo4j/neo4j-go-driver/v5/neo4j"
+)
+
+// ErrGDSUnavailable is returned by the algorithms needing the Graph Data
+// Science library when it isn't installed on the server.
+var ErrGDSUnavailable = errors.New("neo4j: graph data science library is not installed")
+
+// projectionKeys are the config keys selecting the projected graph, not
+// passed to the algorithms.
+var projectionKeys = []string{"nodeLabels", "relationshipTypes", "orientation"}
+
+// projection is the in-memory graph GDS algorithms run on.
+type projection struct {
+	nodeLabels        []string
+	relationshipTypes []string
+	// orientation is NATURAL, REVERSE or UNDIRECTED.
+	orientation string
+	// weight is the relationship property projected for weighted algorithms.
+	weight string
+	// nodeProperties are the node properties projected for the algorithms
+	// reading them.
+	nodeProperties []string
+}
+
+// splitGDSConfig splits an algorithm config into the projection it runs on,
+// defaulting to all nodes and relationships with the given orientation, and
+// the config passed to the algorithm.
+func splitGDSConfig(config map[string]any, orientation string) (*projection, map[string]any) {
+	p := &projection{
+		nodeLabels:        internal.ConfigStrings(config, "nodeLabels"),
+		relationshipTypes: internal.ConfigStrings(config, "relationshipTypes"),
+		orientation:       orientation,
+		weight:            internal.ConfigString(config, "relationshipWeightProperty"),
+		nodeProperties:    internal.ConfigStrings(config, "nodeProperties"),
+	}
+	if o := internal.ConfigString(config, "orientation"); o != "" {
+		p.orientation = o
+	}
+	algo := maps.Clone(config)
+	if algo == nil {
+		algo = make(map[string]any)
+	}
+	for _, key := range projectionKeys {
+		delete(algo, key)
+	}
+	return p, algo
+}
+
+// params returns the node and relationship projections of gds.graph.project.
+func (p *projection) params() (any, any) {
+	var nodes any = "*"
+	if len(p.nodeLabels) > 0 {
+		nodes = p.nodeLabels
+	}
+	if len(p.nodeProperties) > 0 {
+		labels := p.nodeLabels
+		if len(labels) == 0 {
+			labels = []string{"*"}
+		}
+		projected := make(map[string]any, len(labels))
+		for _, label := range labels {
+			name := label
+			if label == "*" {
+				name = "__ALL__"
+			}
+			projected[name] = map[string]any{"label": label, "properties": p.nodeProperties}
+		}
+		nodes = projected
+	}
+
+	types := p.relationshipTypes
+	if len(types) == 0 {
+		types = []string{"*"}
+	}
+	rels := make(map[string]any, len(types))
+	for _, typ := range types {
+		rel := map[string]any{"type": typ, "orientation": p.orientation}
+		if p.weight != "" {
+			rel["properties"] = map[string]any{
+				p.weight: map[string]any{"property": p.weight, "defaultValue": 1.0},
+			}
+		}
+		name := typ
+		if typ == "*" {
+			name = "__ALL__"
+		}
+		rels[name] = rel
+	}
+	return nodes, rels
+}
+
+// withProjection projects p under a unique name, runs fn on it and drops it.
+func (c *neo4jClient) withProjection(ctx context.Context, p *projection, fn func(name string) error) error {
+	name := "forge_" + idgen.NewULID()
+	nodes, rels := p.params()
+	err := c.runCypher(ctx, "CALL gds.graph.project($name, $nodes, $rels)", map[string]any{"name": name, "nodes": nodes, "rels": rels})
+	if err != nil {
+		return gdsError(err)
+	}
+	defer func() {
+		// The projection only lives in memory, drop it even if ctx is done.
+		_ = c.runCypher(context.WithoutCancel(ctx), "CALL gds.graph.drop($name, false) YIELD graphName RETURN graphName", map[string]any{"name": name})
+	}()
+	return gdsError(fn(name))
+}
+
+// gdsError maps the error of calling a missing GDS procedure to ErrGDSUnavailable.
+func gdsError(err error) error {
+	var neoErr *neo4j.Neo4jError
+	if errors.As(err, &neoErr) && neoErr.Code == "Neo.ClientError.Procedure.ProcedureNotFound" {
+		return errors.Join(ErrGDSUnavailable, err)
+	}
+	return err
+}
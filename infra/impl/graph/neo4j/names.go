@@ -0,0 +1,57 @@
+package neo4j
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Labels, relationship types and property keys are written in the Cypher
+// quoted, and their parameters renamed, so that no name can change the query.
+// The clients reject the names graph.ValidateName rejects before building it.
+
+var (
+	// plainName matches the names Cypher reads unquoted.
+	plainName = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+	// paramUnsafe matches the characters a parameter name can't hold.
+	paramUnsafe = regexp.MustCompile(`[^A-Za-z0-9_]`)
+)
+
+// quoteName returns name between backticks, the backticks it holds doubled,
+// e.g. `a“b` for a`b.
+func quoteName(name string) string {
+	return "`" + strings.ReplaceAll(name, "`", "``") + "`"
+}
+
+// safeName returns name as is if Cypher reads it unquoted, or quoted
+// otherwise, e.g. for property keys after an alias.
+func safeName(name string) string {
+	if plainName.MatchString(name) {
+		return name
+	}
+	return quoteName(name)
+}
+
+// quoteNames returns the names quoted and joined by sep, e.g. "|" for
+// alternative relationship types.
+func quoteNames(names []string, sep string) string {
+	quoted := make([]string, len(names))
+	for i, name := range names {
+		quoted[i] = quoteName(name)
+	}
+	return strings.Join(quoted, sep)
+}
+
+// uniqueParam returns a parameter name for base not yet in params, base having
+// the characters a parameter name can't hold replaced by underscores and a
+// counter appended if needed.
+func uniqueParam(params map[string]any, base string) string {
+	base = paramUnsafe.ReplaceAllString(base, "_")
+	name := base
+	for i := 1; ; i++ {
+		if _, exists := params[name]; !exists {
+			return name
+		}
+		name = fmt.Sprintf("%s_%d", base, i)
+	}
+}
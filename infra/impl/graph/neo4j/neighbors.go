@@ -0,0 +1,101 @@
+package neo4j
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/me2seeks/forge/infra/contract/graph"
+)
+
+// GetNeighbors matches the walks from the node with a variable-length pattern
+// and collects their distinct end nodes and edges.
+func (c *neo4jClient) GetNeighbors(ctx context.Context, nodeID string, opts ...graph.NeighborOpt) (*graph.Neighborhood, error) {
+	o := graph.NewNeighborOptions(opts...)
+	if err := graph.ValidateNames(o.RelationshipTypes...); err != nil {
+		return nil, err
+	}
+	cypher, params := buildNeighborsCypher(nodeID, o)
+	res, err := c.RawQuery(ctx, cypher, params)
+	if err != nil {
+		return nil, err
+	}
+	return toNeighborhood(res), nil
+}
+
+// Traverse loads the subgraph within the depth of spec from the node in a
+// single query, and runs the traversal in memory over it.
+func (c *neo4jClient) Traverse(ctx context.Context, startNodeID string, spec graph.TraversalSpec) ([]*graph.Path, error) {
+	if err := graph.ValidateNames(spec.RelationshipTypes...); err != nil {
+		return nil, err
+	}
+	cypher, params := buildSubgraphCypher(startNodeID, spec.NeighborOptions())
+	res, err := c.RawQuery(ctx, cypher, params)
+	if err != nil {
+		return nil, err
+	}
+	n := toNeighborhood(res)
+	return graph.TraverseSubgraph(startNodeID, n.Nodes, n.Edges, spec)
+}
+
+func buildNeighborsCypher(nodeID string, o *graph.NeighborOptions) (string, map[string]any) {
+	cypher := "MATCH (n) WHERE elementId(n) = $id " +
+		"MATCH p = (n)" + relPattern("", o.RelationshipTypes, o.Depth, o.Direction) + "(m) WHERE elementId(m) <> elementId(n) " +
+		"UNWIND relationships(p) AS r RETURN collect(DISTINCT m) AS nodes, collect(DISTINCT r) AS edges"
+	return cypher, map[string]any{"id": nodeID}
+}
+
+// buildSubgraphCypher returns the Cypher of Traverse, loading the nodes
+// within the depth of o from the node, itself first, and the edges of the
+// types of o between them, in a single row with the lists nodes and edges.
+// DISTINCT lets the planner prune the walks reaching the same node.
+func buildSubgraphCypher(nodeID string, o *graph.NeighborOptions) (string, map[string]any) {
+	cypher := "MATCH (n) WHERE elementId(n) = $id " +
+		"OPTIONAL MATCH (n)" + relPattern("", o.RelationshipTypes, o.Depth, o.Direction) + "(m) " +
+		"WITH n, [n] + collect(DISTINCT m) AS nodes " +
+		"UNWIND nodes AS a " +
+		"OPTIONAL MATCH (a)" + relPattern("r", o.RelationshipTypes, 1, graph.DirectionOutgoing) + "(b) WHERE b IN nodes " +
+		"RETURN nodes, collect(DISTINCT r) AS edges"
+	return cypher, map[string]any{"id": nodeID}
+}
+
+// relPattern returns the relationship pattern of up to depth edges of types
+// in direction, bound to variable unless it is empty.
+func relPattern(variable string, types []string, depth int, direction graph.EdgeDirection) string {
+	var rel strings.Builder
+	rel.WriteString("[" + variable)
+	for i, typ := range types {
+		if i == 0 {
+			rel.WriteString(":")
+		} else {
+			rel.WriteString("|")
+		}
+		rel.WriteString(quoteName(typ))
+	}
+	if depth > 1 {
+		rel.WriteString(fmt.Sprintf("*1..%d", depth))
+	}
+	rel.WriteString("]")
+
+	switch direction {
+	case graph.DirectionOutgoing:
+		return "-" + rel.String() + "->"
+	case graph.DirectionIncoming:
+		return "<-" + rel.String() + "-"
+	default:
+		return "-" + rel.String() + "-"
+	}
+}
+
+// toNeighborhood returns the neighborhood of the nodes and edges lists
+// returned by the Cypher of GetNeighbors and Traverse, empty if it returns no
+// row.
+func toNeighborhood(res *graph.QueryResult) *graph.Neighborhood {
+	n := &graph.Neighborhood{}
+	if len(res.Records) > 0 {
+		// Empty lists aren't converted to typed slices, leaving them nil.
+		n.Nodes, _ = res.Records[0]["nodes"].([]*graph.Node)
+		n.Edges, _ = res.Records[0]["edges"].([]*graph.Edge)
+	}
+	return n
+}
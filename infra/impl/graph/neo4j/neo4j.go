@@ -3,6 +3,9 @@ package neo4j
 import (
 	"context"
 	"fmt"
+	"io"
+	"maps"
+	"slices"
 	"strings"
 
 	"github.com/me2seeks/forge/infra/contract/graph"
@@ -13,6 +16,11 @@ import (
 
 type neo4jClient struct {
 	driver neo4j.DriverWithContext
+	// database is the default database, the server's default if empty.
+	database string
+	exec     executor
+	// version is the version of the server, detected on first use.
+	version *versionCache
 }
 
 // Option is a function that configures the neo4j client
@@ -22,6 +30,7 @@ type Option func(*options)
 type options struct {
 	auth        auth.TokenManager
 	configurers []func(*config.Config)
+	database    string
 }
 
 // WithAuth sets the authentication token for the client
@@ -73,6 +82,14 @@ func WithConfigurers(configurers ...func(*config.Config)) Option {
 	}
 }
 
+// WithDatabase sets the database the client uses unless a call selects
+// another one with graph.WithDatabase. Defaults to the server's default database.
+func WithDatabase(database string) Option {
+	return func(o *options) {
+		o.database = database
+	}
+}
+
 // New creates a new neo4j client with the given options
 func New(ctx context.Context, uri string, opts ...Option) (graph.Client, error) {
 	// Default options
@@ -95,17 +112,31 @@ func New(ctx context.Context, uri string, opts ...Option) (graph.Client, error)
 		return nil, err
 	}
 
+	return NewWithDriver(driver, opts...), nil
+}
+
+// NewWithDriver creates a neo4j client on a driver created by the caller, e.g.
+// shared with other code, or connected to another Bolt server. Options
+// configuring the driver are ignored.
+func NewWithDriver(driver neo4j.DriverWithContext, opts ...Option) graph.Client {
+	o := &options{}
+	for _, opt := range opts {
+		opt(o)
+	}
 	return &neo4jClient{
-		driver: driver,
-	}, nil
+		driver:   driver,
+		database: o.database,
+		exec:     &sessionExecutor{driver: driver, database: o.database},
+		version:  &versionCache{},
+	}
 }
 
 func (c *neo4jClient) CreateNode(ctx context.Context, node *graph.Node) (*graph.Node, error) {
-	session := c.driver.NewSession(ctx, neo4j.SessionConfig{AccessMode: neo4j.AccessModeWrite})
-	defer session.Close(ctx)
-
-	result, err := session.ExecuteWrite(ctx, func(tx neo4j.ManagedTransaction) (any, error) {
-		cypher := "CREATE (n:`" + node.Labels[0] + "` $props) RETURN n"
+	if err := graph.ValidateNames(node.Labels...); err != nil {
+		return nil, err
+	}
+	result, err := c.exec.write(ctx, func(tx runner) (any, error) {
+		cypher := "CREATE (n:" + quoteName(node.Labels[0]) + " $props) RETURN n"
 		params := map[string]any{"props": node.Properties}
 		res, err := tx.Run(ctx, cypher, params)
 		if err != nil {
@@ -138,10 +169,7 @@ func (c *neo4jClient) CreateNode(ctx context.Context, node *graph.Node) (*graph.
 }
 
 func (c *neo4jClient) GetNode(ctx context.Context, nodeID string) (*graph.Node, error) {
-	session := c.driver.NewSession(ctx, neo4j.SessionConfig{AccessMode: neo4j.AccessModeRead})
-	defer session.Close(ctx)
-
-	result, err := session.ExecuteRead(ctx, func(tx neo4j.ManagedTransaction) (any, error) {
+	result, err := c.exec.read(ctx, func(tx runner) (any, error) {
 		cypher := "MATCH (n) WHERE elementId(n) = $id RETURN n"
 		params := map[string]any{"id": nodeID}
 		res, err := tx.Run(ctx, cypher, params)
@@ -176,10 +204,7 @@ func (c *neo4jClient) GetNode(ctx context.Context, nodeID string) (*graph.Node,
 }
 
 func (c *neo4jClient) UpdateNode(ctx context.Context, nodeID string, properties graph.Properties) error {
-	session := c.driver.NewSession(ctx, neo4j.SessionConfig{AccessMode: neo4j.AccessModeWrite})
-	defer session.Close(ctx)
-
-	_, err := session.ExecuteWrite(ctx, func(tx neo4j.ManagedTransaction) (any, error) {
+	_, err := c.exec.write(ctx, func(tx runner) (any, error) {
 		cypher := "MATCH (n) WHERE elementId(n) = $id SET n += $props"
 		params := map[string]any{"id": nodeID, "props": properties}
 		_, err := tx.Run(ctx, cypher, params)
@@ -190,10 +215,7 @@ func (c *neo4jClient) UpdateNode(ctx context.Context, nodeID string, properties
 }
 
 func (c *neo4jClient) DeleteNode(ctx context.Context, nodeID string) error {
-	session := c.driver.NewSession(ctx, neo4j.SessionConfig{AccessMode: neo4j.AccessModeWrite})
-	defer session.Close(ctx)
-
-	_, err := session.ExecuteWrite(ctx, func(tx neo4j.ManagedTransaction) (any, error) {
+	_, err := c.exec.write(ctx, func(tx runner) (any, error) {
 		cypher := "MATCH (n) WHERE elementId(n) = $id DETACH DELETE n"
 		params := map[string]any{"id": nodeID}
 		_, err := tx.Run(ctx, cypher, params)
@@ -203,41 +225,73 @@ func (c *neo4jClient) DeleteNode(ctx context.Context, nodeID string) error {
 	return err
 }
 
-func (c *neo4jClient) CreateEdge(ctx context.Context, edge *graph.Edge) (*graph.Edge, error) {
-	session := c.driver.NewSession(ctx, neo4j.SessionConfig{AccessMode: neo4j.AccessModeWrite})
-	defer session.Close(ctx)
-
-	result, err := session.ExecuteWrite(ctx, func(tx neo4j.ManagedTransaction) (any, error) {
-		var cypher string
-		params := make(map[string]any)
-
-		// Check if selectors are provided
-		if edge.SourceNodeSelector != nil && edge.TargetNodeSelector != nil {
-			// Build MATCH clauses for source and target nodes based on selectors
-			sourceMatch, sourceParams := buildNodeMatchClause("a", edge.SourceNodeSelector)
-			targetMatch, targetParams := buildNodeMatchClause("b", edge.TargetNodeSelector)
+func (c *neo4jClient) MergeNode(ctx context.Context, node *graph.Node, matchKeys []string) (*graph.Node, error) {
+	cypher, params, err := buildMergeNodeCypher(node, matchKeys)
+	if err != nil {
+		return nil, err
+	}
+	result, err := c.exec.write(ctx, func(tx runner) (any, error) {
+		res, err := tx.Run(ctx, cypher, params)
+		if err != nil {
+			return nil, err
+		}
+		record, err := res.Single(ctx)
+		if err != nil {
+			return nil, err
+		}
+		n, _ := record.Get("n")
+		return n, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	merged, ok := result.(neo4j.Node)
+	if !ok {
+		return nil, fmt.Errorf("unexpected merge result %T", result)
+	}
+	return toGraphNode(merged), nil
+}
 
-			// Merge parameters
-			for k, v := range sourceParams {
-				params[k] = v
-			}
-			for k, v := range targetParams {
-				params[k] = v
-			}
-			params["props"] = edge.Properties
+// buildMergeNodeCypher builds a MERGE on the labels and matchKeys of node. A
+// created node gets all its properties, a matched one has them added.
+func buildMergeNodeCypher(node *graph.Node, matchKeys []string) (string, map[string]any, error) {
+	if len(node.Labels) == 0 {
+		return "", nil, fmt.Errorf("merge node: no label")
+	}
+	if len(matchKeys) == 0 {
+		return "", nil, fmt.Errorf("merge node: no match key")
+	}
+	if err := graph.ValidateNames(append(slices.Clone(node.Labels), matchKeys...)...); err != nil {
+		return "", nil, fmt.Errorf("merge node: %w", err)
+	}
 
-			// Construct the full Cypher query
-			cypher = fmt.Sprintf("%s %s CREATE (a)-[r:`%s` $props]->(b) RETURN r", sourceMatch, targetMatch, edge.Label)
-		} else {
-			// Fallback to the original implementation using element IDs
-			cypher = "MATCH (a), (b) WHERE elementId(a) = $sourceId AND elementId(b) = $targetId CREATE (a)-[r:`" + edge.Label + "` $props]->(b) RETURN r"
-			params = map[string]any{
-				"sourceId": edge.SourceNodeID,
-				"targetId": edge.TargetNodeID,
-				"props":    edge.Properties,
-			}
+	var sb strings.Builder
+	sb.WriteString("MERGE (n")
+	for _, label := range node.Labels {
+		sb.WriteString(":" + quoteName(label))
+	}
+	params := map[string]any{"props": node.Properties}
+	matches := make([]string, 0, len(matchKeys))
+	for i, key := range matchKeys {
+		value, ok := node.Properties[key]
+		if !ok {
+			return "", nil, fmt.Errorf("merge node: match key %s is not a property of the node", key)
 		}
+		paramName := fmt.Sprintf("match_%d", i)
+		params[paramName] = value
+		matches = append(matches, quoteName(key)+": $"+paramName)
+	}
+	sb.WriteString(" {" + strings.Join(matches, ", ") + "})")
+	sb.WriteString(" ON CREATE SET n = $props ON MATCH SET n += $props RETURN n")
+	return sb.String(), params, nil
+}
 
+func (c *neo4jClient) CreateEdge(ctx context.Context, edge *graph.Edge) (*graph.Edge, error) {
+	if err := edge.ValidateNames(); err != nil {
+		return nil, err
+	}
+	cypher, params := buildCreateEdgeCypher(edge)
+	result, err := c.exec.write(ctx, func(tx runner) (any, error) {
 		res, err := tx.Run(ctx, cypher, params)
 		if err != nil {
 			return nil, err
@@ -269,11 +323,136 @@ func (c *neo4jClient) CreateEdge(ctx context.Context, edge *graph.Edge) (*graph.
 	}, nil
 }
 
-func (c *neo4jClient) GetEdge(ctx context.Context, edgeID string) (*graph.Edge, error) {
-	session := c.driver.NewSession(ctx, neo4j.SessionConfig{AccessMode: neo4j.AccessModeRead})
-	defer session.Close(ctx)
+// buildCreateEdgeCypher creates edge between the nodes matched by its
+// selectors if both are set, or by its node IDs otherwise.
+func buildCreateEdgeCypher(edge *graph.Edge) (string, map[string]any) {
+	// Check if selectors are provided
+	if edge.SourceNodeSelector != nil && edge.TargetNodeSelector != nil {
+		// Build MATCH clauses for source and target nodes based on selectors
+		sourceMatch, sourceParams := buildNodeMatchClause("a", edge.SourceNodeSelector)
+		targetMatch, targetParams := buildNodeMatchClause("b", edge.TargetNodeSelector)
+
+		// Merge parameters
+		params := make(map[string]any)
+		for k, v := range sourceParams {
+			params[k] = v
+		}
+		for k, v := range targetParams {
+			params[k] = v
+		}
+		params["props"] = propsOrEmpty(edge.Properties)
+
+		// Construct the full Cypher query
+		return fmt.Sprintf("%s %s CREATE (a)-[r:%s $props]->(b) RETURN r", sourceMatch, targetMatch, quoteName(edge.Label)), params
+	}
+
+	// Fallback to the original implementation using element IDs
+	cypher := "MATCH (a), (b) WHERE elementId(a) = $sourceId AND elementId(b) = $targetId CREATE (a)-[r:" + quoteName(edge.Label) + " $props]->(b) RETURN r"
+	return cypher, map[string]any{
+		"sourceId": edge.SourceNodeID,
+		"targetId": edge.TargetNodeID,
+		"props":    propsOrEmpty(edge.Properties),
+	}
+}
+
+func (c *neo4jClient) MergeEdge(ctx context.Context, edge *graph.Edge, onCreate, onMatch graph.Properties) (*graph.Edge, error) {
+	cypher, params, err := buildMergeEdgeCypher(edge, onCreate, onMatch)
+	if err != nil {
+		return nil, err
+	}
+	result, err := c.exec.write(ctx, func(tx runner) (any, error) {
+		res, err := tx.Run(ctx, cypher, params)
+		if err != nil {
+			return nil, err
+		}
+		records, err := res.Collect(ctx)
+		if err != nil {
+			return nil, err
+		}
+		switch len(records) {
+		case 0:
+			return nil, nil
+		case 1:
+			r, _ := records[0].Get("r")
+			return r, nil
+		default:
+			// Failing rolls back the edges merged between the other matches.
+			return nil, fmt.Errorf("merge edge: endpoints match %d pairs of nodes", len(records))
+		}
+	})
+	if err != nil || result == nil {
+		return nil, err
+	}
+	merged, ok := result.(neo4j.Relationship)
+	if !ok {
+		return nil, fmt.Errorf("unexpected merge result %T", result)
+	}
+	return toGraphEdge(merged), nil
+}
 
-	result, err := session.ExecuteRead(ctx, func(tx neo4j.ManagedTransaction) (any, error) {
+// buildMergeEdgeCypher matches the endpoints of edge and builds a MERGE on its
+// label and properties between them.
+func buildMergeEdgeCypher(edge *graph.Edge, onCreate, onMatch graph.Properties) (string, map[string]any, error) {
+	if edge.Label == "" {
+		return "", nil, fmt.Errorf("merge edge: no label")
+	}
+	if err := edge.ValidateNames(); err != nil {
+		return "", nil, fmt.Errorf("merge edge: %w", err)
+	}
+	params := make(map[string]any)
+	source, err := buildEndpointMatchClause("a", edge.SourceNodeID, edge.SourceNodeSelector, params)
+	if err != nil {
+		return "", nil, err
+	}
+	target, err := buildEndpointMatchClause("b", edge.TargetNodeID, edge.TargetNodeSelector, params)
+	if err != nil {
+		return "", nil, err
+	}
+
+	var sb strings.Builder
+	sb.WriteString(source + " " + target + " MERGE (a)-[r:" + quoteName(edge.Label))
+	if len(edge.Properties) > 0 {
+		// MERGE doesn't take a map parameter, and the keys are sorted to keep
+		// the query plan cacheable.
+		keys := slices.Sorted(maps.Keys(edge.Properties))
+		props := make([]string, 0, len(keys))
+		for i, key := range keys {
+			paramName := fmt.Sprintf("merge_%d", i)
+			params[paramName] = edge.Properties[key]
+			props = append(props, quoteName(key)+": $"+paramName)
+		}
+		sb.WriteString(" {" + strings.Join(props, ", ") + "}")
+	}
+	sb.WriteString("]->(b)")
+	if len(onCreate) > 0 {
+		params["onCreate"] = onCreate
+		sb.WriteString(" ON CREATE SET r += $onCreate")
+	}
+	if len(onMatch) > 0 {
+		params["onMatch"] = onMatch
+		sb.WriteString(" ON MATCH SET r += $onMatch")
+	}
+	sb.WriteString(" RETURN r")
+	return sb.String(), params, nil
+}
+
+// buildEndpointMatchClause matches the endpoint alias by selector if set, or by
+// its element ID otherwise, adding the parameters to params.
+func buildEndpointMatchClause(alias, nodeID string, selector *graph.NodeSelector, params map[string]any) (string, error) {
+	if selector != nil {
+		clause, selectorParams := buildNodeMatchClause(alias, selector)
+		maps.Copy(params, selectorParams)
+		return clause, nil
+	}
+	if nodeID == "" {
+		return "", fmt.Errorf("no node ID or selector for endpoint %s", alias)
+	}
+	params[alias+"_id"] = nodeID
+	return fmt.Sprintf("MATCH (%s) WHERE elementId(%s) = $%s_id", alias, alias, alias), nil
+}
+
+func (c *neo4jClient) GetEdge(ctx context.Context, edgeID string) (*graph.Edge, error) {
+	result, err := c.exec.read(ctx, func(tx runner) (any, error) {
 		cypher := "MATCH ()-[r]->() WHERE elementId(r) = $id RETURN r"
 		params := map[string]any{"id": edgeID}
 		res, err := tx.Run(ctx, cypher, params)
@@ -310,10 +489,7 @@ func (c *neo4jClient) GetEdge(ctx context.Context, edgeID string) (*graph.Edge,
 }
 
 func (c *neo4jClient) UpdateEdge(ctx context.Context, edgeID string, properties graph.Properties) error {
-	session := c.driver.NewSession(ctx, neo4j.SessionConfig{AccessMode: neo4j.AccessModeWrite})
-	defer session.Close(ctx)
-
-	_, err := session.ExecuteWrite(ctx, func(tx neo4j.ManagedTransaction) (any, error) {
+	_, err := c.exec.write(ctx, func(tx runner) (any, error) {
 		cypher := "MATCH ()-[r]->() WHERE elementId(r) = $id SET r += $props"
 		params := map[string]any{"id": edgeID, "props": properties}
 		_, err := tx.Run(ctx, cypher, params)
@@ -324,10 +500,7 @@ func (c *neo4jClient) UpdateEdge(ctx context.Context, edgeID string, properties
 }
 
 func (c *neo4jClient) DeleteEdge(ctx context.Context, edgeID string) error {
-	session := c.driver.NewSession(ctx, neo4j.SessionConfig{AccessMode: neo4j.AccessModeWrite})
-	defer session.Close(ctx)
-
-	_, err := session.ExecuteWrite(ctx, func(tx neo4j.ManagedTransaction) (any, error) {
+	_, err := c.exec.write(ctx, func(tx runner) (any, error) {
 		cypher := "MATCH ()-[r]->() WHERE elementId(r) = $id DELETE r"
 		params := map[string]any{"id": edgeID}
 		_, err := tx.Run(ctx, cypher, params)
@@ -338,8 +511,11 @@ func (c *neo4jClient) DeleteEdge(ctx context.Context, edgeID string) error {
 }
 
 func (c *neo4jClient) CreateNodeIndex(ctx context.Context, label string, properties []string) error {
+	if err := graph.ValidateNames(append([]string{label}, properties...)...); err != nil {
+		return err
+	}
 	// TODO Neo4j 社区版一次只能为一个属性创建索引
-	cypher := "CREATE INDEX ON :`" + label + "`(" + properties[0] + ")"
+	cypher := "CREATE INDEX ON :" + quoteName(label) + "(" + quoteName(properties[0]) + ")"
 	return c.runCypher(ctx, cypher, nil)
 }
 
@@ -348,19 +524,30 @@ func (c *neo4jClient) CreateEdgeIndex(ctx context.Context, label string, propert
 	return nil
 }
 
+// CreateConstraint creates the constraint on the nodes of label, in the syntax
+// of the server version: ASSERT before Neo4j 4.4, and REQUIRE since, with IF
+// NOT EXISTS, as creating an existing constraint fails there. Property type
+// constraints need Neo4j 5.9.
 func (c *neo4jClient) CreateConstraint(ctx context.Context, label, property string, constraintType graph.ConstraintType) error {
-	var cypher string
-	switch constraintType {
-	case graph.ConstraintUnique:
-		cypher = "CREATE CONSTRAINT ON (n:`" + label + "`) ASSERT n." + property + " IS UNIQUE"
-	case graph.ConstraintExists:
-		cypher = "CREATE CONSTRAINT ON (n:`" + label + "`) ASSERT exists(n." + property + ")"
+	if err := graph.ValidateNames(label, property); err != nil {
+		return err
+	}
+	version, err := c.serverVersion(ctx)
+	if err != nil {
+		return err
+	}
+	cypher, err := buildCreateConstraintCypher(label, property, constraintType, version)
+	if err != nil {
+		return err
 	}
 	return c.runCypher(ctx, cypher, nil)
 }
 
 func (c *neo4jClient) DropNodeIndex(ctx context.Context, label string, properties []string) error {
-	cypher := "DROP INDEX ON :`" + label + "`(" + properties[0] + ")"
+	if err := graph.ValidateNames(append([]string{label}, properties...)...); err != nil {
+		return err
+	}
+	cypher := "DROP INDEX ON :" + quoteName(label) + "(" + quoteName(properties[0]) + ")"
 	return c.runCypher(ctx, cypher, nil)
 }
 
@@ -369,22 +556,40 @@ func (c *neo4jClient) DropEdgeIndex(ctx context.Context, label string, propertie
 	return nil
 }
 
+// DropConstraint drops the constraint on the nodes of label. Since Neo4j 4.4,
+// constraints are dropped by name, so it is looked up with ListConstraints,
+// nothing being dropped if there is none.
 func (c *neo4jClient) DropConstraint(ctx context.Context, label, property string, constraintType graph.ConstraintType) error {
-	var cypher string
-	switch constraintType {
-	case graph.ConstraintUnique:
-		cypher = "DROP CONSTRAINT ON (n:`" + label + "`) ASSERT n." + property + " IS UNIQUE"
-	case graph.ConstraintExists:
-		cypher = "DROP CONSTRAINT ON (n:`" + label + "`) ASSERT exists(n." + property + ")"
+	if err := graph.ValidateNames(label, property); err != nil {
+		return err
 	}
-	return c.runCypher(ctx, cypher, nil)
+	version, err := c.serverVersion(ctx)
+	if err != nil {
+		return err
+	}
+	if !version.atLeast(4, 4) {
+		cypher, err := buildDropConstraintCypher(label, property, constraintType)
+		if err != nil {
+			return err
+		}
+		return c.runCypher(ctx, cypher, nil)
+	}
+
+	constraints, err := c.ListConstraints(ctx)
+	if err != nil {
+		return err
+	}
+	for _, constraint := range constraints {
+		if constraint.Type == constraintType && constraint.EntityType == graph.EntityNode &&
+			slices.Equal(constraint.LabelsOrTypes, []string{label}) && slices.Equal(constraint.Properties, []string{property}) {
+			return c.runCypher(ctx, "DROP CONSTRAINT "+quoteName(constraint.Name)+" IF EXISTS", nil)
+		}
+	}
+	return nil
 }
 
 func (c *neo4jClient) runCypher(ctx context.Context, cypher string, params map[string]any) error {
-	session := c.driver.NewSession(ctx, neo4j.SessionConfig{AccessMode: neo4j.AccessModeWrite})
-	defer session.Close(ctx)
-
-	_, err := session.ExecuteWrite(ctx, func(tx neo4j.ManagedTransaction) (any, error) {
+	_, err := c.exec.write(ctx, func(tx runner) (any, error) {
 		_, err := tx.Run(ctx, cypher, params)
 		return nil, err
 	})
@@ -393,194 +598,57 @@ func (c *neo4jClient) runCypher(ctx context.Context, cypher string, params map[s
 
 // UpdateNodesByQuery updates properties of all nodes matching the query.
 func (c *neo4jClient) UpdateNodesByQuery(ctx context.Context, query *graph.Query, properties graph.Properties) (int, error) {
-	// We need to know the aliases to build the RETURN count clause.
-	// Collect aliases from MATCH for the operation clause generator and RETURN clause
-	var aliasesInMatch []string
-	for _, p := range query.Match {
-		// If alias is empty, use a default one
-		alias := p.Alias
-		if alias == "" {
-			alias = "n" // Default alias for nodes
-		}
-		aliasesInMatch = append(aliasesInMatch, alias)
-		if p.Edge != nil {
-			// If edge alias is empty, use a default one
-			edgeAlias := p.Edge.Alias
-			if edgeAlias == "" {
-				edgeAlias = "r" // Default alias for relationships
-			}
-			aliasesInMatch = append(aliasesInMatch, edgeAlias)
-			if p.Edge.Node != nil {
-				// If edge node alias is empty, use a default one
-				edgeNodeAlias := p.Edge.Node.Alias
-				if edgeNodeAlias == "" {
-					edgeNodeAlias = "m" // Default alias for nodes connected by relationship
-				}
-				aliasesInMatch = append(aliasesInMatch, edgeNodeAlias)
-			}
-		}
-	}
-
-	// Define the operation clause generator for SET
-	opClauseGenerator := func(aliasesInMatchForOp []string) (string, map[string]any) {
-		// Heuristic: Assume the first node alias in the MATCH clause is the target for update.
-		// A more robust solution might require the query or method to specify the target alias.
-		if len(aliasesInMatchForOp) == 0 {
-			// If no aliases, we can't build a SET clause. This should ideally be an error.
-			return "", make(map[string]any)
-		}
-		targetAlias := aliasesInMatchForOp[0]
-		return buildSetClause(targetAlias, properties)
-	}
-
-	cypher, params := buildCypherQueryForOperation(query, opClauseGenerator)
-	// Append RETURN count for affected nodes
-	if len(aliasesInMatch) > 0 {
-		cypher += " RETURN count(" + aliasesInMatch[0] + ")"
-	} else {
-		cypher += " RETURN count(*)"
+	if err := query.ValidateNames(); err != nil {
+		return 0, err
 	}
-
-	session := c.driver.NewSession(ctx, neo4j.SessionConfig{AccessMode: neo4j.AccessModeWrite})
-	defer session.Close(ctx)
-
-	result, err := session.ExecuteWrite(ctx, func(tx neo4j.ManagedTransaction) (any, error) {
-		res, err := tx.Run(ctx, cypher, params)
-		if err != nil {
-			return nil, err
-		}
-		if res.Next(ctx) {
-			key := "count(*)"
-			if len(aliasesInMatch) > 0 {
-				key = "count(" + aliasesInMatch[0] + ")"
-			}
-			count, _ := res.Record().Get(key)
-			return count, nil
-		}
-		return int64(0), nil
-	})
-	if err != nil {
+	if err := properties.ValidateNames(); err != nil {
 		return 0, err
 	}
-
-	return int(result.(int64)), nil
+	cypher, params := buildUpdateByQueryCypher(query, false, properties)
+	return c.runCount(ctx, cypher, params)
 }
 
 // UpdateEdgesByQuery updates properties of all edges matching the query.
-// The implementation is very similar to UpdateNodesByQuery.
 func (c *neo4jClient) UpdateEdgesByQuery(ctx context.Context, query *graph.Query, properties graph.Properties) (int, error) {
-	// Determine edge alias for SET clause and RETURN count
-	edgeAlias := ""
-	if len(query.Match) > 0 && query.Match[0].Edge != nil && query.Match[0].Edge.Alias != "" {
-		edgeAlias = query.Match[0].Edge.Alias
-	}
-
-	// Define the operation clause generator for SET
-	opClauseGenerator := func(aliasesInMatchForOp []string) (string, map[string]any) {
-		// Use the pre-determined edge alias
-		if edgeAlias == "" {
-			return "", make(map[string]any)
-		}
-		return buildSetClause(edgeAlias, properties)
-	}
-
-	cypher, params := buildCypherQueryForOperation(query, opClauseGenerator)
-	// Append RETURN count for affected edges
-	if edgeAlias != "" {
-		cypher += " RETURN count(" + edgeAlias + ")"
-	} else {
-		cypher += " RETURN count(*)"
+	if err := query.ValidateNames(); err != nil {
+		return 0, err
 	}
-
-	session := c.driver.NewSession(ctx, neo4j.SessionConfig{AccessMode: neo4j.AccessModeWrite})
-	defer session.Close(ctx)
-
-	result, err := session.ExecuteWrite(ctx, func(tx neo4j.ManagedTransaction) (any, error) {
-		res, err := tx.Run(ctx, cypher, params)
-		if err != nil {
-			return nil, err
-		}
-		if res.Next(ctx) {
-			key := "count(*)"
-			if edgeAlias != "" {
-				key = "count(" + edgeAlias + ")"
-			}
-			count, _ := res.Record().Get(key)
-			return count, nil
-		}
-		return int64(0), nil
-	})
-	if err != nil {
+	if err := properties.ValidateNames(); err != nil {
 		return 0, err
 	}
-
-	return int(result.(int64)), nil
+	cypher, params := buildUpdateByQueryCypher(query, true, properties)
+	return c.runCount(ctx, cypher, params)
 }
 
 // DeleteNodesByQuery deletes all nodes matching the query.
 func (c *neo4jClient) DeleteNodesByQuery(ctx context.Context, query *graph.Query) (int, error) {
-	// Collect aliases from MATCH
-	var aliasesInMatch []string
-	for _, p := range query.Match {
-		// If alias is empty, use a default one
-		alias := p.Alias
-		if alias == "" {
-			alias = "n" // Default alias for nodes
-		}
-		aliasesInMatch = append(aliasesInMatch, alias)
-		if p.Edge != nil {
-			// If edge alias is empty, use a default one
-			edgeAlias := p.Edge.Alias
-			if edgeAlias == "" {
-				edgeAlias = "r" // Default alias for relationships
-			}
-			aliasesInMatch = append(aliasesInMatch, edgeAlias)
-			if p.Edge.Node != nil {
-				// If edge node alias is empty, use a default one
-				edgeNodeAlias := p.Edge.Node.Alias
-				if edgeNodeAlias == "" {
-					edgeNodeAlias = "m" // Default alias for nodes connected by relationship
-				}
-				aliasesInMatch = append(aliasesInMatch, edgeNodeAlias)
-			}
-		}
-	}
-
-	opClauseGenerator := func(aliasesInMatchForOp []string) (string, map[string]any) {
-		// Heuristic: Assume the first node alias in the MATCH clause is the target for deletion.
-		if len(aliasesInMatchForOp) == 0 {
-			return "", make(map[string]any)
-		}
-		targetAlias := aliasesInMatchForOp[0]
-		// Neo4j requires DETACH DELETE for nodes to remove relationships too.
-		return "DETACH DELETE " + targetAlias, make(map[string]any)
+	if err := query.ValidateNames(); err != nil {
+		return 0, err
 	}
+	cypher, params := buildDeleteByQueryCypher(query, false)
+	return c.runCount(ctx, cypher, params)
+}
 
-	cypher, params := buildCypherQueryForOperation(query, opClauseGenerator)
-	// Append RETURN count for deleted nodes
-	if len(aliasesInMatch) > 0 {
-		cypher += " RETURN count(" + aliasesInMatch[0] + ")"
-	} else {
-		cypher += " RETURN count(*)"
+// DeleteEdgesByQuery deletes all edges matching the query.
+func (c *neo4jClient) DeleteEdgesByQuery(ctx context.Context, query *graph.Query) (int, error) {
+	if err := query.ValidateNames(); err != nil {
+		return 0, err
 	}
+	cypher, params := buildDeleteByQueryCypher(query, true)
+	return c.runCount(ctx, cypher, params)
+}
 
-	session := c.driver.NewSession(ctx, neo4j.SessionConfig{AccessMode: neo4j.AccessModeWrite})
-	defer session.Close(ctx)
-
-	result, err := session.ExecuteWrite(ctx, func(tx neo4j.ManagedTransaction) (any, error) {
+// runCount runs a write returning the count of the entities it changed.
+func (c *neo4jClient) runCount(ctx context.Context, cypher string, params map[string]any) (int, error) {
+	result, err := c.exec.write(ctx, func(tx runner) (any, error) {
 		res, err := tx.Run(ctx, cypher, params)
 		if err != nil {
 			return nil, err
 		}
 		if res.Next(ctx) {
-			key := "count(*)"
-			if len(aliasesInMatch) > 0 {
-				key = "count(" + aliasesInMatch[0] + ")"
-			}
-			count, _ := res.Record().Get(key)
-			return count, nil
+			return res.Record().Values[0], nil
 		}
-		return int64(0), nil
+		return int64(0), res.Err()
 	})
 	if err != nil {
 		return 0, err
@@ -589,64 +657,71 @@ func (c *neo4jClient) DeleteNodesByQuery(ctx context.Context, query *graph.Query
 	return int(result.(int64)), nil
 }
 
-// DeleteEdgesByQuery deletes all edges matching the query.
-func (c *neo4jClient) DeleteEdgesByQuery(ctx context.Context, query *graph.Query) (int, error) {
-	// Determine edge alias for DELETE clause and RETURN count
-	edgeAlias := ""
-	if len(query.Match) > 0 && query.Match[0].Edge != nil && query.Match[0].Edge.Alias != "" {
-		edgeAlias = query.Match[0].Edge.Alias
+// targetAlias returns the alias changed by the ByQuery operations: the first
+// node alias of the query, or the edge alias of its first pattern if edges is
+// true, empty if there is none.
+func targetAlias(query *graph.Query, edges bool) string {
+	if len(query.Match) == 0 {
+		return ""
 	}
-
-	// Define the operation clause generator for DELETE
-	opClauseGenerator := func(aliasesInMatchForOp []string) (string, map[string]any) {
-		// Use the pre-determined edge alias
-		if edgeAlias == "" {
-			return "", make(map[string]any)
+	if !edges {
+		if query.Match[0].Alias == "" {
+			return "n" // Default alias for nodes
 		}
-		// For edges, it's just DELETE
-		return "DELETE " + edgeAlias, make(map[string]any)
+		return query.Match[0].Alias
 	}
-
-	cypher, params := buildCypherQueryForOperation(query, opClauseGenerator)
-	// Append RETURN count for deleted edges
-	if edgeAlias != "" {
-		cypher += " RETURN count(" + edgeAlias + ")"
-	} else {
-		cypher += " RETURN count(*)"
+	if query.Match[0].Edge != nil {
+		return query.Match[0].Edge.Alias
 	}
+	return ""
+}
 
-	session := c.driver.NewSession(ctx, neo4j.SessionConfig{AccessMode: neo4j.AccessModeWrite})
-	defer session.Close(ctx)
-
-	result, err := session.ExecuteWrite(ctx, func(tx neo4j.ManagedTransaction) (any, error) {
-		res, err := tx.Run(ctx, cypher, params)
-		if err != nil {
-			return nil, err
+// buildUpdateByQueryCypher sets properties on the target alias of the query,
+// see targetAlias, and returns the count of its matches.
+func buildUpdateByQueryCypher(query *graph.Query, edges bool, properties graph.Properties) (string, map[string]any) {
+	alias := targetAlias(query, edges)
+	cypher, params := buildCypherQueryForOperation(query, func([]string) (string, map[string]any) {
+		if alias == "" {
+			return "", make(map[string]any)
 		}
-		if res.Next(ctx) {
-			key := "count(*)"
-			if edgeAlias != "" {
-				key = "count(" + edgeAlias + ")"
-			}
-			count, _ := res.Record().Get(key)
-			return count, nil
+		return buildSetClause(alias, properties)
+	})
+	return cypher + countClause(alias), params
+}
+
+// buildDeleteByQueryCypher deletes the target alias of the query, see
+// targetAlias, and returns the count of its matches. Nodes are deleted with
+// their relationships.
+func buildDeleteByQueryCypher(query *graph.Query, edges bool) (string, map[string]any) {
+	alias := targetAlias(query, edges)
+	cypher, params := buildCypherQueryForOperation(query, func([]string) (string, map[string]any) {
+		switch {
+		case alias == "":
+			return "", make(map[string]any)
+		case edges:
+			return "DELETE " + alias, make(map[string]any)
+		default:
+			// Neo4j requires DETACH DELETE for nodes to remove relationships too.
+			return "DETACH DELETE " + alias, make(map[string]any)
 		}
-		return int64(0), nil
 	})
-	if err != nil {
-		return 0, err
-	}
+	return cypher + countClause(alias), params
+}
 
-	return int(result.(int64)), nil
+func countClause(alias string) string {
+	if alias == "" {
+		return " RETURN count(*)"
+	}
+	return " RETURN count(" + alias + ")"
 }
 
 func (c *neo4jClient) Query(ctx context.Context, query *graph.Query) (*graph.QueryResult, error) {
+	if err := query.ValidateNames(); err != nil {
+		return nil, err
+	}
 	cypher, params := buildCypherQuery(query)
 
-	session := c.driver.NewSession(ctx, neo4j.SessionConfig{AccessMode: neo4j.AccessModeRead})
-	defer session.Close(ctx)
-
-	result, err := session.ExecuteRead(ctx, func(tx neo4j.ManagedTransaction) (any, error) {
+	result, err := c.exec.read(ctx, func(tx runner) (any, error) {
 		res, err := tx.Run(ctx, cypher, params)
 		if err != nil {
 			return nil, err
@@ -672,10 +747,7 @@ func (c *neo4jClient) Query(ctx context.Context, query *graph.Query) (*graph.Que
 }
 
 func (c *neo4jClient) RawQuery(ctx context.Context, query string, params map[string]any) (*graph.QueryResult, error) {
-	session := c.driver.NewSession(ctx, neo4j.SessionConfig{AccessMode: neo4j.AccessModeRead})
-	defer session.Close(ctx)
-
-	result, err := session.ExecuteRead(ctx, func(tx neo4j.ManagedTransaction) (any, error) {
+	result, err := c.exec.read(ctx, func(tx runner) (any, error) {
 		res, err := tx.Run(ctx, query, params)
 		if err != nil {
 			return nil, err
@@ -724,17 +796,16 @@ func buildMatchClause(matchPatterns []graph.Pattern, params map[string]any) stri
 		sb.WriteString(alias)
 		// Correctly format multiple node labels, e.g., :Person:Manager
 		for _, label := range p.Labels {
-			sb.WriteString(":`")
-			sb.WriteString(label)
-			sb.WriteString("`")
+			sb.WriteString(":")
+			sb.WriteString(quoteName(label))
 		}
 		if len(p.Properties) > 0 {
 			sb.WriteString(" {")
 			propStrings := make([]string, 0, len(p.Properties))
 			for key, value := range p.Properties {
-				paramName := alias + "_" + key
+				paramName := uniqueParam(params, alias+"_"+key)
 				params[paramName] = value
-				propStrings = append(propStrings, key+": $"+paramName)
+				propStrings = append(propStrings, safeName(key)+": $"+paramName)
 			}
 			sb.WriteString(strings.Join(propStrings, ", "))
 			sb.WriteString("}")
@@ -764,7 +835,11 @@ func buildMatchClause(matchPatterns []graph.Pattern, params map[string]any) stri
 			if len(edgePattern.Labels) > 0 {
 				// Correctly format multiple relationship types, e.g., :KNOWS|LOVES
 				sb.WriteString(":")
-				sb.WriteString(strings.Join(edgePattern.Labels, "|"))
+				types := make([]string, len(edgePattern.Labels))
+				for i, label := range edgePattern.Labels {
+					types[i] = safeName(label)
+				}
+				sb.WriteString(strings.Join(types, "|"))
 			}
 
 			// Handle variable-length path syntax
@@ -789,9 +864,9 @@ func buildMatchClause(matchPatterns []graph.Pattern, params map[string]any) stri
 				sb.WriteString(" {")
 				propStrings := make([]string, 0, len(edgePattern.Properties))
 				for key, value := range edgePattern.Properties {
-					paramName := edgeAlias + "_" + key
+					paramName := uniqueParam(params, edgeAlias+"_"+key)
 					params[paramName] = value
-					propStrings = append(propStrings, key+": $"+paramName)
+					propStrings = append(propStrings, safeName(key)+": $"+paramName)
 				}
 				sb.WriteString(strings.Join(propStrings, ", "))
 				sb.WriteString("}")
@@ -820,9 +895,8 @@ func buildMatchClause(matchPatterns []graph.Pattern, params map[string]any) stri
 				sb.WriteString(edgeNodeAlias)
 				// Correctly format multiple node labels for the target node
 				for _, label := range edgePattern.Node.Labels {
-					sb.WriteString(":`")
-					sb.WriteString(label)
-					sb.WriteString("`")
+					sb.WriteString(":")
+					sb.WriteString(quoteName(label))
 				}
 				// Note: For full recursive support, we'd also handle edgePattern.Node.Properties here
 				// and in the params map. For now, we keep it simple for the first step.
@@ -838,23 +912,14 @@ func buildMatchClause(matchPatterns []graph.Pattern, params map[string]any) stri
 func buildCondition(cond graph.Condition, params map[string]any) string {
 	var sb strings.Builder
 	sb.WriteString(cond.Alias)
-	sb.WriteString(".")
-	sb.WriteString(cond.Property)
-
 	// Generate a unique parameter name
-	paramName := cond.Alias + "_" + cond.Property
-	// Handle potential name collisions by appending a counter if needed
-	// A more robust solution might be needed for complex cases, but this is a start.
-	counter := 0
-	originalParamName := paramName
-	for {
-		if _, exists := params[paramName]; !exists {
-			break
-		}
-		counter++
-		paramName = fmt.Sprintf("%s_%d", originalParamName, counter)
+	base := cond.Alias
+	if cond.Property != "" {
+		sb.WriteString(".")
+		sb.WriteString(safeName(cond.Property))
+		base += "_" + cond.Property
 	}
-
+	paramName := uniqueParam(params, base)
 	params[paramName] = cond.Value
 
 	switch cond.Operator {
@@ -956,14 +1021,7 @@ func buildCypherQuery(query *graph.Query) (string, map[string]any) {
 		params := make(map[string]any) // Local params for this operation clause
 		sb.WriteString("RETURN ")
 
-		var returnClauses []string
-		for _, r := range query.Return {
-			clause := r.Expression
-			if r.Alias != "" {
-				clause += " AS " + r.Alias
-			}
-			returnClauses = append(returnClauses, clause)
-		}
+		returnClauses := buildProjection(query.Return)
 		if len(returnClauses) == 0 {
 			// If no return clauses are specified, return everything from MATCH
 			// This is a simple heuristic; a more robust solution might be needed.
@@ -978,7 +1036,7 @@ func buildCypherQuery(query *graph.Query) (string, map[string]any) {
 			for _, o := range query.OrderBy {
 				// Note: OrderBy might need adjustment if it's ordering by an aliased expression.
 				// This implementation assumes ordering by a property on a variable.
-				orderStr := o.Alias + "." + o.Property
+				orderStr := orderExpression(query, o)
 				if !o.Asc {
 					orderStr += " DESC"
 				} else {
@@ -1024,10 +1082,28 @@ func buildCypherQueryForOperation(query *graph.Query, opClauseGenerator func(ali
 	}
 
 	// --- WHERE Clause ---
-	whereClause := buildWhereClause(query.Where, params)
-	if whereClause != "" {
-		sb.WriteString(whereClause)
-		sb.WriteString(" ")
+	whereClauses := []string{buildWhereClause(query.Where, params)}
+
+	// --- WITH Clauses ---
+	for _, stage := range query.With {
+		whereClauses = append(whereClauses, "WITH "+strings.Join(buildProjection(stage.Items), ", "))
+		whereClauses = append(whereClauses, buildWhereClause(stage.Where, params))
+	}
+
+	// The keyset condition applies to the records returned, so after the last stage.
+	if after := buildAfterCondition(query, params); after != "" {
+		last := len(whereClauses) - 1
+		if whereClauses[last] == "" {
+			whereClauses[last] = "WHERE " + after
+		} else {
+			whereClauses[last] += " AND " + after
+		}
+	}
+	for _, clause := range whereClauses {
+		if clause != "" {
+			sb.WriteString(clause)
+			sb.WriteString(" ")
+		}
 	}
 
 	// --- Operation Clause (RETURN, SET, DELETE) ---
@@ -1085,13 +1161,97 @@ func buildSetClause(alias string, properties graph.Properties) (string, map[stri
 	params := make(map[string]any)
 	for key, value := range properties {
 		// Generate a unique parameter name to avoid conflicts
-		paramName := fmt.Sprintf("%s_set_%s_%d", alias, key, len(params)) // Simple uniqueness
+		paramName := uniqueParam(params, fmt.Sprintf("%s_set_%s_%d", alias, key, len(params)))
 		params[paramName] = value
-		setParts = append(setParts, fmt.Sprintf("%s.%s = $%s", alias, key, paramName))
+		setParts = append(setParts, fmt.Sprintf("%s.%s = $%s", alias, safeName(key), paramName))
 	}
 	return "SET " + strings.Join(setParts, ", "), params
 }
 
+// buildProjection returns the items of a RETURN or WITH clause.
+func buildProjection(items []graph.Return) []string {
+	var clauses []string
+	for _, r := range items {
+		clause := r.Expression
+		if r.Aggregate != nil {
+			clause = aggregateExpression(r.Aggregate)
+		}
+		if r.Alias != "" {
+			clause += " AS " + r.Alias
+		}
+		clauses = append(clauses, clause)
+	}
+	return clauses
+}
+
+// aggregateExpression returns the Cypher function call computing a, e.g.
+// count(DISTINCT n.city). Cypher groups by the other returned items.
+func aggregateExpression(a *graph.Aggregation) string {
+	arg := a.Alias
+	if a.Property != "" {
+		arg += "." + safeName(a.Property)
+	}
+	if arg == "" {
+		arg = "*"
+	} else if a.Distinct {
+		arg = "DISTINCT " + arg
+	}
+	return string(a.Func) + "(" + arg + ")"
+}
+
+// isAggregateAlias reports whether alias names an aggregated item of the
+// RETURN or WITH clauses of query.
+func isAggregateAlias(query *graph.Query, alias string) bool {
+	items := query.Return
+	for _, stage := range query.With {
+		items = append(slices.Clip(items), stage.Items...)
+	}
+	for _, r := range items {
+		if r.Aggregate != nil && r.Alias == alias {
+			return true
+		}
+	}
+	return false
+}
+
+// orderExpression returns the expression sorted on by o in query.
+func orderExpression(query *graph.Query, o graph.Order) string {
+	if o.Property == "" {
+		if isAggregateAlias(query, o.Alias) {
+			return o.Alias
+		}
+		return "elementId(" + o.Alias + ")"
+	}
+	return o.Alias + "." + safeName(o.Property)
+}
+
+// buildAfterCondition builds the keyset condition selecting the rows sorting
+// after the values of query.After, compared in order with the first keys of
+// query.OrderBy: (k1 > v1) OR (k1 = v1 AND k2 > v2) OR ..., with < for
+// descending keys.
+func buildAfterCondition(query *graph.Query, params map[string]any) string {
+	orderBy, after := query.OrderBy, query.After
+	n := min(len(orderBy), len(after))
+	if n == 0 {
+		return ""
+	}
+
+	var equals, branches []string
+	for i := 0; i < n; i++ {
+		paramName := fmt.Sprintf("after_%d", i)
+		params[paramName] = after[i]
+		expr := orderExpression(query, orderBy[i])
+		op := " < "
+		if orderBy[i].Asc {
+			op = " > "
+		}
+		branch := append(slices.Clone(equals), expr+op+"$"+paramName)
+		branches = append(branches, "("+strings.Join(branch, " AND ")+")")
+		equals = append(equals, expr+" = $"+paramName)
+	}
+	return "(" + strings.Join(branches, " OR ") + ")"
+}
+
 // buildNodeMatchClause generates a Cypher MATCH clause for a node based on its selector.
 // It returns the MATCH clause string and the parameters map.
 func buildNodeMatchClause(alias string, selector *graph.NodeSelector) (string, map[string]any) {
@@ -1105,9 +1265,8 @@ func buildNodeMatchClause(alias string, selector *graph.NodeSelector) (string, m
 
 	// Add labels
 	for _, label := range selector.Labels {
-		sb.WriteString(":`")
-		sb.WriteString(label)
-		sb.WriteString("`")
+		sb.WriteString(":")
+		sb.WriteString(quoteName(label))
 	}
 
 	// Add properties
@@ -1116,9 +1275,9 @@ func buildNodeMatchClause(alias string, selector *graph.NodeSelector) (string, m
 		propStrings := make([]string, 0, len(selector.Properties))
 		params := make(map[string]any)
 		for key, value := range selector.Properties {
-			paramName := alias + "_" + key
+			paramName := uniqueParam(params, alias+"_"+key)
 			params[paramName] = value
-			propStrings = append(propStrings, key+": $"+paramName)
+			propStrings = append(propStrings, safeName(key)+": $"+paramName)
 		}
 		sb.WriteString(strings.Join(propStrings, ", "))
 		sb.WriteString("}")
@@ -1174,23 +1333,37 @@ func (c *neo4jClient) FindEdges(ctx context.Context, query *graph.Query) ([]*gra
 	return edges, nil
 }
 
-func (c *neo4jClient) Count(ctx context.Context, query *graph.Query) (int64, error) {
-	// Define the operation clause generator for COUNT
-	opClauseGenerator := func(aliasesInMatch []string) (string, map[string]any) {
-		// Heuristic: count the first alias in the MATCH clause.
-		if len(aliasesInMatch) > 0 {
-			return "RETURN count(" + aliasesInMatch[0] + ")", nil
-		}
-		// Fallback if no aliases are found
-		return "RETURN count(*)", nil
+func (c *neo4jClient) FindPaths(ctx context.Context, query *graph.Query) ([]*graph.Path, error) {
+	result, err := c.Query(ctx, query)
+	if err != nil {
+		return nil, err
 	}
 
-	cypher, params := buildCypherQueryForOperation(query, opClauseGenerator)
+	var paths []*graph.Path
+	for _, record := range result.Records {
+		for _, entity := range record {
+			switch v := entity.(type) {
+			case *graph.Path:
+				paths = append(paths, v)
+			case []any:
+				for _, item := range v {
+					if path, ok := item.(*graph.Path); ok {
+						paths = append(paths, path)
+					}
+				}
+			}
+		}
+	}
+	return paths, nil
+}
 
-	session := c.driver.NewSession(ctx, neo4j.SessionConfig{AccessMode: neo4j.AccessModeRead})
-	defer session.Close(ctx)
+func (c *neo4jClient) Count(ctx context.Context, query *graph.Query) (int64, error) {
+	if err := query.ValidateNames(); err != nil {
+		return 0, err
+	}
+	cypher, params := buildCountCypher(query)
 
-	count, err := session.ExecuteRead(ctx, func(tx neo4j.ManagedTransaction) (any, error) {
+	count, err := c.exec.read(ctx, func(tx runner) (any, error) {
 		res, err := tx.Run(ctx, cypher, params)
 		if err != nil {
 			return nil, err
@@ -1210,6 +1383,18 @@ func (c *neo4jClient) Count(ctx context.Context, query *graph.Query) (int64, err
 	return count.(int64), nil
 }
 
+// buildCountCypher counts the matches of the first alias of the query.
+func buildCountCypher(query *graph.Query) (string, map[string]any) {
+	return buildCypherQueryForOperation(query, func(aliasesInMatch []string) (string, map[string]any) {
+		// Heuristic: count the first alias in the MATCH clause.
+		if len(aliasesInMatch) > 0 {
+			return "RETURN count(" + aliasesInMatch[0] + ")", nil
+		}
+		// Fallback if no aliases are found
+		return "RETURN count(*)", nil
+	})
+}
+
 func toGraphNode(n neo4j.Node) *graph.Node {
 	return &graph.Node{
 		ID:         n.ElementId,
@@ -1234,6 +1419,8 @@ func toGraphEntity(entity any) graph.ResultEntity {
 		return toGraphNode(v)
 	case neo4j.Relationship:
 		return toGraphEdge(v)
+	case neo4j.Path:
+		return toGraphPath(v)
 	case []any:
 		return handleInterfaceSlice(v)
 	default:
@@ -1299,6 +1486,8 @@ func handleMixedTypes(slice []any) graph.ResultEntity {
 			result = append(result, toGraphNode(v))
 		case neo4j.Relationship:
 			result = append(result, toGraphEdge(v))
+		case neo4j.Path:
+			result = append(result, toGraphPath(v))
 		default:
 			result = append(result, v)
 		}
@@ -1318,47 +1507,50 @@ func (c *neo4jClient) NewBulkWriter() graph.BulkWriter {
 	}
 }
 
+// ImportGraph imports in batches of the bulk writer, see Import.
+func (c *neo4jClient) ImportGraph(ctx context.Context, nodes, edges io.Reader, opts ...graph.ImportOpt) (*graph.ImportResult, error) {
+	return graph.Import(ctx, c, nodes, edges, opts...)
+}
+
 func (b *bulkWriter) AddNode(ctx context.Context, node *graph.Node) error {
 	b.nodes = append(b.nodes, node)
 	return nil
 }
 
-// --- Graph Algorithms ---
-
-func (c *neo4jClient) ShortestPath(ctx context.Context, sourceNodeID, targetNodeID string, config map[string]any) ([]*graph.Path, error) {
-	// TODO: Implement with GDS or APOC
-	return nil, fmt.Errorf("not implemented")
-}
-
-func (c *neo4jClient) PageRank(ctx context.Context, config map[string]any) (map[string]float64, error) {
-	// TODO: Implement with GDS
-	return nil, fmt.Errorf("not implemented")
-}
-
-func (c *neo4jClient) ConnectedComponents(ctx context.Context, config map[string]any) (map[string]string, error) {
-	// TODO: Implement with GDS
-	return nil, fmt.Errorf("not implemented")
-}
-
-func (c *neo4jClient) BetweennessCentrality(ctx context.Context, config map[string]any) (map[string]float64, error) {
-	// TODO: Implement with GDS
-	return nil, fmt.Errorf("not implemented")
-}
-
 func (b *bulkWriter) AddEdge(ctx context.Context, edge *graph.Edge) error {
 	b.edges = append(b.edges, edge)
 	return nil
 }
 
+// Close creates the added nodes, then edges, in a single transaction.
 func (b *bulkWriter) Close(ctx context.Context) error {
-	// This should be a single transaction
-	if len(b.nodes) > 0 {
-		cypher := "UNWIND $nodes AS nodeProps CREATE (n) SET n = nodeProps"
-		params := map[string]any{"nodes": b.nodes} // needs conversion
-		if err := b.client.runCypher(ctx, cypher, params); err != nil {
-			return err
+	if len(b.nodes) == 0 && len(b.edges) == 0 {
+		return nil
+	}
+	var nodes []*graph.Node
+	var edges []*graph.Edge
+	_, err := b.client.exec.write(ctx, func(tx runner) (any, error) {
+		var err error
+		if len(b.nodes) > 0 {
+			if nodes, err = createNodes(ctx, tx, b.nodes); err != nil {
+				return nil, err
+			}
+		}
+		if len(b.edges) > 0 {
+			if edges, err = createEdges(ctx, tx, b.edges); err != nil {
+				return nil, err
+			}
 		}
+		return nil, nil
+	})
+	if err != nil {
+		return err
+	}
+	for i, node := range nodes {
+		b.nodes[i].ID = node.ID
+	}
+	for i, edge := range edges {
+		b.edges[i].ID = edge.ID
 	}
-	// Similar UNWIND for edges
 	return nil
 }
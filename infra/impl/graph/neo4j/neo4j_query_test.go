@@ -1,11 +1,14 @@
 package neo4j
 
 import (
+	"context"
+	"errors"
 	"reflect"
 	"strings"
 	"testing"
 
 	"github.com/me2seeks/forge/infra/contract/graph"
+	"github.com/neo4j/neo4j-go-driver/v5/neo4j"
 )
 
 // TestBuildCypherQuery_Basic tests a basic MATCH and RETURN query.
@@ -200,6 +203,33 @@ func TestBuildCypherQuery_PathAliasAndExpression(t *testing.T) {
 	}
 }
 
+// TestToGraphEntity_Path tests that paths, alone or in lists, are converted.
+func TestToGraphEntity_Path(t *testing.T) {
+	a := neo4j.Node{ElementId: "4:abc:1", Labels: []string{"User"}, Props: map[string]any{"name": "Bob"}}
+	b := neo4j.Node{ElementId: "4:abc:2", Labels: []string{"Product"}, Props: map[string]any{}}
+	r := neo4j.Relationship{ElementId: "5:abc:1", StartElementId: a.ElementId, EndElementId: b.ElementId, Type: "PURCHASED", Props: map[string]any{}}
+	p := neo4j.Path{Nodes: []neo4j.Node{a, b}, Relationships: []neo4j.Relationship{r}}
+
+	path, ok := toGraphEntity(p).(*graph.Path)
+	if !ok {
+		t.Fatalf("Expected a *graph.Path, got %T", toGraphEntity(p))
+	}
+	if len(path.Nodes) != 2 || path.Nodes[0].ID != a.ElementId || path.Nodes[1].ID != b.ElementId {
+		t.Errorf("Unexpected path nodes: %v", path.Nodes)
+	}
+	if len(path.Edges) != 1 || path.Edges[0].ID != r.ElementId || path.Edges[0].Label != "PURCHASED" {
+		t.Errorf("Unexpected path edges: %v", path.Edges)
+	}
+
+	list, ok := toGraphEntity([]any{p, p}).([]any)
+	if !ok || len(list) != 2 {
+		t.Fatalf("Expected a list of 2 paths, got %v", toGraphEntity([]any{p, p}))
+	}
+	if _, ok := list[0].(*graph.Path); !ok {
+		t.Errorf("Expected a *graph.Path, got %T", list[0])
+	}
+}
+
 func intPtr(i int) *int {
 	return &i
 }
@@ -231,3 +261,589 @@ func TestBuildCypherQuery_ReturnExpression(t *testing.T) {
 		t.Errorf("Params should be empty for this query, got: %v", params)
 	}
 }
+
+// TestBuildCypherQuery_Aggregate tests aggregations grouped by the other returned items.
+func TestBuildCypherQuery_Aggregate(t *testing.T) {
+	query := &graph.Query{
+		Match: []graph.Pattern{
+			{
+				Alias:  "p",
+				Labels: []string{"Person"},
+				Edge: &graph.EdgePattern{
+					Labels:    []string{"LIVES_IN"},
+					Direction: graph.DirectionOutgoing,
+					Node:      &graph.Pattern{Alias: "c", Labels: []string{"City"}},
+				},
+			},
+		},
+		Return: []graph.Return{
+			{Expression: "c.name", Alias: "city"},
+			{Alias: "people", Aggregate: &graph.Aggregation{Func: graph.AggregateCount}},
+			{Alias: "avg_age", Aggregate: &graph.Aggregation{Func: graph.AggregateAvg, Alias: "p", Property: "age"}},
+			{Alias: "names", Aggregate: &graph.Aggregation{Func: graph.AggregateCollect, Alias: "p", Property: "name", Distinct: true}},
+		},
+		OrderBy: []graph.Order{{Alias: "people"}, {Alias: "c", Property: "name", Asc: true}},
+		Limit:   intPtr(10),
+	}
+
+	expectedCypher := "MATCH (p:`Person`)-[r:LIVES_IN]->(c:`City`) " +
+		"RETURN c.name AS city, count(*) AS people, avg(p.age) AS avg_age, collect(DISTINCT p.name) AS names " +
+		"ORDER BY people DESC, c.name ASC LIMIT $limit"
+	cypher, params := buildCypherQuery(query)
+
+	if cypher != expectedCypher {
+		t.Errorf("Cypher mismatch.\nGot:  %s\nWant: %s", cypher, expectedCypher)
+	}
+	if !reflect.DeepEqual(params, map[string]any{"limit": 10}) {
+		t.Errorf("Unexpected params: %v", params)
+	}
+}
+
+// TestBuildCypherQuery_With tests filtering on an aggregate computed by a stage.
+func TestBuildCypherQuery_With(t *testing.T) {
+	query := &graph.Query{
+		Match: []graph.Pattern{
+			{
+				Alias:  "p",
+				Labels: []string{"Person"},
+				Edge: &graph.EdgePattern{
+					Alias:     "k",
+					Labels:    []string{"KNOWS"},
+					Direction: graph.DirectionBoth,
+					Node:      &graph.Pattern{Alias: "f"},
+				},
+			},
+		},
+		Where: &graph.Where{Filter: []graph.Condition{{Alias: "p", Property: "active", Operator: graph.OpEqual, Value: true}}},
+		With: []graph.Stage{{
+			Items: []graph.Return{
+				{Expression: "p"},
+				{Alias: "friends", Aggregate: &graph.Aggregation{Func: graph.AggregateCount, Alias: "f", Distinct: true}},
+			},
+			Where: &graph.Where{Filter: []graph.Condition{{Alias: "friends", Operator: graph.OpGreaterThan, Value: 10}}},
+		}},
+		Return:  []graph.Return{{Expression: "p"}, {Expression: "friends"}},
+		OrderBy: []graph.Order{{Alias: "friends"}, {Alias: "p", Asc: true}},
+		After:   []any{12, "4:abc:7"},
+	}
+
+	expectedCypher := "MATCH (p:`Person`)-[k:KNOWS]-(f) WHERE p.active = $p_active " +
+		"WITH p, count(DISTINCT f) AS friends WHERE friends > $friends AND ((friends < $after_0) OR (friends = $after_0 AND elementId(p) > $after_1)) " +
+		"RETURN p, friends ORDER BY friends DESC, elementId(p) ASC"
+	expectedParams := map[string]any{"p_active": true, "friends": 10, "after_0": 12, "after_1": "4:abc:7"}
+	cypher, params := buildCypherQuery(query)
+
+	if cypher != expectedCypher {
+		t.Errorf("Cypher mismatch.\nGot:  %s\nWant: %s", cypher, expectedCypher)
+	}
+	if !reflect.DeepEqual(params, expectedParams) {
+		t.Errorf("Params mismatch.\nGot:  %v\nWant: %v", params, expectedParams)
+	}
+}
+
+// TestBuildMergeNodeCypher tests the MERGE built for an upsert.
+func TestBuildMergeNodeCypher(t *testing.T) {
+	node := &graph.Node{
+		Labels:     []string{"Person", "Employee"},
+		Properties: graph.Properties{"email": "alice@example.com", "name": "Alice"},
+	}
+
+	cypher, params, err := buildMergeNodeCypher(node, []string{"email"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	expectedCypher := "MERGE (n:`Person`:`Employee` {`email`: $match_0}) ON CREATE SET n = $props ON MATCH SET n += $props RETURN n"
+	if cypher != expectedCypher {
+		t.Errorf("Cypher mismatch.\nGot:  %s\nWant: %s", cypher, expectedCypher)
+	}
+	expectedParams := map[string]any{"match_0": "alice@example.com", "props": node.Properties}
+	if !reflect.DeepEqual(params, expectedParams) {
+		t.Errorf("Params mismatch.\nGot:  %v\nWant: %v", params, expectedParams)
+	}
+
+	if _, _, err := buildMergeNodeCypher(node, []string{"phone"}); err == nil {
+		t.Error("Expected an error for a match key missing from the properties")
+	}
+	if _, _, err := buildMergeNodeCypher(node, nil); err == nil {
+		t.Error("Expected an error without match keys")
+	}
+}
+
+// TestBuildMergeEdgeCypher tests the MERGE built for an edge between an ID and a selector.
+func TestBuildMergeEdgeCypher(t *testing.T) {
+	edge := &graph.Edge{
+		Label:              "WORKS_AT",
+		SourceNodeID:       "4:abc:1",
+		TargetNodeSelector: &graph.NodeSelector{Labels: []string{"Company"}, Properties: graph.Properties{"name": "Acme"}},
+		Properties:         graph.Properties{"role": "engineer", "dept": "r&d"},
+	}
+	onCreate := graph.Properties{"since": 2024}
+
+	cypher, params, err := buildMergeEdgeCypher(edge, onCreate, nil)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	expectedCypher := "MATCH (a) WHERE elementId(a) = $a_id MATCH (b:`Company` {name: $b_name}) " +
+		"MERGE (a)-[r:`WORKS_AT` {`dept`: $merge_0, `role`: $merge_1}]->(b) ON CREATE SET r += $onCreate RETURN r"
+	if cypher != expectedCypher {
+		t.Errorf("Cypher mismatch.\nGot:  %s\nWant: %s", cypher, expectedCypher)
+	}
+	expectedParams := map[string]any{
+		"a_id":     "4:abc:1",
+		"b_name":   "Acme",
+		"merge_0":  "r&d",
+		"merge_1":  "engineer",
+		"onCreate": onCreate,
+	}
+	if !reflect.DeepEqual(params, expectedParams) {
+		t.Errorf("Params mismatch.\nGot:  %v\nWant: %v", params, expectedParams)
+	}
+
+	if _, _, err := buildMergeEdgeCypher(&graph.Edge{Label: "KNOWS", SourceNodeID: "4:abc:1"}, nil, nil); err == nil {
+		t.Error("Expected an error for an edge without target")
+	}
+}
+
+// TestBuildByQueryCypher tests the target and count of the ByQuery operations.
+func TestBuildByQueryCypher(t *testing.T) {
+	query := &graph.Query{Match: []graph.Pattern{{Alias: "p", Labels: []string{"Person"}, Edge: &graph.EdgePattern{Alias: "k", Node: &graph.Pattern{}}}}}
+
+	cypher, params := buildUpdateByQueryCypher(query, true, graph.Properties{"weight": 1})
+	expectedCypher := "MATCH (p:`Person`)-[k]->(m) SET k.weight = $k_set_weight_0 RETURN count(k)"
+	if cypher != expectedCypher {
+		t.Errorf("Cypher mismatch.\nGot:  %s\nWant: %s", cypher, expectedCypher)
+	}
+	if !reflect.DeepEqual(params, map[string]any{"k_set_weight_0": 1}) {
+		t.Errorf("Unexpected params: %v", params)
+	}
+
+	cypher, _ = buildDeleteByQueryCypher(query, false)
+	if expectedCypher := "MATCH (p:`Person`)-[k]->(m) DETACH DELETE p RETURN count(p)"; cypher != expectedCypher {
+		t.Errorf("Cypher mismatch.\nGot:  %s\nWant: %s", cypher, expectedCypher)
+	}
+}
+
+func TestBuildCreateEdgeCypher(t *testing.T) {
+	cypher, params := buildCreateEdgeCypher(&graph.Edge{Label: "KNOWS", SourceNodeID: "4:abc:1", TargetNodeID: "4:abc:2"})
+	expectedCypher := "MATCH (a), (b) WHERE elementId(a) = $sourceId AND elementId(b) = $targetId CREATE (a)-[r:`KNOWS` $props]->(b) RETURN r"
+	if cypher != expectedCypher {
+		t.Errorf("Cypher mismatch.\nGot:  %s\nWant: %s", cypher, expectedCypher)
+	}
+	expectedParams := map[string]any{"sourceId": "4:abc:1", "targetId": "4:abc:2", "props": graph.Properties{}}
+	if !reflect.DeepEqual(params, expectedParams) {
+		t.Errorf("Params mismatch.\nGot:  %v\nWant: %v", params, expectedParams)
+	}
+}
+
+// TestBuildCreateNodesCypher tests that nodes are grouped by label set, keeping their index.
+func TestBuildCreateNodesCypher(t *testing.T) {
+	nodes := []*graph.Node{
+		{Labels: []string{"Person"}, Properties: graph.Properties{"name": "Alice"}},
+		{Labels: []string{"Company"}, Properties: graph.Properties{"name": "Acme"}},
+		{Labels: []string{"Person"}},
+	}
+
+	stmts := buildCreateNodesCypher(nodes)
+	if len(stmts) != 2 {
+		t.Fatalf("Statements length mismatch. Got %d, want 2", len(stmts))
+	}
+
+	expectedCypher := "UNWIND $rows AS row CREATE (n:`Person`) SET n = row.props RETURN row.i AS i, n"
+	if stmts[0].cypher != expectedCypher {
+		t.Errorf("Cypher mismatch.\nGot:  %s\nWant: %s", stmts[0].cypher, expectedCypher)
+	}
+	expectedRows := []map[string]any{
+		{"i": 0, "props": graph.Properties{"name": "Alice"}},
+		{"i": 2, "props": graph.Properties{}},
+	}
+	if !reflect.DeepEqual(stmts[0].rows, expectedRows) {
+		t.Errorf("Rows mismatch.\nGot:  %v\nWant: %v", stmts[0].rows, expectedRows)
+	}
+	if !strings.Contains(stmts[1].cypher, "CREATE (n:`Company`)") {
+		t.Errorf("Unexpected Cypher for the second group: %s", stmts[1].cypher)
+	}
+}
+
+// TestBuildCreateEdgesCypher tests that edges are grouped by type and reject selectors.
+func TestBuildCreateEdgesCypher(t *testing.T) {
+	edges := []*graph.Edge{
+		{Label: "KNOWS", SourceNodeID: "1", TargetNodeID: "2"},
+		{Label: "KNOWS", SourceNodeID: "2", TargetNodeID: "3", Properties: graph.Properties{"since": 2020}},
+	}
+
+	stmts, err := buildCreateEdgesCypher(edges)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(stmts) != 1 || len(stmts[0].rows) != 2 {
+		t.Fatalf("Expected a single statement of 2 rows, got %v", stmts)
+	}
+	expectedCypher := "UNWIND $rows AS row MATCH (a) WHERE elementId(a) = row.source MATCH (b) WHERE elementId(b) = row.target " +
+		"CREATE (a)-[r:`KNOWS`]->(b) SET r = row.props RETURN row.i AS i, r"
+	if stmts[0].cypher != expectedCypher {
+		t.Errorf("Cypher mismatch.\nGot:  %s\nWant: %s", stmts[0].cypher, expectedCypher)
+	}
+
+	_, err = buildCreateEdgesCypher([]*graph.Edge{{Label: "KNOWS", SourceNodeSelector: &graph.NodeSelector{}}})
+	if err == nil {
+		t.Error("Expected an error for an edge with selectors")
+	}
+}
+
+// TestBuildBatchQueryCypher tests that the statement runs on each row.
+func TestBuildBatchQueryCypher(t *testing.T) {
+	rows := []map[string]any{{"key": "a"}, {"key": "b"}}
+	cypher, params, err := buildBatchQueryCypher("MERGE (n:`Account` {key: row.key}) SET n.source = $source", rows, map[string]any{"source": "import"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	expectedCypher := "UNWIND $rows AS row MERGE (n:`Account` {key: row.key}) SET n.source = $source"
+	if cypher != expectedCypher {
+		t.Errorf("Cypher mismatch.\nGot:  %s\nWant: %s", cypher, expectedCypher)
+	}
+	if !reflect.DeepEqual(params, map[string]any{"rows": rows, "source": "import"}) {
+		t.Errorf("Unexpected params: %v", params)
+	}
+
+	if _, _, err := buildBatchQueryCypher("RETURN row", rows, map[string]any{"rows": 1}); err == nil {
+		t.Error("Expected an error for a parameter named rows")
+	}
+}
+
+// TestBuildCypherQuery_After tests the keyset condition of a query sorted on a property and the ID.
+func TestBuildCypherQuery_After(t *testing.T) {
+	query := &graph.Query{
+		Match:   []graph.Pattern{{Alias: "n", Labels: []string{"Person"}}},
+		Return:  []graph.Return{{Expression: "n"}},
+		OrderBy: []graph.Order{{Alias: "n", Property: "age", Asc: false}, {Alias: "n", Asc: true}},
+		After:   []any{30, "4:abc:7"},
+	}
+
+	expectedCypher := "MATCH (n:`Person`) WHERE ((n.age < $after_0) OR (n.age = $after_0 AND elementId(n) > $after_1)) " +
+		"RETURN n ORDER BY n.age DESC, elementId(n) ASC"
+	expectedParams := map[string]any{"after_0": 30, "after_1": "4:abc:7"}
+
+	cypher, params := buildCypherQuery(query)
+
+	if cypher != expectedCypher {
+		t.Errorf("Cypher mismatch.\nGot:  %s\nWant: %s", cypher, expectedCypher)
+	}
+	if !reflect.DeepEqual(params, expectedParams) {
+		t.Errorf("Params mismatch.\nGot:  %v\nWant: %v", params, expectedParams)
+	}
+}
+
+// TestBuildShortestPathCypher tests the path pattern built from the config.
+func TestBuildShortestPathCypher(t *testing.T) {
+	cypher, params := buildShortestPathCypher("1", "2", nil)
+	expectedCypher := "MATCH (s), (t) WHERE elementId(s) = $source AND elementId(t) = $target MATCH p = shortestPath((s)-[*]-(t)) RETURN p"
+	if cypher != expectedCypher {
+		t.Errorf("Cypher mismatch.\nGot:  %s\nWant: %s", cypher, expectedCypher)
+	}
+	if !reflect.DeepEqual(params, map[string]any{"source": "1", "target": "2"}) {
+		t.Errorf("Unexpected params: %v", params)
+	}
+
+	cypher, _ = buildShortestPathCypher("1", "2", map[string]any{
+		"all":               true,
+		"relationshipTypes": []any{"KNOWS", "WORKS_WITH"},
+		"direction":         "outgoing",
+		"maxDepth":          5,
+	})
+	expectedCypher = "MATCH (s), (t) WHERE elementId(s) = $source AND elementId(t) = $target MATCH p = allShortestPaths((s)-[:`KNOWS`|`WORKS_WITH`*..5]->(t)) RETURN p"
+	if cypher != expectedCypher {
+		t.Errorf("Cypher mismatch.\nGot:  %s\nWant: %s", cypher, expectedCypher)
+	}
+}
+
+// TestSplitGDSConfig tests that projection keys are taken out of an algorithm config.
+func TestSplitGDSConfig(t *testing.T) {
+	config := map[string]any{
+		"nodeLabels":                 []string{"Page"},
+		"relationshipTypes":          []any{"LINKS"},
+		"relationshipWeightProperty": "weight",
+		"dampingFactor":              0.85,
+	}
+
+	p, algo := splitGDSConfig(config, "NATURAL")
+
+	expectedAlgo := map[string]any{"relationshipWeightProperty": "weight", "dampingFactor": 0.85}
+	if !reflect.DeepEqual(algo, expectedAlgo) {
+		t.Errorf("Algorithm config mismatch.\nGot:  %v\nWant: %v", algo, expectedAlgo)
+	}
+	nodes, rels := p.params()
+	if !reflect.DeepEqual(nodes, []string{"Page"}) {
+		t.Errorf("Unexpected node projection: %v", nodes)
+	}
+	expectedRels := map[string]any{"LINKS": map[string]any{
+		"type":        "LINKS",
+		"orientation": "NATURAL",
+		"properties":  map[string]any{"weight": map[string]any{"property": "weight", "defaultValue": 1.0}},
+	}}
+	if !reflect.DeepEqual(rels, expectedRels) {
+		t.Errorf("Relationship projection mismatch.\nGot:  %v\nWant: %v", rels, expectedRels)
+	}
+	if len(config) != 4 {
+		t.Error("The caller's config must not be modified")
+	}
+}
+
+// TestBuildLinkPredictionCypher tests the scoring of explicit candidate pairs.
+func TestBuildLinkPredictionCypher(t *testing.T) {
+	cypher, params, err := buildLinkPredictionCypher("adamicAdar", map[string]any{
+		"sourceNodeIds":     []string{"1"},
+		"targetNodeIds":     []any{"2", "3"},
+		"relationshipTypes": []string{"KNOWS"},
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	expectedCypher := "MATCH (s) WHERE elementId(s) IN $sources " +
+		"MATCH (t) WHERE elementId(t) IN $targets AND t <> s " +
+		"OPTIONAL MATCH (s)-[:`KNOWS`]-(m)-[:`KNOWS`]-(t) " +
+		"WITH s, t, collect(DISTINCT m) AS common " +
+		"RETURN elementId(s) AS source, elementId(t) AS target, " +
+		"reduce(acc = 0.0, m IN common | acc + 1.0 / log(COUNT { MATCH (m)-[:`KNOWS`]-(x) RETURN DISTINCT x })) AS score " +
+		"ORDER BY score DESC LIMIT $topK"
+	if cypher != expectedCypher {
+		t.Errorf("Cypher mismatch.\nGot:  %s\nWant: %s", cypher, expectedCypher)
+	}
+	expectedParams := map[string]any{"sources": []string{"1"}, "targets": []string{"2", "3"}, "topK": 10}
+	if !reflect.DeepEqual(params, expectedParams) {
+		t.Errorf("Params mismatch.\nGot:  %v\nWant: %v", params, expectedParams)
+	}
+
+	if _, _, err := buildLinkPredictionCypher("magic", map[string]any{"sourceNodeIds": []string{"1"}}); err == nil {
+		t.Error("Expected an error for an unknown algorithm")
+	}
+}
+
+// TestBuildNeighborsCypher tests the expansion of GetNeighbors, of a single
+// edge by default.
+func TestBuildNeighborsCypher(t *testing.T) {
+	cypher, params := buildNeighborsCypher("1", graph.NewNeighborOptions())
+	expectedCypher := "MATCH (n) WHERE elementId(n) = $id " +
+		"MATCH p = (n)-[]-(m) WHERE elementId(m) <> elementId(n) " +
+		"UNWIND relationships(p) AS r RETURN collect(DISTINCT m) AS nodes, collect(DISTINCT r) AS edges"
+	if cypher != expectedCypher {
+		t.Errorf("Cypher mismatch.\nGot:  %s\nWant: %s", cypher, expectedCypher)
+	}
+	if !reflect.DeepEqual(params, map[string]any{"id": "1"}) {
+		t.Errorf("Unexpected params: %v", params)
+	}
+
+	cypher, _ = buildNeighborsCypher("1", graph.NewNeighborOptions(
+		graph.WithDirection(graph.DirectionIncoming), graph.WithRelationshipTypes("KNOWS", "LIKES"), graph.WithDepth(3)))
+	expectedCypher = "MATCH (n) WHERE elementId(n) = $id " +
+		"MATCH p = (n)<-[:`KNOWS`|`LIKES`*1..3]-(m) WHERE elementId(m) <> elementId(n) " +
+		"UNWIND relationships(p) AS r RETURN collect(DISTINCT m) AS nodes, collect(DISTINCT r) AS edges"
+	if cypher != expectedCypher {
+		t.Errorf("Cypher mismatch.\nGot:  %s\nWant: %s", cypher, expectedCypher)
+	}
+}
+
+// TestBuildSubgraphCypher tests the loading of the subgraph of Traverse, whose
+// edges are matched once each whatever the direction of the traversal.
+func TestBuildSubgraphCypher(t *testing.T) {
+	spec := graph.TraversalSpec{RelationshipTypes: []string{"KNOWS"}, MaxDepth: 2}
+	cypher, params := buildSubgraphCypher("1", spec.NeighborOptions())
+	expectedCypher := "MATCH (n) WHERE elementId(n) = $id " +
+		"OPTIONAL MATCH (n)-[:`KNOWS`*1..2]-(m) " +
+		"WITH n, [n] + collect(DISTINCT m) AS nodes " +
+		"UNWIND nodes AS a " +
+		"OPTIONAL MATCH (a)-[r:`KNOWS`]->(b) WHERE b IN nodes " +
+		"RETURN nodes, collect(DISTINCT r) AS edges"
+	if cypher != expectedCypher {
+		t.Errorf("Cypher mismatch.\nGot:  %s\nWant: %s", cypher, expectedCypher)
+	}
+	if !reflect.DeepEqual(params, map[string]any{"id": "1"}) {
+		t.Errorf("Unexpected params: %v", params)
+	}
+}
+
+// TestBuildCreateFullTextIndexCypher tests the full-text index DDL.
+func TestBuildCreateFullTextIndexCypher(t *testing.T) {
+	cypher, err := buildCreateFullTextIndexCypher("docs", []string{"Article", "Note"}, []string{"title", "body"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	expectedCypher := "CREATE FULLTEXT INDEX `docs` IF NOT EXISTS FOR (n:`Article`|`Note`) ON EACH [n.`title`, n.`body`]"
+	if cypher != expectedCypher {
+		t.Errorf("Cypher mismatch.\nGot:  %s\nWant: %s", cypher, expectedCypher)
+	}
+
+	if _, err := buildCreateFullTextIndexCypher("docs", nil, []string{"title"}); err == nil {
+		t.Error("Expected an error without labels")
+	}
+}
+
+// TestBuildCypherQuery_QuotedNames tests that names are quoted, their
+// backticks doubled, and that their parameters are renamed.
+func TestBuildCypherQuery_QuotedNames(t *testing.T) {
+	query := &graph.Query{
+		Match: []graph.Pattern{{
+			Alias:      "n",
+			Labels:     []string{"Per`son"},
+			Properties: graph.Properties{"first name": "Ada"},
+			Edge: &graph.EdgePattern{
+				Labels: []string{"KNOWS", "WORKS`WITH"},
+				Node:   &graph.Pattern{Alias: "m"},
+			},
+		}},
+		Where: &graph.Where{
+			Filter: []graph.Condition{{Alias: "n", Property: "last-name", Operator: graph.OpEqual, Value: "Lovelace"}},
+		},
+		Return: []graph.Return{{Expression: "n"}},
+	}
+	cypher, params := buildCypherQuery(query)
+	expectedCypher := "MATCH (n:`Per``son` {`first name`: $n_first_name})-[r:KNOWS|`WORKS``WITH`]->(m) WHERE n.`last-name` = $n_last_name RETURN n"
+	if cypher != expectedCypher {
+		t.Errorf("Cypher mismatch.\nGot:  %s\nWant: %s", cypher, expectedCypher)
+	}
+	expectedParams := map[string]any{"n_first_name": "Ada", "n_last_name": "Lovelace"}
+	if !reflect.DeepEqual(params, expectedParams) {
+		t.Errorf("Unexpected params: %v", params)
+	}
+}
+
+// TestInvalidNames tests that names with control characters are rejected.
+func TestInvalidNames(t *testing.T) {
+	node := &graph.Node{Labels: []string{"Person\n"}, Properties: graph.Properties{"name": "Ada"}}
+	if _, _, err := buildMergeNodeCypher(node, []string{"name"}); !errors.Is(err, graph.ErrInvalidName) {
+		t.Errorf("Expected an invalid name error, got %v", err)
+	}
+	edges := []*graph.Edge{{Label: "", SourceNodeID: "1", TargetNodeID: "2"}}
+	if _, err := buildCreateEdgesCypher(edges); !errors.Is(err, graph.ErrInvalidName) {
+		t.Errorf("Expected an invalid name error, got %v", err)
+	}
+	query := &graph.Query{Match: []graph.Pattern{{Alias: "n", Properties: graph.Properties{"na\x00me": "Ada"}}}}
+	if err := query.ValidateNames(); !errors.Is(err, graph.ErrInvalidName) {
+		t.Errorf("Expected an invalid name error, got %v", err)
+	}
+}
+
+// TestToConstraintType tests the mapping of the constraint types of SHOW CONSTRAINTS.
+func TestToConstraintType(t *testing.T) {
+	cases := map[string]graph.ConstraintType{
+		"UNIQUENESS":                      graph.ConstraintUnique,
+		"RELATIONSHIP_UNIQUENESS":         graph.ConstraintUnique,
+		"NODE_PROPERTY_EXISTENCE":         graph.ConstraintExists,
+		"RELATIONSHIP_PROPERTY_EXISTENCE": graph.ConstraintExists,
+		"NODE_KEY":                        graph.ConstraintNodeKey,
+		"RELATIONSHIP_KEY":                "RELATIONSHIP_KEY",
+	}
+	for typ, want := range cases {
+		if got := toConstraintType(typ, ""); got != want {
+			t.Errorf("toConstraintType(%s) = %s, want %s", typ, got, want)
+		}
+	}
+	if got, want := toConstraintType("NODE_PROPERTY_TYPE", "STRING"), graph.PropertyTypeConstraint("string"); got != want {
+		t.Errorf("toConstraintType(NODE_PROPERTY_TYPE) = %s, want %s", got, want)
+	}
+}
+
+// TestBuildConstraintCypher tests the constraint syntax of each server version.
+func TestBuildConstraintCypher(t *testing.T) {
+	cases := []struct {
+		constraintType graph.ConstraintType
+		version        serverVersion
+		want           string
+	}{
+		{graph.ConstraintUnique, serverVersion{3, 5}, "CREATE CONSTRAINT ON (n:`Person`) ASSERT n.`email` IS UNIQUE"},
+		{graph.ConstraintExists, serverVersion{4, 3}, "CREATE CONSTRAINT ON (n:`Person`) ASSERT exists(n.`email`)"},
+		{graph.ConstraintUnique, serverVersion{4, 4}, "CREATE CONSTRAINT IF NOT EXISTS FOR (n:`Person`) REQUIRE n.`email` IS UNIQUE"},
+		{graph.ConstraintExists, serverVersion{5, 13}, "CREATE CONSTRAINT IF NOT EXISTS FOR (n:`Person`) REQUIRE n.`email` IS NOT NULL"},
+		{graph.ConstraintNodeKey, serverVersion{5, 13}, "CREATE CONSTRAINT IF NOT EXISTS FOR (n:`Person`) REQUIRE (n.`email`) IS NODE KEY"},
+		{graph.PropertyTypeConstraint("string"), serverVersion{2025, 1}, "CREATE CONSTRAINT IF NOT EXISTS FOR (n:`Person`) REQUIRE n.`email` IS :: STRING"},
+	}
+	for _, c := range cases {
+		cypher, err := buildCreateConstraintCypher("Person", "email", c.constraintType, c.version)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if cypher != c.want {
+			t.Errorf("Cypher mismatch for %s on %s.\nGot:  %s\nWant: %s", c.constraintType, c.version, cypher, c.want)
+		}
+	}
+
+	if _, err := buildCreateConstraintCypher("Person", "email", graph.PropertyTypeConstraint("STRING"), serverVersion{5, 8}); err == nil {
+		t.Error("Expected an error for a property type constraint before Neo4j 5.9")
+	}
+	if _, err := buildCreateConstraintCypher("Person", "email", "CHECK", serverVersion{5, 13}); err == nil {
+		t.Error("Expected an error for an unsupported constraint type")
+	}
+
+	cypher, err := buildDropConstraintCypher("Person", "email", graph.ConstraintExists)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if want := "DROP CONSTRAINT ON (n:`Person`) ASSERT exists(n.`email`)"; cypher != want {
+		t.Errorf("Cypher mismatch.\nGot:  %s\nWant: %s", cypher, want)
+	}
+}
+
+func TestParseServerVersion(t *testing.T) {
+	cases := map[string]serverVersion{
+		"Neo4j/3.5.35":            {3, 5},
+		"Neo4j/4.4.26-enterprise": {4, 4},
+		"Neo4j/5.13.0":            {5, 13},
+		"Neo4j/5.0-aura":          {5, 0},
+		"Neo4j/2025.01.0":         {2025, 1},
+	}
+	for agent, want := range cases {
+		got, err := parseServerVersion(agent)
+		if err != nil || got != want {
+			t.Errorf("parseServerVersion(%s) = %v, %v, want %v", agent, got, err, want)
+		}
+	}
+	if _, err := parseServerVersion("Memgraph"); err == nil {
+		t.Error("Expected an error for an agent without version")
+	}
+}
+
+// TestSessionConfig tests that the database of the context overrides the client's.
+func TestSessionConfig(t *testing.T) {
+	ctx := context.Background()
+
+	config := sessionConfig(ctx, "main", neo4j.AccessModeRead)
+	if config.DatabaseName != "main" || config.AccessMode != neo4j.AccessModeRead {
+		t.Errorf("Unexpected session config: %+v", config)
+	}
+
+	config = sessionConfig(graph.WithDatabase(ctx, "tenant1"), "main", neo4j.AccessModeWrite)
+	if config.DatabaseName != "tenant1" || config.AccessMode != neo4j.AccessModeWrite {
+		t.Errorf("Unexpected session config: %+v", config)
+	}
+}
+
+// TestSessionConfigRouting tests that the access mode and bookmarks of the
+// context configure sessions.
+func TestSessionConfigRouting(t *testing.T) {
+	ctx := graph.WithAccessMode(context.Background(), graph.AccessModeRead)
+	config := sessionConfig(ctx, "", neo4j.AccessModeWrite)
+	if config.AccessMode != neo4j.AccessModeRead || config.Bookmarks != nil {
+		t.Errorf("Unexpected session config: %+v", config)
+	}
+
+	ctx = graph.WithAccessMode(context.Background(), graph.AccessModeWrite)
+	ctx = graph.WithBookmarks(ctx, graph.NewBookmarks("bm1", "bm2"))
+	config = sessionConfig(ctx, "", neo4j.AccessModeRead)
+	if config.AccessMode != neo4j.AccessModeWrite {
+		t.Errorf("Unexpected access mode: %v", config.AccessMode)
+	}
+	if got := neo4j.BookmarksToRawValues(config.Bookmarks); !reflect.DeepEqual(got, []string{"bm1", "bm2"}) {
+		t.Errorf("Unexpected bookmarks: %v", got)
+	}
+}
+
+func TestBookmarksUpdate(t *testing.T) {
+	b := graph.NewBookmarks("bm1", "bm2")
+	b.Update([]string{"bm1"}, []string{"bm3", "bm2"})
+	if got := b.Values(); !reflect.DeepEqual(got, []string{"bm2", "bm3"}) {
+		t.Errorf("Unexpected bookmarks: %v", got)
+	}
+}
@@ -2,6 +2,7 @@ package neo4j
 
 import (
 	"context"
+	"errors"
 	"os"
 	"testing"
 
@@ -226,7 +227,34 @@ func TestQueryConvenienceMethods(t *testing.T) {
 	require.Equal(t, "PURCHASED", edges[0].Label)
 	require.EqualValues(t, 2024, edges[0].Properties["year"])
 
-	// 4. Test Count
+	// 4. Test FindPaths
+	findPathsQuery := &graph.Query{
+		Match: []graph.Pattern{
+			{
+				PathAlias: "path",
+				Alias:     "u",
+				Labels:    []string{"User"},
+				Edge: &graph.EdgePattern{
+					Labels:    []string{"PURCHASED"},
+					Direction: graph.DirectionOutgoing,
+					Node:      &graph.Pattern{Alias: "pr", Labels: []string{"Product"}},
+				},
+			},
+		},
+		Return: []graph.Return{
+			{Expression: "path"},
+		},
+	}
+	paths, err := client.FindPaths(ctx, findPathsQuery)
+	require.NoError(t, err)
+	require.Len(t, paths, 1)
+	require.Len(t, paths[0].Nodes, 2)
+	require.Equal(t, user1.ID, paths[0].Nodes[0].ID)
+	require.Equal(t, product.ID, paths[0].Nodes[1].ID)
+	require.Len(t, paths[0].Edges, 1)
+	require.Equal(t, "PURCHASED", paths[0].Edges[0].Label)
+
+	// 5. Test Count
 	count, err := client.Count(ctx, findNodesQuery)
 	require.NoError(t, err)
 	require.Equal(t, int64(2), count)
@@ -449,3 +477,511 @@ func TestQueryWithMustExpr(t *testing.T) {
 	require.Len(t, nodes, 1)
 	require.Equal(t, "Alice", nodes[0].Properties["name"])
 }
+
+func TestTransaction(t *testing.T) {
+	client, teardown := setup(t)
+	defer teardown()
+
+	ctx := context.Background()
+
+	// 1. A rolled back transaction leaves nothing behind
+	var createdID string
+	err := graph.RunInTx(ctx, client, func(ctx context.Context, tx graph.Tx) error {
+		node, err := tx.CreateNode(ctx, &graph.Node{Labels: []string{"TxUser"}, Properties: graph.Properties{"name": "Alice"}})
+		if err != nil {
+			return err
+		}
+		createdID = node.ID
+
+		// The transaction sees its own writes
+		got, err := tx.GetNode(ctx, node.ID)
+		require.NoError(t, err)
+		require.NotNil(t, got)
+		return errors.New("abort")
+	})
+	require.EqualError(t, err, "abort")
+
+	node, err := client.GetNode(ctx, createdID)
+	require.NoError(t, err)
+	require.Nil(t, node)
+
+	// 2. A committed transaction applies all its writes
+	tx, err := client.BeginTx(ctx)
+	require.NoError(t, err)
+	source, err := tx.CreateNode(ctx, &graph.Node{Labels: []string{"TxUser"}, Properties: graph.Properties{"name": "Bob"}})
+	require.NoError(t, err)
+	target, err := tx.CreateNode(ctx, &graph.Node{Labels: []string{"TxUser"}, Properties: graph.Properties{"name": "Carol"}})
+	require.NoError(t, err)
+	_, err = tx.CreateEdge(ctx, &graph.Edge{Label: "KNOWS", SourceNodeID: source.ID, TargetNodeID: target.ID})
+	require.NoError(t, err)
+	require.NoError(t, tx.Commit(ctx))
+
+	_, err = tx.GetNode(ctx, source.ID)
+	require.ErrorIs(t, err, graph.ErrTxDone)
+	require.ErrorIs(t, tx.Rollback(ctx), graph.ErrTxDone)
+
+	count, err := client.Count(ctx, &graph.Query{Match: []graph.Pattern{{Alias: "n", Labels: []string{"TxUser"}}}})
+	require.NoError(t, err)
+	require.Equal(t, int64(2), count)
+}
+
+func TestMergeNode(t *testing.T) {
+	client, teardown := setup(t)
+	defer teardown()
+
+	ctx := context.Background()
+
+	// 1. The first merge creates the node
+	created, err := client.MergeNode(ctx, &graph.Node{
+		Labels:     []string{"MergeUser"},
+		Properties: graph.Properties{"email": "alice@example.com", "name": "Alice", "age": int64(30)},
+	}, []string{"email"})
+	require.NoError(t, err)
+	require.Equal(t, "Alice", created.Properties["name"])
+
+	// 2. The second one updates it, keeping the properties it doesn't set
+	merged, err := client.MergeNode(ctx, &graph.Node{
+		Labels:     []string{"MergeUser"},
+		Properties: graph.Properties{"email": "alice@example.com", "name": "Alice Smith"},
+	}, []string{"email"})
+	require.NoError(t, err)
+	require.Equal(t, created.ID, merged.ID)
+	require.Equal(t, "Alice Smith", merged.Properties["name"])
+	require.Equal(t, int64(30), merged.Properties["age"])
+
+	count, err := client.Count(ctx, &graph.Query{Match: []graph.Pattern{{Alias: "n", Labels: []string{"MergeUser"}}}})
+	require.NoError(t, err)
+	require.Equal(t, int64(1), count)
+}
+
+func TestMergeEdge(t *testing.T) {
+	client, teardown := setup(t)
+	defer teardown()
+
+	ctx := context.Background()
+
+	person, err := client.CreateNode(ctx, &graph.Node{Labels: []string{"MergePerson"}, Properties: graph.Properties{"name": "Alice"}})
+	require.NoError(t, err)
+	_, err = client.CreateNode(ctx, &graph.Node{Labels: []string{"MergeCompany"}, Properties: graph.Properties{"name": "Acme"}})
+	require.NoError(t, err)
+
+	edge := &graph.Edge{
+		Label:              "WORKS_AT",
+		SourceNodeID:       person.ID,
+		TargetNodeSelector: &graph.NodeSelector{Labels: []string{"MergeCompany"}, Properties: graph.Properties{"name": "Acme"}},
+		Properties:         graph.Properties{"role": "engineer"},
+	}
+
+	// 1. The first merge creates the edge
+	created, err := client.MergeEdge(ctx, edge, graph.Properties{"created": true}, graph.Properties{"matched": true})
+	require.NoError(t, err)
+	require.NotNil(t, created)
+	require.Equal(t, true, created.Properties["created"])
+	require.Nil(t, created.Properties["matched"])
+
+	// 2. The second one matches it
+	merged, err := client.MergeEdge(ctx, edge, graph.Properties{"created": false}, graph.Properties{"matched": true})
+	require.NoError(t, err)
+	require.Equal(t, created.ID, merged.ID)
+	require.Equal(t, true, merged.Properties["created"])
+	require.Equal(t, true, merged.Properties["matched"])
+
+	// 3. A missing endpoint merges nothing
+	missing, err := client.MergeEdge(ctx, &graph.Edge{
+		Label:              "WORKS_AT",
+		SourceNodeID:       person.ID,
+		TargetNodeSelector: &graph.NodeSelector{Labels: []string{"MergeCompany"}, Properties: graph.Properties{"name": "Globex"}},
+	}, nil, nil)
+	require.NoError(t, err)
+	require.Nil(t, missing)
+}
+
+func TestBatchCreate(t *testing.T) {
+	client, teardown := setup(t)
+	defer teardown()
+
+	ctx := context.Background()
+
+	// 1. Nodes of several label sets come back in order
+	nodes, err := client.CreateNodes(ctx, []*graph.Node{
+		{Labels: []string{"BatchPerson"}, Properties: graph.Properties{"name": "Alice"}},
+		{Labels: []string{"BatchCompany"}, Properties: graph.Properties{"name": "Acme"}},
+		{Labels: []string{"BatchPerson"}, Properties: graph.Properties{"name": "Bob"}},
+	})
+	require.NoError(t, err)
+	require.Len(t, nodes, 3)
+	require.Equal(t, "Alice", nodes[0].Properties["name"])
+	require.Equal(t, []string{"BatchCompany"}, nodes[1].Labels)
+	require.Equal(t, "Bob", nodes[2].Properties["name"])
+
+	// 2. Edges too
+	edges, err := client.CreateEdges(ctx, []*graph.Edge{
+		{Label: "WORKS_AT", SourceNodeID: nodes[0].ID, TargetNodeID: nodes[1].ID},
+		{Label: "KNOWS", SourceNodeID: nodes[0].ID, TargetNodeID: nodes[2].ID, Properties: graph.Properties{"since": int64(2020)}},
+	})
+	require.NoError(t, err)
+	require.Len(t, edges, 2)
+	require.Equal(t, "WORKS_AT", edges[0].Label)
+	require.Equal(t, int64(2020), edges[1].Properties["since"])
+
+	// 3. A missing endpoint creates none of the batch
+	_, err = client.CreateEdges(ctx, []*graph.Edge{
+		{Label: "KNOWS", SourceNodeID: nodes[1].ID, TargetNodeID: nodes[2].ID},
+		{Label: "KNOWS", SourceNodeID: nodes[0].ID, TargetNodeID: "missing"},
+	})
+	require.Error(t, err)
+
+	count, err := client.Count(ctx, &graph.Query{
+		Match: []graph.Pattern{{
+			Alias:  "a",
+			Labels: []string{"BatchPerson"},
+			Edge:   &graph.EdgePattern{Alias: "r", Labels: []string{"KNOWS"}, Direction: graph.DirectionOutgoing, Node: &graph.Pattern{Alias: "b"}},
+		}},
+		Return: []graph.Return{{Expression: "r"}},
+	})
+	require.NoError(t, err)
+	require.Equal(t, int64(1), count)
+}
+
+func TestBatchQuery(t *testing.T) {
+	client, teardown := setup(t)
+	defer teardown()
+
+	ctx := context.Background()
+	accounts := []map[string]any{{"key": "acc-1"}, {"key": "acc-2"}, {"key": "acc-3"}}
+	_, err := client.BatchQuery(ctx, "MERGE (:Account {key: row.key})", accounts, nil)
+	require.NoError(t, err)
+
+	transfers := []map[string]any{
+		{"from": "acc-1", "to": "acc-2", "amount": 10},
+		{"from": "acc-2", "to": "acc-3", "amount": 20},
+	}
+	upsert := "MATCH (a:Account {key: row.from}), (b:Account {key: row.to}) " +
+		"MERGE (a)-[t:TRANSFERRED]->(b) SET t.amount = row.amount, t.currency = $currency RETURN t"
+	res, err := client.BatchQuery(ctx, upsert, transfers, map[string]any{"currency": "EUR"})
+	require.NoError(t, err)
+	require.Len(t, res.Records, 2)
+
+	// Running it again updates the edges instead of adding more.
+	transfers[0]["amount"] = 15
+	_, err = client.BatchQuery(ctx, upsert, transfers, map[string]any{"currency": "EUR"})
+	require.NoError(t, err)
+	edges, err := client.FindEdges(ctx, &graph.Query{
+		Match:  []graph.Pattern{{Alias: "a", Labels: []string{"Account"}, Edge: &graph.EdgePattern{Alias: "t", Labels: []string{"TRANSFERRED"}, Direction: graph.DirectionOutgoing, Node: &graph.Pattern{Alias: "b"}}}},
+		Return: []graph.Return{{Expression: "t"}},
+	})
+	require.NoError(t, err)
+	require.Len(t, edges, 2)
+	amounts := []any{edges[0].Properties["amount"], edges[1].Properties["amount"]}
+	require.ElementsMatch(t, []any{int64(15), int64(20)}, amounts)
+}
+
+func TestQueryStream(t *testing.T) {
+	client, teardown := setup(t)
+	defer teardown()
+
+	ctx := context.Background()
+
+	nodes := make([]*graph.Node, 100)
+	for i := range nodes {
+		nodes[i] = &graph.Node{Labels: []string{"StreamItem"}, Properties: graph.Properties{"i": int64(i)}}
+	}
+	_, err := client.CreateNodes(ctx, nodes)
+	require.NoError(t, err)
+
+	it, err := client.QueryStream(ctx, &graph.Query{
+		Match:   []graph.Pattern{{Alias: "n", Labels: []string{"StreamItem"}}},
+		Return:  []graph.Return{{Expression: "n"}},
+		OrderBy: []graph.Order{{Alias: "n", Property: "i", Asc: true}},
+	})
+	require.NoError(t, err)
+	var seen int64
+	for it.Next(ctx) {
+		node := it.Record()["n"].(*graph.Node)
+		require.Equal(t, seen, node.Properties["i"])
+		seen++
+	}
+	require.NoError(t, it.Err())
+	require.NoError(t, it.Close(ctx))
+	require.Equal(t, int64(100), seen)
+
+	// Closing early discards the rest
+	it, err = client.QueryStream(ctx, &graph.Query{
+		Match:  []graph.Pattern{{Alias: "n", Labels: []string{"StreamItem"}}},
+		Return: []graph.Return{{Expression: "n"}},
+	})
+	require.NoError(t, err)
+	require.True(t, it.Next(ctx))
+	require.NoError(t, it.Close(ctx))
+	require.False(t, it.Next(ctx))
+}
+
+func TestShortestPath(t *testing.T) {
+	client, teardown := setup(t)
+	defer teardown()
+
+	ctx := context.Background()
+
+	// a -> b -> c -> d, plus a shortcut a -> c
+	nodes, err := client.CreateNodes(ctx, []*graph.Node{
+		{Labels: []string{"PathStop"}, Properties: graph.Properties{"name": "a"}},
+		{Labels: []string{"PathStop"}, Properties: graph.Properties{"name": "b"}},
+		{Labels: []string{"PathStop"}, Properties: graph.Properties{"name": "c"}},
+		{Labels: []string{"PathStop"}, Properties: graph.Properties{"name": "d"}},
+	})
+	require.NoError(t, err)
+	_, err = client.CreateEdges(ctx, []*graph.Edge{
+		{Label: "ROAD", SourceNodeID: nodes[0].ID, TargetNodeID: nodes[1].ID},
+		{Label: "ROAD", SourceNodeID: nodes[1].ID, TargetNodeID: nodes[2].ID},
+		{Label: "ROAD", SourceNodeID: nodes[2].ID, TargetNodeID: nodes[3].ID},
+		{Label: "ROAD", SourceNodeID: nodes[0].ID, TargetNodeID: nodes[2].ID},
+	})
+	require.NoError(t, err)
+
+	paths, err := client.ShortestPath(ctx, nodes[0].ID, nodes[3].ID, map[string]any{"relationshipTypes": []string{"ROAD"}, "direction": "OUTGOING"})
+	require.NoError(t, err)
+	require.Len(t, paths, 1)
+	require.Len(t, paths[0].Nodes, 3)
+	require.Len(t, paths[0].Edges, 2)
+	require.Equal(t, nodes[0].ID, paths[0].Nodes[0].ID)
+	require.Equal(t, nodes[3].ID, paths[0].Nodes[2].ID)
+
+	// No path against the direction
+	paths, err = client.ShortestPath(ctx, nodes[3].ID, nodes[0].ID, map[string]any{"direction": "OUTGOING"})
+	require.NoError(t, err)
+	require.Empty(t, paths)
+}
+
+func TestPageRank(t *testing.T) {
+	client, teardown := setup(t)
+	defer teardown()
+
+	ctx := context.Background()
+
+	nodes, err := client.CreateNodes(ctx, []*graph.Node{
+		{Labels: []string{"RankPage"}, Properties: graph.Properties{"name": "hub"}},
+		{Labels: []string{"RankPage"}, Properties: graph.Properties{"name": "a"}},
+		{Labels: []string{"RankPage"}, Properties: graph.Properties{"name": "b"}},
+	})
+	require.NoError(t, err)
+	_, err = client.CreateEdges(ctx, []*graph.Edge{
+		{Label: "LINKS", SourceNodeID: nodes[1].ID, TargetNodeID: nodes[0].ID},
+		{Label: "LINKS", SourceNodeID: nodes[2].ID, TargetNodeID: nodes[0].ID},
+	})
+	require.NoError(t, err)
+
+	scores, err := client.PageRank(ctx, map[string]any{"nodeLabels": []string{"RankPage"}, "relationshipTypes": []string{"LINKS"}, "maxIterations": 20})
+	if errors.Is(err, ErrGDSUnavailable) {
+		t.Skip("GDS is not installed")
+	}
+	require.NoError(t, err)
+	require.Len(t, scores, 3)
+	require.Greater(t, scores[nodes[0].ID], scores[nodes[1].ID])
+}
+
+func TestConnectedComponents(t *testing.T) {
+	client, teardown := setup(t)
+	defer teardown()
+
+	ctx := context.Background()
+
+	nodes, err := client.CreateNodes(ctx, []*graph.Node{
+		{Labels: []string{"Island"}, Properties: graph.Properties{"name": "a"}},
+		{Labels: []string{"Island"}, Properties: graph.Properties{"name": "b"}},
+		{Labels: []string{"Island"}, Properties: graph.Properties{"name": "c"}},
+	})
+	require.NoError(t, err)
+	_, err = client.CreateEdge(ctx, &graph.Edge{Label: "BRIDGE", SourceNodeID: nodes[0].ID, TargetNodeID: nodes[1].ID})
+	require.NoError(t, err)
+
+	// Either through GDS or the fallback
+	components, err := client.ConnectedComponents(ctx, map[string]any{"nodeLabels": []string{"Island"}})
+	require.NoError(t, err)
+	require.Len(t, components, 3)
+	require.Equal(t, components[nodes[0].ID], components[nodes[1].ID])
+	require.NotEqual(t, components[nodes[0].ID], components[nodes[2].ID])
+}
+
+func TestBetweennessCentrality(t *testing.T) {
+	client, teardown := setup(t)
+	defer teardown()
+
+	ctx := context.Background()
+
+	// a - b - c: every path between a and c goes through b
+	nodes, err := client.CreateNodes(ctx, []*graph.Node{
+		{Labels: []string{"Relay"}, Properties: graph.Properties{"name": "a"}},
+		{Labels: []string{"Relay"}, Properties: graph.Properties{"name": "b"}},
+		{Labels: []string{"Relay"}, Properties: graph.Properties{"name": "c"}},
+	})
+	require.NoError(t, err)
+	_, err = client.CreateEdges(ctx, []*graph.Edge{
+		{Label: "WIRE", SourceNodeID: nodes[0].ID, TargetNodeID: nodes[1].ID},
+		{Label: "WIRE", SourceNodeID: nodes[1].ID, TargetNodeID: nodes[2].ID},
+	})
+	require.NoError(t, err)
+
+	scores, err := client.BetweennessCentrality(ctx, map[string]any{
+		"nodeLabels":        []string{"Relay"},
+		"relationshipTypes": []string{"WIRE"},
+		"orientation":       "UNDIRECTED",
+	})
+	if errors.Is(err, ErrGDSUnavailable) {
+		t.Skip("GDS is not installed")
+	}
+	require.NoError(t, err)
+	require.Greater(t, scores[nodes[1].ID], scores[nodes[0].ID])
+	require.Zero(t, scores[nodes[2].ID])
+}
+
+func TestNeo4jExtensions(t *testing.T) {
+	client, teardown := setup(t)
+	defer teardown()
+
+	ctx := context.Background()
+
+	ext, ok := graph.AsNeo4jExtensions(client)
+	require.True(t, ok)
+
+	// a and c share the neighbor b
+	nodes, err := client.CreateNodes(ctx, []*graph.Node{
+		{Labels: []string{"Friend"}, Properties: graph.Properties{"name": "a"}},
+		{Labels: []string{"Friend"}, Properties: graph.Properties{"name": "b"}},
+		{Labels: []string{"Friend"}, Properties: graph.Properties{"name": "c"}},
+	})
+	require.NoError(t, err)
+	_, err = client.CreateEdges(ctx, []*graph.Edge{
+		{Label: "FRIEND", SourceNodeID: nodes[0].ID, TargetNodeID: nodes[1].ID},
+		{Label: "FRIEND", SourceNodeID: nodes[1].ID, TargetNodeID: nodes[2].ID},
+	})
+	require.NoError(t, err)
+
+	links, err := ext.LinkPrediction(ctx, "commonNeighbors", map[string]any{"sourceNodeIds": []string{nodes[0].ID}, "relationshipTypes": []string{"FRIEND"}})
+	require.NoError(t, err)
+	require.Len(t, links, 1)
+	require.Equal(t, nodes[2].ID, links[0].TargetNodeID)
+	require.Equal(t, 1.0, links[0].Score)
+
+	scores, err := ext.Centrality(ctx, "degree", map[string]any{"nodeLabels": []string{"Friend"}, "orientation": "UNDIRECTED"})
+	if errors.Is(err, ErrGDSUnavailable) {
+		t.Skip("GDS is not installed")
+	}
+	require.NoError(t, err)
+	require.Equal(t, 2.0, scores[nodes[1].ID])
+}
+
+func TestFullTextSearch(t *testing.T) {
+	client, teardown := setup(t)
+	defer teardown()
+
+	ctx := context.Background()
+
+	require.NoError(t, client.CreateFullTextIndex(ctx, "article_text", []string{"Article"}, []string{"title", "body"}))
+	defer func() {
+		require.NoError(t, client.DropFullTextIndex(ctx, "article_text"))
+	}()
+	// Creating it again is a no-op
+	require.NoError(t, client.CreateFullTextIndex(ctx, "article_text", []string{"Article"}, []string{"title", "body"}))
+
+	_, err := client.CreateNodes(ctx, []*graph.Node{
+		{Labels: []string{"Article"}, Properties: graph.Properties{"title": "Graph databases", "body": "Nodes and relationships"}},
+		{Labels: []string{"Article"}, Properties: graph.Properties{"title": "Cooking", "body": "Graph paper recipes"}},
+		{Labels: []string{"Article"}, Properties: graph.Properties{"title": "Gardening", "body": "Roses"}},
+	})
+	require.NoError(t, err)
+
+	// Index population is asynchronous
+	require.NoError(t, client.(*neo4jClient).runCypher(ctx, "CALL db.awaitIndexes(60)", nil))
+
+	results, err := client.FullTextSearch(ctx, "article_text", "graph", 10)
+	require.NoError(t, err)
+	require.Len(t, results, 2)
+	require.Equal(t, "Graph databases", results[0].Node.Properties["title"])
+	require.GreaterOrEqual(t, results[0].Score, results[1].Score)
+
+	results, err = client.FullTextSearch(ctx, "article_text", "graph", 1)
+	require.NoError(t, err)
+	require.Len(t, results, 1)
+}
+
+func TestSchemaIntrospection(t *testing.T) {
+	client, teardown := setup(t)
+	defer teardown()
+
+	ctx := context.Background()
+
+	nodes, err := client.CreateNodes(ctx, []*graph.Node{
+		{Labels: []string{"SchemaA"}, Properties: graph.Properties{"name": "a"}},
+		{Labels: []string{"SchemaB"}, Properties: graph.Properties{"name": "b"}},
+	})
+	require.NoError(t, err)
+	_, err = client.CreateEdge(ctx, &graph.Edge{Label: "SCHEMA_REL", SourceNodeID: nodes[0].ID, TargetNodeID: nodes[1].ID})
+	require.NoError(t, err)
+
+	labels, err := client.ListLabels(ctx)
+	require.NoError(t, err)
+	require.Contains(t, labels, "SchemaA")
+	require.Contains(t, labels, "SchemaB")
+
+	types, err := client.ListRelationshipTypes(ctx)
+	require.NoError(t, err)
+	require.Contains(t, types, "SCHEMA_REL")
+
+	require.NoError(t, client.CreateFullTextIndex(ctx, "schema_text", []string{"SchemaA"}, []string{"name"}))
+	defer func() {
+		require.NoError(t, client.DropFullTextIndex(ctx, "schema_text"))
+	}()
+	indexes, err := client.ListIndexes(ctx)
+	require.NoError(t, err)
+	var found *graph.IndexInfo
+	for _, index := range indexes {
+		if index.Name == "schema_text" {
+			found = index
+		}
+	}
+	require.NotNil(t, found)
+	require.Equal(t, "FULLTEXT", found.Type)
+	require.Equal(t, graph.EntityNode, found.EntityType)
+	require.Equal(t, []string{"SchemaA"}, found.LabelsOrTypes)
+	require.Equal(t, []string{"name"}, found.Properties)
+
+	_, err = client.ListConstraints(ctx)
+	require.NoError(t, err)
+}
+
+func TestDatabaseSelection(t *testing.T) {
+	client, teardown := setup(t)
+	defer teardown()
+
+	ctx := context.Background()
+
+	_, err := client.GetNode(graph.WithDatabase(ctx, "missing-db"), "4:abc:0")
+	require.Error(t, err)
+
+	_, err = client.ListLabels(graph.WithDatabase(ctx, "neo4j"))
+	require.NoError(t, err)
+}
+
+func TestAccessModeAndBookmarks(t *testing.T) {
+	client, teardown := setup(t)
+	defer teardown()
+
+	bookmarks := graph.NewBookmarks()
+	ctx := graph.WithBookmarks(context.Background(), bookmarks)
+
+	node, err := client.CreateNode(ctx, &graph.Node{Labels: []string{"Person"}, Properties: graph.Properties{"name": "Alice"}})
+	require.NoError(t, err)
+	require.NotEmpty(t, bookmarks.Values())
+
+	// A read routed to any member after the bookmarks sees the write.
+	got, err := client.GetNode(graph.WithAccessMode(ctx, graph.AccessModeRead), node.ID)
+	require.NoError(t, err)
+	require.Equal(t, "Alice", got.Properties["name"])
+
+	// A read-only transaction can't write.
+	err = graph.RunInTx(graph.WithAccessMode(ctx, graph.AccessModeRead), client, func(ctx context.Context, tx graph.Tx) error {
+		_, err := tx.CreateNode(ctx, &graph.Node{Labels: []string{"Person"}})
+		return err
+	})
+	require.Error(t, err)
+}
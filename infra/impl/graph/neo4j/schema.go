@@ -0,0 +1,246 @@
+package neo4j
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/me2seeks/forge/infra/contract/graph"
+	"github.com/neo4j/neo4j-go-driver/v5/neo4j"
+)
+
+func (c *neo4jClient) ListLabels(ctx context.Context) ([]string, error) {
+	return c.listStrings(ctx, "CALL db.labels() YIELD label RETURN label AS value ORDER BY value")
+}
+
+func (c *neo4jClient) ListRelationshipTypes(ctx context.Context) ([]string, error) {
+	return c.listStrings(ctx, "CALL db.relationshipTypes() YIELD relationshipType RETURN relationshipType AS value ORDER BY value")
+}
+
+func (c *neo4jClient) listStrings(ctx context.Context, cypher string) ([]string, error) {
+	result, err := c.exec.read(ctx, func(tx runner) (any, error) {
+		res, err := tx.Run(ctx, cypher, nil)
+		if err != nil {
+			return nil, err
+		}
+		var values []string
+		for res.Next(ctx) {
+			value, _ := res.Record().Get("value")
+			values = append(values, value.(string))
+		}
+		return values, res.Err()
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result.([]string), nil
+}
+
+func (c *neo4jClient) ListIndexes(ctx context.Context) ([]*graph.IndexInfo, error) {
+	cypher := "SHOW INDEXES YIELD name, type, entityType, labelsOrTypes, properties, state RETURN * ORDER BY name"
+	result, err := c.exec.read(ctx, func(tx runner) (any, error) {
+		res, err := tx.Run(ctx, cypher, nil)
+		if err != nil {
+			return nil, err
+		}
+		var indexes []*graph.IndexInfo
+		for res.Next(ctx) {
+			record := res.Record()
+			indexes = append(indexes, &graph.IndexInfo{
+				Name:          recordString(record, "name"),
+				Type:          recordString(record, "type"),
+				EntityType:    graph.EntityType(recordString(record, "entityType")),
+				LabelsOrTypes: recordStrings(record, "labelsOrTypes"),
+				Properties:    recordStrings(record, "properties"),
+				State:         recordString(record, "state"),
+			})
+		}
+		return indexes, res.Err()
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result.([]*graph.IndexInfo), nil
+}
+
+// ListConstraints returns the constraints of SHOW CONSTRAINTS, yielding all
+// the columns, as propertyType only exists since Neo4j 5.9.
+func (c *neo4jClient) ListConstraints(ctx context.Context) ([]*graph.ConstraintInfo, error) {
+	cypher := "SHOW CONSTRAINTS YIELD * RETURN * ORDER BY name"
+	result, err := c.exec.read(ctx, func(tx runner) (any, error) {
+		res, err := tx.Run(ctx, cypher, nil)
+		if err != nil {
+			return nil, err
+		}
+		var constraints []*graph.ConstraintInfo
+		for res.Next(ctx) {
+			record := res.Record()
+			constraints = append(constraints, &graph.ConstraintInfo{
+				Name:          recordString(record, "name"),
+				Type:          toConstraintType(recordString(record, "type"), recordString(record, "propertyType")),
+				EntityType:    graph.EntityType(recordString(record, "entityType")),
+				LabelsOrTypes: recordStrings(record, "labelsOrTypes"),
+				Properties:    recordStrings(record, "properties"),
+			})
+		}
+		return constraints, res.Err()
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result.([]*graph.ConstraintInfo), nil
+}
+
+// toConstraintType maps the constraint types of SHOW CONSTRAINTS, e.g.
+// UNIQUENESS or NODE_PROPERTY_EXISTENCE, to the contract's, propertyType being
+// the type required by property type constraints.
+func toConstraintType(typ, propertyType string) graph.ConstraintType {
+	switch {
+	case strings.HasSuffix(typ, "UNIQUENESS"):
+		return graph.ConstraintUnique
+	case strings.HasSuffix(typ, "PROPERTY_EXISTENCE"):
+		return graph.ConstraintExists
+	case strings.HasSuffix(typ, "PROPERTY_TYPE"):
+		return graph.PropertyTypeConstraint(propertyType)
+	case typ == "NODE_KEY":
+		return graph.ConstraintNodeKey
+	default:
+		return graph.ConstraintType(typ)
+	}
+}
+
+// buildCreateConstraintCypher returns the Cypher creating the constraint on
+// the nodes of label in the syntax of version.
+func buildCreateConstraintCypher(label, property string, constraintType graph.ConstraintType, version serverVersion) (string, error) {
+	requirement, err := constraintRequirement(property, constraintType, version)
+	if err != nil {
+		return "", err
+	}
+	if !version.atLeast(4, 4) {
+		return "CREATE CONSTRAINT ON (n:" + quoteName(label) + ") ASSERT " + requirement, nil
+	}
+	return "CREATE CONSTRAINT IF NOT EXISTS FOR (n:" + quoteName(label) + ") REQUIRE " + requirement, nil
+}
+
+// buildDropConstraintCypher returns the Cypher dropping the constraint on the
+// nodes of label before Neo4j 4.4, which drops constraints by name.
+func buildDropConstraintCypher(label, property string, constraintType graph.ConstraintType) (string, error) {
+	requirement, err := constraintRequirement(property, constraintType, serverVersion{})
+	if err != nil {
+		return "", err
+	}
+	return "DROP CONSTRAINT ON (n:" + quoteName(label) + ") ASSERT " + requirement, nil
+}
+
+// constraintRequirement returns what the constraint requires of the property
+// of the nodes n in the syntax of version.
+func constraintRequirement(property string, constraintType graph.ConstraintType, version serverVersion) (string, error) {
+	prop := "n." + quoteName(property)
+	switch constraintType {
+	case graph.ConstraintUnique:
+		return prop + " IS UNIQUE", nil
+	case graph.ConstraintExists:
+		if !version.atLeast(4, 4) {
+			return "exists(" + prop + ")", nil
+		}
+		return prop + " IS NOT NULL", nil
+	case graph.ConstraintNodeKey:
+		return "(" + prop + ") IS NODE KEY", nil
+	}
+	if typ, ok := constraintType.PropertyType(); ok {
+		if !version.atLeast(5, 9) {
+			return "", fmt.Errorf("property type constraints need Neo4j 5.9, the server runs %s", version)
+		}
+		if typ == "" {
+			return "", fmt.Errorf("property type constraint on %s without type", property)
+		}
+		return prop + " IS :: " + typ, nil
+	}
+	return "", fmt.Errorf("unsupported constraint type %s", constraintType)
+}
+
+// serverVersion is the major and minor version of a Neo4j server, e.g. 5.13,
+// or 2025.1 since Neo4j versions by date.
+type serverVersion struct {
+	major, minor int
+}
+
+func (v serverVersion) atLeast(major, minor int) bool {
+	return v.major > major || v.major == major && v.minor >= minor
+}
+
+func (v serverVersion) String() string {
+	return fmt.Sprintf("%d.%d", v.major, v.minor)
+}
+
+// parseServerVersion parses the agent the server reports, e.g. Neo4j/5.13.0
+// or Neo4j/4.4.26-enterprise.
+func parseServerVersion(agent string) (serverVersion, error) {
+	_, version, _ := strings.Cut(agent, "/")
+	parts := strings.SplitN(version, ".", 3)
+	major, err := strconv.Atoi(parts[0])
+	if err != nil || len(parts) < 2 {
+		return serverVersion{}, fmt.Errorf("unexpected server agent %q", agent)
+	}
+	// The minor version may be followed by a suffix, e.g. 5.0-aura.
+	digits := strings.IndexFunc(parts[1], func(r rune) bool { return r < '0' || r > '9' })
+	if digits < 0 {
+		digits = len(parts[1])
+	}
+	minor, err := strconv.Atoi(parts[1][:digits])
+	if err != nil {
+		return serverVersion{}, fmt.Errorf("unexpected server agent %q", agent)
+	}
+	return serverVersion{major: major, minor: minor}, nil
+}
+
+// versionCache holds the version of the server once detected, shared by the
+// client and its transactions.
+type versionCache struct {
+	mu      sync.Mutex
+	version *serverVersion
+}
+
+// serverVersion returns the version of the server, asked to the server on
+// first use. A failed detection is retried on the next call.
+func (c *neo4jClient) serverVersion(ctx context.Context) (serverVersion, error) {
+	c.version.mu.Lock()
+	defer c.version.mu.Unlock()
+	if c.version.version != nil {
+		return *c.version.version, nil
+	}
+	info, err := c.driver.GetServerInfo(ctx)
+	if err != nil {
+		return serverVersion{}, fmt.Errorf("detect server version: %w", err)
+	}
+	version, err := parseServerVersion(info.Agent())
+	if err != nil {
+		return serverVersion{}, fmt.Errorf("detect server version: %w", err)
+	}
+	c.version.version = &version
+	return version, nil
+}
+
+func recordString(record *neo4j.Record, key string) string {
+	v, _ := record.Get(key)
+	s, _ := v.(string)
+	return s
+}
+
+// recordStrings returns the list of strings under key, nil if it is null.
+func recordStrings(record *neo4j.Record, key string) []string {
+	v, _ := record.Get(key)
+	items, _ := v.([]any)
+	if items == nil {
+		return nil
+	}
+	values := make([]string, 0, len(items))
+	for _, item := range items {
+		if s, ok := item.(string); ok {
+			values = append(values, s)
+		}
+	}
+	return values
+}
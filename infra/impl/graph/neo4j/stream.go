@@ -0,0 +1,103 @@
+package neo4j
+
+import (
+	"context"
+	"errors"
+	"io"
+
+	"github.com/me2seeks/forge/infra/contract/graph"
+	"github.com/neo4j/neo4j-go-driver/v5/neo4j"
+)
+
+func (c *neo4jClient) QueryStream(ctx context.Context, query *graph.Query) (graph.RecordIterator, error) {
+	if err := query.ValidateNames(); err != nil {
+		return nil, err
+	}
+	cypher, params := buildCypherQuery(query)
+	return c.exec.stream(ctx, cypher, params)
+}
+
+// ExportGraph writes the entities of the records of QueryStream.
+func (c *neo4jClient) ExportGraph(ctx context.Context, w io.Writer, format graph.ExportFormat, query *graph.Query) error {
+	return graph.Export(ctx, c, w, format, query)
+}
+
+// stream runs cypher in an explicit read transaction of a new session, both
+// kept open until the iterator is closed. Unlike managed transactions, it
+// isn't retried, as records may already have been consumed.
+func (e *sessionExecutor) stream(ctx context.Context, cypher string, params map[string]any) (graph.RecordIterator, error) {
+	session, closeSession := newSession(ctx, e.driver, e.database, neo4j.AccessModeRead)
+	tx, err := session.BeginTransaction(ctx)
+	if err != nil {
+		_ = closeSession(ctx)
+		return nil, err
+	}
+	res, err := tx.Run(ctx, cypher, params)
+	if err != nil {
+		_ = tx.Close(ctx)
+		_ = closeSession(ctx)
+		return nil, err
+	}
+	return &recordIterator{
+		res: res,
+		close: func(ctx context.Context) error {
+			// The transaction only read, commit just ends it.
+			return errors.Join(tx.Commit(ctx), tx.Close(ctx), closeSession(ctx))
+		},
+	}, nil
+}
+
+// stream runs cypher in the transaction, which ends the iteration when it ends.
+func (e *txExecutor) stream(ctx context.Context, cypher string, params map[string]any) (graph.RecordIterator, error) {
+	res, err := e.run(ctx, func(tx runner) (any, error) {
+		return tx.Run(ctx, cypher, params)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &recordIterator{res: res.(neo4j.ResultWithContext)}, nil
+}
+
+// recordIterator converts the records of a driver result as they are fetched.
+type recordIterator struct {
+	res    neo4j.ResultWithContext
+	record graph.Record
+	close  func(ctx context.Context) error
+	closed bool
+}
+
+func (it *recordIterator) Next(ctx context.Context) bool {
+	if it.closed || !it.res.Next(ctx) {
+		it.record = nil
+		return false
+	}
+	it.record = toGraphRecord(it.res.Record())
+	return true
+}
+
+func (it *recordIterator) Record() graph.Record {
+	return it.record
+}
+
+func (it *recordIterator) Err() error {
+	return it.res.Err()
+}
+
+func (it *recordIterator) Close(ctx context.Context) error {
+	if it.closed {
+		return nil
+	}
+	it.closed = true
+	if it.close == nil {
+		return nil
+	}
+	return it.close(ctx)
+}
+
+func toGraphRecord(record *neo4j.Record) graph.Record {
+	rec := make(graph.Record, len(record.Keys))
+	for i, key := range record.Keys {
+		rec[key] = toGraphEntity(record.Values[i])
+	}
+	return rec
+}
@@ -0,0 +1,165 @@
+package neo4j
+
+import (
+	"context"
+	"errors"
+	"sync"
+
+	"github.com/me2seeks/forge/infra/contract/graph"
+	"github.com/neo4j/neo4j-go-driver/v5/neo4j"
+)
+
+// runner runs Cypher, in a managed or an explicit transaction.
+type runner interface {
+	Run(ctx context.Context, cypher string, params map[string]any) (neo4j.ResultWithContext, error)
+}
+
+// executor runs units of work, each in its own transaction or all in the same one.
+type executor interface {
+	read(ctx context.Context, work func(tx runner) (any, error)) (any, error)
+	write(ctx context.Context, work func(tx runner) (any, error)) (any, error)
+	// stream runs a read query whose records are fetched as they are iterated.
+	stream(ctx context.Context, cypher string, params map[string]any) (graph.RecordIterator, error)
+}
+
+// sessionExecutor runs each unit of work in a managed transaction of a new
+// session, retried by the driver on transient errors.
+type sessionExecutor struct {
+	driver   neo4j.DriverWithContext
+	database string
+}
+
+// sessionConfig returns the config of a session on the database selected by
+// ctx, or database by default, with the access mode of ctx, or mode by
+// default, starting after the bookmarks of ctx.
+func sessionConfig(ctx context.Context, database string, mode neo4j.AccessMode) neo4j.SessionConfig {
+	if db := graph.DatabaseFromContext(ctx); db != "" {
+		database = db
+	}
+	config := neo4j.SessionConfig{AccessMode: accessMode(ctx, mode), DatabaseName: database}
+	if b := graph.BookmarksFromContext(ctx); b != nil {
+		config.Bookmarks = neo4j.BookmarksFromRawValues(b.Values()...)
+	}
+	return config
+}
+
+// accessMode returns the driver access mode set by ctx, or mode by default.
+func accessMode(ctx context.Context, mode neo4j.AccessMode) neo4j.AccessMode {
+	def := graph.AccessModeWrite
+	if mode == neo4j.AccessModeRead {
+		def = graph.AccessModeRead
+	}
+	if graph.AccessModeFromContext(ctx, def) == graph.AccessModeRead {
+		return neo4j.AccessModeRead
+	}
+	return neo4j.AccessModeWrite
+}
+
+// newSession opens a session configured by ctx, see sessionConfig, and returns
+// it with the function closing it, which records its last bookmarks in the
+// bookmarks of ctx.
+func newSession(ctx context.Context, driver neo4j.DriverWithContext, database string, mode neo4j.AccessMode) (neo4j.SessionWithContext, func(ctx context.Context) error) {
+	config := sessionConfig(ctx, database, mode)
+	session := driver.NewSession(ctx, config)
+	bookmarks := graph.BookmarksFromContext(ctx)
+	return session, func(ctx context.Context) error {
+		if bookmarks != nil {
+			bookmarks.Update(neo4j.BookmarksToRawValues(config.Bookmarks), neo4j.BookmarksToRawValues(session.LastBookmarks()))
+		}
+		return session.Close(ctx)
+	}
+}
+
+func (e *sessionExecutor) read(ctx context.Context, work func(tx runner) (any, error)) (any, error) {
+	return e.execute(ctx, neo4j.AccessModeRead, work)
+}
+
+func (e *sessionExecutor) write(ctx context.Context, work func(tx runner) (any, error)) (any, error) {
+	return e.execute(ctx, neo4j.AccessModeWrite, work)
+}
+
+// execute runs work in a managed transaction routed by the access mode of
+// ctx, or mode by default.
+func (e *sessionExecutor) execute(ctx context.Context, mode neo4j.AccessMode, work func(tx runner) (any, error)) (any, error) {
+	session, closeSession := newSession(ctx, e.driver, e.database, mode)
+	defer closeSession(ctx)
+
+	managed := func(tx neo4j.ManagedTransaction) (any, error) {
+		return work(tx)
+	}
+	if accessMode(ctx, mode) == neo4j.AccessModeRead {
+		return session.ExecuteRead(ctx, managed)
+	}
+	return session.ExecuteWrite(ctx, managed)
+}
+
+// txExecutor runs all units of work in the explicit transaction of a Tx.
+type txExecutor struct {
+	mu     sync.Mutex
+	tx     neo4j.ExplicitTransaction
+	closed bool
+}
+
+func (e *txExecutor) run(ctx context.Context, work func(tx runner) (any, error)) (any, error) {
+	// Explicit transactions don't support concurrent use.
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.closed {
+		return nil, graph.ErrTxDone
+	}
+	return work(e.tx)
+}
+
+func (e *txExecutor) read(ctx context.Context, work func(tx runner) (any, error)) (any, error) {
+	return e.run(ctx, work)
+}
+
+func (e *txExecutor) write(ctx context.Context, work func(tx runner) (any, error)) (any, error) {
+	return e.run(ctx, work)
+}
+
+// neo4jTx reuses the client operations on top of an explicit transaction.
+type neo4jTx struct {
+	*neo4jClient
+	closeSession func(ctx context.Context) error
+	exec         *txExecutor
+}
+
+// BeginTx opens a session configured by ctx, see sessionConfig, and starts an
+// explicit transaction on it. The transaction writes unless ctx sets the read
+// access mode.
+func (c *neo4jClient) BeginTx(ctx context.Context) (graph.Tx, error) {
+	session, closeSession := newSession(ctx, c.driver, c.database, neo4j.AccessModeWrite)
+	tx, err := session.BeginTransaction(ctx)
+	if err != nil {
+		_ = closeSession(ctx)
+		return nil, err
+	}
+
+	exec := &txExecutor{tx: tx}
+	return &neo4jTx{
+		neo4jClient:  &neo4jClient{driver: c.driver, database: c.database, exec: exec, version: c.version},
+		closeSession: closeSession,
+		exec:         exec,
+	}, nil
+}
+
+func (t *neo4jTx) Commit(ctx context.Context) error {
+	return t.finish(ctx, t.exec.tx.Commit)
+}
+
+func (t *neo4jTx) Rollback(ctx context.Context) error {
+	return t.finish(ctx, t.exec.tx.Rollback)
+}
+
+func (t *neo4jTx) finish(ctx context.Context, end func(ctx context.Context) error) error {
+	t.exec.mu.Lock()
+	defer t.exec.mu.Unlock()
+	if t.exec.closed {
+		return graph.ErrTxDone
+	}
+	t.exec.closed = true
+
+	err := end(ctx)
+	return errors.Join(err, t.exec.tx.Close(ctx), t.closeSession(ctx))
+}
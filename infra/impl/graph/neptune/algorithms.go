@@ -0,0 +1,91 @@
+package neptune
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/me2seeks/forge/infra/contract/graph"
+	"github.com/me2seeks/forge/infra/impl/graph/internal"
+	neo4jimpl "github.com/me2seeks/forge/infra/impl/graph/neo4j"
+)
+
+// defaultMaxDepth bounds the search of ShortestPath unless config sets
+// "maxDepth".
+const defaultMaxDepth = 10
+
+// ShortestPath finds the shortest path between two nodes by iterative
+// deepening: it queries the paths of each length from 1 to config["maxDepth"]
+// (10 by default) until some are found, returning one, or all of them up to
+// config["limit"] (100 by default) if config["all"] is true. config may also
+// set "relationshipTypes" and "direction" (OUTGOING, INCOMING or BOTH, the
+// default). Weighted paths aren't supported, as Neptune has no path algorithm.
+func (c *neptuneClient) ShortestPath(ctx context.Context, sourceNodeID, targetNodeID string, config map[string]any) ([]*graph.Path, error) {
+	if internal.ConfigString(config, "relationshipWeightProperty") != "" {
+		return nil, unsupported("weighted shortest path")
+	}
+	if err := graph.ValidateNames(internal.ConfigStrings(config, "relationshipTypes")...); err != nil {
+		return nil, err
+	}
+	limit := 1
+	if all, _ := config["all"].(bool); all {
+		limit = internal.ConfigIntOr(config, "limit", 100)
+	}
+	params := map[string]any{"source": sourceNodeID, "target": targetNodeID, "limit": limit}
+	for depth := 1; depth <= internal.ConfigIntOr(config, "maxDepth", defaultMaxDepth); depth++ {
+		results, err := c.run(ctx, false, buildPathsCypher(depth, config), params)
+		if err != nil {
+			return nil, err
+		}
+		var paths []*graph.Path
+		for _, result := range results {
+			if path, ok := toGraphEntity(result["p"]).(*graph.Path); ok {
+				paths = append(paths, path)
+			}
+		}
+		if len(paths) > 0 {
+			return paths, nil
+		}
+	}
+	return nil, nil
+}
+
+// buildPathsCypher returns the paths of length depth between $source and
+// $target, up to $limit.
+func buildPathsCypher(depth int, config map[string]any) string {
+	var rel strings.Builder
+	rel.WriteString("[")
+	for i, typ := range internal.ConfigStrings(config, "relationshipTypes") {
+		if i == 0 {
+			rel.WriteString(":")
+		} else {
+			rel.WriteString("|")
+		}
+		rel.WriteString(neo4jimpl.QuoteName(typ))
+	}
+	rel.WriteString(fmt.Sprintf("*%d]", depth))
+
+	pattern := "-" + rel.String() + "-"
+	switch strings.ToUpper(internal.ConfigString(config, "direction")) {
+	case "OUTGOING":
+		pattern = "-" + rel.String() + "->"
+	case "INCOMING":
+		pattern = "<-" + rel.String() + "-"
+	}
+	return "MATCH (s), (t) WHERE id(s) = $source AND id(t) = $target " +
+		"MATCH p = (s)" + pattern + "(t) RETURN p LIMIT $limit"
+}
+
+// PageRank isn't supported: Neptune Database has no graph algorithms, which
+// run on Neptune Analytics.
+func (c *neptuneClient) PageRank(ctx context.Context, config map[string]any) (map[string]float64, error) {
+	return nil, unsupported("page rank")
+}
+
+func (c *neptuneClient) ConnectedComponents(ctx context.Context, config map[string]any) (map[string]string, error) {
+	return nil, unsupported("connected components")
+}
+
+func (c *neptuneClient) BetweennessCentrality(ctx context.Context, config map[string]any) (map[string]float64, error) {
+	return nil, unsupported("betweenness centrality")
+}
@@ -0,0 +1,233 @@
+// Package neptune implements the graph contract on Amazon Neptune over its
+// openCypher HTTPS endpoint, signing requests with SigV4 when IAM database
+// authentication is enabled. Queries are built like the neo4j client's, with
+// entities identified by their Neptune ID, i.e. id(n).
+//
+// Neptune runs each request in its own transaction and manages its indexes
+// itself, so the operations depending on explicit transactions, constraints,
+// full-text indexes, graph algorithms or multiple databases fail with an error
+// wrapping errors.ErrUnsupported.
+package neptune
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	v4 "github.com/aws/aws-sdk-go-v2/aws/signer/v4"
+	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/me2seeks/forge/httpclient"
+	"github.com/me2seeks/forge/infra/contract/graph"
+	"github.com/me2seeks/forge/sonic"
+)
+
+// signingName is the service name of Neptune's SigV4 signatures.
+const signingName = "neptune-db"
+
+// Error is an error response of the Neptune HTTP API.
+type Error struct {
+	// Status is the HTTP status code.
+	Status int `json:"-"`
+	// Code is Neptune's error code, e.g. MalformedQueryException.
+	Code      string `json:"code"`
+	Message   string `json:"detailedMessage"`
+	RequestID string `json:"requestId"`
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("neptune: %s: %s (status %d)", e.Code, e.Message, e.Status)
+}
+
+type neptuneClient struct {
+	http   *httpclient.Client
+	writer string
+	reader string
+	// credentials and region sign the requests, which aren't signed if
+	// credentials is nil.
+	credentials aws.CredentialsProvider
+	region      string
+	signer      *v4.Signer
+}
+
+// Option is a function that configures the neptune client
+type Option func(*options)
+
+// options contains the configuration for the neptune client
+type options struct {
+	reader      string
+	awsConfig   *aws.Config
+	noIAMAuth   bool
+	httpOptions []httpclient.Option
+}
+
+// WithReaderEndpoint sets the endpoint of the cluster's read replicas, which
+// the reads are sent to, like the neo4j client routes them to followers. A
+// call is routed with the access mode of graph.WithAccessMode, or its default
+// one. Defaults to the endpoint of the client.
+func WithReaderEndpoint(endpoint string) Option {
+	return func(o *options) {
+		o.reader = endpoint
+	}
+}
+
+// WithAWSConfig sets the region and credentials signing the requests, loaded
+// from the environment by default, see config.LoadDefaultConfig.
+func WithAWSConfig(cfg aws.Config) Option {
+	return func(o *options) {
+		o.awsConfig = &cfg
+	}
+}
+
+// WithoutIAMAuth sends unsigned requests, to clusters without IAM database
+// authentication.
+func WithoutIAMAuth() Option {
+	return func(o *options) {
+		o.noIAMAuth = true
+	}
+}
+
+// WithHTTPOptions configures the HTTP client, e.g. its timeout and retries.
+func WithHTTPOptions(opts ...httpclient.Option) Option {
+	return func(o *options) {
+		o.httpOptions = append(o.httpOptions, opts...)
+	}
+}
+
+// New creates a neptune client of the cluster at endpoint, e.g.
+// https://my-cluster.cluster-abc.us-east-1.neptune.amazonaws.com:8182, and
+// checks it can query it.
+func New(ctx context.Context, endpoint string, opts ...Option) (graph.Client, error) {
+	o := &options{}
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	c := &neptuneClient{
+		http:   httpclient.New(o.httpOptions...),
+		writer: strings.TrimSuffix(endpoint, "/"),
+		reader: strings.TrimSuffix(endpoint, "/"),
+	}
+	if o.reader != "" {
+		c.reader = strings.TrimSuffix(o.reader, "/")
+	}
+	if !o.noIAMAuth {
+		cfg := o.awsConfig
+		if cfg == nil {
+			loaded, err := config.LoadDefaultConfig(ctx)
+			if err != nil {
+				return nil, fmt.Errorf("load aws config failed: %w", err)
+			}
+			cfg = &loaded
+		}
+		if cfg.Region == "" {
+			return nil, fmt.Errorf("neptune: no aws region to sign requests for")
+		}
+		c.credentials, c.region, c.signer = cfg.Credentials, cfg.Region, v4.NewSigner()
+	}
+
+	if _, err := c.run(ctx, false, "RETURN 1", nil); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// run runs query on the writer endpoint, or on the reader endpoint if ctx
+// sets the read access mode or write is false and ctx sets none. It returns
+// the results as decoded from JSON, see toGraphEntity.
+func (c *neptuneClient) run(ctx context.Context, write bool, query string, params map[string]any) ([]map[string]any, error) {
+	if db := graph.DatabaseFromContext(ctx); db != "" {
+		return nil, unsupported("database " + db)
+	}
+	def := graph.AccessModeRead
+	if write {
+		def = graph.AccessModeWrite
+	}
+	endpoint := c.writer
+	if graph.AccessModeFromContext(ctx, def) == graph.AccessModeRead {
+		endpoint = c.reader
+	}
+
+	form := url.Values{"query": {query}}
+	if len(params) > 0 {
+		buf, err := sonic.Marshal(params)
+		if err != nil {
+			return nil, fmt.Errorf("marshal parameters failed: %w", err)
+		}
+		form.Set("parameters", string(buf))
+	}
+	body := form.Encode()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint+"/openCypher", strings.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	if err := c.sign(ctx, req, body); err != nil {
+		return nil, err
+	}
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	buf, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("read response failed: %w", err)
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		apiErr := &Error{Status: resp.StatusCode}
+		if err := sonic.Unmarshal(buf, apiErr); err != nil || apiErr.Message == "" {
+			apiErr.Message = http.StatusText(resp.StatusCode)
+		}
+		return nil, apiErr
+	}
+	var res struct {
+		Results []map[string]any `json:"results"`
+	}
+	if err := sonic.Unmarshal(buf, &res); err != nil {
+		return nil, fmt.Errorf("unmarshal response failed: %w", err)
+	}
+	return res.Results, nil
+}
+
+// sign signs req, whose body is body, with SigV4 if the client has credentials.
+func (c *neptuneClient) sign(ctx context.Context, req *http.Request, body string) error {
+	if c.credentials == nil {
+		return nil
+	}
+	creds, err := c.credentials.Retrieve(ctx)
+	if err != nil {
+		return fmt.Errorf("retrieve aws credentials failed: %w", err)
+	}
+	hash := sha256.Sum256([]byte(body))
+	return c.signer.SignHTTP(ctx, creds, req, hex.EncodeToString(hash[:]), signingName, c.region, time.Now())
+}
+
+// cypher adapts the Cypher built by the neo4j builders to Neptune, which has
+// no elementId(): its id() returns the string IDs of the entities.
+func cypher(query string) string {
+	return strings.ReplaceAll(query, "elementId(", "id(")
+}
+
+// unsupported returns the error of an operation Neptune has no equivalent for.
+func unsupported(op string) error {
+	return fmt.Errorf("neptune: %s: %w", op, errors.ErrUnsupported)
+}
+
+// BeginTx fails, as each request to the openCypher endpoint is a transaction
+// of its own. The operations writing several entities, e.g. CreateNodes or
+// MergeEdge, do so in a single request where possible.
+func (c *neptuneClient) BeginTx(ctx context.Context) (graph.Tx, error) {
+	return nil, unsupported("transactions")
+}
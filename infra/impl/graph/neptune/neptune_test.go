@@ -0,0 +1,225 @@
+package neptune
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/credentials"
+	"github.com/me2seeks/forge/infra/contract/graph"
+	"github.com/stretchr/testify/require"
+)
+
+var testEndpoint = os.Getenv("NEPTUNE_ENDPOINT")
+
+func setup(t *testing.T) (graph.Client, func()) {
+	if testEndpoint == "" {
+		t.Skip("NEPTUNE_ENDPOINT is not set")
+	}
+	ctx := context.Background()
+	var opts []Option
+	if os.Getenv("NEPTUNE_DISABLE_IAM_AUTH") != "" {
+		opts = append(opts, WithoutIAMAuth())
+	}
+	client, err := New(ctx, testEndpoint, opts...)
+	require.NoError(t, err)
+
+	teardown := func() {
+		_, err := client.(*neptuneClient).run(ctx, true, "MATCH (n) DETACH DELETE n", nil)
+		require.NoError(t, err)
+	}
+	return client, teardown
+}
+
+// newTestServer returns a client of a server checking the requests with check
+// and responding with status and body.
+func newTestServer(t *testing.T, status int, body string, check func(r *http.Request)) *neptuneClient {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if check != nil {
+			check(r)
+		}
+		w.WriteHeader(status)
+		_, _ = w.Write([]byte(body))
+	}))
+	t.Cleanup(server.Close)
+
+	client, err := New(context.Background(), server.URL, WithAWSConfig(aws.Config{
+		Region:      "us-east-1",
+		Credentials: credentials.NewStaticCredentialsProvider("AKID", "SECRET", ""),
+	}))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	return client.(*neptuneClient)
+}
+
+func TestRun(t *testing.T) {
+	var got *http.Request
+	c := newTestServer(t, http.StatusOK, `{"results": [{"n": 1}]}`, func(r *http.Request) {
+		if err := r.ParseForm(); err != nil {
+			t.Errorf("Unexpected form: %v", err)
+		}
+		got = r
+	})
+
+	results, err := c.run(context.Background(), true, "MATCH (n) WHERE id(n) = $id RETURN 1 AS n", map[string]any{"id": "a"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if want := []map[string]any{{"n": int64(1)}}; !reflect.DeepEqual(results, want) {
+		t.Errorf("Unexpected results.\nGot:  %v\nWant: %v", results, want)
+	}
+	if got.URL.Path != "/openCypher" || got.PostForm.Get("query") != "MATCH (n) WHERE id(n) = $id RETURN 1 AS n" {
+		t.Errorf("Unexpected request: %s %v", got.URL.Path, got.PostForm)
+	}
+	if params := got.PostForm.Get("parameters"); params != `{"id":"a"}` {
+		t.Errorf("Unexpected parameters: %s", params)
+	}
+	if auth := got.Header.Get("Authorization"); !strings.Contains(auth, "/us-east-1/neptune-db/aws4_request") {
+		t.Errorf("Unexpected authorization: %s", auth)
+	}
+}
+
+func TestRun_Error(t *testing.T) {
+	c := newTestServer(t, http.StatusOK, `{"results": []}`, nil)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+		_, _ = w.Write([]byte(`{"requestId": "42", "code": "MalformedQueryException", "detailedMessage": "Invalid input"}`))
+	}))
+	defer server.Close()
+	c.writer = server.URL
+
+	_, err := c.run(context.Background(), true, "MATCH", nil)
+	var apiErr *Error
+	if !errors.As(err, &apiErr) {
+		t.Fatalf("Expected an API error, got %v", err)
+	}
+	if apiErr.Status != http.StatusBadRequest || apiErr.Code != "MalformedQueryException" || apiErr.Message != "Invalid input" {
+		t.Errorf("Unexpected error: %+v", apiErr)
+	}
+}
+
+// TestRun_Routing tests that reads go to the reader endpoint unless ctx sets
+// the write access mode.
+func TestRun_Routing(t *testing.T) {
+	c := newTestServer(t, http.StatusOK, `{"results": []}`, nil)
+	var hits []string
+	for _, name := range []string{"writer", "reader"} {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			hits = append(hits, name)
+			_, _ = w.Write([]byte(`{"results": []}`))
+		}))
+		defer server.Close()
+		if name == "writer" {
+			c.writer = server.URL
+		} else {
+			c.reader = server.URL
+		}
+	}
+
+	ctx := context.Background()
+	_, _ = c.run(ctx, false, "RETURN 1", nil)
+	_, _ = c.run(ctx, true, "RETURN 1", nil)
+	_, _ = c.run(graph.WithAccessMode(ctx, graph.AccessModeWrite), false, "RETURN 1", nil)
+	if want := []string{"reader", "writer", "writer"}; !reflect.DeepEqual(hits, want) {
+		t.Errorf("Unexpected routing.\nGot:  %v\nWant: %v", hits, want)
+	}
+
+	_, err := c.run(graph.WithDatabase(ctx, "other"), false, "RETURN 1", nil)
+	if !errors.Is(err, errors.ErrUnsupported) {
+		t.Errorf("Expected an unsupported error, got %v", err)
+	}
+}
+
+func TestToGraphEntity(t *testing.T) {
+	a := map[string]any{"~id": "a", "~entityType": "node", "~labels": []any{"City"}, "~properties": map[string]any{"name": "A"}}
+	b := map[string]any{"~id": "b", "~entityType": "node", "~labels": []any{"City"}, "~properties": map[string]any{}}
+	r := map[string]any{"~id": "r", "~entityType": "relationship", "~start": "a", "~end": "b", "~type": "ROAD", "~properties": map[string]any{"km": int64(10)}}
+
+	nodeA := &graph.Node{ID: "a", Labels: []string{"City"}, Properties: graph.Properties{"name": "A"}}
+	nodeB := &graph.Node{ID: "b", Labels: []string{"City"}, Properties: graph.Properties{}}
+	edge := &graph.Edge{ID: "r", Label: "ROAD", SourceNodeID: "a", TargetNodeID: "b", Properties: graph.Properties{"km": int64(10)}}
+
+	cases := []struct {
+		value any
+		want  graph.ResultEntity
+	}{
+		{a, nodeA},
+		{r, edge},
+		{[]any{a, r, b}, &graph.Path{Nodes: []*graph.Node{nodeA, nodeB}, Edges: []*graph.Edge{edge}}},
+		{[]any{a, b}, []*graph.Node{nodeA, nodeB}},
+		{[]any{r}, []*graph.Edge{edge}},
+		{[]any{a, int64(1)}, []any{nodeA, int64(1)}},
+		{map[string]any{"name": "A"}, map[string]any{"name": "A"}},
+		{"A", "A"},
+	}
+	for _, c := range cases {
+		if got := toGraphEntity(c.value); !reflect.DeepEqual(got, c.want) {
+			t.Errorf("Unexpected entity.\nGot:  %#v\nWant: %#v", got, c.want)
+		}
+	}
+}
+
+func TestBuildPathsCypher(t *testing.T) {
+	got := buildPathsCypher(2, map[string]any{"relationshipTypes": []any{"ROAD", "RAIL"}, "direction": "incoming"})
+	want := "MATCH (s), (t) WHERE id(s) = $source AND id(t) = $target MATCH p = (s)<-[:`ROAD`|`RAIL`*2]-(t) RETURN p LIMIT $limit"
+	if got != want {
+		t.Errorf("Cypher mismatch.\nGot:  %s\nWant: %s", got, want)
+	}
+}
+
+func TestCypher(t *testing.T) {
+	got := cypher("MATCH (a) WHERE elementId(a) = $id RETURN a ORDER BY elementId(a)")
+	if want := "MATCH (a) WHERE id(a) = $id RETURN a ORDER BY id(a)"; got != want {
+		t.Errorf("Cypher mismatch.\nGot:  %s\nWant: %s", got, want)
+	}
+}
+
+func TestNeptune(t *testing.T) {
+	client, teardown := setup(t)
+	defer teardown()
+
+	ctx := context.Background()
+
+	nodes, err := client.CreateNodes(ctx, []*graph.Node{
+		{Labels: []string{"City"}, Properties: graph.Properties{"name": "A"}},
+		{Labels: []string{"City"}, Properties: graph.Properties{"name": "B"}},
+		{Labels: []string{"City"}, Properties: graph.Properties{"name": "C"}},
+	})
+	require.NoError(t, err)
+	a, b, c := nodes[0], nodes[1], nodes[2]
+	_, err = client.CreateEdges(ctx, []*graph.Edge{
+		{Label: "ROAD", SourceNodeID: a.ID, TargetNodeID: b.ID},
+		{Label: "ROAD", SourceNodeID: b.ID, TargetNodeID: c.ID},
+	})
+	require.NoError(t, err)
+
+	got, err := client.GetNode(ctx, b.ID)
+	require.NoError(t, err)
+	require.Equal(t, "B", got.Properties["name"])
+
+	count, err := client.Count(ctx, &graph.Query{Match: []graph.Pattern{{Labels: []string{"City"}}}})
+	require.NoError(t, err)
+	require.EqualValues(t, 3, count)
+
+	paths, err := client.ShortestPath(ctx, a.ID, c.ID, map[string]any{"direction": "OUTGOING"})
+	require.NoError(t, err)
+	require.Len(t, paths, 1)
+	require.Len(t, paths[0].Edges, 2)
+
+	merged, err := client.MergeNode(ctx, &graph.Node{Labels: []string{"City"}, Properties: graph.Properties{"name": "A", "size": 1}}, []string{"name"})
+	require.NoError(t, err)
+	require.Equal(t, a.ID, merged.ID)
+
+	_, err = client.BeginTx(ctx)
+	require.True(t, errors.Is(err, errors.ErrUnsupported))
+	require.NoError(t, client.CreateNodeIndex(ctx, "City", []string{"name"}))
+	err = client.CreateConstraint(ctx, "City", "name", graph.ConstraintUnique)
+	require.True(t, errors.Is(err, errors.ErrUnsupported))
+}
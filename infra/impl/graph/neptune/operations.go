@@ -0,0 +1,473 @@
+package neptune
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"slices"
+
+	"github.com/me2seeks/forge/infra/contract/graph"
+	neo4jimpl "github.com/me2seeks/forge/infra/impl/graph/neo4j"
+)
+
+func (c *neptuneClient) CreateNode(ctx context.Context, node *graph.Node) (*graph.Node, error) {
+	nodes, err := c.CreateNodes(ctx, []*graph.Node{node})
+	if err != nil {
+		return nil, err
+	}
+	return nodes[0], nil
+}
+
+// CreateNodes creates the nodes with one request per label set, each of which
+// is atomic, but not the whole batch.
+func (c *neptuneClient) CreateNodes(ctx context.Context, nodes []*graph.Node) ([]*graph.Node, error) {
+	if len(nodes) == 0 {
+		return nil, nil
+	}
+	for i, node := range nodes {
+		if err := graph.ValidateNames(node.Labels...); err != nil {
+			return nil, fmt.Errorf("create nodes: node %d: %w", i, err)
+		}
+	}
+	created := make([]*graph.Node, len(nodes))
+	for _, stmt := range neo4jimpl.BuildCreateNodesCypher(nodes) {
+		err := c.runBatch(ctx, stmt, "n", func(i int, entity graph.ResultEntity) {
+			created[i], _ = entity.(*graph.Node)
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	return created, nil
+}
+
+// CreateEdges creates the edges with one request per type, each of which is
+// atomic, but not the whole batch. It fails if an endpoint is missing, after
+// creating the edges of the other types.
+func (c *neptuneClient) CreateEdges(ctx context.Context, edges []*graph.Edge) ([]*graph.Edge, error) {
+	if len(edges) == 0 {
+		return nil, nil
+	}
+	stmts, err := neo4jimpl.BuildCreateEdgesCypher(edges)
+	if err != nil {
+		return nil, err
+	}
+	created := make([]*graph.Edge, len(edges))
+	for _, stmt := range stmts {
+		err := c.runBatch(ctx, stmt, "r", func(i int, entity graph.ResultEntity) {
+			created[i], _ = entity.(*graph.Edge)
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	if i := slices.Index(created, nil); i >= 0 {
+		return nil, fmt.Errorf("create edges: endpoint of edge %d not found", i)
+	}
+	return created, nil
+}
+
+// runBatch runs stmt and calls collect with the index and the created entity of each row.
+func (c *neptuneClient) runBatch(ctx context.Context, stmt *neo4jimpl.BatchStatement, key string, collect func(i int, entity graph.ResultEntity)) error {
+	results, err := c.run(ctx, true, cypher(stmt.Cypher), map[string]any{"rows": stmt.Rows})
+	if err != nil {
+		return err
+	}
+	for _, result := range results {
+		i, _ := result["i"].(int64)
+		collect(int(i), toGraphEntity(result[key]))
+	}
+	return nil
+}
+
+func (c *neptuneClient) GetNode(ctx context.Context, nodeID string) (*graph.Node, error) {
+	node, _, err := c.single(ctx, false, "MATCH (n) WHERE id(n) = $id RETURN n", map[string]any{"id": nodeID}, "n")
+	return node, err
+}
+
+// UpdateNode sets properties on the node; setting a property to nil removes it.
+func (c *neptuneClient) UpdateNode(ctx context.Context, nodeID string, properties graph.Properties) error {
+	_, err := c.run(ctx, true, "MATCH (n) WHERE id(n) = $id SET n += $props", map[string]any{"id": nodeID, "props": propsOrEmpty(properties)})
+	return err
+}
+
+// DeleteNode deletes the node and its edges.
+func (c *neptuneClient) DeleteNode(ctx context.Context, nodeID string) error {
+	_, err := c.run(ctx, true, "MATCH (n) WHERE id(n) = $id DETACH DELETE n", map[string]any{"id": nodeID})
+	return err
+}
+
+func (c *neptuneClient) MergeNode(ctx context.Context, node *graph.Node, matchKeys []string) (*graph.Node, error) {
+	query, params, err := neo4jimpl.BuildMergeNodeCypher(node, matchKeys)
+	if err != nil {
+		return nil, err
+	}
+	merged, _, err := c.single(ctx, true, cypher(query), params, "n")
+	return merged, err
+}
+
+func (c *neptuneClient) CreateEdge(ctx context.Context, edge *graph.Edge) (*graph.Edge, error) {
+	if err := edge.ValidateNames(); err != nil {
+		return nil, err
+	}
+	query, params := neo4jimpl.BuildCreateEdgeCypher(edge)
+	_, created, err := c.single(ctx, true, cypher(query), params, "r")
+	return created, err
+}
+
+// MergeEdge merges the edge between its endpoints in a single request. Unlike
+// the neo4j client, which rolls back, it fails after merging an edge for each
+// pair of nodes if the endpoints match several.
+func (c *neptuneClient) MergeEdge(ctx context.Context, edge *graph.Edge, onCreate, onMatch graph.Properties) (*graph.Edge, error) {
+	query, params, err := neo4jimpl.BuildMergeEdgeCypher(edge, onCreate, onMatch)
+	if err != nil {
+		return nil, err
+	}
+	results, err := c.run(ctx, true, cypher(query), params)
+	if err != nil {
+		return nil, err
+	}
+	switch len(results) {
+	case 0:
+		return nil, nil
+	case 1:
+		merged, _ := toGraphEntity(results[0]["r"]).(*graph.Edge)
+		return merged, nil
+	default:
+		return nil, fmt.Errorf("merge edge: endpoints match %d pairs of nodes", len(results))
+	}
+}
+
+func (c *neptuneClient) GetEdge(ctx context.Context, edgeID string) (*graph.Edge, error) {
+	_, edge, err := c.single(ctx, false, "MATCH ()-[r]->() WHERE id(r) = $id RETURN r", map[string]any{"id": edgeID}, "r")
+	return edge, err
+}
+
+// UpdateEdge sets properties on the edge; setting a property to nil removes it.
+func (c *neptuneClient) UpdateEdge(ctx context.Context, edgeID string, properties graph.Properties) error {
+	_, err := c.run(ctx, true, "MATCH ()-[r]->() WHERE id(r) = $id SET r += $props", map[string]any{"id": edgeID, "props": propsOrEmpty(properties)})
+	return err
+}
+
+func (c *neptuneClient) DeleteEdge(ctx context.Context, edgeID string) error {
+	_, err := c.run(ctx, true, "MATCH ()-[r]->() WHERE id(r) = $id DELETE r", map[string]any{"id": edgeID})
+	return err
+}
+
+// GetNeighbors runs the Cypher of the neo4j client in a single request.
+func (c *neptuneClient) GetNeighbors(ctx context.Context, nodeID string, opts ...graph.NeighborOpt) (*graph.Neighborhood, error) {
+	o := graph.NewNeighborOptions(opts...)
+	if err := graph.ValidateNames(o.RelationshipTypes...); err != nil {
+		return nil, err
+	}
+	query, params := neo4jimpl.BuildNeighborsCypher(nodeID, o)
+	res, err := c.queryRecords(ctx, false, cypher(query), params)
+	if err != nil {
+		return nil, err
+	}
+	n := &graph.Neighborhood{}
+	if len(res.Records) > 0 {
+		n.Nodes, _ = res.Records[0]["nodes"].([]*graph.Node)
+		n.Edges, _ = res.Records[0]["edges"].([]*graph.Edge)
+	}
+	return n, nil
+}
+
+// Traverse loads the subgraph with the Cypher of the neo4j client, and runs
+// the traversal in memory over it.
+func (c *neptuneClient) Traverse(ctx context.Context, startNodeID string, spec graph.TraversalSpec) ([]*graph.Path, error) {
+	if err := graph.ValidateNames(spec.RelationshipTypes...); err != nil {
+		return nil, err
+	}
+	query, params := neo4jimpl.BuildSubgraphCypher(startNodeID, spec.NeighborOptions())
+	res, err := c.queryRecords(ctx, false, cypher(query), params)
+	if err != nil || len(res.Records) == 0 {
+		return nil, err
+	}
+	nodes, _ := res.Records[0]["nodes"].([]*graph.Node)
+	edges, _ := res.Records[0]["edges"].([]*graph.Edge)
+	return graph.TraverseSubgraph(startNodeID, nodes, edges, spec)
+}
+
+// single runs a query returning at most one entity under key, and returns it
+// as a node or an edge, both nil if there is none.
+func (c *neptuneClient) single(ctx context.Context, write bool, query string, params map[string]any, key string) (*graph.Node, *graph.Edge, error) {
+	results, err := c.run(ctx, write, query, params)
+	if err != nil || len(results) == 0 {
+		return nil, nil, err
+	}
+	switch v := toGraphEntity(results[0][key]).(type) {
+	case *graph.Node:
+		return v, nil, nil
+	case *graph.Edge:
+		return nil, v, nil
+	default:
+		return nil, nil, fmt.Errorf("unexpected result %T", v)
+	}
+}
+
+// propsOrEmpty avoids setting an entity to null, which Cypher rejects.
+func propsOrEmpty(props graph.Properties) graph.Properties {
+	if props == nil {
+		return graph.Properties{}
+	}
+	return props
+}
+
+func (c *neptuneClient) Query(ctx context.Context, query *graph.Query) (*graph.QueryResult, error) {
+	if err := query.ValidateNames(); err != nil {
+		return nil, err
+	}
+	q, params := neo4jimpl.BuildQueryCypher(query)
+	return c.queryRecords(ctx, false, cypher(q), params)
+}
+
+// RawQuery runs an openCypher query, routed to the reader endpoint unless ctx
+// sets the write access mode.
+func (c *neptuneClient) RawQuery(ctx context.Context, query string, params map[string]any) (*graph.QueryResult, error) {
+	return c.queryRecords(ctx, false, query, params)
+}
+
+func (c *neptuneClient) BatchQuery(ctx context.Context, statement string, rows []map[string]any, params map[string]any) (*graph.QueryResult, error) {
+	query, params, err := neo4jimpl.BuildBatchQueryCypher(statement, rows, params)
+	if err != nil {
+		return nil, err
+	}
+	return c.queryRecords(ctx, true, query, params)
+}
+
+func (c *neptuneClient) queryRecords(ctx context.Context, write bool, query string, params map[string]any) (*graph.QueryResult, error) {
+	results, err := c.run(ctx, write, query, params)
+	if err != nil {
+		return nil, err
+	}
+	records := make([]graph.Record, len(results))
+	for i, result := range results {
+		records[i] = toGraphRecord(result)
+	}
+	return &graph.QueryResult{Records: records}, nil
+}
+
+// QueryStream runs the query like Query, as the openCypher endpoint returns
+// all the records at once, and iterates over them.
+func (c *neptuneClient) QueryStream(ctx context.Context, query *graph.Query) (graph.RecordIterator, error) {
+	result, err := c.Query(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	return &recordIterator{records: result.Records}, nil
+}
+
+// ExportGraph writes the entities of the records of QueryStream.
+func (c *neptuneClient) ExportGraph(ctx context.Context, w io.Writer, format graph.ExportFormat, query *graph.Query) error {
+	return graph.Export(ctx, c, w, format, query)
+}
+
+// recordIterator iterates over records already fetched.
+type recordIterator struct {
+	records []graph.Record
+	record  graph.Record
+}
+
+func (it *recordIterator) Next(ctx context.Context) bool {
+	if len(it.records) == 0 {
+		it.record = nil
+		return false
+	}
+	it.record, it.records = it.records[0], it.records[1:]
+	return true
+}
+
+func (it *recordIterator) Record() graph.Record {
+	return it.record
+}
+
+func (it *recordIterator) Err() error {
+	return nil
+}
+
+func (it *recordIterator) Close(ctx context.Context) error {
+	it.records, it.record = nil, nil
+	return nil
+}
+
+func (c *neptuneClient) FindNodes(ctx context.Context, query *graph.Query) ([]*graph.Node, error) {
+	result, err := c.Query(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	var nodes []*graph.Node
+	seen := make(map[string]struct{})
+	for _, record := range result.Records {
+		for _, entity := range record {
+			if node, ok := entity.(*graph.Node); ok {
+				if _, exists := seen[node.ID]; !exists {
+					nodes = append(nodes, node)
+					seen[node.ID] = struct{}{}
+				}
+			}
+		}
+	}
+	return nodes, nil
+}
+
+func (c *neptuneClient) FindEdges(ctx context.Context, query *graph.Query) ([]*graph.Edge, error) {
+	result, err := c.Query(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	var edges []*graph.Edge
+	seen := make(map[string]struct{})
+	for _, record := range result.Records {
+		for _, entity := range record {
+			if edge, ok := entity.(*graph.Edge); ok {
+				if _, exists := seen[edge.ID]; !exists {
+					edges = append(edges, edge)
+					seen[edge.ID] = struct{}{}
+				}
+			}
+		}
+	}
+	return edges, nil
+}
+
+func (c *neptuneClient) FindPaths(ctx context.Context, query *graph.Query) ([]*graph.Path, error) {
+	result, err := c.Query(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	var paths []*graph.Path
+	for _, record := range result.Records {
+		for _, entity := range record {
+			switch v := entity.(type) {
+			case *graph.Path:
+				paths = append(paths, v)
+			case []any:
+				for _, item := range v {
+					if path, ok := item.(*graph.Path); ok {
+						paths = append(paths, path)
+					}
+				}
+			}
+		}
+	}
+	return paths, nil
+}
+
+func (c *neptuneClient) Count(ctx context.Context, query *graph.Query) (int64, error) {
+	if err := query.ValidateNames(); err != nil {
+		return 0, err
+	}
+	q, params := neo4jimpl.BuildCountCypher(query)
+	return c.count(ctx, false, cypher(q), params)
+}
+
+// UpdateNodesByQuery sets properties on the nodes of the first alias of the
+// query, in a single request.
+func (c *neptuneClient) UpdateNodesByQuery(ctx context.Context, query *graph.Query, properties graph.Properties) (int, error) {
+	if err := query.ValidateNames(); err != nil {
+		return 0, err
+	}
+	if err := properties.ValidateNames(); err != nil {
+		return 0, err
+	}
+	q, params := neo4jimpl.BuildUpdateByQueryCypher(query, false, properties)
+	count, err := c.count(ctx, true, cypher(q), params)
+	return int(count), err
+}
+
+// UpdateEdgesByQuery sets properties on the edges of the edge alias of the
+// first pattern of the query, in a single request.
+func (c *neptuneClient) UpdateEdgesByQuery(ctx context.Context, query *graph.Query, properties graph.Properties) (int, error) {
+	if err := query.ValidateNames(); err != nil {
+		return 0, err
+	}
+	if err := properties.ValidateNames(); err != nil {
+		return 0, err
+	}
+	q, params := neo4jimpl.BuildUpdateByQueryCypher(query, true, properties)
+	count, err := c.count(ctx, true, cypher(q), params)
+	return int(count), err
+}
+
+// DeleteNodesByQuery deletes the nodes of the first alias of the query and
+// their edges, in a single request.
+func (c *neptuneClient) DeleteNodesByQuery(ctx context.Context, query *graph.Query) (int, error) {
+	if err := query.ValidateNames(); err != nil {
+		return 0, err
+	}
+	q, params := neo4jimpl.BuildDeleteByQueryCypher(query, false)
+	count, err := c.count(ctx, true, cypher(q), params)
+	return int(count), err
+}
+
+// DeleteEdgesByQuery deletes the edges of the edge alias of the first pattern
+// of the query, in a single request.
+func (c *neptuneClient) DeleteEdgesByQuery(ctx context.Context, query *graph.Query) (int, error) {
+	if err := query.ValidateNames(); err != nil {
+		return 0, err
+	}
+	q, params := neo4jimpl.BuildDeleteByQueryCypher(query, true)
+	count, err := c.count(ctx, true, cypher(q), params)
+	return int(count), err
+}
+
+// count runs a query returning a single count.
+func (c *neptuneClient) count(ctx context.Context, write bool, query string, params map[string]any) (int64, error) {
+	results, err := c.run(ctx, write, query, params)
+	if err != nil || len(results) == 0 {
+		return 0, err
+	}
+	for _, value := range results[0] {
+		count, _ := value.(int64)
+		return count, nil
+	}
+	return 0, nil
+}
+
+type bulkWriter struct {
+	nodes  []*graph.Node
+	edges  []*graph.Edge
+	client *neptuneClient
+}
+
+func (c *neptuneClient) NewBulkWriter() graph.BulkWriter {
+	return &bulkWriter{
+		client: c,
+	}
+}
+
+// ImportGraph imports in batches of the bulk writer, see Import.
+func (c *neptuneClient) ImportGraph(ctx context.Context, nodes, edges io.Reader, opts ...graph.ImportOpt) (*graph.ImportResult, error) {
+	return graph.Import(ctx, c, nodes, edges, opts...)
+}
+
+func (b *bulkWriter) AddNode(ctx context.Context, node *graph.Node) error {
+	b.nodes = append(b.nodes, node)
+	return nil
+}
+
+func (b *bulkWriter) AddEdge(ctx context.Context, edge *graph.Edge) error {
+	b.edges = append(b.edges, edge)
+	return nil
+}
+
+// Close creates the added nodes, then edges, see CreateNodes and CreateEdges.
+// Without transactions, a failure keeps the entities created before it.
+func (b *bulkWriter) Close(ctx context.Context) error {
+	nodes, err := b.client.CreateNodes(ctx, b.nodes)
+	if err != nil {
+		return err
+	}
+	for i, node := range nodes {
+		b.nodes[i].ID = node.ID
+	}
+	edges, err := b.client.CreateEdges(ctx, b.edges)
+	if err != nil {
+		return err
+	}
+	for i, edge := range edges {
+		b.edges[i].ID = edge.ID
+	}
+	return nil
+}
@@ -0,0 +1,157 @@
+package neptune
+
+import (
+	"github.com/me2seeks/forge/infra/contract/graph"
+)
+
+// Neptune returns nodes and relationships as JSON objects whose ~entityType
+// is "node" or "relationship", and paths as lists alternating nodes and
+// relationships.
+const (
+	entityTypeKey = "~entityType"
+	entityNode    = "node"
+	entityEdge    = "relationship"
+)
+
+func toGraphRecord(result map[string]any) graph.Record {
+	record := make(graph.Record, len(result))
+	for key, value := range result {
+		record[key] = toGraphEntity(value)
+	}
+	return record
+}
+
+// toGraphEntity converts nodes, relationships and paths, and lists of them, to
+// graph entities like the neo4j client: a list of nodes to []*graph.Node, a
+// list of relationships to []*graph.Edge, and other lists to []any of
+// converted items.
+func toGraphEntity(value any) graph.ResultEntity {
+	switch v := value.(type) {
+	case map[string]any:
+		switch v[entityTypeKey] {
+		case entityNode:
+			return toGraphNode(v)
+		case entityEdge:
+			return toGraphEdge(v)
+		}
+		return v
+	case []any:
+		return toGraphList(v)
+	default:
+		return v
+	}
+}
+
+func toGraphList(list []any) graph.ResultEntity {
+	if isPath(list) {
+		return toGraphPath(list)
+	}
+	if allOf(list, entityNode) {
+		nodes := make([]*graph.Node, len(list))
+		for i, item := range list {
+			nodes[i] = toGraphNode(item.(map[string]any))
+		}
+		return nodes
+	}
+	if allOf(list, entityEdge) {
+		edges := make([]*graph.Edge, len(list))
+		for i, item := range list {
+			edges[i] = toGraphEdge(item.(map[string]any))
+		}
+		return edges
+	}
+	items := make([]any, len(list))
+	for i, item := range list {
+		items[i] = toGraphEntity(item)
+	}
+	return items
+}
+
+func entityType(value any) any {
+	obj, ok := value.(map[string]any)
+	if !ok {
+		return nil
+	}
+	return obj[entityTypeKey]
+}
+
+func allOf(list []any, typ string) bool {
+	for _, item := range list {
+		if entityType(item) != typ {
+			return false
+		}
+	}
+	return len(list) > 0
+}
+
+// isPath reports whether list alternates nodes and relationships, starting
+// and ending with a node.
+func isPath(list []any) bool {
+	if len(list) < 3 || len(list)%2 == 0 {
+		return false
+	}
+	for i, item := range list {
+		want := entityNode
+		if i%2 == 1 {
+			want = entityEdge
+		}
+		if entityType(item) != want {
+			return false
+		}
+	}
+	return true
+}
+
+func toGraphNode(obj map[string]any) *graph.Node {
+	return &graph.Node{
+		ID:         toString(obj["~id"]),
+		Labels:     toStrings(obj["~labels"]),
+		Properties: toProperties(obj["~properties"]),
+	}
+}
+
+func toGraphEdge(obj map[string]any) *graph.Edge {
+	return &graph.Edge{
+		ID:           toString(obj["~id"]),
+		Label:        toString(obj["~type"]),
+		SourceNodeID: toString(obj["~start"]),
+		TargetNodeID: toString(obj["~end"]),
+		Properties:   toProperties(obj["~properties"]),
+	}
+}
+
+func toGraphPath(list []any) *graph.Path {
+	path := &graph.Path{}
+	for i, item := range list {
+		if i%2 == 0 {
+			path.Nodes = append(path.Nodes, toGraphNode(item.(map[string]any)))
+		} else {
+			path.Edges = append(path.Edges, toGraphEdge(item.(map[string]any)))
+		}
+	}
+	return path
+}
+
+func toProperties(value any) graph.Properties {
+	props, _ := value.(map[string]any)
+	if props == nil {
+		return graph.Properties{}
+	}
+	return props
+}
+
+func toString(value any) string {
+	s, _ := value.(string)
+	return s
+}
+
+func toStrings(value any) []string {
+	list, _ := value.([]any)
+	strs := make([]string, 0, len(list))
+	for _, item := range list {
+		if s, ok := item.(string); ok {
+			strs = append(strs, s)
+		}
+	}
+	return strs
+}
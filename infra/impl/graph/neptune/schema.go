@@ -0,0 +1,81 @@
+package neptune
+
+import (
+	"context"
+
+	"github.com/me2seeks/forge/infra/contract/graph"
+)
+
+// Neptune indexes all the labels and properties itself, so there are no
+// indexes to create or drop: the index operations do nothing, for the code
+// creating its indexes on startup to run unchanged, and ListIndexes returns
+// none. Neptune has no constraints nor full-text indexes, which are only
+// available through its OpenSearch integration.
+
+func (c *neptuneClient) CreateNodeIndex(ctx context.Context, label string, properties []string) error {
+	return nil
+}
+
+func (c *neptuneClient) CreateEdgeIndex(ctx context.Context, label string, properties []string) error {
+	return nil
+}
+
+func (c *neptuneClient) DropNodeIndex(ctx context.Context, label string, properties []string) error {
+	return nil
+}
+
+func (c *neptuneClient) DropEdgeIndex(ctx context.Context, label string, properties []string) error {
+	return nil
+}
+
+func (c *neptuneClient) CreateConstraint(ctx context.Context, label, property string, constraintType graph.ConstraintType) error {
+	return unsupported("constraints")
+}
+
+func (c *neptuneClient) DropConstraint(ctx context.Context, label, property string, constraintType graph.ConstraintType) error {
+	return unsupported("constraints")
+}
+
+func (c *neptuneClient) CreateFullTextIndex(ctx context.Context, name string, labels, properties []string) error {
+	return unsupported("full-text index")
+}
+
+func (c *neptuneClient) DropFullTextIndex(ctx context.Context, name string) error {
+	return unsupported("full-text index")
+}
+
+func (c *neptuneClient) FullTextSearch(ctx context.Context, indexName, query string, limit int) ([]*graph.ScoredNode, error) {
+	return nil, unsupported("full-text search")
+}
+
+func (c *neptuneClient) ListIndexes(ctx context.Context) ([]*graph.IndexInfo, error) {
+	return nil, nil
+}
+
+func (c *neptuneClient) ListConstraints(ctx context.Context) ([]*graph.ConstraintInfo, error) {
+	return nil, nil
+}
+
+// ListLabels returns the labels of the stored nodes, scanning them all.
+func (c *neptuneClient) ListLabels(ctx context.Context) ([]string, error) {
+	return c.listStrings(ctx, "MATCH (n) UNWIND labels(n) AS value RETURN DISTINCT value ORDER BY value")
+}
+
+// ListRelationshipTypes returns the types of the stored edges, scanning them all.
+func (c *neptuneClient) ListRelationshipTypes(ctx context.Context) ([]string, error) {
+	return c.listStrings(ctx, "MATCH ()-[r]->() RETURN DISTINCT type(r) AS value ORDER BY value")
+}
+
+func (c *neptuneClient) listStrings(ctx context.Context, query string) ([]string, error) {
+	results, err := c.run(ctx, false, query, nil)
+	if err != nil {
+		return nil, err
+	}
+	values := make([]string, 0, len(results))
+	for _, result := range results {
+		if value, ok := result["value"].(string); ok {
+			values = append(values, value)
+		}
+	}
+	return values, nil
+}
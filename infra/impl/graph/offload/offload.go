@@ -0,0 +1,718 @@
+// Package offload decorates a graph.Client so that oversized node property
+// values are kept in object storage instead of the graph.
+//
+// A value whose encoding exceeds the threshold is written to storage under a
+// content-addressed key and replaced on the node by a reference string holding
+// the key and the SHA-256 of the object. References are resolved, and checked
+// against their checksum, when nodes are read back through the client.
+// Strings and byte slices round-trip as is, other values through JSON.
+//
+// Objects are shared by all values with the same content, so they are never
+// deleted by the client; expire orphans with a lifecycle rule on the prefix.
+package offload
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"maps"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/me2seeks/forge/infra/contract/graph"
+	"github.com/me2seeks/forge/infra/contract/storage"
+	"github.com/me2seeks/forge/sonic"
+	"github.com/me2seeks/forge/taskgroup"
+)
+
+// refPrefix starts the reference strings, followed by "<kind>:<sha256>:<key>".
+const refPrefix = "forge-offload:v1:"
+
+const (
+	kindString = "s"
+	kindBytes  = "b"
+	kindJSON   = "j"
+)
+
+type client struct {
+	graph.Client
+	storage     storage.Storage
+	threshold   int
+	prefix      string
+	concurrency int
+}
+
+type Option func(*client)
+
+// WithThreshold sets the encoded size in bytes above which a value is
+// offloaded. Defaults to 256KiB.
+func WithThreshold(n int) Option {
+	return func(c *client) {
+		c.threshold = n
+	}
+}
+
+// WithKeyPrefix sets the prefix of the object keys. Defaults to "graph-properties/".
+func WithKeyPrefix(prefix string) Option {
+	return func(c *client) {
+		c.prefix = prefix
+	}
+}
+
+// WithConcurrency sets the number of objects transferred at once. Defaults to 8.
+func WithConcurrency(n int) Option {
+	return func(c *client) {
+		c.concurrency = n
+	}
+}
+
+// New wraps inner so that the node properties written through CreateNode,
+// UpdateNode, UpdateNodesByQuery, bulk writers and ImportGraph are offloaded
+// to store, and the nodes returned by GetNode, GetNeighbors, Traverse,
+// FindNodes, FindPaths, Query, RawQuery and BatchQuery are rehydrated, on the
+// client and its transactions alike, as are the exported ones.
+func New(inner graph.Client, store storage.Storage, opts ...Option) graph.Client {
+	c := &client{
+		Client:      inner,
+		storage:     store,
+		threshold:   256 << 10,
+		prefix:      "graph-properties/",
+		concurrency: 8,
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+func (c *client) CreateNode(ctx context.Context, node *graph.Node) (*graph.Node, error) {
+	return c.createNode(ctx, c.Client, node)
+}
+
+func (c *client) CreateNodes(ctx context.Context, nodes []*graph.Node) ([]*graph.Node, error) {
+	return c.createNodes(ctx, c.Client, nodes)
+}
+
+func (c *client) MergeNode(ctx context.Context, node *graph.Node, matchKeys []string) (*graph.Node, error) {
+	return c.mergeNode(ctx, c.Client, node, matchKeys)
+}
+
+func (c *client) GetNode(ctx context.Context, nodeID string) (*graph.Node, error) {
+	return c.getNode(ctx, c.Client, nodeID)
+}
+
+func (c *client) UpdateNode(ctx context.Context, nodeID string, properties graph.Properties) error {
+	return c.updateNode(ctx, c.Client, nodeID, properties)
+}
+
+func (c *client) UpdateNodesByQuery(ctx context.Context, query *graph.Query, properties graph.Properties) (int, error) {
+	return c.updateNodesByQuery(ctx, c.Client, query, properties)
+}
+
+func (c *client) GetNeighbors(ctx context.Context, nodeID string, opts ...graph.NeighborOpt) (*graph.Neighborhood, error) {
+	return c.getNeighbors(ctx, c.Client, nodeID, opts)
+}
+
+func (c *client) Traverse(ctx context.Context, startNodeID string, spec graph.TraversalSpec) ([]*graph.Path, error) {
+	return c.traverse(ctx, c.Client, startNodeID, spec)
+}
+
+func (c *client) FindNodes(ctx context.Context, query *graph.Query) ([]*graph.Node, error) {
+	return c.findNodes(ctx, c.Client, query)
+}
+
+func (c *client) FindPaths(ctx context.Context, query *graph.Query) ([]*graph.Path, error) {
+	return c.findPaths(ctx, c.Client, query)
+}
+
+func (c *client) Query(ctx context.Context, query *graph.Query) (*graph.QueryResult, error) {
+	return c.query(ctx, c.Client, query)
+}
+
+func (c *client) FullTextSearch(ctx context.Context, indexName, query string, limit int) ([]*graph.ScoredNode, error) {
+	return c.fullTextSearch(ctx, c.Client, indexName, query, limit)
+}
+
+func (c *client) QueryStream(ctx context.Context, query *graph.Query) (graph.RecordIterator, error) {
+	return c.queryStream(ctx, c.Client, query)
+}
+
+// ExportGraph exports the nodes rehydrated by QueryStream.
+func (c *client) ExportGraph(ctx context.Context, w io.Writer, format graph.ExportFormat, query *graph.Query) error {
+	return graph.Export(ctx, c, w, format, query)
+}
+
+// ImportGraph imports with the bulk writers of the client, offloading the
+// node properties.
+func (c *client) ImportGraph(ctx context.Context, nodes, edges io.Reader, opts ...graph.ImportOpt) (*graph.ImportResult, error) {
+	return graph.Import(ctx, c, nodes, edges, opts...)
+}
+
+func (c *client) RawQuery(ctx context.Context, query string, params map[string]any) (*graph.QueryResult, error) {
+	return c.rawQuery(ctx, c.Client, query, params)
+}
+
+func (c *client) BatchQuery(ctx context.Context, statement string, rows []map[string]any, params map[string]any) (*graph.QueryResult, error) {
+	return c.batchQuery(ctx, c.Client, statement, rows, params)
+}
+
+// Unwrap returns the decorated client.
+func (c *client) Unwrap() graph.Client {
+	return c.Client
+}
+
+func (c *client) BeginTx(ctx context.Context) (graph.Tx, error) {
+	tx, err := c.Client.BeginTx(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &txn{Tx: tx, client: c}, nil
+}
+
+// txn offloads the properties written in a transaction like the client does.
+// Objects are written before commit, so a rolled back transaction leaves them
+// orphaned.
+type txn struct {
+	graph.Tx
+	client *client
+}
+
+func (t *txn) CreateNode(ctx context.Context, node *graph.Node) (*graph.Node, error) {
+	return t.client.createNode(ctx, t.Tx, node)
+}
+
+func (t *txn) CreateNodes(ctx context.Context, nodes []*graph.Node) ([]*graph.Node, error) {
+	return t.client.createNodes(ctx, t.Tx, nodes)
+}
+
+func (t *txn) MergeNode(ctx context.Context, node *graph.Node, matchKeys []string) (*graph.Node, error) {
+	return t.client.mergeNode(ctx, t.Tx, node, matchKeys)
+}
+
+func (t *txn) GetNode(ctx context.Context, nodeID string) (*graph.Node, error) {
+	return t.client.getNode(ctx, t.Tx, nodeID)
+}
+
+func (t *txn) UpdateNode(ctx context.Context, nodeID string, properties graph.Properties) error {
+	return t.client.updateNode(ctx, t.Tx, nodeID, properties)
+}
+
+func (t *txn) UpdateNodesByQuery(ctx context.Context, query *graph.Query, properties graph.Properties) (int, error) {
+	return t.client.updateNodesByQuery(ctx, t.Tx, query, properties)
+}
+
+func (t *txn) GetNeighbors(ctx context.Context, nodeID string, opts ...graph.NeighborOpt) (*graph.Neighborhood, error) {
+	return t.client.getNeighbors(ctx, t.Tx, nodeID, opts)
+}
+
+func (t *txn) Traverse(ctx context.Context, startNodeID string, spec graph.TraversalSpec) ([]*graph.Path, error) {
+	return t.client.traverse(ctx, t.Tx, startNodeID, spec)
+}
+
+func (t *txn) FindNodes(ctx context.Context, query *graph.Query) ([]*graph.Node, error) {
+	return t.client.findNodes(ctx, t.Tx, query)
+}
+
+func (t *txn) FindPaths(ctx context.Context, query *graph.Query) ([]*graph.Path, error) {
+	return t.client.findPaths(ctx, t.Tx, query)
+}
+
+func (t *txn) Query(ctx context.Context, query *graph.Query) (*graph.QueryResult, error) {
+	return t.client.query(ctx, t.Tx, query)
+}
+
+func (t *txn) FullTextSearch(ctx context.Context, indexName, query string, limit int) ([]*graph.ScoredNode, error) {
+	return t.client.fullTextSearch(ctx, t.Tx, indexName, query, limit)
+}
+
+func (t *txn) QueryStream(ctx context.Context, query *graph.Query) (graph.RecordIterator, error) {
+	return t.client.queryStream(ctx, t.Tx, query)
+}
+
+func (t *txn) RawQuery(ctx context.Context, query string, params map[string]any) (*graph.QueryResult, error) {
+	return t.client.rawQuery(ctx, t.Tx, query, params)
+}
+
+func (t *txn) BatchQuery(ctx context.Context, statement string, rows []map[string]any, params map[string]any) (*graph.QueryResult, error) {
+	return t.client.batchQuery(ctx, t.Tx, statement, rows, params)
+}
+
+func (c *client) createNode(ctx context.Context, inner graph.Operations, node *graph.Node) (*graph.Node, error) {
+	props, err := c.offload(ctx, node.Properties)
+	if err != nil {
+		return nil, err
+	}
+	created, err := inner.CreateNode(ctx, &graph.Node{ID: node.ID, Labels: node.Labels, Properties: props})
+	if err != nil || created == nil {
+		return created, err
+	}
+	restore(created, node.Properties)
+	return created, nil
+}
+
+func (c *client) createNodes(ctx context.Context, inner graph.Operations, nodes []*graph.Node) ([]*graph.Node, error) {
+	offloaded := make([]*graph.Node, len(nodes))
+	for i, node := range nodes {
+		props, err := c.offload(ctx, node.Properties)
+		if err != nil {
+			return nil, err
+		}
+		offloaded[i] = &graph.Node{ID: node.ID, Labels: node.Labels, Properties: props}
+	}
+	created, err := inner.CreateNodes(ctx, offloaded)
+	if err != nil {
+		return nil, err
+	}
+	for i, node := range created {
+		if node != nil {
+			restore(node, nodes[i].Properties)
+		}
+	}
+	return created, nil
+}
+
+func (c *client) mergeNode(ctx context.Context, inner graph.Operations, node *graph.Node, matchKeys []string) (*graph.Node, error) {
+	// Match keys are compared by the database, so they are never offloaded.
+	rest := make(graph.Properties, len(node.Properties))
+	for k, v := range node.Properties {
+		if !slices.Contains(matchKeys, k) {
+			rest[k] = v
+		}
+	}
+	props, err := c.offload(ctx, rest)
+	if err != nil {
+		return nil, err
+	}
+	for _, k := range matchKeys {
+		if v, ok := node.Properties[k]; ok {
+			props[k] = v
+		}
+	}
+
+	merged, err := inner.MergeNode(ctx, &graph.Node{ID: node.ID, Labels: node.Labels, Properties: props}, matchKeys)
+	if err != nil || merged == nil {
+		return merged, err
+	}
+	// A matched node may hold references written earlier.
+	restore(merged, node.Properties)
+	if err := c.rehydrate(ctx, []*graph.Node{merged}); err != nil {
+		return nil, err
+	}
+	return merged, nil
+}
+
+// restore puts the written values back in place of their references in the
+// node returned by a write, as there is no need to read them back.
+func restore(node *graph.Node, written graph.Properties) {
+	restored := make(graph.Properties, len(node.Properties))
+	for k, v := range node.Properties {
+		if orig, ok := written[k]; ok && isRef(v) {
+			v = orig
+		}
+		restored[k] = v
+	}
+	node.Properties = restored
+}
+
+func (c *client) getNode(ctx context.Context, inner graph.Operations, nodeID string) (*graph.Node, error) {
+	node, err := inner.GetNode(ctx, nodeID)
+	if err != nil || node == nil {
+		return node, err
+	}
+	if err := c.rehydrate(ctx, []*graph.Node{node}); err != nil {
+		return nil, err
+	}
+	return node, nil
+}
+
+func (c *client) updateNode(ctx context.Context, inner graph.Operations, nodeID string, properties graph.Properties) error {
+	props, err := c.offload(ctx, properties)
+	if err != nil {
+		return err
+	}
+	return inner.UpdateNode(ctx, nodeID, props)
+}
+
+func (c *client) updateNodesByQuery(ctx context.Context, inner graph.Operations, query *graph.Query, properties graph.Properties) (int, error) {
+	props, err := c.offload(ctx, properties)
+	if err != nil {
+		return 0, err
+	}
+	return inner.UpdateNodesByQuery(ctx, query, props)
+}
+
+func (c *client) getNeighbors(ctx context.Context, inner graph.Operations, nodeID string, opts []graph.NeighborOpt) (*graph.Neighborhood, error) {
+	neighborhood, err := inner.GetNeighbors(ctx, nodeID, opts...)
+	if err != nil {
+		return nil, err
+	}
+	if err := c.rehydrate(ctx, neighborhood.Nodes); err != nil {
+		return nil, err
+	}
+	return neighborhood, nil
+}
+
+// traverse rehydrates the nodes before the functions of spec see them, so that
+// they may filter on offloaded values. A failed rehydration in NodeFilter
+// rejects the node and fails the traversal once it ends.
+func (c *client) traverse(ctx context.Context, inner graph.Operations, startNodeID string, spec graph.TraversalSpec) ([]*graph.Path, error) {
+	var filterErr error
+	if filter := spec.NodeFilter; filter != nil {
+		spec.NodeFilter = func(node *graph.Node) bool {
+			if err := c.rehydrate(ctx, []*graph.Node{node}); err != nil {
+				if filterErr == nil {
+					filterErr = err
+				}
+				return false
+			}
+			return filter(node)
+		}
+	}
+	if visit := spec.Visit; visit != nil {
+		spec.Visit = func(path *graph.Path) error {
+			if err := c.rehydrate(ctx, path.Nodes); err != nil {
+				return err
+			}
+			return visit(path)
+		}
+	}
+	paths, err := inner.Traverse(ctx, startNodeID, spec)
+	if err != nil {
+		return nil, err
+	}
+	if filterErr != nil {
+		return nil, filterErr
+	}
+	var nodes []*graph.Node
+	for _, path := range paths {
+		nodes = append(nodes, path.Nodes...)
+	}
+	if err := c.rehydrate(ctx, nodes); err != nil {
+		return nil, err
+	}
+	return paths, nil
+}
+
+func (c *client) findNodes(ctx context.Context, inner graph.Operations, query *graph.Query) ([]*graph.Node, error) {
+	nodes, err := inner.FindNodes(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	if err := c.rehydrate(ctx, nodes); err != nil {
+		return nil, err
+	}
+	return nodes, nil
+}
+
+func (c *client) findPaths(ctx context.Context, inner graph.Operations, query *graph.Query) ([]*graph.Path, error) {
+	paths, err := inner.FindPaths(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	var nodes []*graph.Node
+	for _, path := range paths {
+		nodes = append(nodes, path.Nodes...)
+	}
+	if err := c.rehydrate(ctx, nodes); err != nil {
+		return nil, err
+	}
+	return paths, nil
+}
+
+func (c *client) query(ctx context.Context, inner graph.Operations, query *graph.Query) (*graph.QueryResult, error) {
+	res, err := inner.Query(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	return res, c.rehydrate(ctx, recordNodes(res))
+}
+
+// fullTextSearch rehydrates the found nodes. Offloaded values are references in
+// the graph, so full-text indexes can't match them.
+func (c *client) fullTextSearch(ctx context.Context, inner graph.Operations, indexName, query string, limit int) ([]*graph.ScoredNode, error) {
+	scored, err := inner.FullTextSearch(ctx, indexName, query, limit)
+	if err != nil {
+		return nil, err
+	}
+	nodes := make([]*graph.Node, len(scored))
+	for i, s := range scored {
+		nodes[i] = s.Node
+	}
+	return scored, c.rehydrate(ctx, nodes)
+}
+
+func (c *client) queryStream(ctx context.Context, inner graph.Operations, query *graph.Query) (graph.RecordIterator, error) {
+	it, err := inner.QueryStream(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	return &recordIterator{RecordIterator: it, client: c}, nil
+}
+
+// recordIterator rehydrates the nodes of each record as it is reached.
+type recordIterator struct {
+	graph.RecordIterator
+	client *client
+	err    error
+}
+
+func (it *recordIterator) Next(ctx context.Context) bool {
+	if it.err != nil || !it.RecordIterator.Next(ctx) {
+		return false
+	}
+	res := &graph.QueryResult{Records: []graph.Record{it.RecordIterator.Record()}}
+	if err := it.client.rehydrate(ctx, recordNodes(res)); err != nil {
+		it.err = err
+		return false
+	}
+	return true
+}
+
+func (it *recordIterator) Err() error {
+	if it.err != nil {
+		return it.err
+	}
+	return it.RecordIterator.Err()
+}
+
+func (c *client) rawQuery(ctx context.Context, inner graph.Operations, query string, params map[string]any) (*graph.QueryResult, error) {
+	res, err := inner.RawQuery(ctx, query, params)
+	if err != nil {
+		return nil, err
+	}
+	return res, c.rehydrate(ctx, recordNodes(res))
+}
+
+func (c *client) batchQuery(ctx context.Context, inner graph.Operations, statement string, rows []map[string]any, params map[string]any) (*graph.QueryResult, error) {
+	res, err := inner.BatchQuery(ctx, statement, rows, params)
+	if err != nil {
+		return nil, err
+	}
+	return res, c.rehydrate(ctx, recordNodes(res))
+}
+
+func (c *client) NewBulkWriter() graph.BulkWriter {
+	return &bulkWriter{BulkWriter: c.Client.NewBulkWriter(), client: c}
+}
+
+type bulkWriter struct {
+	graph.BulkWriter
+	client *client
+	// nodes are the added nodes, and copies the offloaded copies added to the
+	// inner writer in their place.
+	nodes  []*graph.Node
+	copies []*graph.Node
+}
+
+func (w *bulkWriter) AddNode(ctx context.Context, node *graph.Node) error {
+	props, err := w.client.offload(ctx, node.Properties)
+	if err != nil {
+		return err
+	}
+	c := &graph.Node{ID: node.ID, Labels: node.Labels, Properties: props}
+	if err := w.BulkWriter.AddNode(ctx, c); err != nil {
+		return err
+	}
+	w.nodes = append(w.nodes, node)
+	w.copies = append(w.copies, c)
+	return nil
+}
+
+// Close closes the inner writer and sets the IDs of the added nodes to those
+// of their copies.
+func (w *bulkWriter) Close(ctx context.Context) error {
+	if err := w.BulkWriter.Close(ctx); err != nil {
+		return err
+	}
+	for i, node := range w.nodes {
+		node.ID = w.copies[i].ID
+	}
+	return nil
+}
+
+// recordNodes returns the nodes found in the records of res.
+func recordNodes(res *graph.QueryResult) []*graph.Node {
+	if res == nil {
+		return nil
+	}
+	var nodes []*graph.Node
+	for _, rec := range res.Records {
+		for _, entity := range rec {
+			switch v := entity.(type) {
+			case *graph.Node:
+				nodes = append(nodes, v)
+			case []*graph.Node:
+				nodes = append(nodes, v...)
+			case []any:
+				for _, item := range v {
+					switch item := item.(type) {
+					case *graph.Node:
+						nodes = append(nodes, item)
+					case *graph.Path:
+						nodes = append(nodes, item.Nodes...)
+					}
+				}
+			case *graph.Path:
+				nodes = append(nodes, v.Nodes...)
+			}
+		}
+	}
+	return nodes
+}
+
+// offload returns a copy of props whose oversized values are stored as objects
+// and replaced by references. The references are collected apart and set once
+// all objects are stored, so that out is never written while being filled.
+func (c *client) offload(ctx context.Context, props graph.Properties) (graph.Properties, error) {
+	if len(props) == 0 {
+		return props, nil
+	}
+
+	out := make(graph.Properties, len(props))
+	refs := make(map[string]string)
+	var mu sync.Mutex
+	tg := taskgroup.NewTaskGroup(ctx, c.concurrency)
+	for k, v := range props {
+		out[k] = v
+		kind, data, err := encode(v)
+		if err != nil {
+			_ = tg.Wait()
+			return nil, fmt.Errorf("encode property %s failed: %w", k, err)
+		}
+		if len(data) <= c.threshold {
+			continue
+		}
+
+		tg.Go(func() error {
+			sum := sha256.Sum256(data)
+			checksum := hex.EncodeToString(sum[:])
+			key := c.prefix + checksum
+			if err := c.storage.PutObject(ctx, key, data, storage.WithObjectSize(int64(len(data)))); err != nil {
+				return fmt.Errorf("offload property %s failed: %w", k, err)
+			}
+			mu.Lock()
+			refs[k] = refPrefix + kind + ":" + checksum + ":" + key
+			mu.Unlock()
+			return nil
+		})
+	}
+	if err := tg.Wait(); err != nil {
+		return nil, err
+	}
+	for k, ref := range refs {
+		out[k] = ref
+	}
+	return out, nil
+}
+
+// rehydrate replaces the references in the properties of nodes by their values.
+// The values are loaded into their own slots and set once all are loaded, so
+// that the properties are never written while being read.
+func (c *client) rehydrate(ctx context.Context, nodes []*graph.Node) error {
+	type job struct {
+		node  *graph.Node
+		key   string
+		ref   string
+		value any
+	}
+	var jobs []*job
+	for _, node := range nodes {
+		if node == nil {
+			continue
+		}
+		for k, v := range node.Properties {
+			if isRef(v) {
+				jobs = append(jobs, &job{node: node, key: k, ref: v.(string)})
+			}
+		}
+	}
+	if len(jobs) == 0 {
+		return nil
+	}
+
+	tg := taskgroup.NewTaskGroup(ctx, c.concurrency)
+	for _, j := range jobs {
+		tg.Go(func() error {
+			v, err := c.load(ctx, j.ref)
+			if err != nil {
+				return fmt.Errorf("rehydrate property %s of node %s failed: %w", j.key, j.node.ID, err)
+			}
+			j.value = v
+			return nil
+		})
+	}
+	if err := tg.Wait(); err != nil {
+		return err
+	}
+
+	// Don't mutate a map the inner client may share.
+	copied := make(map[*graph.Node]bool)
+	for _, j := range jobs {
+		if !copied[j.node] {
+			j.node.Properties = maps.Clone(j.node.Properties)
+			copied[j.node] = true
+		}
+		j.node.Properties[j.key] = j.value
+	}
+	return nil
+}
+
+func (c *client) load(ctx context.Context, ref string) (any, error) {
+	kind, checksum, key, ok := parseRef(ref)
+	if !ok {
+		return nil, fmt.Errorf("malformed reference %q", ref)
+	}
+	data, err := c.storage.GetObject(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+	sum := sha256.Sum256(data)
+	if hex.EncodeToString(sum[:]) != checksum {
+		return nil, fmt.Errorf("checksum mismatch for object %s", key)
+	}
+	return decode(kind, data)
+}
+
+func isRef(v any) bool {
+	s, ok := v.(string)
+	return ok && strings.HasPrefix(s, refPrefix)
+}
+
+func parseRef(ref string) (kind, checksum, key string, ok bool) {
+	parts := strings.SplitN(strings.TrimPrefix(ref, refPrefix), ":", 3)
+	if len(parts) != 3 {
+		return "", "", "", false
+	}
+	return parts[0], parts[1], parts[2], true
+}
+
+func encode(v any) (string, []byte, error) {
+	switch v := v.(type) {
+	case string:
+		return kindString, []byte(v), nil
+	case []byte:
+		return kindBytes, v, nil
+	default:
+		data, err := sonic.Marshal(v)
+		return kindJSON, data, err
+	}
+}
+
+func decode(kind string, data []byte) (any, error) {
+	switch kind {
+	case kindString:
+		return string(data), nil
+	case kindBytes:
+		return bytes.Clone(data), nil
+	case kindJSON:
+		var v any
+		err := sonic.Unmarshal(data, &v)
+		return v, err
+	default:
+		return nil, fmt.Errorf("unknown reference kind %q", kind)
+	}
+}
@@ -0,0 +1,231 @@
+package offload
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/me2seeks/forge/infra/contract/graph"
+	"github.com/me2seeks/forge/infra/contract/storage"
+)
+
+type memGraph struct {
+	graph.Client
+	nodes map[string]*graph.Node
+}
+
+func (g *memGraph) CreateNode(_ context.Context, node *graph.Node) (*graph.Node, error) {
+	stored := &graph.Node{ID: "n1", Labels: node.Labels, Properties: node.Properties}
+	g.nodes[stored.ID] = stored
+	return &graph.Node{ID: stored.ID, Labels: stored.Labels, Properties: stored.Properties}, nil
+}
+
+func (g *memGraph) GetNode(_ context.Context, id string) (*graph.Node, error) {
+	n := g.nodes[id]
+	return &graph.Node{ID: n.ID, Labels: n.Labels, Properties: n.Properties}, nil
+}
+
+func (g *memGraph) CreateNodes(ctx context.Context, nodes []*graph.Node) ([]*graph.Node, error) {
+	created := make([]*graph.Node, len(nodes))
+	for i, node := range nodes {
+		stored := &graph.Node{ID: fmt.Sprintf("n%d", len(g.nodes)+1), Labels: node.Labels, Properties: node.Properties}
+		g.nodes[stored.ID] = stored
+		created[i] = &graph.Node{ID: stored.ID, Labels: stored.Labels, Properties: stored.Properties}
+	}
+	return created, nil
+}
+
+func (g *memGraph) MergeNode(_ context.Context, node *graph.Node, matchKeys []string) (*graph.Node, error) {
+	for _, n := range g.nodes {
+		if n.Properties[matchKeys[0]] == node.Properties[matchKeys[0]] {
+			maps.Copy(n.Properties, node.Properties)
+			return &graph.Node{ID: n.ID, Labels: n.Labels, Properties: maps.Clone(n.Properties)}, nil
+		}
+	}
+	return g.CreateNode(context.Background(), node)
+}
+
+func (g *memGraph) QueryStream(context.Context, *graph.Query) (graph.RecordIterator, error) {
+	it := &sliceIterator{}
+	for _, id := range slices.Sorted(maps.Keys(g.nodes)) {
+		n := g.nodes[id]
+		it.records = append(it.records, graph.Record{"n": &graph.Node{ID: n.ID, Labels: n.Labels, Properties: maps.Clone(n.Properties)}})
+	}
+	return it, nil
+}
+
+type sliceIterator struct {
+	records []graph.Record
+	pos     int
+}
+
+func (it *sliceIterator) Next(context.Context) bool {
+	it.pos++
+	return it.pos <= len(it.records)
+}
+
+func (it *sliceIterator) Record() graph.Record { return it.records[it.pos-1] }
+
+func (it *sliceIterator) Err() error { return nil }
+
+func (it *sliceIterator) Close(context.Context) error { return nil }
+
+func (g *memGraph) BeginTx(context.Context) (graph.Tx, error) {
+	return &memTx{memGraph: g}, nil
+}
+
+type memTx struct {
+	*memGraph
+	committed bool
+}
+
+func (tx *memTx) Commit(context.Context) error {
+	tx.committed = true
+	return nil
+}
+
+func (tx *memTx) Rollback(context.Context) error {
+	return nil
+}
+
+type memStorage struct {
+	storage.Storage
+	mu      sync.Mutex
+	objects map[string][]byte
+}
+
+func (s *memStorage) PutObject(_ context.Context, key string, content []byte, _ ...storage.PutOptFn) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.objects[key] = content
+	return nil
+}
+
+func (s *memStorage) GetObject(_ context.Context, key string) ([]byte, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.objects[key], nil
+}
+
+func TestOffload(t *testing.T) {
+	ctx := context.Background()
+	g := &memGraph{nodes: map[string]*graph.Node{}}
+	store := &memStorage{objects: map[string][]byte{}}
+	c := New(g, store, WithThreshold(16))
+
+	body := strings.Repeat("x", 64)
+	props := graph.Properties{"title": "short", "body": body, "tags": []any{"a", "b", "c", "d", "e", "f"}}
+	created, err := c.CreateNode(ctx, &graph.Node{Labels: []string{"Doc"}, Properties: props})
+	require.NoError(t, err)
+	assert.Equal(t, props, created.Properties)
+
+	stored := g.nodes["n1"].Properties
+	assert.Equal(t, "short", stored["title"])
+	assert.True(t, isRef(stored["body"]))
+	assert.True(t, isRef(stored["tags"]))
+	assert.Len(t, store.objects, 2)
+
+	got, err := c.GetNode(ctx, "n1")
+	require.NoError(t, err)
+	assert.Equal(t, props, got.Properties)
+	assert.True(t, isRef(g.nodes["n1"].Properties["body"]))
+
+	// A tampered object fails the checksum.
+	_, _, key, _ := parseRef(stored["body"].(string))
+	store.objects[key] = []byte("tampered")
+	_, err = c.GetNode(ctx, "n1")
+	assert.ErrorContains(t, err, "checksum mismatch")
+}
+
+func TestOffloadInTx(t *testing.T) {
+	ctx := context.Background()
+	g := &memGraph{nodes: map[string]*graph.Node{}}
+	c := New(g, &memStorage{objects: map[string][]byte{}}, WithThreshold(16))
+
+	body := strings.Repeat("x", 64)
+	err := graph.RunInTx(ctx, c, func(ctx context.Context, tx graph.Tx) error {
+		_, err := tx.CreateNode(ctx, &graph.Node{Labels: []string{"Doc"}, Properties: graph.Properties{"body": body}})
+		return err
+	})
+	require.NoError(t, err)
+	assert.True(t, isRef(g.nodes["n1"].Properties["body"]))
+}
+
+func TestOffloadMerge(t *testing.T) {
+	ctx := context.Background()
+	g := &memGraph{nodes: map[string]*graph.Node{}}
+	c := New(g, &memStorage{objects: map[string][]byte{}}, WithThreshold(16))
+
+	key := strings.Repeat("k", 32)
+	body := strings.Repeat("x", 64)
+	_, err := c.MergeNode(ctx, &graph.Node{Labels: []string{"Doc"}, Properties: graph.Properties{"key": key, "body": body}}, []string{"key"})
+	require.NoError(t, err)
+	assert.Equal(t, key, g.nodes["n1"].Properties["key"])
+	assert.True(t, isRef(g.nodes["n1"].Properties["body"]))
+
+	// The body set by the first merge is read back from storage.
+	merged, err := c.MergeNode(ctx, &graph.Node{Labels: []string{"Doc"}, Properties: graph.Properties{"key": key, "title": "t"}}, []string{"key"})
+	require.NoError(t, err)
+	assert.Equal(t, graph.Properties{"key": key, "body": body, "title": "t"}, merged.Properties)
+}
+
+func TestOffloadCreateNodes(t *testing.T) {
+	ctx := context.Background()
+	g := &memGraph{nodes: map[string]*graph.Node{}}
+	c := New(g, &memStorage{objects: map[string][]byte{}}, WithThreshold(16))
+
+	body := strings.Repeat("x", 64)
+	created, err := c.CreateNodes(ctx, []*graph.Node{
+		{Labels: []string{"Doc"}, Properties: graph.Properties{"body": body}},
+		{Labels: []string{"Doc"}, Properties: graph.Properties{"title": "short"}},
+	})
+	require.NoError(t, err)
+	require.Len(t, created, 2)
+	assert.Equal(t, body, created[0].Properties["body"])
+	assert.True(t, isRef(g.nodes["n1"].Properties["body"]))
+	assert.Equal(t, "short", g.nodes["n2"].Properties["title"])
+}
+
+func TestOffloadQueryStream(t *testing.T) {
+	ctx := context.Background()
+	g := &memGraph{nodes: map[string]*graph.Node{}}
+	c := New(g, &memStorage{objects: map[string][]byte{}}, WithThreshold(16))
+
+	body := strings.Repeat("x", 64)
+	_, err := c.CreateNodes(ctx, []*graph.Node{
+		{Labels: []string{"Doc"}, Properties: graph.Properties{"body": body}},
+		{Labels: []string{"Doc"}, Properties: graph.Properties{"body": "short"}},
+	})
+	require.NoError(t, err)
+
+	it, err := c.QueryStream(ctx, &graph.Query{})
+	require.NoError(t, err)
+	defer it.Close(ctx)
+	var bodies []any
+	for it.Next(ctx) {
+		bodies = append(bodies, it.Record()["n"].(*graph.Node).Properties["body"])
+	}
+	require.NoError(t, it.Err())
+	assert.Equal(t, []any{body, "short"}, bodies)
+}
+
+type extGraph struct {
+	*memGraph
+	graph.Neo4jExtensions
+}
+
+func TestUnwrap(t *testing.T) {
+	g := &extGraph{memGraph: &memGraph{nodes: map[string]*graph.Node{}}}
+	_, ok := graph.AsNeo4jExtensions(New(g, &memStorage{}))
+	assert.True(t, ok)
+
+	_, ok = graph.AsNeo4jExtensions(New(g.memGraph, &memStorage{}))
+	assert.False(t, ok)
+}
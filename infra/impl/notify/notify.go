@@ -0,0 +1,114 @@
+package notify
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	"github.com/me2seeks/forge/execute"
+	"github.com/me2seeks/forge/infra/contract/notify"
+	"github.com/me2seeks/forge/logs"
+	"github.com/me2seeks/forge/retry"
+)
+
+type Sender = notify.Sender
+
+type sender struct {
+	providers map[notify.Channel]notify.Provider
+	templates *Templates
+	retry     retry.Policy
+	reporters []notify.StatusReporter
+	now       func() time.Time
+}
+
+type Option func(*sender)
+
+// WithTemplates sets the templates that messages can refer to.
+func WithTemplates(t *Templates) Option {
+	return func(s *sender) {
+		s.templates = t
+	}
+}
+
+// WithRetry sets the retry policy of deliveries. Defaults to 3 attempts with
+// exponential backoff from 500ms.
+func WithRetry(policy retry.Policy) Option {
+	return func(s *sender) {
+		s.retry = policy
+	}
+}
+
+// WithStatusReporter adds a reporter called with the receipt of every Send.
+func WithStatusReporter(r notify.StatusReporter) Option {
+	return func(s *sender) {
+		s.reporters = append(s.reporters, r)
+	}
+}
+
+// New creates a Sender routing each message to the provider of its channel. A
+// later provider replaces an earlier one of the same channel.
+func New(providers []notify.Provider, opts ...Option) Sender {
+	s := &sender{
+		providers: make(map[notify.Channel]notify.Provider, len(providers)),
+		templates: NewTemplates(),
+		retry: retry.Policy{
+			MaxAttempts: 3,
+			Backoff:     retry.Exponential(500*time.Millisecond, 10*time.Second),
+		},
+		now: time.Now,
+	}
+	for _, p := range providers {
+		s.providers[p.Channel()] = p
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+func (s *sender) Send(ctx context.Context, msg *notify.Message) (*notify.Receipt, error) {
+	p, ok := s.providers[msg.Channel]
+	if !ok {
+		return nil, fmt.Errorf("no provider for channel %s", msg.Channel)
+	}
+
+	if msg.Template != "" {
+		rendered := *msg
+		var err error
+		if rendered.Subject, rendered.Body, rendered.HTML, err = s.templates.render(msg.Template, msg.Data); err != nil {
+			return nil, err
+		}
+		msg = &rendered
+	}
+
+	// An attempt abandoned on ctx cancellation may still be running, hence the atomics.
+	var attempts atomic.Int32
+	var providerMessageID atomic.Value
+	err := execute.RunWithRetry(ctx, s.retry, func(ctx context.Context) error {
+		attempts.Add(1)
+		id, err := p.Deliver(ctx, msg)
+		if err == nil {
+			providerMessageID.Store(id)
+		}
+		return err
+	})
+
+	receipt := &notify.Receipt{Provider: p.Name(), Attempts: int(attempts.Load())}
+	if id, ok := providerMessageID.Load().(string); ok {
+		receipt.ProviderMessageID = id
+	}
+	if err != nil {
+		receipt.Status = notify.StatusFailed
+		receipt.Error = err.Error()
+		logs.CtxWarnf(ctx, "[notify] deliver %s via %s failed after %d attempts: %v", msg.Channel, p.Name(), receipt.Attempts, err)
+	} else {
+		receipt.Status = notify.StatusSent
+		receipt.SentAt = s.now()
+	}
+
+	for _, r := range s.reporters {
+		r(ctx, msg, receipt)
+	}
+	return receipt, err
+}
@@ -0,0 +1,96 @@
+package notify
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/me2seeks/forge/infra/contract/notify"
+	"github.com/me2seeks/forge/infra/impl/notify/webhook"
+	"github.com/me2seeks/forge/retry"
+)
+
+type fakeProvider struct {
+	failures int
+	got      []*notify.Message
+}
+
+func (p *fakeProvider) Name() string            { return "fake" }
+func (p *fakeProvider) Channel() notify.Channel { return notify.ChannelEmail }
+
+func (p *fakeProvider) Deliver(_ context.Context, msg *notify.Message) (string, error) {
+	if p.failures > 0 {
+		p.failures--
+		return "", errors.New("unavailable")
+	}
+	p.got = append(p.got, msg)
+	return "id-1", nil
+}
+
+func TestSend(t *testing.T) {
+	ctx := context.Background()
+	templates := NewTemplates()
+	require.NoError(t, templates.Add("welcome", "Welcome {{.Name}}", "<p>Hi {{.Name}}</p>", true))
+
+	provider := &fakeProvider{failures: 1}
+	var receipts []*notify.Receipt
+	s := New([]notify.Provider{provider},
+		WithTemplates(templates),
+		WithRetry(retry.Policy{MaxAttempts: 2}),
+		WithStatusReporter(func(_ context.Context, _ *notify.Message, r *notify.Receipt) {
+			receipts = append(receipts, r)
+		}),
+	)
+
+	receipt, err := s.Send(ctx, &notify.Message{
+		Channel:  notify.ChannelEmail,
+		To:       []string{"a@example.com"},
+		Template: "welcome",
+		Data:     map[string]any{"Name": "<Ann>"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, notify.StatusSent, receipt.Status)
+	assert.Equal(t, 2, receipt.Attempts)
+	assert.Equal(t, "id-1", receipt.ProviderMessageID)
+	assert.Equal(t, []*notify.Receipt{receipt}, receipts)
+
+	require.Len(t, provider.got, 1)
+	assert.Equal(t, "Welcome <Ann>", provider.got[0].Subject)
+	assert.Equal(t, "<p>Hi &lt;Ann&gt;</p>", provider.got[0].Body)
+	assert.True(t, provider.got[0].HTML)
+
+	provider.failures = 2
+	receipt, err = s.Send(ctx, &notify.Message{Channel: notify.ChannelEmail, Body: "x"})
+	assert.Error(t, err)
+	assert.Equal(t, notify.StatusFailed, receipt.Status)
+	assert.Equal(t, "unavailable", receipt.Error)
+
+	_, err = s.Send(ctx, &notify.Message{Channel: notify.ChannelSMS})
+	assert.Error(t, err)
+}
+
+func TestWebhook(t *testing.T) {
+	var body string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		buf, _ := io.ReadAll(r.Body)
+		body = string(buf)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer srv.Close()
+
+	s := New([]notify.Provider{webhook.New(srv.URL, webhook.WithChannel(notify.ChannelSMS))})
+	receipt, err := s.Send(context.Background(), &notify.Message{
+		Channel: notify.ChannelSMS,
+		To:      []string{"+10000000000"},
+		Body:    "code 1234",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "webhook", receipt.Provider)
+	assert.JSONEq(t, `{"channel":"sms","to":["+10000000000"],"body":"code 1234"}`, body)
+}
@@ -0,0 +1,75 @@
+package ses
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/credentials"
+	"github.com/aws/aws-sdk-go-v2/service/sesv2"
+	"github.com/aws/aws-sdk-go-v2/service/sesv2/types"
+
+	"github.com/me2seeks/forge/infra/contract/notify"
+)
+
+type sesProvider struct {
+	client *sesv2.Client
+	from   string
+}
+
+// New creates an email provider sending through Amazon SES in region.
+func New(ctx context.Context, ak, sk, region, from string) (notify.Provider, error) {
+	cfg, err := config.LoadDefaultConfig(ctx,
+		config.WithCredentialsProvider(credentials.NewStaticCredentialsProvider(ak, sk, "")),
+		config.WithRegion(region),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("init config failed, region: %s, err: %v", region, err)
+	}
+	return NewFromClient(sesv2.NewFromConfig(cfg), from), nil
+}
+
+// NewFromClient creates an email provider on an existing SES client.
+func NewFromClient(client *sesv2.Client, from string) notify.Provider {
+	return &sesProvider{client: client, from: from}
+}
+
+func (p *sesProvider) Name() string {
+	return "ses"
+}
+
+func (p *sesProvider) Channel() notify.Channel {
+	return notify.ChannelEmail
+}
+
+func (p *sesProvider) Deliver(ctx context.Context, msg *notify.Message) (string, error) {
+	body := &types.Body{}
+	content := &types.Content{Data: aws.String(msg.Body), Charset: aws.String("UTF-8")}
+	if msg.HTML {
+		body.Html = content
+	} else {
+		body.Text = content
+	}
+
+	tags := make([]types.MessageTag, 0, len(msg.Metadata))
+	for k, v := range msg.Metadata {
+		tags = append(tags, types.MessageTag{Name: aws.String(k), Value: aws.String(v)})
+	}
+
+	out, err := p.client.SendEmail(ctx, &sesv2.SendEmailInput{
+		FromEmailAddress: aws.String(p.from),
+		Destination:      &types.Destination{ToAddresses: msg.To},
+		Content: &types.EmailContent{
+			Simple: &types.Message{
+				Subject: &types.Content{Data: aws.String(msg.Subject), Charset: aws.String("UTF-8")},
+				Body:    body,
+			},
+		},
+		EmailTags: tags,
+	})
+	if err != nil {
+		return "", fmt.Errorf("ses: send email failed: %w", err)
+	}
+	return aws.ToString(out.MessageId), nil
+}
@@ -0,0 +1,145 @@
+package smtp
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"fmt"
+	"mime"
+	"net"
+	"net/smtp"
+	"strings"
+	"time"
+
+	"github.com/me2seeks/forge/idgen"
+	"github.com/me2seeks/forge/infra/contract/notify"
+)
+
+type smtpProvider struct {
+	addr     string
+	host     string
+	from     string
+	auth     smtp.Auth
+	implicit bool
+}
+
+// Option is a function that configures the smtp provider
+type Option func(*smtpProvider)
+
+// WithAuth authenticates with PLAIN auth, which net/smtp only allows over TLS or to localhost.
+func WithAuth(username, password string) Option {
+	return func(p *smtpProvider) {
+		p.auth = smtp.PlainAuth("", username, password, p.host)
+	}
+}
+
+// WithImplicitTLS connects over TLS from the start, usually on port 465, instead
+// of upgrading with STARTTLS when the server offers it.
+func WithImplicitTLS() Option {
+	return func(p *smtpProvider) {
+		p.implicit = true
+	}
+}
+
+// New creates an email provider sending through the SMTP server at addr (host:port).
+func New(addr, from string, opts ...Option) (notify.Provider, error) {
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		return nil, fmt.Errorf("invalid smtp addr %s: %w", addr, err)
+	}
+	p := &smtpProvider{addr: addr, host: host, from: from}
+	for _, opt := range opts {
+		opt(p)
+	}
+	return p, nil
+}
+
+func (p *smtpProvider) Name() string {
+	return "smtp"
+}
+
+func (p *smtpProvider) Channel() notify.Channel {
+	return notify.ChannelEmail
+}
+
+func (p *smtpProvider) Deliver(ctx context.Context, msg *notify.Message) (string, error) {
+	if len(msg.To) == 0 {
+		return "", fmt.Errorf("smtp: no recipients")
+	}
+
+	var conn net.Conn
+	var err error
+	d := &net.Dialer{}
+	if p.implicit {
+		conn, err = (&tls.Dialer{NetDialer: d, Config: &tls.Config{ServerName: p.host}}).DialContext(ctx, "tcp", p.addr)
+	} else {
+		conn, err = d.DialContext(ctx, "tcp", p.addr)
+	}
+	if err != nil {
+		return "", fmt.Errorf("smtp: dial %s failed: %w", p.addr, err)
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = conn.SetDeadline(deadline)
+	}
+
+	c, err := smtp.NewClient(conn, p.host)
+	if err != nil {
+		_ = conn.Close()
+		return "", fmt.Errorf("smtp: %w", err)
+	}
+	defer c.Close()
+
+	if !p.implicit {
+		if ok, _ := c.Extension("STARTTLS"); ok {
+			if err = c.StartTLS(&tls.Config{ServerName: p.host}); err != nil {
+				return "", fmt.Errorf("smtp: starttls failed: %w", err)
+			}
+		}
+	}
+	if p.auth != nil {
+		if err = c.Auth(p.auth); err != nil {
+			return "", fmt.Errorf("smtp: auth failed: %w", err)
+		}
+	}
+
+	if err = c.Mail(p.from); err != nil {
+		return "", fmt.Errorf("smtp: mail from failed: %w", err)
+	}
+	for _, to := range msg.To {
+		if err = c.Rcpt(to); err != nil {
+			return "", fmt.Errorf("smtp: rcpt %s failed: %w", to, err)
+		}
+	}
+
+	id := fmt.Sprintf("<%s@%s>", idgen.NewULID(), p.host)
+	w, err := c.Data()
+	if err != nil {
+		return "", fmt.Errorf("smtp: data failed: %w", err)
+	}
+	if _, err = w.Write(p.compose(id, msg)); err != nil {
+		return "", fmt.Errorf("smtp: write message failed: %w", err)
+	}
+	if err = w.Close(); err != nil {
+		return "", fmt.Errorf("smtp: send message failed: %w", err)
+	}
+	return id, c.Quit()
+}
+
+func (p *smtpProvider) compose(id string, msg *notify.Message) []byte {
+	contentType := "text/plain"
+	if msg.HTML {
+		contentType = "text/html"
+	}
+
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "From: %s\r\n", p.from)
+	fmt.Fprintf(&buf, "To: %s\r\n", strings.Join(msg.To, ", "))
+	fmt.Fprintf(&buf, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", msg.Subject))
+	fmt.Fprintf(&buf, "Message-ID: %s\r\n", id)
+	fmt.Fprintf(&buf, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	buf.WriteString("MIME-Version: 1.0\r\n")
+	fmt.Fprintf(&buf, "Content-Type: %s; charset=utf-8\r\n", contentType)
+	buf.WriteString("Content-Transfer-Encoding: 8bit\r\n\r\n")
+	buf.WriteString(strings.ReplaceAll(msg.Body, "\n", "\r\n"))
+	return buf.Bytes()
+}
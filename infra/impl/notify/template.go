@@ -0,0 +1,75 @@
+package notify
+
+import (
+	"bytes"
+	"fmt"
+	htmltemplate "html/template"
+	"sync"
+	"text/template"
+)
+
+// Templates holds the named templates messages refer to through Message.Template.
+// HTML bodies are rendered with html/template so Data is escaped.
+type Templates struct {
+	mu        sync.RWMutex
+	templates map[string]*msgTemplate
+}
+
+type msgTemplate struct {
+	subject *template.Template
+	text    *template.Template
+	html    *htmltemplate.Template
+}
+
+func NewTemplates() *Templates {
+	return &Templates{templates: make(map[string]*msgTemplate)}
+}
+
+// Add parses and registers the template name, replacing any previous one.
+func (t *Templates) Add(name, subject, body string, html bool) error {
+	mt := &msgTemplate{}
+	var err error
+	if mt.subject, err = template.New(name + ".subject").Option("missingkey=error").Parse(subject); err != nil {
+		return fmt.Errorf("parse subject of template %s failed: %w", name, err)
+	}
+	if html {
+		mt.html, err = htmltemplate.New(name).Option("missingkey=error").Parse(body)
+	} else {
+		mt.text, err = template.New(name).Option("missingkey=error").Parse(body)
+	}
+	if err != nil {
+		return fmt.Errorf("parse body of template %s failed: %w", name, err)
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.templates[name] = mt
+	return nil
+}
+
+// render returns the subject and body of template name for data, and whether the body is HTML.
+func (t *Templates) render(name string, data map[string]any) (subject, body string, html bool, err error) {
+	t.mu.RLock()
+	mt, ok := t.templates[name]
+	t.mu.RUnlock()
+	if !ok {
+		return "", "", false, fmt.Errorf("template %s not found", name)
+	}
+
+	var buf bytes.Buffer
+	if err = mt.subject.Execute(&buf, data); err != nil {
+		return "", "", false, fmt.Errorf("render subject of template %s failed: %w", name, err)
+	}
+	subject = buf.String()
+
+	buf.Reset()
+	if mt.html != nil {
+		err = mt.html.Execute(&buf, data)
+	} else {
+		err = mt.text.Execute(&buf, data)
+	}
+	if err != nil {
+		return "", "", false, fmt.Errorf("render body of template %s failed: %w", name, err)
+	}
+	return subject, buf.String(), mt.html != nil, nil
+}
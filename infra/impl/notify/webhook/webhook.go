@@ -0,0 +1,108 @@
+package webhook
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/me2seeks/forge/httpclient"
+	"github.com/me2seeks/forge/infra/contract/notify"
+)
+
+type webhookProvider struct {
+	name    string
+	url     string
+	channel notify.Channel
+	client  *httpclient.Client
+}
+
+// Option is a function that configures the webhook provider
+type Option func(*webhookProvider)
+
+// WithChannel makes the provider serve ch, e.g. ChannelSMS for an SMS gateway.
+// Defaults to ChannelWebhook.
+func WithChannel(ch notify.Channel) Option {
+	return func(p *webhookProvider) {
+		p.channel = ch
+	}
+}
+
+// WithName sets the provider name reported in receipts. Defaults to "webhook".
+func WithName(name string) Option {
+	return func(p *webhookProvider) {
+		p.name = name
+	}
+}
+
+// WithClient sets the HTTP client. Its retries add to those of the Sender.
+func WithClient(c *httpclient.Client) Option {
+	return func(p *webhookProvider) {
+		p.client = c
+	}
+}
+
+// Payload is the JSON body posted for every message.
+type Payload struct {
+	Channel  notify.Channel    `json:"channel"`
+	To       []string          `json:"to,omitempty"`
+	Subject  string            `json:"subject,omitempty"`
+	Body     string            `json:"body"`
+	HTML     bool              `json:"html,omitempty"`
+	Metadata map[string]string `json:"metadata,omitempty"`
+}
+
+type response struct {
+	ID string `json:"id"`
+}
+
+// New creates a provider posting each message as a Payload to url. If url is
+// empty, messages are posted to each of their To URLs instead.
+func New(url string, opts ...Option) notify.Provider {
+	p := &webhookProvider{
+		name:    "webhook",
+		url:     url,
+		channel: notify.ChannelWebhook,
+		client:  httpclient.New(),
+	}
+	for _, opt := range opts {
+		opt(p)
+	}
+	return p
+}
+
+func (p *webhookProvider) Name() string {
+	return p.name
+}
+
+func (p *webhookProvider) Channel() notify.Channel {
+	return p.channel
+}
+
+// Deliver returns the "id" field of the JSON response, if any.
+func (p *webhookProvider) Deliver(ctx context.Context, msg *notify.Message) (string, error) {
+	payload := &Payload{
+		Channel:  msg.Channel,
+		To:       msg.To,
+		Subject:  msg.Subject,
+		Body:     msg.Body,
+		HTML:     msg.HTML,
+		Metadata: msg.Metadata,
+	}
+
+	urls := []string{p.url}
+	if p.url == "" {
+		urls = msg.To
+		payload.To = nil
+	}
+	if len(urls) == 0 {
+		return "", fmt.Errorf("webhook: no url")
+	}
+
+	var resp response
+	for _, url := range urls {
+		if err := p.client.DoJSON(ctx, http.MethodPost, url, payload, &resp); err != nil {
+			return "", fmt.Errorf("webhook: post %s failed: %w", url, err)
+		}
+	}
+	return resp.ID, nil
+}
@@ -0,0 +1,139 @@
+package local
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/me2seeks/forge/infra/contract/ratelimit"
+	"github.com/me2seeks/forge/prelude/cache"
+)
+
+// localLimiter is a process-local token bucket limiter, one bucket per key.
+// Buckets of the least recently used keys are dropped beyond maxKeys, which only
+// forgets their history: a dropped key starts again with a full bucket.
+type localLimiter struct {
+	mu sync.Mutex
+
+	limit    ratelimit.Limit
+	keyLimit func(key string) ratelimit.Limit
+	buckets  *cache.LRU[string, *bucket]
+	now      func() time.Time
+}
+
+type bucket struct {
+	tokens float64
+	last   time.Time
+	limit  ratelimit.Limit
+}
+
+type Option func(*localLimiter)
+
+// WithKeyLimit overrides the default limit for some keys.
+func WithKeyLimit(fn func(key string) ratelimit.Limit) Option {
+	return func(l *localLimiter) {
+		l.keyLimit = fn
+	}
+}
+
+// WithMaxKeys bounds the number of buckets kept in memory. Defaults to 100000.
+func WithMaxKeys(n int) Option {
+	return func(l *localLimiter) {
+		l.buckets = cache.NewLRU[string, *bucket](n)
+	}
+}
+
+// WithClock overrides time.Now, mainly for tests.
+func WithClock(now func() time.Time) Option {
+	return func(l *localLimiter) {
+		l.now = now
+	}
+}
+
+func New(limit ratelimit.Limit, opts ...Option) ratelimit.Limiter {
+	l := &localLimiter{
+		limit:   limit,
+		buckets: cache.NewLRU[string, *bucket](100000),
+		now:     time.Now,
+	}
+	for _, opt := range opts {
+		opt(l)
+	}
+	return l
+}
+
+func (l *localLimiter) Allow(ctx context.Context, key string) (bool, error) {
+	res, err := l.AllowN(ctx, key, 1)
+	return res.Allowed, err
+}
+
+func (l *localLimiter) AllowN(_ context.Context, key string, n int) (ratelimit.Result, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	b, err := l.bucket(key)
+	if err != nil {
+		return ratelimit.Result{}, err
+	}
+	if float64(n) > burst(b.limit) {
+		return ratelimit.Result{}, fmt.Errorf("ratelimit: %d events exceed the burst of key %s", n, key)
+	}
+
+	now := l.now()
+	rate := float64(b.limit.Events) / float64(b.limit.Period)
+	b.tokens = math.Min(burst(b.limit), b.tokens+float64(now.Sub(b.last))*rate)
+	b.last = now
+
+	if b.tokens >= float64(n) {
+		b.tokens -= float64(n)
+		return ratelimit.Result{Allowed: true, Remaining: int(b.tokens)}, nil
+	}
+	return ratelimit.Result{
+		Remaining:  int(b.tokens),
+		RetryAfter: time.Duration(math.Ceil((float64(n) - b.tokens) / rate)),
+	}, nil
+}
+
+func (l *localLimiter) Wait(ctx context.Context, key string) error {
+	for {
+		res, err := l.AllowN(ctx, key, 1)
+		if err != nil || res.Allowed {
+			return err
+		}
+
+		timer := time.NewTimer(res.RetryAfter)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+func (l *localLimiter) bucket(key string) (*bucket, error) {
+	if b, ok := l.buckets.Get(key); ok {
+		return b, nil
+	}
+
+	limit := l.limit
+	if l.keyLimit != nil {
+		limit = l.keyLimit(key)
+	}
+	if limit.Events <= 0 || limit.Period <= 0 {
+		return nil, fmt.Errorf("ratelimit: invalid limit %+v for key %s", limit, key)
+	}
+
+	b := &bucket{tokens: burst(limit), last: l.now(), limit: limit}
+	l.buckets.Set(key, b)
+	return b, nil
+}
+
+func burst(limit ratelimit.Limit) float64 {
+	if limit.Burst > 0 {
+		return float64(limit.Burst)
+	}
+	return float64(limit.Events)
+}
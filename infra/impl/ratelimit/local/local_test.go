@@ -0,0 +1,56 @@
+package local
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/me2seeks/forge/infra/contract/ratelimit"
+)
+
+func TestLocalLimiter(t *testing.T) {
+	ctx := context.Background()
+	now := time.Unix(0, 0)
+	l := New(ratelimit.PerSecond(2),
+		WithClock(func() time.Time { return now }),
+		WithKeyLimit(func(key string) ratelimit.Limit {
+			if key == "vip" {
+				return ratelimit.PerSecond(10)
+			}
+			return ratelimit.PerSecond(2)
+		}),
+	)
+
+	for i := 0; i < 2; i++ {
+		ok, err := l.Allow(ctx, "user")
+		assert.NoError(t, err)
+		assert.True(t, ok)
+	}
+	res, err := l.AllowN(ctx, "user", 1)
+	assert.NoError(t, err)
+	assert.False(t, res.Allowed)
+	assert.Equal(t, 500*time.Millisecond, res.RetryAfter)
+
+	res, err = l.AllowN(ctx, "vip", 5)
+	assert.NoError(t, err)
+	assert.Equal(t, ratelimit.Result{Allowed: true, Remaining: 5}, res)
+
+	now = now.Add(500 * time.Millisecond)
+	ok, err := l.Allow(ctx, "user")
+	assert.NoError(t, err)
+	assert.True(t, ok)
+
+	_, err = l.AllowN(ctx, "user", 3)
+	assert.Error(t, err)
+}
+
+func TestLocalLimiter_Wait(t *testing.T) {
+	l := New(ratelimit.Limit{Events: 1, Period: 10 * time.Millisecond})
+	start := time.Now()
+	for i := 0; i < 3; i++ {
+		assert.NoError(t, l.Wait(context.Background(), "k"))
+	}
+	assert.GreaterOrEqual(t, time.Since(start), 15*time.Millisecond)
+}
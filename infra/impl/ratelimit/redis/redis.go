@@ -0,0 +1,112 @@
+package redis
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+
+	"github.com/me2seeks/forge/idgen"
+	"github.com/me2seeks/forge/infra/contract/ratelimit"
+)
+
+// allowScript drops the events of the sliding window log KEYS[1] older than
+// ARGV[1] milliseconds, then adds ARGV[3] events named after ARGV[4] if they
+// fit in the limit ARGV[2]. It returns whether they were added, the events
+// still allowed and, if denied, the milliseconds until enough events leave the
+// window. Times are read from the server, so that the clocks of the processes
+// sharing a key don't matter.
+var allowScript = redis.NewScript(`
+local t = redis.call("TIME")
+local now = tonumber(t[1]) * 1000 + math.floor(tonumber(t[2]) / 1000)
+local window, limit, n = tonumber(ARGV[1]), tonumber(ARGV[2]), tonumber(ARGV[3])
+redis.call("ZREMRANGEBYSCORE", KEYS[1], "-inf", now - window)
+local count = redis.call("ZCARD", KEYS[1])
+if count + n <= limit then
+	for i = 1, n do
+		redis.call("ZADD", KEYS[1], now, ARGV[4] .. ":" .. i)
+	end
+	redis.call("PEXPIRE", KEYS[1], window)
+	return {1, limit - count - n, 0}
+end
+local i = count + n - limit - 1
+local oldest = redis.call("ZRANGE", KEYS[1], i, i, "WITHSCORES")
+return {0, limit - count, tonumber(oldest[2]) + window - now}`)
+
+// redisLimiter is a sliding window limiter shared by the processes using the
+// same Redis: each key is a sorted set of the events of the last period, and
+// a check is a single script, so concurrent checks can't both take the last
+// events. Unlike the token bucket of the local limiter, Burst is ignored: a
+// window holds up to Events events, however close.
+type redisLimiter struct {
+	client   redis.UniversalClient
+	limit    ratelimit.Limit
+	keyLimit func(key string) ratelimit.Limit
+}
+
+type Option func(*redisLimiter)
+
+// WithKeyLimit overrides the default limit for some keys.
+func WithKeyLimit(fn func(key string) ratelimit.Limit) Option {
+	return func(l *redisLimiter) {
+		l.keyLimit = fn
+	}
+}
+
+func New(client redis.UniversalClient, limit ratelimit.Limit, opts ...Option) ratelimit.Limiter {
+	l := &redisLimiter{
+		client: client,
+		limit:  limit,
+	}
+	for _, opt := range opts {
+		opt(l)
+	}
+	return l
+}
+
+func (l *redisLimiter) Allow(ctx context.Context, key string) (bool, error) {
+	res, err := l.AllowN(ctx, key, 1)
+	return res.Allowed, err
+}
+
+func (l *redisLimiter) AllowN(ctx context.Context, key string, n int) (ratelimit.Result, error) {
+	limit := l.limit
+	if l.keyLimit != nil {
+		limit = l.keyLimit(key)
+	}
+	if limit.Events <= 0 || limit.Period < time.Millisecond {
+		return ratelimit.Result{}, fmt.Errorf("ratelimit: invalid limit %+v for key %s", limit, key)
+	}
+	if n > limit.Events {
+		return ratelimit.Result{}, fmt.Errorf("ratelimit: %d events exceed the limit of key %s", n, key)
+	}
+
+	out, err := allowScript.Run(ctx, l.client, []string{"forge:ratelimit:" + key},
+		limit.Period.Milliseconds(), limit.Events, n, idgen.NewULID()).Int64Slice()
+	if err != nil {
+		return ratelimit.Result{}, err
+	}
+	return ratelimit.Result{
+		Allowed:    out[0] == 1,
+		Remaining:  max(int(out[1]), 0),
+		RetryAfter: time.Duration(out[2]) * time.Millisecond,
+	}, nil
+}
+
+func (l *redisLimiter) Wait(ctx context.Context, key string) error {
+	for {
+		res, err := l.AllowN(ctx, key, 1)
+		if err != nil || res.Allowed {
+			return err
+		}
+
+		timer := time.NewTimer(res.RetryAfter)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
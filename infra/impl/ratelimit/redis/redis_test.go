@@ -0,0 +1,90 @@
+package redis
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/me2seeks/forge/infra/contract/ratelimit"
+)
+
+func newClient(t *testing.T, mr *miniredis.Miniredis) redis.UniversalClient {
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { _ = client.Close() })
+	return client
+}
+
+func TestRedisLimiter(t *testing.T) {
+	ctx := context.Background()
+	mr := miniredis.RunT(t)
+	now := time.Unix(1000, 0)
+	mr.SetTime(now)
+	l := New(newClient(t, mr), ratelimit.PerSecond(2),
+		WithKeyLimit(func(key string) ratelimit.Limit {
+			if key == "vip" {
+				return ratelimit.PerSecond(10)
+			}
+			return ratelimit.PerSecond(2)
+		}),
+	)
+
+	ok, err := l.Allow(ctx, "user")
+	require.NoError(t, err)
+	assert.True(t, ok)
+	mr.SetTime(now.Add(400 * time.Millisecond))
+	ok, err = l.Allow(ctx, "user")
+	require.NoError(t, err)
+	assert.True(t, ok)
+	res, err := l.AllowN(ctx, "user", 1)
+	require.NoError(t, err)
+	assert.Equal(t, ratelimit.Result{RetryAfter: 600 * time.Millisecond}, res)
+
+	res, err = l.AllowN(ctx, "vip", 5)
+	require.NoError(t, err)
+	assert.Equal(t, ratelimit.Result{Allowed: true, Remaining: 5}, res)
+
+	// The first event leaves the window, the second doesn't.
+	mr.SetTime(now.Add(time.Second))
+	ok, err = l.Allow(ctx, "user")
+	require.NoError(t, err)
+	assert.True(t, ok)
+	res, err = l.AllowN(ctx, "user", 1)
+	require.NoError(t, err)
+	assert.Equal(t, ratelimit.Result{RetryAfter: 400 * time.Millisecond}, res)
+
+	_, err = l.AllowN(ctx, "user", 3)
+	assert.Error(t, err)
+}
+
+func TestRedisLimiter_Shared(t *testing.T) {
+	ctx := context.Background()
+	mr := miniredis.RunT(t)
+	a := New(newClient(t, mr), ratelimit.PerMinute(3))
+	b := New(newClient(t, mr), ratelimit.PerMinute(3))
+
+	res, err := a.AllowN(ctx, "tenant", 2)
+	require.NoError(t, err)
+	assert.Equal(t, ratelimit.Result{Allowed: true, Remaining: 1}, res)
+	res, err = b.AllowN(ctx, "tenant", 2)
+	require.NoError(t, err)
+	assert.False(t, res.Allowed)
+	assert.Equal(t, 1, res.Remaining)
+	ok, err := b.Allow(ctx, "tenant")
+	require.NoError(t, err)
+	assert.True(t, ok)
+}
+
+func TestRedisLimiter_Wait(t *testing.T) {
+	mr := miniredis.RunT(t)
+	l := New(newClient(t, mr), ratelimit.Limit{Events: 1, Period: 10 * time.Millisecond})
+	start := time.Now()
+	for i := 0; i < 3; i++ {
+		assert.NoError(t, l.Wait(context.Background(), "k"))
+	}
+	assert.GreaterOrEqual(t, time.Since(start), 15*time.Millisecond)
+}
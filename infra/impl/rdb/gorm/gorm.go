@@ -0,0 +1,186 @@
+package gorm
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+
+	"github.com/me2seeks/forge/infra/contract/rdb"
+)
+
+type gormDB struct {
+	db   *gorm.DB
+	inTx bool
+}
+
+// Option is a function that configures the gorm client
+type Option func(*options)
+
+type options struct {
+	logLevel        logger.LogLevel
+	slowThreshold   time.Duration
+	maxOpenConns    int
+	maxIdleConns    int
+	connMaxLifetime time.Duration
+	config          *gorm.Config
+}
+
+// WithLogLevel sets the level of the logs-backed gorm logger. Defaults to logger.Warn.
+func WithLogLevel(level logger.LogLevel) Option {
+	return func(o *options) {
+		o.logLevel = level
+	}
+}
+
+// WithSlowThreshold sets the duration above which queries are logged as slow. Defaults to 200ms.
+func WithSlowThreshold(d time.Duration) Option {
+	return func(o *options) {
+		o.slowThreshold = d
+	}
+}
+
+// WithMaxOpenConns limits the number of open connections in the pool.
+func WithMaxOpenConns(n int) Option {
+	return func(o *options) {
+		o.maxOpenConns = n
+	}
+}
+
+// WithMaxIdleConns limits the number of idle connections kept in the pool.
+func WithMaxIdleConns(n int) Option {
+	return func(o *options) {
+		o.maxIdleConns = n
+	}
+}
+
+// WithConnMaxLifetime sets the maximum time a connection may be reused.
+func WithConnMaxLifetime(d time.Duration) Option {
+	return func(o *options) {
+		o.connMaxLifetime = d
+	}
+}
+
+// WithConfig sets the base gorm config. Its Logger is replaced by the logs-backed one.
+func WithConfig(config *gorm.Config) Option {
+	return func(o *options) {
+		o.config = config
+	}
+}
+
+// NewPostgres connects to the PostgreSQL database at dsn through pgx.
+func NewPostgres(dsn string, opts ...Option) (rdb.DB, error) {
+	return New(postgres.Open(dsn), opts...)
+}
+
+// New opens a database through the given gorm dialector.
+func New(dialector gorm.Dialector, opts ...Option) (rdb.DB, error) {
+	o := &options{
+		logLevel:      logger.Warn,
+		slowThreshold: 200 * time.Millisecond,
+		config:        &gorm.Config{},
+	}
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	config := *o.config
+	config.Logger = newLogger(o.logLevel, o.slowThreshold)
+
+	db, err := gorm.Open(dialector, &config)
+	if err != nil {
+		return nil, fmt.Errorf("open database failed: %w", err)
+	}
+
+	sqlDB, err := db.DB()
+	if err != nil {
+		return nil, fmt.Errorf("get connection pool failed: %w", err)
+	}
+	if o.maxOpenConns > 0 {
+		sqlDB.SetMaxOpenConns(o.maxOpenConns)
+	}
+	if o.maxIdleConns > 0 {
+		sqlDB.SetMaxIdleConns(o.maxIdleConns)
+	}
+	if o.connMaxLifetime > 0 {
+		sqlDB.SetConnMaxLifetime(o.connMaxLifetime)
+	}
+
+	return &gormDB{db: db}, nil
+}
+
+// Unwrap returns the *gorm.DB behind db, which is bound to the transaction if db
+// was passed to a Transaction callback. It reports false if db wasn't created by
+// this package.
+func Unwrap(db rdb.DB) (*gorm.DB, bool) {
+	g, ok := db.(*gormDB)
+	if !ok {
+		return nil, false
+	}
+	return g.db, true
+}
+
+func (g *gormDB) Exec(ctx context.Context, query string, args ...any) (int64, error) {
+	res := g.db.WithContext(ctx).Exec(query, args...)
+	return res.RowsAffected, res.Error
+}
+
+func (g *gormDB) Query(ctx context.Context, dest any, query string, args ...any) error {
+	return g.db.WithContext(ctx).Raw(query, args...).Scan(dest).Error
+}
+
+func (g *gormDB) QueryRow(ctx context.Context, dest any, query string, args ...any) error {
+	res := g.db.WithContext(ctx).Raw(query, args...).Scan(dest)
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return rdb.ErrNotFound
+	}
+	return nil
+}
+
+func (g *gormDB) Transaction(ctx context.Context, fn func(ctx context.Context, tx rdb.DB) error, opts ...rdb.TxOptFn) error {
+	var sqlOpts []*sql.TxOptions
+	if len(opts) > 0 {
+		o := &rdb.TxOption{}
+		for _, opt := range opts {
+			opt(o)
+		}
+		sqlOpts = append(sqlOpts, &sql.TxOptions{Isolation: o.Isolation, ReadOnly: o.ReadOnly})
+	}
+
+	return g.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		return fn(ctx, &gormDB{db: tx, inTx: true})
+	}, sqlOpts...)
+}
+
+func (g *gormDB) Ping(ctx context.Context) error {
+	sqlDB, err := g.db.DB()
+	if err != nil {
+		return err
+	}
+	return sqlDB.PingContext(ctx)
+}
+
+func (g *gormDB) Close() error {
+	if g.inTx {
+		return nil
+	}
+	sqlDB, err := g.db.DB()
+	if err != nil {
+		return err
+	}
+	return sqlDB.Close()
+}
+
+// IsNotFound reports whether err means that no row matched, from either the
+// contract or gorm itself.
+func IsNotFound(err error) bool {
+	return errors.Is(err, rdb.ErrNotFound) || errors.Is(err, gorm.ErrRecordNotFound)
+}
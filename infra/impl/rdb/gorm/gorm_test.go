@@ -0,0 +1,106 @@
+package gorm
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/postgres"
+
+	"github.com/me2seeks/forge/infra/contract/rdb"
+)
+
+type user struct {
+	ID   int64
+	Name string
+}
+
+func newMock(t *testing.T) (rdb.DB, sqlmock.Sqlmock) {
+	conn, mock, err := sqlmock.New()
+	require.NoError(t, err)
+
+	db, err := New(postgres.New(postgres.Config{Conn: conn}))
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+	return db, mock
+}
+
+func TestQuery(t *testing.T) {
+	ctx := context.Background()
+	db, mock := newMock(t)
+
+	mock.ExpectExec(regexp.QuoteMeta("UPDATE users SET name = $1 WHERE id = $2")).
+		WithArgs("bob", 1).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	n, err := db.Exec(ctx, "UPDATE users SET name = ? WHERE id = ?", "bob", 1)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), n)
+
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT id, name FROM users")).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name"}).AddRow(1, "bob").AddRow(2, "alice"))
+	users, err := rdb.Select[user](ctx, db, "SELECT id, name FROM users")
+	require.NoError(t, err)
+	assert.Equal(t, []user{{1, "bob"}, {2, "alice"}}, users)
+
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT id, name FROM users WHERE id = $1")).
+		WithArgs(3).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name"}))
+	_, err = rdb.Get[user](ctx, db, "SELECT id, name FROM users WHERE id = ?", 3)
+	assert.ErrorIs(t, err, rdb.ErrNotFound)
+	assert.True(t, IsNotFound(err))
+}
+
+func TestPaginate(t *testing.T) {
+	ctx := context.Background()
+	db, mock := newMock(t)
+
+	query := "SELECT id, name FROM users WHERE id > ? ORDER BY id"
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT COUNT(*) FROM (SELECT id, name FROM users WHERE id > $1 ORDER BY id) AS paginate_q")).
+		WithArgs(0).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(3))
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT id, name FROM users WHERE id > $1 ORDER BY id LIMIT $2 OFFSET $3")).
+		WithArgs(0, 2, 2).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name"}).AddRow(3, "carol"))
+
+	page, err := rdb.Paginate[user](ctx, db, rdb.Page{Number: 2, Size: 2}, query, 0)
+	require.NoError(t, err)
+	assert.Equal(t, []user{{3, "carol"}}, page.Items)
+	assert.Equal(t, int64(3), page.Total)
+	assert.False(t, page.HasNext())
+
+	// Pages past the end skip the row query.
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT COUNT(*)")).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(3))
+	page, err = rdb.Paginate[user](ctx, db, rdb.Page{Number: 5, Size: 2}, query, 0)
+	require.NoError(t, err)
+	assert.Empty(t, page.Items)
+}
+
+func TestTransaction(t *testing.T) {
+	ctx := context.Background()
+	db, mock := newMock(t)
+
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta("DELETE FROM users")).WillReturnResult(sqlmock.NewResult(0, 2))
+	mock.ExpectCommit()
+	err := db.Transaction(ctx, func(ctx context.Context, tx rdb.DB) error {
+		_, err := tx.Exec(ctx, "DELETE FROM users")
+		return err
+	})
+	require.NoError(t, err)
+
+	errBoom := errors.New("boom")
+	mock.ExpectBegin()
+	mock.ExpectRollback()
+	err = db.Transaction(ctx, func(ctx context.Context, tx rdb.DB) error {
+		assert.NoError(t, tx.Close())
+		return errBoom
+	})
+	assert.ErrorIs(t, err, errBoom)
+}
@@ -0,0 +1,66 @@
+package gorm
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+
+	"github.com/me2seeks/forge/logs"
+)
+
+// gormLogger routes gorm's logs through the logs package so they carry the
+// same context fields as the rest of the service.
+type gormLogger struct {
+	level         logger.LogLevel
+	slowThreshold time.Duration
+}
+
+func newLogger(level logger.LogLevel, slowThreshold time.Duration) logger.Interface {
+	return &gormLogger{level: level, slowThreshold: slowThreshold}
+}
+
+func (l *gormLogger) LogMode(level logger.LogLevel) logger.Interface {
+	n := *l
+	n.level = level
+	return &n
+}
+
+func (l *gormLogger) Info(ctx context.Context, format string, v ...any) {
+	if l.level >= logger.Info {
+		logs.CtxInfof(ctx, format, v...)
+	}
+}
+
+func (l *gormLogger) Warn(ctx context.Context, format string, v ...any) {
+	if l.level >= logger.Warn {
+		logs.CtxWarnf(ctx, format, v...)
+	}
+}
+
+func (l *gormLogger) Error(ctx context.Context, format string, v ...any) {
+	if l.level >= logger.Error {
+		logs.CtxErrorf(ctx, format, v...)
+	}
+}
+
+func (l *gormLogger) Trace(ctx context.Context, begin time.Time, fc func() (string, int64), err error) {
+	if l.level <= logger.Silent {
+		return
+	}
+
+	elapsed := time.Since(begin)
+	switch {
+	case err != nil && l.level >= logger.Error && !errors.Is(err, gorm.ErrRecordNotFound):
+		query, rows := fc()
+		logs.CtxErrorf(ctx, "[rdb] %v [%v] [rows:%d] %s", err, elapsed, rows, query)
+	case l.slowThreshold > 0 && elapsed > l.slowThreshold && l.level >= logger.Warn:
+		query, rows := fc()
+		logs.CtxWarnf(ctx, "[rdb] slow query >= %v [%v] [rows:%d] %s", l.slowThreshold, elapsed, rows, query)
+	case l.level >= logger.Info:
+		query, rows := fc()
+		logs.CtxDebugf(ctx, "[rdb] [%v] [rows:%d] %s", elapsed, rows, query)
+	}
+}
@@ -0,0 +1,47 @@
+package rdb
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/me2seeks/forge/conf"
+	"github.com/me2seeks/forge/infra/contract/rdb"
+	"github.com/me2seeks/forge/infra/impl/rdb/gorm"
+)
+
+type DB = rdb.DB
+
+// Config selects and configures the database built by NewWithConfig.
+type Config struct {
+	Type            string        `env:"RDB_TYPE" yaml:"type" json:"type" default:"postgres"`
+	DSN             string        `env:"RDB_DSN" yaml:"dsn" json:"dsn" required:"true"`
+	MaxOpenConns    int           `env:"RDB_MAX_OPEN_CONNS" yaml:"max_open_conns" json:"max_open_conns"`
+	MaxIdleConns    int           `env:"RDB_MAX_IDLE_CONNS" yaml:"max_idle_conns" json:"max_idle_conns"`
+	ConnMaxLifetime time.Duration `env:"RDB_CONN_MAX_LIFETIME" yaml:"conn_max_lifetime" json:"conn_max_lifetime"`
+	SlowThreshold   time.Duration `env:"RDB_SLOW_THRESHOLD" yaml:"slow_threshold" json:"slow_threshold" default:"200ms"`
+}
+
+// New creates the database configured by the RDB_* env vars.
+func New() (DB, error) {
+	var cfg Config
+	if err := conf.Load(&cfg); err != nil {
+		return nil, err
+	}
+	return NewWithConfig(cfg)
+}
+
+func NewWithConfig(cfg Config) (DB, error) {
+	opts := []gorm.Option{
+		gorm.WithMaxOpenConns(cfg.MaxOpenConns),
+		gorm.WithMaxIdleConns(cfg.MaxIdleConns),
+		gorm.WithConnMaxLifetime(cfg.ConnMaxLifetime),
+		gorm.WithSlowThreshold(cfg.SlowThreshold),
+	}
+
+	switch cfg.Type {
+	case "postgres":
+		return gorm.NewPostgres(cfg.DSN, opts...)
+	}
+
+	return nil, fmt.Errorf("unknown rdb type: %s", cfg.Type)
+}
@@ -3,48 +3,79 @@ package storage
 import (
 	"context"
 	"fmt"
-	"os"
 
+	"github.com/me2seeks/forge/conf"
 	"github.com/me2seeks/forge/infra/contract/storage"
 	"github.com/me2seeks/forge/infra/impl/storage/minio"
 	"github.com/me2seeks/forge/infra/impl/storage/s3"
 	"github.com/me2seeks/forge/infra/impl/storage/tos"
-	"github.com/me2seeks/forge/types/consts"
 )
 
 type Storage = storage.Storage
 
+// Config selects and configures the backend built by NewWithConfig.
+type Config struct {
+	Type   string `env:"STORAGE_TYPE" yaml:"type" json:"type" required:"true"`
+	Bucket string `env:"STORAGE_BUCKET" yaml:"bucket" json:"bucket"`
+	MinIO  struct {
+		Endpoint string `env:"MINIO_ENDPOINT" yaml:"endpoint" json:"endpoint"`
+		AK       string `env:"MINIO_AK" yaml:"ak" json:"ak"`
+		SK       string `env:"MINIO_SK" yaml:"sk" json:"sk"`
+		UseSSL   bool   `yaml:"use_ssl" json:"use_ssl"`
+	} `yaml:"minio" json:"minio"`
+	TOS struct {
+		AccessKey string `env:"TOS_ACCESS_KEY" yaml:"access_key" json:"access_key"`
+		SecretKey string `env:"TOS_SECRET_KEY" yaml:"secret_key" json:"secret_key"`
+		Endpoint  string `env:"TOS_ENDPOINT" yaml:"endpoint" json:"endpoint"`
+		Region    string `env:"TOS_REGION" yaml:"region" json:"region"`
+	} `yaml:"tos" json:"tos"`
+	S3 struct {
+		AccessKey string `env:"S3_ACCESS_KEY" yaml:"access_key" json:"access_key"`
+		SecretKey string `env:"S3_SECRET_KEY" yaml:"secret_key" json:"secret_key"`
+		Endpoint  string `env:"S3_ENDPOINT" yaml:"endpoint" json:"endpoint"`
+		Region    string `env:"S3_REGION" yaml:"region" json:"region"`
+	} `yaml:"s3" json:"s3"`
+}
+
+// New creates the backend configured by the STORAGE_TYPE and related env vars.
 func New(ctx context.Context) (Storage, error) {
-	storageType := os.Getenv(consts.StorageType)
-	switch storageType {
+	var cfg Config
+	if err := conf.Load(&cfg); err != nil {
+		return nil, err
+	}
+	return NewWithConfig(ctx, cfg)
+}
+
+func NewWithConfig(ctx context.Context, cfg Config) (Storage, error) {
+	switch cfg.Type {
 	case "minio":
 		return minio.New(
 			ctx,
-			os.Getenv(consts.MinIOEndpoint),
-			os.Getenv(consts.MinIOAK),
-			os.Getenv(consts.MinIOSK),
-			os.Getenv(consts.StorageBucket),
-			false,
+			cfg.MinIO.Endpoint,
+			cfg.MinIO.AK,
+			cfg.MinIO.SK,
+			cfg.Bucket,
+			cfg.MinIO.UseSSL,
 		)
 	case "tos":
 		return tos.New(
 			ctx,
-			os.Getenv(consts.TOSAccessKey),
-			os.Getenv(consts.TOSSecretKey),
-			os.Getenv(consts.StorageBucket),
-			os.Getenv(consts.TOSEndpoint),
-			os.Getenv(consts.TOSRegion),
+			cfg.TOS.AccessKey,
+			cfg.TOS.SecretKey,
+			cfg.Bucket,
+			cfg.TOS.Endpoint,
+			cfg.TOS.Region,
 		)
 	case "s3":
 		return s3.New(
 			ctx,
-			os.Getenv(consts.S3AccessKey),
-			os.Getenv(consts.S3SecretKey),
-			os.Getenv(consts.StorageBucket),
-			os.Getenv(consts.S3Endpoint),
-			os.Getenv(consts.S3Region),
+			cfg.S3.AccessKey,
+			cfg.S3.SecretKey,
+			cfg.Bucket,
+			cfg.S3.Endpoint,
+			cfg.S3.Region,
 		)
 	}
 
-	return nil, fmt.Errorf("unknown storage type: %s", storageType)
+	return nil, fmt.Errorf("unknown storage type: %s", cfg.Type)
 }
@@ -0,0 +1,224 @@
+// Package jobs runs durable background jobs, such as graph imports or storage
+// migrations, stored through the rdb contract. Jobs move through a small state
+// machine, are retried with backoff and are claimed by workers under a lease,
+// so a job whose worker died is picked up again once its lease expires.
+package jobs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/me2seeks/forge/idgen"
+	"github.com/me2seeks/forge/infra/contract/rdb"
+)
+
+// Schema creates the jobs table on PostgreSQL.
+const Schema = `
+CREATE TABLE IF NOT EXISTS jobs (
+	id           VARCHAR(32)  PRIMARY KEY,
+	type         VARCHAR(255) NOT NULL,
+	payload      BYTEA        NOT NULL,
+	state        VARCHAR(16)  NOT NULL,
+	attempts     INT          NOT NULL DEFAULT 0,
+	max_attempts INT          NOT NULL,
+	last_error   TEXT         NOT NULL DEFAULT '',
+	run_at       TIMESTAMP    NOT NULL,
+	locked_by    VARCHAR(64)  NOT NULL DEFAULT '',
+	locked_until TIMESTAMP,
+	created_at   TIMESTAMP    NOT NULL,
+	updated_at   TIMESTAMP    NOT NULL
+);
+CREATE INDEX IF NOT EXISTS idx_jobs_due ON jobs (state, run_at);
+`
+
+var (
+	ErrNotFound = errors.New("jobs: job not found")
+	// ErrInvalidTransition is returned when a job can't move to the requested state.
+	ErrInvalidTransition = errors.New("jobs: invalid state transition")
+)
+
+type State string
+
+const (
+	StatePending   State = "pending"
+	StateRunning   State = "running"
+	StateSucceeded State = "succeeded"
+	// StateDead means the job failed on its last attempt.
+	StateDead     State = "dead"
+	StateCanceled State = "canceled"
+)
+
+// transitions lists the states each state may move to. A running job goes back
+// to pending to be retried, or when its lease expires.
+var transitions = map[State][]State{
+	StatePending: {StateRunning, StateCanceled},
+	StateRunning: {StateSucceeded, StatePending, StateDead},
+}
+
+// CanTransition reports whether a job may move from one state to the other.
+func CanTransition(from, to State) bool {
+	for _, s := range transitions[from] {
+		if s == to {
+			return true
+		}
+	}
+	return false
+}
+
+// Terminal reports whether no transition leaves s.
+func (s State) Terminal() bool {
+	return len(transitions[s]) == 0
+}
+
+type Job struct {
+	ID          string
+	Type        string
+	Payload     []byte
+	State       State
+	Attempts    int
+	MaxAttempts int
+	LastError   string
+	// RunAt is when the job is due, for its first run or its next retry.
+	RunAt       time.Time
+	LockedBy    string
+	LockedUntil *time.Time
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+}
+
+const jobColumns = "id, type, payload, state, attempts, max_attempts, last_error, run_at, locked_by, locked_until, created_at, updated_at"
+
+// Client enqueues and queries jobs.
+type Client struct {
+	db  rdb.DB
+	now func() time.Time
+}
+
+func NewClient(db rdb.DB) *Client {
+	return &Client{db: db, now: time.Now}
+}
+
+type enqueueOptions struct {
+	id          string
+	runAt       time.Time
+	maxAttempts int
+	tx          rdb.DB
+}
+
+type EnqueueOption func(*enqueueOptions)
+
+// WithID sets the job ID, making Enqueue fail if a job with that ID exists.
+func WithID(id string) EnqueueOption {
+	return func(o *enqueueOptions) {
+		o.id = id
+	}
+}
+
+// WithRunAt schedules the job to run no earlier than t.
+func WithRunAt(t time.Time) EnqueueOption {
+	return func(o *enqueueOptions) {
+		o.runAt = t
+	}
+}
+
+// WithDelay schedules the job to run no earlier than d from now.
+func WithDelay(d time.Duration) EnqueueOption {
+	return func(o *enqueueOptions) {
+		o.runAt = time.Now().Add(d)
+	}
+}
+
+// WithMaxAttempts sets the number of runs after which a failing job is dead. Defaults to 5.
+func WithMaxAttempts(n int) EnqueueOption {
+	return func(o *enqueueOptions) {
+		o.maxAttempts = n
+	}
+}
+
+// WithTx enqueues through tx, so the job only exists if tx commits.
+func WithTx(tx rdb.DB) EnqueueOption {
+	return func(o *enqueueOptions) {
+		o.tx = tx
+	}
+}
+
+// Enqueue stores a pending job of type typ, run by the worker handler of that type.
+func (c *Client) Enqueue(ctx context.Context, typ string, payload []byte, opts ...EnqueueOption) (*Job, error) {
+	now := c.now()
+	o := &enqueueOptions{
+		id:          idgen.NewULID(),
+		runAt:       now,
+		maxAttempts: 5,
+		tx:          c.db,
+	}
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	job := &Job{
+		ID:          o.id,
+		Type:        typ,
+		Payload:     payload,
+		State:       StatePending,
+		MaxAttempts: o.maxAttempts,
+		RunAt:       o.runAt,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+	if _, err := o.tx.Exec(ctx,
+		"INSERT INTO jobs (id, type, payload, state, max_attempts, run_at, created_at, updated_at) VALUES (?, ?, ?, ?, ?, ?, ?, ?)",
+		job.ID, job.Type, job.Payload, job.State, job.MaxAttempts, job.RunAt, job.CreatedAt, job.UpdatedAt); err != nil {
+		return nil, fmt.Errorf("insert job failed: %w", err)
+	}
+	return job, nil
+}
+
+func (c *Client) Get(ctx context.Context, id string) (*Job, error) {
+	job, err := rdb.Get[Job](ctx, c.db, "SELECT "+jobColumns+" FROM jobs WHERE id = ?", id)
+	if errors.Is(err, rdb.ErrNotFound) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &job, nil
+}
+
+// Filter selects jobs in List. Zero fields match everything.
+type Filter struct {
+	Type  string
+	State State
+}
+
+// List returns a page of the jobs matching filter, newest first.
+func (c *Client) List(ctx context.Context, filter Filter, page rdb.Page) (*rdb.PageResult[Job], error) {
+	query := "SELECT " + jobColumns + " FROM jobs WHERE 1 = 1"
+	var args []any
+	if filter.Type != "" {
+		query += " AND type = ?"
+		args = append(args, filter.Type)
+	}
+	if filter.State != "" {
+		query += " AND state = ?"
+		args = append(args, filter.State)
+	}
+	return rdb.Paginate[Job](ctx, c.db, page, query+" ORDER BY id DESC", args...)
+}
+
+// Cancel cancels a pending job. Running jobs can't be canceled.
+func (c *Client) Cancel(ctx context.Context, id string) error {
+	n, err := c.db.Exec(ctx, "UPDATE jobs SET state = ?, updated_at = ? WHERE id = ? AND state = ?",
+		StateCanceled, c.now(), id, StatePending)
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		if _, err := c.Get(ctx, id); err != nil {
+			return err
+		}
+		return ErrInvalidTransition
+	}
+	return nil
+}
@@ -0,0 +1,106 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/postgres"
+
+	"github.com/me2seeks/forge/infra/contract/rdb"
+	"github.com/me2seeks/forge/infra/impl/rdb/gorm"
+)
+
+func newMock(t *testing.T) (rdb.DB, sqlmock.Sqlmock) {
+	conn, mock, err := sqlmock.New()
+	require.NoError(t, err)
+
+	db, err := gorm.New(postgres.New(postgres.Config{Conn: conn}))
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+	return db, mock
+}
+
+func TestCanTransition(t *testing.T) {
+	assert.True(t, CanTransition(StatePending, StateRunning))
+	assert.True(t, CanTransition(StateRunning, StatePending))
+	assert.False(t, CanTransition(StateRunning, StateCanceled))
+	assert.False(t, CanTransition(StateSucceeded, StatePending))
+	assert.True(t, StateDead.Terminal())
+	assert.False(t, StatePending.Terminal())
+}
+
+func TestClient(t *testing.T) {
+	ctx := context.Background()
+	db, mock := newMock(t)
+	c := NewClient(db)
+	runAt := time.Unix(1700000000, 0)
+
+	mock.ExpectExec(regexp.QuoteMeta("INSERT INTO jobs")).
+		WithArgs("import-1", "graph.import", []byte(`{}`), StatePending, 3, runAt, sqlmock.AnyArg(), sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	job, err := c.Enqueue(ctx, "graph.import", []byte(`{}`), WithID("import-1"), WithRunAt(runAt), WithMaxAttempts(3))
+	require.NoError(t, err)
+	assert.Equal(t, StatePending, job.State)
+
+	mock.ExpectExec(regexp.QuoteMeta("UPDATE jobs SET state = $1")).
+		WithArgs(StateCanceled, sqlmock.AnyArg(), "import-1", StatePending).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery(regexp.QuoteMeta("FROM jobs WHERE id = $1")).
+		WithArgs("import-1").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "state"}).AddRow("import-1", StateRunning))
+	assert.ErrorIs(t, c.Cancel(ctx, "import-1"), ErrInvalidTransition)
+
+	mock.ExpectQuery(regexp.QuoteMeta("FROM jobs WHERE id = $1")).
+		WithArgs("missing").
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+	_, err = c.Get(ctx, "missing")
+	assert.ErrorIs(t, err, ErrNotFound)
+}
+
+func TestWorker(t *testing.T) {
+	ctx := context.Background()
+	db, mock := newMock(t)
+	now := time.Unix(1700000000, 0)
+
+	w := NewWorker(db, WithWorkerID("w1"), WithBackoff(func(int) time.Duration { return time.Minute }))
+	w.now = func() time.Time { return now }
+	w.Handle("graph.import", func(context.Context, *Job) error { return nil })
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(regexp.QuoteMeta("FOR UPDATE SKIP LOCKED")).
+		WithArgs(StatePending, now, StateRunning, now, "graph.import", 2).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "type", "state", "attempts", "max_attempts", "run_at"}).
+			AddRow("a", "graph.import", StatePending, 0, 3, now).
+			AddRow("b", "graph.import", StatePending, 2, 3, now))
+	mock.ExpectExec(regexp.QuoteMeta("UPDATE jobs SET state = $1, attempts = $2, locked_by = $3")).
+		WithArgs(StateRunning, 1, "w1", now.Add(30*time.Second), now, "a").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(regexp.QuoteMeta("UPDATE jobs SET state = $1, attempts = $2, locked_by = $3")).
+		WithArgs(StateRunning, 3, "w1", now.Add(30*time.Second), now, "b").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	jobs, err := w.claim(ctx, 2)
+	require.NoError(t, err)
+	require.Len(t, jobs, 2)
+
+	mock.ExpectExec(regexp.QuoteMeta("UPDATE jobs SET state = $1, last_error = $2, run_at = $3")).
+		WithArgs(StatePending, "timeout", now.Add(time.Minute), now, "a", "w1").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	require.NoError(t, w.finish(ctx, jobs[0], errors.New("timeout")))
+	assert.Equal(t, StatePending, jobs[0].State)
+
+	mock.ExpectExec(regexp.QuoteMeta("UPDATE jobs SET state = $1, last_error = $2, run_at = $3")).
+		WithArgs(StateDead, "timeout", now, now, "b", "w1").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	require.NoError(t, w.finish(ctx, jobs[1], errors.New("timeout")))
+	assert.Equal(t, StateDead, jobs[1].State)
+}
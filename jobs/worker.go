@@ -0,0 +1,295 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/me2seeks/forge/execute"
+	"github.com/me2seeks/forge/idgen"
+	"github.com/me2seeks/forge/infra/contract/rdb"
+	"github.com/me2seeks/forge/logs"
+	"github.com/me2seeks/forge/retry"
+	"github.com/me2seeks/forge/safego"
+)
+
+// Handler runs a job. Its ctx is canceled if the worker loses the job's lease.
+// Returning an error retries the job until it runs out of attempts, unless the
+// error wraps ErrPermanent.
+type Handler func(ctx context.Context, job *Job) error
+
+// ErrPermanent marks a handler error that must not be retried.
+var ErrPermanent = errors.New("jobs: permanent failure")
+
+// Worker claims due jobs and runs them with the handler of their type.
+type Worker struct {
+	db          rdb.DB
+	id          string
+	concurrency int
+	poll        time.Duration
+	lease       time.Duration
+	backoff     retry.Backoff
+	now         func() time.Time
+
+	mu       sync.RWMutex
+	handlers map[string]Handler
+}
+
+type WorkerOption func(*Worker)
+
+// WithConcurrency sets the number of jobs run at once. Defaults to 4.
+func WithConcurrency(n int) WorkerOption {
+	return func(w *Worker) {
+		w.concurrency = n
+	}
+}
+
+// WithPollInterval sets how often the worker looks for due jobs when idle. Defaults to 1s.
+func WithPollInterval(d time.Duration) WorkerOption {
+	return func(w *Worker) {
+		w.poll = d
+	}
+}
+
+// WithLease sets how long a claimed job is reserved. The lease is renewed every
+// third of it while the job runs. Defaults to 30s.
+func WithLease(d time.Duration) WorkerOption {
+	return func(w *Worker) {
+		w.lease = d
+	}
+}
+
+// WithBackoff sets the delay before the given retry of a failed job.
+// Defaults to exponential backoff from 5s up to 1h.
+func WithBackoff(backoff retry.Backoff) WorkerOption {
+	return func(w *Worker) {
+		w.backoff = backoff
+	}
+}
+
+// WithWorkerID sets the ID recorded in the jobs this worker holds. Defaults to a ULID.
+func WithWorkerID(id string) WorkerOption {
+	return func(w *Worker) {
+		w.id = id
+	}
+}
+
+func NewWorker(db rdb.DB, opts ...WorkerOption) *Worker {
+	w := &Worker{
+		db:          db,
+		id:          idgen.NewULID(),
+		concurrency: 4,
+		poll:        time.Second,
+		lease:       30 * time.Second,
+		backoff:     retry.Exponential(5*time.Second, time.Hour),
+		now:         time.Now,
+		handlers:    make(map[string]Handler),
+	}
+	for _, opt := range opts {
+		opt(w)
+	}
+	return w
+}
+
+// Handle registers the handler of the jobs of type typ. Only jobs of registered
+// types are claimed.
+func (w *Worker) Handle(typ string, h Handler) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.handlers[typ] = h
+}
+
+func (w *Worker) types() []string {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+	types := make([]string, 0, len(w.handlers))
+	for typ := range w.handlers {
+		types = append(types, typ)
+	}
+	return types
+}
+
+// Run claims and runs jobs until ctx is done, then waits for the running jobs
+// and returns ctx.Err(). The jobs' ctx isn't canceled with ctx, so they can
+// finish within their lease.
+func (w *Worker) Run(ctx context.Context) error {
+	slots := make(chan struct{}, w.concurrency)
+	var wg sync.WaitGroup
+	defer wg.Wait()
+
+	for {
+		free := w.concurrency - len(slots)
+		claimed := 0
+		if free > 0 {
+			jobs, err := w.claim(ctx, free)
+			if err != nil && ctx.Err() == nil {
+				logs.CtxErrorf(ctx, "[jobs] claim failed: %v", err)
+			}
+			claimed = len(jobs)
+			for _, job := range jobs {
+				slots <- struct{}{}
+				wg.Add(1)
+				safego.Go(ctx, func() {
+					defer func() {
+						<-slots
+						wg.Done()
+					}()
+					w.process(context.WithoutCancel(ctx), job)
+				})
+			}
+		}
+		if claimed > 0 && claimed == free {
+			continue
+		}
+
+		timer := time.NewTimer(w.poll)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// claim leases up to limit due jobs: pending jobs whose run_at has passed, and
+// running jobs whose lease expired.
+func (w *Worker) claim(ctx context.Context, limit int) ([]*Job, error) {
+	types := w.types()
+	if len(types) == 0 {
+		return nil, nil
+	}
+
+	var jobs []*Job
+	err := w.db.Transaction(ctx, func(ctx context.Context, tx rdb.DB) error {
+		now := w.now()
+		placeholders := strings.TrimSuffix(strings.Repeat("?, ", len(types)), ", ")
+		args := []any{StatePending, now, StateRunning, now}
+		for _, typ := range types {
+			args = append(args, typ)
+		}
+		args = append(args, limit)
+
+		rows, err := rdb.Select[Job](ctx, tx,
+			"SELECT "+jobColumns+" FROM jobs WHERE ((state = ? AND run_at <= ?) OR (state = ? AND locked_until < ?)) AND type IN ("+placeholders+") ORDER BY run_at LIMIT ? FOR UPDATE SKIP LOCKED",
+			args...)
+		if err != nil {
+			return err
+		}
+
+		lockedUntil := now.Add(w.lease)
+		for i := range rows {
+			job := &rows[i]
+			job.State = StateRunning
+			job.Attempts++
+			job.LockedBy = w.id
+			job.LockedUntil = &lockedUntil
+			job.UpdatedAt = now
+			if _, err := tx.Exec(ctx,
+				"UPDATE jobs SET state = ?, attempts = ?, locked_by = ?, locked_until = ?, updated_at = ? WHERE id = ?",
+				job.State, job.Attempts, job.LockedBy, lockedUntil, now, job.ID); err != nil {
+				return err
+			}
+			jobs = append(jobs, job)
+		}
+		return nil
+	})
+	return jobs, err
+}
+
+// process runs job, renewing its lease meanwhile, and records the outcome.
+func (w *Worker) process(ctx context.Context, job *Job) {
+	w.mu.RLock()
+	h := w.handlers[job.Type]
+	w.mu.RUnlock()
+
+	runCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	renewed := make(chan struct{})
+	safego.Go(ctx, func() {
+		defer close(renewed)
+		w.renew(runCtx, job, cancel)
+	})
+
+	err := execute.RunWithContextDone(runCtx, func(ctx context.Context) error {
+		return h(ctx, job)
+	})
+	lost := runCtx.Err() != nil
+	cancel()
+	<-renewed
+	if lost {
+		logs.CtxWarnf(ctx, "[jobs] lost lease of job %s, leaving it to its new owner", job.ID)
+		return
+	}
+
+	if err := w.finish(ctx, job, err); err != nil {
+		logs.CtxErrorf(ctx, "[jobs] record outcome of job %s failed: %v", job.ID, err)
+	}
+}
+
+// renew extends the lease of job until ctx is done, calling lost if another
+// worker took it over.
+func (w *Worker) renew(ctx context.Context, job *Job, lost func()) {
+	ticker := time.NewTicker(w.lease / 3)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		n, err := w.db.Exec(ctx, "UPDATE jobs SET locked_until = ? WHERE id = ? AND locked_by = ? AND state = ?",
+			w.now().Add(w.lease), job.ID, w.id, StateRunning)
+		if ctx.Err() != nil {
+			return
+		}
+		if err != nil {
+			logs.CtxWarnf(ctx, "[jobs] renew lease of job %s failed: %v", job.ID, err)
+			continue
+		}
+		if n == 0 {
+			lost()
+			return
+		}
+	}
+}
+
+// finish moves job out of running according to the handler error.
+func (w *Worker) finish(ctx context.Context, job *Job, runErr error) error {
+	now := w.now()
+	next := StateSucceeded
+	runAt := job.RunAt
+	var msg string
+	if runErr != nil {
+		msg = runErr.Error()
+		if job.Attempts >= job.MaxAttempts || errors.Is(runErr, ErrPermanent) {
+			next = StateDead
+			logs.CtxErrorf(ctx, "[jobs] job %s of type %s is dead after %d attempts: %v", job.ID, job.Type, job.Attempts, runErr)
+		} else {
+			next = StatePending
+			runAt = now.Add(w.backoff(job.Attempts))
+			logs.CtxWarnf(ctx, "[jobs] job %s of type %s failed, retrying at %v: %v", job.ID, job.Type, runAt, runErr)
+		}
+	}
+	if !CanTransition(job.State, next) {
+		return fmt.Errorf("%w: %s to %s", ErrInvalidTransition, job.State, next)
+	}
+
+	n, err := w.db.Exec(ctx,
+		"UPDATE jobs SET state = ?, last_error = ?, run_at = ?, locked_by = '', locked_until = NULL, updated_at = ? WHERE id = ? AND locked_by = ?",
+		next, msg, runAt, now, job.ID, w.id)
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return fmt.Errorf("job %s is no longer held by worker %s", job.ID, w.id)
+	}
+	job.State, job.LastError, job.RunAt = next, msg, runAt
+	job.LockedBy, job.LockedUntil, job.UpdatedAt = "", nil, now
+	return nil
+}
@@ -182,6 +182,10 @@ func (ll *defaultLogger) logfCtx(ctx context.Context, lv Level, format *string,
 	if logID != nil {
 		msg += fmt.Sprintf("[log-id: %v] ", logID)
 	}
+	kv := extractContext(ctx)
+	for i := 0; i+1 < len(kv); i += 2 {
+		msg += fmt.Sprintf("[%v: %v] ", kv[i], kv[i+1])
+	}
 	if format != nil {
 		msg += fmt.Sprintf(*format, v...)
 	} else {
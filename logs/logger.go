@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"io"
+	"sync"
 )
 
 // FormatLogger is a logs interface that output logs with a format.
@@ -86,6 +87,53 @@ func SetContext(ctx context.Context, kv ...any) context.Context {
 	return context.WithValue(ctx, logKey{}, kv)
 }
 
+// LogID returns the log ID set on ctx by SetContext, or "" if there is none.
+func LogID(ctx context.Context) string {
+	kv, _ := ctx.Value(logKey{}).([]any)
+	if len(kv) == 0 {
+		return ""
+	}
+	return fmt.Sprint(kv...)
+}
+
+// ForwardContext returns dst carrying the log context of src set by SetContext,
+// or dst itself if src has none.
+func ForwardContext(dst, src context.Context) context.Context {
+	kv, ok := src.Value(logKey{}).([]any)
+	if !ok {
+		return dst
+	}
+	return context.WithValue(dst, logKey{}, kv)
+}
+
+// ContextExtractor returns key/value pairs describing ctx, e.g. its trace ID,
+// added to every Ctx* log line.
+type ContextExtractor func(ctx context.Context) []any
+
+var (
+	extractorsMu sync.RWMutex
+	extractors   []ContextExtractor
+)
+
+// RegisterContextExtractor adds fn to the extractors consulted by the built-in loggers.
+func RegisterContextExtractor(fn ContextExtractor) {
+	extractorsMu.Lock()
+	defer extractorsMu.Unlock()
+	extractors = append(extractors, fn)
+}
+
+// extractContext returns the key/value pairs of all registered extractors for ctx.
+func extractContext(ctx context.Context) []any {
+	extractorsMu.RLock()
+	defer extractorsMu.RUnlock()
+
+	var kv []any
+	for _, fn := range extractors {
+		kv = append(kv, fn(ctx)...)
+	}
+	return kv
+}
+
 func (lv Level) toString() string {
 	if lv >= LevelTrace && lv <= LevelFatal {
 		return strs[lv]
@@ -171,6 +171,10 @@ func (s *slogLogger) logCtx(ctx context.Context, level Level, format string, v .
 			attrs = append(attrs, slog.Any("context", kv))
 		}
 	}
+	kv := extractContext(ctx)
+	for i := 0; i+1 < len(kv); i += 2 {
+		attrs = append(attrs, slog.Any(fmt.Sprint(kv[i]), kv[i+1]))
+	}
 	msg := fmt.Sprintf(format, v...)
 	s.l.LogAttrs(ctx, toSlogLevel(level), msg, attrs...)
 }
@@ -5,6 +5,7 @@ import (
 	"errors"
 	"fmt"
 
+	"github.com/me2seeks/forge/prelude/tuple"
 	json "github.com/me2seeks/forge/sonic"
 )
 
@@ -285,11 +286,8 @@ func FlatMapOrWithError[T, U any](option Option[T], fallbackValue U, mapper func
 	return maybe.TakeOr(fallbackValue), nil
 }
 
-// Pair 是一个表示包含两个元素的元组的数据类型。
-type Pair[T, U any] struct {
-	Value1 T
-	Value2 U
-}
+// Pair 是一个表示包含两个元素的元组的数据类型，它是 tuple.Pair 的别名。
+type Pair[T, U any] = tuple.Pair[T, U]
 
 // Zip 将两个 Option 压缩成一个包含每个 Option 值的 Pair。
 // 如果任一 Option 为 None，则此函数也返回 None。
@@ -0,0 +1,148 @@
+package otel
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/exporters/otlp/otlpmetric/otlpmetrichttp"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/propagation"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/me2seeks/forge/logs"
+)
+
+// Config configures the providers installed by Setup. The OTLP exporters also honor
+// the standard OTEL_EXPORTER_OTLP_* env vars, and the resource OTEL_RESOURCE_ATTRIBUTES.
+type Config struct {
+	ServiceName    string `env:"OTEL_SERVICE_NAME" yaml:"service_name" json:"service_name" required:"true"`
+	ServiceVersion string `env:"OTEL_SERVICE_VERSION" yaml:"service_version" json:"service_version"`
+	Environment    string `env:"OTEL_DEPLOYMENT_ENVIRONMENT" yaml:"environment" json:"environment"`
+	// EndpointURL is the OTLP/HTTP collector URL, e.g. http://localhost:4318.
+	EndpointURL string `env:"OTEL_ENDPOINT_URL" yaml:"endpoint_url" json:"endpoint_url"`
+	// SampleRatio is the fraction of root traces sampled, child spans follow their parent.
+	// Values <= 0 sample every trace, as the default of 1.
+	SampleRatio float64 `env:"OTEL_SAMPLE_RATIO" yaml:"sample_ratio" json:"sample_ratio" default:"1"`
+	// MetricInterval is the export period of metrics, 30s if <= 0.
+	MetricInterval time.Duration `env:"OTEL_METRIC_INTERVAL" yaml:"metric_interval" json:"metric_interval" default:"30s"`
+	// Disabled installs only the propagators and the log integration, no exporters.
+	Disabled bool `env:"OTEL_SDK_DISABLED" yaml:"disabled" json:"disabled"`
+}
+
+var registerLogsOnce sync.Once
+
+// Setup installs the global tracer and meter providers exporting over OTLP/HTTP, the
+// W3C trace context and baggage propagators, and adds trace and span IDs to the
+// logs.Ctx* output. The returned function flushes and stops the providers.
+func Setup(ctx context.Context, cfg Config) (shutdown func(context.Context) error, err error) {
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(
+		propagation.TraceContext{},
+		propagation.Baggage{},
+	))
+	registerLogsOnce.Do(func() {
+		logs.RegisterContextExtractor(logFields)
+	})
+
+	if cfg.Disabled {
+		return func(context.Context) error { return nil }, nil
+	}
+	// the defaults only apply through conf.Load, not to a Config literal.
+	if cfg.SampleRatio <= 0 {
+		cfg.SampleRatio = 1
+	}
+	if cfg.MetricInterval <= 0 {
+		cfg.MetricInterval = 30 * time.Second
+	}
+
+	attrs := []attribute.KeyValue{attribute.String("service.name", cfg.ServiceName)}
+	if cfg.ServiceVersion != "" {
+		attrs = append(attrs, attribute.String("service.version", cfg.ServiceVersion))
+	}
+	if cfg.Environment != "" {
+		attrs = append(attrs, attribute.String("deployment.environment.name", cfg.Environment))
+	}
+	res, err := resource.New(ctx,
+		resource.WithFromEnv(),
+		resource.WithTelemetrySDK(),
+		resource.WithHost(),
+		resource.WithAttributes(attrs...),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("create otel resource failed: %w", err)
+	}
+
+	var traceOpts []otlptracehttp.Option
+	var metricOpts []otlpmetrichttp.Option
+	if cfg.EndpointURL != "" {
+		traceOpts = append(traceOpts, otlptracehttp.WithEndpointURL(cfg.EndpointURL))
+		metricOpts = append(metricOpts, otlpmetrichttp.WithEndpointURL(cfg.EndpointURL))
+	}
+
+	traceExporter, err := otlptracehttp.New(ctx, traceOpts...)
+	if err != nil {
+		return nil, fmt.Errorf("create otlp trace exporter failed: %w", err)
+	}
+	metricExporter, err := otlpmetrichttp.New(ctx, metricOpts...)
+	if err != nil {
+		return nil, fmt.Errorf("create otlp metric exporter failed: %w", err)
+	}
+
+	tp := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(traceExporter),
+		sdktrace.WithResource(res),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(cfg.SampleRatio))),
+	)
+	mp := sdkmetric.NewMeterProvider(
+		sdkmetric.WithReader(sdkmetric.NewPeriodicReader(metricExporter, sdkmetric.WithInterval(cfg.MetricInterval))),
+		sdkmetric.WithResource(res),
+	)
+	otel.SetTracerProvider(tp)
+	otel.SetMeterProvider(mp)
+
+	return func(ctx context.Context) error {
+		return errors.Join(tp.Shutdown(ctx), mp.Shutdown(ctx))
+	}, nil
+}
+
+// Tracer returns a tracer of the global provider.
+func Tracer(name string) trace.Tracer {
+	return otel.Tracer(name)
+}
+
+// Meter returns a meter of the global provider.
+func Meter(name string) metric.Meter {
+	return otel.Meter(name)
+}
+
+// TraceID returns the trace ID of the span in ctx, or "" if there is none.
+func TraceID(ctx context.Context) string {
+	if sc := trace.SpanContextFromContext(ctx); sc.HasTraceID() {
+		return sc.TraceID().String()
+	}
+	return ""
+}
+
+// SpanID returns the ID of the span in ctx, or "" if there is none.
+func SpanID(ctx context.Context) string {
+	if sc := trace.SpanContextFromContext(ctx); sc.HasSpanID() {
+		return sc.SpanID().String()
+	}
+	return ""
+}
+
+func logFields(ctx context.Context) []any {
+	sc := trace.SpanContextFromContext(ctx)
+	if !sc.IsValid() {
+		return nil
+	}
+	return []any{"trace_id", sc.TraceID().String(), "span_id", sc.SpanID().String()}
+}
@@ -0,0 +1,60 @@
+package otel
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+
+	"github.com/me2seeks/forge/logs"
+)
+
+func TestPropagationAndLogs(t *testing.T) {
+	shutdown, err := Setup(context.Background(), Config{ServiceName: "test", Disabled: true})
+	assert.NoError(t, err)
+	defer shutdown(context.Background())
+
+	tp := sdktrace.NewTracerProvider()
+	ctx, span := tp.Tracer("test").Start(context.Background(), "op")
+	defer span.End()
+
+	header := http.Header{}
+	InjectHTTP(ctx, header)
+	assert.Contains(t, header.Get("traceparent"), TraceID(ctx))
+
+	remote := ExtractHTTP(context.Background(), header)
+	assert.Equal(t, TraceID(ctx), TraceID(remote))
+	assert.Equal(t, SpanID(ctx), SpanID(remote))
+
+	carrier := map[string]string{}
+	Inject(ctx, carrier)
+	assert.Equal(t, TraceID(ctx), TraceID(Extract(context.Background(), carrier)))
+
+	var buf bytes.Buffer
+	logs.SetOutput(&buf)
+	defer logs.SetOutput(os.Stderr)
+	logs.CtxInfof(ctx, "hello")
+	assert.Contains(t, buf.String(), "[trace_id: "+TraceID(ctx)+"]")
+
+	assert.Empty(t, TraceID(context.Background()))
+}
+
+func TestSetupDefaults(t *testing.T) {
+	collector := httptest.NewServer(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {}))
+	defer collector.Close()
+
+	// a Config literal leaves SampleRatio at 0, which still samples every root trace.
+	shutdown, err := Setup(context.Background(), Config{ServiceName: "test", EndpointURL: collector.URL})
+	assert.NoError(t, err)
+	defer shutdown(context.Background())
+
+	_, span := otel.Tracer("test").Start(context.Background(), "op")
+	defer span.End()
+	assert.True(t, span.SpanContext().IsSampled())
+}
@@ -0,0 +1,96 @@
+// Package outbox implements the transactional outbox pattern: events are written
+// to the database in the same transaction as the business change that produced
+// them, and a Relay publishes them afterwards. Delivery is at least once, so
+// consumers should deduplicate on Message.ID.
+package outbox
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/me2seeks/forge/idgen"
+	"github.com/me2seeks/forge/infra/contract/rdb"
+	"github.com/me2seeks/forge/otel"
+	"github.com/me2seeks/forge/sonic"
+)
+
+// Schema creates the outbox table on PostgreSQL.
+const Schema = `
+CREATE TABLE IF NOT EXISTS outbox (
+	id              VARCHAR(32)  PRIMARY KEY,
+	topic           VARCHAR(255) NOT NULL,
+	key             VARCHAR(255) NOT NULL DEFAULT '',
+	payload         BYTEA        NOT NULL,
+	headers         TEXT         NOT NULL DEFAULT '{}',
+	status          VARCHAR(16)  NOT NULL,
+	attempts        INT          NOT NULL DEFAULT 0,
+	last_error      TEXT         NOT NULL DEFAULT '',
+	created_at      TIMESTAMP    NOT NULL,
+	next_attempt_at TIMESTAMP    NOT NULL,
+	published_at    TIMESTAMP
+);
+CREATE INDEX IF NOT EXISTS idx_outbox_pending ON outbox (status, next_attempt_at);
+`
+
+// Status is the delivery state of a message.
+type Status string
+
+const (
+	StatusPending   Status = "pending"
+	StatusPublished Status = "published"
+	// StatusDead means the message exhausted its publish attempts.
+	StatusDead Status = "dead"
+)
+
+type Message struct {
+	// ID is generated by Enqueue if empty.
+	ID      string
+	Topic   string
+	Key     string
+	Payload []byte
+	// Headers are sent along with the payload. Enqueue adds the trace context of ctx.
+	Headers   map[string]string
+	Attempts  int
+	CreatedAt time.Time
+}
+
+// Publisher delivers messages to a broker.
+type Publisher interface {
+	Publish(ctx context.Context, msg *Message) error
+}
+
+// PublisherFunc adapts a function to a Publisher.
+type PublisherFunc func(ctx context.Context, msg *Message) error
+
+func (f PublisherFunc) Publish(ctx context.Context, msg *Message) error {
+	return f(ctx, msg)
+}
+
+// Enqueue stores msgs in the outbox through tx, which should be the transaction
+// of the business writes the messages describe.
+func Enqueue(ctx context.Context, tx rdb.DB, msgs ...*Message) error {
+	now := time.Now()
+	for _, msg := range msgs {
+		if msg.ID == "" {
+			msg.ID = idgen.NewULID()
+		}
+		if msg.Headers == nil {
+			msg.Headers = make(map[string]string)
+		}
+		otel.Inject(ctx, msg.Headers)
+		msg.CreatedAt = now
+
+		headers, err := sonic.MarshalString(msg.Headers)
+		if err != nil {
+			return fmt.Errorf("marshal headers of message %s failed: %w", msg.ID, err)
+		}
+
+		if _, err = tx.Exec(ctx,
+			"INSERT INTO outbox (id, topic, key, payload, headers, status, created_at, next_attempt_at) VALUES (?, ?, ?, ?, ?, ?, ?, ?)",
+			msg.ID, msg.Topic, msg.Key, msg.Payload, headers, StatusPending, now, now); err != nil {
+			return fmt.Errorf("insert message %s failed: %w", msg.ID, err)
+		}
+	}
+	return nil
+}
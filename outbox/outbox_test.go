@@ -0,0 +1,88 @@
+package outbox
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/postgres"
+
+	"github.com/me2seeks/forge/infra/contract/rdb"
+	"github.com/me2seeks/forge/infra/impl/rdb/gorm"
+)
+
+func newMock(t *testing.T) (rdb.DB, sqlmock.Sqlmock) {
+	conn, mock, err := sqlmock.New()
+	require.NoError(t, err)
+
+	db, err := gorm.New(postgres.New(postgres.Config{Conn: conn}))
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+	return db, mock
+}
+
+func TestEnqueue(t *testing.T) {
+	ctx := context.Background()
+	db, mock := newMock(t)
+
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta("INSERT INTO outbox")).
+		WithArgs(sqlmock.AnyArg(), "orders", "42", []byte(`{"id":42}`), "{}", StatusPending, sqlmock.AnyArg(), sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	msg := &Message{Topic: "orders", Key: "42", Payload: []byte(`{"id":42}`)}
+	err := db.Transaction(ctx, func(ctx context.Context, tx rdb.DB) error {
+		return Enqueue(ctx, tx, msg)
+	})
+	require.NoError(t, err)
+	assert.Len(t, msg.ID, 26)
+}
+
+func TestRelayOnce(t *testing.T) {
+	ctx := context.Background()
+	db, mock := newMock(t)
+	now := time.Unix(1700000000, 0)
+
+	var published []string
+	pub := PublisherFunc(func(_ context.Context, msg *Message) error {
+		if msg.ID == "b" {
+			return errors.New("broker down")
+		}
+		published = append(published, msg.ID)
+		return nil
+	})
+	r, err := NewRelay(db, pub,
+		WithConcurrency(1),
+		WithMaxAttempts(3),
+		WithBackoff(func(int) time.Duration { return time.Minute }),
+		WithClock(func() time.Time { return now }),
+	)
+	require.NoError(t, err)
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(regexp.QuoteMeta("FOR UPDATE SKIP LOCKED")).
+		WithArgs(StatusPending, now, 100).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "topic", "key", "payload", "headers", "attempts", "created_at"}).
+			AddRow("a", "orders", "", []byte("1"), `{"x":"y"}`, 0, now.Add(-time.Second)).
+			AddRow("b", "orders", "", []byte("2"), `{}`, 2, now.Add(-time.Second)))
+	mock.ExpectExec(regexp.QuoteMeta("UPDATE outbox SET status = $1, attempts = $2, published_at = $3 WHERE id = $4")).
+		WithArgs(StatusPublished, 1, now, "a").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(regexp.QuoteMeta("UPDATE outbox SET status = $1, attempts = $2, last_error = $3, next_attempt_at = $4 WHERE id = $5")).
+		WithArgs(StatusDead, 3, "broker down", now.Add(time.Minute), "b").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	n, err := r.RelayOnce(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 2, n)
+	assert.Equal(t, []string{"a"}, published)
+}
@@ -0,0 +1,234 @@
+package outbox
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+
+	"github.com/me2seeks/forge/infra/contract/rdb"
+	"github.com/me2seeks/forge/logs"
+	"github.com/me2seeks/forge/otel"
+	"github.com/me2seeks/forge/retry"
+	"github.com/me2seeks/forge/sonic"
+	"github.com/me2seeks/forge/taskgroup"
+)
+
+// Relay moves pending messages from the outbox to a Publisher.
+//
+// Each batch is claimed with SELECT ... FOR UPDATE SKIP LOCKED and its rows stay
+// locked until the outcome is written back, so concurrent relays never publish
+// the same message twice. A relay that crashes mid-batch leaves its messages
+// pending, and they are published again.
+type Relay struct {
+	db          rdb.DB
+	pub         Publisher
+	batchSize   int
+	concurrency int
+	interval    time.Duration
+	maxAttempts int
+	backoff     retry.Backoff
+	now         func() time.Time
+
+	published metric.Int64Counter
+	failed    metric.Int64Counter
+	dead      metric.Int64Counter
+	lag       metric.Float64Histogram
+}
+
+type Option func(*Relay)
+
+// WithBatchSize sets the number of messages claimed per batch. Defaults to 100.
+func WithBatchSize(n int) Option {
+	return func(r *Relay) {
+		r.batchSize = n
+	}
+}
+
+// WithConcurrency sets the number of messages of a batch published in parallel. Defaults to 8.
+func WithConcurrency(n int) Option {
+	return func(r *Relay) {
+		r.concurrency = n
+	}
+}
+
+// WithInterval sets how long Run sleeps when the outbox is drained. Defaults to 1s.
+func WithInterval(d time.Duration) Option {
+	return func(r *Relay) {
+		r.interval = d
+	}
+}
+
+// WithMaxAttempts sets the publish attempts after which a message is marked dead. Defaults to 10.
+func WithMaxAttempts(n int) Option {
+	return func(r *Relay) {
+		r.maxAttempts = n
+	}
+}
+
+// WithBackoff sets the delay before the given retry of a failed message.
+// Defaults to exponential backoff from 1s up to 5m.
+func WithBackoff(backoff retry.Backoff) Option {
+	return func(r *Relay) {
+		r.backoff = backoff
+	}
+}
+
+// WithClock overrides time.Now, mainly for tests.
+func WithClock(now func() time.Time) Option {
+	return func(r *Relay) {
+		r.now = now
+	}
+}
+
+func NewRelay(db rdb.DB, pub Publisher, opts ...Option) (*Relay, error) {
+	r := &Relay{
+		db:          db,
+		pub:         pub,
+		batchSize:   100,
+		concurrency: 8,
+		interval:    time.Second,
+		maxAttempts: 10,
+		backoff:     retry.Exponential(time.Second, 5*time.Minute),
+		now:         time.Now,
+	}
+	for _, opt := range opts {
+		opt(r)
+	}
+
+	meter := otel.Meter("github.com/me2seeks/forge/outbox")
+	var err error
+	if r.published, err = meter.Int64Counter("outbox.published",
+		metric.WithDescription("Messages published by the outbox relay.")); err != nil {
+		return nil, err
+	}
+	if r.failed, err = meter.Int64Counter("outbox.failed",
+		metric.WithDescription("Failed publish attempts of the outbox relay.")); err != nil {
+		return nil, err
+	}
+	if r.dead, err = meter.Int64Counter("outbox.dead",
+		metric.WithDescription("Messages that exhausted their publish attempts.")); err != nil {
+		return nil, err
+	}
+	if r.lag, err = meter.Float64Histogram("outbox.lag",
+		metric.WithDescription("Time from enqueue to publish."), metric.WithUnit("s")); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// Run relays batches until ctx is done, sleeping for the interval whenever the
+// outbox has no more due messages. Batch errors are logged and retried.
+func (r *Relay) Run(ctx context.Context) error {
+	for {
+		n, err := r.RelayOnce(ctx)
+		if err != nil {
+			logs.CtxErrorf(ctx, "[outbox] relay batch failed: %v", err)
+		}
+		if err == nil && n == r.batchSize {
+			continue
+		}
+
+		timer := time.NewTimer(r.interval)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+type row struct {
+	ID        string
+	Topic     string
+	Key       string
+	Payload   []byte
+	Headers   string
+	Attempts  int
+	CreatedAt time.Time
+}
+
+type outcome struct {
+	msg *Message
+	err error
+}
+
+// RelayOnce publishes a single batch of due messages and returns its size.
+func (r *Relay) RelayOnce(ctx context.Context) (int, error) {
+	var n int
+	err := r.db.Transaction(ctx, func(ctx context.Context, tx rdb.DB) error {
+		rows, err := rdb.Select[row](ctx, tx,
+			"SELECT id, topic, key, payload, headers, attempts, created_at FROM outbox WHERE status = ? AND next_attempt_at <= ? ORDER BY id LIMIT ? FOR UPDATE SKIP LOCKED",
+			StatusPending, r.now(), r.batchSize)
+		if err != nil {
+			return fmt.Errorf("claim messages failed: %w", err)
+		}
+		n = len(rows)
+
+		outcomes := make([]outcome, len(rows))
+		tg := taskgroup.NewUninterruptibleTaskGroup(ctx, r.concurrency)
+		for i, rw := range rows {
+			msg := &Message{
+				ID:        rw.ID,
+				Topic:     rw.Topic,
+				Key:       rw.Key,
+				Payload:   rw.Payload,
+				Attempts:  rw.Attempts,
+				CreatedAt: rw.CreatedAt,
+			}
+			outcomes[i].msg = msg
+			tg.Go(func() error {
+				outcomes[i].err = r.publish(ctx, msg, rw.Headers)
+				return nil
+			})
+		}
+		_ = tg.Wait()
+
+		for _, o := range outcomes {
+			if err := r.settle(ctx, tx, o); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	return n, err
+}
+
+func (r *Relay) publish(ctx context.Context, msg *Message, headers string) error {
+	if err := sonic.UnmarshalString(headers, &msg.Headers); err != nil {
+		return fmt.Errorf("unmarshal headers failed: %w", err)
+	}
+	return r.pub.Publish(otel.Extract(ctx, msg.Headers), msg)
+}
+
+// settle writes the outcome of a publish back to the outbox.
+func (r *Relay) settle(ctx context.Context, tx rdb.DB, o outcome) error {
+	now := r.now()
+	topic := metric.WithAttributes(attribute.String("topic", o.msg.Topic))
+
+	if o.err == nil {
+		r.published.Add(ctx, 1, topic)
+		r.lag.Record(ctx, now.Sub(o.msg.CreatedAt).Seconds(), topic)
+		_, err := tx.Exec(ctx, "UPDATE outbox SET status = ?, attempts = ?, published_at = ? WHERE id = ?",
+			StatusPublished, o.msg.Attempts+1, now, o.msg.ID)
+		return err
+	}
+
+	attempts := o.msg.Attempts + 1
+	r.failed.Add(ctx, 1, topic)
+	status := StatusPending
+	if attempts >= r.maxAttempts {
+		status = StatusDead
+		r.dead.Add(ctx, 1, topic)
+		logs.CtxErrorf(ctx, "[outbox] message %s to %s is dead after %d attempts: %v", o.msg.ID, o.msg.Topic, attempts, o.err)
+	} else {
+		logs.CtxWarnf(ctx, "[outbox] publish message %s to %s failed: %v", o.msg.ID, o.msg.Topic, o.err)
+	}
+
+	_, err := tx.Exec(ctx, "UPDATE outbox SET status = ?, attempts = ?, last_error = ?, next_attempt_at = ? WHERE id = ?",
+		status, attempts, o.err.Error(), now.Add(r.backoff(attempts)), o.msg.ID)
+	return err
+}
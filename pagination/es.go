@@ -0,0 +1,84 @@
+package pagination
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/me2seeks/forge/infra/contract/es"
+	"github.com/me2seeks/forge/sonic"
+)
+
+// SearchAfter runs req on index for the page after page.Cursor with
+// search_after. req must be sorted, ending with a unique tiebreaker field, and
+// every sort field other than _score and _id must be in the hits' _source,
+// where the cursor position is read from. req.From and req.SearchAfter are
+// ignored.
+func SearchAfter(ctx context.Context, codec *Codec, client es.Client, index string, req es.Request, page Request) (*Response[es.Hit], error) {
+	if len(req.Sort) == 0 {
+		return nil, errors.New("pagination: search_after needs a sorted request")
+	}
+	kind := "es:" + index
+	req.From, req.SearchAfter = nil, nil
+	if page.Cursor != "" {
+		if err := codec.Decode(kind, page.Cursor, &req.SearchAfter); err != nil {
+			return nil, err
+		}
+	}
+	limit := page.Limit()
+	size := limit + 1
+	req.Size = &size
+
+	resp, err := client.Search(ctx, index, &req)
+	if err != nil {
+		return nil, err
+	}
+	hits := resp.Hits.Hits
+	out := &Response[es.Hit]{Items: hits}
+	if len(hits) <= limit {
+		return out, nil
+	}
+
+	out.Items = hits[:limit]
+	position, err := sortValues(&hits[limit-1], req.Sort)
+	if err != nil {
+		return nil, err
+	}
+	if out.NextCursor, err = codec.Encode(kind, position); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// sortValues returns the values hit is sorted by.
+func sortValues(hit *es.Hit, sort []es.SortFiled) ([]any, error) {
+	values := make([]any, len(sort))
+	for i, s := range sort {
+		switch s.Field {
+		case "_score":
+			if hit.Score_ == nil {
+				return nil, errors.New("pagination: hit has no _score")
+			}
+			values[i] = *hit.Score_
+		case "_id":
+			if hit.Id_ == nil {
+				return nil, errors.New("pagination: hit has no _id")
+			}
+			values[i] = *hit.Id_
+		default:
+			path := make([]any, 0, strings.Count(s.Field, ".")+1)
+			for _, p := range strings.Split(s.Field, ".") {
+				path = append(path, p)
+			}
+			node, err := sonic.Get(hit.Source_, path...)
+			if err != nil {
+				return nil, fmt.Errorf("pagination: read sort field %s from _source failed: %w", s.Field, err)
+			}
+			if err := numbers.UnmarshalString(node.Raw(), &values[i]); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return values, nil
+}
@@ -0,0 +1,106 @@
+package pagination
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/me2seeks/forge/infra/contract/graph"
+)
+
+// GraphQuery runs query for the page after page.Cursor with keyset pagination
+// on query.OrderBy, see graph.Query.After. The aliases sorted by must be
+// returned as nodes or edges, where the cursor position is read from.
+// query.Skip, query.Limit and query.After are ignored.
+func GraphQuery(ctx context.Context, codec *Codec, client graph.Operations, query graph.Query, page Request) (*Response[graph.Record], error) {
+	if len(query.OrderBy) == 0 {
+		return nil, errors.New("pagination: keyset pagination needs a sorted query")
+	}
+	kind := "graph"
+	query.Skip, query.After = nil, nil
+	if page.Cursor != "" {
+		var position []any
+		if err := codec.Decode(kind, page.Cursor, &position); err != nil {
+			return nil, err
+		}
+		if len(position) != len(query.OrderBy) {
+			return nil, ErrInvalidCursor
+		}
+		query.After = toGraphValues(position)
+	}
+	limit := page.Limit()
+	size := limit + 1
+	query.Limit = &size
+
+	res, err := client.Query(ctx, &query)
+	if err != nil {
+		return nil, err
+	}
+	records := res.Records
+	out := &Response[graph.Record]{Items: records}
+	if len(records) <= limit {
+		return out, nil
+	}
+
+	out.Items = records[:limit]
+	position, err := orderValues(records[limit-1], query.OrderBy)
+	if err != nil {
+		return nil, err
+	}
+	if out.NextCursor, err = codec.Encode(kind, position); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// orderValues returns the values rec is sorted by.
+func orderValues(rec graph.Record, orderBy []graph.Order) ([]any, error) {
+	values := make([]any, len(orderBy))
+	for i, o := range orderBy {
+		var id string
+		var props graph.Properties
+		switch entity := rec[o.Alias].(type) {
+		case *graph.Node:
+			id, props = entity.ID, entity.Properties
+		case *graph.Edge:
+			id, props = entity.ID, entity.Properties
+		case nil:
+			return nil, fmt.Errorf("pagination: alias %s sorted by isn't returned", o.Alias)
+		default:
+			if o.Property != "" {
+				return nil, fmt.Errorf("pagination: alias %s sorted by isn't returned as a node or an edge", o.Alias)
+			}
+			// A value returned as is, e.g. an aggregate.
+			values[i] = entity
+			continue
+		}
+		if o.Property == "" {
+			values[i] = id
+			continue
+		}
+		v, ok := props[o.Property]
+		if !ok || v == nil {
+			return nil, fmt.Errorf("pagination: property %s.%s sorted by is null", o.Alias, o.Property)
+		}
+		values[i] = v
+	}
+	return values, nil
+}
+
+// toGraphValues turns the numbers of a decoded position back into integers or
+// floats, as graph databases don't compare numbers to strings.
+func toGraphValues(position []any) []any {
+	for i, v := range position {
+		n, ok := v.(json.Number)
+		if !ok {
+			continue
+		}
+		if x, err := n.Int64(); err == nil {
+			position[i] = x
+		} else if f, err := n.Float64(); err == nil {
+			position[i] = f
+		}
+	}
+	return position
+}
@@ -0,0 +1,135 @@
+package pagination
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/me2seeks/forge/infra/contract/es"
+	"github.com/me2seeks/forge/infra/contract/graph"
+	"github.com/me2seeks/forge/infra/contract/storage"
+)
+
+func TestCodec(t *testing.T) {
+	codec := NewCodec([]byte("secret"))
+	cursor, err := codec.Encode("es:docs", []any{int64(9007199254740993), "b"})
+	require.NoError(t, err)
+
+	var position []any
+	require.NoError(t, codec.Decode("es:docs", cursor, &position))
+	assert.Equal(t, []any{json.Number("9007199254740993"), "b"}, position)
+
+	assert.ErrorIs(t, codec.Decode("es:users", cursor, &position), ErrInvalidCursor)
+	assert.ErrorIs(t, NewCodec([]byte("other")).Decode("es:docs", cursor, &position), ErrInvalidCursor)
+	assert.ErrorIs(t, codec.Decode("es:docs", cursor[:len(cursor)-2]+"AA", &position), ErrInvalidCursor)
+	assert.ErrorIs(t, codec.Decode("es:docs", "not a cursor", &position), ErrInvalidCursor)
+}
+
+type fakeES struct {
+	es.Client
+	hits []es.Hit
+	reqs []es.Request
+}
+
+// Search serves the hits after req.SearchAfter, sorted by their "n" field.
+func (f *fakeES) Search(_ context.Context, _ string, req *es.Request) (*es.Response, error) {
+	f.reqs = append(f.reqs, *req)
+	start := 0
+	if len(req.SearchAfter) > 0 {
+		after, _ := req.SearchAfter[0].(json.Number).Int64()
+		start = int(after)
+	}
+	end := min(start+*req.Size, len(f.hits))
+	return &es.Response{Hits: es.HitsMetadata{Hits: f.hits[start:end]}}, nil
+}
+
+func TestSearchAfter(t *testing.T) {
+	ctx := context.Background()
+	codec := NewCodec([]byte("secret"))
+	client := &fakeES{}
+	for _, src := range []string{`{"n":1}`, `{"n":2}`, `{"n":3}`} {
+		client.hits = append(client.hits, es.Hit{Source_: json.RawMessage(src)})
+	}
+	req := es.Request{Sort: []es.SortFiled{{Field: "n", Asc: true}}}
+
+	page, err := SearchAfter(ctx, codec, client, "docs", req, Request{Size: 2})
+	require.NoError(t, err)
+	assert.Len(t, page.Items, 2)
+	require.True(t, page.HasMore())
+
+	page, err = SearchAfter(ctx, codec, client, "docs", req, Request{Cursor: page.NextCursor, Size: 2})
+	require.NoError(t, err)
+	assert.Len(t, page.Items, 1)
+	assert.False(t, page.HasMore())
+	assert.Equal(t, []any{json.Number("2")}, client.reqs[1].SearchAfter)
+}
+
+type fakeStorage struct {
+	storage.Storage
+}
+
+func (fakeStorage) ListObjectsPaginated(_ context.Context, input *storage.ListObjectsPaginatedInput) (*storage.ListObjectsPaginatedOutput, error) {
+	if input.Cursor == "" {
+		return &storage.ListObjectsPaginatedOutput{Files: []*storage.FileInfo{{Key: "a"}}, Cursor: "token", IsTruncated: true}, nil
+	}
+	return &storage.ListObjectsPaginatedOutput{Files: []*storage.FileInfo{{Key: input.Cursor}}}, nil
+}
+
+func TestListObjects(t *testing.T) {
+	ctx := context.Background()
+	codec := NewCodec([]byte("secret"))
+
+	page, err := ListObjects(ctx, codec, fakeStorage{}, "imports/", Request{})
+	require.NoError(t, err)
+	require.True(t, page.HasMore())
+
+	page, err = ListObjects(ctx, codec, fakeStorage{}, "imports/", Request{Cursor: page.NextCursor})
+	require.NoError(t, err)
+	assert.Equal(t, "token", page.Items[0].Key)
+	assert.False(t, page.HasMore())
+
+	_, err = ListObjects(ctx, codec, fakeStorage{}, "exports/", Request{Cursor: "x"})
+	assert.ErrorIs(t, err, ErrInvalidCursor)
+}
+
+type fakeGraph struct {
+	graph.Client
+	queries []graph.Query
+}
+
+// Query returns the nodes of rank 1 to 3 after query.After, as the database would.
+func (g *fakeGraph) Query(_ context.Context, query *graph.Query) (*graph.QueryResult, error) {
+	g.queries = append(g.queries, *query)
+	res := &graph.QueryResult{}
+	for rank := int64(1); rank <= 3 && len(res.Records) < *query.Limit; rank++ {
+		if len(query.After) > 0 && rank <= query.After[0].(int64) {
+			continue
+		}
+		res.Records = append(res.Records, graph.Record{"n": &graph.Node{ID: "n", Properties: graph.Properties{"rank": rank}}})
+	}
+	return res, nil
+}
+
+func TestGraphQuery(t *testing.T) {
+	ctx := context.Background()
+	codec := NewCodec([]byte("secret"))
+	client := &fakeGraph{}
+	query := graph.Query{OrderBy: []graph.Order{{Alias: "n", Property: "rank", Asc: true}}}
+
+	page, err := GraphQuery(ctx, codec, client, query, Request{Size: 2})
+	require.NoError(t, err)
+	assert.Len(t, page.Items, 2)
+	require.True(t, page.HasMore())
+
+	page, err = GraphQuery(ctx, codec, client, query, Request{Cursor: page.NextCursor, Size: 2})
+	require.NoError(t, err)
+	assert.Len(t, page.Items, 1)
+	assert.False(t, page.HasMore())
+	assert.Equal(t, []any{int64(2)}, client.queries[1].After)
+
+	_, err = GraphQuery(ctx, codec, client, graph.Query{}, Request{})
+	assert.Error(t, err)
+}
@@ -0,0 +1,31 @@
+package pagination
+
+import (
+	"context"
+
+	"github.com/me2seeks/forge/infra/contract/storage"
+)
+
+// ListObjects lists the page of objects under prefix after page.Cursor with
+// ListObjectsPaginated, wrapping the backend's own cursor.
+func ListObjects(ctx context.Context, codec *Codec, s storage.Storage, prefix string, page Request) (*Response[*storage.FileInfo], error) {
+	kind := "storage:" + prefix
+	input := &storage.ListObjectsPaginatedInput{Prefix: prefix, PageSize: page.Limit()}
+	if page.Cursor != "" {
+		if err := codec.Decode(kind, page.Cursor, &input.Cursor); err != nil {
+			return nil, err
+		}
+	}
+
+	output, err := s.ListObjectsPaginated(ctx, input)
+	if err != nil {
+		return nil, err
+	}
+	out := &Response[*storage.FileInfo]{Items: output.Files}
+	if output.IsTruncated && output.Cursor != "" {
+		if out.NextCursor, err = codec.Encode(kind, output.Cursor); err != nil {
+			return nil, err
+		}
+	}
+	return out, nil
+}
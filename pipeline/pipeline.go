@@ -0,0 +1,166 @@
+package pipeline
+
+import (
+	"context"
+	"fmt"
+	"runtime/debug"
+	"sync"
+
+	"github.com/me2seeks/forge/safego"
+)
+
+// Stage is one step of a Pipeline: Workers goroutines apply Fn to the items
+// they receive and push the results into a queue of QueueSize elements, which
+// blocks the stage when the next one falls behind.
+type Stage[In, Out any] struct {
+	Name      string
+	Workers   int
+	QueueSize int
+	Fn        func(ctx context.Context, in In) (Out, error)
+}
+
+// NewStage creates a Stage. workers < 1 is treated as 1 and queueSize < 0 as 0 (unbuffered).
+func NewStage[In, Out any](name string, workers, queueSize int, fn func(ctx context.Context, in In) (Out, error)) Stage[In, Out] {
+	return Stage[In, Out]{
+		Name:      name,
+		Workers:   max(workers, 1),
+		QueueSize: max(queueSize, 0),
+		Fn:        fn,
+	}
+}
+
+// Pipeline chains stages from In to Out. Items are processed concurrently inside
+// each stage, so the output order is not guaranteed.
+type Pipeline[In, Out any] struct {
+	run func(ctx context.Context, in <-chan In, fail func(error)) <-chan Out
+}
+
+// From starts a pipeline with a single stage.
+func From[In, Out any](s Stage[In, Out]) Pipeline[In, Out] {
+	return Pipeline[In, Out]{run: s.start}
+}
+
+// Then appends s to p.
+func Then[In, Mid, Out any](p Pipeline[In, Mid], s Stage[Mid, Out]) Pipeline[In, Out] {
+	return Pipeline[In, Out]{
+		run: func(ctx context.Context, in <-chan In, fail func(error)) <-chan Out {
+			return s.start(ctx, p.run(ctx, in, fail), fail)
+		},
+	}
+}
+
+// Run starts the pipeline reading from in. The returned channel is closed once
+// in is closed and every item has been processed, or after the first error;
+// callers must drain it. wait blocks until all stages have stopped and returns
+// the first error, or ctx's error if it was cancelled.
+func (p Pipeline[In, Out]) Run(ctx context.Context, in <-chan In) (out <-chan Out, wait func() error) {
+	ctx, cancel := context.WithCancelCause(ctx)
+
+	var once sync.Once
+	var firstErr error
+	fail := func(err error) {
+		once.Do(func() {
+			firstErr = err
+			cancel(err)
+		})
+	}
+
+	done := make(chan struct{})
+	res := p.run(ctx, in, fail)
+	forward := make(chan Out)
+	go func() {
+		defer close(done)
+		defer close(forward)
+		for item := range res {
+			forward <- item
+		}
+	}()
+
+	return forward, func() error {
+		<-done
+		defer cancel(nil)
+		if firstErr != nil {
+			return firstErr
+		}
+		return context.Cause(ctx)
+	}
+}
+
+// Process feeds items through the pipeline and collects every result.
+func (p Pipeline[In, Out]) Process(ctx context.Context, items []In) ([]Out, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	in := make(chan In)
+	safego.Go(ctx, func() {
+		defer close(in)
+		for _, item := range items {
+			select {
+			case in <- item:
+			case <-ctx.Done():
+				return
+			}
+		}
+	})
+
+	out, wait := p.Run(ctx, in)
+	results := make([]Out, 0, len(items))
+	for item := range out {
+		results = append(results, item)
+	}
+	if err := wait(); err != nil {
+		return nil, err
+	}
+	return results, nil
+}
+
+func (s Stage[In, Out]) start(ctx context.Context, in <-chan In, fail func(error)) <-chan Out {
+	out := make(chan Out, s.QueueSize)
+
+	var wg sync.WaitGroup
+	for i := 0; i < max(s.Workers, 1); i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			s.work(ctx, in, out, fail)
+		}()
+	}
+	go func() {
+		wg.Wait()
+		close(out)
+	}()
+
+	return out
+}
+
+func (s Stage[In, Out]) work(ctx context.Context, in <-chan In, out chan<- Out, fail func(error)) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case item, ok := <-in:
+			if !ok {
+				return
+			}
+			res, err := s.call(ctx, item)
+			if err != nil {
+				fail(fmt.Errorf("pipeline stage %s: %w", s.Name, err))
+				return
+			}
+			select {
+			case out <- res:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}
+}
+
+func (s Stage[In, Out]) call(ctx context.Context, item In) (res Out, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = safego.NewPanicErr(r, debug.Stack())
+		}
+	}()
+	return s.Fn(ctx, item)
+}
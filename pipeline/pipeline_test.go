@@ -0,0 +1,44 @@
+package pipeline
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPipeline(t *testing.T) {
+	double := NewStage("double", 4, 2, func(_ context.Context, i int) (int, error) { return i * 2, nil })
+	format := NewStage("format", 2, 2, func(_ context.Context, i int) (string, error) { return strconv.Itoa(i), nil })
+	p := Then(From(double), format)
+
+	res, err := p.Process(context.Background(), []int{1, 2, 3, 4})
+	assert.NoError(t, err)
+	sort.Strings(res)
+	assert.Equal(t, []string{"2", "4", "6", "8"}, res)
+}
+
+func TestPipeline_Error(t *testing.T) {
+	errBoom := errors.New("boom")
+	fail := NewStage("fail", 2, 0, func(_ context.Context, i int) (int, error) {
+		if i == 3 {
+			return 0, errBoom
+		}
+		return i, nil
+	})
+	panicking := NewStage("panic", 1, 0, func(_ context.Context, i int) (int, error) {
+		if i == 5 {
+			panic("unexpected")
+		}
+		return i, nil
+	})
+
+	_, err := From(fail).Process(context.Background(), []int{1, 2, 3, 4})
+	assert.ErrorIs(t, err, errBoom)
+
+	_, err = From(panicking).Process(context.Background(), []int{4, 5, 6})
+	assert.ErrorContains(t, err, "unexpected")
+}
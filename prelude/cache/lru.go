@@ -0,0 +1,205 @@
+package cache
+
+import (
+	"container/list"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// LRU is a fixed-capacity least-recently-used cache safe for concurrent use.
+// Entries may carry a TTL, expired entries are dropped lazily on access.
+type LRU[K comparable, V any] struct {
+	mu       sync.Mutex
+	capacity int
+	items    map[K]*list.Element
+	order    *list.List
+
+	ttl     time.Duration
+	onEvict func(key K, value V)
+	now     func() time.Time
+
+	hits      atomic.Uint64
+	misses    atomic.Uint64
+	evictions atomic.Uint64
+}
+
+type entry[K comparable, V any] struct {
+	key      K
+	value    V
+	expireAt time.Time
+}
+
+// Stats is a snapshot of the cache counters.
+type Stats struct {
+	Hits      uint64
+	Misses    uint64
+	Evictions uint64
+}
+
+type Option[K comparable, V any] func(*LRU[K, V])
+
+// WithTTL sets the default TTL applied by Set. Zero means entries never expire.
+func WithTTL[K comparable, V any](ttl time.Duration) Option[K, V] {
+	return func(c *LRU[K, V]) {
+		c.ttl = ttl
+	}
+}
+
+// WithOnEvict registers a callback invoked when an entry is evicted for capacity
+// or expiry, or removed by Delete/Purge. It runs with the cache lock held and must
+// not call back into the cache.
+func WithOnEvict[K comparable, V any](fn func(key K, value V)) Option[K, V] {
+	return func(c *LRU[K, V]) {
+		c.onEvict = fn
+	}
+}
+
+// WithClock overrides time.Now, mainly for tests.
+func WithClock[K comparable, V any](now func() time.Time) Option[K, V] {
+	return func(c *LRU[K, V]) {
+		c.now = now
+	}
+}
+
+// NewLRU creates a cache holding at most capacity entries. A capacity <= 0 means unbounded.
+func NewLRU[K comparable, V any](capacity int, opts ...Option[K, V]) *LRU[K, V] {
+	c := &LRU[K, V]{
+		capacity: capacity,
+		items:    make(map[K]*list.Element),
+		order:    list.New(),
+		now:      time.Now,
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+func (c *LRU[K, V]) Get(key K) (V, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	e, ok := c.items[key]
+	if !ok {
+		c.misses.Add(1)
+		var zero V
+		return zero, false
+	}
+
+	ent := e.Value.(*entry[K, V])
+	if c.expired(ent) {
+		c.removeElement(e)
+		c.evictions.Add(1)
+		c.misses.Add(1)
+		var zero V
+		return zero, false
+	}
+
+	c.order.MoveToFront(e)
+	c.hits.Add(1)
+	return ent.value, true
+}
+
+// Peek returns the value of key without updating its recency or the stats.
+func (c *LRU[K, V]) Peek(key K) (V, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if e, ok := c.items[key]; ok {
+		if ent := e.Value.(*entry[K, V]); !c.expired(ent) {
+			return ent.value, true
+		}
+	}
+	var zero V
+	return zero, false
+}
+
+// Set adds or replaces key using the default TTL.
+func (c *LRU[K, V]) Set(key K, value V) {
+	c.SetWithTTL(key, value, c.ttl)
+}
+
+// SetWithTTL adds or replaces key with its own TTL. Zero means no expiry.
+func (c *LRU[K, V]) SetWithTTL(key K, value V, ttl time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var expireAt time.Time
+	if ttl > 0 {
+		expireAt = c.now().Add(ttl)
+	}
+
+	if e, ok := c.items[key]; ok {
+		ent := e.Value.(*entry[K, V])
+		ent.value = value
+		ent.expireAt = expireAt
+		c.order.MoveToFront(e)
+		return
+	}
+
+	c.items[key] = c.order.PushFront(&entry[K, V]{key: key, value: value, expireAt: expireAt})
+	if c.capacity > 0 && c.order.Len() > c.capacity {
+		c.removeElement(c.order.Back())
+		c.evictions.Add(1)
+	}
+}
+
+func (c *LRU[K, V]) Delete(key K) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	e, ok := c.items[key]
+	if ok {
+		c.removeElement(e)
+	}
+	return ok
+}
+
+// Len returns the number of entries, including expired ones not yet collected.
+func (c *LRU[K, V]) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.order.Len()
+}
+
+// Keys returns the keys from most to least recently used.
+func (c *LRU[K, V]) Keys() []K {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	keys := make([]K, 0, c.order.Len())
+	for e := c.order.Front(); e != nil; e = e.Next() {
+		keys = append(keys, e.Value.(*entry[K, V]).key)
+	}
+	return keys
+}
+
+func (c *LRU[K, V]) Purge() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for e := c.order.Front(); e != nil; e = c.order.Front() {
+		c.removeElement(e)
+	}
+}
+
+func (c *LRU[K, V]) Stats() Stats {
+	return Stats{
+		Hits:      c.hits.Load(),
+		Misses:    c.misses.Load(),
+		Evictions: c.evictions.Load(),
+	}
+}
+
+func (c *LRU[K, V]) expired(ent *entry[K, V]) bool {
+	return !ent.expireAt.IsZero() && !c.now().Before(ent.expireAt)
+}
+
+func (c *LRU[K, V]) removeElement(e *list.Element) {
+	ent := c.order.Remove(e).(*entry[K, V])
+	delete(c.items, ent.key)
+	if c.onEvict != nil {
+		c.onEvict(ent.key, ent.value)
+	}
+}
@@ -0,0 +1,45 @@
+package cache
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLRU(t *testing.T) {
+	var evicted []string
+	c := NewLRU[string, int](2, WithOnEvict(func(k string, _ int) { evicted = append(evicted, k) }))
+
+	c.Set("a", 1)
+	c.Set("b", 2)
+	_, _ = c.Get("a")
+	c.Set("c", 3) // evicts b, the least recently used
+
+	_, ok := c.Get("b")
+	assert.False(t, ok)
+	v, ok := c.Get("a")
+	assert.True(t, ok)
+	assert.Equal(t, 1, v)
+	assert.Equal(t, []string{"b"}, evicted)
+	assert.Equal(t, []string{"a", "c"}, c.Keys())
+	assert.Equal(t, Stats{Hits: 2, Misses: 1, Evictions: 1}, c.Stats())
+}
+
+func TestLRU_TTL(t *testing.T) {
+	now := time.Unix(0, 0)
+	c := NewLRU[string, int](0,
+		WithTTL[string, int](time.Minute),
+		WithClock[string, int](func() time.Time { return now }),
+	)
+
+	c.Set("a", 1)
+	c.SetWithTTL("b", 2, 0)
+
+	now = now.Add(2 * time.Minute)
+	_, ok := c.Get("a")
+	assert.False(t, ok)
+	_, ok = c.Get("b")
+	assert.True(t, ok)
+	assert.Equal(t, 1, c.Len())
+}
@@ -0,0 +1,141 @@
+package heap
+
+import (
+	"container/heap"
+)
+
+// PriorityQueue is a binary heap ordered by a comparator: the element for
+// which less reports true against every other element is popped first.
+// It is not safe for concurrent use.
+type PriorityQueue[T any] struct {
+	h        *items[T]
+	capacity int
+}
+
+type Option func(*options)
+
+type options struct {
+	capacity int
+}
+
+// WithCapacity bounds the queue to n elements, Push reports false once it is full.
+func WithCapacity(n int) Option {
+	return func(o *options) {
+		o.capacity = n
+	}
+}
+
+func NewPriorityQueue[T any](less func(a, b T) bool, opts ...Option) *PriorityQueue[T] {
+	o := &options{}
+	for _, opt := range opts {
+		opt(o)
+	}
+	return &PriorityQueue[T]{
+		h:        &items[T]{less: less},
+		capacity: o.capacity,
+	}
+}
+
+func (q *PriorityQueue[T]) Len() int {
+	return len(q.h.data)
+}
+
+// Push adds x and reports whether it was accepted by a bounded queue.
+func (q *PriorityQueue[T]) Push(x T) bool {
+	if q.capacity > 0 && q.Len() >= q.capacity {
+		return false
+	}
+	heap.Push(q.h, x)
+	return true
+}
+
+// Pop removes and returns the first element.
+func (q *PriorityQueue[T]) Pop() (T, bool) {
+	if q.Len() == 0 {
+		var zero T
+		return zero, false
+	}
+	return heap.Pop(q.h).(T), true
+}
+
+// Peek returns the first element without removing it.
+func (q *PriorityQueue[T]) Peek() (T, bool) {
+	if q.Len() == 0 {
+		var zero T
+		return zero, false
+	}
+	return q.h.data[0], true
+}
+
+// PushPop pushes x and then pops the first element, which may be x itself.
+// It is more efficient than Push followed by Pop and ignores the capacity,
+// which makes it the building block of bounded top-K selection.
+func (q *PriorityQueue[T]) PushPop(x T) T {
+	if q.Len() == 0 || !q.h.less(q.h.data[0], x) {
+		return x
+	}
+	top := q.h.data[0]
+	q.h.data[0] = x
+	heap.Fix(q.h, 0)
+	return top
+}
+
+// Fix re-establishes the ordering after the element at index i changed its priority.
+// Indexes refer to the positions reported by Items.
+func (q *PriorityQueue[T]) Fix(i int, x T) {
+	q.h.data[i] = x
+	heap.Fix(q.h, i)
+}
+
+// Remove removes and returns the element at index i.
+func (q *PriorityQueue[T]) Remove(i int) T {
+	return heap.Remove(q.h, i).(T)
+}
+
+// Items returns the underlying elements in heap order, not in priority order.
+// The slice must not be modified.
+func (q *PriorityQueue[T]) Items() []T {
+	return q.h.data
+}
+
+// TopK returns the k greatest elements of src according to less, greatest first.
+func TopK[T any](src []T, k int, less func(a, b T) bool) []T {
+	if k <= 0 {
+		return nil
+	}
+
+	// a min-heap of the k greatest elements seen so far.
+	q := NewPriorityQueue(less)
+	for _, x := range src {
+		if q.Len() < k {
+			q.Push(x)
+			continue
+		}
+		q.PushPop(x)
+	}
+
+	dst := make([]T, q.Len())
+	for i := len(dst) - 1; i >= 0; i-- {
+		dst[i], _ = q.Pop()
+	}
+	return dst
+}
+
+type items[T any] struct {
+	data []T
+	less func(a, b T) bool
+}
+
+func (h *items[T]) Len() int           { return len(h.data) }
+func (h *items[T]) Less(i, j int) bool { return h.less(h.data[i], h.data[j]) }
+func (h *items[T]) Swap(i, j int)      { h.data[i], h.data[j] = h.data[j], h.data[i] }
+func (h *items[T]) Push(x any)         { h.data = append(h.data, x.(T)) }
+
+func (h *items[T]) Pop() any {
+	n := len(h.data) - 1
+	x := h.data[n]
+	var zero T
+	h.data[n] = zero
+	h.data = h.data[:n]
+	return x
+}
@@ -0,0 +1,36 @@
+package heap
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPriorityQueue(t *testing.T) {
+	q := NewPriorityQueue(func(a, b int) bool { return a < b }, WithCapacity(4))
+	for _, x := range []int{5, 1, 4, 2} {
+		assert.True(t, q.Push(x))
+	}
+	assert.False(t, q.Push(3))
+
+	top, ok := q.Peek()
+	assert.True(t, ok)
+	assert.Equal(t, 1, top)
+
+	var popped []int
+	for q.Len() > 0 {
+		x, _ := q.Pop()
+		popped = append(popped, x)
+	}
+	assert.Equal(t, []int{1, 2, 4, 5}, popped)
+
+	_, ok = q.Pop()
+	assert.False(t, ok)
+}
+
+func TestTopK(t *testing.T) {
+	less := func(a, b int) bool { return a < b }
+	assert.Equal(t, []int{9, 8, 7}, TopK([]int{3, 9, 1, 7, 8, 2}, 3, less))
+	assert.Equal(t, []int{2, 1}, TopK([]int{1, 2}, 5, less))
+	assert.Nil(t, TopK([]int{1, 2}, 0, less))
+}
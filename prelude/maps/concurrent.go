@@ -0,0 +1,170 @@
+package maps
+
+import (
+	"hash/maphash"
+	"sync"
+)
+
+const defaultShardCount = 32
+
+// ConcurrentMap is a map safe for concurrent use that spreads keys over
+// independently locked shards to reduce contention on hot paths.
+type ConcurrentMap[K comparable, V any] struct {
+	shards []*shard[K, V]
+	hash   func(K) uint64
+}
+
+type shard[K comparable, V any] struct {
+	mu sync.RWMutex
+	m  map[K]V
+}
+
+type ConcurrentMapOption[K comparable] func(*concurrentMapOptions[K])
+
+type concurrentMapOptions[K comparable] struct {
+	shardCount int
+	hash       func(K) uint64
+}
+
+// WithShardCount sets the number of shards, 32 by default.
+func WithShardCount[K comparable](n int) ConcurrentMapOption[K] {
+	return func(o *concurrentMapOptions[K]) {
+		o.shardCount = n
+	}
+}
+
+// WithHasher sets the function used to pick a key's shard, maphash.Comparable
+// with a random seed by default. Keys that are == must hash equally.
+func WithHasher[K comparable](hash func(K) uint64) ConcurrentMapOption[K] {
+	return func(o *concurrentMapOptions[K]) {
+		o.hash = hash
+	}
+}
+
+func NewConcurrentMap[K comparable, V any](opts ...ConcurrentMapOption[K]) *ConcurrentMap[K, V] {
+	o := &concurrentMapOptions[K]{
+		shardCount: defaultShardCount,
+	}
+	for _, opt := range opts {
+		opt(o)
+	}
+	if o.shardCount <= 0 {
+		o.shardCount = defaultShardCount
+	}
+	if o.hash == nil {
+		o.hash = defaultHasher[K](maphash.MakeSeed())
+	}
+
+	shards := make([]*shard[K, V], o.shardCount)
+	for i := range shards {
+		shards[i] = &shard[K, V]{m: make(map[K]V)}
+	}
+
+	return &ConcurrentMap[K, V]{
+		shards: shards,
+		hash:   o.hash,
+	}
+}
+
+func (c *ConcurrentMap[K, V]) Load(key K) (V, bool) {
+	s := c.shard(key)
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	v, ok := s.m[key]
+	return v, ok
+}
+
+func (c *ConcurrentMap[K, V]) Store(key K, value V) {
+	s := c.shard(key)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.m[key] = value
+}
+
+// LoadOrStore returns the existing value for the key if present.
+// Otherwise, it stores and returns the given value. loaded is true if the value was loaded.
+func (c *ConcurrentMap[K, V]) LoadOrStore(key K, value V) (actual V, loaded bool) {
+	s := c.shard(key)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if v, ok := s.m[key]; ok {
+		return v, true
+	}
+	s.m[key] = value
+	return value, false
+}
+
+func (c *ConcurrentMap[K, V]) LoadAndDelete(key K) (V, bool) {
+	s := c.shard(key)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	v, ok := s.m[key]
+	delete(s.m, key)
+	return v, ok
+}
+
+func (c *ConcurrentMap[K, V]) Delete(key K) {
+	s := c.shard(key)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.m, key)
+}
+
+// Compute atomically replaces the value of key with the result of fn, which receives
+// the current value and whether it exists. If fn returns keep=false the key is deleted.
+// fn runs under the shard lock and must not call back into the map.
+func (c *ConcurrentMap[K, V]) Compute(key K, fn func(old V, loaded bool) (value V, keep bool)) (V, bool) {
+	s := c.shard(key)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	old, loaded := s.m[key]
+	value, keep := fn(old, loaded)
+	if !keep {
+		delete(s.m, key)
+		var zero V
+		return zero, false
+	}
+	s.m[key] = value
+	return value, true
+}
+
+// Range calls fn for each key and value until fn returns false. Each shard is
+// locked for reading while it is visited, so fn must not modify the map.
+func (c *ConcurrentMap[K, V]) Range(fn func(key K, value V) bool) {
+	for _, s := range c.shards {
+		if !s.rangeLocked(fn) {
+			return
+		}
+	}
+}
+
+func (c *ConcurrentMap[K, V]) Len() int {
+	n := 0
+	for _, s := range c.shards {
+		s.mu.RLock()
+		n += len(s.m)
+		s.mu.RUnlock()
+	}
+	return n
+}
+
+func (c *ConcurrentMap[K, V]) shard(key K) *shard[K, V] {
+	return c.shards[c.hash(key)%uint64(len(c.shards))]
+}
+
+func (s *shard[K, V]) rangeLocked(fn func(K, V) bool) bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	for k, v := range s.m {
+		if !fn(k, v) {
+			return false
+		}
+	}
+	return true
+}
+
+func defaultHasher[K comparable](seed maphash.Seed) func(K) uint64 {
+	return func(key K) uint64 {
+		return maphash.Comparable(seed, key)
+	}
+}
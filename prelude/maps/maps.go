@@ -16,3 +16,92 @@ func TransformKey[K1, K2 comparable, V any](m map[K1]V, f func(K1) K2) map[K2]V
 	}
 	return n
 }
+
+// Merge combines ms into a new map. When a key is present in more than one map,
+// resolve decides the kept value from the current and the incoming one;
+// a nil resolve lets the later map win.
+func Merge[K comparable, V any](resolve func(k K, cur, next V) V, ms ...map[K]V) map[K]V {
+	size := 0
+	for _, m := range ms {
+		size += len(m)
+	}
+
+	n := make(map[K]V, size)
+	for _, m := range ms {
+		for k, v := range m {
+			if cur, ok := n[k]; ok && resolve != nil {
+				v = resolve(k, cur, v)
+			}
+			n[k] = v
+		}
+	}
+	return n
+}
+
+func FilterKeys[K comparable, V any](m map[K]V, f func(K) bool) map[K]V {
+	n := make(map[K]V)
+	for k, v := range m {
+		if f(k) {
+			n[k] = v
+		}
+	}
+	return n
+}
+
+func FilterValues[K comparable, V any](m map[K]V, f func(V) bool) map[K]V {
+	n := make(map[K]V)
+	for k, v := range m {
+		if f(v) {
+			n[k] = v
+		}
+	}
+	return n
+}
+
+// Keys returns the keys of m in unspecified order.
+func Keys[K comparable, V any](m map[K]V) []K {
+	keys := make([]K, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	return keys
+}
+
+// Values returns the values of m in unspecified order.
+func Values[K comparable, V any](m map[K]V) []V {
+	values := make([]V, 0, len(m))
+	for _, v := range m {
+		values = append(values, v)
+	}
+	return values
+}
+
+// Invert swaps keys and values. If several keys share a value, which one
+// survives is unspecified.
+func Invert[K, V comparable](m map[K]V) map[V]K {
+	n := make(map[V]K, len(m))
+	for k, v := range m {
+		n[v] = k
+	}
+	return n
+}
+
+func MapValues[K comparable, V1, V2 any](m map[K]V1, f func(V1) V2) map[K]V2 {
+	n := make(map[K]V2, len(m))
+	for k, v := range m {
+		n[k] = f(v)
+	}
+	return n
+}
+
+func Equal[K, V comparable](m1, m2 map[K]V) bool {
+	if len(m1) != len(m2) {
+		return false
+	}
+	for k, v1 := range m1 {
+		if v2, ok := m2[k]; !ok || v1 != v2 {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,123 @@
+package maps
+
+import (
+	"cmp"
+	"encoding/json"
+	"math"
+	"strconv"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOrderedMap(t *testing.T) {
+	m := NewOrderedMap[string, int]()
+	m.Set("z", 1)
+	m.Set("a", 2)
+	m.Set("m", 3)
+	m.Set("z", 4)
+	m.Delete("a")
+
+	assert.Equal(t, []string{"z", "m"}, m.Keys())
+	assert.Equal(t, []int{4, 3}, m.Values())
+
+	b, err := json.Marshal(m)
+	assert.NoError(t, err)
+	assert.Equal(t, `{"z":4,"m":3}`, string(b))
+
+	decoded := NewOrderedMap[string, int]()
+	assert.NoError(t, json.Unmarshal([]byte(`{"b":1,"a":2,"c":3}`), decoded))
+	assert.Equal(t, []string{"b", "a", "c"}, decoded.Keys())
+}
+
+func TestSortedMap(t *testing.T) {
+	m := NewSortedMap[int, string](cmp.Compare[int])
+	m.Set(3, "c")
+	m.Set(1, "a")
+	m.Set(2, "b")
+	m.Set(1, "A")
+	m.Delete(2)
+
+	assert.Equal(t, []int{1, 3}, m.Keys())
+	assert.Equal(t, []string{"A", "c"}, m.Values())
+
+	b, err := json.Marshal(m)
+	assert.NoError(t, err)
+	assert.Equal(t, `{"1":"A","3":"c"}`, string(b))
+
+	decoded := NewSortedMap[int, string](cmp.Compare[int])
+	assert.NoError(t, json.Unmarshal([]byte(`{"10":"x","2":"y"}`), decoded))
+	assert.Equal(t, []int{2, 10}, decoded.Keys())
+}
+
+func TestMerge(t *testing.T) {
+	a := map[string]int{"x": 1, "y": 2}
+	b := map[string]int{"y": 3, "z": 4}
+
+	assert.Equal(t, map[string]int{"x": 1, "y": 3, "z": 4}, Merge(nil, a, b))
+
+	sum := func(_ string, cur, next int) int { return cur + next }
+	assert.Equal(t, map[string]int{"x": 1, "y": 5, "z": 4}, Merge(sum, a, b))
+}
+
+func TestTransforms(t *testing.T) {
+	m := map[string]int{"a": 1, "b": 2, "c": 3}
+
+	assert.Equal(t, map[string]int{"a": 1}, FilterKeys(m, func(k string) bool { return k == "a" }))
+	assert.Equal(t, map[string]int{"b": 2, "c": 3}, FilterValues(m, func(v int) bool { return v > 1 }))
+	assert.ElementsMatch(t, []string{"a", "b", "c"}, Keys(m))
+	assert.ElementsMatch(t, []int{1, 2, 3}, Values(m))
+	assert.Equal(t, map[int]string{1: "a", 2: "b", 3: "c"}, Invert(m))
+	assert.Equal(t, map[string]int{"a": 2, "b": 4, "c": 6}, MapValues(m, func(v int) int { return v * 2 }))
+	assert.True(t, Equal(m, map[string]int{"c": 3, "b": 2, "a": 1}))
+	assert.False(t, Equal(m, map[string]int{"a": 1}))
+}
+
+func TestConcurrentMap(t *testing.T) {
+	m := NewConcurrentMap[string, int](WithShardCount[string](4))
+
+	var wg sync.WaitGroup
+	for i := 0; i < 100; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			m.Compute("counter", func(old int, _ bool) (int, bool) { return old + 1, true })
+			m.LoadOrStore(strconv.Itoa(i%10), i)
+		}()
+	}
+	wg.Wait()
+
+	v, ok := m.Load("counter")
+	assert.True(t, ok)
+	assert.Equal(t, 100, v)
+	assert.Equal(t, 11, m.Len())
+
+	m.Compute("counter", func(int, bool) (int, bool) { return 0, false })
+	_, ok = m.Load("counter")
+	assert.False(t, ok)
+
+	seen := 0
+	m.Range(func(string, int) bool { seen++; return true })
+	assert.Equal(t, 10, seen)
+}
+
+func TestConcurrentMapKeys(t *testing.T) {
+	// -0.0 == +0.0, so both must land in the same shard.
+	for range 100 {
+		m := NewConcurrentMap[float64, string]()
+		m.Store(0.0, "zero")
+		v, ok := m.Load(math.Copysign(0, -1))
+		assert.True(t, ok)
+		assert.Equal(t, "zero", v)
+	}
+
+	// snowflake-like ids, whose low bits are all 0, spread over the shards.
+	ids := NewConcurrentMap[int64, struct{}]()
+	for i := range int64(1000) {
+		ids.Store(i<<22, struct{}{})
+	}
+	for _, s := range ids.shards {
+		assert.Less(t, len(s.m), 100)
+	}
+}
@@ -0,0 +1,279 @@
+package maps
+
+import (
+	"bytes"
+	"container/list"
+	"encoding/json"
+	"fmt"
+	"iter"
+	"sort"
+
+	"github.com/me2seeks/forge/sonic"
+)
+
+// OrderedMap is a map that iterates and marshals in insertion order.
+// Overwriting an existing key keeps its original position. It is not safe for concurrent use.
+type OrderedMap[K comparable, V any] struct {
+	entries map[K]*list.Element
+	order   *list.List
+}
+
+type entry[K comparable, V any] struct {
+	key   K
+	value V
+}
+
+func NewOrderedMap[K comparable, V any]() *OrderedMap[K, V] {
+	return &OrderedMap[K, V]{
+		entries: make(map[K]*list.Element),
+		order:   list.New(),
+	}
+}
+
+func (m *OrderedMap[K, V]) Get(key K) (V, bool) {
+	if e, ok := m.entries[key]; ok {
+		return e.Value.(*entry[K, V]).value, true
+	}
+	var zero V
+	return zero, false
+}
+
+func (m *OrderedMap[K, V]) Set(key K, value V) {
+	if e, ok := m.entries[key]; ok {
+		e.Value.(*entry[K, V]).value = value
+		return
+	}
+	m.entries[key] = m.order.PushBack(&entry[K, V]{key: key, value: value})
+}
+
+func (m *OrderedMap[K, V]) Delete(key K) {
+	if e, ok := m.entries[key]; ok {
+		m.order.Remove(e)
+		delete(m.entries, key)
+	}
+}
+
+func (m *OrderedMap[K, V]) Len() int {
+	return len(m.entries)
+}
+
+// All returns an iterator over the key/value pairs in insertion order.
+func (m *OrderedMap[K, V]) All() iter.Seq2[K, V] {
+	return func(yield func(K, V) bool) {
+		for e := m.order.Front(); e != nil; e = e.Next() {
+			kv := e.Value.(*entry[K, V])
+			if !yield(kv.key, kv.value) {
+				return
+			}
+		}
+	}
+}
+
+func (m *OrderedMap[K, V]) Keys() []K {
+	return collectKeys(m.All(), m.Len())
+}
+
+func (m *OrderedMap[K, V]) Values() []V {
+	return collectValues(m.All(), m.Len())
+}
+
+// MarshalJSON encodes the map as a JSON object whose members keep insertion order.
+func (m *OrderedMap[K, V]) MarshalJSON() ([]byte, error) {
+	return marshalOrdered(m.All())
+}
+
+// UnmarshalJSON decodes a JSON object, recording members in document order.
+func (m *OrderedMap[K, V]) UnmarshalJSON(data []byte) error {
+	*m = *NewOrderedMap[K, V]()
+	return unmarshalOrdered(data, m.Set)
+}
+
+// SortedMap is a map that iterates and marshals in key order as defined by a comparator.
+// It is not safe for concurrent use.
+type SortedMap[K comparable, V any] struct {
+	cmp     func(a, b K) int
+	keys    []K
+	entries map[K]V
+}
+
+// NewSortedMap returns an empty SortedMap ordered by cmp, which must return a negative
+// number when a < b, a positive number when a > b and zero when they are equal.
+func NewSortedMap[K comparable, V any](cmp func(a, b K) int) *SortedMap[K, V] {
+	return &SortedMap[K, V]{
+		cmp:     cmp,
+		entries: make(map[K]V),
+	}
+}
+
+func (m *SortedMap[K, V]) Get(key K) (V, bool) {
+	v, ok := m.entries[key]
+	return v, ok
+}
+
+func (m *SortedMap[K, V]) Set(key K, value V) {
+	if _, ok := m.entries[key]; !ok {
+		i := m.search(key)
+		m.keys = append(m.keys, key)
+		copy(m.keys[i+1:], m.keys[i:])
+		m.keys[i] = key
+	}
+	m.entries[key] = value
+}
+
+func (m *SortedMap[K, V]) Delete(key K) {
+	if _, ok := m.entries[key]; !ok {
+		return
+	}
+	delete(m.entries, key)
+	i := m.search(key)
+	m.keys = append(m.keys[:i], m.keys[i+1:]...)
+}
+
+func (m *SortedMap[K, V]) Len() int {
+	return len(m.entries)
+}
+
+// All returns an iterator over the key/value pairs in key order.
+func (m *SortedMap[K, V]) All() iter.Seq2[K, V] {
+	return func(yield func(K, V) bool) {
+		for _, k := range m.keys {
+			if !yield(k, m.entries[k]) {
+				return
+			}
+		}
+	}
+}
+
+func (m *SortedMap[K, V]) Keys() []K {
+	return append([]K(nil), m.keys...)
+}
+
+func (m *SortedMap[K, V]) Values() []V {
+	return collectValues(m.All(), m.Len())
+}
+
+// MarshalJSON encodes the map as a JSON object whose members are in key order.
+func (m *SortedMap[K, V]) MarshalJSON() ([]byte, error) {
+	return marshalOrdered(m.All())
+}
+
+// UnmarshalJSON decodes a JSON object into the map, keeping the existing comparator.
+func (m *SortedMap[K, V]) UnmarshalJSON(data []byte) error {
+	m.keys = nil
+	m.entries = make(map[K]V)
+	return unmarshalOrdered(data, m.Set)
+}
+
+func (m *SortedMap[K, V]) search(key K) int {
+	return sort.Search(len(m.keys), func(i int) bool {
+		return m.cmp(m.keys[i], key) >= 0
+	})
+}
+
+func collectKeys[K, V any](seq iter.Seq2[K, V], size int) []K {
+	keys := make([]K, 0, size)
+	for k := range seq {
+		keys = append(keys, k)
+	}
+	return keys
+}
+
+func collectValues[K, V any](seq iter.Seq2[K, V], size int) []V {
+	values := make([]V, 0, size)
+	for _, v := range seq {
+		values = append(values, v)
+	}
+	return values
+}
+
+func marshalOrdered[K, V any](seq iter.Seq2[K, V]) ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	first := true
+	for k, v := range seq {
+		if !first {
+			buf.WriteByte(',')
+		}
+		first = false
+
+		kb, err := marshalKey(k)
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(kb)
+		buf.WriteByte(':')
+
+		vb, err := sonic.Marshal(v)
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal value of key %v: %w", k, err)
+		}
+		buf.Write(vb)
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+// marshalKey encodes k as a JSON object key; non-string keys are quoted
+// the same way encoding/json does for integer keys.
+func marshalKey[K any](k K) ([]byte, error) {
+	kb, err := sonic.Marshal(k)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal key %v: %w", k, err)
+	}
+	if len(kb) > 0 && kb[0] == '"' {
+		return kb, nil
+	}
+	return sonic.Marshal(string(kb))
+}
+
+func unmarshalOrdered[K, V any](data []byte, set func(K, V)) error {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	tok, err := dec.Token()
+	if err != nil {
+		return err
+	}
+	if delim, ok := tok.(json.Delim); !ok || delim != '{' {
+		return fmt.Errorf("expected JSON object, got %v", tok)
+	}
+
+	for dec.More() {
+		tok, err = dec.Token()
+		if err != nil {
+			return err
+		}
+		rawKey := tok.(string)
+
+		var key K
+		if err = unmarshalKey(rawKey, &key); err != nil {
+			return fmt.Errorf("failed to unmarshal key %q: %w", rawKey, err)
+		}
+
+		var raw json.RawMessage
+		if err = dec.Decode(&raw); err != nil {
+			return err
+		}
+		var value V
+		if err = sonic.Unmarshal(raw, &value); err != nil {
+			return fmt.Errorf("failed to unmarshal value of key %q: %w", rawKey, err)
+		}
+		set(key, value)
+	}
+
+	_, err = dec.Token()
+	return err
+}
+
+func unmarshalKey[K any](raw string, key *K) error {
+	if s, ok := any(key).(*string); ok {
+		*s = raw
+		return nil
+	}
+	if err := sonic.UnmarshalString(raw, key); err == nil {
+		return nil
+	}
+	quoted, err := sonic.MarshalString(raw)
+	if err != nil {
+		return err
+	}
+	return sonic.UnmarshalString(quoted, key)
+}
@@ -0,0 +1,96 @@
+package queue
+
+const minDequeCapacity = 8
+
+// Deque is a double-ended queue backed by a growable ring buffer.
+// The zero value is ready to use. It is not safe for concurrent use.
+type Deque[T any] struct {
+	buf   []T
+	head  int
+	count int
+}
+
+func NewDeque[T any](capacity int) *Deque[T] {
+	return &Deque[T]{buf: make([]T, max(capacity, minDequeCapacity))}
+}
+
+func (d *Deque[T]) Len() int {
+	return d.count
+}
+
+func (d *Deque[T]) PushBack(x T) {
+	d.grow()
+	d.buf[(d.head+d.count)%len(d.buf)] = x
+	d.count++
+}
+
+func (d *Deque[T]) PushFront(x T) {
+	d.grow()
+	d.head = (d.head - 1 + len(d.buf)) % len(d.buf)
+	d.buf[d.head] = x
+	d.count++
+}
+
+func (d *Deque[T]) PopFront() (T, bool) {
+	var zero T
+	if d.count == 0 {
+		return zero, false
+	}
+	x := d.buf[d.head]
+	d.buf[d.head] = zero
+	d.head = (d.head + 1) % len(d.buf)
+	d.count--
+	return x, true
+}
+
+func (d *Deque[T]) PopBack() (T, bool) {
+	var zero T
+	if d.count == 0 {
+		return zero, false
+	}
+	i := (d.head + d.count - 1) % len(d.buf)
+	x := d.buf[i]
+	d.buf[i] = zero
+	d.count--
+	return x, true
+}
+
+func (d *Deque[T]) Front() (T, bool) {
+	if d.count == 0 {
+		var zero T
+		return zero, false
+	}
+	return d.buf[d.head], true
+}
+
+func (d *Deque[T]) Back() (T, bool) {
+	if d.count == 0 {
+		var zero T
+		return zero, false
+	}
+	return d.buf[(d.head+d.count-1)%len(d.buf)], true
+}
+
+// At returns the i-th element counting from the front. It panics if i is out of range.
+func (d *Deque[T]) At(i int) T {
+	if i < 0 || i >= d.count {
+		panic("queue: Deque index out of range")
+	}
+	return d.buf[(d.head+i)%len(d.buf)]
+}
+
+func (d *Deque[T]) Clear() {
+	clear(d.buf)
+	d.head, d.count = 0, 0
+}
+
+func (d *Deque[T]) grow() {
+	if d.count < len(d.buf) {
+		return
+	}
+	buf := make([]T, max(len(d.buf)*2, minDequeCapacity))
+	for i := 0; i < d.count; i++ {
+		buf[i] = d.buf[(d.head+i)%len(d.buf)]
+	}
+	d.buf, d.head = buf, 0
+}
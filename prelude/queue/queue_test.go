@@ -0,0 +1,42 @@
+package queue
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDeque(t *testing.T) {
+	var d Deque[int]
+	for i := 0; i < 20; i++ {
+		d.PushBack(i)
+	}
+	d.PushFront(-1)
+
+	assert.Equal(t, 21, d.Len())
+	assert.Equal(t, -1, d.At(0))
+	front, _ := d.PopFront()
+	back, _ := d.PopBack()
+	assert.Equal(t, -1, front)
+	assert.Equal(t, 19, back)
+	assert.Equal(t, 19, d.Len())
+}
+
+func TestRingBuffer(t *testing.T) {
+	r := NewRingBuffer[int](3, false)
+	assert.True(t, r.Push(1))
+	assert.True(t, r.Push(2))
+	assert.True(t, r.Push(3))
+	assert.False(t, r.Push(4))
+	assert.Equal(t, []int{1, 2, 3}, r.ToSlice())
+
+	o := NewRingBuffer[int](3, true)
+	for i := 1; i <= 5; i++ {
+		o.Push(i)
+	}
+	assert.Equal(t, []int{3, 4, 5}, o.ToSlice())
+	x, ok := o.Pop()
+	assert.True(t, ok)
+	assert.Equal(t, 3, x)
+	assert.Equal(t, []int{4, 5}, o.ToSlice())
+}
@@ -0,0 +1,87 @@
+package queue
+
+// RingBuffer is a FIFO queue with a fixed capacity. When it is full, Push either
+// rejects the new element or, with overwrite enabled, drops the oldest one.
+// It is not safe for concurrent use.
+type RingBuffer[T any] struct {
+	buf       []T
+	head      int
+	count     int
+	overwrite bool
+}
+
+// NewRingBuffer creates a ring buffer holding at most capacity elements.
+// It panics if capacity <= 0.
+func NewRingBuffer[T any](capacity int, overwrite bool) *RingBuffer[T] {
+	if capacity <= 0 {
+		panic("queue: RingBuffer capacity must be positive")
+	}
+	return &RingBuffer[T]{
+		buf:       make([]T, capacity),
+		overwrite: overwrite,
+	}
+}
+
+func (r *RingBuffer[T]) Len() int {
+	return r.count
+}
+
+func (r *RingBuffer[T]) Cap() int {
+	return len(r.buf)
+}
+
+func (r *RingBuffer[T]) Full() bool {
+	return r.count == len(r.buf)
+}
+
+// Push appends x and reports whether it was stored. With overwrite enabled it
+// always succeeds, evicting the oldest element when the buffer is full.
+func (r *RingBuffer[T]) Push(x T) bool {
+	if r.Full() {
+		if !r.overwrite {
+			return false
+		}
+		r.buf[r.head] = x
+		r.head = (r.head + 1) % len(r.buf)
+		return true
+	}
+	r.buf[(r.head+r.count)%len(r.buf)] = x
+	r.count++
+	return true
+}
+
+// Pop removes and returns the oldest element.
+func (r *RingBuffer[T]) Pop() (T, bool) {
+	var zero T
+	if r.count == 0 {
+		return zero, false
+	}
+	x := r.buf[r.head]
+	r.buf[r.head] = zero
+	r.head = (r.head + 1) % len(r.buf)
+	r.count--
+	return x, true
+}
+
+// Peek returns the oldest element without removing it.
+func (r *RingBuffer[T]) Peek() (T, bool) {
+	if r.count == 0 {
+		var zero T
+		return zero, false
+	}
+	return r.buf[r.head], true
+}
+
+// ToSlice returns the elements from oldest to newest.
+func (r *RingBuffer[T]) ToSlice() []T {
+	dst := make([]T, r.count)
+	for i := range dst {
+		dst[i] = r.buf[(r.head+i)%len(r.buf)]
+	}
+	return dst
+}
+
+func (r *RingBuffer[T]) Clear() {
+	clear(r.buf)
+	r.head, r.count = 0, 0
+}
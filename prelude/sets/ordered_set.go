@@ -0,0 +1,73 @@
+package sets
+
+import (
+	"container/list"
+	"iter"
+)
+
+// OrderedSet is a set that remembers insertion order. Re-adding an existing
+// element keeps its original position. It is not safe for concurrent use.
+type OrderedSet[T comparable] struct {
+	index map[T]*list.Element
+	order *list.List
+}
+
+func NewOrderedSet[T comparable](elems ...T) *OrderedSet[T] {
+	s := &OrderedSet[T]{
+		index: make(map[T]*list.Element, len(elems)),
+		order: list.New(),
+	}
+	s.Add(elems...)
+	return s
+}
+
+func (s *OrderedSet[T]) Add(elems ...T) {
+	for _, elem := range elems {
+		if _, ok := s.index[elem]; ok {
+			continue
+		}
+		s.index[elem] = s.order.PushBack(elem)
+	}
+}
+
+func (s *OrderedSet[T]) Remove(elems ...T) {
+	for _, elem := range elems {
+		if e, ok := s.index[elem]; ok {
+			s.order.Remove(e)
+			delete(s.index, elem)
+		}
+	}
+}
+
+func (s *OrderedSet[T]) Contains(elem T) bool {
+	_, ok := s.index[elem]
+	return ok
+}
+
+func (s *OrderedSet[T]) Len() int {
+	return len(s.index)
+}
+
+// All returns an iterator over the elements in insertion order.
+func (s *OrderedSet[T]) All() iter.Seq[T] {
+	return func(yield func(T) bool) {
+		for e := s.order.Front(); e != nil; e = e.Next() {
+			if !yield(e.Value.(T)) {
+				return
+			}
+		}
+	}
+}
+
+// ToSlice returns the elements in insertion order.
+func (s *OrderedSet[T]) ToSlice() []T {
+	sl := make([]T, 0, s.Len())
+	for elem := range s.All() {
+		sl = append(sl, elem)
+	}
+	return sl
+}
+
+func (s *OrderedSet[T]) Clone() *OrderedSet[T] {
+	return NewOrderedSet(s.ToSlice()...)
+}
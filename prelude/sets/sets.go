@@ -24,3 +24,95 @@ func (s Set[T]) Contains(elem T) bool {
 	_, ok := s[elem]
 	return ok
 }
+
+func (s Set[T]) Add(elems ...T) {
+	for _, elem := range elems {
+		s[elem] = struct{}{}
+	}
+}
+
+func (s Set[T]) Remove(elems ...T) {
+	for _, elem := range elems {
+		delete(s, elem)
+	}
+}
+
+func (s Set[T]) Len() int {
+	return len(s)
+}
+
+func (s Set[T]) Clone() Set[T] {
+	c := make(Set[T], len(s))
+	for elem := range s {
+		c[elem] = struct{}{}
+	}
+	return c
+}
+
+// Union returns a new set with the elements of both s and other.
+func (s Set[T]) Union(other Set[T]) Set[T] {
+	big, small := s, other
+	if len(big) < len(small) {
+		big, small = small, big
+	}
+	u := big.Clone()
+	for elem := range small {
+		u[elem] = struct{}{}
+	}
+	return u
+}
+
+// Intersect returns a new set with the elements present in both s and other.
+func (s Set[T]) Intersect(other Set[T]) Set[T] {
+	big, small := s, other
+	if len(big) < len(small) {
+		big, small = small, big
+	}
+	i := make(Set[T], len(small))
+	for elem := range small {
+		if big.Contains(elem) {
+			i[elem] = struct{}{}
+		}
+	}
+	return i
+}
+
+// Difference returns a new set with the elements of s that are not in other.
+func (s Set[T]) Difference(other Set[T]) Set[T] {
+	d := make(Set[T], len(s))
+	for elem := range s {
+		if !other.Contains(elem) {
+			d[elem] = struct{}{}
+		}
+	}
+	return d
+}
+
+// SymmetricDifference returns a new set with the elements present in exactly one of s and other.
+func (s Set[T]) SymmetricDifference(other Set[T]) Set[T] {
+	d := s.Difference(other)
+	for elem := range other {
+		if !s.Contains(elem) {
+			d[elem] = struct{}{}
+		}
+	}
+	return d
+}
+
+// IsSubset reports whether every element of s is in other.
+func (s Set[T]) IsSubset(other Set[T]) bool {
+	if len(s) > len(other) {
+		return false
+	}
+	for elem := range s {
+		if !other.Contains(elem) {
+			return false
+		}
+	}
+	return true
+}
+
+// Equal reports whether s and other contain the same elements.
+func (s Set[T]) Equal(other Set[T]) bool {
+	return len(s) == len(other) && s.IsSubset(other)
+}
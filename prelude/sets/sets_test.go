@@ -0,0 +1,64 @@
+package sets
+
+import (
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSetAlgebra(t *testing.T) {
+	a := FromSlice([]int{1, 2, 3})
+	b := FromSlice([]int{2, 3, 4})
+
+	assert.True(t, a.Union(b).Equal(FromSlice([]int{1, 2, 3, 4})))
+	assert.True(t, a.Intersect(b).Equal(FromSlice([]int{2, 3})))
+	assert.True(t, a.Difference(b).Equal(FromSlice([]int{1})))
+	assert.True(t, a.SymmetricDifference(b).Equal(FromSlice([]int{1, 4})))
+
+	assert.True(t, FromSlice([]int{2}).IsSubset(a))
+	assert.False(t, a.IsSubset(b))
+	assert.False(t, a.Equal(b))
+
+	c := a.Clone()
+	c.Add(5, 6)
+	c.Remove(1)
+	assert.Equal(t, 3, a.Len())
+	assert.Equal(t, 4, c.Len())
+	assert.False(t, c.Contains(1))
+	assert.True(t, a.Contains(1))
+}
+
+func TestSyncSet(t *testing.T) {
+	s := NewSyncSet[int]()
+
+	var wg sync.WaitGroup
+	var added atomic.Int32
+	for i := 0; i < 100; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if s.AddIfAbsent(i % 10) {
+				added.Add(1)
+			}
+		}()
+	}
+	wg.Wait()
+
+	assert.Equal(t, int32(10), added.Load())
+	assert.Equal(t, 10, s.Len())
+	assert.True(t, s.Equal(SyncFromSlice([]int{0, 1, 2, 3, 4, 5, 6, 7, 8, 9})))
+}
+
+func TestOrderedSet(t *testing.T) {
+	s := NewOrderedSet("c", "a", "b", "a")
+	assert.Equal(t, []string{"c", "a", "b"}, s.ToSlice())
+
+	s.Add("d", "c")
+	s.Remove("a")
+	assert.Equal(t, []string{"c", "b", "d"}, s.ToSlice())
+	assert.True(t, s.Contains("d"))
+	assert.False(t, s.Contains("a"))
+	assert.Equal(t, 3, s.Len())
+}
@@ -0,0 +1,95 @@
+package sets
+
+import "sync"
+
+// SyncSet is a Set safe for concurrent use. The zero value is not usable,
+// create one with NewSyncSet or SyncFromSlice.
+type SyncSet[T comparable] struct {
+	mu  sync.RWMutex
+	set Set[T]
+}
+
+func NewSyncSet[T comparable](elems ...T) *SyncSet[T] {
+	return SyncFromSlice(elems)
+}
+
+func SyncFromSlice[T comparable](s []T) *SyncSet[T] {
+	return &SyncSet[T]{set: FromSlice(s)}
+}
+
+func (s *SyncSet[T]) ToSlice() []T {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.set.ToSlice()
+}
+
+func (s *SyncSet[T]) Contains(elem T) bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.set.Contains(elem)
+}
+
+func (s *SyncSet[T]) Add(elems ...T) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.set.Add(elems...)
+}
+
+// AddIfAbsent adds elem and returns true if it was not already present,
+// as a single atomic step.
+func (s *SyncSet[T]) AddIfAbsent(elem T) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.set.Contains(elem) {
+		return false
+	}
+	s.set[elem] = struct{}{}
+	return true
+}
+
+func (s *SyncSet[T]) Remove(elems ...T) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.set.Remove(elems...)
+}
+
+func (s *SyncSet[T]) Len() int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.set.Len()
+}
+
+func (s *SyncSet[T]) Clone() *SyncSet[T] {
+	return &SyncSet[T]{set: s.Snapshot()}
+}
+
+// Snapshot returns a copy of the current elements as a plain Set.
+func (s *SyncSet[T]) Snapshot() Set[T] {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.set.Clone()
+}
+
+func (s *SyncSet[T]) Union(other *SyncSet[T]) *SyncSet[T] {
+	return &SyncSet[T]{set: s.Snapshot().Union(other.Snapshot())}
+}
+
+func (s *SyncSet[T]) Intersect(other *SyncSet[T]) *SyncSet[T] {
+	return &SyncSet[T]{set: s.Snapshot().Intersect(other.Snapshot())}
+}
+
+func (s *SyncSet[T]) Difference(other *SyncSet[T]) *SyncSet[T] {
+	return &SyncSet[T]{set: s.Snapshot().Difference(other.Snapshot())}
+}
+
+func (s *SyncSet[T]) SymmetricDifference(other *SyncSet[T]) *SyncSet[T] {
+	return &SyncSet[T]{set: s.Snapshot().SymmetricDifference(other.Snapshot())}
+}
+
+func (s *SyncSet[T]) IsSubset(other *SyncSet[T]) bool {
+	return s.Snapshot().IsSubset(other.Snapshot())
+}
+
+func (s *SyncSet[T]) Equal(other *SyncSet[T]) bool {
+	return s.Snapshot().Equal(other.Snapshot())
+}
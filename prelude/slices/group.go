@@ -0,0 +1,60 @@
+package slices
+
+// Number is the set of types SumBy can add up.
+type Number interface {
+	~int | ~int8 | ~int16 | ~int32 | ~int64 |
+		~uint | ~uint8 | ~uint16 | ~uint32 | ~uint64 | ~uintptr |
+		~float32 | ~float64
+}
+
+// GroupByKey groups the elements of src by the key returned by fn, keeping their order.
+func GroupByKey[E any, K comparable](src []E, fn func(E) K) map[K][]E {
+	if src == nil {
+		return nil
+	}
+	dst := make(map[K][]E)
+	for _, e := range src {
+		k := fn(e)
+		dst[k] = append(dst[k], e)
+	}
+	return dst
+}
+
+// CountBy counts the elements of src per key returned by fn.
+func CountBy[E any, K comparable](src []E, fn func(E) K) map[K]int {
+	if src == nil {
+		return nil
+	}
+	dst := make(map[K]int)
+	for _, e := range src {
+		dst[fn(e)]++
+	}
+	return dst
+}
+
+// SumBy sums the values returned by fn per key.
+func SumBy[E any, K comparable, N Number](src []E, fn func(E) (K, N)) map[K]N {
+	if src == nil {
+		return nil
+	}
+	dst := make(map[K]N)
+	for _, e := range src {
+		k, n := fn(e)
+		dst[k] += n
+	}
+	return dst
+}
+
+// GroupReduce groups the elements of src by keyFn and folds each group with reduceFn,
+// starting from the zero value of A.
+func GroupReduce[E any, K comparable, A any](src []E, keyFn func(E) K, reduceFn func(acc A, e E) A) map[K]A {
+	if src == nil {
+		return nil
+	}
+	dst := make(map[K]A)
+	for _, e := range src {
+		k := keyFn(e)
+		dst[k] = reduceFn(dst[k], e)
+	}
+	return dst
+}
@@ -59,6 +59,55 @@ func Unique[T comparable](src []T) []T {
 	return dst
 }
 
+// Compact returns a copy of src with runs of equal adjacent elements collapsed into one.
+func Compact[T comparable](src []T) []T {
+	if src == nil {
+		return nil
+	}
+	dst := make([]T, 0, len(src))
+	for i, s := range src {
+		if i > 0 && s == src[i-1] {
+			continue
+		}
+		dst = append(dst, s)
+	}
+
+	return dst
+}
+
+// DedupBy keeps the first element for each key returned by fn, preserving order.
+func DedupBy[E any, K comparable](src []E, fn func(E) K) []E {
+	if src == nil {
+		return nil
+	}
+	dst := make([]E, 0, len(src))
+	m := make(map[K]struct{}, len(src))
+	for _, e := range src {
+		k := fn(e)
+		if _, ok := m[k]; ok {
+			continue
+		}
+		dst = append(dst, e)
+		m[k] = struct{}{}
+	}
+
+	return dst
+}
+
+// EqualBy reports whether a and b have the same length and eq holds for every pair of elements at the same index.
+func EqualBy[A, B any](a []A, b []B, eq func(A, B) bool) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if !eq(a[i], b[i]) {
+			return false
+		}
+	}
+
+	return true
+}
+
 func Fill[T any](val T, size int) []T {
 	slice := make([]T, size)
 	for i := 0; i < size; i++ {
@@ -68,6 +117,10 @@ func Fill[T any](val T, size int) []T {
 }
 
 func Chunks[T any](s []T, chunkSize int) [][]T {
+	if chunkSize <= 0 {
+		return nil
+	}
+
 	sliceLen := len(s)
 	chunks := make([][]T, 0, sliceLen/chunkSize)
 
@@ -107,3 +160,32 @@ func Reverse[T any](slice []T) []T {
 	}
 	return slice
 }
+
+// Window returns the sliding windows of src of length size, advancing by step.
+// A trailing window shorter than size is dropped. The windows share memory with src.
+func Window[T any](src []T, size, step int) [][]T {
+	if size <= 0 || step <= 0 || len(src) < size {
+		return nil
+	}
+
+	windows := make([][]T, 0, (len(src)-size)/step+1)
+	for start := 0; start+size <= len(src); start += step {
+		windows = append(windows, src[start:start+size:start+size])
+	}
+
+	return windows
+}
+
+// Pairwise applies fn to each pair of adjacent elements of src.
+func Pairwise[T, R any](src []T, fn func(prev, next T) R) []R {
+	if len(src) < 2 {
+		return nil
+	}
+
+	dst := make([]R, 0, len(src)-1)
+	for i := 1; i < len(src); i++ {
+		dst = append(dst, fn(src[i-1], src[i]))
+	}
+
+	return dst
+}
@@ -0,0 +1,58 @@
+package slices
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWindow(t *testing.T) {
+	src := []int{1, 2, 3, 4, 5}
+
+	assert.Equal(t, [][]int{{1, 2, 3}, {2, 3, 4}, {3, 4, 5}}, Window(src, 3, 1))
+	assert.Equal(t, [][]int{{1, 2}, {3, 4}}, Window(src, 2, 2))
+	assert.Nil(t, Window(src, 6, 1))
+	assert.Nil(t, Window(src, 0, 1))
+	assert.Nil(t, Window(src, 2, 0))
+
+	// appending to a window must not clobber src.
+	w := Window(src, 2, 1)
+	_ = append(w[0], 100)
+	assert.Equal(t, []int{1, 2, 3, 4, 5}, src)
+}
+
+func TestPairwise(t *testing.T) {
+	diffs := Pairwise([]int{1, 4, 9, 16}, func(prev, next int) int { return next - prev })
+	assert.Equal(t, []int{3, 5, 7}, diffs)
+	assert.Nil(t, Pairwise([]int{1}, func(prev, next int) int { return next - prev }))
+}
+
+func TestGroupAggregations(t *testing.T) {
+	type order struct {
+		User   string
+		Amount float64
+	}
+	orders := []order{{"a", 1}, {"b", 2}, {"a", 3}}
+	byUser := func(o order) string { return o.User }
+
+	assert.Equal(t, map[string][]order{"a": {{"a", 1}, {"a", 3}}, "b": {{"b", 2}}}, GroupByKey(orders, byUser))
+	assert.Equal(t, map[string]int{"a": 2, "b": 1}, CountBy(orders, byUser))
+	assert.Equal(t, map[string]float64{"a": 4, "b": 2}, SumBy(orders, func(o order) (string, float64) { return o.User, o.Amount }))
+
+	maxAmount := GroupReduce(orders, byUser, func(acc float64, o order) float64 { return max(acc, o.Amount) })
+	assert.Equal(t, map[string]float64{"a": 3, "b": 2}, maxAmount)
+}
+
+func TestDedup(t *testing.T) {
+	assert.Equal(t, []int{1, 2, 1, 3}, Compact([]int{1, 1, 2, 2, 2, 1, 3, 3}))
+
+	type node struct{ ID, Name string }
+	nodes := []*node{{"1", "a"}, {"2", "b"}, {"1", "c"}}
+	byID := func(n *node) string { return n.ID }
+	assert.Equal(t, []*node{nodes[0], nodes[1]}, DedupBy(nodes, byID))
+
+	others := []*node{{"1", "x"}, {"2", "y"}, {"1", "z"}}
+	sameID := func(a, b *node) bool { return a.ID == b.ID }
+	assert.True(t, EqualBy(nodes, others, sameID))
+	assert.False(t, EqualBy(nodes, others[:2], sameID))
+}
@@ -0,0 +1,69 @@
+package slices
+
+import (
+	"context"
+	"runtime"
+
+	"github.com/me2seeks/forge/taskgroup"
+)
+
+// TransformParallel is like Transform but runs fn on at most workers goroutines
+// at a time. The output keeps the order of src. If workers <= 0, GOMAXPROCS is used.
+// A panic in fn is re-raised once all elements are done, as an error carrying its stack.
+func TransformParallel[A, B any](ctx context.Context, src []A, workers int, fn func(A) B) []B {
+	if src == nil {
+		return nil
+	}
+
+	dst := make([]B, len(src))
+	tg := taskgroup.NewUninterruptibleTaskGroup(ctx, parallelWorkers(workers, len(src)))
+	for i, a := range src {
+		tg.Go(func() error {
+			dst[i] = fn(a)
+			return nil
+		})
+	}
+	// the group runs every task, so it only fails on a recovered panic.
+	if err := tg.Wait(); err != nil {
+		panic(err)
+	}
+
+	return dst
+}
+
+// TransformParallelWithErrorCheck is like TransformWithErrorCheck but runs fn on at most
+// workers goroutines at a time. The first error cancels the elements not yet started
+// and is returned; the output keeps the order of src.
+func TransformParallelWithErrorCheck[A, B any](ctx context.Context, src []A, workers int, fn func(A) (B, error)) ([]B, error) {
+	if src == nil {
+		return nil, nil
+	}
+
+	dst := make([]B, len(src))
+	tg := taskgroup.NewTaskGroup(ctx, parallelWorkers(workers, len(src)))
+	for i, a := range src {
+		tg.Go(func() error {
+			item, err := fn(a)
+			if err != nil {
+				return err
+			}
+			dst[i] = item
+			return nil
+		})
+	}
+	if err := tg.Wait(); err != nil {
+		return nil, err
+	}
+
+	return dst, nil
+}
+
+func parallelWorkers(workers, size int) int {
+	if workers <= 0 {
+		workers = runtime.GOMAXPROCS(0)
+	}
+	if size > 0 && workers > size {
+		workers = size
+	}
+	return workers
+}
@@ -0,0 +1,51 @@
+package slices
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTransformParallel(t *testing.T) {
+	ctx := context.Background()
+	src := make([]int, 100)
+	for i := range src {
+		src[i] = i
+	}
+
+	dst := TransformParallel(ctx, src, 8, strconv.Itoa)
+	assert.Equal(t, Transform(src, strconv.Itoa), dst)
+
+	func() {
+		defer func() {
+			err, ok := recover().(error)
+			assert.True(t, ok)
+			assert.ErrorContains(t, err, "boom")
+		}()
+		TransformParallel(ctx, src, 4, func(i int) string {
+			if i == 42 {
+				panic("boom")
+			}
+			return strconv.Itoa(i)
+		})
+	}()
+
+	dst, err := TransformParallelWithErrorCheck(ctx, src, 0, func(i int) (string, error) {
+		return strconv.Itoa(i), nil
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, Transform(src, strconv.Itoa), dst)
+
+	errBoom := errors.New("boom")
+	dst, err = TransformParallelWithErrorCheck(ctx, src, 4, func(i int) (string, error) {
+		if i == 42 {
+			return "", errBoom
+		}
+		return strconv.Itoa(i), nil
+	})
+	assert.ErrorIs(t, err, errBoom)
+	assert.Nil(t, dst)
+}
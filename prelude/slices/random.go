@@ -0,0 +1,52 @@
+package slices
+
+import (
+	"math/rand/v2"
+)
+
+// Shuffle returns a pseudo-random permutation of src, leaving src untouched.
+// A nil r uses the global source; pass a seeded *rand.Rand for deterministic results.
+func Shuffle[T any](src []T, r *rand.Rand) []T {
+	if src == nil {
+		return nil
+	}
+
+	intN := rand.IntN
+	if r != nil {
+		intN = r.IntN
+	}
+
+	dst := make([]T, len(src))
+	copy(dst, src)
+	for i := len(dst) - 1; i > 0; i-- {
+		j := intN(i + 1)
+		dst[i], dst[j] = dst[j], dst[i]
+	}
+	return dst
+}
+
+// Sample returns n elements picked from src without replacement, leaving src untouched.
+// If n >= len(src), all elements are returned in random order.
+func Sample[T any](src []T, n int, r *rand.Rand) []T {
+	if n <= 0 || len(src) == 0 {
+		return nil
+	}
+	if n > len(src) {
+		n = len(src)
+	}
+
+	intN := rand.IntN
+	if r != nil {
+		intN = r.IntN
+	}
+
+	// partial Fisher-Yates over a copy, only the first n positions are settled.
+	dst := make([]T, len(src))
+	copy(dst, src)
+	for i := 0; i < n; i++ {
+		j := i + intN(len(dst)-i)
+		dst[i], dst[j] = dst[j], dst[i]
+	}
+
+	return dst[:n:n]
+}
@@ -0,0 +1,51 @@
+package slices
+
+import (
+	"math/rand/v2"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func seeded() *rand.Rand {
+	return rand.New(rand.NewPCG(1, 2))
+}
+
+func TestShuffle(t *testing.T) {
+	src := []int{1, 2, 3, 4, 5, 6, 7, 8}
+
+	got := Shuffle(src, seeded())
+	assert.Equal(t, got, Shuffle(src, seeded()), "the same seed must give the same permutation")
+	assert.ElementsMatch(t, src, got)
+	assert.Equal(t, []int{1, 2, 3, 4, 5, 6, 7, 8}, src, "src must be left untouched")
+
+	assert.Nil(t, Shuffle[int](nil, seeded()))
+	assert.Equal(t, []int{}, Shuffle([]int{}, seeded()))
+}
+
+func TestSample(t *testing.T) {
+	src := []int{1, 2, 3, 4, 5, 6, 7, 8}
+
+	got := Sample(src, 3, seeded())
+	assert.Len(t, got, 3)
+	assert.Equal(t, got, Sample(src, 3, seeded()), "the same seed must give the same sample")
+	assert.Subset(t, src, got)
+	assert.Equal(t, []int{1, 2, 3, 4, 5, 6, 7, 8}, src, "src must be left untouched")
+
+	// src has no duplicates, so neither must a sample of it.
+	for range 100 {
+		seen := make(map[int]bool)
+		for _, v := range Sample(src, 5, nil) {
+			assert.False(t, seen[v], "%d sampled twice", v)
+			seen[v] = true
+		}
+	}
+
+	assert.Nil(t, Sample(src, 0, seeded()))
+	assert.Nil(t, Sample(src, -1, seeded()))
+	assert.Nil(t, Sample[int](nil, 3, seeded()))
+
+	all := Sample(src, 10, seeded())
+	assert.Len(t, all, len(src))
+	assert.ElementsMatch(t, src, all)
+}
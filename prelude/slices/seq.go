@@ -0,0 +1,68 @@
+package slices
+
+import "iter"
+
+// Values returns an iterator over the elements of src in order.
+func Values[T any](src []T) iter.Seq[T] {
+	return func(yield func(T) bool) {
+		for _, v := range src {
+			if !yield(v) {
+				return
+			}
+		}
+	}
+}
+
+// Collect drains seq into a newly allocated slice.
+func Collect[T any](seq iter.Seq[T]) []T {
+	var dst []T
+	for v := range seq {
+		dst = append(dst, v)
+	}
+	return dst
+}
+
+// TransformIter is the lazy counterpart of Transform: fn is applied to each
+// element only when the returned iterator is consumed.
+func TransformIter[A, B any](src []A, fn func(A) B) iter.Seq[B] {
+	return Map(Values(src), fn)
+}
+
+// Filter returns an iterator yielding only the elements of seq for which
+// predicate returns true.
+func Filter[T any](seq iter.Seq[T], predicate func(T) bool) iter.Seq[T] {
+	return func(yield func(T) bool) {
+		for v := range seq {
+			if predicate(v) && !yield(v) {
+				return
+			}
+		}
+	}
+}
+
+// Map returns an iterator yielding fn applied to each element of seq.
+func Map[A, B any](seq iter.Seq[A], fn func(A) B) iter.Seq[B] {
+	return func(yield func(B) bool) {
+		for a := range seq {
+			if !yield(fn(a)) {
+				return
+			}
+		}
+	}
+}
+
+// ChunksIter is the lazy counterpart of Chunks. It yields nothing if chunkSize <= 0.
+// The chunks share memory with s.
+func ChunksIter[T any](s []T, chunkSize int) iter.Seq[[]T] {
+	return func(yield func([]T) bool) {
+		if chunkSize <= 0 {
+			return
+		}
+		for start := 0; start < len(s); start += chunkSize {
+			end := min(start+chunkSize, len(s))
+			if !yield(s[start:end:end]) {
+				return
+			}
+		}
+	}
+}
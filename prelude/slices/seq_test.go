@@ -0,0 +1,37 @@
+package slices
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSeq(t *testing.T) {
+	src := []int{1, 2, 3, 4, 5, 6}
+
+	assert.Equal(t, src, Collect(Values(src)))
+	assert.Nil(t, Collect(Values[int](nil)))
+
+	evens := Filter(Values(src), func(i int) bool { return i%2 == 0 })
+	assert.Equal(t, []int{2, 4, 6}, Collect(evens))
+
+	squares := Map(evens, func(i int) int { return i * i })
+	assert.Equal(t, []int{4, 16, 36}, Collect(squares))
+
+	strs := TransformIter(src, func(i int) string { return string(rune('a' + i - 1)) })
+	assert.Equal(t, []string{"a", "b", "c", "d", "e", "f"}, Collect(strs))
+
+	// Map must be evaluated lazily and stop as soon as the consumer stops.
+	calls := 0
+	for range Map(Values(src), func(i int) int { calls++; return i }) {
+		break
+	}
+	assert.Equal(t, 1, calls)
+}
+
+func TestChunksIter(t *testing.T) {
+	src := []int{1, 2, 3, 4, 5}
+	assert.Equal(t, Chunks(src, 2), Collect(ChunksIter(src, 2)))
+	assert.Nil(t, Collect(ChunksIter(src, 0)))
+	assert.Nil(t, Chunks(src, 0))
+}
@@ -0,0 +1,91 @@
+package slices
+
+// Union returns the distinct elements of a followed by the distinct elements
+// of b that are not in a, in first-seen order.
+func Union[T comparable](a, b []T) []T {
+	return UnionBy(a, b, identity[T])
+}
+
+// Intersect returns the distinct elements of a that are also in b, in the order of a.
+func Intersect[T comparable](a, b []T) []T {
+	return IntersectBy(a, b, identity[T])
+}
+
+// Difference returns the distinct elements of a that are not in b, in the order of a.
+func Difference[T comparable](a, b []T) []T {
+	return DifferenceBy(a, b, identity[T])
+}
+
+// SymmetricDifference returns the distinct elements that are in exactly one of a and b,
+// those from a first.
+func SymmetricDifference[T comparable](a, b []T) []T {
+	return SymmetricDifferenceBy(a, b, identity[T])
+}
+
+// UnionBy is like Union but compares elements by the key returned by fn.
+func UnionBy[E any, K comparable](a, b []E, fn func(E) K) []E {
+	seen := make(map[K]struct{}, len(a)+len(b))
+	dst := make([]E, 0, len(a)+len(b))
+	for _, src := range [][]E{a, b} {
+		for _, e := range src {
+			k := fn(e)
+			if _, ok := seen[k]; ok {
+				continue
+			}
+			seen[k] = struct{}{}
+			dst = append(dst, e)
+		}
+	}
+	return dst
+}
+
+// IntersectBy is like Intersect but compares elements by the key returned by fn.
+func IntersectBy[E any, K comparable](a, b []E, fn func(E) K) []E {
+	inB := keySet(b, fn)
+	return filterDistinct(a, fn, func(k K) bool {
+		_, ok := inB[k]
+		return ok
+	})
+}
+
+// DifferenceBy is like Difference but compares elements by the key returned by fn.
+func DifferenceBy[E any, K comparable](a, b []E, fn func(E) K) []E {
+	inB := keySet(b, fn)
+	return filterDistinct(a, fn, func(k K) bool {
+		_, ok := inB[k]
+		return !ok
+	})
+}
+
+// SymmetricDifferenceBy is like SymmetricDifference but compares elements by the key returned by fn.
+func SymmetricDifferenceBy[E any, K comparable](a, b []E, fn func(E) K) []E {
+	return append(DifferenceBy(a, b, fn), DifferenceBy(b, a, fn)...)
+}
+
+func identity[T any](t T) T {
+	return t
+}
+
+func keySet[E any, K comparable](src []E, fn func(E) K) map[K]struct{} {
+	m := make(map[K]struct{}, len(src))
+	for _, e := range src {
+		m[fn(e)] = struct{}{}
+	}
+	return m
+}
+
+func filterDistinct[E any, K comparable](src []E, fn func(E) K, keep func(K) bool) []E {
+	seen := make(map[K]struct{}, len(src))
+	dst := make([]E, 0, len(src))
+	for _, e := range src {
+		k := fn(e)
+		if _, ok := seen[k]; ok {
+			continue
+		}
+		seen[k] = struct{}{}
+		if keep(k) {
+			dst = append(dst, e)
+		}
+	}
+	return dst
+}
@@ -0,0 +1,34 @@
+package slices
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSetOps(t *testing.T) {
+	a := []int{3, 1, 2, 3, 4}
+	b := []int{4, 5, 1, 5}
+
+	assert.Equal(t, []int{3, 1, 2, 4, 5}, Union(a, b))
+	assert.Equal(t, []int{1, 4}, Intersect(a, b))
+	assert.Equal(t, []int{3, 2}, Difference(a, b))
+	assert.Equal(t, []int{3, 2, 5}, SymmetricDifference(a, b))
+	assert.Empty(t, Intersect(a, nil))
+}
+
+func TestSetOpsBy(t *testing.T) {
+	type user struct {
+		ID   int
+		Name string
+	}
+	byID := func(u user) int { return u.ID }
+
+	a := []user{{1, "a"}, {2, "b"}}
+	b := []user{{2, "b2"}, {3, "c"}}
+
+	assert.Equal(t, []user{{1, "a"}, {2, "b"}, {3, "c"}}, UnionBy(a, b, byID))
+	assert.Equal(t, []user{{2, "b"}}, IntersectBy(a, b, byID))
+	assert.Equal(t, []user{{1, "a"}}, DifferenceBy(a, b, byID))
+	assert.Equal(t, []user{{1, "a"}, {3, "c"}}, SymmetricDifferenceBy(a, b, byID))
+}
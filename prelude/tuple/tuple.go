@@ -0,0 +1,70 @@
+package tuple
+
+// Pair is a tuple of two values.
+type Pair[T, U any] struct {
+	Value1 T
+	Value2 U
+}
+
+// Triple is a tuple of three values.
+type Triple[T, U, V any] struct {
+	Value1 T
+	Value2 U
+	Value3 V
+}
+
+func NewPair[T, U any](v1 T, v2 U) Pair[T, U] {
+	return Pair[T, U]{Value1: v1, Value2: v2}
+}
+
+func NewTriple[T, U, V any](v1 T, v2 U, v3 V) Triple[T, U, V] {
+	return Triple[T, U, V]{Value1: v1, Value2: v2, Value3: v3}
+}
+
+func (p Pair[T, U]) Unpack() (T, U) {
+	return p.Value1, p.Value2
+}
+
+func (p Pair[T, U]) Swap() Pair[U, T] {
+	return Pair[U, T]{Value1: p.Value2, Value2: p.Value1}
+}
+
+func (t Triple[T, U, V]) Unpack() (T, U, V) {
+	return t.Value1, t.Value2, t.Value3
+}
+
+func MapFirst[T, U, R any](p Pair[T, U], fn func(T) R) Pair[R, U] {
+	return Pair[R, U]{Value1: fn(p.Value1), Value2: p.Value2}
+}
+
+func MapSecond[T, U, R any](p Pair[T, U], fn func(U) R) Pair[T, R] {
+	return Pair[T, R]{Value1: p.Value1, Value2: fn(p.Value2)}
+}
+
+// Zip pairs up the elements of a and b by index, stopping at the shorter slice.
+func Zip[T, U any](a []T, b []U) []Pair[T, U] {
+	if a == nil || b == nil {
+		return nil
+	}
+
+	n := min(len(a), len(b))
+	dst := make([]Pair[T, U], n)
+	for i := 0; i < n; i++ {
+		dst[i] = Pair[T, U]{Value1: a[i], Value2: b[i]}
+	}
+	return dst
+}
+
+// Unzip splits pairs into two slices of their first and second values.
+func Unzip[T, U any](pairs []Pair[T, U]) ([]T, []U) {
+	if pairs == nil {
+		return nil, nil
+	}
+
+	a := make([]T, len(pairs))
+	b := make([]U, len(pairs))
+	for i, p := range pairs {
+		a[i], b[i] = p.Value1, p.Value2
+	}
+	return a, b
+}
@@ -0,0 +1,22 @@
+package tuple
+
+import (
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPair(t *testing.T) {
+	p := NewPair(1, "a")
+	assert.Equal(t, NewPair("a", 1), p.Swap())
+	assert.Equal(t, NewPair("1", "a"), MapFirst(p, strconv.Itoa))
+	assert.Equal(t, NewPair(1, 1), MapSecond(p, func(s string) int { return len(s) }))
+
+	pairs := Zip([]int{1, 2, 3}, []string{"a", "b"})
+	assert.Equal(t, []Pair[int, string]{{1, "a"}, {2, "b"}}, pairs)
+
+	nums, strs := Unzip(pairs)
+	assert.Equal(t, []int{1, 2}, nums)
+	assert.Equal(t, []string{"a", "b"}, strs)
+}
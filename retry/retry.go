@@ -0,0 +1,208 @@
+// Package retry calls functions until they succeed under a Policy bounding the
+// attempts, the delays between them and the total time spent.
+//
+//	doc, err := retry.Do(ctx, retry.DefaultPolicy, func(ctx context.Context) (*Doc, error) {
+//		return client.Get(ctx, id)
+//	})
+//
+// By default every error is retried except context errors, errors marked with
+// Permanent and errorx status errors that don't affect stability, which are
+// business errors a retry won't fix.
+package retry
+
+import (
+	"context"
+	"errors"
+	"math"
+	"math/rand/v2"
+	"time"
+
+	"github.com/me2seeks/forge/errorx"
+	"github.com/me2seeks/forge/logs"
+)
+
+// Backoff returns the delay before the given retry, starting at 1.
+type Backoff func(retry int) time.Duration
+
+// Constant waits d before every retry.
+func Constant(d time.Duration) Backoff {
+	return func(int) time.Duration {
+		return d
+	}
+}
+
+// Exponential doubles base on every retry, capped at max.
+func Exponential(base, max time.Duration) Backoff {
+	return func(retry int) time.Duration {
+		d := base
+		for i := 1; i < retry && d < max; i++ {
+			d *= 2
+		}
+		return min(d, max)
+	}
+}
+
+// Jitter randomizes the delays of b by up to fraction of themselves in either
+// direction, so clients failing together don't retry together.
+func Jitter(b Backoff, fraction float64) Backoff {
+	return func(retry int) time.Duration {
+		d := b(retry)
+		return d + time.Duration((rand.Float64()*2-1)*fraction*float64(d))
+	}
+}
+
+// Attempt describes a failed call, passed to Policy.OnRetry.
+type Attempt struct {
+	// Number is the number of the failed call, starting at 1.
+	Number int
+	Err    error
+	// Delay is the wait before the next call.
+	Delay time.Duration
+	// Elapsed is the time since the first call started.
+	Elapsed time.Duration
+}
+
+// Unlimited is the MaxAttempts of a Policy retrying until MaxElapsed, a
+// non-retryable error or ctx ends the calls.
+const Unlimited = math.MaxInt
+
+type Policy struct {
+	// MaxAttempts is the total number of calls, including the first one. Values
+	// < 1 mean 1, so the zero Policy makes a single call; Unlimited opts out of
+	// the limit.
+	MaxAttempts int
+	// Backoff returns the delay before each retry. Nil means no delay.
+	Backoff Backoff
+	// MaxElapsed stops the retries once the next call would start later than
+	// this after the first one, if positive.
+	MaxElapsed time.Duration
+	// AttemptTimeout bounds each call, if positive.
+	AttemptTimeout time.Duration
+	// Retryable reports whether err is worth retrying. Nil means IsRetryable.
+	Retryable func(err error) bool
+	// OnRetry is called before waiting for each retry, e.g. to log or count retries.
+	OnRetry func(ctx context.Context, attempt Attempt)
+}
+
+// DefaultPolicy makes 3 calls with jittered exponential delays from 100ms.
+var DefaultPolicy = Policy{
+	MaxAttempts: 3,
+	Backoff:     Jitter(Exponential(100*time.Millisecond, 5*time.Second), 0.2),
+}
+
+type permanentError struct {
+	err error
+}
+
+func (e *permanentError) Error() string {
+	return e.err.Error()
+}
+
+func (e *permanentError) Unwrap() error {
+	return e.err
+}
+
+// Permanent marks err as not worth retrying. It returns nil if err is nil.
+func Permanent(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &permanentError{err: err}
+}
+
+// IsRetryable is the default Policy.Retryable.
+func IsRetryable(err error) bool {
+	var pe *permanentError
+	if errors.As(err, &pe) || errors.Is(err, context.Canceled) {
+		return false
+	}
+	var se errorx.StatusError
+	if errors.As(err, &se) {
+		return se.IsAffectStability()
+	}
+	return true
+}
+
+// LogRetries returns an OnRetry hook logging each retry of the operation name.
+func LogRetries(name string) func(ctx context.Context, attempt Attempt) {
+	return func(ctx context.Context, attempt Attempt) {
+		logs.CtxWarnf(ctx, "[retry] %s attempt %d failed, retrying in %v: %v", name, attempt.Number, attempt.Delay, attempt.Err)
+	}
+}
+
+// Do calls fn until it succeeds, returns a non-retryable error, the policy is
+// exhausted or ctx is done. It returns the result of the last call, whose error
+// is unwrapped from Permanent, or ctx.Err() if ctx ended the retries. An error
+// with a RetryAfter() time.Duration method, e.g. for a throttled request, waits
+// at least that long before the next call.
+func Do[T any](ctx context.Context, policy Policy, fn func(ctx context.Context) (T, error)) (T, error) {
+	retryable := policy.Retryable
+	if retryable == nil {
+		retryable = IsRetryable
+	}
+	attempts := max(policy.MaxAttempts, 1)
+	start := time.Now()
+
+	for attempt := 1; ; attempt++ {
+		res, err := call(ctx, policy.AttemptTimeout, fn)
+		if err == nil {
+			return res, nil
+		}
+		if ctx.Err() != nil {
+			return res, ctx.Err()
+		}
+		if attempt >= attempts || !retryable(err) {
+			return res, unwrapPermanent(err)
+		}
+
+		var delay time.Duration
+		if policy.Backoff != nil {
+			delay = max(policy.Backoff(attempt), 0)
+		}
+		var hint interface{ RetryAfter() time.Duration }
+		if errors.As(err, &hint) {
+			delay = max(delay, hint.RetryAfter())
+		}
+		elapsed := time.Since(start)
+		if policy.MaxElapsed > 0 && elapsed+delay > policy.MaxElapsed {
+			return res, err
+		}
+		if policy.OnRetry != nil {
+			policy.OnRetry(ctx, Attempt{Number: attempt, Err: err, Delay: delay, Elapsed: elapsed})
+		}
+
+		if delay > 0 {
+			timer := time.NewTimer(delay)
+			select {
+			case <-ctx.Done():
+				timer.Stop()
+				return res, ctx.Err()
+			case <-timer.C:
+			}
+		}
+	}
+}
+
+// Run is Do for functions without result.
+func Run(ctx context.Context, policy Policy, fn func(ctx context.Context) error) error {
+	_, err := Do(ctx, policy, func(ctx context.Context) (struct{}, error) {
+		return struct{}{}, fn(ctx)
+	})
+	return err
+}
+
+func call[T any](ctx context.Context, timeout time.Duration, fn func(ctx context.Context) (T, error)) (T, error) {
+	if timeout <= 0 {
+		return fn(ctx)
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	return fn(ctx)
+}
+
+func unwrapPermanent(err error) error {
+	if pe, ok := err.(*permanentError); ok {
+		return pe.err
+	}
+	return err
+}
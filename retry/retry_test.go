@@ -0,0 +1,131 @@
+package retry
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/me2seeks/forge/errorx"
+	"github.com/me2seeks/forge/errorx/code"
+)
+
+func TestDo(t *testing.T) {
+	ctx := context.Background()
+
+	var attempts []Attempt
+	calls := 0
+	res, err := Do(ctx, Policy{
+		MaxAttempts: 5,
+		Backoff:     Constant(time.Millisecond),
+		OnRetry:     func(_ context.Context, a Attempt) { attempts = append(attempts, a) },
+	}, func(context.Context) (int, error) {
+		calls++
+		if calls < 3 {
+			return 0, errors.New("unavailable")
+		}
+		return 42, nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 42, res)
+	require.Len(t, attempts, 2)
+	assert.Equal(t, 2, attempts[1].Number)
+	assert.Equal(t, time.Millisecond, attempts[1].Delay)
+
+	calls = 0
+	err = Run(ctx, Policy{MaxAttempts: 5}, func(context.Context) error {
+		calls++
+		return Permanent(errors.New("bad request"))
+	})
+	assert.EqualError(t, err, "bad request")
+	assert.Equal(t, 1, calls)
+
+	// The zero policy makes a single call.
+	calls = 0
+	err = Run(ctx, Policy{}, func(context.Context) error {
+		calls++
+		return errors.New("unavailable")
+	})
+	assert.EqualError(t, err, "unavailable")
+	assert.Equal(t, 1, calls)
+
+	// Unlimited attempts stop once the next one would exceed MaxElapsed.
+	calls = 0
+	err = Run(ctx, Policy{MaxAttempts: Unlimited, Backoff: Constant(10 * time.Millisecond), MaxElapsed: 35 * time.Millisecond}, func(context.Context) error {
+		calls++
+		return errors.New("unavailable")
+	})
+	assert.EqualError(t, err, "unavailable")
+	assert.True(t, calls > 1 && calls <= 4, "calls = %d", calls)
+}
+
+func TestAttemptTimeout(t *testing.T) {
+	calls := 0
+	err := Run(context.Background(), Policy{MaxAttempts: 2, AttemptTimeout: time.Millisecond}, func(ctx context.Context) error {
+		calls++
+		<-ctx.Done()
+		return ctx.Err()
+	})
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Equal(t, 2, calls)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	err = Run(ctx, Policy{MaxAttempts: Unlimited, Backoff: Constant(time.Hour)}, func(context.Context) error {
+		cancel()
+		return errors.New("unavailable")
+	})
+	assert.ErrorIs(t, err, context.Canceled)
+}
+
+func TestIsRetryable(t *testing.T) {
+	code.Register(1001, "quota exceeded", code.WithAffectStability(false))
+	code.Register(1002, "dependency down", code.WithAffectStability(true))
+
+	assert.True(t, IsRetryable(errors.New("eof")))
+	assert.False(t, IsRetryable(context.Canceled))
+	assert.False(t, IsRetryable(Permanent(errors.New("eof"))))
+	assert.False(t, IsRetryable(errorx.New(1001)))
+	assert.True(t, IsRetryable(errorx.WrapByCode(errors.New("eof"), 1002)))
+}
+
+func TestJitter(t *testing.T) {
+	b := Jitter(Exponential(100*time.Millisecond, time.Second), 0.5)
+	for range 100 {
+		d := b(2)
+		assert.GreaterOrEqual(t, d, 100*time.Millisecond)
+		assert.LessOrEqual(t, d, 300*time.Millisecond)
+	}
+}
+
+func TestExponential(t *testing.T) {
+	b := Exponential(time.Millisecond, 4*time.Millisecond)
+	assert.Equal(t, []time.Duration{time.Millisecond, 2 * time.Millisecond, 4 * time.Millisecond, 4 * time.Millisecond},
+		[]time.Duration{b(1), b(2), b(3), b(4)})
+}
+
+type throttledError struct{}
+
+func (throttledError) Error() string { return "throttled" }
+
+func (throttledError) RetryAfter() time.Duration { return 5 * time.Millisecond }
+
+func TestRetryAfter(t *testing.T) {
+	var delays []time.Duration
+	calls := 0
+	err := Run(context.Background(), Policy{
+		MaxAttempts: 3,
+		Backoff:     Constant(time.Millisecond),
+		OnRetry:     func(_ context.Context, a Attempt) { delays = append(delays, a.Delay) },
+	}, func(context.Context) error {
+		calls++
+		if calls == 1 {
+			return throttledError{}
+		}
+		return errors.New("unavailable")
+	})
+	assert.EqualError(t, err, "unavailable")
+	assert.Equal(t, []time.Duration{5 * time.Millisecond, time.Millisecond}, delays)
+}
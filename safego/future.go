@@ -0,0 +1,72 @@
+package safego
+
+import (
+	"context"
+	"errors"
+	"runtime/debug"
+)
+
+// Future is the pending result of a computation started by GoResult.
+type Future[T any] struct {
+	done  chan struct{}
+	value T
+	err   error
+}
+
+// GoResult runs fn in a new goroutine and returns a Future for its result.
+// A panic in fn is recovered and returned as the Future's error.
+func GoResult[T any](ctx context.Context, fn func(ctx context.Context) (T, error)) *Future[T] {
+	f := &Future[T]{done: make(chan struct{})}
+	go func() {
+		defer close(f.done)
+		defer func() {
+			if r := recover(); r != nil {
+				f.err = NewPanicErr(r, debug.Stack())
+			}
+		}()
+
+		f.value, f.err = fn(ctx)
+	}()
+	return f
+}
+
+// Done returns a channel closed once the result is available.
+func (f *Future[T]) Done() <-chan struct{} {
+	return f.done
+}
+
+// Wait blocks until the result is available or ctx is done.
+func (f *Future[T]) Wait(ctx context.Context) (T, error) {
+	select {
+	case <-f.done:
+		return f.value, f.err
+	case <-ctx.Done():
+		var zero T
+		return zero, ctx.Err()
+	}
+}
+
+// All waits for every future and returns their values in order.
+// It returns early with the first error in order, or ctx.Err() if ctx is done first.
+func All[T any](ctx context.Context, futures ...*Future[T]) ([]T, error) {
+	values := make([]T, len(futures))
+	for i, f := range futures {
+		v, err := f.Wait(ctx)
+		if err != nil {
+			return nil, err
+		}
+		values[i] = v
+	}
+	return values, nil
+}
+
+// Join waits for every future and returns their errors joined, ignoring the values.
+func Join[T any](ctx context.Context, futures ...*Future[T]) error {
+	var errs []error
+	for _, f := range futures {
+		if _, err := f.Wait(ctx); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -2,14 +2,113 @@ package safego
 
 import (
 	"context"
+	"runtime/debug"
+	"sync/atomic"
+	"time"
 
-	"github.com/me2seeks/forge/goutil"
+	"github.com/me2seeks/forge/logs"
 )
 
+// PanicHandler is called with the recovered panic of a goroutine started by this package.
+// name is empty for goroutines started by Go.
+type PanicHandler func(ctx context.Context, name string, err error)
+
+var panicHandler atomic.Pointer[PanicHandler]
+
+// SetPanicHandler replaces the global panic handler, e.g. to report panics to metrics
+// or an error tracker. Passing nil restores the default, which logs the panic and its stack.
+func SetPanicHandler(h PanicHandler) {
+	if h == nil {
+		panicHandler.Store(nil)
+		return
+	}
+	panicHandler.Store(&h)
+}
+
+func defaultPanicHandler(ctx context.Context, name string, err error) {
+	if name == "" {
+		logs.CtxErrorf(ctx, "[catch panic] %v", err)
+		return
+	}
+	logs.CtxErrorf(ctx, "[catch panic] goroutine %s: %v", name, err)
+}
+
+func handlePanic(ctx context.Context, name string, err error) {
+	if ctx == nil {
+		ctx = context.Background() // nolint: byted_context_not_reinitialize -- false positive
+	}
+	if h := panicHandler.Load(); h != nil {
+		(*h)(ctx, name, err)
+		return
+	}
+	defaultPanicHandler(ctx, name, err)
+}
+
 func Go(ctx context.Context, fn func()) {
 	go func() {
-		defer goutil.Recovery(ctx)
+		_ = run(ctx, "", fn)
+	}()
+}
+
+// RestartPolicy controls whether a goroutine started by GoNamed is restarted after a panic.
+type RestartPolicy struct {
+	// MaxRestarts is the number of restarts allowed, a negative value means unlimited.
+	MaxRestarts int
+	// Backoff is the delay before each restart.
+	Backoff time.Duration
+}
+
+type Option func(*options)
+
+type options struct {
+	restart *RestartPolicy
+}
+
+// WithRestart restarts fn according to policy when it panics. The goroutine is
+// not restarted once fn returns normally or ctx is done.
+func WithRestart(policy RestartPolicy) Option {
+	return func(o *options) {
+		o.restart = &policy
+	}
+}
+
+// GoNamed is like Go but gives the goroutine a name, reported to the panic handler.
+func GoNamed(ctx context.Context, name string, fn func(), opts ...Option) {
+	o := &options{}
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	go func() {
+		for restarts := 0; ; restarts++ {
+			if !run(ctx, name, fn) || o.restart == nil {
+				return
+			}
+			if o.restart.MaxRestarts >= 0 && restarts >= o.restart.MaxRestarts {
+				return
+			}
+
+			timer := time.NewTimer(o.restart.Backoff)
+			select {
+			case <-ctx.Done():
+				timer.Stop()
+				return
+			case <-timer.C:
+			}
+			logs.CtxWarnf(ctx, "[safego] restarting goroutine %s after panic, restarts=%d", name, restarts+1)
+		}
+	}()
+}
 
-		fn()
+// run calls fn and reports whether it panicked.
+func run(ctx context.Context, name string, fn func()) (panicked bool) {
+	defer func() {
+		if r := recover(); r != nil {
+			panicked = true
+			handlePanic(ctx, name, NewPanicErr(r, debug.Stack()))
+		}
 	}()
+
+	fn()
+	return false
 }
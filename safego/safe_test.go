@@ -0,0 +1,64 @@
+package safego
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGoNamed(t *testing.T) {
+	var (
+		mu    sync.Mutex
+		names []string
+		wg    sync.WaitGroup
+	)
+	SetPanicHandler(func(_ context.Context, name string, err error) {
+		mu.Lock()
+		defer mu.Unlock()
+		assert.Contains(t, err.Error(), "boom")
+		names = append(names, name)
+	})
+	defer SetPanicHandler(nil)
+
+	wg.Add(3)
+	GoNamed(context.Background(), "worker", func() {
+		defer wg.Done()
+		panic("boom")
+	}, WithRestart(RestartPolicy{MaxRestarts: 2, Backoff: time.Millisecond}))
+	wg.Wait()
+
+	assert.Eventually(t, func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+		return len(names) == 3
+	}, time.Second, time.Millisecond)
+	assert.Equal(t, []string{"worker", "worker", "worker"}, names)
+}
+
+func TestGoResult(t *testing.T) {
+	ctx := context.Background()
+	square := func(i int) *Future[int] {
+		return GoResult(ctx, func(context.Context) (int, error) { return i * i, nil })
+	}
+
+	values, err := All(ctx, square(1), square(2), square(3))
+	assert.NoError(t, err)
+	assert.Equal(t, []int{1, 4, 9}, values)
+
+	boom := GoResult(ctx, func(context.Context) (int, error) { panic("boom") })
+	_, err = All(ctx, square(1), boom)
+	assert.ErrorContains(t, err, "boom")
+	assert.ErrorContains(t, Join(ctx, boom, square(2)), "boom")
+
+	timeoutCtx, cancel := context.WithTimeout(ctx, 10*time.Millisecond)
+	defer cancel()
+	block := GoResult(timeoutCtx, func(ctx context.Context) (int, error) {
+		<-ctx.Done()
+		return 0, ctx.Err()
+	})
+	_, err = block.Wait(timeoutCtx)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+}
@@ -0,0 +1,81 @@
+package search
+
+import (
+	"math"
+	"sort"
+)
+
+// Fusion merges ranked lists into one, best first.
+type Fusion func(lists []Ranked) []Document
+
+// RRF is reciprocal rank fusion: a document scores the sum over lists of
+// weight / (k + rank). It ignores the retrievers' scores, so it needs no
+// calibration between BM25 and vector similarities. k is usually 60.
+func RRF(k float64) Fusion {
+	return func(lists []Ranked) []Document {
+		return fuse(lists, func(list Ranked) []float64 {
+			scores := make([]float64, len(list.Docs))
+			for i := range list.Docs {
+				scores[i] = list.Weight / (k + float64(i+1))
+			}
+			return scores
+		})
+	}
+}
+
+// Weighted scores a document with the weighted sum of its scores, each list
+// being min-max normalized to [0, 1] first.
+func Weighted() Fusion {
+	return func(lists []Ranked) []Document {
+		return fuse(lists, func(list Ranked) []float64 {
+			lo, hi := math.Inf(1), math.Inf(-1)
+			for _, doc := range list.Docs {
+				lo, hi = min(lo, doc.Score), max(hi, doc.Score)
+			}
+			scores := make([]float64, len(list.Docs))
+			for i, doc := range list.Docs {
+				norm := 1.0
+				if hi > lo {
+					norm = (doc.Score - lo) / (hi - lo)
+				}
+				scores[i] = list.Weight * norm
+			}
+			return scores
+		})
+	}
+}
+
+// fuse sums the contributions computed by score for each list, keyed by
+// document ID, and sorts the result by descending score then ID.
+func fuse(lists []Ranked, score func(list Ranked) []float64) []Document {
+	byID := make(map[string]*Document)
+	var order []*Document
+	for _, list := range lists {
+		contributions := score(list)
+		for i, doc := range list.Docs {
+			fused, ok := byID[doc.ID]
+			if !ok {
+				fused = &Document{ID: doc.ID, Scores: make(map[string]float64, len(lists))}
+				byID[doc.ID] = fused
+				order = append(order, fused)
+			}
+			fused.Score += contributions[i]
+			fused.Scores[list.Name] = doc.Score
+			if fused.Source == nil {
+				fused.Source = doc.Source
+			}
+		}
+	}
+
+	sort.SliceStable(order, func(i, j int) bool {
+		if order[i].Score != order[j].Score {
+			return order[i].Score > order[j].Score
+		}
+		return order[i].ID < order[j].ID
+	})
+	docs := make([]Document, len(order))
+	for i, doc := range order {
+		docs[i] = *doc
+	}
+	return docs
+}
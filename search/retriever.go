@@ -0,0 +1,172 @@
+package search
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/me2seeks/forge/infra/contract/embedding"
+	"github.com/me2seeks/forge/infra/contract/es"
+	"github.com/me2seeks/forge/infra/contract/graph"
+)
+
+type bm25 struct {
+	client es.Client
+	index  string
+	fields []string
+}
+
+// NewBM25 returns a retriever running a multi_match query of the query text
+// over fields of index.
+func NewBM25(client es.Client, index string, fields []string) Retriever {
+	return &bm25{client: client, index: index, fields: fields}
+}
+
+func (r *bm25) Name() string {
+	return "bm25"
+}
+
+func (r *bm25) Retrieve(ctx context.Context, q *HybridQuery, k int) ([]Document, error) {
+	if q.Text == "" {
+		return nil, nil
+	}
+	query := &es.Query{Bool: &es.BoolQuery{
+		Must: []es.Query{es.NewMultiMatchQuery(r.fields, q.Text, "best_fields", es.Or)},
+	}}
+	if q.Filter != nil {
+		query.Bool.Filter = []es.Query{*q.Filter}
+	}
+
+	resp, err := r.client.Search(ctx, r.index, &es.Request{Size: &k, Query: query})
+	if err != nil {
+		return nil, err
+	}
+	docs := make([]Document, 0, len(resp.Hits.Hits))
+	for _, hit := range resp.Hits.Hits {
+		if hit.Id_ == nil {
+			continue
+		}
+		doc := Document{ID: *hit.Id_, Source: hit.Source_}
+		if hit.Score_ != nil {
+			doc.Score = *hit.Score_
+		}
+		docs = append(docs, doc)
+	}
+	return docs, nil
+}
+
+// VectorIndex is the nearest-neighbor side of a vector store.
+type VectorIndex interface {
+	// SearchVectors returns the k documents closest to vector, closest first,
+	// with their similarity as score.
+	SearchVectors(ctx context.Context, vector []float32, k int, filter *es.Query) ([]Document, error)
+}
+
+type knn struct {
+	index    VectorIndex
+	embedder embedding.Embedder
+}
+
+// NewKNN returns a retriever searching index with the query vector, embedding
+// the query text with embedder when the query has no vector.
+func NewKNN(index VectorIndex, embedder embedding.Embedder) Retriever {
+	return &knn{index: index, embedder: embedder}
+}
+
+func (r *knn) Name() string {
+	return "knn"
+}
+
+func (r *knn) Retrieve(ctx context.Context, q *HybridQuery, k int) ([]Document, error) {
+	vector := q.Vector
+	if vector == nil {
+		if q.Text == "" || r.embedder == nil {
+			return nil, nil
+		}
+		vectors, err := r.embedder.Embed(ctx, []string{q.Text})
+		if err != nil {
+			return nil, fmt.Errorf("embed query failed: %w", err)
+		}
+		if len(vectors) != 1 {
+			return nil, errors.New("embedder returned no vector")
+		}
+		vector = vectors[0]
+	}
+	return r.index.SearchVectors(ctx, vector, k, q.Filter)
+}
+
+const graphRetrieverName = "graph"
+
+// GraphExpansion configures how fused results are expanded to related nodes.
+type GraphExpansion struct {
+	// Property is the node property holding the document ID.
+	Property string
+	// RelationshipTypes restricts the relationships followed. Empty follows all.
+	RelationshipTypes []string
+	// Depth is the maximum number of hops from a result. Defaults to 1.
+	Depth int
+	// Seeds is the number of top fused results expanded. Defaults to 10.
+	Seeds int
+	// Limit is the number of related documents added. Defaults to 20.
+	Limit int
+	// Weight is the weight of the related documents in fusion. Defaults to 1.
+	Weight float64
+}
+
+// WithGraphExpansion adds to the fused results the documents whose nodes are
+// related to the nodes of the top results, ranked by the number of paths
+// reaching them, and fuses again.
+func WithGraphExpansion(client graph.Client, expansion GraphExpansion) Option {
+	return func(s *Searcher) {
+		if expansion.Depth <= 0 {
+			expansion.Depth = 1
+		}
+		if expansion.Seeds <= 0 {
+			expansion.Seeds = 10
+		}
+		if expansion.Limit <= 0 {
+			expansion.Limit = 20
+		}
+		if expansion.Weight == 0 {
+			expansion.Weight = 1
+		}
+		s.graph, s.expansion = client, expansion
+	}
+}
+
+func (s *Searcher) expand(ctx context.Context, docs []Document) ([]Document, error) {
+	e := s.expansion
+	seeds := docs[:min(len(docs), e.Seeds)]
+	ids := make([]string, len(seeds))
+	for i, doc := range seeds {
+		ids[i] = doc.ID
+	}
+
+	var rel string
+	if len(e.RelationshipTypes) > 0 {
+		types := make([]string, len(e.RelationshipTypes))
+		for i, t := range e.RelationshipTypes {
+			types[i] = "`" + strings.ReplaceAll(t, "`", "``") + "`"
+		}
+		rel = ":" + strings.Join(types, "|")
+	}
+	query := fmt.Sprintf(
+		"MATCH (s)-[%s*1..%d]-(n) WHERE s[$prop] IN $ids AND n[$prop] IS NOT NULL AND NOT n[$prop] IN $ids "+
+			"RETURN n[$prop] AS id, count(*) AS paths ORDER BY paths DESC, id LIMIT $limit",
+		rel, e.Depth)
+	res, err := s.graph.RawQuery(ctx, query, map[string]any{"prop": e.Property, "ids": ids, "limit": e.Limit})
+	if err != nil {
+		return nil, err
+	}
+
+	expanded := make([]Document, 0, len(res.Records))
+	for _, rec := range res.Records {
+		doc := Document{ID: fmt.Sprint(rec["id"])}
+		if paths, ok := rec["paths"].(int64); ok {
+			doc.Score = float64(paths)
+		}
+		expanded = append(expanded, doc)
+	}
+	return expanded, nil
+}
@@ -0,0 +1,158 @@
+// Package search runs hybrid retrieval: a query fans out to several
+// retrievers, typically BM25 over es and kNN over a vector index, whose ranked
+// lists are fused into one, optionally expanded along graph relationships.
+//
+//	s := search.New(
+//		search.WithRetriever(search.NewBM25(esClient, "docs", []string{"title", "body"}), 1),
+//		search.WithRetriever(search.NewKNN(index, embedder), 1),
+//		search.WithGraphExpansion(graphClient, search.GraphExpansion{Property: "doc_id", Weight: 0.5}),
+//	)
+//	docs, err := s.Search(ctx, &search.HybridQuery{Text: "graph databases", Size: 10})
+package search
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/me2seeks/forge/infra/contract/es"
+	"github.com/me2seeks/forge/infra/contract/graph"
+	"github.com/me2seeks/forge/logs"
+	"github.com/me2seeks/forge/taskgroup"
+)
+
+// Document is a search result. Score is the fused score, Scores the score given
+// by each retriever that returned the document, keyed by retriever name.
+type Document struct {
+	ID     string             `json:"id"`
+	Score  float64            `json:"score"`
+	Scores map[string]float64 `json:"scores,omitempty"`
+	// Source is the stored document, if a retriever returned it. Documents only
+	// found by graph expansion have none.
+	Source json.RawMessage `json:"source,omitempty"`
+}
+
+type HybridQuery struct {
+	Text string
+	// Vector is the query embedding. If nil, kNN retrievers embed Text.
+	Vector []float32
+	// Filter restricts the documents retrievers may return.
+	Filter *es.Query
+	// Size is the number of documents returned. Defaults to 10.
+	Size int
+}
+
+// Retriever returns the k best documents for a query, best first.
+type Retriever interface {
+	Name() string
+	Retrieve(ctx context.Context, q *HybridQuery, k int) ([]Document, error)
+}
+
+// Ranked is the list returned by one retriever, with the weight it has in fusion.
+type Ranked struct {
+	Name   string
+	Weight float64
+	Docs   []Document
+}
+
+type weightedRetriever struct {
+	Retriever
+	weight float64
+}
+
+type Searcher struct {
+	retrievers []weightedRetriever
+	fusion     Fusion
+	candidates int
+	graph      graph.Client
+	expansion  GraphExpansion
+}
+
+type Option func(*Searcher)
+
+// WithRetriever adds r, whose list counts weight times in fusion.
+func WithRetriever(r Retriever, weight float64) Option {
+	return func(s *Searcher) {
+		s.retrievers = append(s.retrievers, weightedRetriever{Retriever: r, weight: weight})
+	}
+}
+
+// WithFusion sets how ranked lists are merged. Defaults to RRF(60).
+func WithFusion(f Fusion) Option {
+	return func(s *Searcher) {
+		s.fusion = f
+	}
+}
+
+// WithCandidates sets how many documents each retriever returns. Defaults to
+// three times the query size.
+func WithCandidates(k int) Option {
+	return func(s *Searcher) {
+		s.candidates = k
+	}
+}
+
+func New(opts ...Option) *Searcher {
+	s := &Searcher{fusion: RRF(60)}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// Search runs the retrievers concurrently and fuses their lists. A failing
+// retriever is logged and left out, Search only fails if they all do.
+func (s *Searcher) Search(ctx context.Context, q *HybridQuery) ([]Document, error) {
+	if len(s.retrievers) == 0 {
+		return nil, errors.New("search: no retriever configured")
+	}
+	size := q.Size
+	if size <= 0 {
+		size = 10
+	}
+	k := s.candidates
+	if k <= 0 {
+		k = 3 * size
+	}
+
+	errs := make([]error, len(s.retrievers))
+	tg := taskgroup.NewUninterruptibleCollecting[*Ranked](ctx, len(s.retrievers))
+	for i, r := range s.retrievers {
+		tg.Go(func() (*Ranked, error) {
+			docs, err := r.Retrieve(ctx, q, k)
+			if err != nil {
+				errs[i] = fmt.Errorf("retriever %s failed: %w", r.Name(), err)
+				logs.CtxWarnf(ctx, "[search] %v", errs[i])
+				return nil, nil
+			}
+			return &Ranked{Name: r.Name(), Weight: r.weight, Docs: docs}, nil
+		})
+	}
+	results, _ := tg.Wait()
+
+	lists := make([]Ranked, 0, len(results))
+	for _, res := range results {
+		if res != nil {
+			lists = append(lists, *res)
+		}
+	}
+	if len(lists) == 0 {
+		return nil, errors.Join(errs...)
+	}
+
+	docs := s.fusion(lists)
+	if s.graph != nil && len(docs) > 0 {
+		expanded, err := s.expand(ctx, docs)
+		if err != nil {
+			logs.CtxWarnf(ctx, "[search] graph expansion failed: %v", err)
+		} else if len(expanded) > 0 {
+			docs = s.fusion(append(lists, Ranked{Name: graphRetrieverName, Weight: s.expansion.Weight, Docs: expanded}))
+		}
+	}
+
+	if len(docs) > size {
+		docs = docs[:size]
+	}
+	return docs, nil
+}
@@ -0,0 +1,76 @@
+package search
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/me2seeks/forge/infra/contract/es"
+)
+
+type fakeIndex []Document
+
+func (f fakeIndex) SearchVectors(_ context.Context, _ []float32, k int, _ *es.Query) ([]Document, error) {
+	return f[:min(k, len(f))], nil
+}
+
+type failing struct{}
+
+func (failing) Name() string { return "failing" }
+
+func (failing) Retrieve(context.Context, *HybridQuery, int) ([]Document, error) {
+	return nil, errors.New("timeout")
+}
+
+type fakeES struct {
+	es.Client
+	search func(ctx context.Context, index string, req *es.Request) (*es.Response, error)
+}
+
+func (f *fakeES) Search(ctx context.Context, index string, req *es.Request) (*es.Response, error) {
+	return f.search(ctx, index, req)
+}
+
+func ptr[T any](v T) *T { return &v }
+
+func TestSearch(t *testing.T) {
+	client := &fakeES{search: func(_ context.Context, index string, req *es.Request) (*es.Response, error) {
+		assert.Equal(t, "docs", index)
+		assert.Equal(t, 6, *req.Size)
+		return &es.Response{Hits: es.HitsMetadata{Hits: []es.Hit{
+			{Id_: ptr("a"), Score_: ptr(12.0), Source_: []byte(`{"title":"a"}`)},
+			{Id_: ptr("b"), Score_: ptr(8.0)},
+		}}}, nil
+	}}
+
+	s := New(
+		WithRetriever(NewBM25(client, "docs", []string{"title"}), 1),
+		WithRetriever(NewKNN(fakeIndex{{ID: "b", Score: 0.9}, {ID: "c", Score: 0.7}}, nil), 1),
+		WithRetriever(failing{}, 1),
+	)
+	docs, err := s.Search(context.Background(), &HybridQuery{Text: "graph", Vector: []float32{1}, Size: 2})
+	require.NoError(t, err)
+	require.Len(t, docs, 2)
+	assert.Equal(t, "b", docs[0].ID)
+	assert.Equal(t, map[string]float64{"bm25": 8, "knn": 0.9}, docs[0].Scores)
+	assert.Equal(t, "a", docs[1].ID)
+	assert.JSONEq(t, `{"title":"a"}`, string(docs[1].Source))
+
+	_, err = New(WithRetriever(failing{}, 1)).Search(context.Background(), &HybridQuery{Text: "graph"})
+	assert.EqualError(t, err, "retriever failing failed: timeout")
+}
+
+func TestWeighted(t *testing.T) {
+	docs := Weighted()([]Ranked{
+		{Name: "bm25", Weight: 1, Docs: []Document{{ID: "a", Score: 10}, {ID: "b", Score: 5}, {ID: "c", Score: 0}}},
+		{Name: "knn", Weight: 2, Docs: []Document{{ID: "c", Score: 0.9}, {ID: "a", Score: 0.1}}},
+	})
+	require.Len(t, docs, 3)
+	assert.Equal(t, []string{"c", "a", "b"}, []string{docs[0].ID, docs[1].ID, docs[2].ID})
+	assert.InDelta(t, 2, docs[0].Score, 1e-9)
+	assert.InDelta(t, 1, docs[1].Score, 1e-9)
+	assert.InDelta(t, 0.5, docs[2].Score, 1e-9)
+}
@@ -0,0 +1,124 @@
+package sonic
+
+import "io"
+
+// API is a JSON codec bound to a configuration, created by New.
+// The package-level functions use a default API with int64 integer decoding.
+type API struct {
+	config config
+	engine engine
+
+	prefix string
+	indent string
+}
+
+type Option func(*API)
+
+// WithSortMapKeys encodes map keys in sorted order, producing deterministic output.
+func WithSortMapKeys() Option {
+	return func(a *API) {
+		a.config.sortMapKeys = true
+	}
+}
+
+// WithEscapeHTML escapes <, > and & inside JSON strings, like encoding/json.
+func WithEscapeHTML() Option {
+	return func(a *API) {
+		a.config.escapeHTML = true
+	}
+}
+
+// WithIndent makes Marshal, MarshalString and encoders indent their output by default.
+func WithIndent(prefix, indent string) Option {
+	return func(a *API) {
+		a.prefix, a.indent = prefix, indent
+	}
+}
+
+// WithCaseSensitive matches object keys to struct fields case-sensitively when decoding.
+// It is only honored by the sonic engine.
+func WithCaseSensitive() Option {
+	return func(a *API) {
+		a.config.caseSensitive = true
+	}
+}
+
+// WithDisallowUnknownFields fails decoding on object keys matching no struct field.
+func WithDisallowUnknownFields() Option {
+	return func(a *API) {
+		a.config.disallowUnknownFields = true
+	}
+}
+
+// WithUseNumber decodes numbers into interface{} values as json.Number instead of int64/float64.
+func WithUseNumber() Option {
+	return func(a *API) {
+		a.config.useInt64 = false
+		a.config.useNumber = true
+	}
+}
+
+// New returns an API with the package defaults, integers decoded into interface{}
+// as int64, altered by opts.
+func New(opts ...Option) *API {
+	a := &API{
+		config: config{
+			useInt64: true,
+		},
+	}
+	for _, opt := range opts {
+		opt(a)
+	}
+	a.engine = newEngine(a.config)
+	return a
+}
+
+func (a *API) indented() bool {
+	return a.prefix != "" || a.indent != ""
+}
+
+// Marshal returns the JSON encoding bytes of v.
+func (a *API) Marshal(val any) ([]byte, error) {
+	if a.indented() {
+		return a.engine.MarshalIndent(val, a.prefix, a.indent)
+	}
+	return a.engine.Marshal(val)
+}
+
+// MarshalIndent is like Marshal but applies Indent to format the output.
+func (a *API) MarshalIndent(v any, prefix, indent string) ([]byte, error) {
+	return a.engine.MarshalIndent(v, prefix, indent)
+}
+
+// MarshalString returns the JSON encoding string of v.
+func (a *API) MarshalString(val any) (string, error) {
+	if a.indented() {
+		buf, err := a.engine.MarshalIndent(val, a.prefix, a.indent)
+		return string(buf), err
+	}
+	return a.engine.MarshalToString(val)
+}
+
+// Unmarshal parses the JSON-encoded data and stores the result in the value pointed to by v.
+func (a *API) Unmarshal(buf []byte, val any) error {
+	return a.engine.Unmarshal(buf, val)
+}
+
+// UnmarshalString is like Unmarshal, except buf is a string.
+func (a *API) UnmarshalString(buf string, val any) error {
+	return a.engine.UnmarshalFromString(buf, val)
+}
+
+// NewEncoder returns an Encoder writing to w.
+func (a *API) NewEncoder(w io.Writer) *Encoder {
+	enc := a.engine.NewEncoder(w)
+	if a.indented() {
+		enc.SetIndent(a.prefix, a.indent)
+	}
+	return &Encoder{enc: enc}
+}
+
+// NewDecoder returns a Decoder reading from r.
+func (a *API) NewDecoder(r io.Reader) *Decoder {
+	return newDecoder(r, a)
+}
@@ -0,0 +1,32 @@
+package sonic
+
+import "io"
+
+// config holds the engine independent settings of an API.
+type config struct {
+	sortMapKeys           bool
+	escapeHTML            bool
+	caseSensitive         bool
+	disallowUnknownFields bool
+	useInt64              bool
+	useNumber             bool
+}
+
+// engine is the JSON implementation behind an API. The one compiled in is selected
+// by build tags: sonic by default, encoding/json with forge_stdjson, and
+// goccy/go-json with forge_gojson.
+type engine interface {
+	Marshal(v any) ([]byte, error)
+	MarshalIndent(v any, prefix, indent string) ([]byte, error)
+	MarshalToString(v any) (string, error)
+	Unmarshal(data []byte, v any) error
+	UnmarshalFromString(data string, v any) error
+	NewEncoder(w io.Writer) encoder
+	Valid(data []byte) bool
+}
+
+type encoder interface {
+	Encode(v any) error
+	SetEscapeHTML(on bool)
+	SetIndent(prefix, indent string)
+}
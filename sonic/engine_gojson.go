@@ -0,0 +1,86 @@
+//go:build forge_gojson && !forge_stdjson
+
+package sonic
+
+import (
+	"bytes"
+	"io"
+
+	gojson "github.com/goccy/go-json"
+)
+
+// EngineName is the JSON implementation compiled in.
+const EngineName = "go-json"
+
+// goJSONEngine implements engine with goccy/go-json. Object keys always match
+// fields case-insensitively.
+type goJSONEngine struct {
+	config
+}
+
+func newEngine(c config) engine {
+	return goJSONEngine{config: c}
+}
+
+func (e goJSONEngine) encodeOptions() []gojson.EncodeOptionFunc {
+	var opts []gojson.EncodeOptionFunc
+	if !e.sortMapKeys {
+		opts = append(opts, gojson.UnorderedMap())
+	}
+	if !e.escapeHTML {
+		opts = append(opts, gojson.DisableHTMLEscape())
+	}
+	return opts
+}
+
+func (e goJSONEngine) Marshal(v any) ([]byte, error) {
+	return gojson.MarshalWithOption(v, e.encodeOptions()...)
+}
+
+func (e goJSONEngine) MarshalIndent(v any, prefix, indent string) ([]byte, error) {
+	return gojson.MarshalIndentWithOption(v, prefix, indent, e.encodeOptions()...)
+}
+
+func (e goJSONEngine) MarshalToString(v any) (string, error) {
+	buf, err := e.Marshal(v)
+	return string(buf), err
+}
+
+func (e goJSONEngine) Unmarshal(data []byte, v any) error {
+	if !e.disallowUnknownFields && !e.useNumber && !e.useInt64 {
+		return gojson.Unmarshal(data, v)
+	}
+	// the decoder tolerates trailing data, Unmarshal reports the syntax error.
+	if !gojson.Valid(data) {
+		return gojson.Unmarshal(data, v)
+	}
+
+	dec := gojson.NewDecoder(bytes.NewReader(data))
+	if e.disallowUnknownFields {
+		dec.DisallowUnknownFields()
+	}
+	if e.useNumber || e.useInt64 {
+		dec.UseNumber()
+	}
+	if err := dec.Decode(v); err != nil {
+		return err
+	}
+	if e.useInt64 && !e.useNumber {
+		convertNumbers(v)
+	}
+	return nil
+}
+
+func (e goJSONEngine) UnmarshalFromString(data string, v any) error {
+	return e.Unmarshal([]byte(data), v)
+}
+
+func (e goJSONEngine) NewEncoder(w io.Writer) encoder {
+	enc := gojson.NewEncoder(w)
+	enc.SetEscapeHTML(e.escapeHTML)
+	return enc
+}
+
+func (e goJSONEngine) Valid(data []byte) bool {
+	return gojson.Valid(data)
+}
@@ -0,0 +1,31 @@
+//go:build !forge_stdjson && !forge_gojson
+
+package sonic
+
+import (
+	"io"
+
+	"github.com/bytedance/sonic"
+)
+
+// EngineName is the JSON implementation compiled in.
+const EngineName = "sonic"
+
+type sonicEngine struct {
+	sonic.API
+}
+
+func newEngine(c config) engine {
+	return sonicEngine{API: sonic.Config{
+		SortMapKeys:           c.sortMapKeys,
+		EscapeHTML:            c.escapeHTML,
+		CaseSensitive:         c.caseSensitive,
+		DisallowUnknownFields: c.disallowUnknownFields,
+		UseInt64:              c.useInt64,
+		UseNumber:             c.useNumber,
+	}.Froze()}
+}
+
+func (e sonicEngine) NewEncoder(w io.Writer) encoder {
+	return e.API.NewEncoder(w)
+}
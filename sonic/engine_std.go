@@ -0,0 +1,81 @@
+//go:build forge_stdjson
+
+package sonic
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+)
+
+// EngineName is the JSON implementation compiled in.
+const EngineName = "encoding/json"
+
+// stdEngine implements engine with encoding/json. Map keys are always sorted and
+// object keys always match fields case-insensitively.
+type stdEngine struct {
+	config
+}
+
+func newEngine(c config) engine {
+	return stdEngine{config: c}
+}
+
+func (e stdEngine) Marshal(v any) ([]byte, error) {
+	return e.MarshalIndent(v, "", "")
+}
+
+func (e stdEngine) MarshalIndent(v any, prefix, indent string) ([]byte, error) {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(e.escapeHTML)
+	enc.SetIndent(prefix, indent)
+	if err := enc.Encode(v); err != nil {
+		return nil, err
+	}
+	return bytes.TrimSuffix(buf.Bytes(), []byte("\n")), nil
+}
+
+func (e stdEngine) MarshalToString(v any) (string, error) {
+	buf, err := e.Marshal(v)
+	return string(buf), err
+}
+
+func (e stdEngine) Unmarshal(data []byte, v any) error {
+	if !e.disallowUnknownFields && !e.useNumber && !e.useInt64 {
+		return json.Unmarshal(data, v)
+	}
+	// the decoder tolerates trailing data, json.Unmarshal reports the syntax error.
+	if !json.Valid(data) {
+		return json.Unmarshal(data, v)
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(data))
+	if e.disallowUnknownFields {
+		dec.DisallowUnknownFields()
+	}
+	if e.useNumber || e.useInt64 {
+		dec.UseNumber()
+	}
+	if err := dec.Decode(v); err != nil {
+		return err
+	}
+	if e.useInt64 && !e.useNumber {
+		convertNumbers(v)
+	}
+	return nil
+}
+
+func (e stdEngine) UnmarshalFromString(data string, v any) error {
+	return e.Unmarshal([]byte(data), v)
+}
+
+func (e stdEngine) NewEncoder(w io.Writer) encoder {
+	enc := json.NewEncoder(w)
+	enc.SetEscapeHTML(e.escapeHTML)
+	return enc
+}
+
+func (e stdEngine) Valid(data []byte) bool {
+	return json.Valid(data)
+}
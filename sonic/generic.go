@@ -0,0 +1,49 @@
+package sonic
+
+import "encoding/json"
+
+// RawMessage is a raw encoded JSON value, interchangeable with json.RawMessage.
+type RawMessage = json.RawMessage
+
+// UnmarshalAs parses data into a new value of type T.
+func UnmarshalAs[T any](data []byte) (T, error) {
+	var v T
+	err := Unmarshal(data, &v)
+	return v, err
+}
+
+// UnmarshalStringAs is like UnmarshalAs, except data is a string.
+func UnmarshalStringAs[T any](data string) (T, error) {
+	var v T
+	err := UnmarshalString(data, &v)
+	return v, err
+}
+
+// Valid reports whether data is a valid JSON encoding.
+func Valid(data []byte) bool {
+	return defaultAPI.engine.Valid(data)
+}
+
+// ValidString is like Valid, except data is a string.
+func ValidString(data string) bool {
+	return Valid([]byte(data))
+}
+
+// MarshalRaw encodes v into a RawMessage, e.g. to embed it in a document decoded later.
+func MarshalRaw(v any) (RawMessage, error) {
+	buf, err := Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	return RawMessage(buf), nil
+}
+
+// RawAs decodes raw into a new value of type T. An empty or null raw yields the zero value.
+func RawAs[T any](raw RawMessage) (T, error) {
+	var v T
+	if len(raw) == 0 || string(raw) == "null" {
+		return v, nil
+	}
+	err := Unmarshal(raw, &v)
+	return v, err
+}
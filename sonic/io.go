@@ -0,0 +1,67 @@
+package sonic
+
+import (
+	"bytes"
+	"io"
+	"sync"
+)
+
+// maxPooledBuffer bounds the buffers kept in bufferPool, so a single huge document
+// doesn't pin its memory forever.
+const maxPooledBuffer = 1 << 20
+
+var bufferPool = sync.Pool{
+	New: func() any { return new(bytes.Buffer) },
+}
+
+func getBuffer() *bytes.Buffer {
+	return bufferPool.Get().(*bytes.Buffer)
+}
+
+func putBuffer(buf *bytes.Buffer) {
+	if buf.Cap() > maxPooledBuffer {
+		return
+	}
+	buf.Reset()
+	bufferPool.Put(buf)
+}
+
+// MarshalWrite writes the JSON encoding of v to w, encoding through a pooled buffer.
+func MarshalWrite(w io.Writer, v any) error {
+	return defaultAPI.MarshalWrite(w, v)
+}
+
+// UnmarshalRead reads r to EOF into a pooled buffer and parses it into v.
+func UnmarshalRead(r io.Reader, v any) error {
+	return defaultAPI.UnmarshalRead(r, v)
+}
+
+// MarshalWrite writes the JSON encoding of v to w, encoding through a pooled buffer.
+// Unlike Encoder.Encode, no newline is appended.
+func (a *API) MarshalWrite(w io.Writer, v any) error {
+	buf := getBuffer()
+	defer putBuffer(buf)
+
+	enc := a.engine.NewEncoder(buf)
+	if a.indented() {
+		enc.SetIndent(a.prefix, a.indent)
+	}
+	if err := enc.Encode(v); err != nil {
+		return err
+	}
+
+	_, err := w.Write(bytes.TrimSuffix(buf.Bytes(), []byte("\n")))
+	return err
+}
+
+// UnmarshalRead reads r to EOF into a pooled buffer and parses it into v.
+// The decoded value does not reference the buffer.
+func (a *API) UnmarshalRead(r io.Reader, v any) error {
+	buf := getBuffer()
+	defer putBuffer(buf)
+
+	if _, err := buf.ReadFrom(r); err != nil {
+		return err
+	}
+	return a.engine.Unmarshal(buf.Bytes(), v)
+}
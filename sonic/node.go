@@ -0,0 +1,79 @@
+package sonic
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrNotFound is returned by Get when the path does not exist in the document.
+var ErrNotFound = errors.New("sonic: path not found")
+
+// Node is a JSON value located inside a larger document without decoding the rest of it.
+// Its typed accessors decode only the value itself.
+type Node struct {
+	raw string
+}
+
+// Get locates the value at path in src, skipping everything else. Each path element
+// is a string key, searching an object, or an int index, searching an array.
+// The returned node does not reference src.
+func Get(src []byte, path ...any) (Node, error) {
+	return GetFromString(string(src), path...)
+}
+
+// GetFromString is like Get, except src is a string. The returned node references src.
+func GetFromString(src string, path ...any) (Node, error) {
+	for _, p := range path {
+		switch p.(type) {
+		case string, int:
+		default:
+			return Node{}, fmt.Errorf("sonic: invalid path element %v of type %T", p, p)
+		}
+	}
+
+	raw, err := search(src, path)
+	if err != nil {
+		return Node{}, err
+	}
+	return Node{raw: raw}, nil
+}
+
+// Get locates the value at path inside n.
+func (n Node) Get(path ...any) (Node, error) {
+	return GetFromString(n.raw, path...)
+}
+
+// Raw returns the JSON text of the value.
+func (n Node) Raw() string {
+	return n.raw
+}
+
+// String returns the value as a string, it fails if the value is not a JSON string.
+func (n Node) String() (string, error) {
+	return UnmarshalStringAs[string](n.raw)
+}
+
+// Int64 returns the value as an int64, it fails if the value is not an integer.
+func (n Node) Int64() (int64, error) {
+	return UnmarshalStringAs[int64](n.raw)
+}
+
+// Float64 returns the value as a float64, it fails if the value is not a number.
+func (n Node) Float64() (float64, error) {
+	return UnmarshalStringAs[float64](n.raw)
+}
+
+// Bool returns the value as a bool, it fails if the value is not a JSON boolean.
+func (n Node) Bool() (bool, error) {
+	return UnmarshalStringAs[bool](n.raw)
+}
+
+// Interface decodes the value as Unmarshal would into an interface{}.
+func (n Node) Interface() (any, error) {
+	return UnmarshalStringAs[any](n.raw)
+}
+
+// Unmarshal decodes the value into v.
+func (n Node) Unmarshal(v any) error {
+	return UnmarshalString(n.raw, v)
+}
@@ -0,0 +1,127 @@
+//go:build forge_stdjson || forge_gojson
+
+package sonic
+
+import (
+	"encoding/json"
+	"reflect"
+	"sync"
+)
+
+// convertNumbers emulates sonic's UseInt64 on engines lacking it: numbers decoded as
+// json.Number into interface{} values reachable from v, through pointers, struct
+// fields, maps, slices and arrays, are replaced by int64, or float64 when not
+// integral (go-json's Number is an alias of json.Number).
+func convertNumbers(v any) {
+	rv := reflect.ValueOf(v)
+	if rv.IsValid() && mayHoldNumber(rv.Type()) {
+		convertValue(rv)
+	}
+}
+
+func convertValue(v reflect.Value) {
+	switch v.Kind() {
+	case reflect.Interface:
+		if v.IsNil() {
+			return
+		}
+		e := v.Elem()
+		switch e.Interface().(type) {
+		case json.Number, map[string]any, []any:
+			n := convertNumber(e.Interface())
+			if v.CanSet() {
+				v.Set(reflect.ValueOf(n))
+			}
+		default:
+			if e.Kind() == reflect.Pointer && mayHoldNumber(e.Type()) {
+				convertValue(e)
+			}
+		}
+	case reflect.Pointer:
+		if !v.IsNil() {
+			convertValue(v.Elem())
+		}
+	case reflect.Struct:
+		t := v.Type()
+		for i := range t.NumField() {
+			if f := t.Field(i); f.IsExported() && mayHoldNumber(f.Type) {
+				convertValue(v.Field(i))
+			}
+		}
+	case reflect.Slice, reflect.Array:
+		if !mayHoldNumber(v.Type().Elem()) {
+			return
+		}
+		for i := range v.Len() {
+			convertValue(v.Index(i))
+		}
+	case reflect.Map:
+		if !mayHoldNumber(v.Type().Elem()) {
+			return
+		}
+		// map elements are not addressable, convert a copy and store it back.
+		e := reflect.New(v.Type().Elem()).Elem()
+		iter := v.MapRange()
+		for iter.Next() {
+			e.Set(iter.Value())
+			convertValue(e)
+			v.SetMapIndex(iter.Key(), e)
+		}
+	}
+}
+
+func convertNumber(v any) any {
+	switch n := v.(type) {
+	case json.Number:
+		if i, err := n.Int64(); err == nil {
+			return i
+		}
+		if f, err := n.Float64(); err == nil {
+			return f
+		}
+	case map[string]any:
+		for k, e := range n {
+			n[k] = convertNumber(e)
+		}
+	case []any:
+		for i, e := range n {
+			n[i] = convertNumber(e)
+		}
+	}
+	return v
+}
+
+// numberTypes caches mayHoldNumber per reflect.Type.
+var numberTypes sync.Map
+
+// mayHoldNumber reports whether a value of type t can reach an interface{} value,
+// the only place a decoder stores a json.Number for UseNumber.
+func mayHoldNumber(t reflect.Type) bool {
+	if ok, found := numberTypes.Load(t); found {
+		return ok.(bool)
+	}
+	ok := holdsInterface(t, map[reflect.Type]bool{})
+	numberTypes.Store(t, ok)
+	return ok
+}
+
+func holdsInterface(t reflect.Type, seen map[reflect.Type]bool) bool {
+	if seen[t] {
+		return false
+	}
+	seen[t] = true
+
+	switch t.Kind() {
+	case reflect.Interface:
+		return true
+	case reflect.Pointer, reflect.Slice, reflect.Array, reflect.Map:
+		return holdsInterface(t.Elem(), seen)
+	case reflect.Struct:
+		for i := range t.NumField() {
+			if f := t.Field(i); f.IsExported() && holdsInterface(f.Type, seen) {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,39 @@
+//go:build forge_stdjson || forge_gojson
+
+package sonic
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// search returns the raw JSON at path in src, decoding one level of the document per
+// path element into raw messages.
+func search(src string, path []any) (string, error) {
+	raw := json.RawMessage(src)
+	for _, p := range path {
+		switch key := p.(type) {
+		case string:
+			var obj map[string]json.RawMessage
+			if err := json.Unmarshal(raw, &obj); err != nil {
+				return "", fmt.Errorf("sonic: searching key %q: %w", key, err)
+			}
+			v, ok := obj[key]
+			if !ok {
+				return "", ErrNotFound
+			}
+			raw = v
+		case int:
+			var arr []json.RawMessage
+			if err := json.Unmarshal(raw, &arr); err != nil {
+				return "", fmt.Errorf("sonic: searching index %d: %w", key, err)
+			}
+			if key < 0 || key >= len(arr) {
+				return "", ErrNotFound
+			}
+			raw = arr[key]
+		}
+	}
+	return strings.TrimSpace(string(raw)), nil
+}
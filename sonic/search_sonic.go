@@ -0,0 +1,21 @@
+//go:build !forge_stdjson && !forge_gojson
+
+package sonic
+
+import (
+	"errors"
+
+	"github.com/bytedance/sonic/ast"
+)
+
+// search returns the raw JSON at path in src using sonic's skipping searcher.
+func search(src string, path []any) (string, error) {
+	node, err := ast.NewSearcher(src).GetByPath(path...)
+	if err != nil {
+		if errors.Is(err, ast.ErrNotExist) {
+			return "", ErrNotFound
+		}
+		return "", err
+	}
+	return node.Raw()
+}
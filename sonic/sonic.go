@@ -1,36 +1,32 @@
 package sonic
 
-import "github.com/bytedance/sonic"
-
-var config = sonic.Config{
-	UseInt64: true,
-}.Froze()
+var defaultAPI = New()
 
 // Marshal returns the JSON encoding bytes of v.
 func Marshal(val any) ([]byte, error) {
-	return config.Marshal(val)
+	return defaultAPI.Marshal(val)
 }
 
 // MarshalIndent is like Marshal but applies Indent to format the output.
 // Each JSON element in the output will begin on a new line beginning with prefix
 // followed by one or more copies of indent according to the indentation nesting.
 func MarshalIndent(v any, prefix, indent string) ([]byte, error) {
-	return config.MarshalIndent(v, prefix, indent)
+	return defaultAPI.MarshalIndent(v, prefix, indent)
 }
 
 // MarshalString returns the JSON encoding string of v.
 func MarshalString(val any) (string, error) {
-	return config.MarshalToString(val)
+	return defaultAPI.MarshalString(val)
 }
 
 // Unmarshal parses the JSON-encoded data and stores the result in the value pointed to by v.
 // NOTICE: This API copies given buffer by default,
 // if you want to pass JSON more efficiently, use UnmarshalString instead.
 func Unmarshal(buf []byte, val any) error {
-	return config.Unmarshal(buf, val)
+	return defaultAPI.Unmarshal(buf, val)
 }
 
 // UnmarshalString is like Unmarshal, except buf is a string.
 func UnmarshalString(buf string, val any) error {
-	return config.UnmarshalFromString(buf, val)
+	return defaultAPI.UnmarshalString(buf, val)
 }
@@ -0,0 +1,181 @@
+package sonic
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStream(t *testing.T) {
+	type hit struct {
+		ID    string `json:"id"`
+		Score int64  `json:"score"`
+	}
+
+	var buf bytes.Buffer
+	enc := NewEncoder(&buf)
+	assert.NoError(t, enc.Encode(hit{ID: "a", Score: 1}))
+	assert.NoError(t, enc.Encode(hit{ID: "b", Score: 2}))
+
+	dec := NewDecoder(&buf)
+	var hits []hit
+	for dec.More() {
+		var h hit
+		assert.NoError(t, dec.Decode(&h))
+		hits = append(hits, h)
+	}
+	assert.Equal(t, []hit{{"a", 1}, {"b", 2}}, hits)
+
+	// walk a large array element by element.
+	dec = NewDecoder(strings.NewReader(`{"hits":[{"id":"x","score":9007199254740993}]}`))
+	for _, want := range []any{json.Delim('{'), "hits", json.Delim('[')} {
+		tok, err := dec.Token()
+		assert.NoError(t, err)
+		assert.Equal(t, want, tok)
+	}
+	var v any
+	assert.NoError(t, dec.Decode(&v))
+	assert.Equal(t, map[string]any{"id": "x", "score": int64(9007199254740993)}, v)
+	assert.False(t, dec.More())
+
+	dec = NewDecoder(strings.NewReader(`{"id":"a","unknown":1}`))
+	dec.DisallowUnknownFields()
+	assert.Error(t, dec.Decode(&hit{}))
+}
+
+func TestNew(t *testing.T) {
+	m := map[string]any{"b": "<x>", "a": 1}
+
+	api := New(WithSortMapKeys(), WithEscapeHTML())
+	s, err := api.MarshalString(m)
+	assert.NoError(t, err)
+	assert.Equal(t, `{"a":1,"b":"\u003cx\u003e"}`, s)
+
+	s, err = New(WithSortMapKeys(), WithIndent("", " ")).MarshalString(map[string]int{"b": 2, "a": 1})
+	assert.NoError(t, err)
+	assert.Equal(t, "{\n \"a\": 1,\n \"b\": 2\n}", s)
+
+	type user struct {
+		Name string `json:"name"`
+	}
+	var u user
+	if EngineName == "sonic" {
+		assert.NoError(t, New(WithCaseSensitive()).UnmarshalString(`{"NAME":"x"}`, &u))
+		assert.Empty(t, u.Name)
+	}
+	assert.NoError(t, UnmarshalString(`{"NAME":"x"}`, &u))
+	assert.Equal(t, "x", u.Name)
+}
+
+func TestEngineNumbers(t *testing.T) {
+	// without UseInt64 or UseNumber every engine decodes numbers as float64.
+	var v any
+	assert.NoError(t, newEngine(config{disallowUnknownFields: true}).Unmarshal([]byte(`{"n":1}`), &v))
+	assert.Equal(t, map[string]any{"n": float64(1)}, v)
+
+	// the defaults decode integers as int64 wherever they are held by an interface{}.
+	type item struct {
+		Value any `json:"value"`
+	}
+	type doc struct {
+		Props map[string]any   `json:"props"`
+		Items []item           `json:"items"`
+		ByKey map[string]*item `json:"by_key"`
+		Score *any             `json:"score"`
+		Count int              `json:"count"`
+	}
+	var d doc
+	assert.NoError(t, UnmarshalString(`{"props":{"n":1,"f":1.5,"l":[2]},"items":[{"value":3}],"by_key":{"a":{"value":4}},"score":5,"count":6}`, &d))
+	assert.Equal(t, map[string]any{"n": int64(1), "f": 1.5, "l": []any{int64(2)}}, d.Props)
+	assert.Equal(t, []item{{Value: int64(3)}}, d.Items)
+	assert.Equal(t, int64(4), d.ByKey["a"].Value)
+	assert.Equal(t, int64(5), *d.Score)
+	assert.Equal(t, 6, d.Count)
+}
+
+func TestGeneric(t *testing.T) {
+	type doc struct {
+		Title string `json:"title"`
+	}
+
+	d, err := UnmarshalAs[doc]([]byte(`{"title":"a"}`))
+	assert.NoError(t, err)
+	assert.Equal(t, doc{Title: "a"}, d)
+
+	m, err := UnmarshalStringAs[map[string]any](`{"n":1}`)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]any{"n": int64(1)}, m)
+
+	assert.True(t, Valid([]byte(`[1,2]`)))
+	assert.False(t, ValidString(`{"a":`))
+
+	raw, err := MarshalRaw(doc{Title: "b"})
+	assert.NoError(t, err)
+	d, err = RawAs[doc](raw)
+	assert.NoError(t, err)
+	assert.Equal(t, doc{Title: "b"}, d)
+
+	d, err = RawAs[doc](nil)
+	assert.NoError(t, err)
+	assert.Zero(t, d)
+}
+
+func TestGetPath(t *testing.T) {
+	src := `{"hits":{"total":{"value":2},"hits":[{"_id":"a","_source":{"title":"x","score":1.5,"ok":true}}]}}`
+
+	total, err := GetFromString(src, "hits", "total", "value")
+	assert.NoError(t, err)
+	n, err := total.Int64()
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2), n)
+
+	hit, err := Get([]byte(src), "hits", "hits", 0)
+	assert.NoError(t, err)
+	id, err := hit.Get("_id")
+	assert.NoError(t, err)
+	s, err := id.String()
+	assert.NoError(t, err)
+	assert.Equal(t, "a", s)
+
+	source, err := hit.Get("_source")
+	assert.NoError(t, err)
+	var doc struct {
+		Title string  `json:"title"`
+		Score float64 `json:"score"`
+	}
+	assert.NoError(t, source.Unmarshal(&doc))
+	assert.Equal(t, "x", doc.Title)
+	assert.Equal(t, 1.5, doc.Score)
+
+	ok, err := source.Get("ok")
+	assert.NoError(t, err)
+	b, err := ok.Bool()
+	assert.NoError(t, err)
+	assert.True(t, b)
+
+	_, err = GetFromString(src, "hits", "missing")
+	assert.ErrorIs(t, err, ErrNotFound)
+	_, err = GetFromString(src, "hits", "hits", 3)
+	assert.ErrorIs(t, err, ErrNotFound)
+	_, err = GetFromString(src, "hits", 1.5)
+	assert.Error(t, err)
+}
+
+func TestReadWrite(t *testing.T) {
+	var buf bytes.Buffer
+	assert.NoError(t, MarshalWrite(&buf, map[string]any{"a": []int{1, 2}}))
+	assert.Equal(t, `{"a":[1,2]}`, buf.String())
+
+	var v map[string][]string
+	for i := 0; i < 3; i++ {
+		assert.NoError(t, UnmarshalRead(strings.NewReader(`{"k":["x","y"]}`), &v))
+	}
+	// values must not alias the pooled buffer, which is reused by the next call.
+	assert.NoError(t, UnmarshalRead(strings.NewReader(`{"z":["zzzzzz"]}`), &map[string][]string{}))
+	assert.Equal(t, map[string][]string{"k": {"x", "y"}}, v)
+
+	assert.Error(t, UnmarshalRead(strings.NewReader(`{`), &v))
+}
@@ -0,0 +1,80 @@
+package sonic
+
+import (
+	"encoding/json"
+	"io"
+)
+
+// Encoder writes JSON values to an output stream.
+type Encoder struct {
+	enc encoder
+}
+
+// NewEncoder returns an Encoder writing to w.
+func NewEncoder(w io.Writer) *Encoder {
+	return defaultAPI.NewEncoder(w)
+}
+
+// Encode writes the JSON encoding of v to the stream, followed by a newline.
+func (e *Encoder) Encode(v any) error {
+	return e.enc.Encode(v)
+}
+
+// SetEscapeHTML specifies whether problematic HTML characters are escaped inside
+// JSON strings. They are not by default.
+func (e *Encoder) SetEscapeHTML(on bool) {
+	e.enc.SetEscapeHTML(on)
+}
+
+// SetIndent formats each subsequent value as if by MarshalIndent.
+func (e *Encoder) SetIndent(prefix, indent string) {
+	e.enc.SetIndent(prefix, indent)
+}
+
+// Decoder reads JSON values from an input stream. Tokens are scanned by encoding/json,
+// so the elements of a huge array can be decoded one at a time with Token and More,
+// while each value is decoded by the API the Decoder was created from.
+type Decoder struct {
+	dec *json.Decoder
+	api *API
+}
+
+// NewDecoder returns a Decoder reading from r.
+func NewDecoder(r io.Reader) *Decoder {
+	return defaultAPI.NewDecoder(r)
+}
+
+func newDecoder(r io.Reader, a *API) *Decoder {
+	return &Decoder{dec: json.NewDecoder(r), api: a}
+}
+
+// Decode reads the next JSON value from the stream and stores it in the value pointed to by v.
+func (d *Decoder) Decode(v any) error {
+	var raw json.RawMessage
+	if err := d.dec.Decode(&raw); err != nil {
+		return err
+	}
+	return d.api.Unmarshal(raw, v)
+}
+
+// More reports whether there is another element in the current array or object.
+func (d *Decoder) More() bool {
+	return d.dec.More()
+}
+
+// Token returns the next JSON token in the stream, see encoding/json.Decoder.Token.
+func (d *Decoder) Token() (json.Token, error) {
+	return d.dec.Token()
+}
+
+// Buffered returns a reader of the data remaining in the Decoder's buffer.
+func (d *Decoder) Buffered() io.Reader {
+	return d.dec.Buffered()
+}
+
+// DisallowUnknownFields makes Decode fail on object keys matching no field of the destination struct.
+func (d *Decoder) DisallowUnknownFields() {
+	c := d.api.config
+	c.disallowUnknownFields = true
+	d.api = &API{config: c, engine: newEngine(c), prefix: d.api.prefix, indent: d.api.indent}
+}
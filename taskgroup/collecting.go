@@ -0,0 +1,98 @@
+package taskgroup
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// CollectingTaskGroup is a TaskGroup whose tasks return a value.
+type CollectingTaskGroup[T any] interface {
+	Go(f func() (T, error))
+	// TryGo runs f only if the concurrency limit is not reached and reports whether it did.
+	TryGo(f func() (T, error)) bool
+	// Wait blocks until all tasks finish and returns their results in submission order.
+	Wait() ([]T, error)
+	// WaitCtx is like Wait but gives up when ctx is done, returning ctx.Err().
+	WaitCtx(ctx context.Context) ([]T, error)
+	// WaitTimeout is like WaitCtx with a deadline of d from now.
+	WaitTimeout(d time.Duration) ([]T, error)
+}
+
+type collectingTaskGroup[T any] struct {
+	tg      TaskGroup
+	mu      sync.Mutex
+	results []T
+}
+
+// NewCollecting if one task return error, the rest task will stop
+func NewCollecting[T any](ctx context.Context, concurrentCount int, opts ...Option) CollectingTaskGroup[T] {
+	return &collectingTaskGroup[T]{tg: NewTaskGroup(ctx, concurrentCount, opts...)}
+}
+
+// NewUninterruptibleCollecting if one task return error, the rest task will continue
+func NewUninterruptibleCollecting[T any](ctx context.Context, concurrentCount int, opts ...Option) CollectingTaskGroup[T] {
+	return &collectingTaskGroup[T]{tg: NewUninterruptibleTaskGroup(ctx, concurrentCount, opts...)}
+}
+
+func (c *collectingTaskGroup[T]) Go(f func() (T, error)) {
+	c.mu.Lock()
+	idx := len(c.results)
+	var zero T
+	c.results = append(c.results, zero)
+	c.mu.Unlock()
+
+	c.tg.Go(c.store(idx, f))
+}
+
+func (c *collectingTaskGroup[T]) TryGo(f func() (T, error)) bool {
+	// hold the lock across TryGo so the slot is only reserved once the task is accepted;
+	// the task itself cannot store its result before we release it.
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	idx := len(c.results)
+	if !c.tg.TryGo(c.store(idx, f)) {
+		return false
+	}
+	var zero T
+	c.results = append(c.results, zero)
+	return true
+}
+
+// store returns a task writing f's value to results[idx].
+func (c *collectingTaskGroup[T]) store(idx int, f func() (T, error)) func() error {
+	return func() error {
+		v, err := f()
+		if err != nil {
+			return err
+		}
+
+		c.mu.Lock()
+		c.results[idx] = v
+		c.mu.Unlock()
+		return nil
+	}
+}
+
+func (c *collectingTaskGroup[T]) Wait() ([]T, error) {
+	return c.collect(c.tg.Wait())
+}
+
+func (c *collectingTaskGroup[T]) WaitCtx(ctx context.Context) ([]T, error) {
+	return c.collect(c.tg.WaitCtx(ctx))
+}
+
+func (c *collectingTaskGroup[T]) WaitTimeout(d time.Duration) ([]T, error) {
+	return c.collect(c.tg.WaitTimeout(d))
+}
+
+func (c *collectingTaskGroup[T]) collect(err error) ([]T, error) {
+	if err != nil {
+		return nil, err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.results, nil
+}
@@ -2,49 +2,96 @@ package taskgroup
 
 import (
 	"context"
+	"runtime/debug"
+	"sync"
 	"sync/atomic"
+	"time"
 
 	"golang.org/x/sync/errgroup"
 
 	"github.com/me2seeks/forge/logs"
+	"github.com/me2seeks/forge/safego"
 )
 
 type TaskGroup interface {
 	Go(f func() error)
+	// TryGo runs f only if the concurrency limit is not reached and reports whether it did,
+	// instead of blocking like Go.
+	TryGo(f func() error) bool
 	Wait() error
+	// WaitCtx is like Wait but gives up when ctx is done, returning ctx.Err();
+	// the tasks keep running in the background. It must be called after all Go calls.
+	WaitCtx(ctx context.Context) error
+	// WaitTimeout is like WaitCtx with a deadline of d from now.
+	WaitTimeout(d time.Duration) error
 }
 
 type taskGroup struct {
 	errGroup    *errgroup.Group
 	ctx         context.Context
 	execAllTask atomic.Bool
+	opts        options
+
+	waitOnce sync.Once
+	waitDone chan struct{}
+	waitErr  error
 }
 
-// NewTaskGroup if one task return error, the rest task will stop
-func NewTaskGroup(ctx context.Context, concurrentCount int) TaskGroup {
-	t := &taskGroup{}
-	t.errGroup, t.ctx = errgroup.WithContext(ctx)
-	t.errGroup.SetLimit(concurrentCount)
-	t.execAllTask.Store(false)
+// Option configures a TaskGroup.
+type Option func(*options)
 
-	return t
+type options struct {
+	swallowPanic bool
+}
+
+// WithSwallowPanic restores the legacy behavior of only logging a task panic:
+// the task is then treated as successful and Wait does not report it.
+func WithSwallowPanic() Option {
+	return func(o *options) {
+		o.swallowPanic = true
+	}
+}
+
+// NewTaskGroup if one task return error, the rest task will stop
+func NewTaskGroup(ctx context.Context, concurrentCount int, opts ...Option) TaskGroup {
+	return newTaskGroup(ctx, concurrentCount, false, opts)
 }
 
 // NewUninterruptibleTaskGroup if one task return error, the rest task will continue
-func NewUninterruptibleTaskGroup(ctx context.Context, concurrentCount int) TaskGroup {
+func NewUninterruptibleTaskGroup(ctx context.Context, concurrentCount int, opts ...Option) TaskGroup {
+	return newTaskGroup(ctx, concurrentCount, true, opts)
+}
+
+func newTaskGroup(ctx context.Context, concurrentCount int, execAllTask bool, opts []Option) *taskGroup {
 	t := &taskGroup{}
 	t.errGroup, t.ctx = errgroup.WithContext(ctx)
 	t.errGroup.SetLimit(concurrentCount)
-	t.execAllTask.Store(true)
+	t.execAllTask.Store(execAllTask)
+	for _, opt := range opts {
+		opt(&t.opts)
+	}
 
 	return t
 }
 
+// Go runs f in the group, blocking while the concurrency limit is reached. A panic in f is recovered, logged, and returned
+// from Wait as an error carrying the stack, unless WithSwallowPanic is set.
 func (t *taskGroup) Go(f func() error) {
-	t.errGroup.Go(func() error {
+	t.errGroup.Go(t.wrap(f))
+}
+
+func (t *taskGroup) TryGo(f func() error) bool {
+	return t.errGroup.TryGo(t.wrap(f))
+}
+
+func (t *taskGroup) wrap(f func() error) func() error {
+	return func() (err error) {
 		defer func() {
-			if err := recover(); err != nil {
-				logs.CtxErrorf(t.ctx, "[TaskGroup] exec panic recover:%+v", err)
+			if r := recover(); r != nil {
+				logs.CtxErrorf(t.ctx, "[TaskGroup] exec panic recover:%+v", r)
+				if !t.opts.swallowPanic {
+					err = safego.NewPanicErr(r, debug.Stack())
+				}
 			}
 		}()
 
@@ -57,9 +104,32 @@ func (t *taskGroup) Go(f func() error) {
 		}
 
 		return f()
-	})
+	}
 }
 
 func (t *taskGroup) Wait() error {
 	return t.errGroup.Wait()
 }
+
+func (t *taskGroup) WaitCtx(ctx context.Context) error {
+	t.waitOnce.Do(func() {
+		t.waitDone = make(chan struct{})
+		go func() {
+			t.waitErr = t.errGroup.Wait()
+			close(t.waitDone)
+		}()
+	})
+
+	select {
+	case <-t.waitDone:
+		return t.waitErr
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (t *taskGroup) WaitTimeout(d time.Duration) error {
+	ctx, cancel := context.WithTimeout(context.Background(), d)
+	defer cancel()
+	return t.WaitCtx(ctx)
+}
@@ -0,0 +1,63 @@
+package taskgroup
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCollecting(t *testing.T) {
+	g := NewCollecting[int](context.Background(), 3)
+	for i := 0; i < 10; i++ {
+		g.Go(func() (int, error) {
+			time.Sleep(time.Duration(10-i) * time.Millisecond)
+			return i * i, nil
+		})
+	}
+
+	res, err := g.Wait()
+	assert.NoError(t, err)
+	assert.Equal(t, []int{0, 1, 4, 9, 16, 25, 36, 49, 64, 81}, res)
+
+	errBoom := errors.New("boom")
+	g = NewCollecting[int](context.Background(), 3)
+	g.Go(func() (int, error) { return 0, errBoom })
+	res, err = g.Wait()
+	assert.ErrorIs(t, err, errBoom)
+	assert.Nil(t, res)
+}
+
+func TestPanicPropagation(t *testing.T) {
+	g := NewTaskGroup(context.Background(), 2)
+	g.Go(func() error { panic("boom") })
+	err := g.Wait()
+	assert.ErrorContains(t, err, "boom")
+	assert.ErrorContains(t, err, "stack")
+
+	g = NewTaskGroup(context.Background(), 2, WithSwallowPanic())
+	g.Go(func() error { panic("boom") })
+	assert.NoError(t, g.Wait())
+}
+
+func TestTryGoAndWaitTimeout(t *testing.T) {
+	release := make(chan struct{})
+	g := NewTaskGroup(context.Background(), 1)
+	assert.True(t, g.TryGo(func() error { <-release; return nil }))
+	assert.False(t, g.TryGo(func() error { return nil }))
+
+	assert.ErrorIs(t, g.WaitTimeout(10*time.Millisecond), context.DeadlineExceeded)
+	close(release)
+	assert.NoError(t, g.WaitTimeout(time.Second))
+
+	c := NewCollecting[int](context.Background(), 1)
+	release = make(chan struct{})
+	assert.True(t, c.TryGo(func() (int, error) { <-release; return 1, nil }))
+	assert.False(t, c.TryGo(func() (int, error) { return 2, nil }))
+	close(release)
+	res, err := c.WaitCtx(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, []int{1}, res)
+}
@@ -0,0 +1,127 @@
+package uow
+
+import (
+	"context"
+	"time"
+
+	"github.com/me2seeks/forge/infra/contract/rdb"
+)
+
+// Journal records the progress of units of work so that the ones left pending by
+// a crashed process can be found and compensated out of band. Journal failures
+// are logged and never fail the unit of work itself.
+type Journal interface {
+	Begin(ctx context.Context, id string) error
+	// Step records that the seq-th compensation, named name, was registered.
+	Step(ctx context.Context, id string, seq int, name string) error
+	// Finish records the final status of the unit of work. err is the cause of a
+	// rollback, joined with the compensation errors if any.
+	Finish(ctx context.Context, id string, status Status, err error) error
+}
+
+type nopJournal struct{}
+
+func (nopJournal) Begin(context.Context, string) error                 { return nil }
+func (nopJournal) Step(context.Context, string, int, string) error     { return nil }
+func (nopJournal) Finish(context.Context, string, Status, error) error { return nil }
+
+// JournalSchema creates the tables used by RDBJournal.
+const JournalSchema = `
+CREATE TABLE IF NOT EXISTS uow_journal (
+	id         VARCHAR(32) PRIMARY KEY,
+	status     VARCHAR(16) NOT NULL,
+	error      TEXT        NOT NULL DEFAULT '',
+	created_at TIMESTAMP   NOT NULL,
+	updated_at TIMESTAMP   NOT NULL
+);
+CREATE TABLE IF NOT EXISTS uow_journal_step (
+	uow_id     VARCHAR(32)  NOT NULL,
+	seq        INT          NOT NULL,
+	name       VARCHAR(255) NOT NULL,
+	created_at TIMESTAMP    NOT NULL,
+	PRIMARY KEY (uow_id, seq)
+);
+`
+
+// RDBJournal is a Journal stored in the tables created by JournalSchema.
+type RDBJournal struct {
+	db  rdb.DB
+	now func() time.Time
+}
+
+func NewRDBJournal(db rdb.DB) *RDBJournal {
+	return &RDBJournal{db: db, now: time.Now}
+}
+
+func (j *RDBJournal) Begin(ctx context.Context, id string) error {
+	now := j.now()
+	_, err := j.db.Exec(ctx,
+		"INSERT INTO uow_journal (id, status, created_at, updated_at) VALUES (?, ?, ?, ?)",
+		id, StatusPending, now, now)
+	return err
+}
+
+func (j *RDBJournal) Step(ctx context.Context, id string, seq int, name string) error {
+	_, err := j.db.Exec(ctx,
+		"INSERT INTO uow_journal_step (uow_id, seq, name, created_at) VALUES (?, ?, ?, ?)",
+		id, seq, name, j.now())
+	return err
+}
+
+func (j *RDBJournal) Finish(ctx context.Context, id string, status Status, err error) error {
+	var msg string
+	if err != nil {
+		msg = err.Error()
+	}
+	_, execErr := j.db.Exec(ctx,
+		"UPDATE uow_journal SET status = ?, error = ?, updated_at = ? WHERE id = ?",
+		status, msg, j.now(), id)
+	return execErr
+}
+
+// JournalEntry is a unit of work read back from the journal.
+type JournalEntry struct {
+	ID        string
+	Status    Status
+	Error     string
+	Steps     []string
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+// Unfinished returns the units of work that are still pending or failed and were
+// last updated before olderThan, with the names of their steps in registration
+// order, for an operator or a job to compensate.
+func (j *RDBJournal) Unfinished(ctx context.Context, olderThan time.Time) ([]*JournalEntry, error) {
+	type row struct {
+		ID        string
+		Status    Status
+		Error     string
+		CreatedAt time.Time
+		UpdatedAt time.Time
+	}
+	rows, err := rdb.Select[row](ctx, j.db,
+		"SELECT id, status, error, created_at, updated_at FROM uow_journal WHERE status IN (?, ?) AND updated_at < ? ORDER BY id",
+		StatusPending, StatusFailed, olderThan)
+	if err != nil {
+		return nil, err
+	}
+
+	entries := make([]*JournalEntry, 0, len(rows))
+	for _, r := range rows {
+		steps, err := rdb.Select[string](ctx, j.db,
+			"SELECT name FROM uow_journal_step WHERE uow_id = ? ORDER BY seq", r.ID)
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, &JournalEntry{
+			ID:        r.ID,
+			Status:    r.Status,
+			Error:     r.Error,
+			Steps:     steps,
+			CreatedAt: r.CreatedAt,
+			UpdatedAt: r.UpdatedAt,
+		})
+	}
+	return entries, nil
+}
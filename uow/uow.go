@@ -0,0 +1,205 @@
+// Package uow coordinates writes across stores that can't share a transaction,
+// such as ES, the graph and object storage. Each write registers a compensating
+// action and, if the unit of work fails, the compensations run in reverse order
+// as a best-effort saga.
+package uow
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/me2seeks/forge/execute"
+	"github.com/me2seeks/forge/idgen"
+	"github.com/me2seeks/forge/logs"
+	"github.com/me2seeks/forge/retry"
+)
+
+// ErrFinished is returned when registering on, committing or rolling back a
+// unit of work that is already committed or rolled back.
+var ErrFinished = errors.New("uow: unit of work already finished")
+
+// Status is the state of a unit of work as recorded in the journal.
+type Status string
+
+const (
+	StatusPending     Status = "pending"
+	StatusCommitted   Status = "committed"
+	StatusCompensated Status = "compensated"
+	// StatusFailed means at least one compensation still failed after its retries.
+	StatusFailed Status = "failed"
+)
+
+// CompensateFunc undoes a write. It may be retried, so it must be idempotent.
+type CompensateFunc func(ctx context.Context) error
+
+type step struct {
+	name       string
+	compensate CompensateFunc
+}
+
+type UnitOfWork struct {
+	id      string
+	journal Journal
+	retry   retry.Policy
+
+	mu       sync.Mutex
+	steps    []step
+	finished bool
+}
+
+type Option func(*UnitOfWork)
+
+// WithJournal records the unit of work in j. Defaults to no journaling.
+func WithJournal(j Journal) Option {
+	return func(u *UnitOfWork) {
+		u.journal = j
+	}
+}
+
+// WithRetry sets the retry policy of each compensation. Defaults to 3 attempts
+// with exponential backoff from 100ms.
+func WithRetry(policy retry.Policy) Option {
+	return func(u *UnitOfWork) {
+		u.retry = policy
+	}
+}
+
+type uowKey struct{}
+
+// Begin starts a unit of work and returns a ctx carrying it for Register.
+func Begin(ctx context.Context, opts ...Option) (context.Context, *UnitOfWork) {
+	u := &UnitOfWork{
+		id:      idgen.NewULID(),
+		journal: nopJournal{},
+		retry: retry.Policy{
+			MaxAttempts: 3,
+			Backoff:     retry.Exponential(100*time.Millisecond, 2*time.Second),
+		},
+	}
+	for _, opt := range opts {
+		opt(u)
+	}
+
+	if err := u.journal.Begin(ctx, u.id); err != nil {
+		logs.CtxWarnf(ctx, "[uow] journal begin %s failed: %v", u.id, err)
+	}
+	return context.WithValue(ctx, uowKey{}, u), u
+}
+
+// FromContext returns the unit of work carried by ctx, if any.
+func FromContext(ctx context.Context) (*UnitOfWork, bool) {
+	u, ok := ctx.Value(uowKey{}).(*UnitOfWork)
+	return u, ok
+}
+
+// Register adds a compensation to the unit of work carried by ctx. It is a no-op
+// returning nil if ctx carries none, so store writers can call it unconditionally.
+func Register(ctx context.Context, name string, compensate CompensateFunc) error {
+	u, ok := FromContext(ctx)
+	if !ok {
+		return nil
+	}
+	return u.Register(ctx, name, compensate)
+}
+
+// Run calls fn within a new unit of work, committing it if fn returns nil and
+// rolling it back otherwise, including when fn panics. The returned error wraps
+// the error of fn and any compensation failure.
+func Run(ctx context.Context, fn func(ctx context.Context) error, opts ...Option) (err error) {
+	ctx, u := Begin(ctx, opts...)
+
+	defer func() {
+		if r := recover(); r != nil {
+			_ = u.Rollback(ctx, fmt.Errorf("panic: %v", r))
+			panic(r)
+		}
+	}()
+
+	if err = fn(ctx); err != nil {
+		if rbErr := u.Rollback(ctx, err); rbErr != nil {
+			return errors.Join(err, rbErr)
+		}
+		return err
+	}
+	return u.Commit(ctx)
+}
+
+func (u *UnitOfWork) ID() string {
+	return u.id
+}
+
+// Register adds a compensation, to be run if the unit of work is rolled back.
+func (u *UnitOfWork) Register(ctx context.Context, name string, compensate CompensateFunc) error {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	if u.finished {
+		return ErrFinished
+	}
+	u.steps = append(u.steps, step{name: name, compensate: compensate})
+	if err := u.journal.Step(ctx, u.id, len(u.steps), name); err != nil {
+		logs.CtxWarnf(ctx, "[uow] journal step %s of %s failed: %v", name, u.id, err)
+	}
+	return nil
+}
+
+// Commit discards the registered compensations.
+func (u *UnitOfWork) Commit(ctx context.Context) error {
+	if _, err := u.finish(); err != nil {
+		return err
+	}
+	u.record(ctx, StatusCommitted, nil)
+	return nil
+}
+
+// Rollback runs the registered compensations in reverse order, retrying each one
+// per the retry policy. A failed compensation doesn't stop the others; their
+// errors are joined in the result. cause is only journaled.
+func (u *UnitOfWork) Rollback(ctx context.Context, cause error) error {
+	steps, err := u.finish()
+	if err != nil {
+		return err
+	}
+
+	// Compensations must run even if the request that failed was canceled.
+	ctx = context.WithoutCancel(ctx)
+
+	var errs []error
+	for i := len(steps) - 1; i >= 0; i-- {
+		s := steps[i]
+		if err := execute.RunWithRetry(ctx, u.retry, s.compensate); err != nil {
+			logs.CtxErrorf(ctx, "[uow] compensate %s of %s failed: %v", s.name, u.id, err)
+			errs = append(errs, fmt.Errorf("compensate %s: %w", s.name, err))
+		}
+	}
+
+	err = errors.Join(errs...)
+	if err != nil {
+		u.record(ctx, StatusFailed, errors.Join(cause, err))
+	} else {
+		u.record(ctx, StatusCompensated, cause)
+	}
+	return err
+}
+
+func (u *UnitOfWork) finish() ([]step, error) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	if u.finished {
+		return nil, ErrFinished
+	}
+	u.finished = true
+	steps := u.steps
+	u.steps = nil
+	return steps, nil
+}
+
+func (u *UnitOfWork) record(ctx context.Context, status Status, err error) {
+	if jErr := u.journal.Finish(ctx, u.id, status, err); jErr != nil {
+		logs.CtxWarnf(ctx, "[uow] journal finish %s as %s failed: %v", u.id, status, jErr)
+	}
+}
@@ -0,0 +1,103 @@
+package uow
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/me2seeks/forge/retry"
+)
+
+type memJournal struct {
+	mu     sync.Mutex
+	steps  []string
+	status Status
+	err    error
+}
+
+func (j *memJournal) Begin(context.Context, string) error {
+	j.status = StatusPending
+	return nil
+}
+
+func (j *memJournal) Step(_ context.Context, _ string, _ int, name string) error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	j.steps = append(j.steps, name)
+	return nil
+}
+
+func (j *memJournal) Finish(_ context.Context, _ string, status Status, err error) error {
+	j.status, j.err = status, err
+	return nil
+}
+
+func TestRollback(t *testing.T) {
+	journal := &memJournal{}
+	errWrite := errors.New("write failed")
+	errStuck := errors.New("stuck")
+
+	var undone []string
+	flaky := 0
+	err := Run(context.Background(), func(ctx context.Context) error {
+		assert.NoError(t, Register(ctx, "es", func(context.Context) error {
+			undone = append(undone, "es")
+			return nil
+		}))
+		assert.NoError(t, Register(ctx, "graph", func(context.Context) error {
+			if flaky++; flaky < 2 {
+				return errors.New("flaky")
+			}
+			undone = append(undone, "graph")
+			return nil
+		}))
+		assert.NoError(t, Register(ctx, "storage", func(context.Context) error {
+			return errStuck
+		}))
+		return errWrite
+	}, WithJournal(journal), WithRetry(retry.Policy{MaxAttempts: 2}))
+
+	assert.ErrorIs(t, err, errWrite)
+	assert.ErrorIs(t, err, errStuck)
+	assert.Equal(t, []string{"graph", "es"}, undone)
+	assert.Equal(t, []string{"es", "graph", "storage"}, journal.steps)
+	assert.Equal(t, StatusFailed, journal.status)
+	assert.ErrorIs(t, journal.err, errWrite)
+}
+
+func TestCommit(t *testing.T) {
+	journal := &memJournal{}
+	ctx, u := Begin(context.Background(), WithJournal(journal))
+
+	called := false
+	assert.NoError(t, Register(ctx, "es", func(context.Context) error {
+		called = true
+		return nil
+	}))
+	assert.NoError(t, u.Commit(ctx))
+	assert.False(t, called)
+	assert.Equal(t, StatusCommitted, journal.status)
+
+	assert.ErrorIs(t, u.Rollback(ctx, nil), ErrFinished)
+	assert.ErrorIs(t, Register(ctx, "graph", nil), ErrFinished)
+
+	// Without a unit of work in ctx, Register is a no-op.
+	assert.NoError(t, Register(context.Background(), "es", nil))
+}
+
+func TestRunPanic(t *testing.T) {
+	undone := false
+	assert.Panics(t, func() {
+		_ = Run(context.Background(), func(ctx context.Context) error {
+			_ = Register(ctx, "es", func(context.Context) error {
+				undone = true
+				return nil
+			})
+			panic("boom")
+		})
+	})
+	assert.True(t, undone)
+}